@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// NewAliasCmd creates a new "alias" command that lists the configured command aliases.
+func NewAliasCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "alias",
+		Short: "List configured command aliases",
+		Example: examples(
+			ex("exo alias", `List the aliases defined under "alias" in config.yaml`),
+		),
+		Long: `List the command aliases defined in the configuration.
+
+Aliases are defined under the "alias" key of the configuration file:
+
+  alias:
+    t: zet new --stdin
+
+Running "exo t <args>" then expands to "exo zet new --stdin <args>".
+Built-in commands always take precedence over aliases.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := deps.Config.AliasNames()
+			if len(names) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No aliases defined")
+				return nil
+			}
+			for _, name := range names {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s = %s\n", name, deps.Config.Alias[name])
+			}
+			return nil
+		},
+	}
+}
+
+// ExpandAliases rewrites args when the command it names is a configured alias,
+// replacing the alias with its expansion, split into words as a shell would.
+// Persistent flags before the alias, such as "exo -d t", are kept in front.
+// Built-in commands shadow aliases, and expansion is not recursive. Cobra's
+// hidden completion commands are handled so that arguments of an aliased
+// command complete like the expanded command.
+func ExpandAliases(root *cobra.Command, aliases map[string]string, args []string) []string {
+	if len(aliases) == 0 || len(args) == 0 {
+		return args
+	}
+	if args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd {
+		return append([]string{args[0]}, expandAlias(root, aliases, args[1:], true)...)
+	}
+	return expandAlias(root, aliases, args, false)
+}
+
+// expandAlias expands the alias args name after the leading persistent flags.
+// When completing, the alias is only expanded once it is complete, that is
+// when it is not the last word, the one being completed.
+func expandAlias(root *cobra.Command, aliases map[string]string, args []string, completing bool) []string {
+	n := leadingFlags(root.PersistentFlags(), args)
+	if n >= len(args) || (completing && n == len(args)-1) {
+		return args
+	}
+	expansion, ok := aliases[args[n]]
+	if !ok || isBuiltinCommand(root, args[n]) {
+		return args
+	}
+	words, err := fs.SplitWords(expansion)
+	if err != nil {
+		return args
+	}
+	expanded := append(slices.Clone(args[:n]), words...)
+	return append(expanded, args[n+1:]...)
+}
+
+// leadingFlags returns the number of elements at the start of args that are
+// flags of flags, such as the persistent flags of the root command, with
+// their values.
+func leadingFlags(flags *pflag.FlagSet, args []string) int {
+	i := 0
+next:
+	for i < len(args) {
+		arg := args[i]
+		if arg == "-" || arg == "--" || !strings.HasPrefix(arg, "-") {
+			break
+		}
+		var f *pflag.Flag
+		if name, ok := strings.CutPrefix(arg, "--"); ok {
+			name, _, hasValue := strings.Cut(name, "=")
+			if f = flags.Lookup(name); f != nil && hasValue {
+				i++
+				continue
+			}
+		} else {
+			// A shorthand, a group of them, such as -dv, or one with its
+			// value attached, such as -cwork.yaml.
+			for j := 1; j < len(arg); j++ {
+				if f = flags.ShorthandLookup(arg[j : j+1]); f == nil {
+					break next
+				}
+				if f.NoOptDefVal == "" && j < len(arg)-1 {
+					i++
+					continue next
+				}
+			}
+		}
+		if f == nil {
+			break
+		}
+		i++
+		if f.NoOptDefVal == "" {
+			// The value is the next argument.
+			i++
+		}
+	}
+	return min(i, len(args))
+}
+
+// isBuiltinCommand reports whether name matches a subcommand (or subcommand alias) of root,
+// including the help and completion commands cobra adds on execution.
+func isBuiltinCommand(root *cobra.Command, name string) bool {
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+	for _, c := range root.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasCompletions returns shell completions for alias names, annotated with their expansion.
+// Aliases shadowed by built-in commands are omitted.
+func aliasCompletions(root *cobra.Command, deps Dependencies, toComplete string) []string {
+	var completions []string
+	for _, name := range deps.Config.AliasNames() {
+		if strings.HasPrefix(name, toComplete) && !isBuiltinCommand(root, name) {
+			completions = append(completions, fmt.Sprintf("%s\talias for %s", name, deps.Config.Alias[name]))
+		}
+	}
+	return completions
+}
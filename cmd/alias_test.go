@@ -0,0 +1,49 @@
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpandAliases(t *testing.T) {
+	deps := cmd.Dependencies{Config: &config.Config{}, Logger: testutil.NewDummyLogger(), FS: testutil.NewDummyFS()}
+	root := cmd.NewRootCmd(deps)
+	root.AddCommand(cmd.NewZetCmd(deps))
+	aliases := map[string]string{
+		"t":   "zet new --stdin",
+		"q":   `log --heading '## To do' "Quick note"`,
+		"zet": "day",
+		"bad": `zet new "unterminated`,
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"not an alias", []string{"zet", "list"}, []string{"zet", "list"}},
+		{"alias", []string{"t", "Idea"}, []string{"zet", "new", "--stdin", "Idea"}},
+		{"quoted words", []string{"q"}, []string{"log", "--heading", "## To do", "Quick note"}},
+		{"built-in shadows alias", []string{"zet"}, []string{"zet"}},
+		{"unterminated quote", []string{"bad"}, []string{"bad"}},
+		{"bool flag before", []string{"-d", "t", "Idea"}, []string{"-d", "zet", "new", "--stdin", "Idea"}},
+		{"shorthand group before", []string{"-dv", "t"}, []string{"-dv", "zet", "new", "--stdin"}},
+		{"flag with value before", []string{"--config", "work.yaml", "t"}, []string{"--config", "work.yaml", "zet", "new", "--stdin"}},
+		{"flag with = value before", []string{"--config=work.yaml", "-d", "t"}, []string{"--config=work.yaml", "-d", "zet", "new", "--stdin"}},
+		{"shorthand with value before", []string{"-c", "work.yaml", "t"}, []string{"-c", "work.yaml", "zet", "new", "--stdin"}},
+		{"shorthand with attached value", []string{"-cwork.yaml", "t"}, []string{"-cwork.yaml", "zet", "new", "--stdin"}},
+		{"unknown flag before", []string{"--nope", "t"}, []string{"--nope", "t"}},
+		{"only flags", []string{"-d", "--config", "work.yaml"}, []string{"-d", "--config", "work.yaml"}},
+		{"completing the alias", []string{"__complete", "-d", "t"}, []string{"__complete", "-d", "t"}},
+		{"completing after the alias", []string{"__complete", "-d", "t", ""}, []string{"__complete", "-d", "zet", "new", "--stdin", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cmd.ExpandAliases(root, aliases, tt.args))
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/backup"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// NewBackupCmd creates a new "backup" command with push, pull, snapshot and
+// restore subcommands.
+func NewBackupCmd(deps Dependencies) *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Back the data home up to S3, WebDAV or local snapshots",
+		Example: examples(
+			ex("exo backup push", "Upload the changes since the last backup"),
+			ex("exo backup pull --to ~/restored", "Restore the backup into another directory"),
+			ex("exo backup snapshot", "Take a local snapshot of the data home"),
+			ex("exo backup restore latest ideas/note.md", "Restore a note from the latest snapshot"),
+		),
+		Long: `Back the data home up to remote storage, and restore it from there.
+
+The remote is set in backup.remote: "s3" for Amazon S3 and S3-compatible services
+(backup.s3.endpoint, region, bucket and prefix, with the credentials in
+AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY), or "webdav" (backup.webdav.url and
+username, with the password in EXO_BACKUP_PASSWORD).
+
+Files are stored by content hash: a push only uploads the files changed since the
+previous one. With backup.encrypt, files are encrypted with AES-256-GCM before they
+are uploaded, with a key derived from the passphrase in EXO_BACKUP_PASSPHRASE.
+
+Snapshots are local copies of the data home kept in backup.snapshot_dir. Files
+unchanged since the previous snapshot are hard links to it, so that a snapshot
+only takes the space of what changed. Old snapshots are removed following
+backup.keep.`,
+	}
+	backupCmd.AddCommand(newBackupPushCmd(deps))
+	backupCmd.AddCommand(newBackupPullCmd(deps))
+	backupCmd.AddCommand(newBackupSnapshotCmd(deps))
+	backupCmd.AddCommand(newBackupRestoreCmd(deps))
+	return backupCmd
+}
+
+func newBackupPushCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "push",
+		Short: "Upload the data home to the backup remote",
+		Example: examples(
+			ex("exo backup push", "Upload the changes since the last backup"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, opts, err := backupRemote(deps)
+			if err != nil {
+				return err
+			}
+			if deps.Config.Backup.Encrypt && opts.Passphrase == "" {
+				return exoerrors.New(exoerrors.Config, "backup.encrypt is set: set %s to the passphrase of the backup", backup.EnvPassphrase)
+			}
+			if !deps.Config.Backup.Encrypt {
+				opts.Passphrase = ""
+			}
+			result, err := backup.Push(cmd.Context(), remote, deps.Config.Dir.DataHome, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Backed up %d file(s): uploaded %d (%d bytes)\n", result.Files, result.Transferred, result.Bytes)
+			return nil
+		},
+	}
+}
+
+func newBackupPullCmd(deps Dependencies) *cobra.Command {
+	var to string
+	cmd := &cobra.Command{
+		Use:   "pull",
+		Short: "Restore the data home from the backup remote",
+		Example: examples(
+			ex("exo backup pull", "Restore the files missing or changed in the data home"),
+			ex("exo backup pull --to ~/restored", "Restore the backup into another directory"),
+		),
+		Long: `Restore the backup into the data home, or into --to. Only the files that are
+missing or differ from the backup are downloaded; files not in the backup are kept.`,
+		Annotations: mutates(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote, opts, err := backupRemote(deps)
+			if err != nil {
+				return err
+			}
+			dest := deps.Config.Dir.DataHome
+			if to != "" {
+				if dest, err = filepath.Abs(fs.ExpandPath(to)); err != nil {
+					return fmt.Errorf("invalid destination: %w", err)
+				}
+			}
+			result, err := backup.Pull(cmd.Context(), remote, dest, opts)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %d of %d file(s) into %s (%d bytes)\n", result.Transferred, result.Files, dest, result.Bytes)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&to, "to", "", "Directory to restore into (default: the configured data_home)")
+	return cmd
+}
+
+func newBackupSnapshotCmd(deps Dependencies) *cobra.Command {
+	var list, noPrune bool
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Take a local snapshot of the data home",
+		Example: examples(
+			ex("exo backup snapshot", "Take a snapshot and remove the ones backup.keep does not keep"),
+			ex("exo backup snapshot --list", "List the snapshots"),
+		),
+		Long: `Take a snapshot of the data home into backup.snapshot_dir, named after the
+current time, then remove the old snapshots that the retention policy in
+backup.keep does not keep: the last N snapshots, and the newest one of each of
+the last days, weeks, months and years.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := deps.Config.Backup.SnapshotDir
+			out := cmd.OutOrStdout()
+			if list {
+				snapshots, err := backup.Snapshots(dir)
+				if err != nil {
+					return exoerrors.Wrap(exoerrors.IO, err)
+				}
+				keep := backup.Retain(snapshots, deps.Config.Backup.Keep)
+				for _, s := range snapshots {
+					mark := ""
+					if !keep[s.Name] {
+						mark = " (pruned on the next snapshot)"
+					}
+					fmt.Fprintf(out, "%s%s\n", s.Name, mark)
+				}
+				return nil
+			}
+
+			s, stats, err := backup.TakeSnapshot(dir, deps.Config.Dir.DataHome, time.Now())
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.IO, err)
+			}
+			fmt.Fprintf(out, "Took snapshot %s of %d file(s): copied %d (%d bytes)\n", s.Name, stats.Files, stats.Copied, stats.Bytes)
+			if noPrune {
+				return nil
+			}
+			removed, err := backup.Prune(dir, deps.Config.Backup.Keep)
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.IO, err)
+			}
+			for _, r := range removed {
+				fmt.Fprintf(out, "Removed snapshot %s\n", r.Name)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&list, "list", false, "List the snapshots instead of taking one")
+	cmd.Flags().BoolVar(&noPrune, "no-prune", false, "Keep all snapshots, regardless of backup.keep")
+	return cmd
+}
+
+func newBackupRestoreCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <snapshot> [path]",
+		Short: "Restore files from a local snapshot",
+		Example: examples(
+			ex("exo backup restore latest", "Restore the whole data home from the latest snapshot"),
+			ex("exo backup restore 2024-03-15T101500 ideas/note.md", "Restore a note from a snapshot"),
+		),
+		Long: `Restore the file or directory at path, relative to the data home, from a
+snapshot; without a path, the whole snapshot is restored. The snapshot is named as
+listed by "exo backup snapshot --list", or "latest". Restored files replace the
+ones in the data home; files not in the snapshot are kept.`,
+		Annotations: mutates(),
+		Args:        cobra.RangeArgs(1, 2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveDefault
+			}
+			snapshots, _ := backup.Snapshots(deps.Config.Backup.SnapshotDir)
+			names := []string{"latest"}
+			for _, s := range snapshots {
+				names = append(names, s.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			s, err := backup.FindSnapshot(deps.Config.Backup.SnapshotDir, args[0])
+			if errors.Is(err, backup.ErrNotFound) {
+				return exoerrors.New(exoerrors.NotFound, "snapshot not found: %s", args[0])
+			}
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.IO, err)
+			}
+			var path string
+			if len(args) == 2 {
+				if path, err = snapshotPath(deps.Config.Dir.DataHome, args[1]); err != nil {
+					return err
+				}
+			}
+			n, err := backup.RestoreSnapshot(s, deps.Config.Dir.DataHome, path)
+			if errors.Is(err, backup.ErrNotFound) {
+				return exoerrors.New(exoerrors.NotFound, "%s is not in snapshot %s", args[1], s.Name)
+			}
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.IO, err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %d file(s) from snapshot %s\n", n, s.Name)
+			return nil
+		},
+	}
+}
+
+// snapshotPath returns path, relative to the data home or absolute inside it,
+// relative to the data home.
+func snapshotPath(home, path string) (string, error) {
+	path = fs.ExpandPath(path)
+	if filepath.IsAbs(path) {
+		rel, err := filepath.Rel(home, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return "", exoerrors.New(exoerrors.Usage, "%s is not in the data home", path)
+		}
+		path = rel
+	}
+	path = filepath.Clean(path)
+	if path == "." {
+		return "", nil
+	}
+	if path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+		return "", exoerrors.New(exoerrors.Usage, "%s is not in the data home", path)
+	}
+	return filepath.ToSlash(path), nil
+}
+
+// backupRemote returns the configured backup remote and the options of backups,
+// with the passphrase read from the environment.
+func backupRemote(deps Dependencies) (backup.Remote, backup.Options, error) {
+	remote, err := backup.New(deps.Config.Backup.Remote, *deps.Config)
+	if err != nil {
+		return nil, backup.Options{}, exoerrors.Wrap(exoerrors.Config, err)
+	}
+	return remote, backup.Options{Passphrase: os.Getenv(backup.EnvPassphrase)}, nil
+}
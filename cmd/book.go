@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/book"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// bookTemplate is the template book notes are created with.
+const bookTemplate = "book"
+
+// NewBookCmd returns a new cobra.Command for the "book" command, which keeps
+// literature notes on books.
+func NewBookCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "book",
+		Short: "Keep literature notes on books",
+		Example: examples(
+			ex("exo book new 978-0-262-51087-5", "Create a note on a book looked up by ISBN"),
+			ex(`exo book new "Structure and Interpretation of Computer Programs"`, "Look a book up by title"),
+		),
+	}
+	cmd.AddCommand(newBookNewCmd(deps))
+	return cmd
+}
+
+func newBookNewCmd(deps Dependencies) *cobra.Command {
+	var (
+		offline      bool
+		providerName string
+		override     book.Metadata
+		edit         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "new <ISBN|title...>",
+		Short: "Create a literature note on a book",
+		Long: `Create a literature note on a book in dir.literature_dir, using the "book"
+template, and print its path.
+
+The title, authors, year, publisher, ISBN and page count of the book are looked
+up by ISBN or title with the provider set in book.provider ("openlibrary" by
+default), and recorded in the frontmatter of the note. --title, --author and
+--year take precedence over the metadata found; with --offline, or when
+book.provider is empty, nothing is looked up.`,
+		Example: examples(
+			ex("exo book new 978-0-262-51087-5", "Create a note on a book looked up by ISBN"),
+			ex(`exo book new "Deep Work"`, "Look the book up by title"),
+			ex(`exo book new --offline "Notes on a Book" --author "A. Writer" --year 2021`, "Give the metadata yourself"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.TrimSpace(strings.Join(args, " "))
+			var meta book.Metadata
+			isbn, isISBN := book.NormalizeISBN(query)
+			if isISBN {
+				meta.ISBN = isbn
+			} else {
+				meta.Title = query
+			}
+
+			if providerName == "" {
+				providerName = deps.Config.Book.Provider
+			}
+			if !offline && providerName != "" {
+				found, err := lookupBook(cmd.Context(), deps, providerName, isbn, meta.Title)
+				switch {
+				case err == nil:
+					found.ISBN = firstNonEmpty(meta.ISBN, found.ISBN)
+					meta = found
+				case isISBN && override.Title == "":
+					if errors.Is(err, book.ErrNotFound) {
+						return exoerrors.New(exoerrors.NotFound, "no book found with ISBN %s (give its title with --title)", isbn)
+					}
+					return err
+				default:
+					deps.Logger.Errorf("Failed to look up book: %v", err)
+				}
+			}
+			meta.Title = firstNonEmpty(override.Title, meta.Title)
+			if len(override.Authors) > 0 {
+				meta.Authors = override.Authors
+			}
+			meta.Year = firstNonEmpty(override.Year, meta.Year)
+			if meta.Title == "" {
+				return exoerrors.New(exoerrors.Usage, "no title for ISBN %s: give one with --title", isbn)
+			}
+
+			n, err := createBookNote(cmd.Context(), deps, meta)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), n.Path())
+			if edit {
+				return n.Open()
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&offline, "offline", false, "Do not look the book up")
+	cmd.Flags().StringVar(&providerName, "provider", "", "Metadata provider to look the book up with (default: book.provider)")
+	cmd.Flags().StringVarP(&override.Title, "title", "t", "", "Title of the book")
+	cmd.Flags().StringSliceVarP(&override.Authors, "author", "a", nil, "Author of the book (repeatable)")
+	cmd.Flags().StringVar(&override.Year, "year", "", "Year the book was published")
+	cmd.Flags().BoolVarP(&edit, "edit", "e", false, "Open the note in the editor")
+	_ = cmd.RegisterFlagCompletionFunc("provider", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(book.Names(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// lookupBook looks a book up by isbn, or title if isbn is empty, with the
+// named provider.
+func lookupBook(ctx context.Context, deps Dependencies, providerName, isbn, title string) (book.Metadata, error) {
+	p, err := book.New(providerName, *deps.Config)
+	if err != nil {
+		return book.Metadata{}, exoerrors.Wrap(exoerrors.Config, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, book.DefaultTimeout)
+	defer cancel()
+	return p.Lookup(ctx, isbn, title)
+}
+
+// createBookNote creates the literature note on the book described by meta.
+func createBookNote(ctx context.Context, deps Dependencies, meta book.Metadata) (note.Note, error) {
+	subDir, err := filepath.Rel(deps.Config.Dir.DataHome, deps.Config.Dir.LiteratureDir)
+	if err != nil {
+		return nil, err
+	}
+	n, err := note.NewBaseNote(meta.Title, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS,
+		note.WithContext(ctx),
+		note.WithSubDir(subDir),
+		note.WithFileName(noteFileName(deps, meta.Title)+scan.NoteExtension),
+		note.WithTemplateName(bookTemplate),
+		note.WithType("book"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+	if n.Exists() {
+		return nil, exoerrors.New(exoerrors.Conflict, "note %s already exists", n.Path())
+	}
+
+	pages := ""
+	if meta.Pages > 0 {
+		pages = strconv.Itoa(meta.Pages)
+	}
+	content, err := deps.TemplateManager.ProcessTemplateWithContext(ctx, bookTemplate, map[string]interface{}{
+		"Title":      meta.Title,
+		"Authors":    meta.Authors,
+		"AuthorList": strings.Join(meta.Authors, ", "),
+		"Year":       meta.Year,
+		"Publisher":  meta.Publisher,
+		"ISBN":       meta.ISBN,
+		"Pages":      pages,
+		"Date":       n.Created().Format(dailyDateLayout),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if content, err = focusContent(content); err != nil {
+		return nil, err
+	}
+	if err := n.SetContent(content); err != nil {
+		return nil, err
+	}
+	if err := n.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save note: %w", err)
+	}
+	return n, nil
+}
+
+// firstNonEmpty returns the first of values that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/bulk"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// bulkDone describes each operation once applied, for the summary.
+var bulkDone = map[bulk.Op]string{
+	bulk.Archive: "Archived",
+	bulk.Delete:  "Deleted",
+	bulk.Retag:   "Retagged",
+	bulk.Move:    "Moved",
+}
+
+// NewBulkCmd returns a new cobra.Command for the "bulk" command, which applies
+// an operation to every note matching filters.
+func NewBulkCmd(deps Dependencies) *cobra.Command {
+	var (
+		noteType  string
+		tag       string
+		olderThan string
+		to        string
+		yes       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bulk <archive|delete|retag|move> [query]...",
+		Short: "Archive, delete, retag or move many notes at once",
+		Long: `Apply an operation to every note matching a query, selected from the note
+index:
+
+  archive  move the notes into dir.archive_dir, keeping their path in the vault
+  delete   delete the notes
+  retag    rename the --tag of the notes to --to, or remove it with --to ""
+  move     move the notes into the directory --to, in the vault (relative to
+           data_home)
+
+A query or one of --type, --tag and --older-than is required. These flags are
+short for the type:, tag: and created:< terms; --older-than takes an age such
+as 2y, 6m, 3w or 30d, or a date such as 2024-01, and matches notes created
+before it.
+
+The notes and what would happen to them are always listed first; nothing is
+changed unless --yes is given. Notes locked with locked: true in their
+frontmatter are skipped unless --force is given.
+
+` + queryHelp,
+		Example: examples(
+			ex("exo bulk --type zettel --tag obsolete --older-than 2y archive", "Preview archiving old obsolete zettels"),
+			ex("exo bulk --tag obsolete --older-than 2y archive --yes", "Archive them"),
+			ex("exo bulk --tag golang retag --to go --yes", "Rename a tag"),
+			ex("exo bulk --type inbox move --to zettel", "Preview moving every inbox note to the zettel directory"),
+			ex("exo bulk delete 'type:daily created:<2020 -#keep'", "Preview deleting daily notes from before 2020"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return filterPrefix([]string{string(bulk.Archive), string(bulk.Delete), string(bulk.Retag), string(bulk.Move)}, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			op, err := bulk.ParseOp(args[0])
+			if err != nil {
+				return err
+			}
+			input := strings.Join(args[1:], " ")
+			if strings.TrimSpace(input) == "" && noteType == "" && tag == "" && olderThan == "" {
+				return exoerrors.New(exoerrors.Usage, "a query or one of --type, --tag and --older-than is required")
+			}
+			switch {
+			case op == bulk.Retag && tag == "":
+				return exoerrors.New(exoerrors.Usage, "retag requires --tag, the tag to rename")
+			case op == bulk.Retag && !cmd.Flags().Changed("to"):
+				return exoerrors.New(exoerrors.Usage, `retag requires --to, the new tag (--to "" removes the tag)`)
+			case op == bulk.Move && to == "":
+				return exoerrors.New(exoerrors.Usage, "move requires --to, the target directory")
+			}
+
+			q, err := parseQuery(input,
+				fieldTerm(query.Type, "", noteType),
+				fieldTerm(query.Tag, "", tag),
+				fieldTerm(query.Created, "<", olderThan))
+			if err != nil {
+				return err
+			}
+			notes, err := bulkNotes(deps, op, q)
+			if err != nil {
+				return err
+			}
+
+			dir := deps.Config.Dir.ArchiveDir
+			if op == bulk.Move {
+				dir = to
+				if !filepath.IsAbs(dir) {
+					dir = filepath.Join(deps.Config.Dir.DataHome, dir)
+				}
+				if !isWithin(deps.Config.Dir.DataHome, dir) {
+					return exoerrors.New(exoerrors.Usage, "--to must be a directory of the vault, not %s", to)
+				}
+			}
+			out := cmd.OutOrStdout()
+			if len(notes) == 0 {
+				fmt.Fprintln(out, "No notes match.")
+				return nil
+			}
+			var unlocked []scan.Note
+			for _, n := range notes {
+				if n.Locked && !deps.Force {
+					fmt.Fprintf(out, "%-8s %s (locked)\n", "skip", vaultPath(deps, n.Path))
+					continue
+				}
+				unlocked = append(unlocked, n)
+				fmt.Fprintf(out, "%-8s %s\n", op, bulkPreview(deps, op, n, dir, to))
+			}
+			if locked := len(notes) - len(unlocked); locked > 0 {
+				fmt.Fprintf(out, "\n%d locked notes are skipped; use --force to include them.\n", locked)
+			}
+			if !yes {
+				fmt.Fprintf(out, "\n%d notes match. Run again with --yes to %s them.\n", len(unlocked), op)
+				return nil
+			}
+
+			for _, n := range unlocked {
+				if err := applyBulk(deps, op, n, dir, tag, to); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(out, "\n%s %d notes.\n", bulkDone[op], len(unlocked))
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&noteType, "type", "", "Only notes of this type (frontmatter type or top-level directory)")
+	flags.StringVarP(&tag, "tag", "t", "", "Only notes with this tag; the tag renamed by retag")
+	flags.StringVar(&olderThan, "older-than", "", "Only notes created longer ago than an age, e.g. 2y, 6m, 3w or 30d, or before a date")
+	flags.StringVar(&to, "to", "", "The new tag for retag, or the target directory for move")
+	flags.BoolVarP(&yes, "yes", "y", false, "Apply the operation instead of previewing it")
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeTags(deps))
+	return cmd
+}
+
+// bulkNotes returns the notes matching q from the index, leaving out notes
+// already archived when archiving.
+func bulkNotes(deps Dependencies, op bulk.Op, q query.Query) ([]scan.Note, error) {
+	notes, err := selectNotes(deps, q)
+	if err != nil || op != bulk.Archive {
+		return notes, err
+	}
+	var out []scan.Note
+	for _, n := range notes {
+		if rel, err := filepath.Rel(deps.Config.Dir.ArchiveDir, n.Path); err != nil || strings.HasPrefix(rel, "..") {
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
+
+// bulkPreview describes what op does to the note n.
+func bulkPreview(deps Dependencies, op bulk.Op, n scan.Note, dir, to string) string {
+	path := vaultPath(deps, n.Path)
+	switch op {
+	case bulk.Archive, bulk.Move:
+		return path + " -> " + vaultPath(deps, bulk.Target(op, n.Path, deps.Config.Dir.DataHome, dir))
+	case bulk.Retag:
+		if to == "" {
+			return path + " (tag removed)"
+		}
+		return path + " (#" + strings.TrimPrefix(to, "#") + ")"
+	default:
+		return path
+	}
+}
+
+// applyBulk applies op to the note n.
+func applyBulk(deps Dependencies, op bulk.Op, n scan.Note, dir, tag, to string) error {
+	switch op {
+	case bulk.Archive, bulk.Move:
+		return bulk.MoveFile(deps.FS, n.Path, bulk.Target(op, n.Path, deps.Config.Dir.DataHome, dir))
+	case bulk.Delete:
+		return deps.FS.DeleteFile(n.Path)
+	case bulk.Retag:
+		content, err := deps.FS.ReadFile(n.Path)
+		if err != nil {
+			return fmt.Errorf("failed to read note: %w", err)
+		}
+		retagged, changed, err := bulk.RenameTag(string(content), tag, to)
+		if err != nil || !changed {
+			return err
+		}
+		return deps.FS.WriteFile(n.Path, []byte(retagged))
+	}
+	return nil
+}
+
+// vaultPath returns path relative to data_home when it lies under it.
+func vaultPath(deps Dependencies, path string) string {
+	if rel, err := filepath.Rel(deps.Config.Dir.DataHome, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
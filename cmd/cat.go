@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/render"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewCatCmd returns a new cobra.Command for the "cat" command, which prints the
+// content of a note.
+func NewCatCmd(deps Dependencies) *cobra.Command {
+	var (
+		resolve bool
+		meta    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cat <note>",
+		Short: "Print the content of a note",
+		Long: `Print the content of a note, given as a path, file name, title, ID or alias.
+
+With --meta, only the YAML frontmatter is printed, without its delimiters.
+With --resolve, embeds are expanded: ![[note]] is replaced by the body of the
+note and ![[note#heading]] by the section under the heading, recursively.
+Embeds of missing notes or headings, embeds looping back to a note being
+expanded and embeds nested too deeply are printed as plain [[links]].`,
+		Example: examples(
+			ex(`exo cat "Go channels"`, "Print a note"),
+			ex(`exo cat --resolve "Weekly review" | pandoc -o review.pdf`, "Convert a note with its embeds expanded"),
+			ex(`exo cat --meta 20250208143005 | yq .tags`, "Query the frontmatter of a note"),
+		),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if meta && resolve {
+				return fmt.Errorf("--meta and --resolve cannot be used together")
+			}
+			path, err := resolveNotePath(deps, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			data, err := deps.FS.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read note: %w", err)
+			}
+			content := string(data)
+			if meta {
+				content, _ = frontmatter.Split(content)
+			}
+			if resolve {
+				_, body := frontmatter.Split(content)
+				expanded, err := noteTranscluder(deps).Expand(path, body)
+				if err != nil {
+					return err
+				}
+				content = content[:len(content)-len(body)] + expanded
+			}
+			fmt.Fprint(cmd.OutOrStdout(), content)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&resolve, "resolve", "r", false, "Expand ![[note#heading]] embeds")
+	cmd.Flags().BoolVarP(&meta, "meta", "m", false, "Print only the frontmatter")
+	return cmd
+}
+
+// noteTranscluder returns a render.Transcluder resolving embeds among the notes
+// under data_home. The notes are only listed once an embed is found.
+func noteTranscluder(deps Dependencies) *render.Transcluder {
+	var names *scan.Names
+	return &render.Transcluder{
+		Resolve: func(target string) (string, error) {
+			if names == nil {
+				notes, err := vaultNotes(deps)
+				if err != nil {
+					return "", err
+				}
+				names = scan.NewNames(deps.Config.Dir.DataHome, notes)
+			}
+			matches := names.Resolve(target)
+			if len(matches) != 1 {
+				return "", fmt.Errorf("cannot resolve %s", target)
+			}
+			return matches[0].Path, nil
+		},
+		ReadFile: deps.FS.ReadFile,
+	}
+}
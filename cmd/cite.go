@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/cite"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+const (
+	// literatureTemplate is the template literature notes are created with.
+	literatureTemplate = "literature"
+	// referencesHeading is the heading citations are listed under by "cite --into".
+	referencesHeading = "## References"
+)
+
+// NewCiteCmd returns a new cobra.Command for the "cite" command, which cites
+// an entry of the bibliography and links it to its literature note.
+func NewCiteCmd(deps Dependencies) *cobra.Command {
+	var (
+		into      string
+		style     string
+		reference bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "cite <key>",
+		Short: "Cite a bibliography entry and link its literature note",
+		Long: `Print a citation of an entry of the bibliography (cite.bibliography, a BibTeX
+.bib or CSL-JSON .json file), formatted in cite.style: apa, mla or chicago.
+
+The citation links to the literature note of the entry, <key>.md in
+dir.literature_dir, which is created from the "literature" template when
+missing. With --into the full reference is also listed under "` + referencesHeading + `"
+in a note, and the literature note is recorded in its "sources" frontmatter.`,
+		Example: examples(
+			ex("exo cite knuth1984", "Print [[knuth1984|(Knuth, 1984)]]"),
+			ex(`exo cite knuth1984 --into "Literate programming"`, "Also list the reference in a note"),
+			ex("exo cite knuth1984 --reference --style mla", "Print the full reference in MLA style"),
+		),
+		Annotations:       mutates(),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeCiteKeys(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if style == "" {
+				style = deps.Config.Cite.Style
+			}
+			citeStyle, err := cite.ParseStyle(style)
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.Usage, err)
+			}
+			entries, err := loadBibliography(deps)
+			if err != nil {
+				return err
+			}
+			entry, ok := cite.Find(entries, args[0])
+			if !ok {
+				return exoerrors.New(exoerrors.NotFound, "no entry %q in %s", args[0], deps.Config.Cite.Bibliography)
+			}
+
+			path, created, err := ensureLiteratureNote(cmd.Context(), deps, entry, citeStyle)
+			if err != nil {
+				return err
+			}
+			if created {
+				fmt.Fprintf(cmd.ErrOrStderr(), "Created %s\n", path)
+			}
+			link := strings.TrimSuffix(filepath.Base(path), scan.NoteExtension)
+
+			if into != "" {
+				target, err := resolveNotePath(deps, into)
+				if err != nil {
+					return err
+				}
+				if err := addReference(deps, target, "[["+link+"]]", cite.Reference(entry, citeStyle)); err != nil {
+					return err
+				}
+			}
+
+			if reference {
+				fmt.Fprintln(cmd.OutOrStdout(), cite.Reference(entry, citeStyle))
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "[[%s|%s]]\n", link, cite.InText(entry, citeStyle))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&into, "into", "i", "", "Also list the reference in this note")
+	cmd.Flags().StringVar(&style, "style", "", "Citation style: apa, mla or chicago (default: cite.style)")
+	cmd.Flags().BoolVar(&reference, "reference", false, "Print the full reference instead of the in-text citation")
+	_ = cmd.RegisterFlagCompletionFunc("into", completeNoteNames(deps))
+	_ = cmd.RegisterFlagCompletionFunc("style", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		styles := make([]string, len(cite.Styles))
+		for i, s := range cite.Styles {
+			styles[i] = string(s)
+		}
+		return filterPrefix(styles, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// loadBibliography reads the configured bibliography.
+func loadBibliography(deps Dependencies) ([]cite.Entry, error) {
+	if deps.Config.Cite.Bibliography == "" {
+		return nil, exoerrors.New(exoerrors.Config, "no bibliography configured (set cite.bibliography to a .bib or .json file)")
+	}
+	entries, err := cite.Load(deps.Config.Cite.Bibliography)
+	if err != nil {
+		return nil, exoerrors.Wrap(exoerrors.Config, err)
+	}
+	return entries, nil
+}
+
+// ensureLiteratureNote returns the path of the literature note of entry,
+// creating it from the literature template if it does not exist yet.
+func ensureLiteratureNote(ctx context.Context, deps Dependencies, entry cite.Entry, style cite.Style) (string, bool, error) {
+	path := filepath.Join(deps.Config.Dir.LiteratureDir, safeFileName(entry.Key)+scan.NoteExtension)
+	if deps.FS.FileExists(path) {
+		return path, false, nil
+	}
+	authors := make([]string, len(entry.Authors))
+	for i, a := range entry.Authors {
+		authors[i] = a.String()
+	}
+	content, err := deps.TemplateManager.ProcessTemplateWithContext(ctx, literatureTemplate, map[string]interface{}{
+		"Key":       entry.Key,
+		"Type":      entry.Type,
+		"Title":     entry.Title,
+		"Authors":   authors,
+		"Year":      entry.Year,
+		"Container": entry.Container,
+		"Publisher": entry.Publisher,
+		"DOI":       entry.DOI,
+		"URL":       entry.URL,
+		"Citation":  cite.InText(entry, style),
+		"Reference": cite.Reference(entry, style),
+		"Date":      time.Now().Format(dailyDateLayout),
+	})
+	if err != nil {
+		return "", false, err
+	}
+	if err := deps.FS.EnsureDirectoryExists(path); err != nil {
+		return "", false, err
+	}
+	if err := deps.FS.WriteFile(path, []byte(content)); err != nil {
+		return "", false, fmt.Errorf("failed to write literature note: %w", err)
+	}
+	return path, true, nil
+}
+
+// addReference lists reference, linked to the literature note, under the
+// references heading of the note at path and records the link as a source.
+func addReference(deps Dependencies, path, link, reference string) error {
+	data, err := deps.FS.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read note: %w", err)
+	}
+	content := string(data)
+	item := "- " + reference + " " + link
+	if !strings.Contains(content, item) {
+		content = md.AppendUnder(content, referencesHeading, item)
+	}
+	if content, err = note.AddSource(content, link); err != nil {
+		return err
+	}
+	if err := deps.FS.WriteFile(path, []byte(content)); err != nil {
+		return fmt.Errorf("failed to write note: %w", err)
+	}
+	return nil
+}
+
+// completeCiteKeys completes the keys of the bibliography entries.
+func completeCiteKeys(deps Dependencies) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		entries, err := loadBibliography(deps)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterPrefix(cite.Keys(entries), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
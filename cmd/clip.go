@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/web"
+	"github.com/a-kostevski/exo/pkg/zettel"
+)
+
+const (
+	// clipTemplate is the template new clips are rendered with.
+	clipTemplate = "clip"
+	// clipAttachmentsDir is the directory, next to a clip, raw pages are archived in.
+	clipAttachmentsDir = "attachments"
+)
+
+// NewClipCmd returns a new cobra.Command for the "clip" command, which saves the
+// readable content of a web page as a note in the inbox.
+func NewClipCmd(deps Dependencies) *cobra.Command {
+	var (
+		title       string
+		archiveHTML bool
+		edit        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clip <url>",
+		Short: "Save the readable content of a web page as an inbox note",
+		Example: examples(
+			ex("exo clip https://example.com/essay", "Save a web page as an inbox note"),
+			ex(`exo clip --archive-html --title "Essay on notes" https://example.com/essay`, "Keep the raw page and choose the title"),
+		),
+		Annotations: mutates(),
+		Long: `Fetch a web page, extract its title and readable text, and save them as a new
+note in the inbox using the "clip" template, which records the source URL and the
+capture date. Install the default templates to customize it.
+
+With --archive-html the raw page is also kept as an attachment next to the note.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rawURL := args[0]
+			if err := web.ValidateURL(rawURL); err != nil {
+				return err
+			}
+			page, err := web.NewClient().FetchPage(cmd.Context(), rawURL)
+			if err != nil {
+				return err
+			}
+			if title == "" {
+				title = page.Title
+			}
+			if title == "" {
+				u, _ := url.Parse(rawURL)
+				title = strings.TrimPrefix(u.Host, "www.") + strings.TrimSuffix(u.Path, "/")
+			}
+
+			clip, err := zettel.NewZettelNote(safeFileName(title), *deps.Config, deps.TemplateManager, deps.Logger, deps.FS,
+				note.WithContext(cmd.Context()), note.WithTemplateName(clipTemplate))
+			if err != nil {
+				return fmt.Errorf("failed to create clip: %w", err)
+			}
+			if clip.Exists() {
+				return exoerrors.New(exoerrors.Conflict, "note %s already exists", clip.Path())
+			}
+
+			var archivePath, archiveRef string
+			if archiveHTML {
+				stem := strings.TrimSuffix(filepath.Base(clip.Path()), scan.NoteExtension)
+				archiveRef = clipAttachmentsDir + "/" + stem + ".html"
+				archivePath = filepath.Join(filepath.Dir(clip.Path()), clipAttachmentsDir, stem+".html")
+			}
+
+			content, err := clipContent(cmd.Context(), deps, map[string]interface{}{
+				"Title":    title,
+				"URL":      rawURL,
+				"Captured": time.Now().Format("2006-01-02"),
+				"Archive":  archiveRef,
+				"Content":  page.Text,
+			})
+			if err != nil {
+				return err
+			}
+			if content, err = focusContent(content); err != nil {
+				return err
+			}
+			if err := clip.SetContent(content); err != nil {
+				return err
+			}
+			if err := clip.Save(); err != nil {
+				return fmt.Errorf("failed to save clip: %w", err)
+			}
+			if archivePath != "" {
+				if err := deps.FS.EnsureDirectoryExists(archivePath); err != nil {
+					return err
+				}
+				if err := deps.FS.WriteFile(archivePath, page.HTML); err != nil {
+					return fmt.Errorf("failed to archive page: %w", err)
+				}
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), clip.Path())
+			if edit {
+				return clip.Open()
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&title, "title", "t", "", "Title of the note (default: the page title)")
+	cmd.Flags().BoolVar(&archiveHTML, "archive-html", false, "Keep the raw page as an attachment")
+	cmd.Flags().BoolVarP(&edit, "edit", "e", false, "Open the clip in the editor")
+	return cmd
+}
+
+// clipContent renders the clip template from the template directory, falling back
+// to the built-in default when it has not been installed.
+func clipContent(ctx context.Context, deps Dependencies, data map[string]interface{}) (string, error) {
+	return deps.TemplateManager.ProcessTemplateWithContext(ctx, clipTemplate, data)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/recent"
+	"github.com/a-kostevski/exo/pkg/suggest"
+)
+
+// NewCompleteCmd returns a new cobra.Command for the "complete" command, which
+// ranks the completions of links and tags for shells and editor plugins.
+func NewCompleteCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "complete",
+		Short: "Rank completions of links and tags",
+		Long: `Print the notes a link being typed may point to, or the tags a tag being typed
+may be, best first, as JSON for editor plugins or as names for shells.
+
+Names matching exactly rank first, then names starting with what was typed,
+names with a word starting with it, names containing it and names containing
+its letters in order. Notes used often and recently (see "exo recent") rank
+higher, as do tags carried by many notes.`,
+		Example: examples(
+			ex("exo complete links go", "Rank the notes a [[go link may point to"),
+			ex("exo complete tags proj --format names", "List the tags starting with proj"),
+		),
+	}
+	cmd.AddCommand(newCompleteLinksCmd(deps), newCompleteTagsCmd(deps))
+	return cmd
+}
+
+// completeOptions are the flags shared by the subcommands of "complete".
+type completeOptions struct {
+	limit  int
+	format string
+}
+
+func (o *completeOptions) register(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&o.limit, "limit", "n", 20, "Print at most this many candidates (0 for all)")
+	cmd.Flags().StringVarP(&o.format, "format", "f", "json", "Output format: json or names")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"json", "names"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+func newCompleteLinksCmd(deps Dependencies) *cobra.Command {
+	var opts completeOptions
+	cmd := &cobra.Command{
+		Use:   "links [prefix]",
+		Short: "Rank the notes a link may point to",
+		Long: `Print the notes whose file name, title, ID or alias matches prefix, best first.
+A candidate's name is the file name to write in the link, without extension.`,
+		Example: examples(
+			ex("exo complete links chan", "Rank the notes matching chan"),
+			ex("exo complete links", "Rank every note by use"),
+		),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			notes, err := indexedNotes(deps)
+			if err != nil {
+				return err
+			}
+			typed := ""
+			if len(args) > 0 {
+				typed = args[0]
+			}
+			return writeCandidates(cmd.OutOrStdout(), suggest.Links(notes, deps.Config.Dir.DataHome, typed, noteFrecency(deps)), opts)
+		},
+	}
+	opts.register(cmd)
+	return cmd
+}
+
+func newCompleteTagsCmd(deps Dependencies) *cobra.Command {
+	var opts completeOptions
+	cmd := &cobra.Command{
+		Use:     "tags [prefix]",
+		Short:   "Rank the tags a tag may be",
+		Long:    `Print the tags matching prefix, a leading # ignored, best first.`,
+		Example: examples(ex("exo complete tags '#pro'", "Rank the tags matching pro")),
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			notes, err := indexedNotes(deps)
+			if err != nil {
+				return err
+			}
+			typed := ""
+			if len(args) > 0 {
+				typed = args[0]
+			}
+			return writeCandidates(cmd.OutOrStdout(), suggest.Tags(notes, typed, noteFrecency(deps)), opts)
+		},
+	}
+	opts.register(cmd)
+	return cmd
+}
+
+// writeCandidates writes at most opts.limit candidates to w in opts.format.
+func writeCandidates(w io.Writer, candidates []suggest.Candidate, opts completeOptions) error {
+	if opts.limit > 0 && len(candidates) > opts.limit {
+		candidates = candidates[:opts.limit]
+	}
+	switch opts.format {
+	case "json":
+		if candidates == nil {
+			candidates = []suggest.Candidate{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(candidates)
+	case "names":
+		for _, c := range candidates {
+			fmt.Fprintln(w, c.Name)
+		}
+		return nil
+	default:
+		return exoerrors.New(exoerrors.Usage, "unknown format %q (expected json or names)", opts.format)
+	}
+}
+
+// noteFrecency returns the frecency of the use of notes, or nil when the
+// recent notes are unavailable.
+func noteFrecency(deps Dependencies) suggest.Frecency {
+	store, err := recent.OpenStore(deps.Config.Dir.StatePath(recent.File))
+	if err != nil {
+		deps.Logger.Errorf("Recent notes unavailable: %v", err)
+		return nil
+	}
+	now := time.Now()
+	return func(path string) float64 {
+		return store.Entries[path].Frecency(now)
+	}
+}
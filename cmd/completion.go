@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewCompletionCmd creates the "completion" command, which prints shell completion scripts.
+func NewCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <bash|zsh|fish|powershell>",
+		Short: "Generate the shell completion script",
+		Example: examples(
+			ex("source <(exo completion bash)", "Load completions into the current bash session"),
+			ex(`exo completion zsh > "${fpath[1]}/_exo"`, "Install completions for zsh"),
+			ex("exo completion fish > ~/.config/fish/completions/exo.fish", "Install completions for fish"),
+		),
+		Long: `Generate the completion script for the given shell.
+
+Completions include note names, config keys, tags and aliases.`,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			case "powershell":
+				return root.GenPowerShellCompletionWithDesc(out)
+			default:
+				return fmt.Errorf("unsupported shell %q (expected bash, zsh, fish or powershell)", args[0])
+			}
+		},
+	}
+}
+
+// isCompletionCmd reports whether cmd generates or serves shell completions,
+// in which case nothing but completion output may be written to stdout.
+func isCompletionCmd(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		switch c.Name() {
+		case cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd, "completion":
+			return true
+		}
+	}
+	return false
+}
+
+// completionFunc is the signature of cobra argument and flag completion functions.
+type completionFunc func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeNoteNames completes note names (file names without extension) found under
+// data_home, annotated with their title and a preview of their content.
+func completeNoteNames(deps Dependencies) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var completions []string
+		notes, _ := vaultNotes(deps)
+		for _, n := range notes {
+			name := strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension)
+			if !strings.HasPrefix(strings.ToLower(name), strings.ToLower(toComplete)) {
+				continue
+			}
+			var description []string
+			if n.Title != name {
+				description = append(description, n.Title)
+			}
+			if snippet := scan.Snippet(n.Excerpt, deps.Config.Search.SnippetLength); snippet != "" {
+				description = append(description, snippet)
+			}
+			if len(description) > 0 {
+				completions = append(completions, name+"\t"+strings.Join(description, " — "))
+			} else {
+				completions = append(completions, name)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeConfigKeys completes configuration keys, including configured aliases
+// and template packs.
+func completeConfigKeys(deps Dependencies) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		keys := append([]string{}, configKeys...)
+		for _, name := range deps.Config.AliasNames() {
+			keys = append(keys, "alias."+name)
+		}
+		for _, name := range deps.Config.PackNames() {
+			keys = append(keys, "templates.packs."+name)
+		}
+		return filterPrefix(keys, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeConfigCollections completes the list and map settings of "config
+// add" and "config remove", then for "config remove" their items.
+func completeConfigCollections(deps Dependencies) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		switch {
+		case len(args) == 0:
+			keys := append(slices.Clone(configListKeys), configMapKeys...)
+			return filterPrefix(keys, toComplete), cobra.ShellCompDirectiveNoFileComp
+		case len(args) == 1 && cmd.Name() == "remove":
+			if l := configList(deps.Config, args[0]); l != nil {
+				return filterPrefix(*l, toComplete), cobra.ShellCompDirectiveNoFileComp
+			}
+			if m := configMap(deps.Config, args[0]); m != nil {
+				return filterPrefix(slices.Sorted(maps.Keys(*m)), toComplete), cobra.ShellCompDirectiveNoFileComp
+			}
+		}
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeTags completes tags used by notes under data_home.
+func completeTags(deps Dependencies) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		notes, err := vaultNotes(deps)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		seen := make(map[string]bool)
+		var tags []string
+		for _, n := range notes {
+			for _, tag := range n.Tags {
+				if !seen[tag] {
+					seen[tag] = true
+					tags = append(tags, tag)
+				}
+			}
+		}
+		return filterPrefix(tags, strings.TrimPrefix(toComplete, "#")), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// filterPrefix returns the values starting with prefix.
+func filterPrefix(values []string, prefix string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.HasPrefix(v, prefix) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,76 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// complete runs the hidden completion command for args in a vault holding
+// files, named by slash-separated path, and returns the candidates.
+func complete(t *testing.T, files map[string]string, args ...string) []string {
+	t.Helper()
+	dataHome := t.TempDir()
+	for path, content := range files {
+		full := filepath.Join(dataHome, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(dataHome)
+	t.Cleanup(cleanup)
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+	root := cmd.NewRootCmd(deps)
+	root.AddCommand(cmd.NewCatCmd(deps), cmd.NewMOCCmd(deps), cmd.NewTemplateCmd(deps))
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{"__completeNoDesc"}, args...))
+	require.NoError(t, root.Execute())
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	// The last line is the completion directive.
+	return lines[:len(lines)-1]
+}
+
+func TestComplete_NoteNames(t *testing.T) {
+	files := map[string]string{
+		"zettel/alpha.md":       "# Alpha\n",
+		"zettel/Almond.md":      "# Almond\n",
+		"0-inbox/beta.md":       "# Beta\n",
+		"templates/alarm.md":    "# {{.Title}}\n",
+		".trash/alpine.md":      "# Alpine\n",
+		"zettel/attachment.png": "",
+	}
+	assert.ElementsMatch(t, []string{"alpha", "Almond"}, complete(t, files, "cat", "al"))
+	assert.ElementsMatch(t, []string{"alpha", "Almond", "beta"}, complete(t, files, "cat", ""))
+}
+
+func TestComplete_Tags(t *testing.T) {
+	files := map[string]string{
+		"zettel/go.md":       "---\ntags: [golang, lang]\n---\n# Go\n",
+		"zettel/rust.md":     "# Rust\n\n#lang #systems\n",
+		"templates/tmpl.md":  "# T\n\n#template-only\n",
+		"zettel/nothing.md":  "# Nothing\n",
+		"zettel/graphics.md": "# Graphics\n\n#gpu\n",
+	}
+	assert.ElementsMatch(t, []string{"golang", "gpu"}, complete(t, files, "moc", "g"))
+	assert.ElementsMatch(t, []string{"lang"}, complete(t, files, "moc", "#la"), "a leading # is ignored")
+	assert.ElementsMatch(t, []string{"golang", "lang", "systems", "gpu"}, complete(t, files, "moc", ""))
+}
+
+func TestComplete_TemplateNames(t *testing.T) {
+	files := map[string]string{
+		"templates/daily-review.md": "# Review\n",
+		"templates/notes.txt":       "not a template",
+	}
+	got := complete(t, files, "template", "which", "d")
+	assert.Equal(t, []string{"daily-review", "day"}, got, "custom templates and the built-in defaults, sorted")
+	assert.Empty(t, complete(t, files, "template", "which", "notes"))
+}
@@ -1,12 +1,24 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
 	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/plugin"
+	"github.com/a-kostevski/exo/pkg/prompts"
+	"github.com/a-kostevski/exo/pkg/slug"
 )
 
 // NewConfigCmd creates a new "config" command with subcommands "get" and "set".
@@ -14,66 +26,563 @@ func NewConfigCmd(deps Dependencies) *cobra.Command {
 	configCmd := &cobra.Command{
 		Use:   "config",
 		Short: "Manage configuration",
+		Example: examples(
+			ex("exo config", "Show the whole configuration"),
+		),
 		Long: `Manage exo configuration settings.
 
-Without arguments, lists all configuration settings.
+Without arguments, or with "show", lists all configuration settings.
 Use "get" to retrieve a specific setting.
-Use "set" to modify a specific setting.`,
-		Run: func(cmd *cobra.Command, args []string) {
+Use "set" to modify a specific setting, and "unset" to restore its default.
+Use "add" and "remove" to change the items of a list or map setting.
+Use "doctor" to find out what is wrong with the configuration.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
 			// Simply print the configuration.
-			fmt.Println(deps.Config)
+			fmt.Fprintln(cmd.OutOrStdout(), deps.Config)
+			return nil
 		},
 	}
+	configCmd.AddCommand(newConfigShowCmd(deps))
 	configCmd.AddCommand(NewConfigGetCmd(deps))
 	configCmd.AddCommand(NewConfigSetCmd(deps))
+	configCmd.AddCommand(newConfigUnsetCmd(deps))
+	configCmd.AddCommand(newConfigAddCmd(deps))
+	configCmd.AddCommand(newConfigRemoveCmd(deps))
+	configCmd.AddCommand(newConfigDoctorCmd(deps))
 	return configCmd
 }
 
+func newConfigShowCmd(deps Dependencies) *cobra.Command {
+	var origin bool
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the whole configuration",
+		Long: `Show the whole configuration.
+
+With --origin, every setting is listed with where its value came from: the
+built-in default, the line of the configuration file setting it, the
+environment variable overriding it, such as EDITOR or EXO_DATA_HOME, or the
+command-line flag, such as --read-only.`,
+		Example: examples(
+			ex("exo config show", "Show every setting, including the state and cache directories"),
+			ex("exo config show --origin", "Show where each setting comes from"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if origin {
+				writeConfigOrigins(cmd.OutOrStdout(), deps.Config)
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), deps.Config)
+			return nil
+		},
+	}
+	showCmd.Flags().BoolVar(&origin, "origin", false, "Show where each value comes from")
+	return showCmd
+}
+
+// writeConfigOrigins writes the value and origin of every configuration key,
+// including the aliases and template packs, as a table.
+func writeConfigOrigins(w io.Writer, cfg *config.Config) {
+	keys := slices.Clone(configKeys)
+	for name := range cfg.Alias {
+		keys = append(keys, "alias."+name)
+	}
+	for name := range cfg.Templates.Packs {
+		keys = append(keys, "templates.packs."+name)
+	}
+	slices.Sort(keys[len(configKeys):])
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE\tORIGIN")
+	for _, key := range keys {
+		value := strings.ReplaceAll(getConfigValue(cfg, key), "\n", ", ")
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", key, value, cfg.Origin(originKey(key)))
+	}
+	tw.Flush()
+}
+
+// originKey returns the key of the configuration file that sets the
+// "config get" key, e.g. "general.editor" for "editor".
+func originKey(key string) string {
+	key = strings.ToLower(key)
+	for _, k := range configKeys {
+		if strings.ReplaceAll(k, "_", "") == strings.ReplaceAll(key, "_", "") {
+			key = k
+			break
+		}
+	}
+	switch {
+	case strings.Contains(key, "."):
+		return key
+	case key == "editor" || key == "read_only" || key == "timestamps" || key == "versions" || key == "on_conflict":
+		return "general." + key
+	}
+	return "dir." + key
+}
+
 func NewConfigGetCmd(deps Dependencies) *cobra.Command {
-	return &cobra.Command{
+	var origin bool
+	getCmd := &cobra.Command{
 		Use:   "get [key]",
 		Short: "Get a configuration value",
-		Args:  cobra.ExactArgs(1),
-		Run: func(cmd *cobra.Command, args []string) {
+		Example: examples(
+			ex("exo config get data_home", "Print the data home directory"),
+			ex("exo config get editor --origin", "Print the editor and where it is set"),
+		),
+		Args: cobra.ExactArgs(1),
+		// Complete configuration keys.
+		ValidArgsFunction: completeConfigKeys(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
 			value := getConfigValue(deps.Config, key)
 			if value == "" {
-				deps.Logger.Errorf("Invalid configuration key: %s", key)
-				return
+				cmd.SilenceUsage = true
+				return exoerrors.New(exoerrors.Usage, "invalid configuration key: %s", key)
 			}
-			fmt.Printf("%s: %s\n", key, value)
+			if origin {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: %s (%s)\n", key, value, deps.Config.Origin(originKey(key)))
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", key, value)
+			return nil
 		},
 	}
+	getCmd.Flags().BoolVar(&origin, "origin", false, "Show where the value comes from")
+	return getCmd
 }
 
 func NewConfigSetCmd(deps Dependencies) *cobra.Command {
 	return &cobra.Command{
 		Use:   "set [key] [value]",
 		Short: "Set a configuration value",
-		Args:  cobra.ExactArgs(2),
-		Run: func(cmd *cobra.Command, args []string) {
+		Example: examples(
+			ex(`exo config set editor "code -w"`, "Use VS Code as the editor"),
+			ex(`exo config set alias.t "zet list --tag todo"`, "Define an alias"),
+		),
+		Args: cobra.ExactArgs(2),
+		// Complete configuration keys.
+		ValidArgsFunction: completeConfigKeys(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
 			key := args[0]
 			value := args[1]
+			cmd.SilenceUsage = true
 			if !setConfigValue(deps.Config, key, value) {
-				deps.Logger.Errorf("Invalid configuration key: %s", key)
-				return
+				return exoerrors.New(exoerrors.Usage, "invalid configuration key: %s", key)
+			}
+			if err := deps.Config.Save(); err != nil {
+				return exoerrors.Wrap(exoerrors.Config, fmt.Errorf("failed to save configuration: %w", err))
+			}
+			deps.Logger.Info("Configuration updated successfully")
+			fmt.Fprintf(cmd.OutOrStdout(), "Set %s to %s\n", key, value)
+			return nil
+		},
+	}
+}
+
+func newConfigUnsetCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "unset [key]",
+		Short: "Restore the default of a configuration value",
+		Example: examples(
+			ex("exo config unset editor", "Go back to $EDITOR, or the default editor"),
+			ex("exo config unset alias.t", "Remove an alias"),
+		),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeConfigKeys(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+			cmd.SilenceUsage = true
+			defaults, err := config.Defaults(fs.OSEnv())
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.Config, fmt.Errorf("failed to load the default configuration: %w", err))
+			}
+			if !unsetConfigValue(deps.Config, defaults, key) {
+				return exoerrors.New(exoerrors.Usage, "invalid configuration key: %s", key)
+			}
+			if err := deps.Config.Save(); err != nil {
+				return exoerrors.Wrap(exoerrors.Config, fmt.Errorf("failed to save configuration: %w", err))
+			}
+			deps.Logger.Info("Configuration updated successfully")
+			if value := getConfigValue(deps.Config, key); value != "" {
+				fmt.Fprintf(cmd.OutOrStdout(), "Reset %s to %s\n", key, value)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Unset %s\n", key)
+			return nil
+		},
+	}
+}
+
+// unsetConfigValue restores the value of key in cfg to the one in defaults.
+// Aliases and template packs are removed; the state and cache directories
+// are cleared, to be derived from the data home again when loaded.
+func unsetConfigValue(cfg, defaults *config.Config, key string) bool {
+	key = strings.ToLower(key)
+	if m := configMap(cfg, key); m != nil {
+		// The whole map: remove every entry.
+		*m = nil
+		return true
+	}
+	for _, prefix := range []string{"alias.", "templates.packs."} {
+		if name, ok := strings.CutPrefix(key, prefix); ok {
+			m := configMap(cfg, strings.TrimSuffix(prefix, "."))
+			if _, exists := (*m)[name]; !exists {
+				return false
+			}
+			delete(*m, name)
+			return true
+		}
+	}
+	if l := configList(cfg, key); l != nil {
+		*l = slices.Clone(*configList(defaults, key))
+		return true
+	}
+	switch originKey(key) {
+	case "dir.state_dir", "dir.cache_dir":
+		return setConfigValue(cfg, key, "")
+	}
+	return setConfigValue(cfg, key, getConfigValue(defaults, key))
+}
+
+func newConfigAddCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add [key] [value]",
+		Short: "Add an item to a list or map configuration value",
+		Long: `Add an item to a list configuration value, such as watch.hooks, or an entry
+to a map, such as alias or templates.packs, given as name=value.
+
+The list settings are ` + strings.Join(configListKeys, ", ") + `;
+the map settings are alias and templates.packs.`,
+		Example: examples(
+			ex(`exo config add watch.hooks "exo index update"`, "Run another hook on changes"),
+			ex(`exo config add lint.disabled long-note`, "Disable another lint rule"),
+			ex(`exo config add templates.packs work=~/packs/work`, "Install a template pack"),
+		),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigCollections(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			cmd.SilenceUsage = true
+			if err := addConfigValue(deps.Config, key, value); err != nil {
+				return err
+			}
+			if err := deps.Config.Save(); err != nil {
+				return exoerrors.Wrap(exoerrors.Config, fmt.Errorf("failed to save configuration: %w", err))
+			}
+			deps.Logger.Info("Configuration updated successfully")
+			fmt.Fprintf(cmd.OutOrStdout(), "Added %s to %s\n", value, key)
+			return nil
+		},
+	}
+}
+
+func newConfigRemoveCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove [key] [value]",
+		Short: "Remove an item from a list or map configuration value",
+		Long: `Remove an item from a list configuration value, or the entry of a map by
+name.`,
+		Example: examples(
+			ex(`exo config remove watch.hooks "exo index update"`, "Stop running a hook"),
+			ex(`exo config remove templates.packs work`, "Uninstall a template pack"),
+		),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeConfigCollections(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, value := args[0], args[1]
+			cmd.SilenceUsage = true
+			if err := removeConfigValue(deps.Config, key, value); err != nil {
+				return err
 			}
 			if err := deps.Config.Save(); err != nil {
-				deps.Logger.Errorf("Failed to save configuration: %v", err)
-				return
+				return exoerrors.Wrap(exoerrors.Config, fmt.Errorf("failed to save configuration: %w", err))
 			}
 			deps.Logger.Info("Configuration updated successfully")
-			fmt.Printf("Set %s to %s\n", key, value)
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed %s from %s\n", value, key)
+			return nil
 		},
 	}
 }
 
+// configListKeys lists the list settings "config add" and "config remove"
+// change item by item.
+var configListKeys = []string{"watch.hooks", "periodic.daily.providers", "lint.disabled", "habits", "ignore"}
+
+// configMapKeys lists the map settings "config add" and "config remove"
+// change entry by entry.
+var configMapKeys = []string{"alias", "templates.packs"}
+
+// configList returns the list setting key of cfg, or nil.
+func configList(cfg *config.Config, key string) *[]string {
+	switch strings.ToLower(key) {
+	case "watch.hooks":
+		return &cfg.Watch.Hooks
+	case "periodic.daily.providers":
+		return &cfg.Periodic.Daily.Providers
+	case "lint.disabled":
+		return &cfg.Lint.Disabled
+	case "habits":
+		return &cfg.Habits
+	case "ignore":
+		return &cfg.Ignore
+	}
+	return nil
+}
+
+// configMap returns the map setting key of cfg, or nil.
+func configMap(cfg *config.Config, key string) *map[string]string {
+	switch strings.ToLower(key) {
+	case "alias":
+		return &cfg.Alias
+	case "templates.packs":
+		return &cfg.Templates.Packs
+	}
+	return nil
+}
+
+// addConfigValue adds value to the list setting key, unless it has it, or
+// the name=value entry to the map setting key.
+func addConfigValue(cfg *config.Config, key, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return exoerrors.New(exoerrors.Usage, "cannot add an empty value to %s", key)
+	}
+	if l := configList(cfg, key); l != nil {
+		if slices.Contains(*l, value) {
+			return exoerrors.New(exoerrors.Conflict, "%s already has %s", key, value)
+		}
+		*l = append(*l, value)
+		return nil
+	}
+	m := configMap(cfg, key)
+	if m == nil {
+		return exoerrors.New(exoerrors.Usage, "%s is not a list or map setting; use one of %s",
+			key, strings.Join(append(slices.Clone(configListKeys), configMapKeys...), ", "))
+	}
+	name, entry, ok := strings.Cut(value, "=")
+	if name, entry = strings.TrimSpace(name), strings.TrimSpace(entry); !ok || name == "" || entry == "" {
+		return exoerrors.New(exoerrors.Usage, "the entries of %s are given as name=value", key)
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[name] = entry
+	return nil
+}
+
+// removeConfigValue removes value from the list setting key, or the entry
+// named value from the map setting key.
+func removeConfigValue(cfg *config.Config, key, value string) error {
+	if l := configList(cfg, key); l != nil {
+		if !slices.Contains(*l, value) {
+			return exoerrors.New(exoerrors.NotFound, "%s does not have %s", key, value)
+		}
+		*l = slices.DeleteFunc(*l, func(item string) bool { return item == value })
+		return nil
+	}
+	m := configMap(cfg, key)
+	if m == nil {
+		return exoerrors.New(exoerrors.Usage, "%s is not a list or map setting; use one of %s",
+			key, strings.Join(append(slices.Clone(configListKeys), configMapKeys...), ", "))
+	}
+	if _, ok := (*m)[value]; !ok {
+		return exoerrors.New(exoerrors.NotFound, "%s does not have %s", key, value)
+	}
+	delete(*m, value)
+	return nil
+}
+
+func newConfigDoctorCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the configuration for problems",
+		Long: `Check that the configuration loads, that its directories exist, that the
+templates can be used, that the editor can be found and that the plugins
+load, and suggest fixes.
+
+Unlike other commands, it runs when the configuration file is broken. The
+command fails when a problem is found; warnings do not count.`,
+		Example: examples(
+			ex("exo config doctor", "Check the configuration"),
+			ex("exo config doctor -c ~/work.yaml", "Check another configuration file"),
+		),
+		Args:        cobra.NoArgs,
+		Annotations: tolerant(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			problems := 0
+			fail := func(format string, args ...interface{}) {
+				problems++
+				fmt.Fprintf(out, "fail  "+format+"\n", args...)
+			}
+			warn := func(format string, args ...interface{}) {
+				fmt.Fprintf(out, "warn  "+format+"\n", args...)
+			}
+			ok := func(format string, args ...interface{}) {
+				fmt.Fprintf(out, "ok    "+format+"\n", args...)
+			}
+
+			var configErr error
+			if deps.container != nil {
+				_, configErr = deps.container.Config()
+			}
+			var se *StartupError
+			switch {
+			case errors.As(configErr, &se):
+				fail("configuration %s: %v", se.Target, se.Err)
+				fmt.Fprintf(out, "      %s\n", se.Hint)
+				fmt.Fprintln(out, "      The checks below use the defaults.")
+			case configErr != nil:
+				fail("configuration: %v", configErr)
+			case deps.Config.Source() == "":
+				ok("configuration: no file, using the defaults")
+			default:
+				ok("configuration %s", deps.Config.Source())
+			}
+
+			dirs := []struct{ key, dir string }{
+				{"data_home", deps.Config.Dir.DataHome},
+				{"template_dir", deps.Config.Dir.TemplateDir},
+				{"periodic_dir", deps.Config.Dir.PeriodicDir},
+				{"zettel_dir", deps.Config.Dir.ZettelDir},
+				{"state_dir", deps.Config.Dir.StateDir},
+				{"cache_dir", deps.Config.Dir.CacheDir},
+			}
+			for _, d := range dirs {
+				info, err := os.Stat(d.dir)
+				switch {
+				case os.IsNotExist(err):
+					warn("%s %s does not exist; run \"exo init\" or it is created when first used", d.key, d.dir)
+				case err != nil:
+					fail("%s %s: %v", d.key, d.dir, err)
+				case !info.IsDir():
+					fail("%s %s is not a directory; set %s to a directory", d.key, d.dir, originKey(d.key))
+				default:
+					ok("%s %s", d.key, d.dir)
+				}
+			}
+
+			if deps.container != nil && configErr == nil {
+				if _, err := deps.container.TemplateManager(); err != nil {
+					fail("templates: %v", err)
+				} else {
+					ok("templates")
+				}
+			}
+
+			env := fs.OSEnv()
+			if editor, err := env.EditorCommand(deps.Config.General.Editor, ""); err != nil {
+				fail("editor: %v; set general.editor or $EDITOR", err)
+			} else if _, err := exec.LookPath(editor[0]); err != nil {
+				fail("editor %q not found on PATH; set general.editor or $EDITOR", editor[0])
+			} else {
+				ok("editor %s (%s)", deps.Config.General.Editor, deps.Config.Origin("general.editor"))
+			}
+
+			if _, err := plugin.Discover(cmd.Context(), deps.Config.Dir.PluginDir); err != nil {
+				warn("plugins: %v; see \"exo plugin list\"", err)
+			} else {
+				ok("plugins %s", deps.Config.Dir.PluginDir)
+			}
+
+			if problems > 0 {
+				cmd.SilenceUsage = true
+				return exoerrors.New(exoerrors.Config, "found %d configuration problem(s)", problems)
+			}
+			return nil
+		},
+	}
+}
+
+// configKeys lists the canonical keys accepted by "config get" and "config set".
+var configKeys = []string{
+	"editor",
+	"read_only",
+	"timestamps",
+	"versions",
+	"on_conflict",
+	"data_home",
+	"template_dir",
+	"periodic_dir",
+	"zettel_dir",
+	"archive_dir",
+	"literature_dir",
+	"state_dir",
+	"cache_dir",
+	"log.level",
+	"log.format",
+	"log.output",
+	"daily.log_heading",
+	"sync.remote",
+	"sync.auto_commit",
+	"templates.file_mode",
+	"templates.dir_mode",
+	"templates.profile_dir",
+	"search.snippet_length",
+	"periodic.daily.carry_over",
+	"periodic.daily.providers",
+	"periodic.daily.link_week",
+	"periodic.daily.link_month",
+	"periodic.daily.link_quarter",
+	"periodic.weekly.link_days",
+	"watch.hooks",
+	"calendar.source",
+	"calendar.username",
+	"location.name",
+	"location.latitude",
+	"location.longitude",
+	"weather.url",
+	"weather.units",
+	"prompts.path",
+	"prompts.count",
+	"prompts.order",
+	"cite.bibliography",
+	"cite.style",
+	"book.provider",
+	"book.url",
+	"lint.disabled",
+	"lint.max_words",
+	"lint.todo_days",
+	"slug.style",
+	"slug.max_length",
+	"slug.stop_words",
+	"slug.date_prefix",
+	"export.pdf.engine",
+	"export.pdf.page_size",
+	"export.pdf.css",
+	"export.pdf.template",
+	"export.pandoc",
+	"backup.remote",
+	"backup.encrypt",
+	"backup.s3.endpoint",
+	"backup.s3.region",
+	"backup.s3.bucket",
+	"backup.s3.prefix",
+	"backup.webdav.url",
+	"backup.webdav.username",
+	"backup.snapshot_dir",
+	"backup.keep.last",
+	"backup.keep.daily",
+	"backup.keep.weekly",
+	"backup.keep.monthly",
+	"backup.keep.yearly",
+	"ignore",
+}
+
 // getConfigValue returns the configuration value for a given key.
 func getConfigValue(cfg *config.Config, key string) string {
 	key = strings.ToLower(key)
 	switch key {
 	case "editor":
 		return cfg.General.Editor
+	case "read_only", "readonly":
+		return strconv.FormatBool(cfg.General.ReadOnly)
+	case "timestamps":
+		return strconv.FormatBool(cfg.General.Timestamps)
+	case "versions":
+		return strconv.Itoa(cfg.General.Versions)
+	case "on_conflict", "onconflict":
+		return cfg.General.OnConflict
 	case "data_home", "datahome":
 		return cfg.Dir.DataHome
 	case "template_dir", "templatedir":
@@ -82,13 +591,137 @@ func getConfigValue(cfg *config.Config, key string) string {
 		return cfg.Dir.PeriodicDir
 	case "zettel_dir", "zetteldir":
 		return cfg.Dir.ZettelDir
+	case "archive_dir", "archivedir":
+		return cfg.Dir.ArchiveDir
+	case "literature_dir", "literaturedir":
+		return cfg.Dir.LiteratureDir
+	case "state_dir", "statedir":
+		return cfg.Dir.StateDir
+	case "cache_dir", "cachedir":
+		return cfg.Dir.CacheDir
 	case "log.level", "loglevel":
 		return cfg.Log.Level
 	case "log.format", "logformat":
 		return cfg.Log.Format
 	case "log.output", "logoutput":
 		return cfg.Log.Output
+	case "daily.log_heading", "logheading":
+		return cfg.Daily.LogHeading
+	case "sync.remote":
+		return cfg.Sync.Remote
+	case "sync.auto_commit":
+		return strconv.FormatBool(cfg.Sync.AutoCommit)
+	case "templates.file_mode":
+		return cfg.Templates.FileMode
+	case "templates.dir_mode":
+		return cfg.Templates.DirMode
+	case "templates.profile_dir":
+		return cfg.Templates.ProfileDir
+	case "search.snippet_length":
+		return strconv.Itoa(cfg.Search.SnippetLength)
+	case "periodic.daily.carry_over":
+		return strconv.Itoa(cfg.Periodic.Daily.CarryOver)
+	case "periodic.daily.providers":
+		return strings.Join(cfg.Periodic.Daily.Providers, ",")
+	case "periodic.daily.link_week":
+		return strconv.FormatBool(cfg.Periodic.Daily.LinkWeek)
+	case "periodic.daily.link_month":
+		return strconv.FormatBool(cfg.Periodic.Daily.LinkMonth)
+	case "periodic.daily.link_quarter":
+		return strconv.FormatBool(cfg.Periodic.Daily.LinkQuarter)
+	case "periodic.weekly.link_days":
+		return strconv.FormatBool(cfg.Periodic.Weekly.LinkDays)
+	case "watch.hooks":
+		return strings.Join(cfg.Watch.Hooks, "\n")
+	case "calendar.source":
+		return cfg.Calendar.Source
+	case "calendar.username":
+		return cfg.Calendar.Username
+	case "location.name":
+		return cfg.Location.Name
+	case "location.latitude":
+		return strconv.FormatFloat(cfg.Location.Latitude, 'g', -1, 64)
+	case "location.longitude":
+		return strconv.FormatFloat(cfg.Location.Longitude, 'g', -1, 64)
+	case "weather.url":
+		return cfg.Weather.URL
+	case "weather.units":
+		return cfg.Weather.Units
+	case "prompts.path":
+		return cfg.Prompts.Path
+	case "prompts.count":
+		return strconv.Itoa(cfg.Prompts.Count)
+	case "prompts.order":
+		return cfg.Prompts.Order
+	case "cite.bibliography":
+		return cfg.Cite.Bibliography
+	case "cite.style":
+		return cfg.Cite.Style
+	case "lint.disabled":
+		return strings.Join(cfg.Lint.Disabled, ",")
+	case "ignore":
+		return strings.Join(cfg.Ignore, ",")
+	case "lint.max_words":
+		return strconv.Itoa(cfg.Lint.MaxWords)
+	case "lint.todo_days":
+		return strconv.Itoa(cfg.Lint.TodoDays)
+	case "slug.style":
+		return cfg.Slug.Style
+	case "slug.max_length":
+		return strconv.Itoa(cfg.Slug.MaxLength)
+	case "slug.stop_words":
+		return strconv.FormatBool(cfg.Slug.StopWords)
+	case "slug.date_prefix":
+		return cfg.Slug.DatePrefix
+	case "export.pdf.engine":
+		return cfg.Export.PDF.Engine
+	case "export.pdf.page_size":
+		return cfg.Export.PDF.PageSize
+	case "export.pdf.css":
+		return cfg.Export.PDF.CSS
+	case "export.pdf.template":
+		return cfg.Export.PDF.Template
+	case "export.pandoc":
+		return cfg.Export.Pandoc
+	case "backup.remote":
+		return cfg.Backup.Remote
+	case "backup.encrypt":
+		return strconv.FormatBool(cfg.Backup.Encrypt)
+	case "backup.s3.endpoint":
+		return cfg.Backup.S3.Endpoint
+	case "backup.s3.region":
+		return cfg.Backup.S3.Region
+	case "backup.s3.bucket":
+		return cfg.Backup.S3.Bucket
+	case "backup.s3.prefix":
+		return cfg.Backup.S3.Prefix
+	case "backup.webdav.url":
+		return cfg.Backup.WebDAV.URL
+	case "backup.webdav.username":
+		return cfg.Backup.WebDAV.Username
+	case "backup.snapshot_dir":
+		return cfg.Backup.SnapshotDir
+	case "backup.keep.last":
+		return strconv.Itoa(cfg.Backup.Keep.Last)
+	case "backup.keep.daily":
+		return strconv.Itoa(cfg.Backup.Keep.Daily)
+	case "backup.keep.weekly":
+		return strconv.Itoa(cfg.Backup.Keep.Weekly)
+	case "backup.keep.monthly":
+		return strconv.Itoa(cfg.Backup.Keep.Monthly)
+	case "backup.keep.yearly":
+		return strconv.Itoa(cfg.Backup.Keep.Yearly)
+	case "book.provider":
+		return cfg.Book.Provider
+	case "book.url":
+		return cfg.Book.URL
 	default:
+		if name, ok := strings.CutPrefix(key, "templates.packs."); ok {
+			return cfg.Templates.Packs[name]
+		}
+		if name, ok := strings.CutPrefix(key, "alias."); ok {
+			return cfg.Alias[name]
+		}
 		return ""
 	}
 }
@@ -99,6 +732,29 @@ func setConfigValue(cfg *config.Config, key, value string) bool {
 	switch key {
 	case "editor":
 		cfg.General.Editor = value
+	case "read_only", "readonly":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		cfg.General.ReadOnly = b
+	case "timestamps":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		cfg.General.Timestamps = b
+	case "versions":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		cfg.General.Versions = n
+	case "on_conflict", "onconflict":
+		if value != "fail" && value != "merge" && value != "copy" && value != "overwrite" {
+			return false
+		}
+		cfg.General.OnConflict = value
 	case "data_home", "datahome":
 		cfg.Dir.DataHome = value
 	case "template_dir", "templatedir":
@@ -107,14 +763,254 @@ func setConfigValue(cfg *config.Config, key, value string) bool {
 		cfg.Dir.PeriodicDir = value
 	case "zettel_dir", "zetteldir":
 		cfg.Dir.ZettelDir = value
+	case "archive_dir", "archivedir":
+		cfg.Dir.ArchiveDir = value
+	case "literature_dir", "literaturedir":
+		cfg.Dir.LiteratureDir = value
+	case "state_dir", "statedir":
+		cfg.Dir.StateDir = value
+	case "cache_dir", "cachedir":
+		cfg.Dir.CacheDir = value
 	case "log.level", "loglevel":
 		cfg.Log.Level = value
 	case "log.format", "logformat":
 		cfg.Log.Format = value
 	case "log.output", "logoutput":
 		cfg.Log.Output = value
+	case "daily.log_heading", "logheading":
+		cfg.Daily.LogHeading = value
+	case "sync.remote":
+		cfg.Sync.Remote = value
+	case "sync.auto_commit":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		cfg.Sync.AutoCommit = b
+	case "templates.file_mode", "templates.dir_mode":
+		if _, err := config.ParseMode(value); err != nil {
+			return false
+		}
+		if key == "templates.file_mode" {
+			cfg.Templates.FileMode = value
+		} else {
+			cfg.Templates.DirMode = value
+		}
+	case "templates.profile_dir":
+		cfg.Templates.ProfileDir = value
+	case "search.snippet_length":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		cfg.Search.SnippetLength = n
+	case "periodic.daily.carry_over":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		cfg.Periodic.Daily.CarryOver = n
+	case "periodic.daily.providers":
+		cfg.Periodic.Daily.Providers = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Periodic.Daily.Providers = append(cfg.Periodic.Daily.Providers, name)
+			}
+		}
+	case "periodic.daily.link_week", "periodic.daily.link_month", "periodic.daily.link_quarter", "periodic.weekly.link_days":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		switch key {
+		case "periodic.daily.link_week":
+			cfg.Periodic.Daily.LinkWeek = b
+		case "periodic.daily.link_month":
+			cfg.Periodic.Daily.LinkMonth = b
+		case "periodic.daily.link_quarter":
+			cfg.Periodic.Daily.LinkQuarter = b
+		default:
+			cfg.Periodic.Weekly.LinkDays = b
+		}
+	case "watch.hooks":
+		// Set a single hook from the command line; edit the file for several.
+		cfg.Watch.Hooks = nil
+		if value != "" {
+			cfg.Watch.Hooks = []string{value}
+		}
+	case "calendar.source":
+		cfg.Calendar.Source = value
+	case "calendar.username":
+		cfg.Calendar.Username = value
+	case "location.name":
+		cfg.Location.Name = value
+	case "location.latitude", "location.longitude":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		if key == "location.latitude" {
+			if f < -90 || f > 90 {
+				return false
+			}
+			cfg.Location.Latitude = f
+		} else {
+			if f < -180 || f > 180 {
+				return false
+			}
+			cfg.Location.Longitude = f
+		}
+	case "weather.url":
+		cfg.Weather.URL = value
+	case "weather.units":
+		if value != "" && value != "celsius" && value != "fahrenheit" {
+			return false
+		}
+		cfg.Weather.Units = value
+	case "prompts.path":
+		cfg.Prompts.Path = value
+	case "prompts.count":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		cfg.Prompts.Count = n
+	case "prompts.order":
+		if _, err := prompts.ParseOrder(value); err != nil {
+			return false
+		}
+		cfg.Prompts.Order = value
+	case "cite.bibliography":
+		cfg.Cite.Bibliography = value
+	case "cite.style":
+		if value != "apa" && value != "mla" && value != "chicago" {
+			return false
+		}
+		cfg.Cite.Style = value
+	case "lint.disabled":
+		// A comma-separated list of rule names.
+		cfg.Lint.Disabled = nil
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.Lint.Disabled = append(cfg.Lint.Disabled, name)
+			}
+		}
+	case "ignore":
+		// A comma-separated list of globs.
+		cfg.Ignore = nil
+		for _, pattern := range strings.Split(value, ",") {
+			if pattern = strings.TrimSpace(pattern); pattern != "" {
+				cfg.Ignore = append(cfg.Ignore, pattern)
+			}
+		}
+	case "lint.max_words", "lint.todo_days":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		if key == "lint.max_words" {
+			cfg.Lint.MaxWords = n
+		} else {
+			cfg.Lint.TodoDays = n
+		}
+	case "slug.style":
+		if value == "" || !slug.Style(value).Valid() {
+			return false
+		}
+		cfg.Slug.Style = value
+	case "slug.max_length":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		cfg.Slug.MaxLength = n
+	case "slug.stop_words":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		cfg.Slug.StopWords = b
+	case "slug.date_prefix":
+		cfg.Slug.DatePrefix = value
+	case "export.pdf.engine":
+		cfg.Export.PDF.Engine = value
+	case "export.pdf.page_size":
+		if strings.TrimSpace(value) == "" {
+			return false
+		}
+		cfg.Export.PDF.PageSize = value
+	case "export.pdf.css":
+		cfg.Export.PDF.CSS = value
+	case "export.pdf.template":
+		cfg.Export.PDF.Template = value
+	case "export.pandoc":
+		if strings.TrimSpace(value) == "" {
+			return false
+		}
+		cfg.Export.Pandoc = value
+	case "backup.remote":
+		cfg.Backup.Remote = value
+	case "backup.encrypt":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return false
+		}
+		cfg.Backup.Encrypt = b
+	case "backup.s3.endpoint":
+		cfg.Backup.S3.Endpoint = value
+	case "backup.s3.region":
+		if strings.TrimSpace(value) == "" {
+			return false
+		}
+		cfg.Backup.S3.Region = value
+	case "backup.s3.bucket":
+		cfg.Backup.S3.Bucket = value
+	case "backup.s3.prefix":
+		cfg.Backup.S3.Prefix = value
+	case "backup.webdav.url":
+		cfg.Backup.WebDAV.URL = value
+	case "backup.webdav.username":
+		cfg.Backup.WebDAV.Username = value
+	case "backup.snapshot_dir":
+		if strings.TrimSpace(value) == "" {
+			return false
+		}
+		cfg.Backup.SnapshotDir = value
+	case "backup.keep.last", "backup.keep.daily", "backup.keep.weekly", "backup.keep.monthly", "backup.keep.yearly":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return false
+		}
+		*map[string]*int{
+			"backup.keep.last":    &cfg.Backup.Keep.Last,
+			"backup.keep.daily":   &cfg.Backup.Keep.Daily,
+			"backup.keep.weekly":  &cfg.Backup.Keep.Weekly,
+			"backup.keep.monthly": &cfg.Backup.Keep.Monthly,
+			"backup.keep.yearly":  &cfg.Backup.Keep.Yearly,
+		}[key] = n
+	case "book.provider":
+		cfg.Book.Provider = value
+	case "book.url":
+		cfg.Book.URL = value
 	default:
-		return false
+		if name, ok := strings.CutPrefix(key, "templates.packs."); ok {
+			if name == "" || strings.TrimSpace(value) == "" {
+				return false
+			}
+			if cfg.Templates.Packs == nil {
+				cfg.Templates.Packs = make(map[string]string)
+			}
+			cfg.Templates.Packs[name] = value
+			return true
+		}
+		name, ok := strings.CutPrefix(key, "alias.")
+		if !ok || name == "" {
+			return false
+		}
+		if cfg.Alias == nil {
+			cfg.Alias = make(map[string]string)
+		}
+		cfg.Alias[name] = value
 	}
 	return true
 }
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/plugin"
+	"github.com/a-kostevski/exo/pkg/recent"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/versions"
+)
+
+// annotationTolerant marks the commands that run even when the
+// configuration cannot be loaded, such as "config doctor", with the defaults
+// in its place.
+const annotationTolerant = "exo.tolerant"
+
+// tolerant returns the annotations of a command that runs with a broken
+// configuration.
+func tolerant() map[string]string {
+	return map[string]string{annotationTolerant: "true"}
+}
+
+// pluginCacheFile is the file in cache_dir the plugin manifests are cached in.
+const pluginCacheFile = "plugins.json"
+
+// ContainerOptions are the global flags the dependencies are built with,
+// which main reads before cobra parses the command line.
+type ContainerOptions struct {
+	ConfigPath string // --config; empty searches the configuration directory.
+	ReadOnly   bool   // --read-only.
+	Force      bool   // --force.
+	OnConflict string // --on-conflict; empty keeps general.on_conflict.
+}
+
+// Container builds the dependencies of the commands, each when first needed,
+// and keeps the errors met doing so until a command that needs the failed
+// dependency runs: a broken configuration file or template directory then
+// only stops the commands using them, with a suggested fix, while commands
+// such as "config doctor" still run.
+type Container struct {
+	opts ContainerOptions
+	log  logger.Logger
+
+	config    *config.Config
+	configErr error
+	fsys      fs.FileSystem
+	plugins   []plugin.Plugin
+	tm        templates.TemplateManager
+	tmErr     error
+}
+
+// NewContainer returns a container building the dependencies for opts.
+func NewContainer(opts ContainerOptions) *Container {
+	return &Container{opts: opts, log: logger.NewLogger()}
+}
+
+// Config loads the configuration. When it cannot be loaded, the error
+// explains how to fix it, and the defaults are returned in its place.
+func (c *Container) Config() (*config.Config, error) {
+	if c.config != nil {
+		return c.config, c.configErr
+	}
+	cfg, err := config.NewConfig(c.opts.ConfigPath)
+	if err != nil {
+		c.configErr = configError(c.opts.ConfigPath, err)
+		if cfg, err = config.Defaults(fs.OSEnv()); err != nil {
+			// Without a home directory there are no defaults either.
+			cfg = &config.Config{}
+		}
+	}
+	if c.opts.ReadOnly {
+		cfg.Override("general.read_only", "--read-only", func(c *config.Config) { c.General.ReadOnly = true })
+	}
+	switch {
+	case c.opts.OnConflict != "":
+		cfg.Override("general.on_conflict", "--on-conflict", func(cfg *config.Config) { cfg.General.OnConflict = c.opts.OnConflict })
+	case c.opts.Force:
+		// --force overwrites the notes changed on disk, as it does locked ones.
+		cfg.Override("general.on_conflict", "--force", func(c *config.Config) { c.General.OnConflict = "overwrite" })
+	}
+	c.config = cfg
+	return c.config, c.configErr
+}
+
+// Logger returns the logger.
+func (c *Container) Logger() logger.Logger {
+	return c.log
+}
+
+// FS returns the file system notes are read and written through: read-only
+// with --read-only or general.read_only, else keeping previous versions and
+// recording recent notes, and guarding locked notes unless --force is given.
+func (c *Container) FS() fs.FileSystem {
+	if c.fsys != nil {
+		return c.fsys
+	}
+	cfg, _ := c.Config()
+	var fsys fs.FileSystem = fs.NewOSFileSystem()
+	if !c.opts.Force {
+		// Protect the notes locked with locked: true in their frontmatter.
+		fsys = note.GuardLocked(fsys)
+	}
+	if cfg.General.ReadOnly {
+		fsys = fs.NewReadOnlyFileSystem(fsys)
+	} else {
+		if cfg.General.Versions > 0 {
+			// Keep the previous versions of notes for "exo versions" and "exo diff".
+			fsys = versions.Keep(fsys, versions.NewStore(cfg.Dir.DataHome, cfg.General.Versions),
+				[]string{cfg.Dir.TemplateDir}, c.log)
+		}
+		// Record the notes opened and edited for "exo recent".
+		fsys = recent.Track(fsys, cfg.Dir.StatePath(recent.File), cfg.Dir.DataHome,
+			[]string{cfg.Dir.TemplateDir}, c.log)
+	}
+	c.fsys = fsys
+	return c.fsys
+}
+
+// Plugins returns the plugins of the plugin directory; none when the
+// configuration cannot be loaded. Their manifests are cached in cache_dir,
+// so that plugins only run on every invocation of exo once changed. Plugins
+// that fail to load are reported by "exo plugin list".
+func (c *Container) Plugins() []plugin.Plugin {
+	if c.plugins == nil {
+		c.plugins = []plugin.Plugin{}
+		if cfg, err := c.Config(); err == nil {
+			if plugins, _ := plugin.DiscoverCached(context.Background(), cfg.Dir.PluginDir, cfg.Dir.CachePath(pluginCacheFile)); plugins != nil {
+				c.plugins = plugins
+			}
+		}
+	}
+	return c.plugins
+}
+
+// TemplateManager builds the template manager.
+func (c *Container) TemplateManager() (templates.TemplateManager, error) {
+	if c.tm != nil || c.tmErr != nil {
+		return c.tm, c.tmErr
+	}
+	cfg, err := c.Config()
+	if err != nil {
+		c.tmErr = err
+		return nil, c.tmErr
+	}
+	// Validated when the configuration was loaded.
+	fileMode, dirMode, _ := cfg.Templates.Modes()
+	c.tm, err = templates.NewTemplateManager(templates.TemplateConfig{
+		TemplateDir:       cfg.Dir.TemplateDir,
+		ProfileDir:        cfg.Templates.ProfileDir,
+		TemplateExtension: ".md",
+		FilePermissions:   fileMode,
+		DirPermissions:    dirMode,
+		Funcs:             plugin.FuncMap(c.Plugins()),
+		Logger:            c.log,
+		FS:                c.FS(),
+	})
+	if err != nil {
+		c.tmErr = templateError(cfg.Dir.TemplateDir, err)
+	}
+	return c.tm, c.tmErr
+}
+
+// Dependencies returns the dependencies of the commands. The configuration
+// is loaded, as aliases and plugin commands are known before a command is
+// chosen, while the template manager is only built when a command uses it.
+func (c *Container) Dependencies() Dependencies {
+	cfg, _ := c.Config()
+	return Dependencies{
+		Config:          cfg,
+		Logger:          c.log,
+		FS:              c.FS(),
+		TemplateManager: lazyTemplateManager{c},
+		Plugins:         c.Plugins(),
+		ReadOnly:        cfg.General.ReadOnly,
+		Force:           c.opts.Force,
+		container:       c,
+	}
+}
+
+// check returns the error that keeps cmd from running: the configuration
+// failing to load, unless cmd is tolerant of it.
+func (c *Container) check(cmd *cobra.Command) error {
+	_, err := c.Config()
+	if err == nil {
+		return nil
+	}
+	for p := cmd; p != nil; p = p.Parent() {
+		if p.Annotations[annotationTolerant] == "true" {
+			return nil
+		}
+	}
+	cmd.SilenceUsage = true
+	return err
+}
+
+// checkLateFlags returns a usage error for the global flags the
+// dependencies are built with, such as --force, that cmd was given after the
+// command name: main only reads those before it, so they would be ignored.
+func (c *Container) checkLateFlags(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+	given := func(name string) bool {
+		f := flags.Lookup(name)
+		return f != nil && f.Changed
+	}
+	var late, example string
+	switch {
+	case given("config") && flags.Lookup("config").Value.String() != c.opts.ConfigPath:
+		late, example = "--config", "--config "+flags.Lookup("config").Value.String()
+	case given("read-only") && !c.opts.ReadOnly:
+		late = "--read-only"
+	case given("force") && !c.opts.Force:
+		late = "--force"
+	case given("on-conflict") && flags.Lookup("on-conflict").Value.String() != c.opts.OnConflict:
+		late, example = "--on-conflict", "--on-conflict "+flags.Lookup("on-conflict").Value.String()
+	default:
+		return nil
+	}
+	if example == "" {
+		example = late
+	}
+	return exoerrors.New(exoerrors.Usage, "%s must come before the command, as in \"%s %s %s\"",
+		late, cmd.Root().Name(), example, strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "))
+}
+
+// lazyTemplateManager is the template manager of a container, built on the
+// first use.
+type lazyTemplateManager struct {
+	c *Container
+}
+
+func (l lazyTemplateManager) ProcessTemplate(name string, data interface{}) (string, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return "", err
+	}
+	return tm.ProcessTemplate(name, data)
+}
+
+func (l lazyTemplateManager) ProcessTemplateWithContext(ctx context.Context, name string, data interface{}) (string, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return "", err
+	}
+	return tm.ProcessTemplateWithContext(ctx, name, data)
+}
+
+func (l lazyTemplateManager) ListTemplates() ([]string, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return nil, err
+	}
+	return tm.ListTemplates()
+}
+
+func (l lazyTemplateManager) Resolve(name string) (templates.TemplateSource, string, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return templates.TemplateSource{}, "", err
+	}
+	return tm.Resolve(name)
+}
+
+func (l lazyTemplateManager) ProcessScaffold(ctx context.Context, name string, data interface{}) ([]templates.ScaffoldFile, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return nil, err
+	}
+	return tm.ProcessScaffold(ctx, name, data)
+}
+
+func (l lazyTemplateManager) ResolveScaffold(name string) (templates.TemplateSource, []templates.ScaffoldFile, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return templates.TemplateSource{}, nil, err
+	}
+	return tm.ResolveScaffold(name)
+}
+
+func (l lazyTemplateManager) ListScaffolds() ([]string, error) {
+	tm, err := l.c.TemplateManager()
+	if err != nil {
+		return nil, err
+	}
+	return tm.ListScaffolds()
+}
+
+// StartupError is the failure to build a dependency, with what it concerned
+// and how to fix it.
+type StartupError struct {
+	Stage  string // What was being initialized, e.g. "load configuration".
+	Target string // The path or setting involved, if any.
+	Hint   string // A suggested fix.
+	Err    error
+}
+
+func (e *StartupError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Stage, e.Err)
+}
+
+func (e *StartupError) Unwrap() error {
+	return e.Err
+}
+
+// configError wraps a configuration loading failure with a suggested fix.
+func configError(path string, err error) *StartupError {
+	target := path
+	if target == "" {
+		target = filepath.Join(fs.OSEnv().ConfigDir("exo"), "config.yaml")
+	}
+	hint := "Check the configuration file for YAML syntax errors, or run \"exo config doctor\"."
+	switch {
+	case errors.Is(err, iofs.ErrNotExist):
+		hint = "The configuration file does not exist. Check the --config path, or omit it to use the defaults."
+	case errors.Is(err, iofs.ErrPermission):
+		hint = "The configuration file is not readable. Fix its permissions, e.g. chmod 644 " + target + "."
+	case strings.Contains(err.Error(), "cannot be empty"):
+		hint = "A required setting is empty. Set it in the configuration file or remove the entry to use the default."
+	}
+	return &StartupError{Stage: "load configuration", Target: target, Hint: hint, Err: exoerrors.Wrap(exoerrors.Config, err)}
+}
+
+// templateError wraps a template manager initialization failure with a suggested fix.
+func templateError(dir string, err error) *StartupError {
+	hint := "Set dir.template_dir in the configuration file, then run \"exo templates --install\"."
+	if errors.Is(err, iofs.ErrPermission) {
+		hint = "The template directory is not accessible. Fix the permissions of " + dir + "."
+	}
+	return &StartupError{Stage: "initialize templates", Target: dir, Hint: hint, Err: exoerrors.Wrap(exoerrors.Config, err)}
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/daemon"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/a-kostevski/exo/pkg/watch"
+)
+
+// daemonSocket is the socket "exo daemon" listens on by default, in
+// state_dir.
+const daemonSocket = "daemon.sock"
+
+// NewDaemonCmd returns a new cobra.Command for the "daemon" command, which
+// answers editor plugins over a Unix socket from an index kept warm.
+func NewDaemonCmd(deps Dependencies) *cobra.Command {
+	var (
+		socket string
+		delay  time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Answer editor plugins over a Unix socket",
+		Long: `Keep the note index open and up to date as notes change, and answer editor
+plugins over a Unix socket, ` + filepath.ToSlash(daemonSocket) + ` under data_home by default, in
+milliseconds rather than the startup time of a command.
+
+Requests are JSON-RPC 2.0, one per line:
+
+  status     {}                          the vault and the number of notes indexed
+  resolve    {"name"}                    the notes a name or wikilink target names
+  search     {"query", "limit"}          the notes containing every word of query
+  backlinks  {"note"}                    the notes linking to a note
+  create     {"title", "dir", "content"} create a note, in the inbox by default
+
+Notes are returned with "path", relative to data_home, and "file", absolute.
+The socket is only accessible to the current user.`,
+		Example: examples(
+			ex("exo daemon", "Answer plugins until interrupted"),
+			ex(`echo '{"jsonrpc": "2.0", "id": 1, "method": "resolve", "params": {"name": "Go channels"}}' | nc -U "$(exo config get state_dir)/daemon.sock"`, "Resolve a link from the shell"),
+		),
+		Annotations: mutatesOnRequest(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if socket == "" {
+				socket = filepath.Join(deps.Config.Dir.StateDir, daemonSocket)
+			}
+			exclude := []string{deps.Config.Dir.TemplateDir}
+			ix, err := openIndex(deps)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			if _, err := ix.Sync(exclude...); err != nil {
+				return err
+			}
+			d, err := daemon.New(ix, daemon.Options{Notes: vault.Notes{
+				Config:     *deps.Config,
+				FS:         deps.FS,
+				Logger:     deps.Logger,
+				NewNoteDir: deps.Config.Dir.InboxDir,
+				Exclude:    append(exclude, deps.Config.Dir.PluginDir),
+				Ignore:     vaultIgnore(deps),
+			}})
+			if err != nil {
+				return err
+			}
+
+			w, err := watch.New(deps.Config.Dir.DataHome, delay, exclude...)
+			if err != nil {
+				return err
+			}
+			defer w.Close()
+			ln, err := daemon.Listen(socket)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", socket, err)
+			}
+			defer os.Remove(socket)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			errOut := cmd.ErrOrStderr()
+			go func() {
+				_ = w.Run(ctx, func(events []watch.Event) {
+					paths := make([]string, len(events))
+					for i, ev := range events {
+						paths[i] = ev.Path
+					}
+					if err := d.Update(paths...); err != nil {
+						fmt.Fprintf(errOut, "Failed to update the index: %v\n", err)
+					}
+				})
+			}()
+			fmt.Fprintf(errOut, "Listening on %s (press Ctrl+C to stop)\n", socket)
+			return d.Serve(ctx, ln)
+		},
+	}
+
+	cmd.Flags().StringVar(&socket, "socket", "", "Listen on this socket instead of "+filepath.ToSlash(daemonSocket)+" under data_home")
+	cmd.Flags().DurationVar(&delay, "delay", 100*time.Millisecond, "Wait this long after the last change before updating the index")
+	return cmd
+}
@@ -2,22 +2,40 @@ package cmd
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
 	"github.com/a-kostevski/exo/pkg/periodic"
 )
 
+// dailyDateLayout is the layout used for daily note titles and the --date flag.
+const dailyDateLayout = "2006-01-02"
+
 // NewDayCmd returns a new cobra.Command for the "day" command.
 func NewDayCmd(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "day",
 		Short: "Create or open today's daily note",
+		Long: `Create today's daily note from its template, if it does not exist yet, and
+open it in the editor.
+
+With "agenda" among periodic.daily.providers, new daily notes get the events of
+their date from the calendar of calendar.source as .Agenda. --no-agenda leaves
+the calendar alone, as when offline, for this command and its subcommands.`,
+		Example: examples(
+			ex("exo day", "Open today's daily note"),
+			ex("exo day --no-agenda", "Create today's note without reading the calendar"),
+		),
+		Annotations: mutates(),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			today := time.Now().Truncate(24 * time.Hour)
+			today := periodic.DayOf(time.Now())
 			// Create (or load) today's daily note using injected dependencies.
-			daily, err := periodic.NewDailyNote(today, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+			daily, err := periodic.NewDailyNote(cmd.Context(), today, dayConfig(cmd, deps), deps.TemplateManager, deps.Logger, deps.FS)
 			if err != nil {
 				return fmt.Errorf("failed to create daily note: %w", err)
 			}
@@ -27,5 +45,207 @@ func NewDayCmd(deps Dependencies) *cobra.Command {
 			return nil
 		},
 	}
+	cmd.PersistentFlags().Bool("no-agenda", false, "Do not read the calendar for the agenda of new daily notes")
+	nav := &periodic.DailyNavigator{}
+	cmd.AddCommand(newDayNavCmd(deps, "next", "Create or show the daily note after today (or --date)", nav.Next))
+	cmd.AddCommand(newDayNavCmd(deps, "prev", "Create or show the daily note before today (or --date)", nav.Previous))
+	cmd.AddCommand(newDayAppendCmd(deps))
+	cmd.AddCommand(newDayBackfillCmd(deps))
+	return cmd
+}
+
+// dayConfig returns the configuration daily notes are created with by the
+// "day" command cmd: that of deps, without the "agenda" provider with
+// --no-agenda.
+func dayConfig(cmd *cobra.Command, deps Dependencies) config.Config {
+	cfg := *deps.Config
+	if noAgenda, _ := cmd.Flags().GetBool("no-agenda"); noAgenda {
+		cfg.Periodic.Daily.Providers = slices.DeleteFunc(slices.Clone(cfg.Periodic.Daily.Providers), func(name string) bool {
+			return name == "agenda"
+		})
+	}
+	return cfg
+}
+
+// newDayBackfillCmd returns the "day backfill" command, which creates the
+// periodic notes missing in a range of dates without opening them.
+func newDayBackfillCmd(deps Dependencies) *cobra.Command {
+	var (
+		fromFlag string
+		toFlag   string
+		period   string
+		dryRun   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "backfill",
+		Short: "Create the missing daily (or weekly) notes of a range of dates",
+		Long: `Create the daily notes missing from --from to --to (today by default), from
+their templates and without opening the editor, as after a vacation or when
+starting a streak. Existing notes are left untouched.
+
+With --period weekly, monthly or quarterly, the notes of the weeks, months or
+quarters of the range are created instead.`,
+		Example: examples(
+			ex("exo day backfill --from 2024-01-01", "Create the daily notes missing since January 1st"),
+			ex("exo day backfill --from 2024-01-01 --to 2024-01-31 --period weekly", "Create the weekly notes of January"),
+			ex("exo day backfill --from 2024-01-01 --dry-run", "List the missing daily notes"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromFlag == "" {
+				return exoerrors.New(exoerrors.Usage, "--from is required")
+			}
+			from, err := parseDayDate(fromFlag)
+			if err != nil {
+				return err
+			}
+			to, err := parseDayDate(toFlag)
+			if err != nil {
+				return err
+			}
+			opts := periodic.BackfillOptions{Period: periodic.PeriodType(period), From: from, To: to, DryRun: dryRun}
+			created, err := periodic.Backfill(cmd.Context(), opts, dayConfig(cmd, deps), deps.TemplateManager, deps.Logger, deps.FS)
+			for _, path := range created {
+				fmt.Fprintln(cmd.OutOrStdout(), path)
+			}
+			if err != nil {
+				return err
+			}
+			verb := "Created"
+			if dryRun {
+				verb = "Would create"
+			}
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s %d %s note(s)\n", verb, len(created), period)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromFlag, "from", "", "First date of the range (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&toFlag, "to", "", "Last date of the range (YYYY-MM-DD), today by default")
+	cmd.Flags().StringVarP(&period, "period", "p", string(periodic.Daily), "Notes to create: daily, weekly, monthly or quarterly")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "List the missing notes without creating them")
+	_ = cmd.RegisterFlagCompletionFunc("period", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix([]string{"daily", "weekly", "monthly", "quarterly"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// newDayAppendCmd returns the "day append" command, which adds entries to a
+// section of a daily note defined in daily.sections.
+func newDayAppendCmd(deps Dependencies) *cobra.Command {
+	var (
+		section  string
+		dateFlag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "append [text...]",
+		Short: "Append an entry to a section of today's daily note",
+		Long: `Append an entry under a section of today's daily note (or --date), creating
+the note if needed.
+
+Sections are defined in daily.sections, in the order they appear in daily notes,
+each with a heading, the prefix of its entries and whether entries are
+timestamped. A missing section is created with its template, in its place
+among the other sections. The default sections are Tasks, Log and Notes.
+When no text is given, each non-empty line read from standard input becomes an entry.`,
+		Example: examples(
+			ex(`exo day append --section Log "Shipped the release"`, "Add a timestamped entry to the Log section"),
+			ex(`exo day append -s Tasks "Call Bob"`, "Add a task"),
+			ex(`exo day append -s Notes --date 2025-02-08 "Late thought"`, "Append to another day"),
+		),
+		Annotations: mutates(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if section == "" {
+				return exoerrors.New(exoerrors.Usage, "--section is required (one of %s)", strings.Join(deps.Config.Daily.SectionNames(), ", "))
+			}
+			entries, err := logEntries(cmd, args)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return exoerrors.New(exoerrors.Usage, "nothing to append")
+			}
+			date, err := parseDayDate(dateFlag)
+			if err != nil {
+				return err
+			}
+			daily, err := periodic.NewDailyNote(cmd.Context(), date, dayConfig(cmd, deps), deps.TemplateManager, deps.Logger, deps.FS)
+			if err != nil {
+				return fmt.Errorf("failed to create daily note: %w", err)
+			}
+			now := time.Now()
+			for _, entry := range entries {
+				if err := daily.AppendToSection(section, entry, now); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&section, "section", "s", "", "Section to append to, by name (see daily.sections)")
+	cmd.Flags().StringVar(&dateFlag, "date", "", "Append to the daily note of this date (YYYY-MM-DD) instead of today")
+	_ = cmd.RegisterFlagCompletionFunc("section", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix(deps.Config.Daily.SectionNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
 	return cmd
 }
+
+// newDayNavCmd builds a navigation subcommand of "day". The step function
+// resolves the target date from the origin date, typically a DailyNavigator method.
+// The target daily note is created if it does not exist yet.
+func newDayNavCmd(deps Dependencies, use, short string, step func(time.Time) time.Time) *cobra.Command {
+	var (
+		dateFlag string
+		openFlag bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Example: examples(
+			ex("exo day "+use, short),
+			ex("exo day "+use+" --date 2025-02-08 --open", "Start from another date and open the note"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			origin, err := parseDayDate(dateFlag)
+			if err != nil {
+				return err
+			}
+			daily, err := periodic.NewDailyNote(cmd.Context(), step(origin), dayConfig(cmd, deps), deps.TemplateManager, deps.Logger, deps.FS)
+			if err != nil {
+				return fmt.Errorf("failed to create daily note: %w", err)
+			}
+			if openFlag {
+				if err := daily.Open(); err != nil {
+					return fmt.Errorf("failed to open daily note: %w", err)
+				}
+				return nil
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), daily.Path())
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dateFlag, "date", "", "Resolve from the daily note of this date (YYYY-MM-DD) instead of today")
+	cmd.Flags().BoolVarP(&openFlag, "open", "o", false, "Open the resolved daily note in the editor")
+	return cmd
+}
+
+// parseDayDate parses a YYYY-MM-DD date, defaulting to today when value is
+// empty. An invalid date is a usage error.
+func parseDayDate(value string) (time.Time, error) {
+	if value == "" {
+		return periodic.DayOf(time.Now()), nil
+	}
+	date, err := time.ParseInLocation(dailyDateLayout, value, time.Local)
+	if err != nil {
+		return time.Time{}, exoerrors.New(exoerrors.Usage, "invalid date %q (expected YYYY-MM-DD): %w", value, err)
+	}
+	return date, nil
+}
@@ -0,0 +1,81 @@
+package cmd_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/cmd"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDayDeps(t *testing.T) cmd.Dependencies {
+	t.Helper()
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(t.TempDir())
+	t.Cleanup(cleanup)
+	return cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+}
+
+// runDay runs the "day" command with args and returns its output.
+func runDay(t *testing.T, deps cmd.Dependencies, args ...string) (string, error) {
+	t.Helper()
+	c := cmd.NewDayCmd(deps)
+	var out bytes.Buffer
+	c.SetOut(&out)
+	c.SetErr(&bytes.Buffer{})
+	c.SetArgs(args)
+	err := c.Execute()
+	return strings.TrimSpace(out.String()), err
+}
+
+func TestDay_NextPrev(t *testing.T) {
+	deps := newDayDeps(t)
+	dayDir := filepath.Join(deps.Config.Dir.DataHome, "day")
+
+	path, err := runDay(t, deps, "next", "--date", "2025-02-28")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dayDir, "2025-03-01.md"), path)
+	assert.FileExists(t, path)
+
+	path, err = runDay(t, deps, "prev", "--date", "2025-03-01")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dayDir, "2025-02-28.md"), path)
+	assert.FileExists(t, path)
+}
+
+func TestDay_LocalDate(t *testing.T) {
+	// A zone behind UTC on the same day, where the UTC midnight starting it
+	// is still yesterday.
+	now := time.Now().UTC()
+	if now.Hour() == 0 {
+		t.Skip("no zone behind UTC shares its date in its first hour")
+	}
+	local := time.Local
+	t.Cleanup(func() { time.Local = local })
+	time.Local = time.FixedZone("behind", -3600)
+	today := time.Now().In(time.Local).Format("2006-01-02")
+
+	deps := newDayDeps(t)
+	path, err := runDay(t, deps, "next")
+	require.NoError(t, err)
+	date, err := time.ParseInLocation("2006-01-02", today, time.Local)
+	require.NoError(t, err)
+	assert.Equal(t, date.AddDate(0, 0, 1).Format("2006-01-02")+".md", filepath.Base(path))
+}
+
+func TestDay_InvalidDate(t *testing.T) {
+	deps := newDayDeps(t)
+	for _, args := range [][]string{
+		{"next", "--date", "notadate"},
+		{"prev", "--date", "2025-13-01"},
+		{"backfill", "--from", "notadate"},
+	} {
+		_, err := runDay(t, deps, args...)
+		assert.Equal(t, exoerrors.ExitUsage, exoerrors.ExitCode(err), "%v: %v", args, err)
+	}
+}
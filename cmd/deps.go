@@ -2,10 +2,13 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/a-kostevski/exo/pkg/config"
 	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/plugin"
 	"github.com/a-kostevski/exo/pkg/templates"
 )
 
@@ -15,6 +18,31 @@ type Dependencies struct {
 	Logger          logger.Logger
 	FS              fs.FileSystem
 	TemplateManager templates.TemplateManager
+	// Plugins are the plugins discovered in the plugin directory.
+	Plugins []plugin.Plugin
+	// ReadOnly is set by --read-only or general.read_only; FS then rejects
+	// writes and commands that modify notes refuse to run.
+	ReadOnly bool
+	// Force is set by --force; FS then also changes and deletes notes locked
+	// with locked: true in their frontmatter.
+	Force bool
+
+	// container built the dependencies, if they were; it keeps the errors
+	// met building them.
+	container *Container
+}
+
+// checkUnlocked fails with note.ErrLocked if the note at path is locked, for
+// the changes made outside of deps.FS, unless --force was given.
+func checkUnlocked(deps Dependencies, path string) error {
+	if deps.Force {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil || !note.IsLocked(string(content)) {
+		return nil
+	}
+	return fmt.Errorf("cannot change %s: %w (unlock it or use --force)", path, note.ErrLocked)
 }
 
 // defaultInputReader is a simple implementation of templates.InputReader that uses standard input.
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/integrity"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewDoctorCmd creates a new "doctor" command, whose subcommands check the vault
+// for problems.
+func NewDoctorCmd(deps Dependencies) *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the vault for problems",
+		Example: examples(
+			ex("exo doctor links", "Report broken wikilinks and colliding aliases"),
+			ex("exo doctor integrity", "Report corrupted, truncated and badly encoded files"),
+		),
+	}
+	doctorCmd.AddCommand(newDoctorLinksCmd(deps))
+	doctorCmd.AddCommand(newDoctorIntegrityCmd(deps))
+	return doctorCmd
+}
+
+func newDoctorLinksCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "links",
+		Short: "Report broken wikilinks and colliding aliases",
+		Long: `Check the wikilinks of every note under data_home and report:
+
+  - aliases naming more than one note, because another note has the same
+    alias, title or file name: links using them resolve to the file name or
+    title first, and are ambiguous otherwise
+  - links to notes that do not exist
+  - links naming more than one note
+
+The command fails when a problem is found.`,
+		Example: examples(
+			ex("exo doctor links", "Check every note"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return err
+			}
+			problems := checkLinks(cmd.OutOrStdout(), deps.Config.Dir.DataHome, notes)
+			if problems > 0 {
+				return fmt.Errorf("found %d link problem(s)", problems)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Checked %d notes: no link problems found\n", len(notes))
+			return nil
+		},
+	}
+}
+
+func newDoctorIntegrityCmd(deps Dependencies) *cobra.Command {
+	var fix, accept bool
+
+	cmd := &cobra.Command{
+		Use:   "integrity",
+		Short: "Report corrupted, truncated and badly encoded files",
+		Long: `Check the files under data_home against a manifest of their content hashes,
+kept in manifest.json under state_dir, and report:
+
+  modified      files whose content changed while their modification time did
+                not, as when the disk or a program corrupted them
+  truncated     files emptied since the last check
+  invalid-utf8  notes that are not valid UTF-8
+  bom           notes starting with a byte order mark
+  nul           notes containing NUL bytes, as left by a crash
+
+The manifest is updated with the files added, edited and removed since the
+last check; the first check records every file. Modified and truncated files
+keep being reported until restored (see "exo versions" and "exo backup") or
+recorded as they are with --accept. With --fix, byte order marks and NUL bytes
+are removed, and notes that are not UTF-8 are converted from Windows-1252.
+
+The command fails when a problem is left.`,
+		Example: examples(
+			ex("exo doctor integrity", "Check every file"),
+			ex("exo doctor integrity --fix", "Normalize the encoding of notes"),
+			ex("exo doctor integrity --accept", "Record the modified files as they are"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if fix && deps.ReadOnly {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--fix modifies notes: %w (drop --read-only or set general.read_only to false)", fs.ErrReadOnly)
+			}
+			m, err := integrity.OpenManifest(deps.Config.Dir.StatePath(integrity.File))
+			if err != nil {
+				return err
+			}
+			result, err := integrity.Check(deps.Config.Dir.DataHome, m, integrity.Options{Fix: fix, Accept: accept})
+			if err != nil {
+				return err
+			}
+			if err := m.Save(); err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			problems := 0
+			for _, issue := range result.Issues {
+				line := fmt.Sprintf("%s: %s", issue.Path, issue.Kind)
+				if issue.Detail != "" {
+					line += " (" + issue.Detail + ")"
+				}
+				switch {
+				case issue.Fixed:
+					line += ": fixed"
+				case accept && !issue.Kind.Fixable():
+					line += ": accepted"
+				case issue.Kind.Fixable():
+					line += ": fix with --fix"
+					problems++
+				default:
+					problems++
+				}
+				fmt.Fprintln(out, line)
+			}
+			fmt.Fprintf(out, "Checked %d file(s): %d new, %d edited, %d removed since the last check\n",
+				result.Files, result.Added, result.Changed, result.Removed)
+			if problems > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d integrity problem(s)", problems)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "Remove byte order marks and NUL bytes, and convert notes to UTF-8")
+	cmd.Flags().BoolVar(&accept, "accept", false, "Record modified and truncated files as they are")
+	return cmd
+}
+
+// checkLinks writes the alias collisions and unresolved or ambiguous links
+// among notes to out and returns how many were found.
+func checkLinks(out io.Writer, root string, notes []scan.Note) int {
+	rel := func(n scan.Note) string {
+		if r, err := filepath.Rel(root, n.Path); err == nil {
+			return r
+		}
+		return n.Path
+	}
+	names := scan.NewNames(root, notes)
+	problems := 0
+	for _, c := range names.Collisions() {
+		paths := make([]string, len(c.Notes))
+		for i, n := range c.Notes {
+			paths[i] = rel(n)
+		}
+		fmt.Fprintf(out, "alias %q names %d notes: %s\n", c.Alias, len(c.Notes), strings.Join(paths, ", "))
+		problems++
+	}
+	for _, n := range notes {
+		for _, target := range n.Links {
+			switch matches := names.Resolve(target); len(matches) {
+			case 0:
+				fmt.Fprintf(out, "%s: [[%s]] links to a missing note\n", rel(n), target)
+				problems++
+			case 1:
+			default:
+				fmt.Fprintf(out, "%s: [[%s]] is ambiguous (%d notes)\n", rel(n), target, len(matches))
+				problems++
+			}
+		}
+	}
+	return problems
+}
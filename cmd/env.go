@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// envVar is a variable reported by "exo env".
+type envVar struct {
+	name  string
+	value string
+}
+
+// overrideVars lists the environment variables that influence the configuration.
+var overrideVars = []string{"EXO_DATA_HOME", "EDITOR", "XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME", "XDG_STATE_HOME", "APPDATA", "LOCALAPPDATA"}
+
+// NewEnvCmd returns a new cobra.Command for the "env" command.
+func NewEnvCmd(deps Dependencies) *cobra.Command {
+	var shell bool
+
+	cmd := &cobra.Command{
+		Use:   "env",
+		Short: "Print the resolved exo environment",
+		Example: examples(
+			ex("exo env", "Print paths and settings for a bug report"),
+			ex(`eval "$(exo env --shell)"`, "Export the resolved paths into the shell"),
+		),
+		Long: `Print the exo version, the configuration file in use, all resolved paths,
+the detected editor and the environment overrides in effect.
+
+With --shell, print the values as EXO_* export statements:
+
+  eval "$(exo env --shell)"`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vars := envVars(deps)
+			if shell {
+				for _, v := range vars {
+					fmt.Fprintf(cmd.OutOrStdout(), "export %s=%s\n", v.name, shellQuote(v.value))
+				}
+				return nil
+			}
+			return writeEnv(cmd.OutOrStdout(), vars)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&shell, "shell", "s", false, "Print POSIX shell export statements")
+	return cmd
+}
+
+// envVars returns the EXO_* variables describing the resolved environment.
+func envVars(deps Dependencies) []envVar {
+	cfg := deps.Config
+	return []envVar{
+		{"EXO_VERSION", Version},
+		{"EXO_CONFIG", cfg.Source()},
+		{"EXO_DATA_HOME", cfg.Dir.DataHome},
+		{"EXO_TEMPLATE_DIR", cfg.Dir.TemplateDir},
+		{"EXO_PERIODIC_DIR", cfg.Dir.PeriodicDir},
+		{"EXO_ZETTEL_DIR", cfg.Dir.ZettelDir},
+		{"EXO_PROJECTS_DIR", cfg.Dir.ProjectsDir},
+		{"EXO_INBOX_DIR", cfg.Dir.InboxDir},
+		{"EXO_IDEA_DIR", cfg.Dir.IdeaDir},
+		{"EXO_PLUGIN_DIR", cfg.Dir.PluginDir},
+		{"EXO_EDITOR", cfg.General.Editor},
+	}
+}
+
+// writeEnv prints the environment in a human-readable form.
+func writeEnv(w io.Writer, vars []envVar) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, v := range vars {
+		value := v.value
+		if value == "" {
+			value = "(none)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", v.name, value)
+	}
+	fmt.Fprintln(tw, "\nEnvironment overrides:")
+	found := false
+	for _, name := range overrideVars {
+		if value, ok := os.LookupEnv(name); ok {
+			fmt.Fprintf(tw, "  %s\t%s\n", name, value)
+			found = true
+		}
+	}
+	if !found {
+		fmt.Fprintln(tw, "  (none)")
+	}
+	return tw.Flush()
+}
+
+// shellQuote quotes s for safe use in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
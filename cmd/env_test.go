@@ -0,0 +1,41 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnv_ShellQuoting(t *testing.T) {
+	dataHome := filepath.Join(t.TempDir(), "it's my $HOME")
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(dataHome)
+	t.Cleanup(cleanup)
+	cfg.General.Editor = "code -w"
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+
+	c := cmd.NewEnvCmd(deps)
+	var out bytes.Buffer
+	c.SetOut(&out)
+	c.SetErr(&bytes.Buffer{})
+	c.SetArgs([]string{"--shell"})
+	require.NoError(t, c.Execute())
+
+	script := out.String()
+	assert.Contains(t, script, "export EXO_EDITOR='code -w'\n")
+	assert.Contains(t, script, "export EXO_DATA_HOME='"+strings.ReplaceAll(dataHome, "'", `'\''`)+"'\n")
+
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell to evaluate the output")
+	}
+	got, err := exec.Command(sh, "-c", script+`printf '%s\n%s' "$EXO_DATA_HOME" "$EXO_EDITOR"`).Output()
+	require.NoError(t, err)
+	assert.Equal(t, dataHome+"\ncode -w", string(got))
+}
@@ -0,0 +1,661 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/journal"
+	"github.com/a-kostevski/exo/pkg/notion"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewExportCmd creates a new "export" command with the "html", "json" and "pdf"
+// subcommands. Given notes and --via pandoc, it converts them to any format pandoc
+// writes.
+func NewExportCmd(deps Dependencies) *cobra.Command {
+	var (
+		via    string
+		to     string
+		out    string
+		title  string
+		report string
+	)
+
+	exportCmd := &cobra.Command{
+		Use:   "export [<note|query>... --via pandoc --to <format>]",
+		Short: "Export the vault to other formats",
+		Long: `Export the vault, or some notes, to other formats.
+
+Wikilinks are resolved by the path, file name, title, ID or alias of the note
+they name, so they keep working whatever the exported files are called. Links
+to missing notes, to more than one note or to notes left out of the export are
+replaced by their text and logged; --report writes them to a JSON file.
+
+With --via pandoc, convert a note, or the notes matching a query (see "exo
+search"), to a format pandoc writes, such as docx, epub or latex. The notes are
+given to pandoc as one Markdown document, in title order, with embeds expanded,
+links between them kept and a metadata block with the title and date (and the
+frontmatter of a single note). Set the pandoc executable and the arguments and
+file extension of each format under export in the configuration:
+
+  export:
+    pandoc: /usr/local/bin/pandoc
+    formats:
+      docx:
+        args: [--reference-doc=~/notes/reference.docx]
+      latex:
+        args: [--toc]`,
+		Example: examples(
+			ex("exo export html --out site", "Render the vault as a static site"),
+			ex("exo export json", "Write the vault to a JSON bundle"),
+			ex(`exo export pdf "Go channels"`, "Print a note to Go channels.pdf"),
+			ex(`exo export "Go channels" --via pandoc --to docx`, "Convert a note to Go channels.docx"),
+			ex(`exo export '#book' --via pandoc --to epub --title Reading --out reading.epub`, "Bundle the notes tagged #book as an e-book"),
+		),
+		Args: cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 && via == "" {
+				return cmd.Help()
+			}
+			switch {
+			case via == "":
+				return exoerrors.New(exoerrors.Usage, "unknown export format %q (want html, json or pdf, or notes with --via pandoc)", args[0])
+			case via != "pandoc":
+				return exoerrors.New(exoerrors.Usage, "invalid --via %q (want pandoc)", via)
+			case to == "":
+				return exoerrors.New(exoerrors.Usage, "--to is required with --via pandoc")
+			case len(args) == 0:
+				return exoerrors.New(exoerrors.Usage, "no note or query to export")
+			}
+			return exportPandoc(cmd, deps, args, to, out, title, report)
+		},
+	}
+
+	flags := exportCmd.Flags()
+	flags.StringVar(&via, "via", "", "Program to convert notes with: pandoc")
+	flags.StringVar(&to, "to", "", "Output format of pandoc, e.g. docx, epub or latex")
+	flags.StringVarP(&out, "out", "o", "", "File to write (default: the title with the extension of the format)")
+	flags.StringVarP(&title, "title", "t", "", "Title of the document (default: the title of the note, or the query)")
+	flags.StringVar(&report, "report", "", "Write the unresolved links to this file as JSON")
+	_ = exportCmd.RegisterFlagCompletionFunc("via", cobra.FixedCompletions([]string{"pandoc"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = exportCmd.RegisterFlagCompletionFunc("to", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		formats := []string{"docx", "epub", "latex", "odt", "html", "rtf", "markdown", "pptx"}
+		for _, format := range slices.Sorted(maps.Keys(deps.Config.Export.Formats)) {
+			if !slices.Contains(formats, format) {
+				formats = append(formats, format)
+			}
+		}
+		return filterPrefix(formats, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	exportCmd.AddCommand(NewExportHTMLCmd(deps))
+	exportCmd.AddCommand(NewExportJSONCmd(deps))
+	exportCmd.AddCommand(NewExportPDFCmd(deps))
+	return exportCmd
+}
+
+// exportPandoc converts the notes named by args to the pandoc format to.
+func exportPandoc(cmd *cobra.Command, deps Dependencies, args []string, to, out, title, report string) error {
+	path, err := exec.LookPath(deps.Config.Export.Pandoc)
+	if err != nil {
+		return exoerrors.New(exoerrors.Config, "pandoc not found: %v (install pandoc or set export.pandoc)", err)
+	}
+	doc, err := exportDocument(deps, args, title)
+	if err != nil {
+		return err
+	}
+	// The metadata of a single note describes the document.
+	meta := make(map[string]interface{})
+	if len(doc.Notes) == 1 {
+		for k, v := range doc.Notes[0].Note.Meta {
+			if k != "title" {
+				meta[k] = v
+			}
+		}
+	}
+	markdown, err := doc.Markdown(meta, time.Now())
+	if err != nil {
+		return err
+	}
+
+	format := deps.Config.Export.Formats[strings.ToLower(to)]
+	if out == "" {
+		ext := format.Extension
+		if ext == "" {
+			ext = export.PandocExtension(to)
+		}
+		out = safeFileName(doc.Title) + "." + strings.TrimPrefix(ext, ".")
+	}
+	target := fs.ExpandPath(out)
+	if err := export.Pandoc(cmd.Context(), path, markdown, to, target, format.Args); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Exported %d note(s) to %s\n", len(doc.Notes), target)
+	return reportUnresolved(deps, doc.Unresolved, report)
+}
+
+// NewExportHTMLCmd creates the "export html" command, which renders the vault as a
+// static website.
+func NewExportHTMLCmd(deps Dependencies) *cobra.Command {
+	var out, report string
+
+	cmd := &cobra.Command{
+		Use:   "html",
+		Short: "Render all notes to a static HTML site",
+		Example: examples(
+			ex("exo export html --out site", "Render the vault as a static site in ./site"),
+		),
+		Long: `Render every note in the vault to HTML, suitable for publishing as a digital garden.
+
+Wikilinks are resolved to relative URLs (see "exo export"), an index page and one page per tag are
+generated, and attachments (any non-note file) are copied alongside the pages.
+The template directory and hidden files are skipped.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, err := filepath.Abs(fs.ExpandPath(out))
+			if err != nil {
+				return fmt.Errorf("invalid output directory: %w", err)
+			}
+			result, err := export.HTML(export.HTMLOptions{
+				Source:  deps.Config.Dir.DataHome,
+				Out:     target,
+				Exclude: []string{deps.Config.Dir.TemplateDir, deps.Config.Dir.PluginDir},
+				Ignore:  vaultIgnore(deps),
+			})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported %d note(s), %d tag page(s) and %d attachment(s) to %s\n",
+				result.Notes, result.Tags, result.Attachments, target)
+			return reportUnresolved(deps, result.Unresolved, report)
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "site", "Directory to write the site to")
+	cmd.Flags().StringVar(&report, "report", "", "Write the unresolved links to this file as JSON")
+	return cmd
+}
+
+// NewExportJSONCmd creates the "export json" command, which writes the whole vault
+// to a single JSON bundle.
+func NewExportJSONCmd(deps Dependencies) *cobra.Command {
+	var out, report string
+
+	cmd := &cobra.Command{
+		Use:   "json",
+		Short: "Export the vault to a single JSON bundle",
+		Example: examples(
+			ex("exo export json --out vault.json", "Write the vault to vault.json"),
+			ex("exo export json --out - | jq '.notes | length'", "Stream the bundle to another tool"),
+		),
+		Long: `Write every note (verbatim, with its parsed frontmatter), the link graph between
+notes, attachments and a snapshot of the configuration to a single JSON file.
+Links not resolved to a note are listed, with the reason, under "unresolved".
+
+Restore the bundle with "exo import json", e.g. to back up the vault or move it to
+another machine without Git. Use --out - to write to standard output.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			bundle, err := export.NewBundle(export.JSONOptions{
+				Source:  deps.Config.Dir.DataHome,
+				Exclude: []string{deps.Config.Dir.TemplateDir, deps.Config.Dir.PluginDir},
+				Ignore:  vaultIgnore(deps),
+				Config:  deps.Config,
+			})
+			if err != nil {
+				return err
+			}
+			if out == "-" {
+				if err := export.WriteJSON(cmd.OutOrStdout(), bundle); err != nil {
+					return err
+				}
+				return reportUnresolved(deps, bundle.Unresolved, report)
+			}
+			if out == "" {
+				out = fmt.Sprintf("exo-%s.json", time.Now().Format(dailyDateLayout))
+			}
+			target := fs.ExpandPath(out)
+			f, err := os.Create(target)
+			if err != nil {
+				return fmt.Errorf("failed to create bundle: %w", err)
+			}
+			if err := export.WriteJSON(f, bundle); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write bundle: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported %d note(s) and %d file(s) to %s\n", len(bundle.Notes), len(bundle.Files), target)
+			return reportUnresolved(deps, bundle.Unresolved, report)
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Bundle file to write (default exo-<date>.json, - for stdout)")
+	cmd.Flags().StringVar(&report, "report", "", "Write the unresolved links to this file as JSON")
+	return cmd
+}
+
+// NewExportPDFCmd creates the "export pdf" command, which prints a note, or the
+// notes matching a query, to a PDF file.
+func NewExportPDFCmd(deps Dependencies) *cobra.Command {
+	var (
+		out      string
+		title    string
+		engine   string
+		css      string
+		tmpl     string
+		pageSize string
+		report   string
+		htmlOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pdf <note|query>...",
+		Short: "Print notes to a PDF file",
+		Example: examples(
+			ex(`exo export pdf "Go channels"`, "Print a note to Go channels.pdf"),
+			ex(`exo export pdf 'tag:project AND #exo' --title "Exo" --out exo.pdf`, "Print the notes matching a query to one file"),
+			ex(`exo export pdf "Go channels" --html --out channels.html`, "Write the page printed to PDF instead"),
+		),
+		Long: `Print a note, or the notes matching a query (see "exo search"), to a PDF file,
+one note per page in title order. Embeds are expanded, and wikilinks to notes
+of the file become links within it.
+
+Notes are laid out as an HTML page, which is printed with wkhtmltopdf or
+headless Chromium, whichever is found first on PATH. The program, page size,
+stylesheet and page template (an html/template given .Title, .PageSize, .CSS
+and .Notes with .ID, .Title and .HTML each) are set under export.pdf in the
+configuration or with flags:
+
+  export:
+    pdf:
+      engine: chromium
+      page_size: letter
+      css: ~/notes/print.css`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := deps.Config.Export.PDF
+			if !cmd.Flags().Changed("engine") {
+				engine = cfg.Engine
+			}
+			if !cmd.Flags().Changed("css") {
+				css = cfg.CSS
+			}
+			if !cmd.Flags().Changed("template") {
+				tmpl = cfg.Template
+			}
+			if !cmd.Flags().Changed("page-size") {
+				pageSize = cfg.PageSize
+			}
+
+			doc, err := exportDocument(deps, args, title)
+			if err != nil {
+				return err
+			}
+			opts := export.PageOptions{PageSize: pageSize}
+			if opts.CSS, err = readExportFile(css, "stylesheet"); err != nil {
+				return err
+			}
+			if opts.Template, err = readExportFile(tmpl, "page template"); err != nil {
+				return err
+			}
+			page, err := doc.HTML(opts)
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.Config, err)
+			}
+
+			ext := ".pdf"
+			if htmlOnly {
+				ext = ".html"
+			}
+			if out == "" {
+				out = safeFileName(doc.Title) + ext
+			}
+			target := fs.ExpandPath(out)
+			if htmlOnly {
+				if err := os.WriteFile(target, []byte(page), 0644); err != nil {
+					return fmt.Errorf("failed to write page: %w", err)
+				}
+			} else {
+				path, err := export.FindPDFEngine(engine)
+				if err != nil {
+					return exoerrors.Wrap(exoerrors.Config, err)
+				}
+				if err := export.PDF(cmd.Context(), path, page, target); err != nil {
+					return err
+				}
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Exported %d note(s) to %s\n", len(doc.Notes), target)
+			return reportUnresolved(deps, doc.Unresolved, report)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&out, "out", "o", "", "File to write (default: the title with a .pdf extension)")
+	flags.StringVarP(&title, "title", "t", "", "Title of the document (default: the title of the note, or the query)")
+	flags.StringVar(&engine, "engine", "", "wkhtmltopdf or Chromium executable (default: export.pdf.engine, or the first found)")
+	flags.StringVar(&css, "css", "", "Stylesheet file (default: export.pdf.css)")
+	flags.StringVar(&tmpl, "template", "", "HTML page template file (default: export.pdf.template)")
+	flags.StringVar(&pageSize, "page-size", "", "Page size, e.g. A4 or letter (default: export.pdf.page_size)")
+	flags.StringVar(&report, "report", "", "Write the unresolved links to this file as JSON")
+	flags.BoolVar(&htmlOnly, "html", false, "Write the HTML page instead of printing it")
+	return cmd
+}
+
+// exportDocument returns the document of the notes named by args: the note
+// args name or else the notes matching the query args make up, in title
+// order. The title defaults to the one of the note, or the query.
+func exportDocument(deps Dependencies, args []string, title string) (*export.Document, error) {
+	all, err := vaultNotes(deps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notes: %w", err)
+	}
+	names := scan.NewNames(deps.Config.Dir.DataHome, all)
+
+	var notes []scan.Note
+	if len(args) == 1 {
+		if path, err := resolveNotePath(deps, args[0]); err == nil {
+			n, err := scan.ReadNote(path)
+			if err != nil {
+				return nil, exoerrors.Wrap(exoerrors.IO, err)
+			}
+			notes = []scan.Note{n}
+		} else if !exoerrors.Is(err, exoerrors.NotFound) {
+			return nil, err
+		}
+	}
+	if notes == nil {
+		input := strings.Join(args, " ")
+		q, err := parseQuery(input)
+		if err != nil {
+			return nil, err
+		}
+		if notes, err = selectNotes(deps, q); err != nil {
+			return nil, err
+		}
+		if len(notes) == 0 {
+			return nil, exoerrors.New(exoerrors.NotFound, "no note matches %s", input)
+		}
+		if err := scan.Sort(notes, scan.SortByTitle); err != nil {
+			return nil, err
+		}
+	}
+	if title == "" {
+		title = strings.Join(args, " ")
+		if len(notes) == 1 {
+			title = notes[0].Title
+		}
+	}
+	return export.NewDocument(title, notes, deps.Config.Dir.DataHome, names)
+}
+
+// reportUnresolved logs the links an export left unresolved and, when report
+// is set, writes them to that file as JSON.
+func reportUnresolved(deps Dependencies, links []export.UnresolvedLink, report string) error {
+	for _, l := range links {
+		deps.Logger.Infof("Unresolved link in %s: [[%s]] (%s)", l.From, l.Target, l.Reason)
+	}
+	if report == "" {
+		return nil
+	}
+	if links == nil {
+		links = []export.UnresolvedLink{}
+	}
+	data, err := json.MarshalIndent(links, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(fs.ExpandPath(report), append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write link report: %w", err)
+	}
+	return nil
+}
+
+// readExportFile returns the content of the file at path, or an empty string
+// when path is empty; what names the file in errors.
+func readExportFile(path, what string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(fs.ExpandPath(path))
+	if err != nil {
+		return "", exoerrors.New(exoerrors.Config, "failed to read %s: %v", what, err)
+	}
+	return string(data), nil
+}
+
+// NewImportCmd creates a new "import" command with the "json", "notion" and
+// "journal" subcommands.
+func NewImportCmd(deps Dependencies) *cobra.Command {
+	importCmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import a vault from other formats",
+		Example: examples(
+			ex("exo import json vault.json --to ~/notes", "Restore a JSON bundle into ~/notes"),
+			ex("exo import notion Export.zip", "Import a Notion export into the inbox"),
+			ex("exo import journal DayOne.zip", "Add Day One entries to daily notes"),
+		),
+	}
+	importCmd.AddCommand(NewImportJSONCmd(deps))
+	importCmd.AddCommand(NewImportNotionCmd(deps))
+	importCmd.AddCommand(NewImportJournalCmd(deps))
+	return importCmd
+}
+
+// NewImportJSONCmd creates the "import json" command, which restores a bundle
+// written by "export json" into an empty data home.
+func NewImportJSONCmd(deps Dependencies) *cobra.Command {
+	var (
+		to            string
+		restoreConfig bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "json <bundle>",
+		Short: "Restore a JSON bundle into an empty data home",
+		Example: examples(
+			ex("exo import json vault.json --to ~/notes", "Restore a JSON bundle into ~/notes"),
+			ex("exo import json vault.json --to ~/notes --restore-config", "Also restore the exported configuration"),
+		),
+		Long: `Restore the notes and attachments of a bundle written by "exo export json".
+
+The bundle is restored into the configured data home, or into --to, which must not
+exist or be empty. When --to names another directory, the configuration is updated
+to use it. With --restore-config, the configuration snapshot stored in the bundle
+replaces the current configuration, its directories rebased onto the new data home.
+Use - to read the bundle from standard input.`,
+		Annotations: mutates(),
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = cmd.InOrStdin()
+			if args[0] != "-" {
+				f, err := os.Open(fs.ExpandPath(args[0]))
+				if err != nil {
+					return fmt.Errorf("failed to open bundle: %w", err)
+				}
+				defer f.Close()
+				r = f
+			}
+			bundle, err := export.ReadJSON(r)
+			if err != nil {
+				return err
+			}
+
+			dest := deps.Config.Dir.DataHome
+			if to != "" {
+				if dest, err = filepath.Abs(fs.ExpandPath(to)); err != nil {
+					return fmt.Errorf("invalid destination: %w", err)
+				}
+			}
+			count, err := export.Restore(bundle, dest)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Restored %d file(s) into %s\n", count, dest)
+
+			switch {
+			case restoreConfig:
+				cfg, err := export.RestoreConfig(bundle, dest)
+				if err != nil {
+					return err
+				}
+				if cfg == nil {
+					return fmt.Errorf("bundle has no configuration snapshot")
+				}
+				if err := cfg.Save(); err != nil {
+					return fmt.Errorf("failed to save configuration: %w", err)
+				}
+				deps.Logger.Info("Restored configuration from bundle")
+			case dest != deps.Config.Dir.DataHome:
+				deps.Config.RebaseDirs(deps.Config.Dir.DataHome, dest)
+				if err := deps.Config.Save(); err != nil {
+					return fmt.Errorf("failed to save configuration: %w", err)
+				}
+				deps.Logger.Infof("Configured %s as the data home", dest)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "Data home to restore into (default: the configured data_home)")
+	cmd.Flags().BoolVar(&restoreConfig, "restore-config", false, "Replace the configuration with the snapshot in the bundle")
+	return cmd
+}
+
+// NewImportNotionCmd creates the "import notion" command, which converts the
+// Markdown and CSV export of a Notion workspace into notes in the inbox.
+func NewImportNotionCmd(deps Dependencies) *cobra.Command {
+	var databases string
+
+	cmd := &cobra.Command{
+		Use:   "notion <export.zip>",
+		Short: "Import a Notion export into the inbox",
+		Example: examples(
+			ex("exo import notion Export.zip", "Import a Notion export into the inbox"),
+			ex("exo import notion Export.zip --databases table", "Keep database properties as tables"),
+		),
+		Long: `Import the "Markdown & CSV" export of a Notion workspace into the inbox for triage.
+
+Pages become notes named after their titles, without the IDs Notion appends to file
+names, numbered when a note of the same name exists. Links between pages become
+wikilinks, and images and other files are copied to the attachments directory of the
+inbox. Each database becomes a note listing its rows. With --databases frontmatter,
+the default, the properties of each row move to the frontmatter of its note; with
+--databases table, the database note holds a table of the rows and their properties.`,
+		Annotations: mutates(),
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mode := notion.DatabaseMode(databases)
+			if mode != notion.Frontmatter && mode != notion.Table {
+				return exoerrors.New(exoerrors.Usage, "invalid --databases %q (want frontmatter or table)", databases)
+			}
+			dest := deps.Config.Dir.InboxDir
+			result, err := notion.Import(fs.ExpandPath(args[0]), notion.Options{Dest: dest, Databases: mode})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d note(s), %d database(s) and %d attachment(s) into %s\n",
+				len(result.Notes)-result.Databases, result.Databases, result.Attachments, dest)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&databases, "databases", string(notion.Frontmatter), "How to import database properties: frontmatter or table")
+	_ = cmd.RegisterFlagCompletionFunc("databases", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix([]string{string(notion.Frontmatter), string(notion.Table)}, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// NewImportJournalCmd creates the "import journal" command, which adds the
+// entries of a Day One or generic JSON journal to the daily notes of their days.
+func NewImportJournalCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "journal <export>",
+		Aliases: []string{"dayone"},
+		Short:   "Add the entries of a Day One or JSON journal to daily notes",
+		Example: examples(
+			ex("exo import journal DayOne.zip", "Add Day One entries to daily notes"),
+			ex("exo import journal journal.json", "Add the entries of a JSON journal"),
+		),
+		Long: `Add the entries of a journal to the daily notes of the days they were written,
+creating the notes as needed.
+
+The journal is a Day One export, as a zip archive or its JSON file, or a JSON list of
+entries, each with a "date" (YYYY-MM-DD, with an optional time, or RFC 3339), a "text"
+or "body", and optionally a "title", "tags", "location", "weather" and "media", the
+paths of files relative to the journal.
+
+Each entry becomes a section headed by its time and title, appended to the note.
+Entries already in the note are skipped, so a journal can be imported again. Media
+are copied to the attachments directory next to the daily notes, and the locations,
+weather and tags of entries are added to the frontmatter.`,
+		Annotations: mutates(),
+		Args:        cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := journal.Read(fs.ExpandPath(args[0]))
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return exoerrors.New(exoerrors.NotFound, "no entries in %s", args[0])
+			}
+			var days []time.Time
+			byDay := make(map[time.Time][]journal.Entry)
+			for _, e := range entries {
+				for _, name := range e.Skipped {
+					deps.Logger.Errorf("Skipping media %q of the entry of %s: not a file of the export", name, e.Time.Format("2006-01-02 15:04"))
+				}
+				day := e.Date()
+				if _, ok := byDay[day]; !ok {
+					days = append(days, day)
+				}
+				byDay[day] = append(byDay[day], e)
+			}
+
+			imported, notes := 0, 0
+			for _, day := range days {
+				daily, err := periodic.NewDailyNote(cmd.Context(), day, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+				if err != nil {
+					return fmt.Errorf("failed to create daily note: %w", err)
+				}
+				links, err := journal.CopyMedia(filepath.Join(filepath.Dir(daily.Path()), journal.AttachmentsDir), byDay[day])
+				if err != nil {
+					return err
+				}
+				content, added, err := journal.Add(daily.Content(), byDay[day], links)
+				if err != nil {
+					return fmt.Errorf("failed to update %s: %w", daily.Path(), err)
+				}
+				if added == 0 {
+					continue
+				}
+				if err := daily.SetContent(content); err != nil {
+					return err
+				}
+				if err := daily.Save(); err != nil {
+					return fmt.Errorf("failed to save daily note: %w", err)
+				}
+				imported += added
+				notes++
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %d of %d entries into %d daily note(s)\n", imported, len(entries), notes)
+			return nil
+		},
+	}
+	return cmd
+}
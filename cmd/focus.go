@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/focus"
+)
+
+// NewFocusCmd returns a new cobra.Command for the "focus" command, which scopes
+// the commands of a shell session to a project or tag.
+func NewFocusCmd(deps Dependencies) *cobra.Command {
+	var clear bool
+
+	cmd := &cobra.Command{
+		Use:   "focus [project|#tag]",
+		Short: "Scope the shell session to a project or tag",
+		Example: examples(
+			ex(`eval "$(exo focus website)"`, `Focus the shell session on the "website" project`),
+			ex(`eval "$(exo focus "#reading")"`, "Focus on notes tagged #reading"),
+			ex(`eval "$(exo focus --clear)"`, "Clear the focus"),
+		),
+		Long: `Scope subsequent commands in the shell session to a project or tag.
+
+The focus is kept in the EXO_FOCUS environment variable, so the output of this
+command must be evaluated by the shell:
+
+  eval "$(exo focus website)"    # a directory under projects_dir
+  eval "$(exo focus '#go')"      # a tag
+  eval "$(exo focus --clear)"
+
+While focused, "zet list" only shows notes in the context (use --all to see every
+note), and new zettels are tagged with the tag or assigned to the project.
+A name is taken as a project when projects_dir contains a directory of that name,
+and as a tag otherwise; use the tag:<name> or project:<name> form to be explicit.
+Without arguments, the current focus is printed.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if clear {
+				fmt.Fprintf(out, "unset %s\n", focus.EnvVar)
+				return nil
+			}
+			if len(args) == 0 {
+				f, err := focus.FromEnv()
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "Focus: %s\n", f)
+				return nil
+			}
+			f, err := resolveFocus(deps, args[0])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "export %s=%s\n", focus.EnvVar, shellQuote(f.Token()))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&clear, "clear", false, "Remove the focus from the session")
+	return cmd
+}
+
+// resolveFocus interprets a focus argument: "#tag", "tag:name", "project:name", or a
+// bare name that is a project if a directory of that name exists under projects_dir.
+func resolveFocus(deps Dependencies, arg string) (focus.Focus, error) {
+	switch {
+	case strings.HasPrefix(arg, "#"):
+		return focus.Parse("tag:" + arg)
+	case strings.Contains(arg, ":"):
+		return focus.Parse(arg)
+	}
+	f := focus.Focus{Kind: focus.KindProject, Name: arg}
+	if info, err := os.Stat(f.ProjectDir(deps.Config.Dir.ProjectsDir)); err == nil && info.IsDir() {
+		return f, nil
+	}
+	return focus.Parse("tag:" + arg)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/grep"
+	"github.com/a-kostevski/exo/pkg/query"
+)
+
+// NewGrepCmd returns a new cobra.Command for the "grep" command, which finds
+// the lines of notes matching a pattern.
+func NewGrepCmd(deps Dependencies) *cobra.Command {
+	var (
+		regex      bool
+		ignoreCase bool
+		noteType   string
+		tag        string
+		format     string
+		open       bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "grep <pattern>",
+		Short: "Find the lines of notes matching a pattern",
+		Long: `Find the lines of the notes in the vault containing a text, or matching a
+regular expression with --regexp, and print each as
+"path:line: Title > Heading > Subheading: line", with the headings it is under.
+
+--type and --tag only search the notes of a type or with a tag, read from the
+note index. --open opens the first match in the editor, at its line when the
+editor is known to take one (vim, nvim, nano, emacs…). The command fails when
+nothing matches.`,
+		Example: examples(
+			ex("exo grep goroutine", "Find the lines mentioning goroutines"),
+			ex(`exo grep -E -i 'todo|fixme' --type project`, "Find TODOs and FIXMEs in project notes"),
+			ex("exo grep --tag go --open 'select {'", "Open the first Go note using select"),
+			ex("exo grep channel --format json", "Output the matches as JSON"),
+		),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return exoerrors.New(exoerrors.Usage, "invalid --format %q (want text or json)", format)
+			}
+			g, err := grep.New(grep.Options{Pattern: args[0], Regexp: regex, IgnoreCase: ignoreCase})
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.Usage, err)
+			}
+			q, err := parseQuery("", fieldTerm(query.Type, "", noteType), fieldTerm(query.Tag, "", tag))
+			if err != nil {
+				return err
+			}
+			notes, err := selectNotes(deps, q)
+			if err != nil {
+				return err
+			}
+			matches, err := g.Notes(cmd.Context(), notes)
+			if err != nil {
+				return err
+			}
+			if len(matches) == 0 {
+				cmd.SilenceUsage = true
+				return exoerrors.New(exoerrors.NotFound, "no line matches %q", args[0])
+			}
+
+			out := cmd.OutOrStdout()
+			if format == "json" {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(matches); err != nil {
+					return err
+				}
+			} else {
+				for _, m := range matches {
+					fmt.Fprintln(out, m)
+				}
+			}
+			if open {
+				first := matches[0]
+				return deps.FS.OpenInEditor(first.Path, fs.OSEnv().EditorAtLine(deps.Config.General.Editor, first.Line))
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&regex, "regexp", "E", false, "Treat the pattern as a regular expression")
+	flags.BoolVarP(&ignoreCase, "ignore-case", "i", false, "Ignore case")
+	flags.StringVar(&noteType, "type", "", "Only notes of this type (frontmatter type or top-level directory)")
+	flags.StringVarP(&tag, "tag", "t", "", "Only notes with this tag")
+	flags.StringVarP(&format, "format", "f", "text", "Output format: text or json")
+	flags.BoolVarP(&open, "open", "o", false, "Open the first match in the editor")
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeTags(deps))
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/habit"
+	"github.com/a-kostevski/exo/pkg/periodic"
+)
+
+// NewHabitCmd returns a new cobra.Command for the "habit" command, which
+// tracks habits in the daily notes.
+func NewHabitCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "habit",
+		Short: "Track habits in the daily notes",
+		Long: `Track habits in the daily notes.
+
+Habits are defined under "habits" in the configuration. Checking a habit lists
+the habits as tasks under "` + habit.Heading + `" in the daily note, checked on the days
+they are done, so that check-ins can also be made by editing the note.`,
+		Example: examples(
+			ex(`exo habit add Run`, "Track a new habit"),
+			ex(`exo habit check Run`, "Check the habit in today's daily note"),
+			ex(`exo habit report --month 2025-02`, "Show the completion of habits in February 2025"),
+		),
+	}
+	cmd.AddCommand(newHabitAddCmd(deps))
+	cmd.AddCommand(newHabitCheckCmd(deps))
+	cmd.AddCommand(newHabitListCmd(deps))
+	cmd.AddCommand(newHabitReportCmd(deps))
+	return cmd
+}
+
+func newHabitAddCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:     "add <name>",
+		Short:   "Add a habit to the configuration",
+		Example: examples(ex(`exo habit add "Read 20 pages"`, "Track a new habit")),
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := strings.TrimSpace(args[0])
+			if name == "" {
+				return exoerrors.New(exoerrors.Usage, "habit name cannot be empty")
+			}
+			if _, ok := configuredHabit(deps, name); ok {
+				return exoerrors.New(exoerrors.Conflict, "habit %q already exists", name)
+			}
+			deps.Config.Habits = append(deps.Config.Habits, name)
+			if err := deps.Config.Save(); err != nil {
+				return exoerrors.Wrap(exoerrors.Config, fmt.Errorf("failed to save configuration: %w", err))
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Added habit %s\n", name)
+			return nil
+		},
+	}
+}
+
+func newHabitCheckCmd(deps Dependencies) *cobra.Command {
+	var (
+		dateFlag string
+		undo     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check <name>",
+		Short: "Check a habit in today's daily note",
+		Example: examples(
+			ex("exo habit check Run", "Check the habit in today's daily note"),
+			ex("exo habit check Run --date 2025-02-08", "Check it for another day"),
+			ex("exo habit check Run --undo", "Uncheck it"),
+		),
+		Annotations:       mutates(),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeHabits(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, ok := configuredHabit(deps, args[0])
+			if !ok {
+				return exoerrors.New(exoerrors.NotFound, "unknown habit %q (add it with \"exo habit add\")", args[0])
+			}
+			date, err := parseDayDate(dateFlag)
+			if err != nil {
+				return err
+			}
+			daily, err := periodic.NewDailyNote(cmd.Context(), date, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+			if err != nil {
+				return fmt.Errorf("failed to create daily note: %w", err)
+			}
+			if err := daily.SetContent(habit.Check(daily.Content(), deps.Config.Habits, name, !undo)); err != nil {
+				return err
+			}
+			if err := daily.Save(); err != nil {
+				return fmt.Errorf("failed to save daily note: %w", err)
+			}
+			verb := "Checked"
+			if undo {
+				verb = "Unchecked"
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s on %s\n", verb, name, date.Format(dailyDateLayout))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dateFlag, "date", "", "Check the habit on this date (YYYY-MM-DD) instead of today")
+	cmd.Flags().BoolVar(&undo, "undo", false, "Uncheck the habit")
+	return cmd
+}
+
+func newHabitListCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Short:   "List the habits with today's check-ins and streaks",
+		Example: examples(ex("exo habit list", "Show which habits are done today")),
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(deps.Config.Habits) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), `No habits defined; add one with "exo habit add"`)
+				return nil
+			}
+			dailyDir := filepath.Join(deps.Config.Dir.DataHome, "day")
+			today := periodic.DayOf(time.Now())
+			items, err := habit.Read(dailyDir, today)
+			if err != nil {
+				return err
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, name := range deps.Config.Habits {
+				streak, err := habit.Streak(dailyDir, name, today)
+				if err != nil {
+					return err
+				}
+				mark := " "
+				if habit.Done(items, name) {
+					mark = "x"
+				}
+				fmt.Fprintf(tw, "[%s] %s\t%d day streak\n", mark, name, streak)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+func newHabitReportCmd(deps Dependencies) *cobra.Command {
+	var month string
+
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Show the completion of habits over a month",
+		Long: `Show the completion of habits over a month, read from the daily notes: a row
+per habit and a column per day, with x on the days the habit was done, followed
+by the number of days it was done and its completion rate so far.`,
+		Example: examples(
+			ex("exo habit report", "Show this month's habits"),
+			ex("exo habit report --month 2025-02", "Show the habits of February 2025"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			start := time.Now()
+			if month != "" {
+				var err error
+				if start, err = time.Parse("2006-01", month); err != nil {
+					return exoerrors.New(exoerrors.Usage, "invalid --month %q (expected YYYY-MM)", month)
+				}
+			}
+			m, err := habit.Report(filepath.Join(deps.Config.Dir.DataHome, "day"), deps.Config.Habits, start, time.Now())
+			if err != nil {
+				return err
+			}
+			if len(m.Habits) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), `No habits defined; add one with "exo habit add"`)
+				return nil
+			}
+			return m.Render(cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&month, "month", "", "Month to report on (YYYY-MM, default: this month)")
+	return cmd
+}
+
+// configuredHabit returns the configured habit called name, compared
+// case-insensitively.
+func configuredHabit(deps Dependencies, name string) (string, bool) {
+	for _, h := range deps.Config.Habits {
+		if strings.EqualFold(h, strings.TrimSpace(name)) {
+			return h, true
+		}
+	}
+	return "", false
+}
+
+// completeHabits completes the names of the configured habits.
+func completeHabits(deps Dependencies) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterPrefix(deps.Config.Habits, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
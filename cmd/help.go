@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+)
+
+// example is a runnable command line documented in a command's help.
+type example struct {
+	command     string
+	description string
+}
+
+// ex returns an example running command, described by description.
+func ex(command, description string) example {
+	return example{command: command, description: description}
+}
+
+// examples renders examples for the Example field of a cobra.Command, which is shown
+// in the command's --help and in "exo help topics". Each example is a comment line
+// with its description followed by the command, so that it can be copied as is.
+func examples(list ...example) string {
+	blocks := make([]string, len(list))
+	for i, e := range list {
+		blocks[i] = "  # " + e.description + "\n  " + e.command
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// newHelpCmd returns the "help" command, which shows the help of a command and,
+// through "help topics", browses the commands and their examples.
+func newHelpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "help [command]",
+		Short: "Help about any command",
+		Long: `Show the help of any command, including its flags and examples.
+
+Use "exo help topics" to browse every command and its examples.`,
+		Example: examples(
+			ex("exo help zet fork", "Show the help of a subcommand"),
+			ex("exo help topics", "List every command"),
+		),
+		ValidArgsFunction: completeCommandPath,
+		Annotations:       tolerant(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			target, _, err := cmd.Root().Find(args)
+			if target == nil || err != nil {
+				return fmt.Errorf("unknown help topic %q", strings.Join(args, " "))
+			}
+			target.InitDefaultHelpFlag()
+			return target.Help()
+		},
+	}
+	cmd.AddCommand(newHelpTopicsCmd())
+	return cmd
+}
+
+// newHelpTopicsCmd returns the "help topics" command.
+func newHelpTopicsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "topics [command]",
+		Short: "Browse commands and their examples",
+		Long: `Without arguments, list every command with a short description. Given a
+command, show its description and examples.`,
+		Example: examples(
+			ex("exo help topics", "List every command"),
+			ex("exo help topics report trends", "Show the examples of a subcommand"),
+		),
+		ValidArgsFunction: completeCommandPath,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if len(args) == 0 {
+				return writeTopics(out, cmd.Root())
+			}
+			target, _, err := cmd.Root().Find(args)
+			if target == nil || err != nil || target == cmd.Root() {
+				return fmt.Errorf("unknown help topic %q", strings.Join(args, " "))
+			}
+			writeTopic(out, target)
+			return nil
+		},
+	}
+}
+
+// topicCommands returns the available commands below root, depth first.
+func topicCommands(root *cobra.Command) []*cobra.Command {
+	var out []*cobra.Command
+	for _, c := range root.Commands() {
+		if !c.IsAvailableCommand() {
+			continue
+		}
+		out = append(out, c)
+		out = append(out, topicCommands(c)...)
+	}
+	return out
+}
+
+// writeTopics lists every available command with its short description.
+func writeTopics(w io.Writer, root *cobra.Command) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	prefix := root.Name() + " "
+	for _, c := range topicCommands(root) {
+		fmt.Fprintf(tw, "  %s\t%s\n", strings.TrimPrefix(c.CommandPath(), prefix), c.Short)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\nUse \"%s help topics <command>\" to see the examples of a command.\n", root.Name())
+	return nil
+}
+
+// writeTopic shows the description and examples of c.
+func writeTopic(w io.Writer, c *cobra.Command) {
+	fmt.Fprintf(w, "%s - %s\n", c.CommandPath(), c.Short)
+	if c.Example != "" {
+		fmt.Fprintf(w, "\nExamples:\n%s\n", c.Example)
+	}
+	var subcommands []string
+	for _, sub := range c.Commands() {
+		if sub.IsAvailableCommand() {
+			subcommands = append(subcommands, sub.Name())
+		}
+	}
+	if len(subcommands) > 0 {
+		fmt.Fprintf(w, "\nSubcommands: %s\n", strings.Join(subcommands, ", "))
+	}
+	fmt.Fprintf(w, "\nUse \"%s --help\" for all flags.\n", c.CommandPath())
+}
+
+// completeCommandPath completes the next word of a command path, as taken by "help".
+func completeCommandPath(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	parent, _, err := cmd.Root().Find(args)
+	if err != nil || parent == nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, c := range parent.Commands() {
+		if c.IsAvailableCommand() && strings.HasPrefix(c.Name(), toComplete) {
+			names = append(names, c.Name()+"\t"+c.Short)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/tui"
+)
+
+// NewInboxCmd returns a new cobra.Command for the "inbox" command, which
+// captures notes into the inbox.
+func NewInboxCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inbox",
+		Short: "Capture notes into the inbox",
+		Example: examples(
+			ex(`exo inbox add "Read about CSP"`, "Capture a thought"),
+			ex(`curl -s https://example.com/notes.txt | exo inbox add --title "Example notes"`, "Capture the output of a command"),
+		),
+	}
+	cmd.AddCommand(newInboxAddCmd(deps))
+	return cmd
+}
+
+func newInboxAddCmd(deps Dependencies) *cobra.Command {
+	var capture captureOptions
+
+	cmd := &cobra.Command{
+		Use:   "add [text...]",
+		Short: "Capture text as a new note in the inbox",
+		Long: `Capture text as a new note in the inbox (dir.inbox_dir) without opening the
+editor, and print the path of the note.
+
+The text is given as arguments, or read from standard input when it is not a
+terminal or with --stdin. The title is taken from --title, or else from the
+first line of the text.`,
+		Example: examples(
+			ex(`exo inbox add "Read about CSP"`, "Capture a thought"),
+			ex(`git log -1 --format=%B | exo inbox add`, "Capture the last commit message, titled by its subject"),
+			ex(`exo inbox add --stdin --title "Dump" < notes.txt`, "Capture a file under a title"),
+		),
+		Annotations: mutates(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text := strings.Join(args, " ")
+			if capture.stdin || (len(args) == 0 && !tui.IsTerminal(os.Stdin)) {
+				if len(args) > 0 {
+					return exoerrors.New(exoerrors.Usage, "give the text as arguments or on standard input, not both")
+				}
+				in, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("failed to read standard input: %w", err)
+				}
+				text = string(in)
+			}
+			if strings.TrimSpace(text) == "" {
+				return exoerrors.New(exoerrors.Usage, "nothing to capture: give text as arguments or pipe it in")
+			}
+			inbox, err := filepath.Rel(deps.Config.Dir.DataHome, deps.Config.Dir.InboxDir)
+			if err != nil {
+				return err
+			}
+			n, err := captureZettel(cmd.Context(), deps, capture.title, text, note.WithSubDir(inbox))
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), n.Path())
+			return nil
+		},
+	}
+	capture.addFlags(cmd, "Read the text from standard input")
+	return cmd
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+)
+
+// indexFile is the note metadata index, in cache_dir.
+const indexFile = "index.db"
+
+// NewIndexCmd creates a new "index" command with rebuild and status subcommands.
+func NewIndexCmd(deps Dependencies) *cobra.Command {
+	indexCmd := &cobra.Command{
+		Use:   "index",
+		Short: "Manage the note metadata index",
+		Example: examples(
+			ex("exo index status", "Show how many notes are indexed"),
+			ex("exo index rebuild", "Index every note from scratch"),
+		),
+		Long: `Commands that list notes read their metadata (title, type, tags, dates and
+links) from an index kept in ` + indexFile + ` under cache_dir. The index is
+brought up to date before use by re-reading only the notes that changed, so
+there is normally no need to manage it; rebuild it if it gets out of step.`,
+	}
+	indexCmd.AddCommand(newIndexRebuildCmd(deps))
+	indexCmd.AddCommand(newIndexStatusCmd(deps))
+	return indexCmd
+}
+
+func newIndexRebuildCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rebuild",
+		Short: "Index every note from scratch",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ix, err := openIndex(deps)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			start := time.Now()
+			stats, err := ix.Rebuild(deps.Config.Dir.TemplateDir)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d notes in %s.\n", stats.Added, time.Since(start).Round(time.Millisecond))
+			return nil
+		},
+	}
+}
+
+func newIndexStatusCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Update the index and show what changed",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ix, err := openIndex(deps)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			stats, err := ix.Sync(deps.Config.Dir.TemplateDir)
+			if err != nil {
+				return err
+			}
+			count, err := ix.Count()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Index:     %s\n", deps.Config.Dir.CachePath(indexFile))
+			fmt.Fprintf(out, "Notes:     %d\n", count)
+			fmt.Fprintf(out, "Added:     %d\n", stats.Added)
+			fmt.Fprintf(out, "Updated:   %d\n", stats.Updated)
+			fmt.Fprintf(out, "Removed:   %d\n", stats.Removed)
+			return nil
+		},
+	}
+}
+
+// openIndex opens the note index of the vault, leaving out the paths of
+// vaultIgnore.
+func openIndex(deps Dependencies) (*store.Index, error) {
+	ix, err := store.Open(deps.Config.Dir.CachePath(indexFile), deps.Config.Dir.DataHome)
+	if err != nil {
+		return nil, err
+	}
+	ix.SetIgnore(vaultIgnore(deps))
+	return ix, nil
+}
+
+// vaultIgnore returns the paths of the vault scans leave out: those matching
+// the globs of .exoignore and of the ignore setting. An unreadable or invalid
+// .exoignore is logged and left aside.
+func vaultIgnore(deps Dependencies) *scan.Ignore {
+	ig, err := scan.LoadIgnore(deps.Config.Dir.DataHome, deps.Config.Ignore)
+	if err != nil {
+		deps.Logger.Errorf("Failed to load ignore patterns: %v", err)
+		ig, _ = scan.NewIgnore(deps.Config.Dir.DataHome, deps.Config.Ignore)
+	}
+	return ig
+}
+
+// indexedNotes returns the notes under data_home, excluding the template
+// directory, from the index after bringing it up to date.
+func indexedNotes(deps Dependencies) ([]scan.Note, error) {
+	ix, err := openIndex(deps)
+	if err != nil {
+		return nil, err
+	}
+	defer ix.Close()
+	if _, err := ix.Sync(deps.Config.Dir.TemplateDir); err != nil {
+		return nil, err
+	}
+	return ix.Notes(store.Query{})
+}
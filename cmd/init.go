@@ -1,54 +1,148 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
 	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/a-kostevski/exo/pkg/logger"
 	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/tui"
+	"github.com/a-kostevski/exo/pkg/vault"
 )
 
 // NewInitCmd returns a new "init" command that initializes configuration directories
 // and installs default templates. All dependencies are injected via the deps parameter.
 func NewInitCmd(deps Dependencies) *cobra.Command {
-	var force bool
+	var force, repair bool
 
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize exo configuration and directories",
+		Example: examples(
+			ex("exo init", "Create the configuration, directories and default templates"),
+			ex("exo init --repair", "Restore missing directories and templates of an existing vault"),
+		),
 		Long: `Initialize the exo configuration and create all necessary directories.
 If configuration already exists, it will not be overwritten unless --force is used.
 
-This command creates the required directories and installs the built-in default templates.`,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			// Use the injected configuration.
-			cfg := deps.Config
-
-			// Create required directories.
-			if err := ensureDirectories(cfg, deps.Logger, deps.FS); err != nil {
-				return fmt.Errorf("failed to create directories: %w", err)
-			}
+This command creates the required directories and installs the built-in default templates.
 
-			// Install default templates.
-			if err := installTemplates(cfg, force, deps.Logger, deps.FS); err != nil {
-				return fmt.Errorf("failed to install default templates: %w", err)
+With --repair, it only restores what is missing or broken, and can be run
+any number of times: missing directories are created, missing default
+templates installed, leaving customized ones as they are, and directories
+the owner cannot use and templates not in templates.file_mode get their
+permissions fixed. Every change is reported.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if repair {
+				if force {
+					return exoerrors.New(exoerrors.Usage, "--repair keeps customized templates; it cannot be used with --force")
+				}
+				if deps.ReadOnly {
+					cmd.SilenceUsage = true
+					return fmt.Errorf("--repair modifies the vault: %w (drop --read-only or set general.read_only to false)", fs.ErrReadOnly)
+				}
+				return repairVault(cmd.OutOrStdout(), deps.Config)
 			}
-
-			deps.Logger.Info("Initialization completed successfully")
-			return nil
+			return initVault(deps, force)
 		},
 	}
 
 	// Define GNU-friendly flag for forcing overwrites.
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Force overwrite existing configuration and templates")
+	cmd.Flags().BoolVar(&repair, "repair", false, "Only restore missing directories and templates, and fix permissions")
 	return cmd
 }
 
+// initVault creates the directories of the configuration and installs the
+// default templates, overwriting changed ones with force.
+func initVault(deps Dependencies, force bool) error {
+	if deps.ReadOnly {
+		return fmt.Errorf("setting exo up modifies the vault: %w (drop --read-only or set general.read_only to false)", fs.ErrReadOnly)
+	}
+	// Create required directories.
+	if err := ensureDirectories(deps.Config, deps.Logger); err != nil {
+		return fmt.Errorf("failed to create directories: %w", err)
+	}
+
+	// Install default templates.
+	if err := installTemplates(deps.Config, force, deps.Logger, deps.FS); err != nil {
+		return fmt.Errorf("failed to install default templates: %w", err)
+	}
+
+	deps.Logger.Info("Initialization completed successfully")
+	return nil
+}
+
+// repairVault restores the directories and default templates of cfg that
+// are missing and fixes their permissions, reporting every change to w.
+func repairVault(w io.Writer, cfg *config.Config) error {
+	fileMode, dirMode, err := cfg.Templates.Modes()
+	if err != nil {
+		return err
+	}
+	changes, err := vault.Repair(vault.RepairOptions{
+		Dirs:        []string{cfg.Dir.DataHome, cfg.Dir.IdeaDir, cfg.Dir.PeriodicDir, cfg.Dir.ZettelDir},
+		DirMode:     dirMode,
+		TemplateDir: cfg.Dir.TemplateDir,
+		FileMode:    fileMode,
+		Templates:   templates.NewEmbedTemplateStore(templates.DefaultTemplatesFS, templates.DefaultTemplateBaseDir),
+	})
+	for _, c := range changes {
+		fmt.Fprintln(w, c)
+	}
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "Nothing to repair")
+		return nil
+	}
+	fmt.Fprintf(w, "Made %d change(s)\n", len(changes))
+	return nil
+}
+
+// firstRun sets exo up, as "exo init" does, before cmd creates the first
+// notes of a vault whose data_home does not exist yet: right away with
+// --yes, else after asking on a terminal. Elsewhere, it fails with what to
+// run instead of leaving cmd to fail on the missing directories.
+func firstRun(deps Dependencies, cmd *cobra.Command) error {
+	if _, err := os.Stat(deps.Config.Dir.DataHome); !os.IsNotExist(err) {
+		return nil
+	}
+	yes, _ := cmd.Flags().GetBool("yes")
+	if !yes {
+		if !tui.IsTerminal(os.Stdin) {
+			cmd.SilenceUsage = true
+			return exoerrors.New(exoerrors.Config, "exo is not set up: %s does not exist (run \"exo init\", or run again with --yes to set it up)",
+				deps.Config.Dir.DataHome)
+		}
+		fmt.Fprintf(cmd.ErrOrStderr(), "exo is not set up yet: %s does not exist.\nCreate it and install the default templates now, as \"exo init\" does? [Y/n] ",
+			deps.Config.Dir.DataHome)
+		answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "", "y", "yes":
+		default:
+			cmd.SilenceUsage = true
+			return exoerrors.New(exoerrors.Usage, "exo is not set up: run \"exo init\" first")
+		}
+	}
+	return initVault(deps, false)
+}
+
 // ensureDirectories creates all required directories as defined in the configuration.
-func ensureDirectories(cfg *config.Config, log logger.Logger, fsys fs.FileSystem) error {
+func ensureDirectories(cfg *config.Config, log logger.Logger) error {
+	_, dirMode, err := cfg.Templates.Modes()
+	if err != nil {
+		return err
+	}
 	// List all directories that should exist.
 	dirs := []string{
 		cfg.Dir.DataHome,
@@ -59,7 +153,10 @@ func ensureDirectories(cfg *config.Config, log logger.Logger, fsys fs.FileSystem
 	}
 
 	for _, dir := range dirs {
-		if err := fsys.EnsureDirectoryExists(dir); err != nil {
+		if _, err := os.Stat(dir); err == nil {
+			continue
+		}
+		if err := os.MkdirAll(dir, dirMode); err != nil {
 			return fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 		log.Infof("Created directory %s", dir)
@@ -79,11 +176,17 @@ func installTemplates(cfg *config.Config, force bool, log logger.Logger, fsys fs
 		Reader:    &defaultInputReader{}, // Our interactive input reader implementation.
 	}
 
+	fileMode, dirMode, err := cfg.Templates.Modes()
+	if err != nil {
+		return err
+	}
+
 	// Build a TemplateConfig using the injected logger and file system.
 	tmplCfg := templates.TemplateConfig{
 		TemplateDir:       cfg.Dir.TemplateDir,
 		TemplateExtension: ".md",
-		FilePermissions:   0644,
+		FilePermissions:   fileMode,
+		DirPermissions:    dirMode,
 		Logger:            log,
 		FS:                fsys,
 	}
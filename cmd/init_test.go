@@ -0,0 +1,49 @@
+package cmd_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assertVaultDirs(t *testing.T, cfg config.Config) {
+	t.Helper()
+	for _, dir := range []string{cfg.Dir.DataHome, cfg.Dir.IdeaDir, cfg.Dir.TemplateDir, cfg.Dir.PeriodicDir, cfg.Dir.ZettelDir} {
+		assert.DirExists(t, dir)
+	}
+}
+
+func TestInit_CreatesDirectories(t *testing.T) {
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(filepath.Join(t.TempDir(), "vault"))
+	t.Cleanup(cleanup)
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+
+	c := cmd.NewInitCmd(deps)
+	c.SetOut(&bytes.Buffer{})
+	c.SetErr(&bytes.Buffer{})
+	c.SetArgs(nil)
+	require.NoError(t, c.Execute())
+	assertVaultDirs(t, cfg)
+}
+
+func TestFirstRun_CreatesDirectories(t *testing.T) {
+	dataHome := filepath.Join(t.TempDir(), "vault")
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(dataHome)
+	t.Cleanup(cleanup)
+	require.NoError(t, fsys.DeleteFile(dataHome))
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+
+	root := cmd.NewRootCmd(deps)
+	root.AddCommand(cmd.NewZetCmd(deps))
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"--yes", "zet", "First note"})
+	require.NoError(t, root.Execute())
+	assertVaultDirs(t, cfg)
+}
@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/lint"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewLintCmd returns a new cobra.Command for the "lint" command, which checks
+// notes against configurable rules.
+func NewLintCmd(deps Dependencies) *cobra.Command {
+	var (
+		rules  []string
+		list   bool
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "lint [note]...",
+		Short: "Check notes against style rules",
+		Long: `Check the given notes, or every note under data_home, against rules and report
+the issues found, one per line as "path:line: message (rule)":
+
+  frontmatter        notes lacking the fields in lint.required_fields, by type
+  schema             frontmatter not matching the schema of the note type
+  heading-hierarchy  headings skipping a level, or several level-one headings
+  untagged-zettel    zettels without tags
+  long-note          notes longer than lint.max_words words
+  stale-todo         open tasks and TODOs older than lint.todo_days days
+
+Rules are configured under "lint":
+
+  lint:
+    disabled: [long-note]
+    required_fields:
+      "*": [created]
+      book: [isbn, authors]
+    max_words: 1500
+    todo_days: 14
+
+An open TODO dates from the date (YYYY-MM-DD) written on its line or, failing
+that, from the creation of the note. Plugins can provide more rules (see "exo
+plugin"). The command fails when an issue is found.`,
+		Example: examples(
+			ex("exo lint", "Check every note"),
+			ex(`exo lint "Go channels" --rule heading-hierarchy`, "Check the headings of one note"),
+			ex("exo lint --list", "List the rules"),
+		),
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all := lintRules(deps)
+			out := cmd.OutOrStdout()
+			if list {
+				return listLintRules(out, all, deps.Config.Lint.Disabled)
+			}
+			if format != "text" && format != "json" {
+				return exoerrors.New(exoerrors.Usage, "invalid --format %q (want text or json)", format)
+			}
+			selected, err := lint.Select(all, rules, deps.Config.Lint.Disabled)
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.Usage, err)
+			}
+
+			notes, err := lintNotes(deps, args)
+			if err != nil {
+				return err
+			}
+			issues := lint.Run(notes, selected)
+			for i := range issues {
+				issues[i].Path = vaultPath(deps, issues[i].Path)
+			}
+
+			if format == "json" {
+				if issues == nil {
+					issues = []lint.Issue{}
+				}
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(issues); err != nil {
+					return err
+				}
+			} else {
+				for _, issue := range issues {
+					fmt.Fprintln(out, issue)
+				}
+			}
+			if len(issues) > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("found %d issue(s)", len(issues))
+			}
+			if format == "text" {
+				fmt.Fprintf(out, "Checked %d notes: no issues found\n", len(notes))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&rules, "rule", "r", nil, "Only run these rules, even if disabled (repeatable)")
+	cmd.Flags().BoolVar(&list, "list", false, "List the rules and whether they are enabled")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format: text or json")
+	_ = cmd.RegisterFlagCompletionFunc("rule", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for _, r := range lintRules(deps) {
+			names = append(names, r.Name())
+		}
+		return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// lintRules returns the built-in rules, configured from lint settings,
+// followed by the rules of plugins.
+func lintRules(deps Dependencies) []lint.Rule {
+	cfg := deps.Config.Lint
+	rules := []lint.Rule{
+		lint.RequiredFields{Fields: cfg.RequiredFields},
+		lint.Schema{Schemas: deps.Config.Schemas()},
+		lint.HeadingHierarchy{},
+		lint.UntaggedZettel{Types: []string{"zettel"}},
+		lint.LongNote{MaxWords: cfg.MaxWords},
+		lint.StaleTodo{Days: cfg.TodoDays, Now: time.Now()},
+	}
+	return append(rules, lint.PluginRules(deps.Plugins)...)
+}
+
+// listLintRules writes the rules to w, marking the disabled ones.
+func listLintRules(w io.Writer, rules []lint.Rule, disabled []string) error {
+	off := make(map[string]bool, len(disabled))
+	for _, name := range disabled {
+		off[strings.ToLower(name)] = true
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range rules {
+		state := "on"
+		if off[strings.ToLower(r.Name())] {
+			state = "off"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Name(), state, r.Description())
+	}
+	return tw.Flush()
+}
+
+// lintNotes returns the notes named by args, or every note under data_home
+// without args, with their content and type.
+func lintNotes(deps Dependencies, args []string) ([]lint.Note, error) {
+	var notes []scan.Note
+	if len(args) == 0 {
+		all, err := vaultNotes(deps)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search notes: %w", err)
+		}
+		notes = all
+	}
+	for _, arg := range args {
+		path, err := resolveNotePath(deps, arg)
+		if err != nil {
+			return nil, err
+		}
+		n, err := scan.ReadNote(path)
+		if err != nil {
+			return nil, exoerrors.Wrap(exoerrors.IO, err)
+		}
+		notes = append(notes, n)
+	}
+
+	out := make([]lint.Note, 0, len(notes))
+	for _, n := range notes {
+		content, err := deps.FS.ReadFile(n.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		out = append(out, lint.Note{Note: n, Type: lintNoteType(deps, n), Content: string(content)})
+	}
+	return out, nil
+}
+
+// lintNoteType returns the type of n, as told by note.Detect.
+func lintNoteType(deps Dependencies, n scan.Note) string {
+	return string(note.Detect(n.Path, n.Meta, noteTypeDirs(deps)))
+}
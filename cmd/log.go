@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/periodic"
+)
+
+// NewLogCmd returns a new cobra.Command for the "log" command, which appends
+// timestamped entries to today's daily note.
+func NewLogCmd(deps Dependencies) *cobra.Command {
+	var heading string
+
+	cmd := &cobra.Command{
+		Use:   "log [text...]",
+		Short: "Append a timestamped entry to today's daily note",
+		Example: examples(
+			ex(`exo log "Reviewed the quarterly plan"`, "Append an entry to today's daily note"),
+			ex("git log -1 --format=%s | exo log", "Log each line read from standard input"),
+		),
+		Annotations: mutates(),
+		Long: `Append a timestamped bullet to today's daily note, creating the note if needed.
+
+Entries are added to the Log section of daily.sections, as by
+"exo day append --section Log". Without a Log section, or when daily.log_heading
+is set, they go under daily.log_heading (default "## Notes") instead, and with
+--heading under that heading.
+When no text is given, each non-empty line read from standard input becomes an entry.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := logEntries(cmd, args)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				return fmt.Errorf("nothing to log")
+			}
+			now := time.Now()
+			today := periodic.DayOf(now)
+			daily, err := periodic.NewDailyNote(cmd.Context(), today, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+			if err != nil {
+				return fmt.Errorf("failed to create daily note: %w", err)
+			}
+			for _, entry := range entries {
+				if err := appendLog(deps, daily, heading, entry, now); err != nil {
+					return fmt.Errorf("failed to append to daily note: %w", err)
+				}
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&heading, "heading", "", "Heading to append under instead of the Log section")
+	return cmd
+}
+
+// logSection is the section of daily.sections "exo log" appends to.
+const logSection = "Log"
+
+// appendLog appends entry, logged at at, to daily: under heading if given,
+// else to the Log section unless daily.log_heading is set or there is no Log
+// section, else under daily.log_heading.
+func appendLog(deps Dependencies, daily *periodic.DailyNote, heading, entry string, at time.Time) error {
+	if heading == "" {
+		if _, ok := deps.Config.Daily.Section(logSection); ok && deps.Config.Origin("daily.log_heading").Kind == config.FromDefault {
+			return daily.AppendToSection(logSection, entry, at)
+		}
+		heading = deps.Config.Daily.LogHeading
+	}
+	return daily.AppendEntry(heading, entry, at)
+}
+
+// logEntries returns the entries to log: the joined arguments, or the non-empty
+// lines of standard input when no arguments are given.
+func logEntries(cmd *cobra.Command, args []string) ([]string, error) {
+	if len(args) > 0 {
+		return []string{strings.Join(args, " ")}, nil
+	}
+	var entries []string
+	scanner := bufio.NewScanner(cmd.InOrStdin())
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			entries = append(entries, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read standard input: %w", err)
+	}
+	return entries, nil
+}
@@ -0,0 +1,43 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_Section(t *testing.T) {
+	tests := []struct {
+		name     string
+		sections []config.DailySection
+		args     []string
+		want     string
+	}{
+		{"log section", []config.DailySection{{Name: "Tasks"}, {Name: "Log", Timestamp: true}, {Name: "Notes"}}, nil, "## Log"},
+		{"no log section", []config.DailySection{{Name: "Tasks"}}, nil, "## Notes"},
+		{"heading", []config.DailySection{{Name: "Log", Timestamp: true}}, []string{"--heading", "## Journal"}, "## Journal"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deps := newDayDeps(t)
+			deps.Config.Daily = config.DailyConfig{LogHeading: "## Notes", Sections: tt.sections}
+			c := cmd.NewLogCmd(deps)
+			c.SetOut(&bytes.Buffer{})
+			c.SetErr(&bytes.Buffer{})
+			c.SetArgs(append(tt.args, "Shipped the release"))
+			require.NoError(t, c.Execute())
+
+			path := filepath.Join(deps.Config.Dir.DataHome, "day", time.Now().Format("2006-01-02")+".md")
+			content, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Regexp(t, tt.want+`\n+- \d\d:\d\d Shipped the release\n`, string(content))
+		})
+	}
+}
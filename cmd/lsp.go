@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/lint"
+	"github.com/a-kostevski/exo/pkg/lsp"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/watch"
+)
+
+// NewLSPCmd returns a new cobra.Command for the "lsp" command, which runs a
+// language server over the vault on stdin and stdout.
+func NewLSPCmd(deps Dependencies) *cobra.Command {
+	var stdio bool
+
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server for editors",
+		Long: `Run a Language Server Protocol server over the vault on stdin and stdout, for
+Neovim, VS Code or any editor with an LSP client:
+
+  completion   note names after "[[", tags after "#"
+  definition   go to the note, or heading, a wikilink points to
+  references   the links to the current note from other notes
+  diagnostics  the issues found by "exo lint", as the note is edited
+
+Notes are looked up in the note index, which is kept up to date as notes are
+saved or change on disk. Lint rules disabled in lint.disabled are not run.
+
+In Neovim:
+
+  vim.lsp.start({ name = "exo", cmd = { "exo", "lsp" }, root_dir = vim.fn.expand("~/.local/share/exo") })`,
+		Example: examples(
+			ex("exo lsp", "Serve an editor over stdin and stdout"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rules, err := lint.Select(lintRules(deps), nil, deps.Config.Lint.Disabled)
+			if err != nil {
+				return err
+			}
+			ix, err := openIndex(deps)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			if _, err := ix.Sync(deps.Config.Dir.TemplateDir); err != nil {
+				return err
+			}
+			s, err := lsp.New(ix, lsp.Options{
+				DataHome: deps.Config.Dir.DataHome,
+				Rules:    rules,
+				NoteType: func(n scan.Note) string { return lintNoteType(deps, n) },
+			})
+			if err != nil {
+				return err
+			}
+
+			w, err := watch.New(deps.Config.Dir.DataHome, 100*time.Millisecond, deps.Config.Dir.TemplateDir)
+			if err != nil {
+				return err
+			}
+			defer w.Close()
+			ctx, stop := context.WithCancel(cmd.Context())
+			defer stop()
+			errOut := cmd.ErrOrStderr()
+			go func() {
+				_ = w.Run(ctx, func(events []watch.Event) {
+					paths := make([]string, len(events))
+					for i, ev := range events {
+						paths[i] = ev.Path
+					}
+					if err := s.Update(paths...); err != nil {
+						fmt.Fprintf(errOut, "Failed to update the index: %v\n", err)
+					}
+				})
+			}()
+			cmd.SilenceUsage = true
+			return s.Serve(cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	// Language clients commonly pass --stdio, the only transport.
+	cmd.Flags().BoolVar(&stdio, "stdio", true, "Communicate over stdin and stdout")
+	_ = cmd.Flags().MarkHidden("stdio")
+	return cmd
+}
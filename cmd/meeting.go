@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/calendar"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/tui"
+)
+
+// envCalendarPassword holds the password sent to calendar.source.
+const envCalendarPassword = calendar.PasswordEnv
+
+// NewMeetingCmd returns a new cobra.Command for the "meeting" command, which
+// creates a meeting note for an upcoming calendar event.
+func NewMeetingCmd(deps Dependencies) *cobra.Command {
+	var (
+		days    int
+		pick    int
+		list    bool
+		heading string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "meeting",
+		Short: "Create a meeting note for an upcoming calendar event",
+		Long: `List the events of the calendar configured as calendar.source from the start
+of today, pick one and create a meeting note filled with its title, time,
+location and attendees, linked from today's daily note.
+
+The source is the path of an ICS file, or the URL of an ICS feed or a CalDAV
+calendar. Set calendar.username and the EXO_CALENDAR_PASSWORD environment
+variable for calendars requiring a login:
+
+  calendar:
+    source: https://caldav.example.com/calendars/me/work/
+    username: me
+
+The note is created from the meeting template, which receives the event as the
+attendees, time and location variables; other variables are asked for as with
+"exo new".`,
+		Example: examples(
+			ex("exo meeting", "Pick one of today's meetings and create its note"),
+			ex("exo meeting --list --days 7", "List the meetings of the coming week"),
+			ex("exo meeting --pick 2", "Create the note of the second meeting without prompting"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if deps.Config.Calendar.Source == "" {
+				return fmt.Errorf("no calendar configured (set calendar.source with \"exo config set calendar.source <file or URL>\")")
+			}
+			if days < 1 {
+				return fmt.Errorf("--days must be at least 1")
+			}
+			now := time.Now()
+			from := periodic.DayOf(now)
+			src := calendar.Source{
+				Location: deps.Config.Calendar.Source,
+				Username: deps.Config.Calendar.Username,
+				Password: os.Getenv(envCalendarPassword),
+			}
+			events, err := calendar.Load(cmd.Context(), src, from, from.AddDate(0, 0, days))
+			if err != nil {
+				return err
+			}
+			if len(events) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No upcoming events")
+				return nil
+			}
+
+			out := cmd.OutOrStdout()
+			if list {
+				printEvents(out, events)
+				return nil
+			}
+			terminal := tui.IsTerminal(os.Stdin)
+			in := bufio.NewReader(cmd.InOrStdin())
+			if pick == 0 {
+				if !terminal {
+					return fmt.Errorf("no terminal to pick an event in (use --pick N; see --list)")
+				}
+				printEvents(cmd.ErrOrStderr(), events)
+				if pick, err = askEvent(cmd.ErrOrStderr(), in, len(events)); err != nil {
+					return err
+				}
+			}
+			if pick < 1 || pick > len(events) {
+				return fmt.Errorf("no event %d (there are %d)", pick, len(events))
+			}
+
+			ev := events[pick-1]
+			daily, err := periodic.NewDailyNote(cmd.Context(), periodic.DayOf(now), *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+			if err != nil {
+				return fmt.Errorf("failed to create daily note: %w", err)
+			}
+			sources := templates.VarSources{Given: meetingVars(ev), Env: os.LookupEnv}
+			if terminal {
+				sources.Ask = promptVar(cmd.ErrOrStderr(), in)
+				if len(ev.Attendees) == 0 {
+					// Ask for the attendees the calendar does not list.
+					delete(sources.Given, "attendees")
+				}
+			}
+			title := ev.Summary
+			if title == "" {
+				title = "Meeting"
+			}
+			fileName := ev.Start.Format(dailyDateLayout) + " " + safeFileName(title)
+			n, err := createTypedNote(cmd.Context(), deps, "meeting", title, fileName, sources)
+			if err != nil {
+				return err
+			}
+
+			if heading == "" {
+				heading = "## Meetings"
+			}
+			if err := daily.AppendEntry(heading, "[["+fileName+"]]", ev.Start.Local()); err != nil {
+				return fmt.Errorf("failed to append to daily note: %w", err)
+			}
+			return n.Open()
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 1, "Number of days, from the start of today, to list events for")
+	cmd.Flags().IntVar(&pick, "pick", 0, "Create the note of the Nth listed event without prompting")
+	cmd.Flags().BoolVar(&list, "list", false, "Only list the events")
+	cmd.Flags().StringVar(&heading, "heading", "", `Heading of the daily note the meeting is linked under (default "## Meetings")`)
+	return cmd
+}
+
+// printEvents writes the events as a numbered list.
+func printEvents(out io.Writer, events []calendar.Event) {
+	for i, ev := range events {
+		fmt.Fprintf(out, "%3d  %s  %s\n", i+1, eventTime(ev), ev.Summary)
+	}
+}
+
+// askEvent asks for the number of one of n listed events.
+func askEvent(out io.Writer, in *bufio.Reader, n int) (int, error) {
+	fmt.Fprintf(out, "Event [1-%d]: ", n)
+	line, err := in.ReadString('\n')
+	if err != nil && line == "" {
+		return 0, fmt.Errorf("failed to read the event: %w", err)
+	}
+	pick, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil {
+		return 0, fmt.Errorf("invalid event %q", strings.TrimSpace(line))
+	}
+	return pick, nil
+}
+
+// eventTime describes when ev takes place, in local time.
+func eventTime(ev calendar.Event) string {
+	start, end := ev.Start.Local(), ev.End.Local()
+	if ev.AllDay {
+		return start.Format("Mon 2006-01-02") + " all day"
+	}
+	if !end.After(start) {
+		return start.Format("Mon 2006-01-02 15:04")
+	}
+	return start.Format("Mon 2006-01-02 15:04") + "-" + end.Format("15:04")
+}
+
+// meetingVars returns the meeting template variables describing ev.
+func meetingVars(ev calendar.Event) map[string]string {
+	return map[string]string{
+		"attendees": strings.Join(ev.Attendees, ", "),
+		"time":      eventTime(ev),
+		"location":  ev.Location,
+	}
+}
@@ -0,0 +1,209 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/vault"
+)
+
+// NewMigrateCmd creates a new "migrate" command with the "vault" and "slugs"
+// subcommands.
+func NewMigrateCmd(deps Dependencies) *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate exo data between layouts or locations",
+		Example: examples(
+			ex("exo migrate vault --to ~/notes --dry-run", "Preview moving the vault to ~/notes"),
+			ex("exo migrate slugs --dry-run", "Preview renaming notes after the slug settings"),
+		),
+	}
+	migrateCmd.AddCommand(NewMigrateVaultCmd(deps))
+	migrateCmd.AddCommand(newMigrateSlugsCmd(deps))
+	return migrateCmd
+}
+
+// NewMigrateVaultCmd creates the "migrate vault" command, which moves the whole vault
+// to a new data home and switches the configuration over once the copy is verified.
+func NewMigrateVaultCmd(deps Dependencies) *cobra.Command {
+	var (
+		to     string
+		keep   bool
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "vault --to <new-data-home>",
+		Short: "Move the vault to a new data home",
+		Example: examples(
+			ex("exo migrate vault --to ~/notes --dry-run", "Preview moving the vault to ~/notes"),
+			ex("exo migrate vault --to ~/notes --keep", "Copy the vault, keeping the old one"),
+		),
+		Long: `Move the entire vault (including any Git history) to a new data home.
+
+Every file is copied and verified by checksum, and "git fsck" is run when the vault
+is a Git repository. Only then are the directory settings under the old data home
+rewritten in the configuration file that was loaded (the one given by --config, if
+any), and only once that is saved is the old vault removed (unless --keep).`,
+		Args:        cobra.NoArgs,
+		Annotations: mutates(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if to == "" {
+				return fmt.Errorf("--to is required")
+			}
+			target, err := filepath.Abs(fs.ExpandPath(to))
+			if err != nil {
+				return fmt.Errorf("invalid destination: %w", err)
+			}
+			from := deps.Config.Dir.DataHome
+
+			result, err := vault.Migrate(vault.MigrateOptions{
+				From:     from,
+				To:       target,
+				Keep:     keep,
+				DryRun:   dryRun,
+				ReadOnly: deps.ReadOnly,
+				Switch: func() error {
+					if deps.ReadOnly {
+						return fs.ErrReadOnly
+					}
+					deps.Config.RebaseDirs(from, target)
+					if err := deps.Config.SaveDirs(); err != nil {
+						return fmt.Errorf("failed to save configuration: %w", err)
+					}
+					return nil
+				},
+			})
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				fmt.Fprintf(cmd.OutOrStdout(), "Would move %d file(s) from %s to %s\n", result.Files, from, target)
+				return nil
+			}
+
+			if os.Getenv("EXO_DATA_HOME") != "" {
+				deps.Logger.Info("EXO_DATA_HOME is set and overrides the configured data home; update it to " + target)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Moved %d file(s) from %s to %s\n", result.Files, from, target)
+			if result.GitRepo {
+				fmt.Fprintln(cmd.OutOrStdout(), "Git history preserved and verified")
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", "New data home directory (must not exist or be empty)")
+	cmd.Flags().BoolVar(&keep, "keep", false, "Keep the old vault after migrating")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Only check what would be migrated")
+	return cmd
+}
+
+func newMigrateSlugsCmd(deps Dependencies) *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "slugs [dir...]",
+		Short: "Rename notes after the slug settings",
+		Long: `Rename the notes of the given directories, or of the whole vault but the
+periodic notes, to the file names new notes get under the slug settings
+(slug.style, slug.max_length, slug.stop_words and slug.date_prefix): the
+title of the note, slugged as of its creation date, after the ID its file name
+starts with, if any.
+
+Directories are named as in "exo mv". The Markdown links, path-qualified
+wikilinks and wikilinks by file name of other notes pointing to a renamed note
+are updated; wikilinks by title, ID or alias keep working without changes.
+Locked notes are skipped unless --force is given.`,
+		Example: examples(
+			ex("exo config set slug.style kebab && exo migrate slugs --dry-run", "Preview switching to kebab-case file names"),
+			ex("exo migrate slugs zettel inbox", "Rename the notes of the zettel and inbox directories"),
+		),
+		Annotations: mutates(),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return filterPrefix(noteDirNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var dirs []string
+			for _, arg := range args {
+				dir, err := noteDir(deps, arg)
+				if err != nil {
+					return err
+				}
+				dirs = append(dirs, dir)
+			}
+			if len(dirs) == 0 {
+				dirs = []string{deps.Config.Dir.DataHome}
+			}
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to search notes: %w", err)
+			}
+
+			opts := deps.Config.Slug.Options()
+			out := cmd.OutOrStdout()
+			renamed, skipped := 0, 0
+			for i := range notes {
+				n := notes[i]
+				if !slices.ContainsFunc(dirs, func(dir string) bool { return isWithin(dir, n.Path) }) ||
+					isWithin(deps.Config.Dir.PeriodicDir, n.Path) {
+					continue
+				}
+				name := vault.SlugName(n, opts)
+				if name == filepath.Base(n.Path) {
+					continue
+				}
+				if !deps.Force && n.Locked {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: the note is locked\n", vaultPath(deps, n.Path))
+					skipped++
+					continue
+				}
+				to := filepath.Join(filepath.Dir(n.Path), name)
+				if dryRun {
+					fmt.Fprintf(out, "Would rename %s to %s\n", vaultPath(deps, n.Path), vaultPath(deps, to))
+					renamed++
+					continue
+				}
+				result, err := vault.RenameNote(vault.RenameOptions{
+					Root:  deps.Config.Dir.DataHome,
+					From:  n.Path,
+					Name:  name,
+					Notes: notes,
+					FS:    deps.FS,
+				})
+				if exoerrors.Is(err, exoerrors.Conflict) {
+					fmt.Fprintf(cmd.ErrOrStderr(), "Skipped %s: %s already exists\n", vaultPath(deps, n.Path), vaultPath(deps, to))
+					skipped++
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				notes[i].Path = result.Path
+				fmt.Fprintf(out, "Renamed %s to %s\n", vaultPath(deps, n.Path), vaultPath(deps, result.Path))
+				for _, path := range result.Updated {
+					fmt.Fprintf(out, "  updated links in %s\n", vaultPath(deps, path))
+				}
+				for _, path := range result.Skipped {
+					fmt.Fprintf(cmd.ErrOrStderr(), "  skipped links in %s: the note is locked\n", vaultPath(deps, path))
+				}
+				renamed++
+			}
+			verb := "Renamed"
+			if dryRun {
+				verb = "Would rename"
+			}
+			fmt.Fprintf(out, "%s %d note(s), skipped %d\n", verb, renamed, skipped)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Only print the notes that would be renamed")
+	return cmd
+}
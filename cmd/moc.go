@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/moc"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewMOCCmd returns a new cobra.Command for the "moc" command, which generates
+// a map of content: an index note listing the notes with a tag or in a
+// directory.
+func NewMOCCmd(deps Dependencies) *cobra.Command {
+	var (
+		out     string
+		groupBy string
+		edit    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "moc <tag|folder>",
+		Short: "Generate or refresh a map of content for a tag or folder",
+		Long: `Generate a map of content: an index note linking to every note with a tag, or
+in a folder (a directory, absolute or relative to data_home), grouped by
+directory (--group-by dir), by their other tags (tag) or not at all (none).
+
+The note is "MOC <tag>.md" in data_home for a tag and "MOC <folder>.md" in the
+folder itself, unless --out is given. The list is written between
+"<!-- exo:begin moc -->" and "<!-- exo:end moc -->" markers: running the
+command again refreshes it, keeping whatever was written around the markers.`,
+		Example: examples(
+			ex("exo moc '#go'", "Map the notes tagged #go"),
+			ex("exo moc projects/exo --group-by none", "Map the notes of a folder in a flat list"),
+			ex(`exo moc go --group-by tag --out "Go index"`, "Refresh the map in another note"),
+		),
+		Annotations:       mutates(),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTags(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			group, err := moc.ParseGroupBy(groupBy)
+			if err != nil {
+				return exoerrors.Wrap(exoerrors.Usage, err)
+			}
+			topic, dir := mocTopic(deps, args[0])
+			if topic == "" {
+				return exoerrors.New(exoerrors.Usage, "give a tag or a folder to map")
+			}
+
+			name := strings.TrimPrefix(topic, "#")
+			path := filepath.Join(deps.Config.Dir.DataHome, "MOC "+safeFileName(name)+scan.NoteExtension)
+			if dir != "" {
+				name = filepath.Base(dir)
+				path = filepath.Join(dir, "MOC "+safeFileName(name)+scan.NoteExtension)
+			}
+			if out != "" {
+				if path, err = resolveNotePath(deps, out); err != nil {
+					path = filepath.Join(deps.Config.Dir.DataHome, safeFileName(out)+scan.NoteExtension)
+				}
+			}
+
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to search notes: %w", err)
+			}
+			var members []scan.Note
+			for _, n := range notes {
+				if n.Path == path {
+					continue
+				}
+				if (dir != "" && !isWithin(dir, n.Path)) || (dir == "" && !n.HasTag(topic)) {
+					continue
+				}
+				members = append(members, n)
+			}
+			root := deps.Config.Dir.DataHome
+			if dir != "" {
+				root = dir
+			}
+			list := moc.Render(members, group, root, topic)
+
+			content := "# MOC " + name + "\n"
+			if deps.FS.FileExists(path) {
+				data, err := deps.FS.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read note: %w", err)
+				}
+				content = string(data)
+			}
+			if err := deps.FS.EnsureDirectoryExists(path); err != nil {
+				return err
+			}
+			if err := deps.FS.WriteFile(path, []byte(note.ReplaceGenerated(content, moc.Block, list))); err != nil {
+				return fmt.Errorf("failed to write note: %w", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s (%d notes)\n", path, len(members))
+			if edit {
+				return deps.FS.OpenInEditor(path, deps.Config.General.Editor)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&out, "out", "o", "", "Note to write the map of content to")
+	cmd.Flags().StringVar(&groupBy, "group-by", string(moc.ByDir), "Group the notes by dir, tag or none")
+	cmd.Flags().BoolVarP(&edit, "edit", "e", false, "Open the map of content in the editor")
+	_ = cmd.RegisterFlagCompletionFunc("out", completeNoteNames(deps))
+	_ = cmd.RegisterFlagCompletionFunc("group-by", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterPrefix([]string{string(moc.ByDir), string(moc.ByTag), string(moc.ByNone)}, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	return cmd
+}
+
+// mocTopic returns the topic given as arg and, unless it is written as a tag
+// ("#go"), the absolute path of the directory it names, as given or relative
+// to data_home. dir is empty when the topic is a tag.
+func mocTopic(deps Dependencies, arg string) (topic, dir string) {
+	arg = strings.TrimSpace(arg)
+	if strings.HasPrefix(arg, "#") {
+		return arg, ""
+	}
+	for _, candidate := range []string{arg, filepath.Join(deps.Config.Dir.DataHome, arg)} {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			if abs, err := filepath.Abs(candidate); err == nil {
+				return arg, abs
+			}
+		}
+	}
+	return arg, ""
+}
+
+// isWithin reports whether path is inside dir.
+func isWithin(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/vault"
+)
+
+// NewMvCmd returns a new cobra.Command for the "mv" command, which moves a note
+// to another directory and updates the links to it.
+func NewMvCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "mv <note> <target-dir>",
+		Short: "Move a note to another directory, keeping links intact",
+		Long: `Move a note into another directory of the vault, keeping its file name.
+
+The target is one of the configured directories, named inbox, zettel, projects,
+ideas or archive, or a directory relative to data_home. The note's frontmatter
+type is set to the type of its new directory, its relative Markdown links are
+rewritten for the new location, and the Markdown links and path-qualified
+wikilinks ([[0-inbox/note]]) of other notes pointing to it are updated. Links by
+name or title keep working without changes. Locked notes are left as they are,
+unless --force is given.`,
+		Example: examples(
+			ex(`exo mv "Go channels" zettel`, "Promote a note from the inbox"),
+			ex(`exo mv "Go channels" projects/concurrency`, "Move a note into a project"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 0 {
+				return completeNoteNames(deps)(cmd, args, toComplete)
+			}
+			if len(args) == 1 {
+				return filterPrefix(noteDirNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, err := resolveNotePath(deps, args[0])
+			if err != nil {
+				return err
+			}
+			if err := checkUnlocked(deps, from); err != nil {
+				return err
+			}
+			toDir, err := noteDir(deps, args[1])
+			if err != nil {
+				return err
+			}
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to search notes: %w", err)
+			}
+			result, err := vault.MoveNote(vault.MoveOptions{
+				Root:  deps.Config.Dir.DataHome,
+				From:  from,
+				ToDir: toDir,
+				Type:  dirNoteType(deps, toDir),
+				Notes: notes,
+				FS:    deps.FS,
+			})
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Moved %s to %s\n", vaultPath(deps, from), vaultPath(deps, result.Path))
+			for _, path := range result.Updated {
+				fmt.Fprintf(out, "  updated links in %s\n", vaultPath(deps, path))
+			}
+			for _, path := range result.Skipped {
+				fmt.Fprintf(cmd.ErrOrStderr(), "  skipped links in %s: the note is locked\n", vaultPath(deps, path))
+			}
+			return nil
+		},
+	}
+}
+
+// noteDirNames returns the names of the configured note directories accepted
+// by noteDir.
+func noteDirNames() []string {
+	return []string{"archive", "ideas", "inbox", "projects", "zettel"}
+}
+
+// noteDir returns the directory named name: a configured note directory, or a
+// directory relative to data_home, which it must lie under.
+func noteDir(deps Dependencies, name string) (string, error) {
+	dirs := deps.Config.Dir
+	switch strings.ToLower(strings.TrimSuffix(name, "/")) {
+	case "inbox":
+		return dirs.InboxDir, nil
+	case "zettel":
+		return dirs.ZettelDir, nil
+	case "projects", "project":
+		return dirs.ProjectsDir, nil
+	case "ideas", "idea":
+		return dirs.IdeaDir, nil
+	case "archive":
+		return dirs.ArchiveDir, nil
+	}
+	dir := name
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(dirs.DataHome, dir)
+	}
+	if rel, err := filepath.Rel(dirs.DataHome, dir); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", exoerrors.New(exoerrors.Validation, "%s is outside the vault %s", name, dirs.DataHome)
+	}
+	return dir, nil
+}
+
+// dirNoteType returns the type of the notes in dir: that of the innermost
+// configured note directory containing it, or "" if there is none.
+func dirNoteType(deps Dependencies, dir string) string {
+	types := noteTypeDirs(deps)
+	parents := make([]string, 0, len(types))
+	for parent := range types {
+		parents = append(parents, parent)
+	}
+	sort.Slice(parents, func(i, j int) bool { return len(parents[i]) > len(parents[j]) })
+	for _, parent := range parents {
+		if rel, err := filepath.Rel(parent, dir); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return types[parent]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/slug"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/tui"
+)
+
+// NewNoteCmd returns a new cobra.Command for the "new" command, which creates a
+// note from a template or of a type provided by a plugin.
+func NewNoteCmd(deps Dependencies) *cobra.Command {
+	var vars []string
+
+	cmd := &cobra.Command{
+		Use:   "new <type> <title>",
+		Short: "Create a note from a template or a plugin-provided type",
+		Long: `Create a note of the given type: a note type provided by a plugin, created in
+the type's directory, or the name of a template, created in the inbox. Run
+"exo plugin list" and "exo templates" to see what is available.
+
+Templates can declare the data they need in a header comment:
+
+  {{/*
+  vars:
+    - name: attendees
+      prompt: Who is attending?
+      required: true
+    - name: project
+      default: exo
+  */ -}}
+
+and use it as {{.attendees}}. Values come from --var, then from EXO_VAR_<NAME>
+environment variables, then from a prompt when run in a terminal, and finally
+from the default.
+
+A type may also name a scaffold, a directory of templates and other files in
+the scaffolds directory of the template directory, such as
+scaffolds/project/{README.md,tasks.md,assets/logo.png}. The scaffold is
+created as a set, in a directory of the inbox named after the title: its .md
+files and the template actions in its file names are executed as templates,
+and other files are copied as they are.`,
+		Example: examples(
+			ex(`exo new meeting "Weekly sync" --var attendees="Ann, Bob" --var project=exo`, "Create a meeting note from the meeting template"),
+			ex(`EXO_VAR_PROJECT=exo exo new meeting "Planning"`, "Supply a template variable from the environment"),
+			ex(`exo new recipe "Pancakes"`, "Create a note of a plugin-provided type"),
+			ex(`exo new project "Garden shed"`, "Create the files of the project scaffold"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return filterPrefix(noteTypeNames(deps), toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			given, err := parseVars(vars)
+			if err != nil {
+				return err
+			}
+			sources := templates.VarSources{Given: given, Env: os.LookupEnv}
+			if tui.IsTerminal(os.Stdin) {
+				sources.Ask = promptVar(cmd.ErrOrStderr(), bufio.NewReader(cmd.InOrStdin()))
+			}
+			if isScaffold(deps, args[0]) {
+				dir, err := createScaffold(cmd.Context(), deps, args[0], args[1], sources)
+				if err != nil {
+					cmd.SilenceUsage = true
+					return err
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), dir)
+				return nil
+			}
+			n, err := createTypedNote(cmd.Context(), deps, args[0], args[1], "", sources)
+			if err != nil {
+				return err
+			}
+			return n.Open()
+		},
+	}
+
+	cmd.Flags().StringArrayVar(&vars, "var", nil, "Set a template variable, as key=value (repeatable)")
+	return cmd
+}
+
+// createTypedNote creates and saves a note of the given type, as "exo new" does,
+// filling the variables of its template from sources. The file name defaults to
+// the title, slugged as configured.
+func createTypedNote(ctx context.Context, deps Dependencies, typeName, title, fileName string, sources templates.VarSources) (note.Note, error) {
+	subDir, templateName := "", typeName
+	if noteType, ok := findNoteType(deps.Plugins, typeName); ok {
+		subDir, templateName = noteType.Dir, noteType.Template
+	} else {
+		rel, err := filepath.Rel(deps.Config.Dir.DataHome, deps.Config.Dir.InboxDir)
+		if err != nil {
+			return nil, err
+		}
+		subDir = rel
+	}
+	if fileName == "" {
+		fileName = noteFileName(deps, title)
+	}
+
+	// Types without a template of their own get a plain heading.
+	_, source, err := deps.TemplateManager.Resolve(templateName)
+	if err != nil {
+		if !errors.Is(err, iofs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		if templateName == typeName {
+			return nil, exoerrors.New(exoerrors.NotFound, "unknown note type %q (see \"exo plugin list\" and \"exo templates\")", typeName)
+		}
+	}
+
+	n, err := note.NewBaseNote(title, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS,
+		note.WithContext(ctx),
+		note.WithSubDir(subDir),
+		note.WithFileName(fileName+scan.NoteExtension),
+		note.WithTemplateName(templateName),
+		note.WithType(typeName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create note: %w", err)
+	}
+	if n.Exists() {
+		return nil, exoerrors.New(exoerrors.Conflict, "note %s already exists", n.Path())
+	}
+
+	content := "# " + title + "\n"
+	if source != "" {
+		declared, err := templates.ParseVars(source)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", templateName, err)
+		}
+		values, err := templates.ResolveVars(declared, sources)
+		if err != nil {
+			return nil, err
+		}
+		data := map[string]interface{}{
+			"Title": title,
+			"Type":  typeName,
+			"Date":  n.Created().Format(dailyDateLayout),
+		}
+		for k, v := range values {
+			data[k] = v
+		}
+		if content, err = deps.TemplateManager.ProcessTemplateWithContext(ctx, templateName, data); err != nil {
+			return nil, err
+		}
+	}
+	if content, err = focusContent(content); err != nil {
+		return nil, err
+	}
+	if err := n.SetContent(content); err != nil {
+		return nil, err
+	}
+	if err := n.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save note: %w", err)
+	}
+	return n, nil
+}
+
+// isScaffold reports whether typeName names a scaffold, being neither a
+// plugin note type nor a template.
+func isScaffold(deps Dependencies, typeName string) bool {
+	if _, ok := findNoteType(deps.Plugins, typeName); ok {
+		return false
+	}
+	if _, _, err := deps.TemplateManager.Resolve(typeName); !errors.Is(err, iofs.ErrNotExist) {
+		return false
+	}
+	_, _, err := deps.TemplateManager.ResolveScaffold(typeName)
+	return err == nil
+}
+
+// createScaffold creates the files of the scaffold name in a directory of the
+// inbox named after title, filling the variables of its templates from
+// sources, and returns the directory.
+func createScaffold(ctx context.Context, deps Dependencies, name, title string, sources templates.VarSources) (string, error) {
+	dir := filepath.Join(deps.Config.Dir.InboxDir, noteFileName(deps, title))
+	if deps.FS.FileExists(dir) {
+		return "", exoerrors.New(exoerrors.Conflict, "%s already exists", dir)
+	}
+	_, files, err := deps.TemplateManager.ResolveScaffold(name)
+	if err != nil {
+		return "", err
+	}
+	declared, err := templates.ScaffoldVars(files)
+	if err != nil {
+		return "", fmt.Errorf("scaffold %s: %w", name, err)
+	}
+	values, err := templates.ResolveVars(declared, sources)
+	if err != nil {
+		return "", err
+	}
+	data := map[string]interface{}{
+		"Title": title,
+		"Type":  name,
+		"Date":  time.Now().Format(dailyDateLayout),
+	}
+	for k, v := range values {
+		data[k] = v
+	}
+	if files, err = deps.TemplateManager.ProcessScaffold(ctx, name, data); err != nil {
+		return "", err
+	}
+	if err := templates.WriteScaffold(deps.FS, dir, files); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// parseVars parses key=value pairs given with --var.
+func parseVars(pairs []string) (map[string]string, error) {
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid --var %q (expected key=value)", pair)
+		}
+		values[strings.TrimSpace(key)] = value
+	}
+	return values, nil
+}
+
+// promptVar returns a templates.VarSources.Ask function asking on out and
+// reading answers from in.
+func promptVar(out io.Writer, in *bufio.Reader) func(templates.Var) (string, error) {
+	return func(v templates.Var) (string, error) {
+		prompt := v.Prompt
+		if prompt == "" {
+			prompt = v.Name
+		}
+		if v.Default != "" {
+			prompt += " [" + v.Default + "]"
+		}
+		fmt.Fprint(out, prompt+": ")
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return "", fmt.Errorf("failed to read %s: %w", v.Name, err)
+		}
+		return strings.TrimSpace(line), nil
+	}
+}
+
+// noteTypeNames returns the note types "exo new" accepts, described by where
+// they come from: plugin note types, installed templates, default templates
+// and scaffolds.
+func noteTypeNames(deps Dependencies) []string {
+	seen := make(map[string]bool)
+	var names []string
+	add := func(name, desc string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name+"\t"+desc)
+		}
+	}
+	for _, p := range deps.Plugins {
+		for _, t := range p.NoteTypes {
+			add(t.Name, p.Name+" plugin")
+		}
+	}
+	if installed, err := deps.TemplateManager.ListTemplates(); err == nil {
+		sort.Strings(installed)
+		for _, name := range installed {
+			add(name, "template")
+		}
+	}
+	if entries, err := templates.DefaultTemplatesFS.ReadDir(templates.DefaultTemplateBaseDir); err == nil {
+		for _, e := range entries {
+			add(strings.TrimSuffix(e.Name(), ".md"), "default template")
+		}
+	}
+	if scaffolds, err := deps.TemplateManager.ListScaffolds(); err == nil {
+		for _, name := range scaffolds {
+			add(name, "scaffold")
+		}
+	}
+	return names
+}
+
+// noteFileName returns the file name, without extension, of a new note titled
+// title, as the slug settings say.
+func noteFileName(deps Dependencies, title string) string {
+	return slug.Make(title, deps.Config.Slug.Options(), time.Now())
+}
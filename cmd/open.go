@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// NewOpenCmd returns a new cobra.Command for the "open" command, which opens a
+// note in the editor.
+func NewOpenCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "open <note>",
+		Short: "Open a note in the editor",
+		Long: `Open a note in the configured editor. The note may be given as a path, a file
+name (with or without .md), a title or one of the aliases listed in its
+frontmatter:
+
+  ---
+  aliases: [CSP, Communicating sequential processes]
+  ---`,
+		Example: examples(
+			ex(`exo open "Go channels"`, "Open a note by title or file name"),
+			ex("exo open CSP", "Open the note declaring the alias CSP"),
+		),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveNotePath(deps, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			return deps.FS.OpenInEditor(path, deps.Config.General.Editor)
+		},
+	}
+}
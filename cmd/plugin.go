@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/plugin"
+)
+
+// annotationPlugin marks commands provided by a plugin with the plugin name.
+const annotationPlugin = "exo.plugin"
+
+// NewPluginCmd returns a new cobra.Command for the "plugin" command.
+func NewPluginCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plugin",
+		Short: "Inspect installed plugins",
+		Long: `Plugins are executables in the plugin directory (dir.plugin_dir, by default
+plugins/ under data_home) that add subcommands, note types, template functions
+and lint rules.
+
+Invoked with --exo-manifest, a plugin prints a JSON manifest:
+
+  {"name": "recipes",
+   "commands": [{"name": "shop", "short": "Print a shopping list", "usage": "<note>"}],
+   "note_types": [{"name": "recipe", "dir": "recipes", "template": "recipe"}],
+   "template_funcs": ["servings"],
+   "lint_rules": [{"name": "recipe-yield", "description": "Recipes without a yield"}]}
+
+Commands run the plugin with the command name and its arguments; template
+functions run it with --exo-func <name> <args...> and use its output. Lint
+rules run it with --exo-lint <rule> <note path>, and it prints the issues found
+as a JSON array of {"line": 3, "message": "..."}, or nothing. Plugins
+receive EXO_DATA_HOME, EXO_TEMPLATE_DIR and EXO_CONFIG in their environment.`,
+		Example: examples(
+			ex("exo plugin list", "Show installed plugins and what they provide"),
+		),
+	}
+	cmd.AddCommand(newPluginListCmd(deps))
+	return cmd
+}
+
+// newPluginListCmd returns the "plugin list" command.
+func newPluginListCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List installed plugins",
+		Example: examples(
+			ex("exo plugin list", "Show installed plugins and what they provide"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			// Discover again so that plugins failing to load are reported.
+			plugins, err := plugin.Discover(cmd.Context(), deps.Config.Dir.PluginDir)
+			if len(plugins) == 0 && err == nil {
+				fmt.Fprintf(out, "No plugins installed in %s\n", deps.Config.Dir.PluginDir)
+				return nil
+			}
+			for _, p := range plugins {
+				fmt.Fprintf(out, "%s (%s)\n", p.Name, p.Path)
+				if p.Description != "" {
+					fmt.Fprintf(out, "  %s\n", p.Description)
+				}
+				for _, c := range p.Commands {
+					fmt.Fprintf(out, "  command:       %s", strings.TrimSpace(c.Name+" "+c.Usage))
+					if registered, _, err := cmd.Root().Find([]string{c.Name}); err != nil || registered.Annotations[annotationPlugin] != p.Name {
+						fmt.Fprint(out, " (unavailable: the name is taken by another command)")
+					}
+					fmt.Fprintln(out)
+				}
+				for _, t := range p.NoteTypes {
+					fmt.Fprintf(out, "  note type:     %s (in %s, template %s)\n", t.Name, t.Dir, t.Template)
+				}
+				for _, f := range p.TemplateFuncs {
+					fmt.Fprintf(out, "  template func: %s\n", f)
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("some plugins failed to load: %w", err)
+			}
+			return nil
+		},
+	}
+}
+
+// AddPluginCommands registers the commands of deps.Plugins on root. Commands whose
+// name is already taken by a built-in command or an earlier plugin are skipped,
+// which "exo plugin list" reports.
+func AddPluginCommands(root *cobra.Command, deps Dependencies) {
+	for _, p := range deps.Plugins {
+		for _, c := range p.Commands {
+			if existing, _, err := root.Find([]string{c.Name}); err == nil && existing != root {
+				continue
+			}
+			root.AddCommand(newPluginCommand(deps, p, c))
+		}
+	}
+}
+
+// newPluginCommand returns a command running the plugin command c of p, passing
+// every argument and flag through to the plugin.
+func newPluginCommand(deps Dependencies, p plugin.Plugin, c plugin.Command) *cobra.Command {
+	short := c.Short
+	if short == "" {
+		short = "Provided by the " + p.Name + " plugin"
+	}
+	annotations := mutates()
+	annotations[annotationPlugin] = p.Name
+	return &cobra.Command{
+		Use:                strings.TrimSpace(c.Name + " " + c.Usage),
+		Short:              short,
+		Annotations:        annotations,
+		DisableFlagParsing: true,
+		SilenceUsage:       true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			run := p.Command(cmd.Context(), c.Name, args...)
+			run.Stdin, run.Stdout, run.Stderr = cmd.InOrStdin(), cmd.OutOrStdout(), cmd.ErrOrStderr()
+			run.Env = plugin.Env(deps.Config.Dir.DataHome, deps.Config.Dir.TemplateDir, deps.Config.Source())
+			if err := run.Run(); err != nil {
+				return fmt.Errorf("plugin %s: %s: %w", p.Name, c.Name, err)
+			}
+			return nil
+		},
+	}
+}
+
+// findNoteType returns the plugin note type called name.
+func findNoteType(plugins []plugin.Plugin, name string) (plugin.NoteType, bool) {
+	for _, p := range plugins {
+		for _, t := range p.NoteTypes {
+			if t.Name == name {
+				return t, true
+			}
+		}
+	}
+	return plugin.NoteType{}, false
+}
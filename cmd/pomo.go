@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/notify"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/pomo"
+	"github.com/a-kostevski/exo/pkg/tui"
+)
+
+// NewPomoCmd returns a new cobra.Command for the "pomo" command, which runs a
+// pomodoro timer and logs it to today's daily note.
+func NewPomoCmd(deps Dependencies) *cobra.Command {
+	var silent bool
+
+	cmd := &cobra.Command{
+		Use:   "pomo [duration] [task...]",
+		Short: "Run a pomodoro timer and log it to today's daily note",
+		Long: `Run a pomodoro timer of 25 minutes, or the given duration (e.g. 50m, or 50 for
+minutes), counting down in the terminal.
+
+The start and the end of the pomodoro are logged to today's daily note as
+"exo log" entries are. Completed pomodoros are counted,
+along with the streak of consecutive days with at least one, and a desktop
+notification (notify-send or osascript, else the terminal bell) is shown when
+the time is up. --silent hides the countdown and the notification.
+Interrupting the timer with Ctrl+C logs the pomodoro as interrupted.`,
+		Example: examples(
+			ex(`exo pomo "Write the report"`, "Focus on a task for 25 minutes"),
+			ex(`exo pomo 50m "Deep work"`, "Run a longer pomodoro"),
+			ex(`exo pomo --silent 15m Email`, "Run without countdown or notification"),
+		),
+		Annotations: mutates(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			d, task := pomoArgs(args)
+			if d <= 0 {
+				return exoerrors.New(exoerrors.Usage, "invalid pomodoro duration %s", d)
+			}
+			label := task
+			if label == "" {
+				label = "(no task)"
+			}
+
+			start := time.Now()
+			if err := logPomo(cmd.Context(), deps, fmt.Sprintf("Pomodoro started (%s): %s", shortDuration(d), label), start); err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			errOut := cmd.ErrOrStderr()
+			countdown := !silent && tui.IsTerminal(os.Stderr)
+			err := pomo.Run(ctx, d, time.Second, func(remaining time.Duration) {
+				if countdown {
+					fmt.Fprintf(errOut, "\r%s  %s ", formatPomo(remaining), label)
+				}
+			})
+			if countdown {
+				fmt.Fprintln(errOut)
+			}
+			if err != nil {
+				if !errors.Is(err, ctx.Err()) {
+					return err
+				}
+				elapsed := shortDuration(time.Since(start))
+				if err := logPomo(cmd.Context(), deps, fmt.Sprintf("Pomodoro interrupted after %s: %s", elapsed, label), time.Now()); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Pomodoro interrupted after %s\n", elapsed)
+				return nil
+			}
+
+			end := time.Now()
+			stats, err := pomo.OpenStats(deps.Config.Dir.StatePath(pomo.File))
+			if err != nil {
+				return err
+			}
+			stats.Complete(end)
+			if err := stats.Save(); err != nil {
+				return err
+			}
+			summary := fmt.Sprintf("%d today, %d-day streak", stats.Today, stats.Streak)
+			if err := logPomo(cmd.Context(), deps, fmt.Sprintf("Pomodoro finished: %s (%s)", label, summary), end); err != nil {
+				return err
+			}
+
+			var notifier notify.Notifier = notify.Nop{}
+			if !silent {
+				notifier = notify.Desktop(notify.Bell{W: errOut})
+			}
+			if err := notifier.Notify("Pomodoro finished", label); err != nil {
+				deps.Logger.Errorf("Failed to show notification: %v", err)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Pomodoro finished: %s\n", summary)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&silent, "silent", false, "Hide the countdown and the notification")
+	return cmd
+}
+
+// pomoArgs returns the duration and the task given as arguments. The first
+// argument is the duration if it parses as one, or as a number of minutes.
+func pomoArgs(args []string) (time.Duration, string) {
+	if len(args) > 0 {
+		if d, err := time.ParseDuration(args[0]); err == nil {
+			return d, strings.Join(args[1:], " ")
+		}
+		if minutes, err := strconv.Atoi(args[0]); err == nil {
+			return time.Duration(minutes) * time.Minute, strings.Join(args[1:], " ")
+		}
+	}
+	return pomo.DefaultDuration, strings.Join(args, " ")
+}
+
+// formatPomo formats d as minutes and seconds, e.g. 24:59.
+func formatPomo(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// shortDuration formats d to the second without zero units, e.g. 25m or 1h30m.
+func shortDuration(d time.Duration) string {
+	s := d.Round(time.Second).String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+// logPomo appends entry to the daily note of at, created in ctx. The note is
+// read afresh, so that edits made while the timer ran are kept.
+func logPomo(ctx context.Context, deps Dependencies, entry string, at time.Time) error {
+	daily, err := periodic.NewDailyNote(ctx, periodic.DayOf(at), *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+	if err != nil {
+		return fmt.Errorf("failed to create daily note: %w", err)
+	}
+	if err := appendLog(deps, daily, "", entry, at); err != nil {
+		return fmt.Errorf("failed to append to daily note: %w", err)
+	}
+	return nil
+}
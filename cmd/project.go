@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/board"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// NewProjectCmd returns a new cobra.Command for the "project" command, which
+// works with the notes of projects.
+func NewProjectCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "project",
+		Short: "Work with the notes of projects",
+		Long: `Work with the notes of projects: the notes under dir.projects_dir, each
+directory there being a project, and the notes naming a project in the project
+field of their frontmatter.`,
+		Example: examples(
+			ex("exo project tasks", "Show the open tasks of every project by status"),
+		),
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newProjectTasksCmd(deps))
+	return cmd
+}
+
+// newProjectTasksCmd returns the "project tasks" command, which shows the
+// tasks of project notes as a board.
+func newProjectTasksCmd(deps Dependencies) *cobra.Command {
+	var (
+		by      string
+		project string
+		all     bool
+		format  string
+		open    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tasks",
+		Short: "Show the tasks of projects as a board",
+		Long: `Show the tasks of project notes as a board, in columns by status or by
+project. The status of a task is given by its checkbox: "- [ ]" todo, "- [/]"
+doing, "- [x]" done and "- [-]" cancelled. Done and cancelled tasks are only
+shown with --all.
+
+--open opens the note of the task whose text contains the given words in the
+editor, at the line of the task.`,
+		Example: examples(
+			ex("exo project tasks", "Show the open tasks by status"),
+			ex("exo project tasks --by project --all", "Show every task by project"),
+			ex("exo project tasks --project website --format json", "Output the board of a project as JSON"),
+			ex(`exo project tasks --open "deploy"`, "Edit the task about deploying"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if by != "status" && by != "project" {
+				return exoerrors.New(exoerrors.Usage, "invalid --by %q (want status or project)", by)
+			}
+			if format != "text" && format != "json" {
+				return exoerrors.New(exoerrors.Usage, "invalid --format %q (want text or json)", format)
+			}
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to scan notes: %w", err)
+			}
+			tasks, err := board.Collect(cmd.Context(), deps.Config.Dir.ProjectsDir, notes)
+			if err != nil {
+				return err
+			}
+			var shown []board.Task
+			for _, t := range tasks {
+				if (project == "" || strings.EqualFold(t.Project, project)) && (all || t.Status.Open()) {
+					shown = append(shown, t)
+				}
+			}
+
+			if open != "" {
+				cmd.SilenceUsage = true
+				found := board.Find(shown, open)
+				switch len(found) {
+				case 0:
+					return exoerrors.New(exoerrors.NotFound, "no task matches %q", open)
+				case 1:
+					t := found[0]
+					return deps.FS.OpenInEditor(t.Path, fs.OSEnv().EditorAtLine(deps.Config.General.Editor, t.Line))
+				}
+				for _, t := range found {
+					fmt.Fprintf(cmd.ErrOrStderr(), "  %s\n", t)
+				}
+				return exoerrors.New(exoerrors.Usage, "%d tasks match %q; be more specific", len(found), open)
+			}
+
+			columns := board.ByProject(shown)
+			if by == "status" {
+				statuses := board.Statuses
+				if !all {
+					statuses = []board.Status{board.Todo, board.Doing}
+				}
+				columns = board.ByStatus(shown, statuses)
+			}
+			out := cmd.OutOrStdout()
+			if format == "json" {
+				if columns == nil {
+					columns = []board.Column{}
+				}
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(columns)
+			}
+			if len(shown) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No tasks found")
+				return nil
+			}
+			for i, c := range columns {
+				if i > 0 {
+					fmt.Fprintln(out)
+				}
+				fmt.Fprintf(out, "## %s (%d)\n", c.Name, len(c.Tasks))
+				for _, t := range c.Tasks {
+					switch {
+					case by == "project":
+						fmt.Fprintf(out, "- %s\n", t)
+					case project == "":
+						// The column gives the status; name the project instead.
+						fmt.Fprintf(out, "- %s: %s (%s:%d)\n", t.Project, t.Text, t.Title, t.Line)
+					default:
+						fmt.Fprintf(out, "- %s (%s:%d)\n", t.Text, t.Title, t.Line)
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&by, "by", "status", "Group the tasks by status or project")
+	flags.StringVarP(&project, "project", "p", "", "Only the tasks of this project")
+	flags.BoolVarP(&all, "all", "a", false, "Include done and cancelled tasks")
+	flags.StringVarP(&format, "format", "f", "text", "Output format: text or json")
+	flags.StringVarP(&open, "open", "o", "", "Open the task whose text contains these words in the editor")
+	_ = cmd.RegisterFlagCompletionFunc("by", cobra.FixedCompletions([]string{"status", "project"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/prompts"
+)
+
+// NewPromptsCmd creates a new "prompts" command, whose subcommands manage the
+// journaling prompts of daily notes.
+func NewPromptsCmd(deps Dependencies) *cobra.Command {
+	promptsCmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "Manage the journaling prompts of daily notes",
+		Long: `Manage the journaling prompts kept in prompts.path, one per line, or in the
+files of the directory it names.
+
+With "prompts" in periodic.daily.providers, new daily notes get prompts.count
+of them, in turn or at random (prompts.order), as the Prompts template data:
+
+  periodic:
+    daily:
+      providers: [prompts]
+  prompts:
+    count: 2
+    order: random`,
+		Example: examples(
+			ex(`exo prompts add "What am I grateful for today?"`, "Add a prompt"),
+			ex("exo prompts list --today", "Show the prompts of today's daily note"),
+		),
+	}
+	promptsCmd.AddCommand(newPromptsAddCmd(deps))
+	promptsCmd.AddCommand(newPromptsListCmd(deps))
+	return promptsCmd
+}
+
+func newPromptsAddCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <prompt>",
+		Short: "Add a journaling prompt",
+		Example: examples(
+			ex(`exo prompts add "What did I learn today?"`, "Add a prompt"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := prompts.Add(deps.Config.Prompts.Path, strings.Join(args, " ")); err != nil {
+				return err
+			}
+			deps.Logger.Infof("Added prompt to %s", deps.Config.Prompts.Path)
+			return nil
+		},
+	}
+}
+
+func newPromptsListCmd(deps Dependencies) *cobra.Command {
+	var (
+		today    bool
+		dateFlag string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the journaling prompts",
+		Example: examples(
+			ex("exo prompts list", "List every prompt"),
+			ex("exo prompts list --date 2025-02-08", "Show the prompts picked for a day"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			list, err := prompts.Load(deps.Config.Prompts.Path)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Fprintf(cmd.ErrOrStderr(), "No prompts in %s\n", deps.Config.Prompts.Path)
+				return nil
+			}
+			if today || dateFlag != "" {
+				date, err := parseDayDate(dateFlag)
+				if err != nil {
+					return err
+				}
+				order, err := prompts.ParseOrder(deps.Config.Prompts.Order)
+				if err != nil {
+					return err
+				}
+				list = prompts.Pick(list, date, deps.Config.Prompts.Count, order)
+			}
+			for i, p := range list {
+				fmt.Fprintf(cmd.OutOrStdout(), "%3d  %s\n", i+1, p)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&today, "today", false, "Only list the prompts picked for today")
+	cmd.Flags().StringVar(&dateFlag, "date", "", "Only list the prompts picked for the day, as YYYY-MM-DD")
+	return cmd
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/web"
+)
+
+const (
+	// defaultHighlightsNote is the note quotes are appended to unless --to or --literature is given.
+	defaultHighlightsNote = "Highlights"
+	// defaultHighlightsHeading is the heading under which quotes are appended.
+	defaultHighlightsHeading = "## Highlights"
+)
+
+// quoteSource describes where a quote comes from.
+type quoteSource struct {
+	// ref identifies the source in a note's "sources" frontmatter: a wikilink or URL.
+	ref string
+	// title is a human-readable name for the source.
+	title string
+	// attribution is the Markdown credited below the quote.
+	attribution string
+}
+
+// NewQuoteCmd returns a new cobra.Command for the "quote" command, which captures
+// a quote together with its source.
+func NewQuoteCmd(deps Dependencies) *cobra.Command {
+	var (
+		to         string
+		literature bool
+		heading    string
+		offline    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "quote <source-note|url> [text...]",
+		Short: "Capture a quote with attribution to its source",
+		Example: examples(
+			ex(`exo quote "Deep Work" "Clarity about what matters provides clarity about what does not."`, "Quote a note in the Highlights note"),
+			ex("pbpaste | exo quote --literature https://example.com/essay", "Quote a web page in its literature note"),
+		),
+		Annotations: mutates(),
+		Long: `Append a quote, attributed to a note or web page, to a highlights note.
+
+By default quotes go to the "Highlights" note (created in data_home if missing);
+use --to to pick another note, or --literature to collect them in a literature note
+for the source in dir.literature_dir. Each source is recorded in the "sources"
+frontmatter list of the target note for later citation.
+When no text is given, it is read from standard input.`,
+		Args: cobra.MinimumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeNoteNames(deps)(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text := strings.Join(args[1:], " ")
+			if text == "" {
+				data, err := io.ReadAll(cmd.InOrStdin())
+				if err != nil {
+					return fmt.Errorf("failed to read standard input: %w", err)
+				}
+				text = strings.TrimSpace(string(data))
+			}
+			if text == "" {
+				return fmt.Errorf("nothing to quote")
+			}
+
+			source, err := resolveQuoteSource(cmd, deps, args[0], offline)
+			if err != nil {
+				return err
+			}
+
+			var path, title string
+			if literature {
+				title = source.title
+				path = filepath.Join(deps.Config.Dir.LiteratureDir, safeFileName(title)+scan.NoteExtension)
+			} else {
+				title = to
+				path, err = resolveNotePath(deps, to)
+				if err != nil {
+					path = filepath.Join(deps.Config.Dir.DataHome, safeFileName(to)+scan.NoteExtension)
+				}
+			}
+
+			content := "# " + title + "\n"
+			if strings.EqualFold(strings.TrimSpace(strings.TrimLeft(heading, "#")), title) {
+				// The heading already names the note, e.g. "## Highlights" in "Highlights".
+				content = ""
+			}
+			if deps.FS.FileExists(path) {
+				data, err := deps.FS.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read note: %w", err)
+				}
+				content = string(data)
+			}
+
+			highlight := note.FormatHighlight(text, source.attribution)
+			if body, err := md.Extract(content, heading); err == nil && body != "" {
+				// Keep consecutive quotes from merging into one blockquote.
+				highlight = "\n" + highlight
+			}
+			content = md.AppendUnder(content, heading, highlight)
+			if content, err = note.AddSource(content, source.ref); err != nil {
+				return err
+			}
+
+			if err := deps.FS.EnsureDirectoryExists(path); err != nil {
+				return err
+			}
+			if err := deps.FS.WriteFile(path, []byte(content)); err != nil {
+				return fmt.Errorf("failed to write note: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), path)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&to, "to", "t", defaultHighlightsNote, "Note to append the quote to")
+	cmd.Flags().BoolVarP(&literature, "literature", "l", false, "Append to the literature note of the source instead")
+	cmd.Flags().StringVar(&heading, "heading", defaultHighlightsHeading, "Heading to append the quote under")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Do not fetch the title of a URL source")
+	_ = cmd.RegisterFlagCompletionFunc("to", completeNoteNames(deps))
+	return cmd
+}
+
+// resolveQuoteSource identifies a quote source given as a URL or as a note.
+func resolveQuoteSource(cmd *cobra.Command, deps Dependencies, arg string, offline bool) (quoteSource, error) {
+	if web.ValidateURL(arg) == nil {
+		var title string
+		if !offline {
+			var err error
+			title, err = web.NewClient().FetchTitle(cmd.Context(), arg)
+			if err != nil {
+				deps.Logger.Infof("Could not fetch page title, using bare URL: %v", err)
+			}
+		}
+		name := title
+		if name == "" {
+			u, _ := url.Parse(arg)
+			name = strings.TrimPrefix(u.Host, "www.") + strings.TrimSuffix(u.Path, "/")
+		}
+		return quoteSource{
+			ref:         arg,
+			title:       name,
+			attribution: web.Citation(title, arg, "", time.Now()),
+		}, nil
+	}
+
+	path, err := resolveNotePath(deps, arg)
+	if err != nil {
+		return quoteSource{}, err
+	}
+	n, err := scan.ReadNote(path)
+	if err != nil {
+		return quoteSource{}, err
+	}
+	link := "[[" + strings.TrimSuffix(filepath.Base(path), scan.NoteExtension) + "]]"
+	return quoteSource{ref: link, title: n.Title, attribution: link}, nil
+}
+
+// safeFileName replaces characters that cannot appear in a file name.
+func safeFileName(name string) string {
+	return strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(strings.TrimSpace(name))
+}
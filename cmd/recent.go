@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/recent"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// NewRecentCmd returns a new cobra.Command for the "recent" command, which
+// lists the notes opened or edited most recently.
+func NewRecentCmd(deps Dependencies) *cobra.Command {
+	var (
+		frecency bool
+		limit    int
+		format   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "recent",
+		Short: "List recently used notes",
+		Long: `List the notes opened in the editor or written by exo, the most recently
+used first.
+
+With --frecency, notes are ranked by frecency instead: the number of times they
+were used, weighted by how recently they were last used, so that notes used
+often surface first.
+
+Uses are recorded in ` + recent.File + ` under state_dir, except in read-only mode.`,
+		Example: examples(
+			ex("exo recent", "List the 20 most recently used notes"),
+			ex("exo recent --frecency -n 5", "List the 5 most frequently and recently used notes"),
+			ex(`vim "$(exo recent -n 1 --format paths)"`, "Reopen the last note"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := recentEntries(deps, frecency)
+			if err != nil {
+				return err
+			}
+			if limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+			return writeRecent(cmd.OutOrStdout(), entries, format)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&frecency, "frecency", false, "Rank notes by frecency instead of last use")
+	flags.IntVarP(&limit, "limit", "n", 20, "List at most this many notes (0 for all)")
+	flags.StringVarP(&format, "format", "f", "table", "Output format: table, json or paths")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "json", "paths"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// recentNote is a recently used note.
+type recentNote struct {
+	recent.Entry
+	Title string `json:"title"`
+}
+
+// recentEntries returns the recorded notes that still exist, ranked by
+// frecency or else by last use.
+func recentEntries(deps Dependencies, frecency bool) ([]recentNote, error) {
+	store, err := recent.OpenStore(deps.Config.Dir.StatePath(recent.File))
+	if err != nil {
+		return nil, err
+	}
+	entries := store.Recent()
+	if frecency {
+		entries = store.Frecent(time.Now())
+	}
+	notes := make([]recentNote, 0, len(entries))
+	for _, e := range entries {
+		n, err := scan.ReadNote(e.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, recentNote{Entry: e, Title: n.Title})
+	}
+	return notes, nil
+}
+
+// writeRecent renders notes to w in the given format.
+func writeRecent(w io.Writer, notes []recentNote, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(notes)
+	case "paths":
+		for _, n := range notes {
+			fmt.Fprintln(w, n.Path)
+		}
+		return nil
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "LAST USED\tUSES\tTITLE")
+		for _, n := range notes {
+			fmt.Fprintf(tw, "%s\t%d\t%s\n", n.LastUsed.Local().Format("2006-01-02 15:04"), n.Uses(), n.Title)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q (expected table, json or paths)", format)
+	}
+}
+
+// frecencyOrder returns notes ordered by the frecency of their use, notes never
+// used last in their original order.
+func frecencyOrder(deps Dependencies, notes []scan.Note) []scan.Note {
+	store, err := recent.OpenStore(deps.Config.Dir.StatePath(recent.File))
+	if err != nil {
+		deps.Logger.Errorf("Recent notes unavailable: %v", err)
+		return notes
+	}
+	now := time.Now()
+	ordered := append([]scan.Note(nil), notes...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return store.Entries[ordered[i].Path].Frecency(now) > store.Entries[ordered[j].Path].Frecency(now)
+	})
+	return ordered
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/notify"
+	"github.com/a-kostevski/exo/pkg/remind"
+)
+
+// NewRemindCmd returns a new cobra.Command for the "remind" command, which
+// lists and notifies the reminders written in notes.
+func NewRemindCmd(deps Dependencies) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remind",
+		Short: "List and notify the reminders written in notes",
+		Long: `Reminders are written anywhere in a note as @remind annotations, with a date
+and optionally a time (` + remind.DefaultTime + ` by default):
+
+  - [ ] Renew the passport @remind(2024-03-01 09:00)
+
+Annotations in code and in checked tasks are ignored. "exo remind list" shows
+the upcoming reminders and "exo remind notify", meant to run every few minutes
+from cron or a systemd timer, shows a desktop notification for each reminder
+that came due. Notified reminders are kept in ` + remind.File + ` under state_dir.`,
+		Example: examples(
+			ex("exo remind list", "Show the reminders of the coming week"),
+			ex("exo remind notify", "Notify the reminders that came due"),
+			ex("*/5 * * * * exo remind notify --quiet", "Crontab entry checking every five minutes"),
+		),
+		Args: cobra.NoArgs,
+	}
+	cmd.AddCommand(newRemindListCmd(deps))
+	cmd.AddCommand(newRemindNotifyCmd(deps))
+	return cmd
+}
+
+// newRemindListCmd returns the "remind list" command, which shows the
+// upcoming reminders.
+func newRemindListCmd(deps Dependencies) *cobra.Command {
+	var (
+		days   int
+		all    bool
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "Show the upcoming reminders",
+		Example: examples(
+			ex("exo remind list --days 30", "Show the reminders of the coming month"),
+			ex("exo remind list --all", "Show every reminder, past ones included"),
+			ex("exo remind list --format json", "Output the reminders as JSON"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "text" && format != "json" {
+				return exoerrors.New(exoerrors.Usage, "invalid --format %q (want text or json)", format)
+			}
+			if days < 1 {
+				return exoerrors.New(exoerrors.Usage, "--days must be at least 1")
+			}
+			reminders, err := vaultReminders(cmd, deps)
+			if err != nil {
+				return err
+			}
+			if !all {
+				now := time.Now()
+				reminders = remind.Between(reminders, now, now.AddDate(0, 0, days))
+			}
+
+			out := cmd.OutOrStdout()
+			if format == "json" {
+				if reminders == nil {
+					reminders = []remind.Reminder{}
+				}
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(reminders)
+			}
+			if len(reminders) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No upcoming reminders")
+				return nil
+			}
+			for _, r := range reminders {
+				fmt.Fprintf(out, "%s  (%s:%d)\n", r, r.Path, r.Line)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 7, "Number of days ahead to show reminders for")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Show every reminder, past ones included")
+	cmd.Flags().StringVarP(&format, "format", "f", "text", "Output format: text or json")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// newRemindNotifyCmd returns the "remind notify" command, which notifies the
+// reminders that came due since they were last checked.
+func newRemindNotifyCmd(deps Dependencies) *cobra.Command {
+	var (
+		within time.Duration
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "notify",
+		Short: "Notify the reminders that came due",
+		Long: `Show a desktop notification (notify-send, or osascript on macOS) for each
+reminder that came due and was not notified yet, and print it. Without a
+notification program, the terminal bell rings instead.
+
+Reminders due more than --within ago are too late and left out, so that a
+machine that was off does not catch up on old reminders at once.`,
+		Example: examples(
+			ex("exo remind notify", "Notify the reminders that came due"),
+			ex("exo remind notify --within 24h", "Also notify the reminders of the last day"),
+			ex("exo remind notify --dry-run", "Print the due reminders without notifying them"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if within <= 0 {
+				return exoerrors.New(exoerrors.Usage, "--within must be positive")
+			}
+			reminders, err := vaultReminders(cmd, deps)
+			if err != nil {
+				return err
+			}
+			store, err := remind.OpenStore(deps.Config.Dir.StatePath(remind.File))
+			if err != nil {
+				return err
+			}
+			now := time.Now()
+			due := store.Due(reminders, now, within)
+			if dryRun {
+				for _, r := range due {
+					fmt.Fprintln(cmd.OutOrStdout(), r)
+				}
+				return nil
+			}
+			notifier := notify.Desktop(notify.Bell{W: cmd.ErrOrStderr()})
+			for _, r := range due {
+				message := r.Text
+				if message == "" {
+					message = r.At.Format("15:04")
+				}
+				if err := notifier.Notify(r.Title, message); err != nil {
+					deps.Logger.Errorf("Failed to show notification: %v", err)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), r)
+				store.MarkNotified(r, now)
+			}
+			if len(due) == 0 {
+				return nil
+			}
+			return store.Save()
+		},
+	}
+
+	cmd.Flags().DurationVar(&within, "within", time.Hour, "Leave out the reminders due longer ago than this")
+	cmd.Flags().BoolVarP(&dryRun, "dry-run", "n", false, "Print the due reminders without notifying them")
+	return cmd
+}
+
+// vaultReminders returns the reminders of the notes of the vault, ordered by
+// time.
+func vaultReminders(cmd *cobra.Command, deps Dependencies) ([]remind.Reminder, error) {
+	notes, err := vaultNotes(deps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan notes: %w", err)
+	}
+	return remind.Collect(cmd.Context(), deps.Config.Dir.DataHome, notes)
+}
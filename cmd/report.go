@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/stats"
+)
+
+// NewReportCmd creates a new "report" command with the "trends" subcommand.
+func NewReportCmd(deps Dependencies) *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report",
+		Short: "Report on notes over time",
+		Example: examples(
+			ex("exo report trends", "Chart words written per week"),
+		),
+	}
+	reportCmd.AddCommand(NewReportTrendsCmd(deps))
+	return reportCmd
+}
+
+// NewReportTrendsCmd creates the "report trends" command, which charts a metric of
+// the daily notes per period.
+func NewReportTrendsCmd(deps Dependencies) *cobra.Command {
+	var (
+		metric  string
+		period  string
+		since   string
+		width   int
+		jsonOut bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Chart a metric of daily notes over time",
+		Example: examples(
+			ex("exo report trends --metric tasks_completed --period weekly", "Chart completed tasks per week"),
+			ex("exo report trends --metric words --period daily --since 30d", "Chart words written per day over the last 30 days"),
+		),
+		Long: `Chart a metric of the daily notes, summed per day, week or month.
+
+Metrics:
+  words            words written
+  tasks            task list items ("- [ ]" and "- [x]")
+  tasks_completed  checked task list items ("- [x]")`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := stats.TrendOptions{
+				Metric:   stats.Metric(metric),
+				Period:   stats.Period(period),
+				DailyDir: filepath.Join(deps.Config.Dir.DataHome, "day"),
+			}
+			if since != "" {
+				t, err := parseSince(since, time.Now())
+				if err != nil {
+					return err
+				}
+				opts.Since = t
+			}
+
+			notes, err := scan.ScanContext(cmd.Context(), scan.WalkOptions{Ignore: vaultIgnore(deps)}, opts.DailyDir)
+			if err != nil {
+				return fmt.Errorf("failed to scan daily notes: %w", err)
+			}
+			points, err := stats.Trend(notes, opts)
+			if err != nil {
+				return err
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(points)
+			}
+			if len(points) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No daily notes found")
+				return nil
+			}
+			fmt.Fprint(cmd.OutOrStdout(), stats.Chart(points, width))
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&metric, "metric", "m", string(stats.MetricWords), "Metric to chart: words, tasks or tasks_completed")
+	flags.StringVarP(&period, "period", "p", string(stats.PeriodWeekly), "Period to sum over: daily, weekly or monthly")
+	flags.StringVar(&since, "since", "", "Only include daily notes since a date (YYYY-MM-DD) or age (e.g. 90d)")
+	flags.IntVar(&width, "width", 40, "Width of the longest bar")
+	flags.BoolVar(&jsonOut, "json", false, "Output the series as JSON")
+	_ = cmd.RegisterFlagCompletionFunc("metric", cobra.FixedCompletions([]string{"words", "tasks", "tasks_completed"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("period", cobra.FixedCompletions([]string{"daily", "weekly", "monthly"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// resolveNotePath resolves a note argument to a file path. The argument may be a path
+// to an existing file, or a note name that is looked up among the notes under
+// data_home: a path relative to data_home or a file name, with or without the .md
+// extension, a title, an ID or an alias declared in the frontmatter.
+func resolveNotePath(deps Dependencies, arg string) (string, error) {
+	if deps.FS.FileExists(arg) {
+		return filepath.Abs(arg)
+	}
+	notes, err := vaultNotes(deps)
+	if err != nil {
+		return "", fmt.Errorf("failed to search notes: %w", err)
+	}
+	matches := scan.NewNames(deps.Config.Dir.DataHome, notes).Resolve(arg)
+	switch len(matches) {
+	case 0:
+		return "", exoerrors.New(exoerrors.NotFound, "note not found: %s", arg)
+	case 1:
+		return matches[0].Path, nil
+	default:
+		paths := make([]string, len(matches))
+		for i, n := range matches {
+			paths[i] = n.Path
+		}
+		return "", exoerrors.New(exoerrors.Conflict, "note %q is ambiguous: %s", arg, strings.Join(paths, ", "))
+	}
+}
+
+// vaultNotes returns every note under data_home, excluding the template
+// directory, from the note index. The files are scanned instead when the index
+// cannot be used.
+func vaultNotes(deps Dependencies) ([]scan.Note, error) {
+	notes, err := indexedNotes(deps)
+	if err == nil {
+		return notes, nil
+	}
+	deps.Logger.Errorf("Note index unavailable, scanning files: %v", err)
+	return scan.ScanContext(context.Background(), scan.WalkOptions{Exclude: []string{deps.Config.Dir.TemplateDir}, Ignore: vaultIgnore(deps)}, deps.Config.Dir.DataHome)
+}
+
+// vaultNotesIn returns the notes of vaultNotes under one of dirs.
+func vaultNotesIn(deps Dependencies, dirs ...string) ([]scan.Note, error) {
+	notes, err := vaultNotes(deps)
+	if err != nil {
+		return nil, err
+	}
+	var in []scan.Note
+	for _, n := range notes {
+		for _, dir := range dirs {
+			if strings.HasPrefix(n.Path, dir+string(filepath.Separator)) {
+				in = append(in, n)
+				break
+			}
+		}
+	}
+	return in, nil
+}
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/review"
+)
+
+// reviewStateFile is the file, in state_dir, review schedules are kept in.
+const reviewStateFile = "review.json"
+
+// errQuitReview stops a review session early.
+var errQuitReview = errors.New("review stopped")
+
+// NewReviewCmd returns a new cobra.Command for the "review" command, which
+// schedules notes and flashcards for spaced repetition.
+func NewReviewCmd(deps Dependencies) *cobra.Command {
+	var (
+		list  bool
+		limit int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review due notes and flashcards with spaced repetition",
+		Long: `Present the notes tagged #review and the flashcards due today one by one and
+grade how well you remembered each: again, hard or easy. Items are rescheduled
+SM-2 style, at intervals growing with each successful review.
+
+Flashcards can be written in any note:
+
+  Q: What does SM-2 stand for?
+  A: SuperMemo 2.
+
+Review schedules are kept in ` + reviewStateFile + ` under state_dir.`,
+		Example: examples(
+			ex("exo review", "Review everything due today"),
+			ex("exo review --limit 10", "Review at most 10 items"),
+			ex("exo review --list", "List the due items without reviewing them"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to scan notes: %w", err)
+			}
+			items, err := review.Collect(deps.Config.Dir.DataHome, notes)
+			if err != nil {
+				return fmt.Errorf("failed to collect review items: %w", err)
+			}
+			store, err := review.OpenStore(deps.Config.Dir.StatePath(reviewStateFile))
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			due := store.Due(items, time.Now())
+			if len(due) == 0 {
+				fmt.Fprintln(out, "Nothing to review.")
+				return nil
+			}
+			if limit > 0 && len(due) > limit {
+				due = due[:limit]
+			}
+			if list {
+				for _, it := range due {
+					fmt.Fprintln(out, reviewLabel(it))
+				}
+				return nil
+			}
+
+			in := bufio.NewReader(cmd.InOrStdin())
+			reviewed := 0
+			for i, it := range due {
+				fmt.Fprintf(out, "\n[%d/%d] %s\n\n", i+1, len(due), reviewLabel(it))
+				grade, ok, err := reviewItem(out, in, it)
+				if errors.Is(err, errQuitReview) || errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				if !ok {
+					continue
+				}
+				state := store.Review(it.ID, grade, time.Now())
+				if err := store.Save(); err != nil {
+					return err
+				}
+				reviewed++
+				fmt.Fprintf(out, "Next review in %d day(s).\n", state.Interval)
+			}
+			fmt.Fprintf(out, "\nReviewed %d of %d due item(s).\n", reviewed, len(due))
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&list, "list", false, "List the due items without reviewing them")
+	cmd.Flags().IntVarP(&limit, "limit", "n", 0, "Review at most this many items (0 for all)")
+	return cmd
+}
+
+// reviewLabel describes a review item by its note and, for flashcards, the
+// first line of its question.
+func reviewLabel(it review.Item) string {
+	if it.IsCard() {
+		question, _, _ := strings.Cut(it.Question, "\n")
+		return fmt.Sprintf("%s: %s", it.Title, question)
+	}
+	return it.Title
+}
+
+// reviewItem presents it and reads its grade. It reports false when the item is
+// skipped and errQuitReview when the user quits.
+func reviewItem(out io.Writer, in *bufio.Reader, it review.Item) (review.Grade, bool, error) {
+	if it.IsCard() {
+		fmt.Fprintf(out, "Q: %s\n\nPress Enter to show the answer.", it.Question)
+		if _, err := in.ReadString('\n'); err != nil {
+			return 0, false, err
+		}
+		fmt.Fprintf(out, "A: %s\n\n", it.Answer)
+	} else {
+		content, err := os.ReadFile(it.Path)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to read note: %w", err)
+		}
+		fmt.Fprintf(out, "%s\n\n", noteBody(string(content)))
+	}
+	for {
+		fmt.Fprint(out, "[a]gain, [h]ard, [e]asy, [s]kip or [q]uit? ")
+		line, err := in.ReadString('\n')
+		if err != nil && line == "" {
+			return 0, false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "s", "skip":
+			return 0, false, nil
+		case "q", "quit":
+			return 0, false, errQuitReview
+		}
+		if grade, err := review.ParseGrade(line); err == nil {
+			return grade, true, nil
+		}
+	}
+}
@@ -1,77 +1,205 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/note"
 )
 
+// Version is the exo version, overridable at build time with
+// -ldflags "-X github.com/a-kostevski/exo/cmd.Version=...".
+var Version = "0.1.0"
+
 // NewRootCmd creates a new root command using the injected dependencies.
-// It provides GNU-friendly usage and help text.
 func NewRootCmd(deps Dependencies) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "exo [OPTIONS] <command> [arguments]",
+		Use:   "exo",
 		Short: "Exo is a note-taking system",
-		Long: `Exo is a note-taking system that helps you organize your thoughts, ideas, and knowledge.
-
-Usage:
-  exo [OPTIONS] <command> [arguments]
-
-Examples:
-  exo init               Initialize exo configuration and directories.
-  exo day                Open today's daily note.
-  exo zet "My Note"      Create a new Zettel note with the title "My Note".
-
-Global Options:
-  -c, --config FILE      Specify configuration file (default: $HOME/.config/exo/config.yaml)
-  -d, --debug            Enable debug logging (sets log level to "debug")
-  -v, --verbose          Enable verbose output (sets log level to "info")
-  -q, --quiet            Suppress all output except errors (sets log level to "error")
-      --version          Print version information
-  -h, --help             Show this help message and exit.
-`,
+		Long:  "Exo is a note-taking system that helps you organize your thoughts, ideas, and knowledge.",
+		Example: examples(
+			ex("exo init", "Initialize exo configuration and directories"),
+			ex("exo day", "Open today's daily note"),
+			ex(`exo zet "My Note"`, `Create a new Zettel note with the title "My Note"`),
+			ex(`exo log "Did a thing"`, "Append a timestamped entry to today's daily note"),
+			ex("exo help topics", "Browse every command and its examples"),
+		),
+		// Errors are printed by ReportError in the format selected with --output.
+		SilenceErrors: true,
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 			// Handle version flag.
 			ver, err := cmd.Flags().GetBool("version")
 			if err == nil && ver {
-				fmt.Println("exo version 0.1.0")
+				fmt.Printf("exo version %s\n", Version)
 				os.Exit(0)
 			}
+			switch output, _ := cmd.Flags().GetString("output"); output {
+			case "text":
+			case "json":
+				// Keep the error report parseable.
+				cmd.SilenceUsage = true
+			default:
+				return exoerrors.New(exoerrors.Usage, "invalid --output %q (want text or json)", output)
+			}
+			// Completion output is parsed by the shell; keep stdout clean.
+			if isCompletionCmd(cmd) {
+				return nil
+			}
+			if deps.container != nil {
+				if err := deps.container.check(cmd); err != nil {
+					return err
+				}
+				if err := deps.container.checkLateFlags(cmd); err != nil {
+					return err
+				}
+			}
+			if policy, _ := cmd.Flags().GetString("on-conflict"); policy != "" && !note.ConflictPolicy(policy).Valid() {
+				return exoerrors.New(exoerrors.Usage, "invalid --on-conflict %q (want fail, merge, copy or overwrite)", policy)
+			}
+			if deps.ReadOnly && cmd.Annotations[annotationMutates] == "true" && cmd.Annotations[annotationOnRequest] != "true" {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%q modifies notes: %w (drop --read-only or set general.read_only to false)", cmd.CommandPath(), fs.ErrReadOnly)
+			}
+			if cmd.Annotations[annotationMutates] == "true" {
+				if err := firstRun(deps, cmd); err != nil {
+					return err
+				}
+			}
 			// At this point, configuration and logger are already constructed.
-			deps.Logger.Infof("Configuration loaded successfully: %+v", deps.Config)
+			// Only dump it on request so that command output stays machine-readable.
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			debug, _ := cmd.Flags().GetBool("debug")
+			if verbose || debug {
+				deps.Logger.Infof("Configuration loaded successfully: %+v", deps.Config)
+			}
 			return nil
 		},
+		// Commit note changes when sync.auto_commit is enabled.
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			autoCommit(deps, cmd)
+		},
+		// Offer configured aliases alongside the built-in subcommands.
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return aliasCompletions(cmd.Root(), deps, toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
 	}
 
+	addGlobalFlags(cmd.PersistentFlags())
+
+	// Help and usage are rendered by cobra from each command's Long, Example and flags.
+	cmd.SetHelpCommand(newHelpCmd())
+	return cmd
+}
+
+// addGlobalFlags defines the persistent flags of the root command in flags.
+func addGlobalFlags(flags *pflag.FlagSet) {
 	// Define GNU-friendly persistent flags.
-	flags := cmd.PersistentFlags()
-	flags.StringP("config", "c", "", "Configuration file (default: $HOME/.config/exo/config.yaml)")
+	flags.StringP("config", "c", "", "Configuration file (default: $HOME/.config/exo/config.yaml, %APPDATA%\\exo\\config.yaml on Windows)")
 	flags.BoolP("debug", "d", false, "Enable debug logging (sets log level to 'debug')")
 	flags.BoolP("verbose", "v", false, "Enable verbose output (sets log level to 'info')")
 	flags.BoolP("quiet", "q", false, "Suppress all output except errors (sets log level to 'error')")
 	flags.Bool("version", false, "Print version information")
+	// Consumed by main before the dependencies are built; declared so cobra accepts it.
+	flags.Bool("debug-startup", false, "Print the resolved configuration before running the command")
+	flags.String("output", "text", "Format of error reports: text, or json with an error code")
+	flags.Bool("read-only", false, "Refuse to modify the vault (also general.read_only)")
+	flags.Bool("yes", false, "Set exo up without asking when a command first needs the vault")
+	flags.Bool("force", false, "Change and delete notes locked with locked: true in their frontmatter, and overwrite notes changed on disk")
+	flags.String("on-conflict", "", "What saving a note changed on disk does: fail, merge, copy or overwrite (also general.on_conflict)")
 	flags.BoolP("help", "h", false, "Show help message and exit")
+}
 
-	// Set a GNU-friendly help template.
-	cmd.SetHelpTemplate(`Usage: {{.CommandPath}} [OPTIONS] <command> [arguments]
-
-{{.Long}}
-
-Global Options:
-{{.PersistentFlags.FlagUsages | trimTrailingWhitespaces}}
-
-Use "{{.CommandPath}} <command> --help" for more information about a command.
-`)
-	return cmd
+// ParseGlobalFlags parses the global flags at the start of args, up to the
+// first argument that is not one of them, such as the command, as cobra will
+// parse them. main reads the flags the dependencies are built with from it;
+// flags after the command belong to the command.
+func ParseGlobalFlags(args []string) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("exo", pflag.ContinueOnError)
+	addGlobalFlags(flags)
+	// The leading arguments are known flags with their values.
+	_ = flags.Parse(args[:leadingFlags(flags, args)])
+	return flags
 }
 
 // Execute runs the root command.
 func Execute(deps Dependencies) {
 	rootCmd := NewRootCmd(deps)
 	// Subcommands will be added in main.
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	os.Exit(Run(rootCmd))
+}
+
+// Run executes root with its subcommands, reports any error to stderr and
+// returns the exit status: 0 on success, else that of the error's category.
+func Run(root *cobra.Command) int {
+	markUsageErrors(root)
+	err := root.Execute()
+	if err == nil {
+		return exoerrors.ExitOK
+	}
+	// Cobra rejects unknown subcommands with a plain error before any command runs.
+	if strings.HasPrefix(err.Error(), "unknown command ") {
+		err = exoerrors.Wrap(exoerrors.Usage, err)
+	}
+	return ReportError(root, os.Stderr, err)
+}
+
+// markUsageErrors categorizes the flag and argument errors of cmd and its
+// subcommands as usage errors.
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return exoerrors.Wrap(exoerrors.Usage, err)
+	})
+	if args := cmd.Args; args != nil {
+		cmd.Args = func(cmd *cobra.Command, a []string) error {
+			return exoerrors.Wrap(exoerrors.Usage, args(cmd, a))
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
+
+// errorReport is an error as reported with --output json.
+type errorReport struct {
+	Code     exoerrors.Code `json:"code"`
+	Message  string         `json:"message"`
+	ExitCode int            `json:"exit_code"`
+}
+
+// ReportError prints err to w in the format selected with --output on root,
+// and returns the exit status for it.
+func ReportError(root *cobra.Command, w io.Writer, err error) int {
+	exitCode := exoerrors.ExitCode(err)
+	if output, _ := root.PersistentFlags().GetString("output"); output == "json" {
+		report := struct {
+			Error errorReport `json:"error"`
+		}{errorReport{Code: exoerrors.CodeOf(err), Message: err.Error(), ExitCode: exitCode}}
+		if encErr := json.NewEncoder(w).Encode(report); encErr == nil {
+			return exitCode
+		}
+	}
+	fmt.Fprintf(w, "Error: %v\n", err)
+	var se *StartupError
+	if errors.As(err, &se) {
+		if se.Target != "" {
+			fmt.Fprintf(w, "  while using: %s\n", se.Target)
+		}
+		if se.Hint != "" {
+			fmt.Fprintf(w, "  suggestion:  %s\n", se.Hint)
+		}
+		fmt.Fprintln(w, "  Run with --debug-startup for details about the resolved configuration.")
 	}
+	return exitCode
 }
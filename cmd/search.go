@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// queryHelp describes the query language for the help of commands taking a
+// query.
+const queryHelp = `Queries combine terms with AND (implied between terms), OR and NOT (or a
+leading "-"), grouped with parentheses. A term is a word or a "quoted phrase" to
+search for, or a field and a value:
+
+  tag:go  #go        notes with a tag
+  type:zettel        notes of a type (frontmatter type or top-level directory)
+  title:chan         notes whose title or an alias contains the text
+  created:2024-01    notes created on a day, in a month or a year
+  modified:>=30d     notes modified since an age (30d, 6m, 2y) or a date`
+
+// NewSearchCmd returns a new cobra.Command for the "search" command, which
+// lists the notes matching a query.
+func NewSearchCmd(deps Dependencies) *cobra.Command {
+	var (
+		sortBy        string
+		limit         int
+		format        string
+		snippetLength int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>...",
+		Short: "Find notes with a query",
+		Long: `Find the notes matching a query, read from the note index.
+
+` + queryHelp,
+		Example: examples(
+			ex(`exo search 'tag:project AND modified:>2024-01-01 AND "kubernetes"'`, "Find recent project notes about Kubernetes"),
+			ex("exo search '#go -type:journal' --sort title", "List the notes tagged #go outside the journal"),
+			ex("exo search created:today --format paths", "Print the paths of the notes created today"),
+		),
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := parseQuery(strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			notes, err := selectNotes(deps, q)
+			if err != nil {
+				return err
+			}
+			if err := scan.Sort(notes, scan.SortField(sortBy)); err != nil {
+				return exoerrors.Wrap(exoerrors.Usage, err)
+			}
+			if limit > 0 && len(notes) > limit {
+				notes = notes[:limit]
+			}
+			if !cmd.Flags().Changed("snippet-length") {
+				snippetLength = deps.Config.Search.SnippetLength
+			}
+			return writeNotes(cmd.OutOrStdout(), notes, noteTypeDirs(deps), format, snippetLength)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&sortBy, "sort", "s", string(scan.SortByModified), "Sort by created, modified or title")
+	flags.IntVarP(&limit, "limit", "n", 0, "Show at most this many notes")
+	flags.StringVarP(&format, "format", "f", "table", "Output format: table, json or paths")
+	flags.IntVar(&snippetLength, "snippet-length", 0, "Maximum length of note previews (default: search.snippet_length)")
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"created", "modified", "title"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "json", "paths"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// parseQuery parses input combined with the non-empty terms, all of which
+// notes must match. Errors are usage errors.
+func parseQuery(input string, terms ...string) (query.Query, error) {
+	var parts []string
+	for _, term := range terms {
+		if term != "" {
+			parts = append(parts, term)
+		}
+	}
+	if strings.TrimSpace(input) != "" {
+		if len(parts) > 0 {
+			input = "(" + input + ")"
+		}
+		parts = append([]string{input}, parts...)
+	}
+	q, err := query.Parse(strings.Join(parts, " "), time.Now())
+	if err != nil {
+		return query.Query{}, exoerrors.New(exoerrors.Usage, "invalid query: %v", err)
+	}
+	return q, nil
+}
+
+// selectNotes returns the notes matching q from the index, after bringing it
+// up to date.
+func selectNotes(deps Dependencies, q query.Query) ([]scan.Note, error) {
+	ix, err := openIndex(deps)
+	if err != nil {
+		return nil, err
+	}
+	defer ix.Close()
+	if _, err := ix.Sync(deps.Config.Dir.TemplateDir); err != nil {
+		return nil, err
+	}
+	notes, err := ix.Select(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notes: %w", err)
+	}
+	return notes, nil
+}
+
+// fieldTerm returns the query term comparing field with value, e.g.
+// "created:>=30d", or an empty string when value is empty.
+func fieldTerm(field query.Field, op, value string) string {
+	if value == "" {
+		return ""
+	}
+	return string(field) + ":" + op + query.Quote(value)
+}
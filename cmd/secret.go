@@ -0,0 +1,304 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/secret"
+)
+
+// NewSecretCmd creates a new "secret" command, whose subcommands encrypt and
+// decrypt the secrets kept in the frontmatter of notes.
+func NewSecretCmd(deps Dependencies) *cobra.Command {
+	secretCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Keep encrypted secrets in the frontmatter of notes",
+		Long: `Keep API keys, account numbers or personal data in the secret field of the
+frontmatter of otherwise plain notes, encrypted:
+
+  secret:
+    api_key: exo-secret:v1:3q2+7w...
+
+Values are encrypted with AES-256-GCM, with a key derived from the passphrase in
+EXO_SECRET_PASSPHRASE, which is asked for when unset. Add secrets with "exo
+secret set", or write them in plain text and encrypt them with "exo secret
+seal". Decrypt them with "exo secret show".`,
+		Example: examples(
+			ex(`echo "$KEY" | exo secret set "OpenAI" api_key`, "Store an API key encrypted"),
+			ex(`exo secret show "OpenAI" api_key`, "Print the API key"),
+			ex("exo secret seal", "Encrypt the secrets written in plain text"),
+		),
+	}
+	secretCmd.AddCommand(newSecretShowCmd(deps))
+	secretCmd.AddCommand(newSecretSetCmd(deps))
+	secretCmd.AddCommand(newSecretSealCmd(deps))
+	secretCmd.AddCommand(newSecretListCmd(deps))
+	return secretCmd
+}
+
+func newSecretShowCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "show <note> <key>",
+		Short: "Print a secret of a note, decrypted",
+		Example: examples(
+			ex(`exo secret show "OpenAI" api_key`, "Print the API key"),
+			ex(`exo secret show Bank iban | pbcopy`, "Copy a secret to the clipboard"),
+		),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeSecretArgs(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, content, err := readSecretNote(deps, args[0])
+			if err != nil {
+				return err
+			}
+			k, err := secretKeyring(cmd)
+			if err != nil {
+				return err
+			}
+			value, err := secret.Get(content, args[1], k)
+			if err != nil {
+				return secretError(path, err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), value)
+			return nil
+		},
+	}
+}
+
+func newSecretSetCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "set <note> <key> [value]",
+		Short: "Store a secret in a note, encrypted",
+		Long: `Store the secret key in the frontmatter of a note, encrypted, replacing the
+value it had. The value is read from standard input, or asked for, when not
+given, which keeps it out of the shell history.`,
+		Example: examples(
+			ex(`exo secret set Bank iban`, "Type a secret in"),
+			ex(`pass show openai | exo secret set "OpenAI" api_key`, "Store a secret read from another program"),
+		),
+		Annotations:       mutates(),
+		Args:              cobra.RangeArgs(2, 3),
+		ValidArgsFunction: completeSecretArgs(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, content, err := readSecretNote(deps, args[0])
+			if err != nil {
+				return err
+			}
+			k, err := secretKeyring(cmd)
+			if err != nil {
+				return err
+			}
+			var value string
+			if len(args) == 3 {
+				value = args[2]
+			} else if value, err = readSecretValue(cmd); err != nil {
+				return err
+			}
+			if content, err = secret.Set(content, args[1], value, k); err != nil {
+				return secretError(path, err)
+			}
+			if err := deps.FS.WriteFile(path, []byte(content)); err != nil {
+				return fmt.Errorf("failed to write note: %w", err)
+			}
+			deps.Logger.Infof("Stored secret %s in %s", args[1], path)
+			return nil
+		},
+	}
+}
+
+func newSecretSealCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "seal [note...]",
+		Short: "Encrypt the secrets written in plain text",
+		Long: `Encrypt the values of the secret field written in plain text in the notes
+given, or in every note of the vault.`,
+		Example: examples(
+			ex("exo secret seal", "Encrypt the plain secrets of every note"),
+			ex(`exo secret seal "OpenAI"`, "Encrypt the plain secrets of a note"),
+		),
+		Annotations:       mutates(),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var paths []string
+			for _, arg := range args {
+				path, err := resolveNotePath(deps, arg)
+				if err != nil {
+					return err
+				}
+				paths = append(paths, path)
+			}
+			if len(args) == 0 {
+				notes, err := vaultNotes(deps)
+				if err != nil {
+					return fmt.Errorf("failed to search notes: %w", err)
+				}
+				for _, n := range notes {
+					if _, ok := n.Meta[secret.Field]; ok {
+						paths = append(paths, n.Path)
+					}
+				}
+			}
+			if len(paths) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No notes with secrets")
+				return nil
+			}
+
+			k, err := secretKeyring(cmd)
+			if err != nil {
+				return err
+			}
+			sealed, notes := 0, 0
+			for _, path := range paths {
+				data, err := deps.FS.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("failed to read note: %w", err)
+				}
+				content, count, err := secret.Seal(string(data), k)
+				if err != nil {
+					return secretError(path, err)
+				}
+				if count == 0 {
+					continue
+				}
+				if err := deps.FS.WriteFile(path, []byte(content)); err != nil {
+					return fmt.Errorf("failed to write note: %w", err)
+				}
+				sealed += count
+				notes++
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Encrypted %d secret(s) in %d note(s)\n", sealed, notes)
+			return nil
+		},
+	}
+}
+
+func newSecretListCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:               "list <note>",
+		Short:             "List the secrets of a note",
+		Example:           examples(ex(`exo secret list "OpenAI"`, "List the keys of the secrets of a note")),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, content, err := readSecretNote(deps, args[0])
+			if err != nil {
+				return err
+			}
+			keys, err := secret.Keys(content)
+			if err != nil {
+				return secretError(path, err)
+			}
+			for _, key := range keys {
+				fmt.Fprintln(cmd.OutOrStdout(), key)
+			}
+			return nil
+		},
+	}
+}
+
+// readSecretNote returns the path and content of the note named arg.
+func readSecretNote(deps Dependencies, arg string) (string, string, error) {
+	path, err := resolveNotePath(deps, arg)
+	if err != nil {
+		return "", "", err
+	}
+	data, err := deps.FS.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read note: %w", err)
+	}
+	return path, string(data), nil
+}
+
+// secretKeyring returns the keyring of the passphrase in EXO_SECRET_PASSPHRASE
+// or, when unset, typed in on the terminal.
+func secretKeyring(cmd *cobra.Command) (*secret.Keyring, error) {
+	if passphrase := os.Getenv(secret.EnvPassphrase); passphrase != "" {
+		return secret.NewKeyring(passphrase), nil
+	}
+	passphrase, err := readHidden(cmd, "Passphrase: ")
+	if errors.Is(err, errNoTerminal) {
+		return nil, exoerrors.New(exoerrors.Config, "set %s to the passphrase of secrets", secret.EnvPassphrase)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if passphrase == "" {
+		return nil, exoerrors.New(exoerrors.Usage, "the passphrase cannot be empty")
+	}
+	return secret.NewKeyring(passphrase), nil
+}
+
+// readSecretValue reads a secret from standard input, or typed in on the
+// terminal, without the final newline.
+func readSecretValue(cmd *cobra.Command) (string, error) {
+	value, err := readHidden(cmd, "Value: ")
+	if errors.Is(err, errNoTerminal) {
+		data, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret: %w", err)
+		}
+		value = strings.TrimRight(string(data), "\r\n")
+	} else if err != nil {
+		return "", err
+	}
+	if value == "" {
+		return "", exoerrors.New(exoerrors.Usage, "the secret cannot be empty")
+	}
+	return value, nil
+}
+
+// errNoTerminal is returned by readHidden when standard input is not a
+// terminal.
+var errNoTerminal = errors.New("standard input is not a terminal")
+
+// readHidden asks for a line on the terminal with prompt, without echoing it.
+func readHidden(cmd *cobra.Command, prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if cmd.InOrStdin() != os.Stdin || !term.IsTerminal(fd) {
+		return "", errNoTerminal
+	}
+	fmt.Fprint(cmd.ErrOrStderr(), prompt)
+	data, err := term.ReadPassword(fd)
+	fmt.Fprintln(cmd.ErrOrStderr())
+	if err != nil {
+		return "", fmt.Errorf("failed to read from terminal: %w", err)
+	}
+	return string(data), nil
+}
+
+// secretError attaches the note at path to err and classifies it.
+func secretError(path string, err error) error {
+	switch {
+	case errors.Is(err, secret.ErrNotFound):
+		return exoerrors.New(exoerrors.NotFound, "%s: %v", path, err)
+	case errors.Is(err, secret.ErrDecrypt):
+		return exoerrors.New(exoerrors.Config, "%s: %v", path, err)
+	default:
+		return fmt.Errorf("%s: %w", path, err)
+	}
+}
+
+// completeSecretArgs completes the note, then the key of its secret.
+func completeSecretArgs(deps Dependencies) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) == 0 {
+			return completeNoteNames(deps)(cmd, args, toComplete)
+		}
+		if len(args) > 1 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		_, content, err := readSecretNote(deps, args[0])
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		keys, _ := secret.Keys(content)
+		return filterPrefix(keys, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
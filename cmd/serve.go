@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/server"
+	"github.com/a-kostevski/exo/pkg/vault"
+)
+
+const (
+	// defaultServeAddr is the address "exo serve" listens on by default.
+	defaultServeAddr = "127.0.0.1:7474"
+	// serveTokenEnv names the environment variable the API token is read from.
+	serveTokenEnv = "EXO_SERVE_TOKEN"
+)
+
+// NewServeCmd returns a new cobra.Command for the "serve" command, which exposes
+// the vault over a local HTTP API.
+func NewServeCmd(deps Dependencies) *cobra.Command {
+	var (
+		addr  string
+		token string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the vault over a local HTTP API",
+		Long: `Serve a JSON API on localhost so editor plugins, browser extensions and shortcuts
+can work with the vault without shelling out:
+
+  GET  /api/notes?tag=&q=   list notes, filtered by tag and title
+  POST /api/notes           create a note from {"title", "dir", "content"}
+  GET  /api/notes/<path>    read a note
+  PUT  /api/notes/<path>    replace the content of a note with {"content"}
+  GET  /api/search?q=       find lines containing q
+  GET  /api/render/<path>   render a note to HTML
+
+Paths are relative to data_home; new notes go to the inbox unless a directory is
+given. Clients must send "Authorization: Bearer <token>", with the token given by
+--token or ` + serveTokenEnv + `, or else generated and printed at startup. POST and PUT
+bodies must be sent as application/json. Requests addressed to another host than
+localhost (or the host of --addr), as by DNS rebinding, and requests made by web
+pages of another origin are rejected. Notes are saved as by the other commands:
+locked notes and --read-only are respected, and previous versions kept.`,
+		Example: examples(
+			ex("exo serve", "Serve the API on "+defaultServeAddr),
+			ex("exo serve --addr 127.0.0.1:9000 --token s3cret", "Use another port and a token of your own"),
+			ex(`curl -s -H "Authorization: Bearer $EXO_SERVE_TOKEN" 'localhost:7474/api/notes?tag=go'`, "List the notes tagged #go"),
+		),
+		Annotations: mutatesOnRequest(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if token == "" {
+				token = os.Getenv(serveTokenEnv)
+			}
+			generated := token == ""
+			if generated {
+				var err error
+				if token, err = newServeToken(); err != nil {
+					return err
+				}
+			}
+			hosts := server.LoopbackHosts
+			if host, _, err := net.SplitHostPort(addr); err == nil && host != "" && !net.ParseIP(host).IsUnspecified() {
+				hosts = append(slices.Clone(hosts), host)
+			}
+			handler := server.New(server.Options{
+				Notes: vault.Notes{
+					Config:     *deps.Config,
+					FS:         deps.FS,
+					Logger:     deps.Logger,
+					NewNoteDir: deps.Config.Dir.InboxDir,
+					Exclude:    []string{deps.Config.Dir.TemplateDir, deps.Config.Dir.PluginDir},
+					Ignore:     vaultIgnore(deps),
+				},
+				Token: token,
+				Hosts: hosts,
+			}).Handler()
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+			srv := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				_ = srv.Shutdown(shutdownCtx)
+			}()
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving %s on http://%s (Ctrl-C to stop)\n", deps.Config.Dir.DataHome, listener.Addr())
+			if generated {
+				fmt.Fprintf(cmd.OutOrStdout(), "Token: %s (set --token or %s to choose it)\n", token, serveTokenEnv)
+			}
+			if err := srv.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", defaultServeAddr, "address to listen on")
+	cmd.Flags().StringVar(&token, "token", "", "bearer token clients must send (defaults to $"+serveTokenEnv+", or a generated one)")
+	return cmd
+}
+
+// newServeToken returns a random API token.
+func newServeToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate a token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// defaultShowWidth is the width notes are wrapped at when it cannot be taken
+// from the terminal.
+const defaultShowWidth = 80
+
+// NewShowCmd returns a new cobra.Command for the "show" command, which prints a
+// note rendered for the terminal.
+func NewShowCmd(deps Dependencies) *cobra.Command {
+	var (
+		resolve bool
+		style   string
+		width   int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "show <note>",
+		Short: "Print a note rendered for the terminal",
+		Long: `Print the body of a note, given as a path, file name, title, ID or alias,
+rendered from Markdown with colors and styles, wrapped to the width of the
+terminal.
+
+The "auto" style picks the dark or light style from the background of the
+terminal, and plain text without escape sequences when the output is not a
+terminal. With --resolve, embeds are expanded as with "exo cat --resolve".`,
+		Example: examples(
+			ex(`exo show "Go channels"`, "Render a note"),
+			ex(`exo show --style light --width 100 20250208143005`, "Render a note in the light style"),
+			ex(`exo show --resolve "Weekly review" | less -R`, "Page through a note with its embeds expanded"),
+		),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := styles.DefaultStyles[style]; !ok && style != styles.AutoStyle {
+				return fmt.Errorf("unknown style %q (available: %s)", style, strings.Join(showStyles(), ", "))
+			}
+			path, err := resolveNotePath(deps, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			data, err := deps.FS.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read note: %w", err)
+			}
+			_, body := frontmatter.Split(string(data))
+			if resolve {
+				if body, err = noteTranscluder(deps).Expand(path, body); err != nil {
+					return err
+				}
+			}
+
+			if width <= 0 {
+				width = defaultShowWidth
+				if w, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && w > 0 {
+					width = w
+				}
+			}
+			r, err := glamour.NewTermRenderer(glamour.WithStandardStyle(style), glamour.WithWordWrap(width))
+			if err != nil {
+				return fmt.Errorf("failed to create renderer: %w", err)
+			}
+			out, err := r.Render(body)
+			if err != nil {
+				return fmt.Errorf("failed to render note: %w", err)
+			}
+			fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&resolve, "resolve", "r", false, "Expand ![[note#heading]] embeds")
+	cmd.Flags().StringVar(&style, "style", styles.AutoStyle, "Rendering style ("+strings.Join(showStyles(), ", ")+")")
+	cmd.Flags().IntVar(&width, "width", 0, "Width to wrap the note at (default: the terminal width, or 80)")
+	return cmd
+}
+
+// showStyles returns the names of the rendering styles, "auto" first.
+func showStyles() []string {
+	names := make([]string, 0, len(styles.DefaultStyles))
+	for name := range styles.DefaultStyles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{styles.AutoStyle}, names...)
+}
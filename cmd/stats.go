@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/stats"
+)
+
+// statsHeading is the heading under which --append-weekly writes the stats block.
+const statsHeading = "## Stats"
+
+// NewStatsCmd returns a new cobra.Command for the "stats" command.
+func NewStatsCmd(deps Dependencies) *cobra.Command {
+	var (
+		jsonOut      bool
+		days         int
+		top          int
+		appendWeekly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show vault statistics",
+		Example: examples(
+			ex("exo stats", "Show note counts, words, tags and links"),
+			ex("exo stats --days 7 --append-weekly", "Summarize the last week into this week's note"),
+		),
+		Annotations: mutatesOnRequest(),
+		Long: `Show statistics about the vault: note counts per type, words written per day
+and week (from daily notes), tag frequency, link counts, and the largest and stalest notes.
+
+Use --append-weekly to add a summary block to the current weekly note.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if appendWeekly && deps.ReadOnly {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("--append-weekly modifies notes: %w (drop --read-only or set general.read_only to false)", fs.ErrReadOnly)
+			}
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to scan vault: %w", err)
+			}
+			opts := stats.Options{
+				Types:    noteTypeDirs(deps),
+				DailyDir: filepath.Join(deps.Config.Dir.DataHome, "day"),
+				Top:      top,
+			}
+			if days > 0 {
+				opts.Since = periodic.DayOf(time.Now()).AddDate(0, 0, -days+1)
+			}
+			s := stats.Compute(notes, opts)
+
+			if appendWeekly {
+				weekly, err := periodic.NewWeeklyNote(cmd.Context(), time.Now(), *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+				if err != nil {
+					return fmt.Errorf("failed to create weekly note: %w", err)
+				}
+				block := fmt.Sprintf("### %s\n\n%s", time.Now().Format("2006-01-02 15:04"), s.Markdown())
+				if err := weekly.SetContent(md.AppendUnder(weekly.Content(), statsHeading, block)); err != nil {
+					return err
+				}
+				if err := weekly.Save(); err != nil {
+					return fmt.Errorf("failed to save weekly note: %w", err)
+				}
+				deps.Logger.Infof("Appended stats to %s", weekly.Path())
+			}
+
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(s)
+			}
+			return writeStats(cmd.OutOrStdout(), s)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output statistics as JSON")
+	cmd.Flags().IntVar(&days, "days", 14, "Number of days covered by the words-per-day series (0 for all)")
+	cmd.Flags().IntVar(&top, "top", 5, "Number of tags, largest and stalest notes to report")
+	cmd.Flags().BoolVar(&appendWeekly, "append-weekly", false, "Append a stats block to the current weekly note")
+	cmd.AddCommand(newStatsStreakCmd(deps))
+	return cmd
+}
+
+// newStatsStreakCmd returns the "stats streak" command, which reports the
+// runs of consecutive days daily notes were written on.
+func newStatsStreakCmd(deps Dependencies) *cobra.Command {
+	var jsonOut bool
+
+	cmd := &cobra.Command{
+		Use:   "streak",
+		Short: "Show the current and longest streaks of daily notes",
+		Long: `Show the number of consecutive days, up to today, with a written daily note,
+the longest such streak and the number of days written.
+
+A daily note counts as written when it has a line its template did not put
+there; numbers are ignored. Templates show the streaks as .Streak.Current and
+.Streak.Longest.`,
+		Example: examples(
+			ex("exo stats streak", "Show the journaling streaks"),
+			ex("exo stats streak --json", "Output the streaks as JSON"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			streak, err := periodic.Streaks(cmd.Context(), time.Now(), *deps.Config, deps.TemplateManager, deps.FS)
+			if err != nil {
+				return err
+			}
+			if jsonOut {
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				return enc.Encode(streak)
+			}
+			return writeStreak(cmd.OutOrStdout(), streak)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Output the streaks as JSON")
+	return cmd
+}
+
+// writeStreak renders streaks as human-readable text.
+func writeStreak(w io.Writer, s periodic.Streak) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Current streak:\t%s\n", pluralDays(s.Current))
+	if s.Longest > 0 {
+		end := s.LongestStart.AddDate(0, 0, s.Longest-1)
+		fmt.Fprintf(tw, "Longest streak:\t%s (%s to %s)\n", pluralDays(s.Longest),
+			s.LongestStart.Format(dailyDateLayout), end.Format(dailyDateLayout))
+	} else {
+		fmt.Fprintf(tw, "Longest streak:\t%s\n", pluralDays(0))
+	}
+	fmt.Fprintf(tw, "Days written:\t%d\n", s.Days)
+	if !s.Last.IsZero() {
+		fmt.Fprintf(tw, "Last written:\t%s\n", s.Last.Format(dailyDateLayout))
+	}
+	return tw.Flush()
+}
+
+// pluralDays returns n days, as "1 day" or "3 days".
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// noteTypeDirs maps the configured note directories to note type names.
+func noteTypeDirs(deps Dependencies) map[string]string {
+	dirs := deps.Config.Dir
+	return map[string]string{
+		filepath.Join(dirs.DataHome, "day"):     "daily",
+		filepath.Join(dirs.DataHome, "week"):    "weekly",
+		filepath.Join(dirs.DataHome, "month"):   "monthly",
+		filepath.Join(dirs.DataHome, "quarter"): "quarterly",
+		dirs.PeriodicDir:                        "periodic",
+		dirs.InboxDir:                           "zettel",
+		dirs.ZettelDir:                          "zettel",
+		dirs.IdeaDir:                            "idea",
+		dirs.ProjectsDir:                        "project",
+	}
+}
+
+// writeStats renders statistics as human-readable text.
+func writeStats(w io.Writer, s stats.Stats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Notes:\t%d\n", s.Notes)
+	fmt.Fprintf(tw, "Words:\t%d\n", s.Words)
+	fmt.Fprintf(tw, "Links:\t%d\n", s.Links)
+	writeCounts(tw, "Notes by type", s.ByType)
+	writeCounts(tw, "Words per day", s.WordsPerDay)
+	writeCounts(tw, "Words per week", s.WordsPerWeek)
+	writeCounts(tw, "Top tags", s.Tags)
+	if len(s.Largest) > 0 {
+		fmt.Fprintln(tw, "\nLargest notes:")
+		for _, n := range s.Largest {
+			fmt.Fprintf(tw, "  %s\t%d words\n", n.Title, n.Words)
+		}
+	}
+	if len(s.Stalest) > 0 {
+		fmt.Fprintln(tw, "\nStalest notes:")
+		for _, n := range s.Stalest {
+			fmt.Fprintf(tw, "  %s\t%s\n", n.Title, n.Modified.Format("2006-01-02"))
+		}
+	}
+	return tw.Flush()
+}
+
+// writeCounts writes a titled list of counters, skipping empty lists.
+func writeCounts(w io.Writer, title string, counts []stats.Count) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s:\n", title)
+	for _, c := range counts {
+		fmt.Fprintf(w, "  %s\t%d\n", c.Name, c.Count)
+	}
+}
@@ -0,0 +1,29 @@
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_ReadOnly(t *testing.T) {
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(t.TempDir())
+	t.Cleanup(cleanup)
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fs.NewReadOnlyFileSystem(fsys), ReadOnly: true}
+
+	run := func(args ...string) error {
+		root := cmd.NewRootCmd(deps)
+		root.AddCommand(cmd.NewStatsCmd(deps))
+		root.SetOut(&bytes.Buffer{})
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs(args)
+		return root.Execute()
+	}
+	require.NoError(t, run("stats"), "reading statistics is allowed in read-only mode")
+	assert.ErrorIs(t, run("stats", "--append-weekly"), fs.ErrReadOnly)
+}
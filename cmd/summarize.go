@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/periodic"
+)
+
+// summaryTemplate is the template a summary is rendered with.
+const summaryTemplate = "summary"
+
+// NewSummarizeCmd returns a new cobra.Command for the "summarize" command, which
+// aggregates daily notes into the weekly note.
+func NewSummarizeCmd(deps Dependencies) *cobra.Command {
+	var (
+		week      bool
+		dateFlag  string
+		printOnly bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "summarize",
+		Short: "Summarize the last week of daily notes into the weekly note",
+		Long: `Aggregate the daily notes of the last 7 days, up to today (or --date), into the
+"## Summary" section of the weekly note, replacing an earlier summary.
+
+The summary lists the entries of the daily notes under their headings, the
+completed tasks and the zettels created in that time. List items copied
+unchanged from the day template, and unchecked tasks, are left out. It is
+rendered with the summary template, which receives the start and end dates,
+the days found, the sections with their entries, the completed tasks and the
+zettels.`,
+		Example: examples(
+			ex("exo summarize --week", "Summarize the last 7 days into this week's note"),
+			ex("exo summarize --week --date 2025-02-09 --print", "Print the summary of another week"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !week {
+				return fmt.Errorf("choose the period to summarize (--week)")
+			}
+			to, err := parseDayDate(dateFlag)
+			if err != nil {
+				return err
+			}
+			notes, err := vaultNotes(deps)
+			if err != nil {
+				return fmt.Errorf("failed to scan vault: %w", err)
+			}
+			opts := periodic.SummaryOptions{
+				DailyDir: filepath.Join(deps.Config.Dir.DataHome, "day"),
+				From:     to.AddDate(0, 0, -6),
+				To:       to,
+			}
+			zettelDir := deps.Config.Dir.ZettelDir + string(filepath.Separator)
+			for _, n := range notes {
+				if strings.HasPrefix(n.Path, zettelDir) {
+					opts.Zettels = append(opts.Zettels, n)
+				}
+			}
+			if _, opts.Template, err = deps.TemplateManager.Resolve("day"); err != nil {
+				return err
+			}
+			s, err := periodic.Summarize(deps.FS, opts)
+			if err != nil {
+				return err
+			}
+			content, err := deps.TemplateManager.ProcessTemplateWithContext(cmd.Context(), summaryTemplate, s)
+			if err != nil {
+				return err
+			}
+			if printOnly {
+				fmt.Fprintln(cmd.OutOrStdout(), strings.TrimRight(content, "\n"))
+				return nil
+			}
+
+			weekly, err := periodic.NewWeeklyNote(cmd.Context(), to, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+			if err != nil {
+				return fmt.Errorf("failed to create weekly note: %w", err)
+			}
+			if err := weekly.SetContent(md.Replace(weekly.Content(), periodic.SummaryHeading, content)); err != nil {
+				return err
+			}
+			if err := weekly.Save(); err != nil {
+				return fmt.Errorf("failed to save weekly note: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), weekly.Path())
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&week, "week", "w", false, "Summarize the last 7 days into the weekly note")
+	cmd.Flags().StringVar(&dateFlag, "date", "", "Last day summarized, as YYYY-MM-DD (default today)")
+	cmd.Flags().BoolVarP(&printOnly, "print", "p", false, "Print the summary instead of writing it to the weekly note")
+	return cmd
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/sync"
+)
+
+// annotationMutates marks commands that modify notes, which triggers the
+// optional auto-commit hook after they run.
+const annotationMutates = "exo.mutates"
+
+// annotationOnRequest marks commands that modify notes only on request, such
+// as stats with --append-weekly or serve for its clients. Unlike the other
+// commands modifying notes, they run in read-only mode, where the file system
+// refuses their writes.
+const annotationOnRequest = "exo.on-request"
+
+// mutates returns the annotations marking a command as modifying notes.
+func mutates() map[string]string {
+	return map[string]string{annotationMutates: "true"}
+}
+
+// mutatesOnRequest returns the annotations marking a command as modifying
+// notes only on request.
+func mutatesOnRequest() map[string]string {
+	annotations := mutates()
+	annotations[annotationOnRequest] = "true"
+	return annotations
+}
+
+// NewSyncCmd creates a new "sync" command with init, status, push and pull subcommands.
+func NewSyncCmd(deps Dependencies) *cobra.Command {
+	syncCmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Synchronize the vault with a Git remote",
+		Example: examples(
+			ex("exo sync init --remote git@example.com:me/notes.git", "Start syncing the vault"),
+			ex("exo sync pull && exo sync push", "Exchange changes with the remote"),
+		),
+		Long: `Synchronize the vault (data_home) with a Git remote.
+
+Set the remote with "exo config set sync.remote <url>" and run "exo sync init".
+With sync.auto_commit enabled, changes are committed after every command that
+modifies notes.`,
+	}
+	syncCmd.AddCommand(newSyncInitCmd(deps))
+	syncCmd.AddCommand(newSyncStatusCmd(deps))
+	syncCmd.AddCommand(newSyncPushCmd(deps))
+	syncCmd.AddCommand(newSyncPullCmd(deps))
+	return syncCmd
+}
+
+func newSyncInitCmd(deps Dependencies) *cobra.Command {
+	var remote string
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Initialize a Git repository in the data home",
+		Example: examples(
+			ex("exo sync init --remote git@example.com:me/notes.git", "Turn the vault into a Git repository with a remote"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if remote != "" && remote != deps.Config.Sync.Remote {
+				deps.Config.Sync.Remote = remote
+				if err := deps.Config.Save(); err != nil {
+					return fmt.Errorf("failed to save configuration: %w", err)
+				}
+			}
+			backend := sync.NewGitBackend(deps.Config.Dir.DataHome)
+			if err := backend.Init(deps.Config.Sync.Remote); err != nil {
+				return fmt.Errorf("failed to initialize sync: %w", err)
+			}
+			deps.Logger.Infof("Initialized Git sync in %s", deps.Config.Dir.DataHome)
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&remote, "remote", "", "Remote URL to record in sync.remote")
+	return cmd
+}
+
+func newSyncStatusCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show local changes and remote tracking state",
+		Example: examples(
+			ex("exo sync status", "Show uncommitted changes and commits to push"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status, err := sync.NewGitBackend(deps.Config.Dir.DataHome).Status()
+			if err != nil {
+				return err
+			}
+			out := cmd.OutOrStdout()
+			remote := status.Remote
+			if remote == "" {
+				remote = "(none)"
+			}
+			fmt.Fprintf(out, "Branch: %s\nRemote: %s\n", status.Branch, remote)
+			if status.Ahead > 0 || status.Behind > 0 {
+				fmt.Fprintf(out, "Ahead %d, behind %d\n", status.Ahead, status.Behind)
+			}
+			if status.Clean() {
+				fmt.Fprintln(out, "No local changes")
+				return nil
+			}
+			fmt.Fprintln(out, "Changes:")
+			for _, c := range status.Changes {
+				fmt.Fprintf(out, "  %-10s %s\n", c.Status, c.Path)
+			}
+			return nil
+		},
+	}
+}
+
+func newSyncPushCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "push",
+		Short: "Commit local changes and push them to the remote",
+		Example: examples(
+			ex("exo sync push", "Commit changes and push them to the remote"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backend := sync.NewGitBackend(deps.Config.Dir.DataHome)
+			if err := commitChanges(backend, "sync push"); err != nil {
+				return err
+			}
+			if err := backend.Push(); err != nil {
+				return fmt.Errorf("failed to push: %w", err)
+			}
+			deps.Logger.Info("Pushed vault to remote")
+			return nil
+		},
+	}
+}
+
+func newSyncPullCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "pull",
+		Short: "Commit local changes and merge changes from the remote",
+		Example: examples(
+			ex("exo sync pull", "Fetch and merge changes from the remote"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			backend := sync.NewGitBackend(deps.Config.Dir.DataHome)
+			if err := commitChanges(backend, "sync pull"); err != nil {
+				return err
+			}
+			err := backend.Pull()
+			var conflict *sync.ConflictError
+			if errors.As(err, &conflict) {
+				out := cmd.ErrOrStderr()
+				fmt.Fprintln(out, "Merge conflicts in the following notes; resolve them and run \"exo sync push\":")
+				for _, path := range conflict.Paths {
+					fmt.Fprintf(out, "  %s\n", path)
+				}
+				return err
+			}
+			if err != nil {
+				return fmt.Errorf("failed to pull: %w", err)
+			}
+			deps.Logger.Info("Pulled changes from remote")
+			return nil
+		},
+	}
+}
+
+// commitChanges commits any pending changes with a message describing them.
+func commitChanges(backend *sync.GitBackend, command string) error {
+	status, err := backend.Status()
+	if err != nil {
+		return err
+	}
+	if status.Clean() {
+		return nil
+	}
+	if _, err := backend.Commit(sync.CommitMessage(command, status.Changes)); err != nil {
+		return fmt.Errorf("failed to commit changes: %w", err)
+	}
+	return nil
+}
+
+// autoCommit commits the changes made by cmd when auto-commit is enabled,
+// cmd is marked as modifying notes, and the vault is a Git repository.
+func autoCommit(deps Dependencies, cmd *cobra.Command) {
+	if !deps.Config.Sync.AutoCommit || cmd.Annotations[annotationMutates] != "true" {
+		return
+	}
+	backend := sync.NewGitBackend(deps.Config.Dir.DataHome)
+	if !backend.IsRepo() {
+		return
+	}
+	command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	if err := commitChanges(backend, command); err != nil {
+		deps.Logger.Errorf("Auto-commit failed: %v", err)
+	}
+}
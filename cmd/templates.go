@@ -1,12 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
+	iofs "io/fs"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"github.com/a-kostevski/exo/pkg/plugin"
 	"github.com/a-kostevski/exo/pkg/templates"
 )
 
@@ -17,36 +22,32 @@ func NewTemplateCmd(deps Dependencies) *cobra.Command {
 	var installFlag bool
 
 	cmd := &cobra.Command{
-		Use:   "templates",
-		Short: "List available templates or install defaults",
+		Use:     "templates",
+		Aliases: []string{"template"},
+		Short:   "List available templates or install defaults",
+		Example: examples(
+			ex("exo templates", "List the custom templates"),
+			ex("exo templates --install", "Install the built-in templates"),
+			ex("exo template install gtd", `Install the "gtd" template pack`),
+		),
 		Long: `Manage templates.
 
-By default, this command lists the available custom templates.
+By default, this command lists the available templates and where each comes
+from. A template is looked up in the custom template directory (dir.template_dir),
+then the profile directory shared by all vaults (templates.profile_dir), then the
+built-in defaults. Scaffolds, directories of templates and other files that
+"exo new" creates as a set, are kept in the scaffolds directory of either.
 Use the --install flag to install built-in default templates into your custom template directory.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if installFlag {
-				// Create a default template store using embedded default templates.
-				defaultStore := templates.NewEmbedTemplateStore(templates.DefaultTemplatesFS, templates.DefaultTemplateBaseDir)
-				opts := templates.InstallOptions{
-					TargetDir: deps.Config.Dir.TemplateDir,
-					Force:     false,
-					Reader:    &defaultInputReader{},
-				}
-				if err := templates.InstallDefaultTemplates(templates.TemplateConfig{
-					TemplateDir:       deps.Config.Dir.TemplateDir,
-					TemplateExtension: ".md",
-					FilePermissions:   0644,
-					Logger:            deps.Logger,
-					FS:                deps.FS,
-				}, opts, defaultStore); err != nil {
+				if err := installTemplates(deps.Config, false, deps.Logger, deps.FS); err != nil {
 					return fmt.Errorf("failed to install default templates: %w", err)
 				}
-				deps.Logger.Info("Default templates installed successfully")
 				return nil
 			}
 
 			// Otherwise, list available templates.
-			names, err := deps.TemplateManager.ListTemplates()
+			names, err := templateNames(deps)
 			if err != nil {
 				return fmt.Errorf("failed to list templates: %w", err)
 			}
@@ -56,19 +57,282 @@ Use the --install flag to install built-in default templates into your custom te
 			}
 			fmt.Println("Available templates:")
 			for _, name := range names {
-				customPath := filepath.Join(deps.Config.Dir.TemplateDir, name+".md")
-				var source string
-				if _, err := os.Stat(customPath); err == nil {
-					source = "[Custom]"
-				} else {
-					source = "[Built-in]"
+				src, _, err := deps.TemplateManager.Resolve(name)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("  - %s %s\n", originLabels[src.Origin], name)
+			}
+			scaffolds, err := deps.TemplateManager.ListScaffolds()
+			if err != nil {
+				return fmt.Errorf("failed to list scaffolds: %w", err)
+			}
+			if len(scaffolds) > 0 {
+				fmt.Println("Available scaffolds:")
+				for _, name := range scaffolds {
+					src, _, err := deps.TemplateManager.ResolveScaffold(name)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("  - %s %s\n", originLabels[src.Origin], name)
 				}
-				fmt.Printf("  - %s %s\n", source, name)
 			}
 			return nil
 		},
 	}
 
 	cmd.Flags().BoolVarP(&installFlag, "install", "i", false, "Install default templates into the custom template directory")
+	cmd.AddCommand(newTemplateInstallCmd(deps))
+	cmd.AddCommand(newTemplateWhichCmd(deps))
+	cmd.AddCommand(newTemplateDiffCmd(deps))
+	cmd.AddCommand(newTemplateResetCmd(deps))
+	return cmd
+}
+
+// originLabels are the labels "exo templates" lists templates with.
+var originLabels = map[templates.Origin]string{
+	templates.OriginCustom:  "[Custom]",
+	templates.OriginProfile: "[Profile]",
+	templates.OriginBuiltIn: "[Built-in]",
+}
+
+func newTemplateWhichCmd(deps Dependencies) *cobra.Command {
+	var printContent bool
+
+	cmd := &cobra.Command{
+		Use:   "which <name>",
+		Short: "Show where a template is resolved from",
+		Long: `Show where the named template is resolved from: the custom template
+directory, the profile directory or the built-in defaults, in that order.`,
+		Example: examples(
+			ex("exo template which day", "Show which day template new daily notes use"),
+			ex("exo template which zettel --print", "Print the zettel template in use"),
+		),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			src, content, err := deps.TemplateManager.Resolve(args[0])
+			if err != nil {
+				return err
+			}
+			if printContent {
+				fmt.Fprint(cmd.OutOrStdout(), content)
+				return nil
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", src.Origin, src.Path)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&printContent, "print", "p", false, "Print the template instead of where it comes from")
+	return cmd
+}
+
+func newTemplateDiffCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "diff [name...]",
+		Short: "Show how templates differ from the built-in defaults",
+		Long: `Show, as a unified diff, how the named templates in use differ from the
+built-in defaults, e.g. to bring changes to the defaults made by an upgrade into
+customized templates. Without names, every customized template is compared.`,
+		Example: examples(
+			ex("exo template diff", "Compare every customized template"),
+			ex("exo template diff day", "Compare the day template"),
+		),
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: completeTemplateNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names := args
+			if len(names) == 0 {
+				all, err := templateNames(deps)
+				if err != nil {
+					return err
+				}
+				for _, name := range all {
+					if _, err := templates.LoadDefaultTemplate(name); err == nil {
+						names = append(names, name)
+					}
+				}
+			}
+			differ := false
+			for _, name := range names {
+				src, content, err := deps.TemplateManager.Resolve(name)
+				if err != nil {
+					return err
+				}
+				if src.Origin == templates.OriginBuiltIn {
+					continue
+				}
+				diff, err := templates.DiffDefault(name, src, content)
+				if err != nil {
+					return err
+				}
+				if diff != "" {
+					differ = true
+					fmt.Fprint(cmd.OutOrStdout(), diff)
+				}
+			}
+			if !differ {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No template differs from the built-in defaults")
+			}
+			return nil
+		},
+	}
+}
+
+func newTemplateResetCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "reset <name>",
+		Short: "Restore a template to the built-in default",
+		Long: `Restore the named template in use, from the template directory or the
+profile directory, to the built-in default. The template is backed up to a
+.bak file first.`,
+		Example: examples(
+			ex("exo template diff day", "Review the changes that would be lost"),
+			ex("exo template reset day", "Restore the day template"),
+		),
+		Annotations:       mutates(),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeTemplateNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			src, _, err := deps.TemplateManager.Resolve(name)
+			if err != nil {
+				return err
+			}
+			if src.Origin == templates.OriginBuiltIn {
+				return fmt.Errorf("template %s already is the built-in default", name)
+			}
+			backup, err := templates.ResetTemplate(name, src.Path)
+			if err != nil {
+				return err
+			}
+			deps.Logger.Infof("Restored %s to the built-in default (backup: %s)", src.Path, backup)
+			return nil
+		},
+	}
+}
+
+// completeTemplateNames completes the names of the available templates.
+func completeTemplateNames(deps Dependencies) completionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		names, _ := templateNames(deps)
+		return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// templateNames returns the sorted names of the templates in the custom
+// template directory, the profile directory and the built-in defaults.
+func templateNames(deps Dependencies) ([]string, error) {
+	seen := make(map[string]bool)
+	add := func(entries []iofs.DirEntry) {
+		for _, e := range entries {
+			if !e.IsDir() && filepath.Ext(e.Name()) == ".md" {
+				seen[strings.TrimSuffix(e.Name(), ".md")] = true
+			}
+		}
+	}
+	for _, dir := range []string{deps.Config.Dir.TemplateDir, deps.Config.Templates.ProfileDir} {
+		if dir == "" {
+			continue
+		}
+		entries, err := deps.FS.ReadDir(dir)
+		if err != nil && !errors.Is(err, iofs.ErrNotExist) {
+			return nil, err
+		}
+		add(entries)
+	}
+	entries, err := templates.DefaultTemplatesFS.ReadDir(templates.DefaultTemplateBaseDir)
+	if err != nil {
+		return nil, err
+	}
+	add(entries)
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func newTemplateInstallCmd(deps Dependencies) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "install [pack]",
+		Short: "Install the built-in templates or a template pack",
+		Long: `Install the built-in templates, or the named template pack, into the custom
+template directory. Existing templates are backed up before they are
+overwritten, after asking unless --force is given.
+
+Template packs are listed in templates.packs, each with where it is fetched
+from: a local directory, a git repository or the URL of a .tar.gz archive.
+A pack holds its templates in a "templates" directory, or at its top level:
+
+  templates:
+    packs:
+      gtd: https://github.com/me/exo-gtd.git
+      work: ~/exo-packs/work`,
+		Example: examples(
+			ex("exo template install", "Install the built-in templates"),
+			ex("exo template install gtd", `Install the "gtd" template pack`),
+			ex("exo config set templates.packs.work ~/exo-packs/work", "Add a template pack"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.MaximumNArgs(1),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return filterPrefix(deps.Config.PackNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				if err := installTemplates(deps.Config, force, deps.Logger, deps.FS); err != nil {
+					return fmt.Errorf("failed to install default templates: %w", err)
+				}
+				return nil
+			}
+			name := args[0]
+			source, ok := deps.Config.Templates.Packs[name]
+			if !ok {
+				packs := "none"
+				if names := deps.Config.PackNames(); len(names) > 0 {
+					packs = strings.Join(names, ", ")
+				}
+				return fmt.Errorf("unknown template pack %q (configured in templates.packs: %s)", name, packs)
+			}
+			if err := installTemplatePack(cmd.Context(), deps, force, source); err != nil {
+				return fmt.Errorf("failed to install template pack %s: %w", name, err)
+			}
+			deps.Logger.Infof("Template pack %s installed successfully", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing templates without asking")
 	return cmd
 }
+
+// installTemplatePack installs the template pack at source into the custom
+// template directory.
+func installTemplatePack(ctx context.Context, deps Dependencies, force bool, source string) error {
+	fileMode, dirMode, err := deps.Config.Templates.Modes()
+	if err != nil {
+		return err
+	}
+	opts := templates.InstallOptions{
+		TargetDir: deps.Config.Dir.TemplateDir,
+		Force:     force,
+		Reader:    &defaultInputReader{},
+	}
+	return templates.InstallPack(ctx, templates.TemplateConfig{
+		TemplateDir:       deps.Config.Dir.TemplateDir,
+		TemplateExtension: ".md",
+		FilePermissions:   fileMode,
+		DirPermissions:    dirMode,
+		Funcs:             plugin.FuncMap(deps.Plugins),
+		Logger:            deps.Logger,
+		FS:                deps.FS,
+	}, opts, source)
+}
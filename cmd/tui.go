@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/tui"
+)
+
+// trashDir is the hidden directory, under data_home, deleted notes are moved to.
+const trashDir = ".trash"
+
+// NewTUICmd returns a new cobra.Command for the "tui" command, an interactive
+// browser of the vault.
+func NewTUICmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "tui",
+		Short: "Browse the vault in an interactive terminal UI",
+		Long: `Browse the vault in three panes: directories, the notes in the selected
+directory, and a preview of the selected note.
+
+Keys:
+  tab, ←/→   switch pane            /      search titles and content
+  ↑/↓, j/k   move or scroll         t      filter by tag
+  enter, e   open in the editor     esc    clear search and tag filters
+  n          new note in directory  d      move note to the trash
+  r          reload                 q      quit
+
+Deleted notes are moved to ` + trashDir + `/ under data_home, where they can be recovered.`,
+		Example: examples(
+			ex("exo tui", "Browse the vault"),
+		),
+		Annotations: mutatesOnRequest(),
+		Args:        cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !tui.IsTerminal(os.Stdin) {
+				return errors.New("the terminal browser needs an interactive terminal")
+			}
+			actions := tuiActions{ctx: cmd.Context(), deps: deps}
+			notes, err := actions.Load()
+			if err != nil {
+				return fmt.Errorf("failed to scan notes: %w", err)
+			}
+			return tui.Run(tui.NewModel(deps.Config.Dir.DataHome, notes, actions))
+		},
+	}
+}
+
+// tuiActions performs the actions of the terminal browser on the vault.
+type tuiActions struct {
+	ctx  context.Context
+	deps Dependencies
+}
+
+func (a tuiActions) Load() ([]scan.Note, error) {
+	return vaultNotes(a.deps)
+}
+
+func (a tuiActions) Open(path string) error {
+	return a.deps.FS.OpenInEditor(path, a.deps.Config.General.Editor)
+}
+
+// Create creates an empty note titled title in dir, or in the inbox when no
+// directory is selected.
+func (a tuiActions) Create(dir, title string) (string, error) {
+	if dir == "" {
+		rel, err := filepath.Rel(a.deps.Config.Dir.DataHome, a.deps.Config.Dir.InboxDir)
+		if err != nil {
+			return "", err
+		}
+		dir = rel
+	}
+	n, err := note.NewBaseNote(title, *a.deps.Config, a.deps.TemplateManager, a.deps.Logger, a.deps.FS,
+		note.WithContext(a.ctx),
+		note.WithSubDir(dir),
+		note.WithFileName(noteFileName(a.deps, title)+scan.NoteExtension),
+		note.WithContent("# "+title+"\n"))
+	if err != nil {
+		return "", fmt.Errorf("failed to create note: %w", err)
+	}
+	if n.Exists() {
+		return "", exoerrors.New(exoerrors.Conflict, "note %s already exists", n.Path())
+	}
+	if err := n.Save(); err != nil {
+		return "", fmt.Errorf("failed to save note: %w", err)
+	}
+	return n.Path(), nil
+}
+
+// Trash moves the note at path into the trash directory, keeping its location
+// relative to data_home and adding a timestamp if a note of that name was
+// trashed before.
+func (a tuiActions) Trash(path string) (string, error) {
+	rel, err := filepath.Rel(a.deps.Config.Dir.DataHome, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("note %s is outside of data_home", path)
+	}
+	if a.deps.ReadOnly {
+		return "", fmt.Errorf("cannot move %s to the trash: %w", path, fs.ErrReadOnly)
+	}
+	if err := checkUnlocked(a.deps, path); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(a.deps.Config.Dir.DataHome, trashDir, rel)
+	if a.deps.FS.FileExists(dest) {
+		dest = strings.TrimSuffix(dest, scan.NoteExtension) + time.Now().Format("-20060102-150405") + scan.NoteExtension
+	}
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", fmt.Errorf("failed to create trash directory: %w", err)
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("failed to move note to the trash: %w", err)
+	}
+	return filepath.Rel(a.deps.Config.Dir.DataHome, dest)
+}
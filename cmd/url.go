@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/web"
+)
+
+// defaultSourcesHeading is the heading under which links are appended.
+const defaultSourcesHeading = "## Sources"
+
+// NewURLCmd creates a new "url" command with the "add" subcommand.
+func NewURLCmd(deps Dependencies) *cobra.Command {
+	urlCmd := &cobra.Command{
+		Use:   "url",
+		Short: "Manage links in notes",
+		Example: examples(
+			ex(`exo url add "Reading list" https://example.com`, "Append a titled link to a note"),
+		),
+	}
+	urlCmd.AddCommand(NewURLAddCmd(deps))
+	return urlCmd
+}
+
+// NewURLAddCmd creates the "url add" command, which appends a formatted link to a note.
+func NewURLAddCmd(deps Dependencies) *cobra.Command {
+	var (
+		archive bool
+		offline bool
+		heading string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "add <note> <url>",
+		Short: "Append a titled link to a note's Sources section",
+		Example: examples(
+			ex(`exo url add "Reading list" https://example.com`, "Append a titled link to the Sources section"),
+			ex(`exo url add --archive "Reading list" https://example.com`, "Also link the archived snapshot"),
+		),
+		Annotations: mutates(),
+		Long: `Fetch the title of a web page and append a Markdown link with the access date
+under the note's Sources section. If the page cannot be fetched, the bare URL is used.
+
+The note may be given as a path or as a note name (with or without .md).`,
+		Args: cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completeNoteNames(deps)(cmd, args, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rawURL := args[1]
+			if err := web.ValidateURL(rawURL); err != nil {
+				return err
+			}
+			path, err := resolveNotePath(deps, args[0])
+			if err != nil {
+				return err
+			}
+
+			var title, snapshot string
+			if !offline {
+				client := web.NewClient()
+				title, err = client.FetchTitle(cmd.Context(), rawURL)
+				if err != nil {
+					deps.Logger.Infof("Could not fetch page title, using bare URL: %v", err)
+				}
+				if archive {
+					snapshot, err = client.ArchiveSnapshot(cmd.Context(), rawURL)
+					if err != nil {
+						deps.Logger.Infof("Could not find archived snapshot: %v", err)
+					}
+				}
+			}
+
+			content, err := deps.FS.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read note: %w", err)
+			}
+			link := web.FormatLink(title, rawURL, snapshot, time.Now())
+			updated := md.AppendUnder(string(content), heading, link)
+			if err := deps.FS.WriteFile(path, []byte(updated)); err != nil {
+				return fmt.Errorf("failed to write note: %w", err)
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), link)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&archive, "archive", "a", false, "Also link the closest archive.org snapshot")
+	cmd.Flags().BoolVar(&offline, "offline", false, "Do not fetch the page; append the bare URL")
+	cmd.Flags().StringVar(&heading, "heading", defaultSourcesHeading, "Heading to append the link under")
+	return cmd
+}
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/diff"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/tui"
+	"github.com/a-kostevski/exo/pkg/versions"
+)
+
+// NewVersionsCmd returns a new cobra.Command for the "versions" command, which
+// lists the previous versions kept of a note.
+func NewVersionsCmd(deps Dependencies) *cobra.Command {
+	return &cobra.Command{
+		Use:   "versions <note>",
+		Short: "List the previous versions of a note",
+		Long: `List the previous versions of a note, given as a path, file name, title, ID or
+alias, the latest first.
+
+A version is kept, in ` + versions.Dir + ` under data_home, when exo overwrites a note or
+the note is changed in the editor opened by exo; general.versions sets how many
+are kept per note. Versions are referred to as @~1 for the latest, @~2 for the
+one before, and so on: compare them with "exo diff".`,
+		Example: examples(
+			ex(`exo versions "Go channels"`, "List the versions of a note"),
+		),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, err := resolveNotePath(deps, strings.Join(args, " "))
+			if err != nil {
+				return err
+			}
+			list, err := noteVersions(deps).List(path)
+			if err != nil {
+				return err
+			}
+			if len(list) == 0 {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No previous versions")
+				return nil
+			}
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "VERSION\tREPLACED\tSIZE")
+			for i, v := range list {
+				fmt.Fprintf(tw, "@~%d\t%s\t%d\n", i+1, v.Time.Format("2006-01-02 15:04:05"), v.Size)
+			}
+			return tw.Flush()
+		},
+	}
+}
+
+// NewDiffCmd returns a new cobra.Command for the "diff" command, which compares
+// two notes, a note with its previous versions or with the skeleton its template
+// renders.
+func NewDiffCmd(deps Dependencies) *cobra.Command {
+	var (
+		words    bool
+		template bool
+		color    string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "diff <note> [note|version] [version]",
+		Short: "Compare notes, versions of a note or a note with its template",
+		Long: `Show, as a unified diff, how a note differs from another note, from one of its
+previous versions or, with --template, from the skeleton its template renders.
+Notes are given as a path, file name, title, ID or alias; quote titles with
+spaces.
+
+Versions are listed by "exo versions": @~1 (the default) is the latest, @~2 the
+one before, and @ the note itself. With two versions, they are compared with
+each other.
+
+--template renders the template of a periodic note (day, week, month or
+quarter) for its period, with the links exo adds but without the data of
+providers, to show what was written in the note. --word compares words rather than lines.`,
+		Example: examples(
+			ex(`exo diff "Go channels"`, "Show the changes since the latest version"),
+			ex(`exo diff "Go channels" @~2 @~1`, "Compare two versions"),
+			ex(`exo diff "Go channels" "Go goroutines"`, "Compare two notes"),
+			ex("exo diff --template --word 2025-02-08", "Show what was written in a daily note"),
+		),
+		Args:              cobra.RangeArgs(1, 3),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch color {
+			case "auto", "always", "never":
+			default:
+				return exoerrors.New(exoerrors.Usage, "unknown color mode %q (expected auto, always or never)", color)
+			}
+			path, err := resolveNotePath(deps, args[0])
+			if err != nil {
+				return err
+			}
+			var from, to *diffText
+			switch {
+			case template:
+				if len(args) > 1 {
+					return exoerrors.New(exoerrors.Usage, "--template compares a single note")
+				}
+				title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+				skeleton, err := periodic.Skeleton(cmd.Context(), title, *deps.Config, deps.TemplateManager)
+				if err != nil {
+					return exoerrors.Wrap(exoerrors.Usage, err)
+				}
+				from = &diffText{content: skeleton, name: "template of " + title}
+				if to, err = readVersion(deps, path, "@"); err != nil {
+					return err
+				}
+			case len(args) > 1 && !versions.IsRef(args[1]):
+				if len(args) > 2 {
+					return exoerrors.New(exoerrors.Usage, "two notes are compared without versions")
+				}
+				other, err := resolveNotePath(deps, args[1])
+				if err != nil {
+					return err
+				}
+				if from, err = readVersion(deps, path, "@"); err != nil {
+					return err
+				}
+				if to, err = readVersion(deps, other, "@"); err != nil {
+					return err
+				}
+			default:
+				refs := []string{"@~1", "@"}
+				switch len(args) {
+				case 2:
+					refs[0] = args[1]
+				case 3:
+					refs = args[1:]
+				}
+				if from, err = readVersion(deps, path, refs[0]); err != nil {
+					return err
+				}
+				if to, err = readVersion(deps, path, refs[1]); err != nil {
+					return err
+				}
+			}
+
+			colored := color == "always" || (color == "auto" && os.Getenv("NO_COLOR") == "" && tui.IsTerminal(os.Stdout))
+			var out string
+			if words {
+				out = diff.Words(from.content, to.content, colored)
+			} else if out, err = diff.Unified(from.content, to.content, from.name, to.name, colored); err != nil {
+				return err
+			}
+			if out == "" {
+				fmt.Fprintln(cmd.ErrOrStderr(), "No differences")
+				return nil
+			}
+			fmt.Fprint(cmd.OutOrStdout(), out)
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVarP(&words, "word", "w", false, "Compare words rather than lines")
+	cmd.Flags().BoolVarP(&template, "template", "t", false, "Compare a periodic note with the skeleton of its template")
+	cmd.Flags().StringVar(&color, "color", "auto", "Color the changes: auto, always or never")
+	_ = cmd.RegisterFlagCompletionFunc("color", cobra.FixedCompletions([]string{"auto", "always", "never"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// noteVersions returns the store of the previous versions of notes.
+func noteVersions(deps Dependencies) *versions.Store {
+	return versions.NewStore(deps.Config.Dir.DataHome, deps.Config.General.Versions)
+}
+
+// diffText is a text compared by "exo diff", and the name it is shown under.
+type diffText struct {
+	content string
+	name    string
+}
+
+// readVersion returns the version ref of the note at path.
+func readVersion(deps Dependencies, path, ref string) (*diffText, error) {
+	n, err := versions.ParseRef(ref)
+	if err != nil {
+		return nil, exoerrors.Wrap(exoerrors.Usage, err)
+	}
+	if n == 0 {
+		data, err := deps.FS.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		return &diffText{content: string(data), name: path}, nil
+	}
+	v, err := noteVersions(deps).Get(path, ref)
+	if errors.Is(err, versions.ErrNoVersion) {
+		return nil, exoerrors.Wrap(exoerrors.NotFound, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := deps.FS.ReadFile(v.Path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read version: %w", err)
+	}
+	return &diffText{content: string(data), name: fmt.Sprintf("%s@~%d (%s)", path, n, v.Time.Format("2006-01-02 15:04:05"))}, nil
+}
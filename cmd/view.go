@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/moc"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+)
+
+// viewBlock is the name of the generated block listing the results of a view
+// in its note.
+const viewBlock = "view"
+
+// NewViewCmd returns a new cobra.Command for the "view" command, which runs the
+// saved queries defined under "views" in the configuration.
+func NewViewCmd(deps Dependencies) *cobra.Command {
+	var (
+		write         bool
+		format        string
+		snippetLength int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "view [name]",
+		Short: "Run a saved query",
+		Long: `Run a saved query, or view, defined under "views" in the configuration: the
+notes matching every filter it sets, a query (see "exo search") or a type, tag,
+text and creation date.
+
+  views:
+    reading:
+      tag: toread
+      sort: title
+    projects:
+      query: tag:project AND modified:>2024-01-01
+    recent-go:
+      type: zettel
+      text: go channels
+      since: 30d
+      limit: 20
+      note: Views/Recent Go
+
+since and before take a date (YYYY-MM-DD) or an age such as 30d, 6m or 1y.
+Notes are sorted by modified (the default), created or title, and limit caps
+their number. Without a name, the views are listed.
+
+A view with a note works like a smart folder: --write lists its results in
+the note, between "<!-- exo:begin view -->" and "<!-- exo:end view -->"
+markers, keeping whatever is written around them. Without a name, --write
+refreshes the notes of every view; "exo watch" refreshes them as notes change.`,
+		Example: examples(
+			ex("exo view", "List the saved views"),
+			ex("exo view reading --format paths", "Print the paths of the notes in the reading view"),
+			ex("exo view --write", "Refresh the notes of every view"),
+		),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeViews(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			if len(args) == 0 && !write {
+				return listViews(out, deps.Config)
+			}
+
+			ix, err := openIndex(deps)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			if _, err := ix.Sync(deps.Config.Dir.TemplateDir); err != nil {
+				return err
+			}
+
+			if len(args) == 0 {
+				return refreshViews(deps, ix, out)
+			}
+			name := strings.ToLower(args[0])
+			view, ok := deps.Config.Views[name]
+			if !ok {
+				return exoerrors.New(exoerrors.NotFound, "no view named %q (see \"exo view\")", args[0])
+			}
+			if write {
+				if view.Note == "" {
+					return exoerrors.New(exoerrors.Usage, "view %q has no note to write to (set views.%s.note)", name, name)
+				}
+				path, count, _, err := refreshView(deps, ix, name, view)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "%s (%d notes)\n", path, count)
+				return nil
+			}
+
+			notes, err := viewNotes(deps, ix, name, view)
+			if err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("snippet-length") {
+				snippetLength = deps.Config.Search.SnippetLength
+			}
+			return writeNotes(out, notes, noteTypeDirs(deps), format, snippetLength)
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVarP(&write, "write", "w", false, "Write the results to the note of the view (of every view without a name)")
+	flags.StringVarP(&format, "format", "f", "table", "Output format: table, json or paths")
+	flags.IntVar(&snippetLength, "snippet-length", 0, "Maximum length of note previews (default: search.snippet_length)")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "json", "paths"}, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+// listViews writes the configured views with their filters to w.
+func listViews(w io.Writer, cfg *config.Config) error {
+	if len(cfg.Views) == 0 {
+		fmt.Fprintln(w, `No views defined; add one under "views" in the configuration`)
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, name := range cfg.ViewNames() {
+		fmt.Fprintf(tw, "%s\t%s\n", name, cfg.Views[name])
+	}
+	return tw.Flush()
+}
+
+// viewNotes returns the notes matching view, sorted and limited as it says.
+// The notes of views are never part of the results, so that refreshing one
+// view cannot change another.
+func viewNotes(deps Dependencies, ix *store.Index, name string, view config.ViewConfig) ([]scan.Note, error) {
+	terms := []string{
+		fieldTerm(query.Type, "", view.Type),
+		fieldTerm(query.Tag, "", view.Tag),
+		fieldTerm(query.Created, ">=", view.Since),
+		fieldTerm(query.Created, "<", view.Before),
+	}
+	for _, word := range strings.Fields(view.Text) {
+		terms = append(terms, query.Quote(word))
+	}
+	q, err := parseQuery(view.Query, terms...)
+	if err != nil {
+		return nil, exoerrors.New(exoerrors.Config, "view %q: %v", name, err)
+	}
+	found, err := ix.Select(q)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search notes: %w", err)
+	}
+
+	viewPaths := make(map[string]bool)
+	for _, v := range deps.Config.Views {
+		if v.Note != "" {
+			viewPaths[viewNotePath(deps, v)] = true
+		}
+	}
+	notes := make([]scan.Note, 0, len(found))
+	for _, n := range found {
+		if !viewPaths[n.Path] {
+			notes = append(notes, n)
+		}
+	}
+
+	sortBy := scan.SortByModified
+	if view.Sort != "" {
+		sortBy = scan.SortField(view.Sort)
+	}
+	if err := scan.Sort(notes, sortBy); err != nil {
+		return nil, exoerrors.Wrap(exoerrors.Config, err)
+	}
+	if view.Limit > 0 && len(notes) > view.Limit {
+		notes = notes[:view.Limit]
+	}
+	return notes, nil
+}
+
+// viewNotePath returns the absolute path of the note of view.
+func viewNotePath(deps Dependencies, view config.ViewConfig) string {
+	path := view.Note
+	if filepath.Ext(path) != scan.NoteExtension {
+		path += scan.NoteExtension
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(deps.Config.Dir.DataHome, path)
+	}
+	return filepath.Clean(path)
+}
+
+// refreshView lists the results of the view called name in its note, creating
+// the note if needed. The note is only written when its list changed.
+func refreshView(deps Dependencies, ix *store.Index, name string, view config.ViewConfig) (path string, count int, changed bool, err error) {
+	notes, err := viewNotes(deps, ix, name, view)
+	if err != nil {
+		return "", 0, false, err
+	}
+	lines := make([]string, len(notes))
+	for i, n := range notes {
+		lines[i] = "- " + moc.Link(n)
+	}
+
+	path = viewNotePath(deps, view)
+	content := "# " + name + "\n"
+	if deps.FS.FileExists(path) {
+		data, err := deps.FS.ReadFile(path)
+		if err != nil {
+			return "", 0, false, fmt.Errorf("failed to read note: %w", err)
+		}
+		content = string(data)
+	}
+	updated := note.ReplaceGenerated(content, viewBlock, strings.Join(lines, "\n"))
+	if updated == content {
+		return path, len(notes), false, nil
+	}
+	if err := deps.FS.EnsureDirectoryExists(path); err != nil {
+		return "", 0, false, err
+	}
+	if err := deps.FS.WriteFile(path, []byte(updated)); err != nil {
+		return "", 0, false, fmt.Errorf("failed to write note: %w", err)
+	}
+	return path, len(notes), true, nil
+}
+
+// refreshViews refreshes the note of every view that has one, reporting each
+// note written to w.
+func refreshViews(deps Dependencies, ix *store.Index, w io.Writer) error {
+	for _, name := range deps.Config.ViewNames() {
+		view := deps.Config.Views[name]
+		if view.Note == "" {
+			continue
+		}
+		path, count, changed, err := refreshView(deps, ix, name, view)
+		if err != nil {
+			return fmt.Errorf("view %q: %w", name, err)
+		}
+		if changed {
+			fmt.Fprintf(w, "%s (%d notes)\n", path, count)
+		}
+	}
+	return nil
+}
+
+// completeViews completes the names of the configured views.
+func completeViews(deps Dependencies) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return filterPrefix(deps.Config.ViewNames(), toComplete), cobra.ShellCompDirectiveNoFileComp
+	}
+}
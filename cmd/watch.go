@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/plugin"
+	"github.com/a-kostevski/exo/pkg/store"
+	"github.com/a-kostevski/exo/pkg/watch"
+)
+
+// NewWatchCmd returns a new cobra.Command for the "watch" command, which keeps
+// the note index up to date and runs hooks as notes change.
+func NewWatchCmd(deps Dependencies) *cobra.Command {
+	var (
+		delay   time.Duration
+		noHooks bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Update the note index and run hooks as notes change",
+		Long: `Watch data_home for notes created, modified or removed outside of exo, for
+example in Obsidian or vim, update the note index as they change and run the
+hooks configured in watch.hooks:
+
+  watch:
+    hooks:
+      - echo "$EXO_EVENT $EXO_NOTE" >> ~/exo-changes.log
+
+Hooks are shell commands run once per changed note, one after the other, with
+the note path in EXO_NOTE, the change (created, modified or removed) in
+EXO_EVENT and the vault locations in EXO_DATA_HOME, EXO_TEMPLATE_DIR and
+EXO_CONFIG. Changes are collected until none happened for --delay.
+
+The notes of saved views (see "exo view") are refreshed after every change.`,
+		Example: examples(
+			ex("exo watch", "Keep the index up to date until interrupted"),
+			ex(`exo config set watch.hooks 'notify-send "exo" "$EXO_NOTE $EXO_EVENT"'`, "Show a desktop notification for each change"),
+		),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ix, err := openIndex(deps)
+			if err != nil {
+				return err
+			}
+			defer ix.Close()
+			if _, err := ix.Sync(deps.Config.Dir.TemplateDir); err != nil {
+				return err
+			}
+
+			w, err := watch.New(deps.Config.Dir.DataHome, delay, deps.Config.Dir.TemplateDir)
+			if err != nil {
+				return err
+			}
+			defer w.Close()
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+			fmt.Fprintf(errOut, "Watching %s (press Ctrl+C to stop)\n", deps.Config.Dir.DataHome)
+			return w.Run(ctx, func(events []watch.Event) {
+				handleChanges(deps, ix, events, !noHooks, out, errOut)
+			})
+		},
+	}
+
+	cmd.Flags().DurationVar(&delay, "delay", 300*time.Millisecond, "Wait this long after the last change before handling changes")
+	cmd.Flags().BoolVar(&noHooks, "no-hooks", false, "Only update the index, without running watch.hooks")
+	return cmd
+}
+
+// handleChanges updates the index with events, runs the hooks for each changed
+// note and refreshes the notes of views. Failures are reported without
+// stopping the watch.
+func handleChanges(deps Dependencies, ix *store.Index, events []watch.Event, hooks bool, out, errOut io.Writer) {
+	paths := make([]string, len(events))
+	for i, ev := range events {
+		paths[i] = ev.Path
+	}
+	if _, err := ix.Update(paths...); err != nil {
+		fmt.Fprintf(errOut, "Failed to update the index: %v\n", err)
+	}
+
+	for _, ev := range events {
+		rel, err := filepath.Rel(deps.Config.Dir.DataHome, ev.Path)
+		if err != nil {
+			rel = ev.Path
+		}
+		if ev.Dir {
+			rel += string(filepath.Separator)
+		}
+		fmt.Fprintf(out, "%-9s %s\n", ev.Op, rel)
+		if ev.Dir || !hooks {
+			continue
+		}
+		for _, hook := range deps.Config.Watch.Hooks {
+			if err := runHook(deps, hook, ev, out, errOut); err != nil {
+				fmt.Fprintf(errOut, "Hook %q failed for %s: %v\n", hook, rel, err)
+			}
+		}
+	}
+	if err := refreshViews(deps, ix, out); err != nil {
+		fmt.Fprintf(errOut, "Failed to refresh views: %v\n", err)
+	}
+}
+
+// runHook runs the shell command hook for the change ev, with cmd on Windows.
+func runHook(deps Dependencies, hook string, ev watch.Event, out, errOut io.Writer) error {
+	args := fs.OSEnv().ShellCommand(hook)
+	c := exec.Command(args[0], args[1:]...)
+	c.Env = append(plugin.Env(deps.Config.Dir.DataHome, deps.Config.Dir.TemplateDir, deps.Config.Source()),
+		"EXO_NOTE="+ev.Path,
+		"EXO_EVENT="+ev.Op.String(),
+	)
+	c.Dir = deps.Config.Dir.DataHome
+	c.Stdout, c.Stderr = out, errOut
+	return c.Run()
+}
@@ -1,33 +1,537 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/focus"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/tui"
 	"github.com/a-kostevski/exo/pkg/zettel"
 )
 
 // NewZetCmd returns a new cobra.Command for the "zet" command.
 func NewZetCmd(deps Dependencies) *cobra.Command {
+	var capture captureOptions
+
 	cmd := &cobra.Command{
 		Use:   "zet [title]",
 		Short: "Create a new Zettel note",
-		Args:  cobra.ExactArgs(1),
+		Example: examples(
+			ex(`exo zet "Channels are pipes"`, "Create a zettel in the inbox and open it"),
+			ex(`go test ./... 2>&1 | exo zet --stdin --title "Test run"`, "Capture the output of a command as a zettel"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runZetNew(cmd, deps, args, capture)
+		},
+	}
+	capture.addFlags(cmd, "Read the body of the zettel from standard input and print its path instead of opening it")
+	cmd.AddCommand(NewZetNewCmd(deps))
+	cmd.AddCommand(NewZetListCmd(deps))
+	cmd.AddCommand(NewZetForkCmd(deps))
+	cmd.AddCommand(NewZetOpenCmd(deps))
+	return cmd
+}
+
+// NewZetNewCmd returns the "zet new" command, which creates a zettel like "zet"
+// does.
+func NewZetNewCmd(deps Dependencies) *cobra.Command {
+	var capture captureOptions
+
+	cmd := &cobra.Command{
+		Use:   "new [title]",
+		Short: "Create a new Zettel note",
+		Long: `Create a zettel in the inbox and open it in the editor.
+
+With --stdin, the body of the zettel is read from standard input, and its path
+is printed instead of opening it. The title is taken from the argument or
+--title, or else from the first line of the input.`,
+		Example: examples(
+			ex(`exo zet new "Channels are pipes"`, "Create a zettel in the inbox and open it"),
+			ex(`pbpaste | exo zet new --stdin`, "Capture the clipboard, titled by its first line"),
+		),
+		Annotations: mutates(),
+		Args:        cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runZetNew(cmd, deps, args, capture)
+		},
+	}
+	capture.addFlags(cmd, "Read the body of the zettel from standard input and print its path instead of opening it")
+	return cmd
+}
+
+// captureOptions holds the flags of commands creating notes from standard input.
+type captureOptions struct {
+	stdin bool
+	title string
+}
+
+// addFlags adds the --stdin and --title flags to cmd.
+func (o *captureOptions) addFlags(cmd *cobra.Command, stdinUsage string) {
+	cmd.Flags().BoolVar(&o.stdin, "stdin", false, stdinUsage)
+	cmd.Flags().StringVarP(&o.title, "title", "t", "", "Title of the note (default: the first line of the input)")
+}
+
+// runZetNew creates a zettel titled by args or --title and opens it, or with
+// --stdin, captures standard input into it and prints its path.
+func runZetNew(cmd *cobra.Command, deps Dependencies, args []string, capture captureOptions) error {
+	title := capture.title
+	if len(args) > 0 {
+		if title != "" {
+			return exoerrors.New(exoerrors.Usage, "give the title as an argument or with --title, not both")
+		}
+		title = args[0]
+	}
+	if capture.stdin {
+		text, err := io.ReadAll(cmd.InOrStdin())
+		if err != nil {
+			return fmt.Errorf("failed to read standard input: %w", err)
+		}
+		zNote, err := captureZettel(cmd.Context(), deps, title, string(text))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), zNote.Path())
+		return nil
+	}
+	if title == "" {
+		return exoerrors.New(exoerrors.Usage, "a title is required (or pipe the note in with --stdin)")
+	}
+
+	content, err := focusContent("")
+	if err != nil {
+		return err
+	}
+	zNote, err := zettel.NewZettelNote(title, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS,
+		note.WithContext(cmd.Context()), note.WithContent(content))
+	if err != nil {
+		return fmt.Errorf("failed to create zettel note: %w", err)
+	}
+	if zNote.Exists() {
+		return exoerrors.New(exoerrors.Conflict, "note already exists: %s", zNote.Path())
+	}
+	if err := zNote.Save(); err != nil {
+		return fmt.Errorf("failed to save zettel note: %w", err)
+	}
+	if err := zNote.Open(); err != nil {
+		return fmt.Errorf("failed to open zettel note: %w", err)
+	}
+	return nil
+}
+
+// captureZettel creates and saves a zettel holding the captured text, titled
+// title or else by the first line of text. An existing note is not replaced.
+func captureZettel(ctx context.Context, deps Dependencies, title, text string, opts ...note.NoteOption) (note.Note, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, exoerrors.New(exoerrors.Validation, "nothing to capture: the input is empty")
+	}
+	if title == "" {
+		title, text = zettel.CaptureTitle(text)
+	}
+	content, err := focusContent(zettel.CaptureContent(title, text))
+	if err != nil {
+		return nil, err
+	}
+	zNote, err := zettel.NewZettelNote(safeFileName(title), *deps.Config, deps.TemplateManager, deps.Logger, deps.FS,
+		append(opts, note.WithContext(ctx), note.WithContent(content))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zettel note: %w", err)
+	}
+	if zNote.Exists() {
+		return nil, exoerrors.New(exoerrors.Conflict, "note already exists: %s", zNote.Path())
+	}
+	if err := zNote.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save zettel note: %w", err)
+	}
+	return zNote, nil
+}
+
+// NewZetForkCmd returns the "zet fork" command, which creates a new zettel from an
+// excerpt of an existing note, linking back to it.
+func NewZetForkCmd(deps Dependencies) *cobra.Command {
+	var (
+		lines   string
+		heading string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "fork <note> [title]",
+		Short: "Create a zettel quoting an excerpt of an existing note",
+		Example: examples(
+			ex(`exo zet fork "Big note" --heading Channels`, "Split a section out of a note"),
+			ex(`exo zet fork "Big note" "Narrow idea" --lines 10-20`, "Split a range of lines into a titled zettel"),
+		),
+		Long: `Create a new zettel pre-populated with a quoted excerpt from an existing note
+and a link back to it, to split one idea out of a larger note.
+
+Select the excerpt with --lines (e.g. 10-20, or 12 for a single line) or --heading;
+without either, the whole note body is quoted. The title defaults to the selected
+heading, or to the source note's title.`,
+		Annotations:       mutates(),
+		Args:              cobra.RangeArgs(1, 2),
+		ValidArgsFunction: completeNoteNames(deps),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			title := args[0]
-			zNote, err := zettel.NewZettelNote(title, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS)
+			if lines != "" && heading != "" {
+				return fmt.Errorf("--lines and --heading cannot be used together")
+			}
+			path, err := resolveNotePath(deps, args[0])
+			if err != nil {
+				return err
+			}
+			source, err := scan.ReadNote(path)
+			if err != nil {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read note: %w", err)
+			}
+
+			var excerpt, title string
+			switch {
+			case lines != "":
+				first, last, err := parseLineRange(lines)
+				if err != nil {
+					return err
+				}
+				if excerpt, err = note.LineRange(string(content), first, last); err != nil {
+					return err
+				}
+			case heading != "":
+				if excerpt, err = md.Extract(string(content), heading); err != nil {
+					return err
+				}
+				title = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(heading), "#"))
+			default:
+				excerpt = noteBody(string(content))
+			}
+			if len(args) > 1 {
+				title = args[1]
+			}
+			if title == "" {
+				title = source.Title
+			}
+
+			sourceName := strings.TrimSuffix(filepath.Base(path), scan.NoteExtension)
+			forked, err := focusContent(zettel.ForkContent(title, sourceName, excerpt))
+			if err != nil {
+				return err
+			}
+			zNote, err := zettel.NewZettelNote(title, *deps.Config, deps.TemplateManager, deps.Logger, deps.FS,
+				note.WithContext(cmd.Context()), note.WithContent(forked))
 			if err != nil {
 				return fmt.Errorf("failed to create zettel note: %w", err)
 			}
+			if zNote.Exists() {
+				return exoerrors.New(exoerrors.Conflict, "note already exists: %s", zNote.Path())
+			}
 			if err := zNote.Save(); err != nil {
 				return fmt.Errorf("failed to save zettel note: %w", err)
 			}
-			if err := zNote.Open(); err != nil {
-				return fmt.Errorf("failed to open zettel note: %w", err)
+			return zNote.Open()
+		},
+	}
+
+	cmd.Flags().StringVar(&lines, "lines", "", "Quote a line range of the source note, e.g. 10-20")
+	cmd.Flags().StringVar(&heading, "heading", "", "Quote the section under this heading of the source note")
+	return cmd
+}
+
+// NewZetOpenCmd returns the "zet open" command, which finds a zettel by title or
+// ID and opens it in the editor.
+func NewZetOpenCmd(deps Dependencies) *cobra.Command {
+	var (
+		printPath bool
+		frecency  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "open <title|id>",
+		Short: "Open a zettel by title or ID",
+		Example: examples(
+			ex(`exo zet open "Go channels"`, "Open a zettel by its exact title"),
+			ex("exo zet open gochan", "Open the zettel whose title best matches"),
+			ex(`vim "$(exo zet open --print-path 20250208143005)"`, "Print the path of a zettel for another program"),
+		),
+		Long: `Open a zettel from the inbox or zettel directory in the editor.
+
+The zettel is looked up by exact file name, title, ID or alias first, and
+otherwise by fuzzy title: titles containing the letters of the query in order.
+When several zettels match, they are listed to choose from, or reported as an
+error when standard input is not a terminal. Pinned zettels (pinned: true in
+their frontmatter) are listed first, then with --frecency, the zettels used
+most often and most recently (see "exo recent").
+
+With --print-path, the path of the zettel is printed instead, for shell
+pipelines and editor mappings.`,
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeNoteNames(deps),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.Join(args, " ")
+			notes, err := vaultNotesIn(deps, deps.Config.Dir.InboxDir, deps.Config.Dir.ZettelDir)
+			if err != nil {
+				return fmt.Errorf("failed to scan zettel notes: %w", err)
+			}
+			matches := scan.NewNames(deps.Config.Dir.DataHome, notes).Resolve(query)
+			if len(matches) == 0 {
+				matches = scan.Fuzzy(notes, query)
+			}
+			if frecency {
+				matches = frecencyOrder(deps, matches)
+			}
+			scan.PinnedFirst(matches)
+			var zettel scan.Note
+			switch {
+			case len(matches) == 0:
+				return exoerrors.New(exoerrors.NotFound, "no zettel matches %q", query)
+			case len(matches) == 1:
+				zettel = matches[0]
+			case tui.IsTerminal(os.Stdin):
+				if zettel, err = selectNote(cmd.InOrStdin(), cmd.ErrOrStderr(), matches); err != nil {
+					return err
+				}
+			default:
+				paths := make([]string, len(matches))
+				for i, n := range matches {
+					paths[i] = n.Path
+				}
+				return exoerrors.New(exoerrors.Conflict, "zettel %q is ambiguous: %s", query, strings.Join(paths, ", "))
+			}
+			if printPath {
+				fmt.Fprintln(cmd.OutOrStdout(), zettel.Path)
+				return nil
+			}
+			return deps.FS.OpenInEditor(zettel.Path, deps.Config.General.Editor)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&printPath, "print-path", "p", false, "Print the path of the zettel instead of opening it")
+	cmd.Flags().BoolVar(&frecency, "frecency", false, "List frequently and recently used zettels first")
+	return cmd
+}
+
+// selectNote lists notes on out, numbered from 1, and returns the one whose
+// number is read from in.
+func selectNote(in io.Reader, out io.Writer, notes []scan.Note) (scan.Note, error) {
+	for i, n := range notes {
+		fmt.Fprintf(out, "%3d) %s  (%s)\n", i+1, n.Title, filepath.Base(n.Path))
+	}
+	fmt.Fprintf(out, "Select a note [1-%d]: ", len(notes))
+	line, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && line == "" {
+		return scan.Note{}, fmt.Errorf("failed to read selection: %w", err)
+	}
+	choice, err := strconv.Atoi(strings.TrimSpace(line))
+	if err != nil || choice < 1 || choice > len(notes) {
+		return scan.Note{}, exoerrors.New(exoerrors.Validation, "invalid selection %q", strings.TrimSpace(line))
+	}
+	return notes[choice-1], nil
+}
+
+// focusContent adds the session focus, if any, to the frontmatter of a new note.
+func focusContent(content string) (string, error) {
+	f, err := focus.FromEnv()
+	if err != nil {
+		return "", err
+	}
+	return f.Annotate(content)
+}
+
+// parseLineRange parses a 1-based line range such as "10-20" or a single line "12".
+func parseLineRange(value string) (first, last int, err error) {
+	from, to, isRange := strings.Cut(value, "-")
+	if first, err = strconv.Atoi(strings.TrimSpace(from)); err != nil {
+		return 0, 0, fmt.Errorf("invalid --lines value %q (expected N or N-M)", value)
+	}
+	last = first
+	if isRange {
+		if last, err = strconv.Atoi(strings.TrimSpace(to)); err != nil {
+			return 0, 0, fmt.Errorf("invalid --lines value %q (expected N or N-M)", value)
+		}
+	}
+	return first, last, nil
+}
+
+// noteBody returns content without its frontmatter and leading title heading.
+func noteBody(content string) string {
+	_, body := frontmatter.Split(content)
+	body = strings.TrimLeft(body, "\n")
+	if strings.HasPrefix(body, "# ") {
+		_, body, _ = strings.Cut(body, "\n")
+	}
+	return strings.Trim(body, "\n")
+}
+
+// NewZetListCmd returns the "zet list" command, which lists zettel notes with
+// filtering and sorting based on their scanned metadata.
+func NewZetListCmd(deps Dependencies) *cobra.Command {
+	var (
+		tag           string
+		since         string
+		titleContains string
+		sortBy        string
+		format        string
+		all           bool
+		snippetLength int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list [query]...",
+		Short: "List zettel notes",
+		Example: examples(
+			ex("exo zet list --tag go --since 7d", "List recent zettels tagged #go"),
+			ex("exo zet list '#go OR #rust' channels", "List the zettels tagged #go or #rust about channels"),
+			ex("exo zet list --sort title --format paths", "Print the paths of all zettels by title"),
+		),
+		Long: `List zettel notes from the inbox and zettel directories.
+
+Notes can be filtered with a query, and sorted by created, modified or title.
+--tag, --since and --title-contains are short for the tag:, created:>= and title:
+terms; --since accepts a date (YYYY-MM-DD) or a relative age such as 7d or 12h.
+When the session is focused (see "exo focus"), only notes in the focused context are
+listed, including the notes of a focused project; use --all to list every note.
+The table shows a preview of each note, the first line of text below its headings,
+shortened to search.snippet_length characters (--snippet-length 0 hides it).
+
+` + queryHelp,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			q, err := parseQuery(strings.Join(args, " "),
+				fieldTerm(query.Tag, "", tag),
+				fieldTerm(query.Created, ">=", since),
+				fieldTerm(query.Title, "", titleContains))
+			if err != nil {
+				return err
+			}
+
+			f, err := focus.FromEnv()
+			if err != nil {
+				return err
+			}
+			if all {
+				f = focus.Focus{}
+			}
+			dirs := []string{deps.Config.Dir.InboxDir, deps.Config.Dir.ZettelDir}
+			if projectDir := f.ProjectDir(deps.Config.Dir.ProjectsDir); projectDir != "" {
+				dirs = append(dirs, projectDir)
 			}
-			return nil
+
+			found, err := selectNotes(deps, q)
+			if err != nil {
+				return err
+			}
+			var notes []scan.Note
+			for _, n := range found {
+				for _, dir := range dirs {
+					if isWithin(dir, n.Path) {
+						notes = append(notes, n)
+						break
+					}
+				}
+			}
+			notes = f.Filter(notes, deps.Config.Dir.ProjectsDir)
+			if err := scan.Sort(notes, scan.SortField(sortBy)); err != nil {
+				return err
+			}
+			if !cmd.Flags().Changed("snippet-length") {
+				snippetLength = deps.Config.Search.SnippetLength
+			}
+			return writeNotes(cmd.OutOrStdout(), notes, noteTypeDirs(deps), format, snippetLength)
 		},
 	}
+
+	flags := cmd.Flags()
+	flags.StringVarP(&tag, "tag", "t", "", "Only list notes with this tag")
+	flags.StringVar(&since, "since", "", "Only list notes created since a date (YYYY-MM-DD) or age (e.g. 7d)")
+	flags.StringVar(&titleContains, "title-contains", "", "Only list notes whose title or an alias contains this text")
+	flags.BoolVarP(&all, "all", "a", false, "Ignore the session focus")
+	flags.StringVarP(&sortBy, "sort", "s", string(scan.SortByModified), "Sort by created, modified or title")
+	flags.StringVarP(&format, "format", "f", "table", "Output format: table, json or paths")
+	flags.IntVar(&snippetLength, "snippet-length", 0, "Maximum length of note previews (default: search.snippet_length)")
+	_ = cmd.RegisterFlagCompletionFunc("tag", completeTags(deps))
+	_ = cmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions([]string{"created", "modified", "title"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"table", "json", "paths"}, cobra.ShellCompDirectiveNoFileComp))
 	return cmd
 }
+
+// writeNotes renders notes to w in the given format, labeled with their type
+// as told by note.Detect from types, the note directories and their types.
+// Tables include a preview of each note of up to snippetLength characters
+// unless it is zero.
+func writeNotes(w io.Writer, notes []scan.Note, types map[string]string, format string, snippetLength int) error {
+	switch format {
+	case "json":
+		type typedNote struct {
+			scan.Note
+			Type note.NoteType `json:"type,omitempty"`
+		}
+		typed := make([]typedNote, 0, len(notes))
+		for _, n := range notes {
+			typed = append(typed, typedNote{Note: n, Type: note.Detect(n.Path, n.Meta, types)})
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(typed)
+	case "paths":
+		for _, n := range notes {
+			fmt.Fprintln(w, n.Path)
+		}
+		return nil
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		if snippetLength > 0 {
+			fmt.Fprintln(tw, "CREATED\tMODIFIED\tTYPE\tTITLE\tTAGS\tPREVIEW")
+		} else {
+			fmt.Fprintln(tw, "CREATED\tMODIFIED\tTYPE\tTITLE\tTAGS")
+		}
+		for _, n := range notes {
+			typ := note.Detect(n.Path, n.Meta, types)
+			if typ == "" {
+				typ = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s",
+				n.Created.Format("2006-01-02"), n.Modified.Format("2006-01-02"), typ, n.Title, strings.Join(n.Tags, ","))
+			if snippetLength > 0 {
+				fmt.Fprintf(tw, "\t%s", scan.Snippet(n.Excerpt, snippetLength))
+			}
+			fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unknown format %q (expected table, json or paths)", format)
+	}
+}
+
+// parseSince parses a date (YYYY-MM-DD) or a relative age (e.g. 7d, 12h) into
+// an absolute time, relative ages being subtracted from now.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	if t, err := time.ParseInLocation(dailyDateLayout, value, time.Local); err == nil {
+		return t, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n >= 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value %q (expected YYYY-MM-DD or an age like 7d)", value)
+}
@@ -0,0 +1,59 @@
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/cmd"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZet_ExistingNoteIsKept(t *testing.T) {
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(t.TempDir())
+	t.Cleanup(cleanup)
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+
+	run := func() error {
+		c := cmd.NewZetCmd(deps)
+		c.SetOut(&bytes.Buffer{})
+		c.SetErr(&bytes.Buffer{})
+		c.SetArgs([]string{"Channels are pipes"})
+		return c.Execute()
+	}
+	require.NoError(t, run())
+	paths, err := filepath.Glob(filepath.Join(cfg.Dir.DataHome, "*", "*.md"))
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	require.NoError(t, os.WriteFile(paths[0], []byte("# Channels are pipes\n\nWritten since.\n"), 0644))
+
+	err = run()
+	assert.True(t, exoerrors.Is(err, exoerrors.Conflict), "got %v", err)
+	content, err := os.ReadFile(paths[0])
+	require.NoError(t, err)
+	assert.Equal(t, "# Channels are pipes\n\nWritten since.\n", string(content))
+}
+
+func TestZetOpen_Index(t *testing.T) {
+	cfg, tm, log, fsys, cleanup := testutil.NewDummyDeps(t.TempDir())
+	t.Cleanup(cleanup)
+	deps := cmd.Dependencies{Config: &cfg, TemplateManager: tm, Logger: log, FS: fsys}
+	path := filepath.Join(cfg.Dir.ZettelDir, "channels.md")
+	require.NoError(t, os.MkdirAll(cfg.Dir.ZettelDir, 0755))
+	require.NoError(t, os.WriteFile(path, []byte("# Go channels\n"), 0644))
+	require.NoError(t, os.MkdirAll(cfg.Dir.ProjectsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Dir.ProjectsDir, "go.md"), []byte("# Go channels plan\n"), 0644))
+
+	c := cmd.NewZetCmd(deps)
+	var out bytes.Buffer
+	c.SetOut(&out)
+	c.SetErr(&bytes.Buffer{})
+	c.SetArgs([]string{"open", "--print-path", "Go channels"})
+	require.NoError(t, c.Execute())
+	assert.Equal(t, path+"\n", out.String(), "only zettels of the inbox and zettel directory are opened")
+	assert.FileExists(t, cfg.Dir.CachePath("index.db"), "zettels are looked up in the note index")
+}
@@ -4,50 +4,88 @@ import (
 	"os"
 
 	"github.com/a-kostevski/exo/cmd"
-	"github.com/a-kostevski/exo/pkg/config"
-	"github.com/a-kostevski/exo/pkg/fs"
-	"github.com/a-kostevski/exo/pkg/logger"
-	"github.com/a-kostevski/exo/pkg/templates"
 )
 
 func main() {
-	// Initialize configuration.
-	cfg, err := config.NewConfig("")
-	if err != nil {
-		os.Exit(1)
-	}
+	startup := parseStartupOptions(os.Args[1:])
 
-	// Build remaining dependencies.
-	log := logger.NewLogger()
-	fsys := fs.NewOSFileSystem()
-	tm, err := templates.NewTemplateManager(templates.TemplateConfig{
-		TemplateDir:       cfg.Dir.TemplateDir,
-		TemplateExtension: ".md",
-		FilePermissions:   0644,
-		Logger:            log,
-		FS:                fsys,
+	// Build the dependencies container: the configuration is loaded now, the
+	// rest when first needed. Errors are reported by the commands needing
+	// what failed to load.
+	container := cmd.NewContainer(cmd.ContainerOptions{
+		ConfigPath: startup.configPath,
+		ReadOnly:   startup.readOnly,
+		Force:      startup.force,
+		OnConflict: startup.onConflict,
 	})
-	if err != nil {
-		os.Exit(1)
-	}
-
-	// Build the dependencies container.
-	deps := cmd.Dependencies{
-		Config:          cfg,
-		Logger:          log,
-		FS:              fsys,
-		TemplateManager: tm,
+	cfg, _ := container.Config()
+	if startup.debug {
+		dumpStartup(os.Stderr, cfg)
 	}
+	deps := container.Dependencies()
 
 	// Create the root command and add subcommands.
 	rootCmd := cmd.NewRootCmd(deps)
+	rootCmd.AddCommand(cmd.NewInitCmd(deps))
 	rootCmd.AddCommand(cmd.NewConfigCmd(deps))
 	rootCmd.AddCommand(cmd.NewZetCmd(deps))
 	rootCmd.AddCommand(cmd.NewDayCmd(deps))
 	rootCmd.AddCommand(cmd.NewTemplateCmd(deps))
+	rootCmd.AddCommand(cmd.NewLogCmd(deps))
+	rootCmd.AddCommand(cmd.NewURLCmd(deps))
+	rootCmd.AddCommand(cmd.NewMigrateCmd(deps))
+	rootCmd.AddCommand(cmd.NewStatsCmd(deps))
+	rootCmd.AddCommand(cmd.NewEnvCmd(deps))
+	rootCmd.AddCommand(cmd.NewSyncCmd(deps))
+	rootCmd.AddCommand(cmd.NewExportCmd(deps))
+	rootCmd.AddCommand(cmd.NewQuoteCmd(deps))
+	rootCmd.AddCommand(cmd.NewImportCmd(deps))
+	rootCmd.AddCommand(cmd.NewReportCmd(deps))
+	rootCmd.AddCommand(cmd.NewFocusCmd(deps))
+	rootCmd.AddCommand(cmd.NewClipCmd(deps))
+	rootCmd.AddCommand(cmd.NewPluginCmd(deps))
+	rootCmd.AddCommand(cmd.NewNoteCmd(deps))
+	rootCmd.AddCommand(cmd.NewServeCmd(deps))
+	rootCmd.AddCommand(cmd.NewTUICmd(deps))
+	rootCmd.AddCommand(cmd.NewReviewCmd(deps))
+	rootCmd.AddCommand(cmd.NewIndexCmd(deps))
+	rootCmd.AddCommand(cmd.NewWatchCmd(deps))
+	rootCmd.AddCommand(cmd.NewMeetingCmd(deps))
+	rootCmd.AddCommand(cmd.NewOpenCmd(deps))
+	rootCmd.AddCommand(cmd.NewDoctorCmd(deps))
+	rootCmd.AddCommand(cmd.NewCatCmd(deps))
+	rootCmd.AddCommand(cmd.NewShowCmd(deps))
+	rootCmd.AddCommand(cmd.NewSummarizeCmd(deps))
+	rootCmd.AddCommand(cmd.NewPromptsCmd(deps))
+	rootCmd.AddCommand(cmd.NewRecentCmd(deps))
+	rootCmd.AddCommand(cmd.NewBulkCmd(deps))
+	rootCmd.AddCommand(cmd.NewMvCmd(deps))
+	rootCmd.AddCommand(cmd.NewInboxCmd(deps))
+	rootCmd.AddCommand(cmd.NewHabitCmd(deps))
+	rootCmd.AddCommand(cmd.NewPomoCmd(deps))
+	rootCmd.AddCommand(cmd.NewCiteCmd(deps))
+	rootCmd.AddCommand(cmd.NewBookCmd(deps))
+	rootCmd.AddCommand(cmd.NewMOCCmd(deps))
+	rootCmd.AddCommand(cmd.NewViewCmd(deps))
+	rootCmd.AddCommand(cmd.NewSearchCmd(deps))
+	rootCmd.AddCommand(cmd.NewLintCmd(deps))
+	rootCmd.AddCommand(cmd.NewBackupCmd(deps))
+	rootCmd.AddCommand(cmd.NewVersionsCmd(deps))
+	rootCmd.AddCommand(cmd.NewDiffCmd(deps))
+	rootCmd.AddCommand(cmd.NewDaemonCmd(deps))
+	rootCmd.AddCommand(cmd.NewLSPCmd(deps))
+	rootCmd.AddCommand(cmd.NewCompleteCmd(deps))
+	rootCmd.AddCommand(cmd.NewSecretCmd(deps))
+	rootCmd.AddCommand(cmd.NewGrepCmd(deps))
+	rootCmd.AddCommand(cmd.NewRemindCmd(deps))
+	rootCmd.AddCommand(cmd.NewProjectCmd(deps))
+	rootCmd.AddCommand(cmd.NewAliasCmd(deps))
+	rootCmd.AddCommand(cmd.NewCompletionCmd())
+	cmd.AddPluginCommands(rootCmd, deps)
 	// (Add additional commands like day, zet, init, etc.)
 
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
-	}
+	// Expand user-defined aliases before cobra dispatches the command line.
+	rootCmd.SetArgs(cmd.ExpandAliases(rootCmd, cfg.Alias, os.Args[1:]))
+
+	os.Exit(cmd.Run(rootCmd))
 }
@@ -0,0 +1,360 @@
+// Package backup copies the data home to and from remote storage, such as an
+// S3 bucket or a WebDAV server. Files are stored once per content, by hash,
+// so that a backup only uploads the files changed since the previous one, and
+// may be encrypted before they leave the machine.
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// Environment variables holding the secrets of backups, which are not kept in
+// the configuration.
+const (
+	EnvPassphrase     = "EXO_BACKUP_PASSPHRASE"
+	EnvWebDAVPassword = "EXO_BACKUP_PASSWORD"
+)
+
+// Names of the files of a backup on its remote, besides the objects holding
+// the contents of files.
+const (
+	headerName   = "backup.json"
+	manifestName = "manifest.json"
+	objectsDir   = "objects/"
+)
+
+// ErrNotFound is returned by remotes for files they do not have.
+var ErrNotFound = errors.New("not found")
+
+// Remote stores the files of a backup, by name, such as "objects/<hash>".
+type Remote interface {
+	// Get returns the contents of the file called name, or an error wrapping
+	// ErrNotFound if there is none.
+	Get(ctx context.Context, name string) ([]byte, error)
+	// Put stores data as the file called name, replacing any existing one.
+	Put(ctx context.Context, name string, data []byte) error
+}
+
+// Factory creates a Remote from the configuration.
+type Factory func(cfg config.Config) (Remote, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		"s3":     newS3,
+		"webdav": newWebDAV,
+	}
+)
+
+// Register makes a remote available under name, replacing any existing one.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// Names returns the names of the registered remotes in sorted order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the remote registered under name.
+func New(name string, cfg config.Config) (Remote, error) {
+	if name == "" {
+		return nil, fmt.Errorf("no backup remote configured (set backup.remote to one of %s)", strings.Join(Names(), ", "))
+	}
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown backup remote %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// header describes a backup. It is stored in the clear, so that the key of an
+// encrypted backup can be derived from its passphrase.
+type header struct {
+	Version   int    `json:"version"`
+	Encrypted bool   `json:"encrypted"`
+	Salt      []byte `json:"salt,omitempty"`
+}
+
+// Manifest lists the files of a backup.
+type Manifest struct {
+	Created time.Time `json:"created"`
+	// Files maps the paths of files, relative to the data home and
+	// slash-separated, to their contents.
+	Files map[string]File `json:"files"`
+}
+
+// File is a file of a backup.
+type File struct {
+	// Hash is the SHA-256 of the contents of the file, in hex.
+	Hash string      `json:"hash"`
+	Size int64       `json:"size"`
+	Mode fs.FileMode `json:"mode"`
+}
+
+// Options configures a backup.
+type Options struct {
+	// Passphrase encrypts the backup when it is not empty. A backup is
+	// either encrypted or not from its first push on; pulling an unencrypted
+	// backup ignores it.
+	Passphrase string
+}
+
+// Result summarizes a push or a pull.
+type Result struct {
+	// Files is the number of files in the backup.
+	Files int
+	// Transferred is the number of files uploaded or downloaded, and Bytes
+	// their size.
+	Transferred int
+	Bytes       int64
+}
+
+// store reads and writes the files of a backup, encrypting them if the backup
+// is encrypted.
+type store struct {
+	remote Remote
+	cipher *cipher
+}
+
+// open returns the store of the backup on remote, or of a new one if create
+// is set and remote holds none.
+func open(ctx context.Context, remote Remote, opts Options, create bool) (*store, error) {
+	data, err := remote.Get(ctx, headerName)
+	if errors.Is(err, ErrNotFound) {
+		if !create {
+			return nil, fmt.Errorf("no backup found on the remote")
+		}
+		h := header{Version: 1, Encrypted: opts.Passphrase != ""}
+		if h.Encrypted {
+			if h.Salt, err = newSalt(); err != nil {
+				return nil, err
+			}
+		}
+		if data, err = json.Marshal(h); err != nil {
+			return nil, err
+		}
+		if err := remote.Put(ctx, headerName, data); err != nil {
+			return nil, fmt.Errorf("failed to write backup header: %w", err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read backup header: %w", err)
+	}
+
+	var h header
+	if err := json.Unmarshal(data, &h); err != nil {
+		return nil, fmt.Errorf("invalid backup header: %w", err)
+	}
+	s := &store{remote: remote}
+	switch {
+	case h.Encrypted && opts.Passphrase == "":
+		return nil, fmt.Errorf("the backup is encrypted: set %s to its passphrase", EnvPassphrase)
+	case !h.Encrypted && opts.Passphrase != "" && create:
+		return nil, fmt.Errorf("the backup is not encrypted: unset backup.encrypt or back up to a new location")
+	case h.Encrypted:
+		if s.cipher, err = newCipher(opts.Passphrase, h.Salt); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *store) get(ctx context.Context, name string) ([]byte, error) {
+	data, err := s.remote.Get(ctx, name)
+	if err != nil || s.cipher == nil {
+		return data, err
+	}
+	return s.cipher.open(data)
+}
+
+func (s *store) put(ctx context.Context, name string, data []byte) error {
+	if s.cipher != nil {
+		var err error
+		if data, err = s.cipher.seal(data); err != nil {
+			return err
+		}
+	}
+	return s.remote.Put(ctx, name, data)
+}
+
+// objectName returns the name of the object holding the contents whose
+// SHA-256 is hash. The objects of encrypted backups are named by a keyed hash,
+// which does not tell which contents they hold.
+func (s *store) objectName(hash string) string {
+	if s.cipher != nil {
+		return objectsDir + s.cipher.name(hash)
+	}
+	return objectsDir + hash
+}
+
+// manifest returns the manifest of the backup, empty if there is none yet.
+func (s *store) manifest(ctx context.Context) (*Manifest, error) {
+	data, err := s.get(ctx, manifestName)
+	if errors.Is(err, ErrNotFound) {
+		return &Manifest{Files: make(map[string]File)}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup manifest: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	if m.Files == nil {
+		m.Files = make(map[string]File)
+	}
+	return &m, nil
+}
+
+// Push backs dir, the data home, up to remote. Only the files whose contents
+// the backup does not hold yet are uploaded. Git metadata is left out.
+func Push(ctx context.Context, remote Remote, dir string, opts Options) (*Result, error) {
+	s, err := open(ctx, remote, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := s.manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(previous.Files))
+	for _, f := range previous.Files {
+		stored[f.Hash] = true
+	}
+
+	m := &Manifest{Created: time.Now().UTC(), Files: make(map[string]File)}
+	result := &Result{}
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		m.Files[filepath.ToSlash(rel)] = File{Hash: hash, Size: int64(len(data)), Mode: info.Mode().Perm()}
+		if stored[hash] {
+			return nil
+		}
+		if err := s.put(ctx, s.objectName(hash), data); err != nil {
+			return fmt.Errorf("failed to upload %s: %w", rel, err)
+		}
+		stored[hash] = true
+		result.Transferred++
+		result.Bytes += int64(len(data))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.put(ctx, manifestName, data); err != nil {
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	result.Files = len(m.Files)
+	return result, nil
+}
+
+// Pull restores the backup on remote into dir, the data home. Only the files
+// missing from dir or whose contents differ from the backup are downloaded;
+// files not in the backup are left alone.
+func Pull(ctx context.Context, remote Remote, dir string, opts Options) (*Result, error) {
+	s, err := open(ctx, remote, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	m, err := s.manifest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(m.Files))
+	for p := range m.Files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	result := &Result{Files: len(paths)}
+	for _, p := range paths {
+		f := m.Files[p]
+		dest := filepath.Join(dir, filepath.FromSlash(p))
+		if rel, err := filepath.Rel(dir, dest); err != nil || strings.HasPrefix(rel, "..") {
+			return nil, fmt.Errorf("invalid path in backup: %s", p)
+		}
+		if data, err := os.ReadFile(dest); err == nil {
+			if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) == f.Hash {
+				continue
+			}
+		}
+		data, err := s.get(ctx, s.objectName(f.Hash))
+		if err != nil {
+			return nil, fmt.Errorf("failed to download %s: %w", p, err)
+		}
+		if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != f.Hash {
+			return nil, fmt.Errorf("failed to download %s: contents do not match the backup", p)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		mode := f.Mode
+		if mode == 0 {
+			mode = 0644
+		}
+		if err := os.WriteFile(dest, data, mode); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", p, err)
+		}
+		result.Transferred++
+		result.Bytes += int64(len(data))
+	}
+	return result, nil
+}
@@ -0,0 +1,147 @@
+package backup_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/backup"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryRemote is a Remote keeping files in memory.
+type memoryRemote struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	puts  []string
+}
+
+func newMemoryRemote() *memoryRemote {
+	return &memoryRemote{files: make(map[string][]byte)}
+}
+
+func (r *memoryRemote) Get(ctx context.Context, name string) ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.files[name]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", name, backup.ErrNotFound)
+	}
+	return data, nil
+}
+
+func (r *memoryRemote) Put(ctx context.Context, name string, data []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.files[name] = data
+	r.puts = append(r.puts, name)
+	return nil
+}
+
+// objects returns the names of the objects put since the last call.
+func (r *memoryRemote) objects() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var objects []string
+	for _, name := range r.puts {
+		if strings.HasPrefix(name, "objects/") {
+			objects = append(objects, name)
+		}
+	}
+	r.puts = nil
+	return objects
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestPushPull(t *testing.T) {
+	ctx := context.Background()
+	remote := newMemoryRemote()
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "zettel", "a.md"), "# A\n")
+	writeFile(t, filepath.Join(home, "zettel", "b.md"), "# B\n")
+	writeFile(t, filepath.Join(home, "copy.md"), "# A\n")
+	writeFile(t, filepath.Join(home, ".git", "HEAD"), "ref: refs/heads/main\n")
+
+	result, err := backup.Push(ctx, remote, home, backup.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, &backup.Result{Files: 3, Transferred: 2, Bytes: 8}, result)
+	assert.Len(t, remote.objects(), 2)
+
+	// Only changed contents are uploaded again.
+	writeFile(t, filepath.Join(home, "zettel", "b.md"), "# B changed\n")
+	result, err = backup.Push(ctx, remote, home, backup.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Transferred)
+	assert.Len(t, remote.objects(), 1)
+
+	restored := t.TempDir()
+	writeFile(t, filepath.Join(restored, "zettel", "a.md"), "# A\n")
+	writeFile(t, filepath.Join(restored, "local.md"), "kept")
+	result, err = backup.Pull(ctx, remote, restored, backup.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Files)
+	assert.Equal(t, 2, result.Transferred)
+	assert.Equal(t, "# B changed\n", readFile(t, filepath.Join(restored, "zettel", "b.md")))
+	assert.Equal(t, "# A\n", readFile(t, filepath.Join(restored, "copy.md")))
+	assert.Equal(t, "kept", readFile(t, filepath.Join(restored, "local.md")))
+	assert.NoFileExists(t, filepath.Join(restored, ".git", "HEAD"))
+}
+
+func TestPull_NoBackup(t *testing.T) {
+	_, err := backup.Pull(context.Background(), newMemoryRemote(), t.TempDir(), backup.Options{})
+	assert.ErrorContains(t, err, "no backup found")
+}
+
+func TestPushPull_Encrypted(t *testing.T) {
+	ctx := context.Background()
+	remote := newMemoryRemote()
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "secret.md"), "the secret note")
+
+	opts := backup.Options{Passphrase: "correct horse"}
+	_, err := backup.Push(ctx, remote, home, opts)
+	require.NoError(t, err)
+	for name, data := range remote.files {
+		assert.False(t, bytes.Contains(data, []byte("secret")), "%s is stored in the clear", name)
+	}
+
+	restored := t.TempDir()
+	_, err = backup.Pull(ctx, remote, restored, opts)
+	require.NoError(t, err)
+	assert.Equal(t, "the secret note", readFile(t, filepath.Join(restored, "secret.md")))
+
+	_, err = backup.Pull(ctx, remote, t.TempDir(), backup.Options{Passphrase: "wrong"})
+	assert.ErrorContains(t, err, "wrong passphrase")
+	_, err = backup.Pull(ctx, remote, t.TempDir(), backup.Options{})
+	assert.ErrorContains(t, err, backup.EnvPassphrase)
+}
+
+func TestPush_EncryptionMismatch(t *testing.T) {
+	ctx := context.Background()
+	remote := newMemoryRemote()
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "a.md"), "a")
+	_, err := backup.Push(ctx, remote, home, backup.Options{})
+	require.NoError(t, err)
+
+	_, err = backup.Push(ctx, remote, home, backup.Options{Passphrase: "late"})
+	assert.ErrorContains(t, err, "not encrypted")
+}
@@ -0,0 +1,76 @@
+package backup
+
+import (
+	"crypto/aes"
+	gocipher "crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/a-kostevski/exo/pkg/secret"
+)
+
+// errDecrypt is returned for files that cannot be decrypted.
+var errDecrypt = errors.New("cannot decrypt backup: wrong passphrase or corrupted data")
+
+// cipher encrypts the files of a backup with AES-256-GCM.
+type cipher struct {
+	aead gocipher.AEAD
+	// nameKey keys the hashes objects are named by.
+	nameKey []byte
+}
+
+func newSalt() ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return salt, nil
+}
+
+// newCipher derives the keys of a backup from passphrase and salt.
+func newCipher(passphrase string, salt []byte) (*cipher, error) {
+	key := secret.DeriveKey(passphrase, salt, 64)
+	block, err := aes.NewCipher(key[:32])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := gocipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &cipher{aead: aead, nameKey: key[32:]}, nil
+}
+
+// seal encrypts data, returning the nonce followed by the ciphertext.
+func (c *cipher) seal(data []byte) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return c.aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts data sealed by seal.
+func (c *cipher) open(data []byte) ([]byte, error) {
+	n := c.aead.NonceSize()
+	if len(data) < n {
+		return nil, errDecrypt
+	}
+	plain, err := c.aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return nil, errDecrypt
+	}
+	return plain, nil
+}
+
+// name returns the name of the object holding the contents whose SHA-256 is
+// hash.
+func (c *cipher) name(hash string) string {
+	mac := hmac.New(sha256.New, c.nameKey)
+	mac.Write([]byte(hash))
+	return hex.EncodeToString(mac.Sum(nil))
+}
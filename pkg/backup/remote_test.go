@@ -0,0 +1,137 @@
+package backup_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/backup"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fileServer serves files kept in memory to GET and PUT requests, and records
+// the other methods it is sent.
+type fileServer struct {
+	mu      sync.Mutex
+	files   map[string][]byte
+	methods []string
+	check   func(r *http.Request, body []byte)
+}
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	if s.check != nil {
+		s.check(r, body)
+	}
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := s.files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		s.files[r.URL.Path] = body
+	default:
+		s.methods = append(s.methods, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func TestS3(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+	server := &fileServer{files: make(map[string][]byte)}
+	server.check = func(r *http.Request, body []byte) {
+		sum := sha256.Sum256(body)
+		assert.Equal(t, hex.EncodeToString(sum[:]), r.Header.Get("x-amz-content-sha256"))
+		assert.Regexp(t, `^AWS4-HMAC-SHA256 Credential=AKID/\d{8}/eu-west-1/s3/aws4_request, `+
+			`SignedHeaders=host;x-amz-content-sha256;x-amz-date, Signature=[0-9a-f]{64}$`, r.Header.Get("Authorization"))
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	var cfg config.Config
+	cfg.Backup.S3 = config.S3Config{Endpoint: ts.URL, Region: "eu-west-1", Bucket: "notes", Prefix: "exo/"}
+	remote, err := backup.New("s3", cfg)
+	require.NoError(t, err)
+
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "a.md"), "# A\n")
+	_, err = backup.Push(context.Background(), remote, home, backup.Options{})
+	require.NoError(t, err)
+	assert.Contains(t, server.files, "/notes/exo/backup.json")
+	assert.Contains(t, server.files, "/notes/exo/manifest.json")
+
+	restored := t.TempDir()
+	_, err = backup.Pull(context.Background(), remote, restored, backup.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "# A\n", readFile(t, filepath.Join(restored, "a.md")))
+}
+
+func TestS3_MissingSettings(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	var cfg config.Config
+	_, err := backup.New("s3", cfg)
+	assert.ErrorContains(t, err, "backup.s3.bucket")
+	cfg.Backup.S3.Bucket = "notes"
+	_, err = backup.New("s3", cfg)
+	assert.ErrorContains(t, err, "AWS_ACCESS_KEY_ID")
+}
+
+func TestWebDAV(t *testing.T) {
+	t.Setenv(backup.EnvWebDAVPassword, "hunter2")
+	server := &fileServer{files: make(map[string][]byte)}
+	server.check = func(r *http.Request, body []byte) {
+		user, password, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "me", user)
+		assert.Equal(t, "hunter2", password)
+	}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	var cfg config.Config
+	cfg.Backup.WebDAV = config.WebDAVConfig{URL: ts.URL + "/dav/exo", Username: "me"}
+	remote, err := backup.New("webdav", cfg)
+	require.NoError(t, err)
+
+	home := t.TempDir()
+	writeFile(t, filepath.Join(home, "a.md"), "# A\n")
+	writeFile(t, filepath.Join(home, "b.md"), "# B\n")
+	_, err = backup.Push(context.Background(), remote, home, backup.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"MKCOL /dav/exo/objects/"}, server.methods)
+	var objects int
+	for path := range server.files {
+		if strings.HasPrefix(path, "/dav/exo/objects/") {
+			objects++
+		}
+	}
+	assert.Equal(t, 2, objects)
+
+	restored := t.TempDir()
+	_, err = backup.Pull(context.Background(), remote, restored, backup.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, "# B\n", readFile(t, filepath.Join(restored, "b.md")))
+}
+
+func TestNew_Unknown(t *testing.T) {
+	_, err := backup.New("ftp", config.Config{})
+	assert.ErrorContains(t, err, "unknown backup remote")
+	_, err = backup.New("", config.Config{})
+	assert.ErrorContains(t, err, "backup.remote")
+}
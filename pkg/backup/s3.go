@@ -0,0 +1,185 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// s3Remote stores backups in a bucket of an S3-compatible service, addressed
+// path-style, with requests signed with AWS Signature Version 4.
+type s3Remote struct {
+	endpoint *url.URL
+	region   string
+	bucket   string
+	prefix   string
+	keyID    string
+	secret   string
+	token    string
+	client   *http.Client
+}
+
+func newS3(cfg config.Config) (Remote, error) {
+	c := cfg.Backup.S3
+	if c.Bucket == "" {
+		return nil, fmt.Errorf("backup.s3.bucket is not set")
+	}
+	r := &s3Remote{
+		region: c.Region,
+		bucket: c.Bucket,
+		prefix: strings.TrimPrefix(c.Prefix, "/"),
+		keyID:  os.Getenv("AWS_ACCESS_KEY_ID"),
+		secret: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		token:  os.Getenv("AWS_SESSION_TOKEN"),
+		client: &http.Client{},
+	}
+	if r.keyID == "" || r.secret == "" {
+		return nil, fmt.Errorf("set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY to the credentials of the bucket")
+	}
+	if r.region == "" {
+		r.region = "us-east-1"
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + r.region + ".amazonaws.com"
+	}
+	u, err := url.Parse(strings.TrimSuffix(endpoint, "/"))
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid backup.s3.endpoint %q", endpoint)
+	}
+	r.endpoint = u
+	return r, nil
+}
+
+// Get implements Remote.
+func (r *s3Remote) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := r.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to read %s: %s", name, s3Error(resp, data))
+	}
+	return data, nil
+}
+
+// Put implements Remote.
+func (r *s3Remote) Put(ctx context.Context, name string, data []byte) error {
+	resp, err := r.do(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to write %s: %s", name, s3Error(resp, body))
+	}
+	return nil
+}
+
+// s3Error returns the status of a failed request, with the code of the error
+// S3 reports in its body, if any.
+func s3Error(resp *http.Response, body []byte) string {
+	if _, rest, ok := strings.Cut(string(body), "<Code>"); ok {
+		if code, _, ok := strings.Cut(rest, "</Code>"); ok {
+			return resp.Status + " (" + code + ")"
+		}
+	}
+	return resp.Status
+}
+
+// do sends a signed request for the object called name.
+func (r *s3Remote) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	key := "/" + r.bucket + "/" + r.prefix + name
+	u := *r.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + key
+	u.RawPath = uriEncode(u.Path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 request: %w", err)
+	}
+	req.ContentLength = int64(len(body))
+	r.sign(req, body, time.Now().UTC())
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// sign adds the headers of AWS Signature Version 4 to req.
+func (r *s3Remote) sign(req *http.Request, body []byte, now time.Time) {
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	req.Header.Set("x-amz-date", amzDate)
+	headers := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	values := map[string]string{"host": req.URL.Host, "x-amz-content-sha256": payloadHash, "x-amz-date": amzDate}
+	if r.token != "" {
+		req.Header.Set("x-amz-security-token", r.token)
+		headers = append(headers, "x-amz-security-token")
+		values["x-amz-security-token"] = r.token
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range headers {
+		canonicalHeaders.WriteString(h + ":" + strings.TrimSpace(values[h]) + "\n")
+	}
+	signedHeaders := strings.Join(headers, ";")
+	canonicalRequest := strings.Join([]string{
+		req.Method, req.URL.EscapedPath(), req.URL.RawQuery, canonicalHeaders.String(), signedHeaders, payloadHash,
+	}, "\n")
+	scope := date + "/" + r.region + "/s3/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+r.secret), date)
+	for _, part := range []string{r.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		r.keyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// uriEncode percent-encodes path as S3 expects in canonical requests: every
+// byte but the unreserved characters and slashes.
+func uriEncode(path string) string {
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,298 @@
+package backup
+
+import (
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// SnapshotLayout is the layout of the names of snapshots: the local time they
+// were taken at.
+const SnapshotLayout = "2006-01-02T150405"
+
+// Snapshot is a local copy of the data home, taken at Time.
+type Snapshot struct {
+	Name string
+	Time time.Time
+	// Path is the directory holding the copy.
+	Path string
+}
+
+// SnapshotStats summarizes a snapshot: its files, and how many of them were
+// copied rather than hard-linked to the previous snapshot.
+type SnapshotStats struct {
+	Files  int
+	Copied int
+	Bytes  int64
+}
+
+// Snapshots returns the snapshots in dir, the oldest first.
+func Snapshots(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list snapshots: %w", err)
+	}
+	var snapshots []Snapshot
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		// Snapshots taken in the same second are numbered, e.g. "…T101500-2".
+		stamp := e.Name()[:min(len(e.Name()), len(SnapshotLayout))]
+		t, err := time.ParseInLocation(SnapshotLayout, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		snapshots = append(snapshots, Snapshot{Name: e.Name(), Time: t, Path: filepath.Join(dir, e.Name())})
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Name < snapshots[j].Name })
+	return snapshots, nil
+}
+
+// FindSnapshot returns the snapshot in dir called name, or the newest one if
+// name is "latest".
+func FindSnapshot(dir, name string) (*Snapshot, error) {
+	snapshots, err := Snapshots(dir)
+	if err != nil {
+		return nil, err
+	}
+	if name == "latest" && len(snapshots) > 0 {
+		return &snapshots[len(snapshots)-1], nil
+	}
+	for i := range snapshots {
+		if snapshots[i].Name == name {
+			return &snapshots[i], nil
+		}
+	}
+	return nil, fmt.Errorf("snapshot %s: %w", name, ErrNotFound)
+}
+
+// TakeSnapshot copies home, the data home, to a new snapshot in dir named
+// after now. Files unchanged since the previous snapshot, of the same size,
+// modification time and mode, are hard links to their copy in it, so that
+// they take no space. Git metadata is left out.
+func TakeSnapshot(dir, home string, now time.Time) (*Snapshot, *SnapshotStats, error) {
+	snapshots, err := Snapshots(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	var previous string
+	if len(snapshots) > 0 {
+		previous = snapshots[len(snapshots)-1].Path
+	}
+
+	base := now.Format(SnapshotLayout)
+	name := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(filepath.Join(dir, name)); os.IsNotExist(err) {
+			break
+		}
+		name = base + "-" + strconv.Itoa(i)
+	}
+	dest := filepath.Join(dir, name)
+	// The snapshot is written under a temporary name, so that an interrupted
+	// one is not taken for a snapshot.
+	tmp := filepath.Join(dir, "."+name+".tmp")
+	if err := os.MkdirAll(tmp, 0700); err != nil {
+		return nil, nil, fmt.Errorf("failed to create snapshot: %w", err)
+	}
+
+	stats := &SnapshotStats{}
+	err = filepath.WalkDir(home, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(home, path)
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == ".git" || isWithinDir(dir, path) {
+				return filepath.SkipDir
+			}
+			return os.MkdirAll(filepath.Join(tmp, rel), 0755)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		stats.Files++
+		target := filepath.Join(tmp, rel)
+		if previous != "" {
+			if old, err := os.Stat(filepath.Join(previous, rel)); err == nil && sameFile(info, old) {
+				if err := os.Link(filepath.Join(previous, rel), target); err == nil {
+					return nil
+				}
+			}
+		}
+		if err := copyFile(path, target, info); err != nil {
+			return err
+		}
+		stats.Copied++
+		stats.Bytes += info.Size()
+		return nil
+	})
+	if err == nil {
+		err = os.Rename(tmp, dest)
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return nil, nil, fmt.Errorf("failed to take snapshot: %w", err)
+	}
+	return &Snapshot{Name: name, Time: now, Path: dest}, stats, nil
+}
+
+// isWithinDir reports whether path is dir or is inside it.
+func isWithinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// sameFile reports whether a and b describe the same version of a file.
+func sameFile(a, b fs.FileInfo) bool {
+	return a.Size() == b.Size() && a.ModTime().Equal(b.ModTime()) && a.Mode() == b.Mode()
+}
+
+// copyFile copies src, described by info, to dest, keeping its mode and
+// modification time.
+func copyFile(src, dest string, info fs.FileInfo) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(dest, info.ModTime(), info.ModTime())
+}
+
+// Retain returns the snapshots kept by policy, a subset of snapshots, which
+// are ordered oldest first. All are kept when the policy keeps none.
+func Retain(snapshots []Snapshot, policy config.RetentionConfig) map[string]bool {
+	keep := make(map[string]bool)
+	if policy == (config.RetentionConfig{}) {
+		for _, s := range snapshots {
+			keep[s.Name] = true
+		}
+		return keep
+	}
+	buckets := []struct {
+		count int
+		key   func(time.Time) string
+	}{
+		{policy.Daily, func(t time.Time) string { return t.Format("2006-01-02") }},
+		{policy.Weekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{policy.Monthly, func(t time.Time) string { return t.Format("2006-01") }},
+		{policy.Yearly, func(t time.Time) string { return t.Format("2006") }},
+	}
+	for i := len(snapshots) - 1; i >= 0 && len(snapshots)-i <= policy.Last; i-- {
+		keep[snapshots[i].Name] = true
+	}
+	for _, b := range buckets {
+		seen := make(map[string]bool)
+		for i := len(snapshots) - 1; i >= 0 && len(seen) < b.count; i-- {
+			key := b.key(snapshots[i].Time)
+			if !seen[key] {
+				seen[key] = true
+				keep[snapshots[i].Name] = true
+			}
+		}
+	}
+	return keep
+}
+
+// Prune removes the snapshots in dir that policy does not keep, and returns
+// them.
+func Prune(dir string, policy config.RetentionConfig) ([]Snapshot, error) {
+	snapshots, err := Snapshots(dir)
+	if err != nil {
+		return nil, err
+	}
+	keep := Retain(snapshots, policy)
+	var removed []Snapshot
+	for _, s := range snapshots {
+		if keep[s.Name] {
+			continue
+		}
+		if err := os.RemoveAll(s.Path); err != nil {
+			return removed, fmt.Errorf("failed to remove snapshot %s: %w", s.Name, err)
+		}
+		removed = append(removed, s)
+	}
+	return removed, nil
+}
+
+// RestoreSnapshot copies the file or directory at path, relative to the data
+// home, from snapshot s into home, replacing the files there; an empty path
+// restores the whole snapshot. Files not in the snapshot are left alone. It
+// returns the number of files restored.
+func RestoreSnapshot(s *Snapshot, home, path string) (int, error) {
+	src := filepath.Join(s.Path, filepath.FromSlash(path))
+	if !isWithinDir(s.Path, src) {
+		return 0, fmt.Errorf("invalid path %s", path)
+	}
+	if _, err := os.Stat(src); err != nil {
+		return 0, fmt.Errorf("%s is not in snapshot %s: %w", path, s.Name, ErrNotFound)
+	}
+	count := 0
+	err := filepath.WalkDir(src, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(s.Path, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(home, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		// The file is replaced rather than written through, as it may be a
+		// hard link shared with snapshots.
+		if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := copyFile(p, target, info); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,161 @@
+package backup_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/backup"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTakeSnapshot(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(home, ".snapshots")
+	writeFile(t, filepath.Join(home, "a.md"), "# A\n")
+	writeFile(t, filepath.Join(home, "sub", "b.md"), "# B\n")
+	writeFile(t, filepath.Join(home, ".git", "HEAD"), "ref: refs/heads/main\n")
+
+	now := time.Date(2024, 3, 15, 10, 15, 0, 0, time.Local)
+	first, stats, err := backup.TakeSnapshot(dir, home, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-15T101500", first.Name)
+	assert.Equal(t, &backup.SnapshotStats{Files: 2, Copied: 2, Bytes: 8}, stats)
+	assert.Equal(t, "# B\n", readFile(t, filepath.Join(first.Path, "sub", "b.md")))
+	assert.NoDirExists(t, filepath.Join(first.Path, ".git"))
+	assert.NoDirExists(t, filepath.Join(first.Path, ".snapshots"))
+
+	writeFile(t, filepath.Join(home, "a.md"), "# A, edited\n")
+	second, stats, err := backup.TakeSnapshot(dir, home, now)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-15T101500-2", second.Name)
+	assert.Equal(t, 2, stats.Files)
+	assert.Equal(t, 1, stats.Copied)
+	assert.Equal(t, "# A\n", readFile(t, filepath.Join(first.Path, "a.md")))
+	assert.Equal(t, "# A, edited\n", readFile(t, filepath.Join(second.Path, "a.md")))
+
+	old, err := os.Stat(filepath.Join(first.Path, "sub", "b.md"))
+	require.NoError(t, err)
+	linked, err := os.Stat(filepath.Join(second.Path, "sub", "b.md"))
+	require.NoError(t, err)
+	assert.True(t, os.SameFile(old, linked))
+
+	snapshots, err := backup.Snapshots(dir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, first.Name, snapshots[0].Name)
+	assert.True(t, snapshots[1].Time.Equal(now))
+}
+
+func TestFindSnapshot(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(home, "a.md"), "# A\n")
+	_, err := backup.FindSnapshot(dir, "latest")
+	assert.ErrorIs(t, err, backup.ErrNotFound)
+
+	for _, day := range []int{1, 2} {
+		_, _, err := backup.TakeSnapshot(dir, home, time.Date(2024, 3, day, 9, 0, 0, 0, time.Local))
+		require.NoError(t, err)
+	}
+	s, err := backup.FindSnapshot(dir, "latest")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-02T090000", s.Name)
+	s, err = backup.FindSnapshot(dir, "2024-03-01T090000")
+	require.NoError(t, err)
+	assert.Equal(t, "2024-03-01T090000", s.Name)
+	_, err = backup.FindSnapshot(dir, "2024-03-03T090000")
+	assert.ErrorIs(t, err, backup.ErrNotFound)
+}
+
+func TestRetain(t *testing.T) {
+	var snapshots []backup.Snapshot
+	// Two snapshots a day, from 2023-12-01 to 2024-03-15.
+	for t0 := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC); t0.Before(time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)); t0 = t0.AddDate(0, 0, 1) {
+		for _, hour := range []int{9, 18} {
+			at := t0.Add(time.Duration(hour) * time.Hour)
+			snapshots = append(snapshots, backup.Snapshot{Name: at.Format(backup.SnapshotLayout), Time: at})
+		}
+	}
+
+	keep := backup.Retain(snapshots, config.RetentionConfig{})
+	assert.Len(t, keep, len(snapshots))
+
+	keep = backup.Retain(snapshots, config.RetentionConfig{Last: 3})
+	assert.Equal(t, map[string]bool{"2024-03-15T180000": true, "2024-03-15T090000": true, "2024-03-14T180000": true}, keep)
+
+	keep = backup.Retain(snapshots, config.RetentionConfig{Daily: 2, Monthly: 3})
+	assert.Equal(t, map[string]bool{
+		"2024-03-15T180000": true,
+		"2024-03-14T180000": true,
+		"2024-02-29T180000": true,
+		"2024-01-31T180000": true,
+	}, keep)
+
+	keep = backup.Retain(snapshots, config.RetentionConfig{Weekly: 2, Yearly: 2})
+	assert.Equal(t, map[string]bool{
+		"2024-03-15T180000": true,
+		// The last snapshot of the week before, which ends on Sunday.
+		"2024-03-10T180000": true,
+		"2023-12-31T180000": true,
+	}, keep)
+}
+
+func TestPrune(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(home, "a.md"), "# A\n")
+	for _, day := range []int{1, 1, 2, 3} {
+		_, _, err := backup.TakeSnapshot(dir, home, time.Date(2024, 3, day, 9, 0, 0, 0, time.Local))
+		require.NoError(t, err)
+	}
+
+	removed, err := backup.Prune(dir, config.RetentionConfig{Daily: 2})
+	require.NoError(t, err)
+	require.Len(t, removed, 2)
+	assert.Equal(t, "2024-03-01T090000", removed[0].Name)
+	assert.Equal(t, "2024-03-01T090000-2", removed[1].Name)
+	assert.NoDirExists(t, removed[0].Path)
+
+	snapshots, err := backup.Snapshots(dir)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, "# A\n", readFile(t, filepath.Join(snapshots[0].Path, "a.md")))
+}
+
+func TestRestoreSnapshot(t *testing.T) {
+	home := t.TempDir()
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(home, "a.md"), "# A\n")
+	writeFile(t, filepath.Join(home, "sub", "b.md"), "# B\n")
+	s, _, err := backup.TakeSnapshot(dir, home, time.Date(2024, 3, 15, 10, 15, 0, 0, time.Local))
+	require.NoError(t, err)
+
+	writeFile(t, filepath.Join(home, "a.md"), "# A, edited\n")
+	writeFile(t, filepath.Join(home, "sub", "b.md"), "# B, edited\n")
+	writeFile(t, filepath.Join(home, "c.md"), "# C\n")
+
+	n, err := backup.RestoreSnapshot(s, home, "sub/b.md")
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, "# B\n", readFile(t, filepath.Join(home, "sub", "b.md")))
+	assert.Equal(t, "# A, edited\n", readFile(t, filepath.Join(home, "a.md")))
+
+	// The restored file is a copy: editing it leaves the snapshot alone.
+	writeFile(t, filepath.Join(home, "sub", "b.md"), "# B, edited again\n")
+	assert.Equal(t, "# B\n", readFile(t, filepath.Join(s.Path, "sub", "b.md")))
+
+	n, err = backup.RestoreSnapshot(s, home, "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, "# A\n", readFile(t, filepath.Join(home, "a.md")))
+	assert.Equal(t, "# C\n", readFile(t, filepath.Join(home, "c.md")))
+
+	_, err = backup.RestoreSnapshot(s, home, "missing.md")
+	assert.ErrorIs(t, err, backup.ErrNotFound)
+	_, err = backup.RestoreSnapshot(s, home, "../outside.md")
+	assert.Error(t, err)
+}
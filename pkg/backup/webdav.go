@@ -0,0 +1,125 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// webDAVRemote stores backups in a collection of a WebDAV server.
+type webDAVRemote struct {
+	url      *url.URL
+	username string
+	password string
+	client   *http.Client
+	// collections are the collections known to exist, by name.
+	collections map[string]bool
+}
+
+func newWebDAV(cfg config.Config) (Remote, error) {
+	c := cfg.Backup.WebDAV
+	if c.URL == "" {
+		return nil, fmt.Errorf("backup.webdav.url is not set")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid backup.webdav.url %q", c.URL)
+	}
+	if !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return &webDAVRemote{
+		url:         u,
+		username:    c.Username,
+		password:    os.Getenv(EnvWebDAVPassword),
+		client:      &http.Client{},
+		collections: map[string]bool{"": true},
+	}, nil
+}
+
+// Get implements Remote.
+func (r *webDAVRemote) Get(ctx context.Context, name string) ([]byte, error) {
+	resp, err := r.do(ctx, http.MethodGet, name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("%s: %w", name, ErrNotFound)
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("failed to read %s: %s", name, resp.Status)
+	}
+	return data, nil
+}
+
+// Put implements Remote, creating the collections of name first.
+func (r *webDAVRemote) Put(ctx context.Context, name string, data []byte) error {
+	if err := r.mkcol(ctx, path.Dir(name)); err != nil {
+		return err
+	}
+	resp, err := r.do(ctx, http.MethodPut, name, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated, http.StatusNoContent:
+		return nil
+	}
+	return fmt.Errorf("failed to write %s: %s", name, resp.Status)
+}
+
+// mkcol creates the collection dir and its parents, unless known to exist.
+func (r *webDAVRemote) mkcol(ctx context.Context, dir string) error {
+	if dir == "." {
+		dir = ""
+	}
+	if r.collections[dir] {
+		return nil
+	}
+	if err := r.mkcol(ctx, path.Dir(dir)); err != nil {
+		return err
+	}
+	resp, err := r.do(ctx, "MKCOL", dir+"/", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	// Servers answer 405 Method Not Allowed for collections that exist.
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+		return fmt.Errorf("failed to create collection %s: %s", dir, resp.Status)
+	}
+	r.collections[dir] = true
+	return nil
+}
+
+func (r *webDAVRemote) do(ctx context.Context, method, name string, body []byte) (*http.Response, error) {
+	u := r.url.ResolveReference(&url.URL{Path: name})
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebDAV request: %w", err)
+	}
+	if r.username != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("WebDAV request failed: %w", err)
+	}
+	return resp, nil
+}
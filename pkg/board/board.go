@@ -0,0 +1,208 @@
+// Package board gathers the tasks of project notes into a board: columns of
+// tasks grouped by status, as on a kanban board, or by project. The status of
+// a task is given by its checkbox:
+//
+//   - [ ] todo
+//   - [/] doing
+//   - [x] done
+//   - [-] cancelled
+package board
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Status is the status of a task.
+type Status string
+
+// The statuses of tasks, by the checkbox marking them.
+const (
+	Todo      Status = "todo"
+	Doing     Status = "doing"
+	Done      Status = "done"
+	Cancelled Status = "cancelled"
+)
+
+// Statuses are the statuses in the order of the columns of a board.
+var Statuses = []Status{Todo, Doing, Done, Cancelled}
+
+// markers are the checkbox markers of the statuses.
+var markers = map[Status]string{Todo: " ", Doing: "/", Done: "x", Cancelled: "-"}
+
+// Marker returns the checkbox of s, e.g. "[/]".
+func (s Status) Marker() string {
+	return "[" + markers[s] + "]"
+}
+
+// Open reports whether a task of status s is still to be done.
+func (s Status) Open() bool {
+	return s == Todo || s == Doing
+}
+
+// taskPattern matches a task: the list marker, the checkbox and the text.
+var taskPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+\[([ xX/-])\]\s+(.*\S)\s*$`)
+
+// projectKey is the frontmatter key naming the project a note belongs to.
+const projectKey = "project"
+
+// Task is a task of a project note.
+type Task struct {
+	Project string `json:"project"`
+	Path    string `json:"path"`
+	Title   string `json:"title"`
+	// Line is the line number of the task, counted from 1.
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Status Status `json:"status"`
+}
+
+// String returns t as "[/] text (title:line)".
+func (t Task) String() string {
+	return fmt.Sprintf("%s %s (%s:%d)", t.Status.Marker(), t.Text, t.Title, t.Line)
+}
+
+// Parse returns the tasks of content, without their project, path and title,
+// in the order they appear. Tasks in the frontmatter and in code blocks are
+// left out.
+func Parse(content string) []Task {
+	var tasks []Task
+	doc := md.Parse(content)
+	for i, line := range doc.Lines {
+		if doc.Code(i) {
+			continue
+		}
+		m := taskPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		status := Todo
+		switch m[1] {
+		case "/":
+			status = Doing
+		case "x", "X":
+			status = Done
+		case "-":
+			status = Cancelled
+		}
+		tasks = append(tasks, Task{Line: i + 1, Text: m[2], Status: status})
+	}
+	return tasks
+}
+
+// Project returns the project n belongs to: the one named by the project field
+// of its frontmatter or, for notes under projectsDir, the directory of the
+// project, or the note itself when it lies directly in projectsDir. Other
+// notes belong to no project.
+func Project(n scan.Note, projectsDir string) string {
+	if name := frontmatter.String(n.Meta, projectKey); name != "" {
+		return name
+	}
+	rel, err := filepath.Rel(projectsDir, n.Path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	if dir, _, ok := strings.Cut(filepath.ToSlash(rel), "/"); ok {
+		return dir
+	}
+	return n.Title
+}
+
+// Collect returns the tasks of the notes belonging to a project, ordered by
+// project, then path and line.
+func Collect(ctx context.Context, projectsDir string, notes []scan.Note) ([]Task, error) {
+	var tasks []Task
+	for _, n := range notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		project := Project(n, projectsDir)
+		if project == "" {
+			continue
+		}
+		content, err := os.ReadFile(n.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", n.Path, err)
+		}
+		for _, t := range Parse(string(content)) {
+			t.Project, t.Path, t.Title = project, n.Path, n.Title
+			tasks = append(tasks, t)
+		}
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		a, b := tasks[i], tasks[j]
+		if !strings.EqualFold(a.Project, b.Project) {
+			return strings.ToLower(a.Project) < strings.ToLower(b.Project)
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return a.Line < b.Line
+	})
+	return tasks, nil
+}
+
+// Column is a column of a board.
+type Column struct {
+	Name  string `json:"name"`
+	Tasks []Task `json:"tasks"`
+}
+
+// ByStatus returns a column of tasks for each of statuses, in that order,
+// empty columns included.
+func ByStatus(tasks []Task, statuses []Status) []Column {
+	columns := make([]Column, len(statuses))
+	index := make(map[Status]int, len(statuses))
+	for i, s := range statuses {
+		columns[i] = Column{Name: string(s), Tasks: []Task{}}
+		index[s] = i
+	}
+	for _, t := range tasks {
+		if i, ok := index[t.Status]; ok {
+			columns[i].Tasks = append(columns[i].Tasks, t)
+		}
+	}
+	return columns
+}
+
+// ByProject returns a column of tasks for each project, in the order the
+// projects first appear in tasks.
+func ByProject(tasks []Task) []Column {
+	var columns []Column
+	index := make(map[string]int)
+	for _, t := range tasks {
+		key := strings.ToLower(t.Project)
+		i, ok := index[key]
+		if !ok {
+			i = len(columns)
+			index[key] = i
+			columns = append(columns, Column{Name: t.Project})
+		}
+		columns[i].Tasks = append(columns[i].Tasks, t)
+	}
+	return columns
+}
+
+// Find returns the tasks whose text contains query, ignoring case. A task
+// whose text is query exactly is the only one returned.
+func Find(tasks []Task, query string) []Task {
+	var found []Task
+	for _, t := range tasks {
+		if strings.EqualFold(t.Text, query) {
+			return []Task{t}
+		}
+		if strings.Contains(strings.ToLower(t.Text), strings.ToLower(query)) {
+			found = append(found, t)
+		}
+	}
+	return found
+}
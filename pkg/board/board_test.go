@@ -0,0 +1,69 @@
+package board_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/board"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	content := "---\ntodo: \"- [ ] not a task\"\n---\n# Plan\n\n" +
+		"- [ ] Buy domain\n" +
+		"  * [/] Deploy the site\n" +
+		"1. [X] Pick a theme\n" +
+		"- [-] Write a blog\n" +
+		"- [?] Unknown marker\n" +
+		"- [] Not a checkbox\n\n" +
+		"```\n- [ ] In code\n```\n"
+	assert.Equal(t, []board.Task{
+		{Line: 6, Text: "Buy domain", Status: board.Todo},
+		{Line: 7, Text: "Deploy the site", Status: board.Doing},
+		{Line: 8, Text: "Pick a theme", Status: board.Done},
+		{Line: 9, Text: "Write a blog", Status: board.Cancelled},
+	}, board.Parse(content))
+}
+
+func TestCollectAndGroup(t *testing.T) {
+	root := t.TempDir()
+	projects := filepath.Join(root, "projects")
+	write := func(rel, content string, meta map[string]interface{}) scan.Note {
+		path := filepath.Join(root, rel)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		name := filepath.Base(rel)
+		return scan.Note{Path: path, Title: name[:len(name)-3], Meta: meta}
+	}
+	notes := []scan.Note{
+		write("projects/website/plan.md", "- [ ] Buy domain\n- [/] Deploy\n- [x] Theme\n", nil),
+		write("projects/Errands.md", "- [ ] Post office\n", nil),
+		write("0-inbox/shed.md", "- [ ] Buy wood\n", map[string]interface{}{"project": "Garden"}),
+		write("0-inbox/other.md", "- [ ] Not a project task\n", nil),
+	}
+	tasks, err := board.Collect(context.Background(), projects, notes)
+	require.NoError(t, err)
+	require.Len(t, tasks, 5)
+	assert.Equal(t, "Errands", tasks[0].Project, "notes directly in the projects directory are projects")
+	assert.Equal(t, "Garden", tasks[1].Project)
+	assert.Equal(t, "[/] Deploy (plan:2)", tasks[3].String())
+
+	columns := board.ByStatus(tasks, []board.Status{board.Todo, board.Doing})
+	require.Len(t, columns, 2)
+	assert.Len(t, columns[0].Tasks, 3)
+	assert.Len(t, columns[1].Tasks, 1)
+
+	columns = board.ByProject(tasks)
+	require.Len(t, columns, 3)
+	assert.Equal(t, "website", columns[2].Name)
+	assert.Len(t, columns[2].Tasks, 3)
+
+	assert.Len(t, board.Find(tasks, "buy"), 2)
+	found := board.Find(tasks, "deploy")
+	require.Len(t, found, 1)
+	assert.Equal(t, 2, found[0].Line)
+}
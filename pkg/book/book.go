@@ -0,0 +1,120 @@
+// Package book looks up the metadata of books, by ISBN or title, from
+// pluggable providers.
+package book
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// DefaultTimeout bounds the time a provider is given to look a book up.
+const DefaultTimeout = 10 * time.Second
+
+// ErrNotFound is returned by providers that know no book matching a query.
+var ErrNotFound = errors.New("book not found")
+
+// Metadata describes a book.
+type Metadata struct {
+	Title     string
+	Authors   []string
+	Year      string
+	Publisher string
+	// ISBN is the ISBN-13, or the ISBN-10 of books without one.
+	ISBN  string
+	Pages int
+}
+
+// Provider looks up books.
+type Provider interface {
+	// Lookup returns the metadata of the book with the ISBN isbn if it is not
+	// empty, and else of the book best matching title.
+	Lookup(ctx context.Context, isbn, title string) (Metadata, error)
+}
+
+// Factory creates a Provider from the configuration.
+type Factory func(cfg config.Config) (Provider, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		"openlibrary": newOpenLibrary,
+	}
+)
+
+// Register makes a provider available under name, replacing any existing one.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// Names returns the names of the registered providers in sorted order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the provider registered under name.
+func New(name string, cfg config.Config) (Provider, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown book provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// NormalizeISBN returns s without hyphens and spaces if it is a valid ISBN-10
+// or ISBN-13, checksum included.
+func NormalizeISBN(s string) (string, bool) {
+	isbn := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(s)))
+	switch len(isbn) {
+	case 10:
+		sum := 0
+		for i, c := range isbn {
+			var d int
+			switch {
+			case c >= '0' && c <= '9':
+				d = int(c - '0')
+			case c == 'X' && i == 9:
+				d = 10
+			default:
+				return "", false
+			}
+			sum += (10 - i) * d
+		}
+		if sum%11 == 0 {
+			return isbn, true
+		}
+	case 13:
+		sum := 0
+		for i, c := range isbn {
+			if c < '0' || c > '9' {
+				return "", false
+			}
+			d := int(c - '0')
+			if i%2 == 1 {
+				d *= 3
+			}
+			sum += d
+		}
+		if sum%10 == 0 {
+			return isbn, true
+		}
+	}
+	return "", false
+}
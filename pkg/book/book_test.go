@@ -0,0 +1,98 @@
+package book_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/book"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeISBN(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+		ok   bool
+	}{
+		{"978-0-262-51087-5", "9780262510875", true},
+		{"0262510871", "0262510871", true},
+		{"0-8044-2957-x", "080442957X", true},
+		{"978-0-262-51087-4", "", false},
+		{"0262510872", "", false},
+		{"Structure and Interpretation", "", false},
+		{"12345", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := book.NormalizeISBN(tt.in)
+		assert.Equal(t, tt.ok, ok, tt.in)
+		assert.Equal(t, tt.want, got, tt.in)
+	}
+}
+
+type staticProvider book.Metadata
+
+func (p staticProvider) Lookup(context.Context, string, string) (book.Metadata, error) {
+	return book.Metadata(p), nil
+}
+
+func TestRegister(t *testing.T) {
+	book.Register("test-static", func(config.Config) (book.Provider, error) {
+		return staticProvider{Title: "Static"}, nil
+	})
+	assert.Contains(t, book.Names(), "test-static")
+	assert.Contains(t, book.Names(), "openlibrary")
+
+	p, err := book.New("test-static", config.Config{})
+	require.NoError(t, err)
+	m, err := p.Lookup(context.Background(), "", "anything")
+	require.NoError(t, err)
+	assert.Equal(t, "Static", m.Title)
+
+	_, err = book.New("missing", config.Config{})
+	assert.ErrorContains(t, err, "unknown book provider")
+}
+
+func TestOpenLibrary(t *testing.T) {
+	var query url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/search.json", r.URL.Path)
+		query = r.URL.Query()
+		if query.Get("title") == "nothing" {
+			w.Write([]byte(`{"numFound":0,"docs":[]}`))
+			return
+		}
+		w.Write([]byte(`{"docs":[{"title":"Structure and Interpretation of Computer Programs",
+			"author_name":["Harold Abelson","Gerald Jay Sussman"],"first_publish_year":1985,
+			"publisher":["MIT Press","McGraw-Hill"],"isbn":["0262510871","9780262510875"],
+			"number_of_pages_median":657}]}`))
+	}))
+	defer srv.Close()
+
+	p, err := book.New("openlibrary", config.Config{Book: config.BookConfig{URL: srv.URL + "/"}})
+	require.NoError(t, err)
+
+	m, err := p.Lookup(context.Background(), "", "sicp")
+	require.NoError(t, err)
+	assert.Equal(t, "sicp", query.Get("title"))
+	assert.Equal(t, book.Metadata{
+		Title:     "Structure and Interpretation of Computer Programs",
+		Authors:   []string{"Harold Abelson", "Gerald Jay Sussman"},
+		Year:      "1985",
+		Publisher: "MIT Press",
+		ISBN:      "9780262510875",
+		Pages:     657,
+	}, m)
+
+	m, err = p.Lookup(context.Background(), "0262510871", "")
+	require.NoError(t, err)
+	assert.Equal(t, "0262510871", query.Get("isbn"))
+	assert.Equal(t, "0262510871", m.ISBN)
+
+	_, err = p.Lookup(context.Background(), "", "nothing")
+	assert.ErrorIs(t, err, book.ErrNotFound)
+}
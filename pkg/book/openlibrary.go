@@ -0,0 +1,102 @@
+package book
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// DefaultOpenLibraryURL is the Open Library API.
+const DefaultOpenLibraryURL = "https://openlibrary.org"
+
+type openLibrary struct {
+	url    string
+	client *http.Client
+}
+
+func newOpenLibrary(cfg config.Config) (Provider, error) {
+	p := openLibrary{
+		url:    strings.TrimSuffix(cfg.Book.URL, "/"),
+		client: &http.Client{Timeout: DefaultTimeout},
+	}
+	if p.url == "" {
+		p.url = DefaultOpenLibraryURL
+	}
+	return p, nil
+}
+
+// Lookup implements Provider with the Open Library search API.
+func (p openLibrary) Lookup(ctx context.Context, isbn, title string) (Metadata, error) {
+	q := url.Values{}
+	if isbn != "" {
+		q.Set("isbn", isbn)
+	} else {
+		q.Set("title", title)
+	}
+	q.Set("fields", "title,author_name,first_publish_year,publisher,isbn,number_of_pages_median")
+	q.Set("limit", "1")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"/search.json?"+q.Encode(), nil)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("invalid Open Library URL: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to look up book: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Metadata{}, fmt.Errorf("failed to look up book: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Metadata{}, fmt.Errorf("failed to look up book: %s", resp.Status)
+	}
+
+	var result struct {
+		Docs []struct {
+			Title     string   `json:"title"`
+			Authors   []string `json:"author_name"`
+			Year      int      `json:"first_publish_year"`
+			Publisher []string `json:"publisher"`
+			ISBN      []string `json:"isbn"`
+			Pages     int      `json:"number_of_pages_median"`
+		} `json:"docs"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return Metadata{}, fmt.Errorf("failed to decode book: %w", err)
+	}
+	if len(result.Docs) == 0 {
+		return Metadata{}, ErrNotFound
+	}
+	doc := result.Docs[0]
+	m := Metadata{
+		Title:   doc.Title,
+		Authors: doc.Authors,
+		ISBN:    isbn,
+		Pages:   doc.Pages,
+	}
+	if doc.Year > 0 {
+		m.Year = strconv.Itoa(doc.Year)
+	}
+	if len(doc.Publisher) > 0 {
+		m.Publisher = doc.Publisher[0]
+	}
+	if m.ISBN == "" {
+		for _, candidate := range doc.ISBN {
+			if len(candidate) == 13 || m.ISBN == "" {
+				m.ISBN = candidate
+			}
+			if len(m.ISBN) == 13 {
+				break
+			}
+		}
+	}
+	return m, nil
+}
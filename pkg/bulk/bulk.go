@@ -0,0 +1,198 @@
+// Package bulk applies an operation to many notes at once: archiving, deleting,
+// retagging or moving them.
+package bulk
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// Op is an operation applied to notes.
+type Op string
+
+// The operations.
+const (
+	// Archive moves notes into the archive directory, keeping their path
+	// relative to the vault.
+	Archive Op = "archive"
+	// Delete removes notes.
+	Delete Op = "delete"
+	// Retag renames a tag of notes, or removes it.
+	Retag Op = "retag"
+	// Move moves notes into a directory.
+	Move Op = "move"
+)
+
+// Ops lists the operations.
+var Ops = []Op{Archive, Delete, Retag, Move}
+
+// ParseOp returns the operation named name.
+func ParseOp(name string) (Op, error) {
+	for _, op := range Ops {
+		if string(op) == strings.ToLower(name) {
+			return op, nil
+		}
+	}
+	return "", exoerrors.New(exoerrors.Usage, "unknown operation %q (expected archive, delete, retag or move)", name)
+}
+
+// Target returns where op puts the note at path: for Archive, the same path
+// relative to root under dir; for Move, the file name under dir.
+func Target(op Op, path, root, dir string) string {
+	if op == Archive {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.Join(dir, rel)
+		}
+	}
+	return filepath.Join(dir, filepath.Base(path))
+}
+
+// MoveFile moves the file at from to to through fsys, creating the directory
+// of to. A file already at to is never overwritten.
+func MoveFile(fsys fs.FileSystem, from, to string) error {
+	if fsys.FileExists(to) {
+		return exoerrors.New(exoerrors.Conflict, "cannot move %s: %s already exists", from, to)
+	}
+	content, err := fsys.ReadFile(from)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", from, err)
+	}
+	if err := fsys.EnsureDirectoryExists(to); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", to, err)
+	}
+	if err := fsys.WriteFile(to, content); err != nil {
+		return fmt.Errorf("failed to move %s: %w", from, err)
+	}
+	if err := fsys.DeleteFile(from); err != nil {
+		return fmt.Errorf("failed to move %s: %w", from, err)
+	}
+	return nil
+}
+
+// RenameTag renames the tag from to to in the frontmatter "tags" and the inline
+// #tags of content, outside fenced code blocks, or removes it when to is
+// empty. Tags match case-insensitively, with or without a leading #. The rest
+// of the frontmatter is kept as written. It reports whether content changed.
+func RenameTag(content, from, to string) (string, bool, error) {
+	from, to = strings.TrimPrefix(from, "#"), strings.TrimPrefix(to, "#")
+	if from == "" {
+		return "", false, exoerrors.New(exoerrors.Validation, "no tag to rename")
+	}
+	raw, body := frontmatter.Split(content)
+	raw, changed, err := renameFrontmatterTag(raw, from, to)
+	if err != nil {
+		return "", false, err
+	}
+
+	inline := regexp.MustCompile(`(?i)(^|\s)#` + regexp.QuoteMeta(from) + `([^\p{L}\p{N}_/-]|$)`)
+	replacement := "${2}"
+	if to != "" {
+		replacement = "${1}#" + strings.ReplaceAll(to, "$", "$$") + "${2}"
+	}
+	lines := strings.Split(body, "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !inline.MatchString(line) {
+			continue
+		}
+		lines[i] = inline.ReplaceAllString(line, replacement)
+		changed = true
+	}
+	if !changed {
+		return content, false, nil
+	}
+	body = strings.Join(lines, "\n")
+	if raw == "" {
+		return body, true, nil
+	}
+	return "---\n" + raw + "---\n" + body, true, nil
+}
+
+// renameFrontmatterTag renames the tag from to to in the "tags" of the raw
+// frontmatter, or removes it when to is empty, and reports whether it did.
+func renameFrontmatterTag(raw, from, to string) (string, bool, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return "", false, fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return raw, false, nil
+	}
+	m := doc.Content[0]
+	changed := false
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value != "tags" {
+			continue
+		}
+		tags := m.Content[i+1]
+		var kept []*yaml.Node
+		var names []string
+		switch tags.Kind {
+		case yaml.SequenceNode:
+			for _, item := range tags.Content {
+				if strings.EqualFold(strings.TrimPrefix(strings.TrimSpace(item.Value), "#"), from) {
+					changed = true
+					item.Value = to
+				}
+				if item.Value == "" || containsFold(names, item.Value) {
+					continue
+				}
+				names = append(names, item.Value)
+				kept = append(kept, item)
+			}
+			tags.Content = kept
+		case yaml.ScalarNode:
+			for _, tag := range strings.Split(tags.Value, ",") {
+				tag = strings.TrimSpace(tag)
+				if strings.EqualFold(strings.TrimPrefix(tag, "#"), from) {
+					changed = true
+					tag = to
+				}
+				if tag != "" && !containsFold(names, tag) {
+					names = append(names, tag)
+				}
+			}
+			tags.Value = strings.Join(names, ", ")
+		}
+		if len(names) == 0 && changed {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+		}
+		break
+	}
+	if !changed {
+		return raw, false, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", false, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", false, fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	return buf.String(), true, nil
+}
+
+// containsFold reports whether values holds value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+package bulk_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/bulk"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOp(t *testing.T) {
+	op, err := bulk.ParseOp("Archive")
+	require.NoError(t, err)
+	assert.Equal(t, bulk.Archive, op)
+
+	_, err = bulk.ParseOp("burn")
+	assert.True(t, exoerrors.Is(err, exoerrors.Usage))
+}
+
+func TestTarget(t *testing.T) {
+	root := filepath.FromSlash("/v")
+	path := filepath.FromSlash("/v/zettel/go.md")
+	assert.Equal(t, filepath.FromSlash("/v/archive/zettel/go.md"), bulk.Target(bulk.Archive, path, root, filepath.FromSlash("/v/archive")))
+	assert.Equal(t, filepath.FromSlash("/v/projects/go.md"), bulk.Target(bulk.Move, path, root, filepath.FromSlash("/v/projects")))
+}
+
+func TestMoveFile(t *testing.T) {
+	dir := t.TempDir()
+	from := filepath.Join(dir, "go.md")
+	to := filepath.Join(dir, "archive", "zettel", "go.md")
+	require.NoError(t, os.WriteFile(from, []byte("# Go"), 0644))
+
+	fsys := fs.NewOSFileSystem()
+	require.NoError(t, bulk.MoveFile(fsys, from, to))
+	assert.NoFileExists(t, from)
+	assert.FileExists(t, to)
+
+	require.NoError(t, os.WriteFile(from, []byte("# Go again"), 0644))
+	err := bulk.MoveFile(fsys, from, to)
+	assert.True(t, exoerrors.Is(err, exoerrors.Conflict))
+	assert.FileExists(t, from, "the note is kept when the target exists")
+
+	other := filepath.Join(dir, "archive", "rust.md")
+	assert.Error(t, bulk.MoveFile(fs.NewReadOnlyFileSystem(fsys), from, other))
+	assert.FileExists(t, from, "a read-only vault is left alone")
+	assert.NoFileExists(t, other)
+}
+
+func TestRenameTag(t *testing.T) {
+	content := "---\ntags:\n  - go\n  - Obsolete\n---\n# Go\n\nSee #obsolete and #obsolete-ish.\n\n```\n#obsolete in code\n```\n"
+
+	retagged, changed, err := bulk.RenameTag(content, "#obsolete", "archived")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "---\ntags:\n  - go\n  - archived\n---\n# Go\n\nSee #archived and #obsolete-ish.\n\n```\n#obsolete in code\n```\n", retagged)
+
+	removed, changed, err := bulk.RenameTag(content, "obsolete", "")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "---\ntags:\n  - go\n---\n# Go\n\nSee and #obsolete-ish.\n\n```\n#obsolete in code\n```\n", removed)
+
+	flow, changed, err := bulk.RenameTag("---\ncreated: 2020-01-01\ntags: [obsolete, go]\n---\n# Go\n", "obsolete", "go")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "---\ncreated: 2020-01-01\ntags: [go]\n---\n# Go\n", flow, "the frontmatter is kept as written")
+
+	plain, changed, err := bulk.RenameTag("# Rust\n\n#lang\n", "go", "golang")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.Equal(t, "# Rust\n\n#lang\n", plain)
+}
@@ -0,0 +1,224 @@
+package calendar_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/calendar"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ics = "BEGIN:VCALENDAR\r\n" +
+	"VERSION:2.0\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"SUMMARY:Stand-up\\, daily\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240102T093000\r\n" +
+	"DURATION:PT15M\r\n" +
+	"RRULE:FREQ=WEEKLY;BYDAY=TU,TH\r\n" +
+	"EXDATE;TZID=Europe/Berlin:20240104T093000\r\n" +
+	"ATTENDEE;CN=\"Doe, Jane\":mailto:jane@example.com\r\n" +
+	"ATTENDEE:mailto:bob@example.com\r\n" +
+	"BEGIN:VALARM\r\n" +
+	"DESCRIPTION:Reminder\r\n" +
+	"END:VALARM\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:standup\r\n" +
+	"RECURRENCE-ID;TZID=Europe/Berlin:20240109T093000\r\n" +
+	"SUMMARY:Stand-up (moved)\r\n" +
+	"DTSTART;TZID=Europe/Berlin:20240109T110000\r\n" +
+	"DTEND;TZID=Europe/Berlin:20240109T111500\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:review\r\n" +
+	"SUMMARY:Quarterly review of the roadmap with the whole te\r\n" +
+	" am\r\n" +
+	"DESCRIPTION:Line one\\nLine two\r\n" +
+	"LOCATION:Room 1\\; floor 2\r\n" +
+	"DTSTART:20240105T140000Z\r\n" +
+	"DTEND:20240105T150000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:holiday\r\n" +
+	"SUMMARY:Holiday\r\n" +
+	"DTSTART;VALUE=DATE:20240108\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:cancelled\r\n" +
+	"SUMMARY:Cancelled\r\n" +
+	"STATUS:CANCELLED\r\n" +
+	"DTSTART:20240105T100000Z\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+func berlin(t *testing.T) *time.Location {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("time zone data not available")
+	}
+	return loc
+}
+
+func TestParse(t *testing.T) {
+	loc := berlin(t)
+	events, err := calendar.Parse(strings.NewReader(ics))
+	require.NoError(t, err)
+	require.Len(t, events, 4)
+
+	standup := events[0]
+	assert.Equal(t, "Stand-up, daily", standup.Summary)
+	assert.True(t, standup.Start.Equal(time.Date(2024, 1, 2, 9, 30, 0, 0, loc)))
+	assert.Equal(t, 15*time.Minute, standup.End.Sub(standup.Start))
+	assert.Equal(t, []string{"Doe, Jane", "bob@example.com"}, standup.Attendees)
+	assert.Empty(t, standup.Description, "alarm properties are ignored")
+
+	review := events[2]
+	assert.Equal(t, "Quarterly review of the roadmap with the whole team", review.Summary)
+	assert.Equal(t, "Line one\nLine two", review.Description)
+	assert.Equal(t, "Room 1; floor 2", review.Location)
+	assert.True(t, review.Start.Equal(time.Date(2024, 1, 5, 14, 0, 0, 0, time.UTC)))
+
+	holiday := events[3]
+	assert.True(t, holiday.AllDay)
+	assert.Equal(t, time.Date(2024, 1, 9, 0, 0, 0, 0, time.Local), holiday.End)
+
+	_, err = calendar.Parse(strings.NewReader("BEGIN:VEVENT\nDTSTART:nonsense\nEND:VEVENT\n"))
+	assert.Error(t, err)
+}
+
+func TestBetween(t *testing.T) {
+	loc := berlin(t)
+	events, err := calendar.Parse(strings.NewReader(ics))
+	require.NoError(t, err)
+
+	got := calendar.Between(events, time.Date(2024, 1, 1, 0, 0, 0, 0, loc), time.Date(2024, 1, 12, 0, 0, 0, 0, loc))
+	var summaries []string
+	for _, ev := range got {
+		summaries = append(summaries, ev.Summary)
+	}
+	assert.Equal(t, []string{
+		"Stand-up, daily",
+		"Quarterly review of the roadmap with the whole team",
+		"Holiday",
+		"Stand-up (moved)",
+		"Stand-up, daily",
+	}, summaries, "the excluded and overridden occurrences are left out")
+	assert.True(t, got[3].Start.Equal(time.Date(2024, 1, 9, 11, 0, 0, 0, loc)))
+	assert.True(t, got[4].Start.Equal(time.Date(2024, 1, 11, 9, 30, 0, 0, loc)))
+	assert.Nil(t, calendar.Between(events, time.Date(2023, 1, 1, 0, 0, 0, 0, loc), time.Date(2023, 2, 1, 0, 0, 0, 0, loc)))
+}
+
+func TestRecurrence(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		want  []string
+	}{
+		{"daily with interval", "FREQ=DAILY;INTERVAL=2;COUNT=3", "20240101T090000", []string{"2024-01-01", "2024-01-03", "2024-01-05"}},
+		{"weekly until", "FREQ=WEEKLY;UNTIL=20240116T090000", "20240102T090000", []string{"2024-01-02", "2024-01-09", "2024-01-16"}},
+		{"monthly by day of month", "FREQ=MONTHLY;COUNT=3", "20240131T090000", []string{"2024-01-31", "2024-03-31", "2024-05-31"}},
+		{"monthly first monday", "FREQ=MONTHLY;BYDAY=1MO;COUNT=3", "20240101T090000", []string{"2024-01-01", "2024-02-05", "2024-03-04"}},
+		{"monthly last friday", "FREQ=MONTHLY;BYDAY=-1FR;COUNT=2", "20240126T090000", []string{"2024-01-26", "2024-02-23"}},
+		{"yearly", "FREQ=YEARLY;COUNT=2", "20240229T090000", []string{"2024-02-29", "2028-02-29"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := "BEGIN:VEVENT\nUID:x\nSUMMARY:x\nDTSTART:" + tt.start + "\nRRULE:" + tt.rule + "\nEND:VEVENT\n"
+			events, err := calendar.Parse(strings.NewReader(data))
+			require.NoError(t, err)
+			var got []string
+			for _, ev := range calendar.Between(events, time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local), time.Date(2030, 1, 1, 0, 0, 0, 0, time.Local)) {
+				got = append(got, ev.Start.Format("2006-01-02"))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err := calendar.Parse(strings.NewReader("BEGIN:VEVENT\nDTSTART:20240101T090000\nRRULE:FREQ=HOURLY\nEND:VEVENT\n"))
+	assert.Error(t, err)
+}
+
+func TestLoad(t *testing.T) {
+	from := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+
+	t.Run("file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "cal.ics")
+		require.NoError(t, os.WriteFile(path, []byte(ics), 0o644))
+		events, err := calendar.Load(context.Background(), calendar.Source{Location: path}, from, to)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "review", events[0].UID)
+	})
+
+	t.Run("feed", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, pass, _ := r.BasicAuth()
+			assert.Equal(t, "me", user)
+			assert.Equal(t, "secret", pass)
+			io.WriteString(w, ics)
+		}))
+		defer srv.Close()
+		events, err := calendar.Load(context.Background(), calendar.Source{Location: srv.URL, Username: "me", Password: "secret"}, from, to)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "review", events[0].UID)
+	})
+
+	t.Run("caldav", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != "REPORT" {
+				io.WriteString(w, "<html>calendar home</html>")
+				return
+			}
+			assert.Equal(t, "1", r.Header.Get("Depth"))
+			body, _ := io.ReadAll(r.Body)
+			assert.Contains(t, string(body), `start="20240105T000000Z" end="20240106T000000Z"`)
+			w.WriteHeader(http.StatusMultiStatus)
+			io.WriteString(w, `<?xml version="1.0"?>
+<d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:response>
+    <d:href>/cal/review.ics</d:href>
+    <d:propstat><d:prop><c:calendar-data>BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:review
+SUMMARY:Review
+DTSTART:20240105T140000Z
+DTEND:20240105T150000Z
+END:VEVENT
+END:VCALENDAR
+</c:calendar-data></d:prop></d:propstat>
+  </d:response>
+</d:multistatus>`)
+		}))
+		defer srv.Close()
+		events, err := calendar.Load(context.Background(), calendar.Source{Location: srv.URL}, from, to)
+		require.NoError(t, err)
+		require.Len(t, events, 1)
+		assert.Equal(t, "Review", events[0].Summary)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := calendar.Load(context.Background(), calendar.Source{}, from, to)
+		assert.Error(t, err)
+		_, err = calendar.Load(context.Background(), calendar.Source{Location: filepath.Join(t.TempDir(), "missing.ics")}, from, to)
+		assert.Error(t, err)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+		_, err = calendar.Load(context.Background(), calendar.Source{Location: srv.URL}, from, to)
+		assert.ErrorContains(t, err, "401")
+	})
+}
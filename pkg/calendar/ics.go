@@ -0,0 +1,266 @@
+// Package calendar reads events from iCalendar (ICS) data, local files, ICS
+// feeds and CalDAV calendars, expanding recurring events.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event is an event of a calendar, or one occurrence of a recurring event.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	URL         string
+	Start       time.Time
+	End         time.Time
+	// AllDay is set for events lasting whole days, whose Start and End are
+	// midnights in the local time zone.
+	AllDay bool
+	// Attendees are the names of the attendees, or their addresses when they
+	// have no name.
+	Attendees []string
+
+	rule       *rule
+	exDates    []time.Time
+	recurrence time.Time
+}
+
+// property is a content line of iCalendar data.
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Parse reads the events of iCalendar data. Cancelled events are left out.
+// Recurring events are returned once, as their first occurrence; use Between
+// to expand them.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		events []Event
+		ev     *Event
+		depth  int // nesting of components inside the current event, such as alarms
+		skip   bool
+	)
+	for i, line := range lines {
+		p, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VEVENT") && ev == nil:
+			ev, skip = &Event{}, false
+			continue
+		case ev == nil:
+			continue
+		case p.name == "BEGIN":
+			depth++
+			continue
+		case p.name == "END" && depth > 0:
+			depth--
+			continue
+		case depth > 0:
+			continue
+		case p.name == "END":
+			if !skip {
+				if ev.End.IsZero() {
+					ev.End = ev.Start
+					if ev.AllDay {
+						ev.End = ev.Start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *ev)
+			}
+			ev = nil
+			continue
+		}
+		if err := ev.set(p, &skip); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+1, p.name, err)
+		}
+	}
+	return events, nil
+}
+
+// set applies the property p to the event.
+func (ev *Event) set(p property, skip *bool) error {
+	var err error
+	switch p.name {
+	case "UID":
+		ev.UID = p.value
+	case "SUMMARY":
+		ev.Summary = unescape(p.value)
+	case "DESCRIPTION":
+		ev.Description = unescape(p.value)
+	case "LOCATION":
+		ev.Location = unescape(p.value)
+	case "URL":
+		ev.URL = p.value
+	case "STATUS":
+		*skip = strings.EqualFold(p.value, "CANCELLED")
+	case "DTSTART":
+		ev.Start, ev.AllDay, err = parseTime(p)
+	case "DTEND":
+		ev.End, _, err = parseTime(p)
+	case "DURATION":
+		var d time.Duration
+		if d, err = parseDuration(p.value); err == nil {
+			ev.End = ev.Start.Add(d)
+		}
+	case "ATTENDEE":
+		name := p.params["CN"]
+		if name == "" {
+			name = strings.TrimPrefix(strings.TrimPrefix(p.value, "mailto:"), "MAILTO:")
+		}
+		ev.Attendees = append(ev.Attendees, name)
+	case "RRULE":
+		ev.rule, err = parseRule(p.value)
+	case "EXDATE":
+		for _, v := range strings.Split(p.value, ",") {
+			t, _, err := parseTime(property{params: p.params, value: v})
+			if err != nil {
+				return err
+			}
+			ev.exDates = append(ev.exDates, t)
+		}
+	case "RECURRENCE-ID":
+		ev.recurrence, _, err = parseTime(p)
+	}
+	return err
+}
+
+// unfold reads the content lines of iCalendar data, joining folded lines.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read calendar: %w", err)
+	}
+	return lines, nil
+}
+
+// parseProperty splits a content line into its name, parameters and value.
+func parseProperty(line string) (property, error) {
+	p := property{params: make(map[string]string)}
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return p, fmt.Errorf("invalid content line %q", line)
+	}
+	p.value = line[colon+1:]
+	parts := strings.Split(line[:colon], ";")
+	p.name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		key, value, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+	return p, nil
+}
+
+// unescape decodes the escapes of iCalendar text values.
+func unescape(s string) string {
+	return strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(s)
+}
+
+// parseTime parses a DATE or DATE-TIME value, in UTC, in the zone named by the
+// TZID parameter, or else in the local zone. It reports whether the value is a
+// date.
+func parseTime(p property) (time.Time, bool, error) {
+	value := strings.TrimSpace(p.value)
+	if p.params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t, false, err
+	}
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t, false, err
+}
+
+var durationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// parseDuration parses an iCalendar duration such as PT1H30M or P1D.
+func parseDuration(s string) (time.Duration, error) {
+	m := durationPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	for i, unit := range []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if m[i+2] != "" {
+			n, _ := strconv.Atoi(m[i+2])
+			d += time.Duration(n) * unit
+		}
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+// Between returns the events, and occurrences of recurring events, taking place
+// between from and to, ordered by start.
+func Between(events []Event, from, to time.Time) []Event {
+	// Occurrences moved or changed individually replace the generated ones.
+	overridden := make(map[string]bool)
+	for _, ev := range events {
+		if !ev.recurrence.IsZero() {
+			overridden[ev.UID+"@"+ev.recurrence.UTC().Format(time.RFC3339)] = true
+		}
+	}
+	var out []Event
+	for _, ev := range events {
+		if ev.rule == nil {
+			if ev.Start.Before(to) && ev.End.After(from) || ev.Start.Equal(from) {
+				out = append(out, ev)
+			}
+			continue
+		}
+		for _, occ := range ev.occurrences(from, to) {
+			if !overridden[ev.UID+"@"+occ.Start.UTC().Format(time.RFC3339)] {
+				out = append(out, occ)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out
+}
@@ -0,0 +1,193 @@
+package calendar
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOccurrences bounds the expansion of a recurring event.
+const maxOccurrences = 10000
+
+// rule is a recurrence rule (RRULE). Daily, weekly, monthly and yearly rules are
+// supported, with an interval, a count or an end, weekdays for weekly rules and
+// weekdays, such as every Monday or the first one, for monthly rules.
+type rule struct {
+	freq     string
+	interval int
+	count    int
+	until    time.Time
+	byDay    []weekday
+}
+
+// weekday is a BYDAY entry: a day of the week, with an ordinal in monthly rules
+// (1 for the first, -1 for the last).
+type weekday struct {
+	n   int
+	day time.Weekday
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseRule parses the value of an RRULE property.
+func parseRule(value string) (*rule, error) {
+	r := &rule{interval: 1}
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.freq = strings.ToUpper(val)
+		case "INTERVAL":
+			r.interval, err = strconv.Atoi(val)
+			if err == nil && r.interval < 1 {
+				err = fmt.Errorf("invalid interval %d", r.interval)
+			}
+		case "COUNT":
+			r.count, err = strconv.Atoi(val)
+		case "UNTIL":
+			r.until, _, err = parseTime(property{value: val})
+		case "BYDAY":
+			for _, d := range strings.Split(val, ",") {
+				d = strings.ToUpper(strings.TrimSpace(d))
+				if len(d) < 2 {
+					return nil, fmt.Errorf("invalid BYDAY %q", d)
+				}
+				day, ok := weekdays[d[len(d)-2:]]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY %q", d)
+				}
+				n := 0
+				if ordinal := d[:len(d)-2]; ordinal != "" {
+					if n, err = strconv.Atoi(ordinal); err != nil {
+						return nil, fmt.Errorf("invalid BYDAY %q", d)
+					}
+				}
+				r.byDay = append(r.byDay, weekday{n: n, day: day})
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	switch r.freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+	default:
+		return nil, fmt.Errorf("unsupported recurrence frequency %q", r.freq)
+	}
+	return r, nil
+}
+
+// occurrences returns the occurrences of the recurring event ev taking place
+// between from and to.
+func (ev Event) occurrences(from, to time.Time) []Event {
+	duration := ev.End.Sub(ev.Start)
+	excluded := make(map[int64]bool, len(ev.exDates))
+	for _, t := range ev.exDates {
+		excluded[t.Unix()] = true
+	}
+	var out []Event
+	n := 0
+	for period := 0; n < maxOccurrences; period++ {
+		starts := ev.rule.period(ev.Start, period)
+		if starts == nil {
+			break
+		}
+		for _, start := range starts {
+			if start.Before(ev.Start) {
+				continue
+			}
+			if (ev.rule.count > 0 && n >= ev.rule.count) || (!ev.rule.until.IsZero() && start.After(ev.rule.until)) || !start.Before(to) {
+				return out
+			}
+			n++
+			end := start.Add(duration)
+			if excluded[start.Unix()] || !(end.After(from) || start.Equal(from)) {
+				continue
+			}
+			occ := ev
+			occ.Start, occ.End, occ.rule = start, end, nil
+			out = append(out, occ)
+		}
+	}
+	return out
+}
+
+// period returns the candidate starts of the given period of a recurrence
+// starting at start, in order: the days of the period'th day, week, month or year
+// matching the rule, at the time of day of start. It returns nil when the
+// period cannot be computed.
+func (r *rule) period(start time.Time, period int) []time.Time {
+	y, m, d := start.Date()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+	}
+	k := period * r.interval
+	switch r.freq {
+	case "DAILY":
+		return []time.Time{at(y, m, d+k)}
+	case "WEEKLY":
+		if len(r.byDay) == 0 {
+			return []time.Time{at(y, m, d+7*k)}
+		}
+		// Weeks start on Monday.
+		monday := d - (int(start.Weekday())+6)%7 + 7*k
+		var starts []time.Time
+		for offset := 0; offset < 7; offset++ {
+			day := at(y, m, monday+offset)
+			for _, wd := range r.byDay {
+				if wd.day == day.Weekday() {
+					starts = append(starts, day)
+				}
+			}
+		}
+		return starts
+	case "MONTHLY":
+		month := time.Date(y, m+time.Month(k), 1, 0, 0, 0, 0, start.Location())
+		if len(r.byDay) == 0 {
+			if t := at(month.Year(), month.Month(), d); t.Month() == month.Month() {
+				return []time.Time{t}
+			}
+			return []time.Time{}
+		}
+		var starts []time.Time
+		for _, wd := range r.byDay {
+			for _, day := range monthDays(month.Year(), month.Month(), wd) {
+				starts = append(starts, at(month.Year(), month.Month(), day))
+			}
+		}
+		sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+		return starts
+	case "YEARLY":
+		if t := at(y+k, m, d); t.Day() == d {
+			return []time.Time{t}
+		}
+		return []time.Time{}
+	}
+	return nil
+}
+
+// monthDays returns the days of a month falling on the weekday of wd: the nth
+// one for an ordinal n, counting from the end when negative, or all of them.
+func monthDays(year int, month time.Month, wd weekday) []int {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	var days []int
+	for day := 1 + (int(wd.day)-int(first.Weekday())+7)%7; day <= last; day += 7 {
+		days = append(days, day)
+	}
+	switch {
+	case wd.n == 0:
+		return days
+	case wd.n > 0 && wd.n <= len(days):
+		return days[wd.n-1 : wd.n]
+	case wd.n < 0 && -wd.n <= len(days):
+		return days[len(days)+wd.n : len(days)+wd.n+1]
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package calendar
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// PasswordEnv is the environment variable holding the password sent to the
+// calendar of calendar.source.
+const PasswordEnv = "EXO_CALENDAR_PASSWORD"
+
+// Source is where events are read from: a local ICS file, or the URL of an ICS
+// feed or a CalDAV calendar.
+type Source struct {
+	// Location is a file path or an http(s) or webcal URL.
+	Location string
+	// Username and Password are sent with basic authentication when Username
+	// is set.
+	Username string
+	Password string
+	// Client is used for requests; it defaults to http.DefaultClient.
+	Client *http.Client
+}
+
+// IsURL reports whether location is a URL rather than a file path.
+func IsURL(location string) bool {
+	return strings.Contains(location, "://")
+}
+
+// Load reads the events of src taking place between from and to, with
+// recurring events expanded, ordered by start. A URL answering with iCalendar
+// data is read as a feed; otherwise it is queried as a CalDAV calendar.
+func Load(ctx context.Context, src Source, from, to time.Time) ([]Event, error) {
+	if src.Location == "" {
+		return nil, fmt.Errorf("no calendar source configured")
+	}
+	var (
+		events []Event
+		err    error
+	)
+	if IsURL(src.Location) {
+		events, err = src.fetch(ctx, from, to)
+	} else {
+		events, err = loadFile(src.Location)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return Between(events, from, to), nil
+}
+
+// loadFile reads the events of an ICS file.
+func loadFile(path string) ([]Event, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open calendar: %w", err)
+	}
+	defer f.Close()
+	events, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return events, nil
+}
+
+// fetch reads the events of a calendar URL, first as a feed and then as a
+// CalDAV calendar.
+func (src Source) fetch(ctx context.Context, from, to time.Time) ([]Event, error) {
+	url := src.Location
+	if rest, ok := strings.CutPrefix(url, "webcal://"); ok {
+		url = "https://" + rest
+	}
+	body, err := src.do(ctx, http.MethodGet, url, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(body), []byte("BEGIN:VCALENDAR")) {
+		events, err := Parse(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", url, err)
+		}
+		return events, nil
+	}
+	return src.report(ctx, url, from, to)
+}
+
+// calendarQuery is the body of a CalDAV calendar-query REPORT for the events
+// between two times, in UTC.
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%s" end="%s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+// multistatus is the part of a CalDAV REPORT response holding calendar data.
+type multistatus struct {
+	Responses []struct {
+		Data []string `xml:"propstat>prop>calendar-data"`
+	} `xml:"response"`
+}
+
+// report queries the CalDAV calendar at url for the events between from and to.
+func (src Source) report(ctx context.Context, url string, from, to time.Time) ([]Event, error) {
+	const layout = "20060102T150405Z"
+	query := fmt.Sprintf(calendarQuery, from.UTC().Format(layout), to.UTC().Format(layout))
+	body, err := src.do(ctx, "REPORT", url, strings.NewReader(query), map[string]string{
+		"Content-Type": "application/xml; charset=utf-8",
+		"Depth":        "1",
+	})
+	if err != nil {
+		return nil, err
+	}
+	var ms multistatus
+	if err := xml.Unmarshal(body, &ms); err != nil {
+		return nil, fmt.Errorf("failed to parse CalDAV response from %s: %w", url, err)
+	}
+	var events []Event
+	for _, r := range ms.Responses {
+		for _, data := range r.Data {
+			evs, err := Parse(strings.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", url, err)
+			}
+			events = append(events, evs...)
+		}
+	}
+	return events, nil
+}
+
+// do sends a request and returns the body of a successful response.
+func (src Source) do(ctx context.Context, method, url string, body io.Reader, header map[string]string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid calendar URL: %w", err)
+	}
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	if src.Username != "" {
+		req.SetBasicAuth(src.Username, src.Password)
+	}
+	client := src.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch calendar: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch calendar: %s %s: %s", method, url, resp.Status)
+	}
+	return data, nil
+}
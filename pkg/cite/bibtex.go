@@ -0,0 +1,367 @@
+package cite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// monthMacros are the month abbreviations BibTeX predefines.
+var monthMacros = map[string]string{
+	"jan": "January", "feb": "February", "mar": "March", "apr": "April",
+	"may": "May", "jun": "June", "jul": "July", "aug": "August",
+	"sep": "September", "oct": "October", "nov": "November", "dec": "December",
+}
+
+// latexReplacer replaces the LaTeX escapes and dashes common in BibTeX fields.
+var latexReplacer = strings.NewReplacer(
+	`\&`, "&", `\%`, "%", `\$`, "$", `\_`, "_", `\#`, "#",
+	"---", "—", "--", "–", "~", " ", "{", "", "}", "",
+)
+
+// bibParser parses BibTeX source.
+type bibParser struct {
+	src    string
+	pos    int
+	macros map[string]string
+}
+
+// ParseBibTeX parses the entries of a BibTeX bibliography. @string macros are
+// expanded; @comment and @preamble blocks are skipped.
+func ParseBibTeX(src string) ([]Entry, error) {
+	p := &bibParser{src: src, macros: make(map[string]string)}
+	for k, v := range monthMacros {
+		p.macros[k] = v
+	}
+	var entries []Entry
+	for {
+		i := strings.IndexByte(p.src[p.pos:], '@')
+		if i < 0 {
+			return entries, nil
+		}
+		p.pos += i + 1
+		typ := strings.ToLower(p.ident())
+		p.skipSpace()
+		closer, err := p.open()
+		if err != nil {
+			return nil, err
+		}
+		switch typ {
+		case "comment", "preamble":
+			if err := p.skipBlock(closer); err != nil {
+				return nil, err
+			}
+		case "string":
+			fields, err := p.fields(closer)
+			if err != nil {
+				return nil, err
+			}
+			for name, value := range fields {
+				p.macros[name] = value
+			}
+		default:
+			key := strings.TrimSpace(p.key(closer))
+			if key == "" {
+				return nil, p.errorf("missing citation key in @%s entry", typ)
+			}
+			fields, err := p.fields(closer)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, bibEntry(key, typ, fields))
+		}
+	}
+}
+
+// bibEntry builds the entry of a BibTeX entry's fields.
+func bibEntry(key, typ string, fields map[string]string) Entry {
+	field := func(names ...string) string {
+		for _, name := range names {
+			if v := fields[name]; v != "" {
+				return cleanTeX(v)
+			}
+		}
+		return ""
+	}
+	names := fields["author"]
+	if names == "" {
+		names = fields["editor"]
+	}
+	year := field("year")
+	if year == "" {
+		if date := field("date"); len(date) >= 4 {
+			year = date[:4]
+		}
+	}
+	return Entry{
+		Key:       key,
+		Type:      typ,
+		Title:     field("title"),
+		Authors:   parseNames(names),
+		Year:      year,
+		Container: field("journal", "journaltitle", "booktitle"),
+		Publisher: field("publisher", "institution", "school", "organization"),
+		Volume:    field("volume"),
+		Issue:     field("number", "issue"),
+		Pages:     field("pages"),
+		DOI:       strings.TrimSpace(fields["doi"]),
+		URL:       strings.TrimSpace(fields["url"]),
+	}
+}
+
+// fields parses the comma-separated "name = value" pairs up to closer.
+// Names are lowercased; values keep their braces.
+func (p *bibParser) fields(closer byte) (map[string]string, error) {
+	fields := make(map[string]string)
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, p.errorf("unterminated entry")
+		}
+		switch p.src[p.pos] {
+		case closer:
+			p.pos++
+			return fields, nil
+		case ',':
+			p.pos++
+			continue
+		}
+		name := strings.ToLower(p.ident())
+		if name == "" {
+			return nil, p.errorf("unexpected %q", p.src[p.pos])
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '=' {
+			return nil, p.errorf("expected = after field %s", name)
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		fields[name] = value
+	}
+}
+
+// value parses a field value: braced or quoted strings, numbers and macros,
+// concatenated with #.
+func (p *bibParser) value() (string, error) {
+	var sb strings.Builder
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return "", p.errorf("missing field value")
+		}
+		switch c := p.src[p.pos]; {
+		case c == '{':
+			start := p.pos + 1
+			if err := p.skipBraces(); err != nil {
+				return "", err
+			}
+			sb.WriteString(p.src[start : p.pos-1])
+		case c == '"':
+			start := p.pos + 1
+			if err := p.skipQuoted(); err != nil {
+				return "", err
+			}
+			sb.WriteString(p.src[start : p.pos-1])
+		default:
+			word := p.ident()
+			if word == "" {
+				return "", p.errorf("unexpected %q in field value", c)
+			}
+			if macro, ok := p.macros[strings.ToLower(word)]; ok {
+				word = macro
+			}
+			sb.WriteString(word)
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '#' {
+			return sb.String(), nil
+		}
+		p.pos++
+	}
+}
+
+// open consumes the { or ( opening an entry and returns its closing
+// character.
+func (p *bibParser) open() (byte, error) {
+	if p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case '{':
+			p.pos++
+			return '}', nil
+		case '(':
+			p.pos++
+			return ')', nil
+		}
+	}
+	return 0, p.errorf("expected { or ( after @")
+}
+
+// skipBlock skips to after closer at depth zero.
+func (p *bibParser) skipBlock(closer byte) error {
+	depth := 0
+	for ; p.pos < len(p.src); p.pos++ {
+		switch c := p.src[p.pos]; {
+		case c == '{':
+			depth++
+		case c == '}' && depth > 0:
+			depth--
+		case c == closer && depth == 0:
+			p.pos++
+			return nil
+		}
+	}
+	return p.errorf("unterminated block")
+}
+
+// skipBraces skips a balanced {...} group starting at the current position.
+// Errors are reported at the opening brace.
+func (p *bibParser) skipBraces() error {
+	start, depth := p.pos, 0
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	p.pos = start
+	return p.errorf("unbalanced braces")
+}
+
+// skipQuoted skips a "..." string starting at the current position; quotes
+// inside braces do not end it.
+func (p *bibParser) skipQuoted() error {
+	start, depth := p.pos, 0
+	for p.pos++; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '"':
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	p.pos = start
+	return p.errorf("unterminated string")
+}
+
+// key consumes and returns the citation key of an entry, up to the comma
+// following it or closer.
+func (p *bibParser) key(closer byte) string {
+	start := p.pos
+	for p.pos < len(p.src) && p.src[p.pos] != ',' && p.src[p.pos] != closer {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+// ident consumes and returns a name: an entry type, field name, macro or
+// number.
+func (p *bibParser) ident() string {
+	start := p.pos
+	for p.pos < len(p.src) {
+		c := rune(p.src[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune("_-:.+/'", c) {
+			break
+		}
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *bibParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *bibParser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.src[:min(p.pos, len(p.src))], "\n") + 1
+	return fmt.Errorf("line %d: %s", line, fmt.Sprintf(format, args...))
+}
+
+// cleanTeX turns a BibTeX field value into plain text.
+func cleanTeX(s string) string {
+	return strings.Join(strings.Fields(latexReplacer.Replace(s)), " ")
+}
+
+// parseNames parses a BibTeX name list: names separated by "and", each
+// written "First Last" or "Last, First". A name in braces is kept whole, as
+// the name of an organization.
+func parseNames(s string) []Author {
+	var (
+		authors []Author
+		name    []string
+	)
+	flush := func() {
+		if len(name) > 0 && !(len(name) == 1 && name[0] == "others") {
+			authors = append(authors, parseName(strings.Join(name, " ")))
+		}
+		name = nil
+	}
+	for _, word := range splitTop(s, unicode.IsSpace) {
+		if strings.EqualFold(word, "and") {
+			flush()
+			continue
+		}
+		name = append(name, word)
+	}
+	flush()
+	return authors
+}
+
+// parseName parses a single BibTeX name.
+func parseName(s string) Author {
+	if parts := splitTop(s, func(r rune) bool { return r == ',' }); len(parts) > 1 {
+		return Author{Family: cleanTeX(parts[0]), Given: cleanTeX(parts[len(parts)-1])}
+	}
+	words := splitTop(s, unicode.IsSpace)
+	if len(words) == 1 {
+		return Author{Family: cleanTeX(words[0])}
+	}
+	return Author{Family: cleanTeX(words[len(words)-1]), Given: cleanTeX(strings.Join(words[:len(words)-1], " "))}
+}
+
+// splitTop splits s at the runes for which sep is true outside braces,
+// dropping empty parts.
+func splitTop(s string, sep func(rune) bool) []string {
+	var (
+		parts []string
+		depth int
+		start int
+	)
+	add := func(part string) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	for i, r := range s {
+		switch {
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case depth == 0 && sep(r):
+			add(s[start:i])
+			start = i + len(string(r))
+		}
+	}
+	add(s[start:])
+	return parts
+}
@@ -0,0 +1,94 @@
+// Package cite reads bibliographies in BibTeX and CSL-JSON and formats
+// citations of their entries.
+package cite
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Author is an author or editor of a cited work. Organizations have a
+// Family name only.
+type Author struct {
+	Family string
+	Given  string
+}
+
+// String returns the name as written in running text, e.g. "Donald E. Knuth".
+func (a Author) String() string {
+	return strings.TrimSpace(a.Given + " " + a.Family)
+}
+
+// Entry is a work in a bibliography.
+type Entry struct {
+	// Key is the citation key the entry is cited by.
+	Key string
+	// Type is the kind of work as given in the bibliography, e.g. "article"
+	// in BibTeX or "article-journal" in CSL-JSON.
+	Type    string
+	Title   string
+	Authors []Author
+	Year    string
+	// Container is the journal, book or proceedings the work appeared in.
+	Container string
+	Publisher string
+	Volume    string
+	Issue     string
+	Pages     string
+	DOI       string
+	URL       string
+}
+
+// IsBook reports whether the entry is a book rather than a part of one or an
+// article.
+func (e Entry) IsBook() bool {
+	switch strings.ToLower(e.Type) {
+	case "book", "mvbook", "booklet":
+		return true
+	}
+	return false
+}
+
+// Load reads the bibliography at path, parsed as BibTeX if it has the .bib
+// extension and as CSL-JSON if it has the .json extension.
+func Load(path string) ([]Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bibliography: %w", err)
+	}
+	var entries []Entry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".bib", ".bibtex":
+		entries, err = ParseBibTeX(string(data))
+	case ".json":
+		entries, err = ParseCSLJSON(data)
+	default:
+		return nil, fmt.Errorf("unsupported bibliography %s: expected a .bib or .json file", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse bibliography %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Find returns the entry with the given key. Keys are compared
+// case-insensitively, as in BibTeX.
+func Find(entries []Entry, key string) (Entry, bool) {
+	for _, e := range entries {
+		if strings.EqualFold(e.Key, key) {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Keys returns the keys of the entries.
+func Keys(entries []Entry) []string {
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	return keys
+}
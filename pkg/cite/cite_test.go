@@ -0,0 +1,182 @@
+package cite_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/cite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bib = `% A comment
+@string{tcj = "The Computer Journal"}
+
+@comment{ignored {entirely}, "even this"}
+
+@article{knuth1984,
+  author  = {Knuth, Donald E.},
+  title   = {Literate {P}rogramming},
+  journal = tcj,
+  year    = 1984,
+  volume  = {27},
+  number  = {2},
+  pages   = {97--111},
+  doi     = {10.1093/comjnl/27.2.97}
+}
+
+@Book(sicp,
+  author    = "Harold Abelson and Gerald Jay Sussman and {Julie Sussman}",
+  title     = "Structure and Interpretation of " # "Computer Programs",
+  publisher = {MIT Press},
+  date      = {1996-07-25},
+)
+
+@misc{who, author = {{World Health Organization}}, title = {Report \& Review}}
+`
+
+func TestParseBibTeX(t *testing.T) {
+	entries, err := cite.ParseBibTeX(bib)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, cite.Entry{
+		Key:       "knuth1984",
+		Type:      "article",
+		Title:     "Literate Programming",
+		Authors:   []cite.Author{{Family: "Knuth", Given: "Donald E."}},
+		Year:      "1984",
+		Container: "The Computer Journal",
+		Volume:    "27",
+		Issue:     "2",
+		Pages:     "97–111",
+		DOI:       "10.1093/comjnl/27.2.97",
+	}, entries[0])
+
+	sicp := entries[1]
+	assert.Equal(t, "sicp", sicp.Key)
+	assert.True(t, sicp.IsBook())
+	assert.Equal(t, "Structure and Interpretation of Computer Programs", sicp.Title)
+	assert.Equal(t, []cite.Author{
+		{Family: "Abelson", Given: "Harold"},
+		{Family: "Sussman", Given: "Gerald Jay"},
+		{Family: "Julie Sussman"},
+	}, sicp.Authors)
+	assert.Equal(t, "1996", sicp.Year)
+	assert.Equal(t, "MIT Press", sicp.Publisher)
+
+	assert.Equal(t, []cite.Author{{Family: "World Health Organization"}}, entries[2].Authors)
+	assert.Equal(t, "Report & Review", entries[2].Title)
+
+	_, err = cite.ParseBibTeX("@article{broken,\n  title = {Unbalanced\n")
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestParseCSLJSON(t *testing.T) {
+	entries, err := cite.ParseCSLJSON([]byte(`[
+	  {"id": "knuth1984", "type": "article-journal", "title": "Literate Programming",
+	   "author": [{"family": "Knuth", "given": "Donald E."}],
+	   "issued": {"date-parts": [[1984, 5]]},
+	   "container-title": "The Computer Journal", "volume": 27, "issue": "2", "page": "97-111"},
+	  {"id": 42, "type": "report", "title": "Report",
+	   "author": [{"literal": "World Health Organization"}], "issued": {"literal": "2020 spring"}}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "knuth1984", entries[0].Key)
+	assert.Equal(t, []cite.Author{{Family: "Knuth", Given: "Donald E."}}, entries[0].Authors)
+	assert.Equal(t, "1984", entries[0].Year)
+	assert.Equal(t, "27", entries[0].Volume)
+	assert.Equal(t, "The Computer Journal", entries[0].Container)
+	assert.Equal(t, "42", entries[1].Key)
+	assert.Equal(t, "2020", entries[1].Year)
+	assert.Equal(t, []cite.Author{{Family: "World Health Organization"}}, entries[1].Authors)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "refs.bib")
+	require.NoError(t, os.WriteFile(path, []byte(bib), 0644))
+	entries, err := cite.Load(path)
+	require.NoError(t, err)
+
+	e, ok := cite.Find(entries, "KNUTH1984")
+	assert.True(t, ok)
+	assert.Equal(t, "knuth1984", e.Key)
+	_, ok = cite.Find(entries, "missing")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"knuth1984", "sicp", "who"}, cite.Keys(entries))
+
+	txt := filepath.Join(dir, "refs.txt")
+	require.NoError(t, os.WriteFile(txt, []byte(bib), 0644))
+	_, err = cite.Load(txt)
+	assert.ErrorContains(t, err, "unsupported bibliography")
+}
+
+func TestInText(t *testing.T) {
+	one := cite.Entry{Authors: []cite.Author{{Family: "Knuth"}}, Year: "1984"}
+	two := cite.Entry{Authors: []cite.Author{{Family: "Kernighan"}, {Family: "Ritchie"}}, Year: "1978"}
+	three := cite.Entry{Authors: []cite.Author{{Family: "Abelson"}, {Family: "Sussman"}, {Family: "Sussman"}}, Year: "1996"}
+	anonymous := cite.Entry{Title: "Report"}
+
+	tests := []struct {
+		style cite.Style
+		want  []string
+	}{
+		{cite.APA, []string{"(Knuth, 1984)", "(Kernighan & Ritchie, 1978)", "(Abelson et al., 1996)", "(Report, n.d.)"}},
+		{cite.MLA, []string{"(Knuth)", "(Kernighan and Ritchie)", "(Abelson et al.)", "(Report)"}},
+		{cite.Chicago, []string{"(Knuth 1984)", "(Kernighan and Ritchie 1978)", "(Abelson, Sussman, and Sussman 1996)", "(Report n.d.)"}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			for i, e := range []cite.Entry{one, two, three, anonymous} {
+				assert.Equal(t, tt.want[i], cite.InText(e, tt.style))
+			}
+		})
+	}
+}
+
+func TestReference(t *testing.T) {
+	entries, err := cite.ParseBibTeX(bib)
+	require.NoError(t, err)
+	article, book := entries[0], entries[1]
+
+	tests := []struct {
+		style         cite.Style
+		article, book string
+	}{
+		{
+			cite.APA,
+			"Knuth, D. E. (1984). Literate Programming. *The Computer Journal*, *27*(2), 97–111. https://doi.org/10.1093/comjnl/27.2.97",
+			"Abelson, H., Sussman, G. J., & Julie Sussman (1996). *Structure and Interpretation of Computer Programs*. MIT Press.",
+		},
+		{
+			cite.MLA,
+			`Knuth, Donald E. "Literate Programming." *The Computer Journal*, vol. 27, no. 2, 1984, pp. 97–111. https://doi.org/10.1093/comjnl/27.2.97`,
+			"Abelson, Harold, et al. *Structure and Interpretation of Computer Programs*. MIT Press, 1996.",
+		},
+		{
+			cite.Chicago,
+			`Knuth, Donald E. 1984. "Literate Programming." *The Computer Journal* 27 (2): 97–111. https://doi.org/10.1093/comjnl/27.2.97`,
+			"Abelson, Harold, Gerald Jay Sussman, and Julie Sussman. 1996. *Structure and Interpretation of Computer Programs*. MIT Press.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.style), func(t *testing.T) {
+			assert.Equal(t, tt.article, cite.Reference(article, tt.style))
+			assert.Equal(t, tt.book, cite.Reference(book, tt.style))
+		})
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	s, err := cite.ParseStyle("")
+	require.NoError(t, err)
+	assert.Equal(t, cite.APA, s)
+	s, err = cite.ParseStyle("Chicago")
+	require.NoError(t, err)
+	assert.Equal(t, cite.Chicago, s)
+	_, err = cite.ParseStyle("ieee")
+	assert.Error(t, err)
+}
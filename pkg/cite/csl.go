@@ -0,0 +1,98 @@
+package cite
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+// cslItem is an item of a CSL-JSON bibliography, as exported by Zotero and
+// pandoc.
+type cslItem struct {
+	ID             cslString `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Author         []cslName `json:"author"`
+	Editor         []cslName `json:"editor"`
+	Issued         cslDate   `json:"issued"`
+	ContainerTitle string    `json:"container-title"`
+	Publisher      string    `json:"publisher"`
+	Volume         cslString `json:"volume"`
+	Issue          cslString `json:"issue"`
+	Page           cslString `json:"page"`
+	DOI            string    `json:"DOI"`
+	URL            string    `json:"URL"`
+}
+
+type cslName struct {
+	Family  string `json:"family"`
+	Given   string `json:"given"`
+	Literal string `json:"literal"`
+}
+
+type cslDate struct {
+	DateParts [][]cslString `json:"date-parts"`
+	Literal   string        `json:"literal"`
+}
+
+// cslString is a CSL-JSON value given either as a string or as a number.
+type cslString string
+
+func (s *cslString) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = cslString(str)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*s = cslString(n.String())
+	return nil
+}
+
+// ParseCSLJSON parses the items of a CSL-JSON bibliography.
+func ParseCSLJSON(data []byte) ([]Entry, error) {
+	var items []cslItem
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(items))
+	for _, item := range items {
+		names := item.Author
+		if len(names) == 0 {
+			names = item.Editor
+		}
+		authors := make([]Author, 0, len(names))
+		for _, name := range names {
+			if name.Literal != "" {
+				authors = append(authors, Author{Family: name.Literal})
+				continue
+			}
+			authors = append(authors, Author{Family: name.Family, Given: name.Given})
+		}
+		year := ""
+		if parts := item.Issued.DateParts; len(parts) > 0 && len(parts[0]) > 0 {
+			year = string(parts[0][0])
+		} else if lit := item.Issued.Literal; len(lit) >= 4 {
+			if _, err := strconv.Atoi(lit[:4]); err == nil {
+				year = lit[:4]
+			}
+		}
+		entries = append(entries, Entry{
+			Key:       string(item.ID),
+			Type:      item.Type,
+			Title:     item.Title,
+			Authors:   authors,
+			Year:      year,
+			Container: item.ContainerTitle,
+			Publisher: item.Publisher,
+			Volume:    string(item.Volume),
+			Issue:     string(item.Issue),
+			Pages:     string(item.Page),
+			DOI:       item.DOI,
+			URL:       item.URL,
+		})
+	}
+	return entries, nil
+}
@@ -0,0 +1,262 @@
+package cite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style is a citation style.
+type Style string
+
+// The supported citation styles. Chicago is the author-date variant.
+const (
+	APA     Style = "apa"
+	MLA     Style = "mla"
+	Chicago Style = "chicago"
+)
+
+// Styles are the supported citation styles.
+var Styles = []Style{APA, MLA, Chicago}
+
+// ParseStyle returns the style called name; an empty name is APA.
+func ParseStyle(name string) (Style, error) {
+	if name == "" {
+		return APA, nil
+	}
+	for _, s := range Styles {
+		if strings.EqualFold(name, string(s)) {
+			return s, nil
+		}
+	}
+	return "", fmt.Errorf("unknown citation style %q (expected apa, mla or chicago)", name)
+}
+
+// InText returns the parenthetical in-text citation of e, e.g. "(Knuth,
+// 1984)" in APA.
+func InText(e Entry, style Style) string {
+	names := make([]string, len(e.Authors))
+	for i, a := range e.Authors {
+		names[i] = a.Family
+	}
+	year := e.Year
+	if year == "" {
+		year = "n.d."
+	}
+	switch style {
+	case MLA:
+		return "(" + shortNames(names, e.Title, " and ", 2) + ")"
+	case Chicago:
+		return "(" + shortNames(names, e.Title, " and ", 3) + " " + year + ")"
+	default:
+		return "(" + shortNames(names, e.Title, " & ", 2) + ", " + year + ")"
+	}
+}
+
+// shortNames lists up to limit names, joining the last two with and, or
+// abbreviates longer lists with "et al.". Without names the title is used.
+func shortNames(names []string, title, and string, limit int) string {
+	switch {
+	case len(names) == 0:
+		return title
+	case len(names) > limit:
+		return names[0] + " et al."
+	case len(names) == 1:
+		return names[0]
+	case len(names) == 2:
+		return names[0] + and + names[1]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + "," + and + names[len(names)-1]
+	}
+}
+
+// Reference returns the reference list entry of e, with titles of books and
+// journals in Markdown italics.
+func Reference(e Entry, style Style) string {
+	var sb strings.Builder
+	switch style {
+	case MLA:
+		if authors := mlaAuthors(e.Authors); authors != "" {
+			sb.WriteString(sentence(authors) + " ")
+		}
+		sb.WriteString(title(e))
+		var parts []string
+		if e.Container != "" {
+			parts = append(parts, italic(e.Container))
+		}
+		if e.Volume != "" {
+			parts = append(parts, "vol. "+e.Volume)
+		}
+		if e.Issue != "" {
+			parts = append(parts, "no. "+e.Issue)
+		}
+		if e.Publisher != "" && e.Container == "" {
+			parts = append(parts, e.Publisher)
+		}
+		if e.Year != "" {
+			parts = append(parts, e.Year)
+		}
+		if e.Pages != "" {
+			parts = append(parts, "pp. "+e.Pages)
+		}
+		if len(parts) > 0 {
+			sb.WriteString(" " + sentence(strings.Join(parts, ", ")))
+		}
+	case Chicago:
+		year := e.Year
+		if year == "" {
+			year = "n.d."
+		}
+		if authors := chicagoAuthors(e.Authors); authors != "" {
+			sb.WriteString(sentence(authors) + " ")
+		}
+		sb.WriteString(sentence(year) + " " + title(e))
+		if e.Container != "" {
+			source := italic(e.Container)
+			if e.Volume != "" {
+				source += " " + e.Volume
+			}
+			if e.Issue != "" {
+				source += " (" + e.Issue + ")"
+			}
+			if e.Pages != "" {
+				source += ": " + e.Pages
+			}
+			sb.WriteString(" " + sentence(source))
+		} else if e.Publisher != "" {
+			sb.WriteString(" " + sentence(e.Publisher))
+		}
+	default:
+		year := e.Year
+		if year == "" {
+			year = "n.d."
+		}
+		t := e.Title
+		if e.IsBook() {
+			t = italic(t)
+		}
+		if authors := apaAuthors(e.Authors); authors != "" {
+			sb.WriteString(authors + " (" + year + "). " + sentence(t))
+		} else {
+			sb.WriteString(sentence(t) + " (" + year + ").")
+		}
+		if e.Container != "" {
+			source := italic(e.Container)
+			if e.Volume != "" {
+				source += ", " + italic(e.Volume)
+			}
+			if e.Issue != "" {
+				source += "(" + e.Issue + ")"
+			}
+			if e.Pages != "" {
+				source += ", " + e.Pages
+			}
+			sb.WriteString(" " + sentence(source))
+		} else if e.Publisher != "" {
+			sb.WriteString(" " + sentence(e.Publisher))
+		}
+	}
+	if e.DOI != "" {
+		sb.WriteString(" https://doi.org/" + e.DOI)
+	} else if e.URL != "" {
+		sb.WriteString(" " + e.URL)
+	}
+	return sb.String()
+}
+
+// apaAuthors lists the authors as "Knuth, D. E., & Lamport, L.".
+func apaAuthors(authors []Author) string {
+	names := make([]string, len(authors))
+	for i, a := range authors {
+		names[i] = a.Family
+		if a.Given != "" {
+			names[i] += ", " + initials(a.Given)
+		}
+	}
+	switch len(names) {
+	case 0:
+		return ""
+	case 1:
+		return names[0]
+	default:
+		return strings.Join(names[:len(names)-1], ", ") + ", & " + names[len(names)-1]
+	}
+}
+
+// mlaAuthors lists the authors as "Knuth, Donald E., and Leslie Lamport",
+// abbreviating three or more with "et al.".
+func mlaAuthors(authors []Author) string {
+	switch len(authors) {
+	case 0:
+		return ""
+	case 1:
+		return invertedName(authors[0])
+	case 2:
+		return invertedName(authors[0]) + ", and " + authors[1].String()
+	default:
+		return invertedName(authors[0]) + ", et al."
+	}
+}
+
+// chicagoAuthors lists the authors as "Knuth, Donald E., Leslie Lamport, and
+// Edsger W. Dijkstra".
+func chicagoAuthors(authors []Author) string {
+	if len(authors) == 0 {
+		return ""
+	}
+	names := []string{invertedName(authors[0])}
+	for _, a := range authors[1:] {
+		names = append(names, a.String())
+	}
+	if len(names) == 1 {
+		return names[0]
+	}
+	names[len(names)-1] = "and " + names[len(names)-1]
+	return strings.Join(names, ", ")
+}
+
+func invertedName(a Author) string {
+	if a.Given == "" {
+		return a.Family
+	}
+	return a.Family + ", " + a.Given
+}
+
+// initials abbreviates given names, e.g. "Donald Ervin" to "D. E." and
+// "Jean-Paul" to "J.-P.".
+func initials(given string) string {
+	var words []string
+	for _, word := range strings.Fields(given) {
+		var parts []string
+		for _, part := range strings.Split(word, "-") {
+			if r, _ := utf8.DecodeRuneInString(part); r != utf8.RuneError {
+				parts = append(parts, string(unicode.ToUpper(r))+".")
+			}
+		}
+		words = append(words, strings.Join(parts, "-"))
+	}
+	return strings.Join(words, " ")
+}
+
+// title returns the title of e as in MLA and Chicago: italic for books,
+// quoted otherwise.
+func title(e Entry) string {
+	if e.IsBook() {
+		return sentence(italic(e.Title))
+	}
+	return `"` + sentence(e.Title) + `"`
+}
+
+func italic(s string) string {
+	return "*" + s + "*"
+}
+
+// sentence ends s with a period unless it already ends with punctuation.
+func sentence(s string) string {
+	s = strings.TrimSpace(s)
+	if s == "" || strings.HasSuffix(s, ".") || strings.HasSuffix(s, "?") || strings.HasSuffix(s, "!") {
+		return s
+	}
+	return s + "."
+}
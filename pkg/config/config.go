@@ -1,12 +1,30 @@
 package config
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/mitchellh/mapstructure"
 	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/slug"
 )
 
 // Environment variables for configuration overrides.
@@ -16,106 +34,791 @@ const (
 
 // Default configuration values.
 const (
-	defaultEditor    = "nvim"
-	defaultLogLevel  = "info"
-	defaultLogFormat = "text"
-	defaultLogOutput = "stdout"
+	defaultLogLevel     = "info"
+	defaultLogFormat    = "text"
+	defaultLogOutput    = "stdout"
+	defaultLogHeading   = "## Notes"
+	defaultFileMode     = "0644"
+	defaultDirMode      = "0755"
+	defaultSnippetLen   = 60
+	defaultPromptOrder  = "rotate"
+	defaultCiteStyle    = "apa"
+	defaultBookProvider = "openlibrary"
+	defaultLintMaxWords = 2000
+	defaultLintTodoDays = 30
+	defaultPDFPageSize  = "A4"
+	defaultPandoc       = "pandoc"
+	defaultS3Region     = "us-east-1"
+	defaultKeepDaily    = 7
+	defaultKeepWeekly   = 4
+	defaultKeepMonthly  = 12
+	defaultVersions     = 10
+	defaultOnConflict   = "fail"
 )
 
+// defaultDailySections are the sections of daily notes entries are appended to
+// when daily.sections is not set.
+var defaultDailySections = []DailySection{
+	{Name: "Tasks", Prefix: "- [ ] "},
+	{Name: "Log", Timestamp: true},
+	{Name: "Notes"},
+}
+
 // Config represents the main configuration structure.
 type Config struct {
-	General GeneralConfig `mapstructure:"general"`
-	Dir     DirConfig     `mapstructure:"dir"`
-	Log     LogConfig     `mapstructure:"log"`
+	General   GeneralConfig   `mapstructure:"general" yaml:"general"`
+	Dir       DirConfig       `mapstructure:"dir" yaml:"dir"`
+	Log       LogConfig       `mapstructure:"log" yaml:"log"`
+	Daily     DailyConfig     `mapstructure:"daily" yaml:"daily"`
+	Sync      SyncConfig      `mapstructure:"sync" yaml:"sync"`
+	Templates TemplatesConfig `mapstructure:"templates" yaml:"templates"`
+	Search    SearchConfig    `mapstructure:"search" yaml:"search"`
+	Periodic  PeriodicConfig  `mapstructure:"periodic" yaml:"periodic"`
+	Watch     WatchConfig     `mapstructure:"watch" yaml:"watch"`
+	Calendar  CalendarConfig  `mapstructure:"calendar" yaml:"calendar"`
+	Location  LocationConfig  `mapstructure:"location" yaml:"location"`
+	Weather   WeatherConfig   `mapstructure:"weather" yaml:"weather"`
+	Prompts   PromptsConfig   `mapstructure:"prompts" yaml:"prompts"`
+	Cite      CiteConfig      `mapstructure:"cite" yaml:"cite"`
+	Book      BookConfig      `mapstructure:"book" yaml:"book"`
+	Lint      LintConfig      `mapstructure:"lint" yaml:"lint"`
+	Slug      SlugConfig      `mapstructure:"slug" yaml:"slug"`
+	Export    ExportConfig    `mapstructure:"export" yaml:"export"`
+	Backup    BackupConfig    `mapstructure:"backup" yaml:"backup"`
+	// Alias maps a short command name to the command line it expands to,
+	// e.g. "t" -> "zet new --stdin".
+	Alias map[string]string `mapstructure:"alias" yaml:"alias,omitempty"`
+	// ID maps a note type to the name of the ID generator used for new notes
+	// of that type, e.g. "zettel" -> "ulid".
+	ID map[string]string `mapstructure:"id" yaml:"id,omitempty"`
+	// Habits are the names of the habits checked in daily notes, in order.
+	Habits []string `mapstructure:"habits" yaml:"habits,omitempty"`
+	// Ignore lists globs, as in .exoignore, of the files and directories of
+	// the vault that search, the index, stats, lint and export leave out,
+	// e.g. "archive/" or "*.excalidraw.md".
+	Ignore []string `mapstructure:"ignore" yaml:"ignore,omitempty"`
+	// Views are saved queries run by "exo view", by name.
+	Views map[string]ViewConfig `mapstructure:"views" yaml:"views,omitempty"`
+	// Schema maps a note type to the frontmatter fields of notes of that type,
+	// by field name.
+	Schema map[string]map[string]FieldConfig `mapstructure:"schema" yaml:"schema,omitempty"`
+
+	// source is the configuration file the values were read from, if any.
+	source string
+	// origins are the origins of the values not set by default, by dotted
+	// lowercase key.
+	origins map[string]Origin
+	// overridden are the values flags override, as they were before, by
+	// dotted lowercase key.
+	overridden map[string]*yaml.Node
+	// rebased are the directory settings RebaseDirs rewrote, by dotted key.
+	rebased map[string]bool
 }
 
 // GeneralConfig holds general configuration values.
 type GeneralConfig struct {
-	Editor string `mapstructure:"editor"`
+	Editor string `mapstructure:"editor" yaml:"editor"`
+	// ReadOnly makes exo refuse to modify the vault.
+	ReadOnly bool `mapstructure:"read_only" yaml:"read_only"`
+	// Timestamps makes exo keep the created and modified fields of the
+	// frontmatter of notes up to date when it saves them.
+	Timestamps bool `mapstructure:"timestamps" yaml:"timestamps"`
+	// Versions is how many previous versions of each note are kept in
+	// data_home/.versions when a note is overwritten or changed in the
+	// editor; 0 keeps none.
+	Versions int `mapstructure:"versions" yaml:"versions"`
+	// OnConflict is what saving a note changed on disk since exo read it
+	// does: fail, merge the changes with conflict markers, copy the note to a
+	// .conflict file next to it, or overwrite the changes.
+	OnConflict string `mapstructure:"on_conflict" yaml:"on_conflict"`
 }
 
 // DirConfig holds directory-related configuration.
 type DirConfig struct {
-	DataHome    string `mapstructure:"data_home"`
-	TemplateDir string `mapstructure:"template_dir"`
-	PeriodicDir string `mapstructure:"periodic_dir"`
-	ZettelDir   string `mapstructure:"zettel_dir"`
-	ProjectsDir string `mapstructure:"projects_dir"`
-	InboxDir    string `mapstructure:"inbox_dir"`
-	IdeaDir     string `mapstructure:"idea_dir"`
+	DataHome    string `mapstructure:"data_home" yaml:"data_home"`
+	TemplateDir string `mapstructure:"template_dir" yaml:"template_dir"`
+	PeriodicDir string `mapstructure:"periodic_dir" yaml:"periodic_dir"`
+	ZettelDir   string `mapstructure:"zettel_dir" yaml:"zettel_dir"`
+	ProjectsDir string `mapstructure:"projects_dir" yaml:"projects_dir"`
+	InboxDir    string `mapstructure:"inbox_dir" yaml:"inbox_dir"`
+	IdeaDir     string `mapstructure:"idea_dir" yaml:"idea_dir"`
+	PluginDir   string `mapstructure:"plugin_dir" yaml:"plugin_dir"`
+	ArchiveDir  string `mapstructure:"archive_dir" yaml:"archive_dir"`
+	// LiteratureDir is where literature notes on cited sources are created.
+	LiteratureDir string `mapstructure:"literature_dir" yaml:"literature_dir"`
+	// StateDir is where exo keeps the state of the vault, such as recent
+	// notes and review schedules; it defaults to a directory of the vault
+	// under $XDG_STATE_HOME/exo.
+	StateDir string `mapstructure:"state_dir" yaml:"state_dir"`
+	// CacheDir is where exo keeps data it can rebuild from the notes, such
+	// as the note index; it defaults to a directory of the vault under
+	// $XDG_CACHE_HOME/exo.
+	CacheDir string `mapstructure:"cache_dir" yaml:"cache_dir"`
+}
+
+// legacyDir is the directory, relative to data_home, state and cache files
+// were kept in before state_dir and cache_dir.
+const legacyDir = ".exo"
+
+// StatePath returns the path of the state file name in state_dir. A file of
+// that name left in data_home/.exo by earlier versions is moved there first.
+func (d DirConfig) StatePath(name string) string {
+	return runtimePath(d.StateDir, d.DataHome, name)
+}
+
+// CachePath returns the path of the cache file name in cache_dir. A file of
+// that name left in data_home/.exo by earlier versions is moved there first.
+func (d DirConfig) CachePath(name string) string {
+	return runtimePath(d.CacheDir, d.DataHome, name)
+}
+
+// runtimePath returns the path of name in dir, moving the file of that name
+// in the legacy directory of dataHome there when dir has none. The legacy
+// file is kept in use when it cannot be moved, as on a read-only vault.
+func runtimePath(dir, dataHome, name string) string {
+	path := filepath.Join(dir, name)
+	legacy := filepath.Join(dataHome, legacyDir, name)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		return path
+	}
+	if _, err := os.Stat(legacy); err != nil {
+		return path
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return legacy
+	}
+	if err := os.Rename(legacy, path); err != nil {
+		return legacy
+	}
+	return path
+}
+
+// vaultDir returns the name of the directory of the vault at dataHome under
+// the state and cache directories of exo: the name of the vault and a hash of
+// its path, so that vaults of the same name do not share state.
+func vaultDir(dataHome string) string {
+	sum := sha256.Sum256([]byte(dataHome))
+	return filepath.Base(dataHome) + "-" + hex.EncodeToString(sum[:4])
+}
+
+// ViewConfig defines a saved query: the notes matching every filter set.
+type ViewConfig struct {
+	// Query matches notes with the query language of "exo search", e.g.
+	// "tag:project AND modified:>2024-01-01".
+	Query string `mapstructure:"query" yaml:"query,omitempty"`
+	// Type matches notes of a type, e.g. "zettel".
+	Type string `mapstructure:"type" yaml:"type,omitempty"`
+	// Tag matches notes with a tag, e.g. "go".
+	Tag string `mapstructure:"tag" yaml:"tag,omitempty"`
+	// Text matches notes containing every word of the text.
+	Text string `mapstructure:"text" yaml:"text,omitempty"`
+	// Since and Before bound the creation date of the notes, given as a date
+	// (YYYY-MM-DD) or an age such as "30d" or "6m".
+	Since  string `mapstructure:"since" yaml:"since,omitempty"`
+	Before string `mapstructure:"before" yaml:"before,omitempty"`
+	// Sort orders the notes by "modified" (the default, newest first),
+	// "created" (newest first) or "title".
+	Sort string `mapstructure:"sort" yaml:"sort,omitempty"`
+	// Limit caps the number of notes; zero lists them all.
+	Limit int `mapstructure:"limit" yaml:"limit,omitempty"`
+	// Note is the note, relative to data_home, the results are written to by
+	// "exo view --write" and refreshed by "exo watch".
+	Note string `mapstructure:"note" yaml:"note,omitempty"`
 }
 
 // LogConfig holds logging configuration.
 type LogConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
-	Output string `mapstructure:"output"`
+	Level  string `mapstructure:"level" yaml:"level"`
+	Format string `mapstructure:"format" yaml:"format"`
+	Output string `mapstructure:"output" yaml:"output"`
+}
+
+// DailyConfig holds daily note configuration.
+type DailyConfig struct {
+	// LogHeading is the heading under which "exo log" appends entries when
+	// it is set or Sections has no Log section.
+	LogHeading string `mapstructure:"log_heading" yaml:"log_heading"`
+	// Sections are the sections "exo day append" adds entries to, in the order
+	// they are laid out in daily notes.
+	Sections []DailySection `mapstructure:"sections" yaml:"sections,omitempty"`
+}
+
+// DailySection defines a section of daily notes.
+type DailySection struct {
+	// Name identifies the section, case-insensitively, e.g. "Log".
+	Name string `mapstructure:"name" yaml:"name"`
+	// Heading introduces the section; it defaults to "## <Name>".
+	Heading string `mapstructure:"heading" yaml:"heading,omitempty"`
+	// Prefix starts every entry; it defaults to "- ", e.g. "- [ ] " for tasks.
+	Prefix string `mapstructure:"prefix" yaml:"prefix,omitempty"`
+	// Timestamp adds the time (HH:MM) to every entry.
+	Timestamp bool `mapstructure:"timestamp" yaml:"timestamp,omitempty"`
+	// Template is the text a section is created with when it is missing.
+	Template string `mapstructure:"template" yaml:"template,omitempty"`
+}
+
+// HeadingLine returns the heading introducing the section.
+func (s DailySection) HeadingLine() string {
+	if s.Heading != "" {
+		return s.Heading
+	}
+	return "## " + s.Name
+}
+
+// EntryPrefix returns the text every entry of the section starts with.
+func (s DailySection) EntryPrefix() string {
+	if s.Prefix != "" {
+		return s.Prefix
+	}
+	return "- "
+}
+
+// Section returns the section called name, compared case-insensitively.
+func (c DailyConfig) Section(name string) (DailySection, bool) {
+	for _, s := range c.Sections {
+		if strings.EqualFold(s.Name, strings.TrimSpace(name)) {
+			return s, true
+		}
+	}
+	return DailySection{}, false
+}
+
+// SectionNames returns the names of the sections, in order.
+func (c DailyConfig) SectionNames() []string {
+	names := make([]string, len(c.Sections))
+	for i, s := range c.Sections {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// SyncConfig holds Git sync configuration.
+type SyncConfig struct {
+	// Remote is the URL of the Git remote the vault is pushed to and pulled from.
+	Remote string `mapstructure:"remote" yaml:"remote"`
+	// AutoCommit commits changes after every command that modifies notes.
+	AutoCommit bool `mapstructure:"auto_commit" yaml:"auto_commit"`
+}
+
+// TemplatesConfig holds template installation settings.
+type TemplatesConfig struct {
+	// FileMode is the octal permission mode of installed template files, e.g. "0644".
+	FileMode string `mapstructure:"file_mode" yaml:"file_mode"`
+	// DirMode is the octal permission mode of a created template directory, e.g. "2775"
+	// for a group-shared directory whose files inherit its group.
+	DirMode string `mapstructure:"dir_mode" yaml:"dir_mode"`
+	// ProfileDir holds the templates used in every vault, for the templates
+	// missing from dir.template_dir. Empty disables it.
+	ProfileDir string `mapstructure:"profile_dir" yaml:"profile_dir"`
+	// Packs maps the name of a template pack to where it is fetched from: a
+	// local directory, a git repository or the URL of a .tar.gz archive.
+	Packs map[string]string `mapstructure:"packs" yaml:"packs,omitempty"`
+}
+
+// PeriodicConfig holds settings for periodic notes.
+type PeriodicConfig struct {
+	Daily  PeriodicDailyConfig  `mapstructure:"daily" yaml:"daily"`
+	Weekly PeriodicWeeklyConfig `mapstructure:"weekly" yaml:"weekly"`
+}
+
+// PeriodicDailyConfig holds settings applied when daily notes are created.
+type PeriodicDailyConfig struct {
+	// CarryOver is the number of previous daily notes whose unchecked tasks are
+	// moved into today's note when it is created; 0 disables carrying tasks over.
+	CarryOver int `mapstructure:"carry_over" yaml:"carry_over"`
+	// Providers names the template data providers, such as "weather" or
+	// "moon", whose data is given to the template of new daily notes.
+	Providers []string `mapstructure:"providers" yaml:"providers,omitempty"`
+	// LinkWeek, LinkMonth and LinkQuarter link new daily notes to the weekly,
+	// monthly and quarterly notes of their day, creating those when missing.
+	LinkWeek    bool `mapstructure:"link_week" yaml:"link_week"`
+	LinkMonth   bool `mapstructure:"link_month" yaml:"link_month"`
+	LinkQuarter bool `mapstructure:"link_quarter" yaml:"link_quarter"`
+	// TemplateOverrides pick another template than "day" for the daily notes
+	// of some days, such as holidays; the first matching override applies.
+	TemplateOverrides []TemplateOverride `mapstructure:"template_overrides" yaml:"template_overrides,omitempty"`
+}
+
+// TemplateOverride is the template daily notes are created from on the days
+// it matches: those from From to To, both included, and on one of Weekdays.
+// Dates are YYYY-MM-DD, or MM-DD for every year; a yearly range may span the
+// new year, e.g. from 12-24 to 01-01.
+type TemplateOverride struct {
+	Template string `mapstructure:"template" yaml:"template"`
+	From     string `mapstructure:"from" yaml:"from,omitempty"`
+	// To defaults to From, for a single day.
+	To string `mapstructure:"to" yaml:"to,omitempty"`
+	// Weekdays are English day names, e.g. "saturday".
+	Weekdays []string `mapstructure:"weekdays" yaml:"weekdays,omitempty"`
+}
+
+// Matches reports whether the override applies to the daily note of date.
+func (o TemplateOverride) Matches(date time.Time) bool {
+	if len(o.Weekdays) > 0 && !slices.ContainsFunc(o.Weekdays, func(day string) bool {
+		return strings.EqualFold(strings.TrimSpace(day), date.Weekday().String())
+	}) {
+		return false
+	}
+	if o.From == "" {
+		return true
+	}
+	to := o.To
+	if to == "" {
+		to = o.From
+	}
+	if len(o.From) == len("01-02") {
+		day := date.Format("01-02")
+		if o.From <= to {
+			return o.From <= day && day <= to
+		}
+		return day >= o.From || day <= to
+	}
+	day := date.Format("2006-01-02")
+	return o.From <= day && day <= to
+}
+
+// validate checks the dates and weekdays of the override.
+func (o TemplateOverride) validate() error {
+	if strings.TrimSpace(o.Template) == "" {
+		return fmt.Errorf("template cannot be empty")
+	}
+	if o.From == "" && o.To == "" && len(o.Weekdays) == 0 {
+		return fmt.Errorf("from or weekdays is required")
+	}
+	if o.From == "" && o.To != "" {
+		return fmt.Errorf("to requires from")
+	}
+	for _, date := range []string{o.From, o.To} {
+		if date == "" {
+			continue
+		}
+		_, yearErr := time.Parse("2006-01-02", date)
+		_, dayErr := time.Parse("01-02", date)
+		if yearErr != nil && dayErr != nil {
+			return fmt.Errorf("invalid date %q (expected YYYY-MM-DD or MM-DD)", date)
+		}
+	}
+	if o.To != "" && len(o.To) != len(o.From) {
+		return fmt.Errorf("from and to must both be YYYY-MM-DD or MM-DD")
+	}
+	if len(o.From) > len("01-02") && o.To != "" && o.To < o.From {
+		return fmt.Errorf("to is before from")
+	}
+	for _, day := range o.Weekdays {
+		if !slices.ContainsFunc(weekdays, func(w time.Weekday) bool { return strings.EqualFold(strings.TrimSpace(day), w.String()) }) {
+			return fmt.Errorf("invalid weekday %q", day)
+		}
+	}
+	return nil
+}
+
+// dateToString decodes the dates YAML reads from unquoted YYYY-MM-DD values
+// into strings, as settings hold them.
+func dateToString(from, to reflect.Type, data interface{}) (interface{}, error) {
+	if t, ok := data.(time.Time); ok && to.Kind() == reflect.String {
+		return t.Format("2006-01-02"), nil
+	}
+	return data, nil
+}
+
+// weekdays are the days of the week, as named in template overrides.
+var weekdays = []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday}
+
+// PeriodicWeeklyConfig holds settings applied when weekly notes are created.
+type PeriodicWeeklyConfig struct {
+	// LinkDays lists the daily notes of the week in new weekly notes whose
+	// template does not link to them.
+	LinkDays bool `mapstructure:"link_days" yaml:"link_days"`
+}
+
+// WatchConfig holds settings for "exo watch".
+type WatchConfig struct {
+	// Hooks are shell commands run for every note changed while watching, with
+	// the note in EXO_NOTE and the change (created, modified or removed) in
+	// EXO_EVENT.
+	Hooks []string `mapstructure:"hooks" yaml:"hooks,omitempty"`
+}
+
+// CalendarConfig holds settings for "exo meeting" and the "agenda" template
+// data provider.
+type CalendarConfig struct {
+	// Source is the calendar events are read from: the path of an ICS file, or
+	// the URL of an ICS feed or a CalDAV calendar.
+	Source string `mapstructure:"source" yaml:"source"`
+	// Username is sent to Source with basic authentication, together with the
+	// password in the EXO_CALENDAR_PASSWORD environment variable.
+	Username string `mapstructure:"username" yaml:"username,omitempty"`
+}
+
+// LocationConfig holds the place given to templates by the "location"
+// provider, whose coordinates the "weather" provider forecasts for.
+type LocationConfig struct {
+	Name      string  `mapstructure:"name" yaml:"name,omitempty"`
+	Latitude  float64 `mapstructure:"latitude" yaml:"latitude,omitempty"`
+	Longitude float64 `mapstructure:"longitude" yaml:"longitude,omitempty"`
+}
+
+// WeatherConfig holds settings for the "weather" template data provider.
+type WeatherConfig struct {
+	// URL is the Open-Meteo compatible forecast API queried; it defaults to
+	// the Open-Meteo API.
+	URL string `mapstructure:"url" yaml:"url,omitempty"`
+	// Units is "celsius" or "fahrenheit"; it defaults to celsius.
+	Units string `mapstructure:"units" yaml:"units,omitempty"`
+}
+
+// PromptsConfig holds settings for "exo prompts" and the "prompts" template
+// data provider.
+type PromptsConfig struct {
+	// Path is the file the journaling prompts are kept in, one per line, or a
+	// directory of such files.
+	Path string `mapstructure:"path" yaml:"path"`
+	// Count is the number of prompts picked for each daily note.
+	Count int `mapstructure:"count" yaml:"count"`
+	// Order is "rotate" to go through the prompts in turn or "random".
+	Order string `mapstructure:"order" yaml:"order"`
+}
+
+// CiteConfig holds settings for "exo cite".
+type CiteConfig struct {
+	// Bibliography is the BibTeX (.bib) or CSL-JSON (.json) file citations
+	// are looked up in.
+	Bibliography string `mapstructure:"bibliography" yaml:"bibliography,omitempty"`
+	// Style is the citation style: "apa", "mla" or "chicago".
+	Style string `mapstructure:"style" yaml:"style"`
+}
+
+// BookConfig holds settings for "exo book".
+type BookConfig struct {
+	// Provider is the name of the metadata provider books are looked up
+	// with; it defaults to "openlibrary".
+	Provider string `mapstructure:"provider" yaml:"provider"`
+	// URL is the base URL of the provider's API; it defaults to the public
+	// API of the provider.
+	URL string `mapstructure:"url" yaml:"url,omitempty"`
+}
+
+// LintConfig holds settings for "exo lint".
+type LintConfig struct {
+	// Disabled lists the rules "exo lint" does not run.
+	Disabled []string `mapstructure:"disabled" yaml:"disabled,omitempty"`
+	// RequiredFields maps a note type to the frontmatter fields notes of that
+	// type must have; the fields under "*" are required of every note.
+	RequiredFields map[string][]string `mapstructure:"required_fields" yaml:"required_fields,omitempty"`
+	// MaxWords is the number of words above which a note is too long; zero
+	// disables the check.
+	MaxWords int `mapstructure:"max_words" yaml:"max_words"`
+	// TodoDays is the age in days above which open TODO items are reported;
+	// zero disables the check.
+	TodoDays int `mapstructure:"todo_days" yaml:"todo_days"`
+}
+
+// SlugConfig holds how the file names of new notes are made from their
+// titles.
+type SlugConfig struct {
+	// Style is "title", which keeps the title as typed, "kebab", "snake" or
+	// "camel".
+	Style string `mapstructure:"style" yaml:"style"`
+	// MaxLength is the number of characters above which file names are cut;
+	// zero does not cut them.
+	MaxLength int `mapstructure:"max_length" yaml:"max_length"`
+	// StopWords drops common English words such as "a" and "the".
+	StopWords bool `mapstructure:"stop_words" yaml:"stop_words"`
+	// DatePrefix is a Go time layout, e.g. "2006-01-02", the creation date is
+	// written with before the title.
+	DatePrefix string `mapstructure:"date_prefix" yaml:"date_prefix,omitempty"`
+}
+
+// Options returns the options of slug.Make.
+func (s SlugConfig) Options() slug.Options {
+	return slug.Options{
+		Style:      slug.Style(s.Style),
+		MaxLength:  s.MaxLength,
+		StopWords:  s.StopWords,
+		DatePrefix: s.DatePrefix,
+	}
+}
+
+// ExportConfig holds settings for "exo export", by format.
+type ExportConfig struct {
+	PDF PDFConfig `mapstructure:"pdf" yaml:"pdf"`
+	// Pandoc is the pandoc executable, by name or path, formats are converted
+	// with by "exo export --via pandoc".
+	Pandoc string `mapstructure:"pandoc" yaml:"pandoc"`
+	// Formats holds the defaults of pandoc output formats, by format name,
+	// e.g. "docx".
+	Formats map[string]ExportFormatConfig `mapstructure:"formats" yaml:"formats,omitempty"`
+}
+
+// ExportFormatConfig holds the defaults of a pandoc output format.
+type ExportFormatConfig struct {
+	// Args are passed to pandoc, e.g. ["--reference-doc=ref.docx"].
+	Args []string `mapstructure:"args" yaml:"args,omitempty"`
+	// Extension is the extension of the files written; it defaults to the
+	// usual one of the format.
+	Extension string `mapstructure:"extension" yaml:"extension,omitempty"`
+}
+
+// PDFConfig holds settings for "exo export pdf".
+type PDFConfig struct {
+	// Engine is the wkhtmltopdf or Chromium executable, by name or path;
+	// empty looks for one on PATH.
+	Engine string `mapstructure:"engine" yaml:"engine,omitempty"`
+	// PageSize is the CSS page size, e.g. "A4" or "letter".
+	PageSize string `mapstructure:"page_size" yaml:"page_size"`
+	// CSS is a stylesheet file replacing the default style of documents.
+	CSS string `mapstructure:"css" yaml:"css,omitempty"`
+	// Template is an HTML page template file replacing the default layout.
+	Template string `mapstructure:"template" yaml:"template,omitempty"`
+}
+
+// BackupConfig holds settings for "exo backup".
+type BackupConfig struct {
+	// Remote is the backend the data home is backed up to, e.g. "s3" or
+	// "webdav".
+	Remote string `mapstructure:"remote" yaml:"remote,omitempty"`
+	// Encrypt encrypts backups with the passphrase in the
+	// EXO_BACKUP_PASSPHRASE environment variable.
+	Encrypt bool         `mapstructure:"encrypt" yaml:"encrypt"`
+	S3      S3Config     `mapstructure:"s3" yaml:"s3"`
+	WebDAV  WebDAVConfig `mapstructure:"webdav" yaml:"webdav"`
+	// SnapshotDir is the directory local snapshots of the data home are kept
+	// in; it defaults to $XDG_STATE_HOME/exo/snapshots.
+	SnapshotDir string `mapstructure:"snapshot_dir" yaml:"snapshot_dir"`
+	// Keep is the retention policy of local snapshots.
+	Keep RetentionConfig `mapstructure:"keep" yaml:"keep"`
+}
+
+// RetentionConfig is how many snapshots are kept: the newest Last ones, and
+// the newest one of each of the latest Daily days, Weekly weeks, Monthly
+// months and Yearly years. Snapshots are never removed when all are zero.
+type RetentionConfig struct {
+	Last    int `mapstructure:"last" yaml:"last"`
+	Daily   int `mapstructure:"daily" yaml:"daily"`
+	Weekly  int `mapstructure:"weekly" yaml:"weekly"`
+	Monthly int `mapstructure:"monthly" yaml:"monthly"`
+	Yearly  int `mapstructure:"yearly" yaml:"yearly"`
+}
+
+// S3Config holds the settings of the S3-compatible backup remote. Credentials
+// are read from the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment
+// variables.
+type S3Config struct {
+	// Endpoint is the URL of the service; it defaults to AWS S3 in Region.
+	Endpoint string `mapstructure:"endpoint" yaml:"endpoint,omitempty"`
+	Region   string `mapstructure:"region" yaml:"region"`
+	Bucket   string `mapstructure:"bucket" yaml:"bucket,omitempty"`
+	// Prefix is prepended to the keys of the backup, e.g. "exo/".
+	Prefix string `mapstructure:"prefix" yaml:"prefix,omitempty"`
+}
+
+// WebDAVConfig holds the settings of the WebDAV backup remote.
+type WebDAVConfig struct {
+	// URL is the collection the backup is stored in.
+	URL string `mapstructure:"url" yaml:"url,omitempty"`
+	// Username is sent with basic authentication, together with the password
+	// in the EXO_BACKUP_PASSWORD environment variable.
+	Username string `mapstructure:"username" yaml:"username,omitempty"`
+}
+
+// FieldConfig describes a frontmatter field of a note type schema.
+type FieldConfig struct {
+	// Type is string, number, bool, date or list; empty accepts any value.
+	Type string `mapstructure:"type" yaml:"type,omitempty"`
+	// Required fields must be set on every note of the type.
+	Required bool `mapstructure:"required" yaml:"required,omitempty"`
+}
+
+// SearchConfig holds settings for note listings and completions.
+type SearchConfig struct {
+	// SnippetLength is the maximum length of the note preview shown next to
+	// listed notes; 0 disables previews.
+	SnippetLength int `mapstructure:"snippet_length" yaml:"snippet_length"`
+}
+
+// Modes returns the parsed file and directory modes. Empty settings yield the
+// defaults of 0644 and 0755.
+func (t TemplatesConfig) Modes() (file, dir os.FileMode, err error) {
+	if t.FileMode == "" {
+		t.FileMode = defaultFileMode
+	}
+	if t.DirMode == "" {
+		t.DirMode = defaultDirMode
+	}
+	if file, err = ParseMode(t.FileMode); err != nil {
+		return 0, 0, fmt.Errorf("invalid templates.file_mode: %w", err)
+	}
+	if dir, err = ParseMode(t.DirMode); err != nil {
+		return 0, 0, fmt.Errorf("invalid templates.dir_mode: %w", err)
+	}
+	return file, dir, nil
+}
+
+// ParseMode parses an octal permission mode such as "0644" or "2775". The setuid,
+// setgid and sticky bits are mapped to their os.FileMode equivalents.
+func ParseMode(s string) (os.FileMode, error) {
+	n, err := strconv.ParseUint(strings.TrimSpace(s), 8, 32)
+	if err != nil || n > 07777 {
+		return 0, fmt.Errorf("%q is not an octal permission mode", s)
+	}
+	mode := os.FileMode(n) & os.ModePerm
+	if n&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if n&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if n&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
 }
 
 // NewConfig creates a new configuration instance.
 // If configPath is non‑empty, it attempts to load configuration from that file,
 // otherwise defaults (plus environment overrides) are used.
 func NewConfig(configPath string) (*Config, error) {
+	return NewConfigIn(configPath, fs.OSEnv())
+}
+
+// NewConfigIn is NewConfig resolving the default directories and the paths
+// of the settings in env, e.g. under %APPDATA% on Windows.
+func NewConfigIn(configPath string, env fs.Env) (*Config, error) {
+	return load(configPath, env, true)
+}
+
+// Defaults returns the configuration in effect without a configuration file:
+// the defaults, with the overrides of the environment variables of env.
+func Defaults(env fs.Env) (*Config, error) {
+	return load("", env, false)
+}
+
+// load loads the configuration in env, from the configuration file at
+// configPath, or found in the configuration directory, when readFile is set.
+func load(configPath string, env fs.Env, readFile bool) (*Config, error) {
 	v := viper.New()
 	v.SetConfigType("yaml")
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
+	if env.Home == "" {
+		return nil, fmt.Errorf("failed to get user home directory")
 	}
 
 	// Set default values.
-	v.SetDefault("general.editor", defaultEditor)
+	v.SetDefault("general.editor", env.DefaultEditor())
+	v.SetDefault("general.versions", defaultVersions)
+	v.SetDefault("general.on_conflict", defaultOnConflict)
 	v.SetDefault("log.level", defaultLogLevel)
 	v.SetDefault("log.format", defaultLogFormat)
 	v.SetDefault("log.output", defaultLogOutput)
+	v.SetDefault("daily.log_heading", defaultLogHeading)
+	v.SetDefault("daily.sections", defaultDailySections)
+	v.SetDefault("templates.file_mode", defaultFileMode)
+	v.SetDefault("templates.dir_mode", defaultDirMode)
+	v.SetDefault("templates.profile_dir", env.Join(env.ConfigDir("exo"), "templates"))
+	v.SetDefault("search.snippet_length", defaultSnippetLen)
+	v.SetDefault("periodic.daily.carry_over", 0)
+	v.SetDefault("periodic.daily.link_week", true)
+	v.SetDefault("periodic.daily.link_month", true)
+	v.SetDefault("periodic.daily.link_quarter", true)
+	v.SetDefault("periodic.weekly.link_days", true)
+	v.SetDefault("prompts.count", 1)
+	v.SetDefault("prompts.order", defaultPromptOrder)
+	v.SetDefault("cite.style", defaultCiteStyle)
+	v.SetDefault("book.provider", defaultBookProvider)
+	v.SetDefault("lint.max_words", defaultLintMaxWords)
+	v.SetDefault("lint.todo_days", defaultLintTodoDays)
+	v.SetDefault("slug.style", string(slug.Title))
+	v.SetDefault("export.pdf.page_size", defaultPDFPageSize)
+	v.SetDefault("export.pandoc", defaultPandoc)
+	v.SetDefault("backup.s3.region", defaultS3Region)
+	v.SetDefault("backup.snapshot_dir", env.Join(env.StateDir("exo"), "snapshots"))
+	v.SetDefault("backup.keep.daily", defaultKeepDaily)
+	v.SetDefault("backup.keep.weekly", defaultKeepWeekly)
+	v.SetDefault("backup.keep.monthly", defaultKeepMonthly)
 
-	dataHome := getDataHome(home)
+	dataHome := getDataHome(env)
 	v.SetDefault("dir.data_home", dataHome)
-	v.SetDefault("dir.template_dir", filepath.Join(dataHome, "templates"))
-	v.SetDefault("dir.periodic_dir", filepath.Join(dataHome, "periodic"))
-	v.SetDefault("dir.zettel_dir", filepath.Join(dataHome, "zettel"))
-	v.SetDefault("dir.projects_dir", filepath.Join(dataHome, "projects"))
-	v.SetDefault("dir.inbox_dir", filepath.Join(dataHome, "0-inbox"))
-	v.SetDefault("dir.idea_dir", filepath.Join(dataHome, "ideas"))
-
-	// If a config file is provided, read it.
-	if configPath != "" {
-		if _, err := os.Stat(configPath); err != nil {
-			return nil, fmt.Errorf("config file not accessible: %w", err)
-		}
-		v.SetConfigFile(configPath)
-		if err := v.ReadInConfig(); err != nil {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
-		}
-	} else {
-		// Otherwise, add the default config search path.
-		v.AddConfigPath(filepath.Join(home, ".config", "exo"))
-	}
+	v.SetDefault("dir.template_dir", env.Join(dataHome, "templates"))
+	v.SetDefault("dir.periodic_dir", env.Join(dataHome, "periodic"))
+	v.SetDefault("dir.zettel_dir", env.Join(dataHome, "zettel"))
+	v.SetDefault("dir.projects_dir", env.Join(dataHome, "projects"))
+	v.SetDefault("dir.inbox_dir", env.Join(dataHome, "0-inbox"))
+	v.SetDefault("dir.idea_dir", env.Join(dataHome, "ideas"))
+	v.SetDefault("dir.plugin_dir", env.Join(dataHome, "plugins"))
+	v.SetDefault("dir.archive_dir", env.Join(dataHome, "archive"))
+	v.SetDefault("dir.literature_dir", env.Join(dataHome, "literature"))
+	v.SetDefault("prompts.path", env.Join(dataHome, "prompts.md"))
 
-	if err := v.ReadInConfig(); err != nil {
-		// Only return error if specific config file was requested
+	if readFile {
+		// If a config file is provided, read it.
 		if configPath != "" {
-			return nil, fmt.Errorf("failed to read config file: %w", err)
+			if _, err := os.Stat(configPath); err != nil {
+				return nil, exoerrors.New(exoerrors.Config, "config file not accessible: %w", err)
+			}
+			v.SetConfigFile(configPath)
+			if err := v.ReadInConfig(); err != nil {
+				return nil, exoerrors.New(exoerrors.Config, "failed to read config file: %w", err)
+			}
+		} else {
+			// Otherwise, add the default config search path.
+			v.AddConfigPath(env.ConfigDir("exo"))
+		}
+
+		if err := v.ReadInConfig(); err != nil {
+			// A missing file in the configuration directory leaves the
+			// defaults; a file that cannot be read or parsed is an error.
+			var notFound viper.ConfigFileNotFoundError
+			if configPath != "" || !errors.As(err, &notFound) {
+				return nil, exoerrors.New(exoerrors.Config, "failed to read config file: %w", err)
+			}
 		}
 	}
 
 	var cfg Config
-	if err := v.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	hooks := mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		dateToString,
+	)
+	if err := v.Unmarshal(&cfg, viper.DecodeHook(hooks)); err != nil {
+		return nil, exoerrors.New(exoerrors.Config, "failed to unmarshal config: %w", err)
 	}
+	cfg.source = v.ConfigFileUsed()
+	cfg.origins = loadOrigins(v, env)
 
 	// Expand and sanitize directory paths.
-	cfg.Dir.DataHome = sanitizePath(cfg.Dir.DataHome, home)
-	cfg.Dir.TemplateDir = sanitizePath(cfg.Dir.TemplateDir, home)
-	cfg.Dir.PeriodicDir = sanitizePath(cfg.Dir.PeriodicDir, home)
-	cfg.Dir.ZettelDir = sanitizePath(cfg.Dir.ZettelDir, home)
-	cfg.Dir.ProjectsDir = sanitizePath(cfg.Dir.ProjectsDir, home)
-	cfg.Dir.InboxDir = sanitizePath(cfg.Dir.InboxDir, home)
-	cfg.Dir.IdeaDir = sanitizePath(cfg.Dir.IdeaDir, home)
+	cfg.Dir.DataHome = env.Sanitize(cfg.Dir.DataHome)
+	cfg.Dir.TemplateDir = env.Sanitize(cfg.Dir.TemplateDir)
+	cfg.Dir.PeriodicDir = env.Sanitize(cfg.Dir.PeriodicDir)
+	cfg.Dir.ZettelDir = env.Sanitize(cfg.Dir.ZettelDir)
+	cfg.Dir.ProjectsDir = env.Sanitize(cfg.Dir.ProjectsDir)
+	cfg.Dir.InboxDir = env.Sanitize(cfg.Dir.InboxDir)
+	cfg.Dir.IdeaDir = env.Sanitize(cfg.Dir.IdeaDir)
+	cfg.Dir.PluginDir = env.Sanitize(cfg.Dir.PluginDir)
+	cfg.Dir.ArchiveDir = env.Sanitize(cfg.Dir.ArchiveDir)
+	cfg.Dir.LiteratureDir = env.Sanitize(cfg.Dir.LiteratureDir)
+	cfg.Prompts.Path = env.Sanitize(cfg.Prompts.Path)
+	if cfg.Dir.StateDir == "" {
+		cfg.Dir.StateDir = env.Join(env.StateDir("exo"), vaultDir(cfg.Dir.DataHome))
+	}
+	cfg.Dir.StateDir = env.Sanitize(cfg.Dir.StateDir)
+	if cfg.Dir.CacheDir == "" {
+		cfg.Dir.CacheDir = env.Join(env.CacheDir("exo"), vaultDir(cfg.Dir.DataHome))
+	}
+	cfg.Dir.CacheDir = env.Sanitize(cfg.Dir.CacheDir)
+	cfg.Backup.SnapshotDir = env.Sanitize(cfg.Backup.SnapshotDir)
+	if cfg.Cite.Bibliography != "" {
+		cfg.Cite.Bibliography = env.Sanitize(cfg.Cite.Bibliography)
+	}
+	if cfg.Templates.ProfileDir != "" {
+		cfg.Templates.ProfileDir = env.Sanitize(cfg.Templates.ProfileDir)
+	}
+	if cfg.Calendar.Source != "" && !strings.Contains(cfg.Calendar.Source, "://") {
+		cfg.Calendar.Source = env.Sanitize(cfg.Calendar.Source)
+	}
 
 	// Apply environment variable override for editor.
-	if editor := os.Getenv("EDITOR"); editor != "" {
+	if editor := env.Getenv("EDITOR"); editor != "" {
 		cfg.General.Editor = editor
 	}
 
@@ -127,30 +830,29 @@ func NewConfig(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
-// getDataHome determines the data home directory.
-// Priority: EXO_DATA_HOME environment variable, else $HOME/.local/share/exo.
-func getDataHome(home string) string {
-	if dataHome := os.Getenv(envDataHome); dataHome != "" {
-		return sanitizePath(dataHome, home)
-	}
-	return filepath.Join(home, ".local", "share", "exo")
+// Source returns the path of the configuration file that was loaded,
+// or an empty string if only defaults and environment overrides are in effect.
+func (c *Config) Source() string {
+	return c.source
 }
 
-// sanitizePath expands the tilde and converts relative paths to absolute based on home.
-func sanitizePath(path, home string) string {
-	// If path starts with ~/, replace with home directory.
-	if strings.HasPrefix(path, "~/") {
-		path = filepath.Join(home, path[2:])
-	}
-	path = filepath.Clean(path)
-	if !filepath.IsAbs(path) {
-		path = filepath.Join(home, path)
+// getDataHome determines the data home directory.
+// Priority: EXO_DATA_HOME environment variable, else $HOME/.local/share/exo, or
+// %LOCALAPPDATA%\exo\data on Windows.
+func getDataHome(env fs.Env) string {
+	if dataHome := env.Getenv(envDataHome); dataHome != "" {
+		return env.Sanitize(dataHome)
 	}
-	return path
+	return env.DataDir("exo")
 }
 
-// Validate checks that required configuration fields are non‑empty.
+// Validate checks that required configuration fields are non‑empty and that
+// the settings are valid. Its errors are of category exoerrors.Config.
 func (c *Config) Validate() error {
+	return exoerrors.Wrap(exoerrors.Config, c.validate())
+}
+
+func (c *Config) validate() error {
 	if c.General.Editor == "" {
 		return fmt.Errorf("editor cannot be empty")
 	}
@@ -166,43 +868,281 @@ func (c *Config) Validate() error {
 	if c.Dir.ZettelDir == "" {
 		return fmt.Errorf("zettel_dir cannot be empty")
 	}
+	if _, _, err := c.Templates.Modes(); err != nil {
+		return err
+	}
+	for name, source := range c.Templates.Packs {
+		if strings.TrimSpace(source) == "" {
+			return fmt.Errorf("templates.packs.%s cannot be empty", name)
+		}
+	}
+	if c.Search.SnippetLength < 0 {
+		return fmt.Errorf("search.snippet_length cannot be negative")
+	}
+	if c.Periodic.Daily.CarryOver < 0 {
+		return fmt.Errorf("periodic.daily.carry_over cannot be negative")
+	}
+	for i, o := range c.Periodic.Daily.TemplateOverrides {
+		if err := o.validate(); err != nil {
+			return fmt.Errorf("periodic.daily.template_overrides[%d]: %w", i, err)
+		}
+	}
+	habits := make(map[string]bool)
+	for _, name := range c.Habits {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return fmt.Errorf("habits cannot contain an empty name")
+		}
+		if habits[key] {
+			return fmt.Errorf("habits: duplicate habit %q", name)
+		}
+		habits[key] = true
+	}
+	for _, pattern := range c.Ignore {
+		if strings.HasPrefix(strings.TrimSpace(pattern), "!") {
+			return fmt.Errorf("ignore: negated pattern %q is not supported", pattern)
+		}
+		if _, err := path.Match(strings.TrimSpace(pattern), ""); err != nil {
+			return fmt.Errorf("ignore: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	seen := make(map[string]bool)
+	for i, s := range c.Daily.Sections {
+		name := strings.ToLower(strings.TrimSpace(s.Name))
+		if name == "" {
+			return fmt.Errorf("daily.sections[%d].name cannot be empty", i)
+		}
+		if seen[name] {
+			return fmt.Errorf("daily.sections: duplicate section %q", s.Name)
+		}
+		seen[name] = true
+		if s.Heading != "" && !strings.HasPrefix(s.Heading, "#") {
+			return fmt.Errorf("daily.sections[%d].heading must be a Markdown heading, e.g. \"## %s\"", i, s.Name)
+		}
+	}
+	if c.Location.Latitude < -90 || c.Location.Latitude > 90 {
+		return fmt.Errorf("location.latitude must be between -90 and 90")
+	}
+	if c.Location.Longitude < -180 || c.Location.Longitude > 180 {
+		return fmt.Errorf("location.longitude must be between -180 and 180")
+	}
+	if c.Prompts.Count < 0 {
+		return fmt.Errorf("prompts.count cannot be negative")
+	}
+	switch c.Prompts.Order {
+	case "", "rotate", "random":
+	default:
+		return fmt.Errorf("prompts.order must be rotate or random")
+	}
+	switch c.Weather.Units {
+	case "", "celsius", "fahrenheit":
+	default:
+		return fmt.Errorf("weather.units must be celsius or fahrenheit")
+	}
+	switch c.Cite.Style {
+	case "", "apa", "mla", "chicago":
+	default:
+		return fmt.Errorf("cite.style must be apa, mla or chicago")
+	}
+	if c.Lint.MaxWords < 0 {
+		return fmt.Errorf("lint.max_words cannot be negative")
+	}
+	if c.Lint.TodoDays < 0 {
+		return fmt.Errorf("lint.todo_days cannot be negative")
+	}
+	if !slug.Style(c.Slug.Style).Valid() {
+		return fmt.Errorf("slug.style must be title, kebab, snake or camel")
+	}
+	if c.Slug.MaxLength < 0 {
+		return fmt.Errorf("slug.max_length cannot be negative")
+	}
+	if c.General.Versions < 0 {
+		return fmt.Errorf("general.versions cannot be negative")
+	}
+	switch c.General.OnConflict {
+	case "", "fail", "merge", "copy", "overwrite":
+	default:
+		return fmt.Errorf("general.on_conflict must be fail, merge, copy or overwrite")
+	}
+	for _, keep := range []struct {
+		name  string
+		count int
+	}{
+		{"last", c.Backup.Keep.Last}, {"daily", c.Backup.Keep.Daily}, {"weekly", c.Backup.Keep.Weekly},
+		{"monthly", c.Backup.Keep.Monthly}, {"yearly", c.Backup.Keep.Yearly},
+	} {
+		if keep.count < 0 {
+			return fmt.Errorf("backup.keep.%s cannot be negative", keep.name)
+		}
+	}
+	for _, noteType := range sortedKeys(c.Schema) {
+		for _, name := range sortedKeys(c.Schema[noteType]) {
+			if !frontmatter.FieldType(c.Schema[noteType][name].Type).Valid() {
+				return fmt.Errorf("schema.%s.%s.type must be string, number, bool, date or list", noteType, name)
+			}
+		}
+	}
+	for _, name := range c.ViewNames() {
+		view := c.Views[name]
+		switch view.Sort {
+		case "", "modified", "created", "title":
+		default:
+			return fmt.Errorf("views.%s.sort must be modified, created or title", name)
+		}
+		if view.Limit < 0 {
+			return fmt.Errorf("views.%s.limit cannot be negative", name)
+		}
+		if _, err := query.Parse(view.Query, time.Now()); err != nil {
+			return fmt.Errorf("views.%s.query: %w", name, err)
+		}
+	}
 	return nil
 }
 
-// Save writes the configuration to $HOME/.config/exo/config.yaml.
+// dirSettings returns the directory settings RebaseDirs rewrites, by dotted
+// key.
+func (c *Config) dirSettings() []struct {
+	key string
+	dir *string
+} {
+	return []struct {
+		key string
+		dir *string
+	}{
+		{"dir.template_dir", &c.Dir.TemplateDir},
+		{"dir.periodic_dir", &c.Dir.PeriodicDir},
+		{"dir.zettel_dir", &c.Dir.ZettelDir},
+		{"dir.projects_dir", &c.Dir.ProjectsDir},
+		{"dir.inbox_dir", &c.Dir.InboxDir},
+		{"dir.idea_dir", &c.Dir.IdeaDir},
+		{"dir.plugin_dir", &c.Dir.PluginDir},
+		{"dir.archive_dir", &c.Dir.ArchiveDir},
+		{"dir.literature_dir", &c.Dir.LiteratureDir},
+		{"prompts.path", &c.Prompts.Path},
+		{"cite.bibliography", &c.Cite.Bibliography},
+	}
+}
+
+// RebaseDirs rewrites every directory setting located under oldHome so that it
+// points to the same relative location under newHome, and sets DataHome to newHome.
+func (c *Config) RebaseDirs(oldHome, newHome string) {
+	oldHome = filepath.Clean(oldHome)
+	newHome = filepath.Clean(newHome)
+	for _, setting := range c.dirSettings() {
+		rel, err := filepath.Rel(oldHome, *setting.dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		*setting.dir = filepath.Join(newHome, rel)
+		if c.rebased == nil {
+			c.rebased = make(map[string]bool)
+		}
+		c.rebased[setting.key] = true
+	}
+	c.Dir.DataHome = newHome
+}
+
+// path returns the configuration file Save writes to.
+func (c *Config) path() (string, error) {
+	if c.source != "" {
+		return c.source, nil
+	}
+	env := fs.OSEnv()
+	if env.Home == "" {
+		return "", fmt.Errorf("failed to get user home directory")
+	}
+	return filepath.Join(env.ConfigDir("exo"), "config.yaml"), nil
+}
+
+// Save writes the configuration back to the file it was loaded from or, when
+// none was, to $HOME/.config/exo/config.yaml (%APPDATA%\exo\config.yaml on
+// Windows).
 func (c *Config) Save() error {
-	home, err := os.UserHomeDir()
+	configPath, err := c.path()
 	if err != nil {
-		return fmt.Errorf("failed to get user home directory: %w", err)
+		return err
 	}
 
-	configDir := filepath.Join(home, ".config", "exo")
-	configPath := filepath.Join(configDir, "config.yaml")
-
 	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
 		return fmt.Errorf("failed to create config directory: %w", err)
 	}
 
-	v := viper.New()
-	v.SetConfigType("yaml")
-	v.Set("general", c.General)
-	v.Set("dir", c.Dir)
-	v.Set("log", c.Log)
-
-	if err := v.WriteConfigAs(configPath); err != nil {
+	data, err := c.marshal()
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(configPath, data, 0644); err != nil {
 		return fmt.Errorf("failed to write config file: %w", err)
 	}
 
 	return nil
 }
 
+// SaveDirs writes the data home and the directory settings RebaseDirs rewrote
+// to the file Save writes to, leaving every other key as it is on disk.
+// Settings the file does not set are written too, as their defaults do not
+// follow the data home, but values taken from the environment never are.
+func (c *Config) SaveDirs() error {
+	configPath, err := c.path()
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	data, err := os.ReadFile(configPath)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse config file: %w", err)
+		}
+	case !os.IsNotExist(err):
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var current yaml.Node
+	if err := current.Encode(c); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	keys := []string{}
+	if c.Origin("dir.data_home").Kind != FromEnv {
+		keys = append(keys, "dir.data_home")
+	}
+	for _, setting := range c.dirSettings() {
+		if kind := c.Origin(setting.key).Kind; kind == FromFile || c.rebased[setting.key] && kind != FromEnv {
+			keys = append(keys, setting.key)
+		}
+	}
+	for _, key := range keys {
+		if value := lookupNode(&current, key); value != nil {
+			setNode(&doc, key, value)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indentOf(data))
+	if err := enc.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	if err := os.WriteFile(configPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
 // String returns a human‑readable representation of the configuration.
 func (c *Config) String() string {
 	var sb strings.Builder
 	sb.WriteString("Configuration:\n")
 	sb.WriteString("-------------\n\n")
 	sb.WriteString("General:\n")
-	sb.WriteString(fmt.Sprintf("  editor:        %s\n\n", c.General.Editor))
+	sb.WriteString(fmt.Sprintf("  editor:        %s\n", c.General.Editor))
+	sb.WriteString(fmt.Sprintf("  read_only:     %t\n", c.General.ReadOnly))
+	sb.WriteString(fmt.Sprintf("  timestamps:    %t\n", c.General.Timestamps))
+	sb.WriteString(fmt.Sprintf("  versions:      %d\n", c.General.Versions))
+	sb.WriteString(fmt.Sprintf("  on_conflict:   %s\n\n", c.General.OnConflict))
 	sb.WriteString("Directories:\n")
 	sb.WriteString(fmt.Sprintf("  data_home:     %s\n", c.Dir.DataHome))
 	sb.WriteString(fmt.Sprintf("  template_dir:  %s\n", c.Dir.TemplateDir))
@@ -210,14 +1150,253 @@ func (c *Config) String() string {
 	sb.WriteString(fmt.Sprintf("  zettel_dir:    %s\n", c.Dir.ZettelDir))
 	sb.WriteString(fmt.Sprintf("  projects_dir:  %s\n", c.Dir.ProjectsDir))
 	sb.WriteString(fmt.Sprintf("  inbox_dir:     %s\n", c.Dir.InboxDir))
-	sb.WriteString(fmt.Sprintf("  idea_dir:      %s\n\n", c.Dir.IdeaDir))
+	sb.WriteString(fmt.Sprintf("  idea_dir:      %s\n", c.Dir.IdeaDir))
+	sb.WriteString(fmt.Sprintf("  plugin_dir:    %s\n", c.Dir.PluginDir))
+	sb.WriteString(fmt.Sprintf("  archive_dir:   %s\n", c.Dir.ArchiveDir))
+	sb.WriteString(fmt.Sprintf("  literature_dir: %s\n", c.Dir.LiteratureDir))
+	sb.WriteString(fmt.Sprintf("  state_dir:     %s\n", c.Dir.StateDir))
+	sb.WriteString(fmt.Sprintf("  cache_dir:     %s\n\n", c.Dir.CacheDir))
 	sb.WriteString("Logging:\n")
 	sb.WriteString(fmt.Sprintf("  level:         %s\n", c.Log.Level))
 	sb.WriteString(fmt.Sprintf("  format:        %s\n", c.Log.Format))
-	sb.WriteString(fmt.Sprintf("  output:        %s\n", c.Log.Output))
+	sb.WriteString(fmt.Sprintf("  output:        %s\n\n", c.Log.Output))
+	sb.WriteString("Daily:\n")
+	sb.WriteString(fmt.Sprintf("  log_heading:   %s\n", c.Daily.LogHeading))
+	sb.WriteString(fmt.Sprintf("  sections:      %s\n\n", strings.Join(c.Daily.SectionNames(), ", ")))
+	sb.WriteString("Sync:\n")
+	sb.WriteString(fmt.Sprintf("  remote:        %s\n", c.Sync.Remote))
+	sb.WriteString(fmt.Sprintf("  auto_commit:   %t\n\n", c.Sync.AutoCommit))
+	sb.WriteString("Templates:\n")
+	sb.WriteString(fmt.Sprintf("  file_mode:     %s\n", c.Templates.FileMode))
+	sb.WriteString(fmt.Sprintf("  dir_mode:      %s\n", c.Templates.DirMode))
+	sb.WriteString(fmt.Sprintf("  profile_dir:   %s\n", c.Templates.ProfileDir))
+	for _, name := range c.PackNames() {
+		sb.WriteString(fmt.Sprintf("  pack %-9s %s\n", name+":", c.Templates.Packs[name]))
+	}
+	sb.WriteString("\n")
+	sb.WriteString("Search:\n")
+	sb.WriteString(fmt.Sprintf("  snippet_length: %d\n\n", c.Search.SnippetLength))
+	sb.WriteString("Periodic:\n")
+	sb.WriteString(fmt.Sprintf("  daily.carry_over: %d\n", c.Periodic.Daily.CarryOver))
+	sb.WriteString(fmt.Sprintf("  daily.link_week: %t\n", c.Periodic.Daily.LinkWeek))
+	sb.WriteString(fmt.Sprintf("  daily.link_month: %t\n", c.Periodic.Daily.LinkMonth))
+	sb.WriteString(fmt.Sprintf("  daily.link_quarter: %t\n", c.Periodic.Daily.LinkQuarter))
+	sb.WriteString(fmt.Sprintf("  weekly.link_days: %t\n", c.Periodic.Weekly.LinkDays))
+	if len(c.Periodic.Daily.Providers) > 0 {
+		sb.WriteString(fmt.Sprintf("  daily.providers:  %s\n", strings.Join(c.Periodic.Daily.Providers, ", ")))
+	}
+	if len(c.Periodic.Daily.TemplateOverrides) > 0 {
+		var names []string
+		for _, o := range c.Periodic.Daily.TemplateOverrides {
+			names = append(names, o.Template)
+		}
+		sb.WriteString(fmt.Sprintf("  daily.template_overrides: %s\n", strings.Join(names, ", ")))
+	}
+	sb.WriteString("\nPrompts:\n")
+	sb.WriteString(fmt.Sprintf("  path:          %s\n", c.Prompts.Path))
+	sb.WriteString(fmt.Sprintf("  count:         %d\n", c.Prompts.Count))
+	sb.WriteString(fmt.Sprintf("  order:         %s\n", c.Prompts.Order))
+	sb.WriteString("\nCitations:\n")
+	if c.Cite.Bibliography != "" {
+		sb.WriteString(fmt.Sprintf("  bibliography:  %s\n", c.Cite.Bibliography))
+	}
+	sb.WriteString(fmt.Sprintf("  style:         %s\n", c.Cite.Style))
+	sb.WriteString("\nBooks:\n")
+	sb.WriteString(fmt.Sprintf("  provider:      %s\n", c.Book.Provider))
+	if c.Book.URL != "" {
+		sb.WriteString(fmt.Sprintf("  url:           %s\n", c.Book.URL))
+	}
+	sb.WriteString("\nLint:\n")
+	sb.WriteString(fmt.Sprintf("  max_words:     %d\n", c.Lint.MaxWords))
+	sb.WriteString(fmt.Sprintf("  todo_days:     %d\n", c.Lint.TodoDays))
+	if len(c.Lint.Disabled) > 0 {
+		sb.WriteString(fmt.Sprintf("  disabled:      %s\n", strings.Join(c.Lint.Disabled, ", ")))
+	}
+	for _, noteType := range sortedKeys(c.Lint.RequiredFields) {
+		sb.WriteString(fmt.Sprintf("  required:      %s: %s\n", noteType, strings.Join(c.Lint.RequiredFields[noteType], ", ")))
+	}
+	sb.WriteString("\nSlugs:\n")
+	sb.WriteString(fmt.Sprintf("  style:         %s\n", c.Slug.Style))
+	sb.WriteString(fmt.Sprintf("  max_length:    %d\n", c.Slug.MaxLength))
+	sb.WriteString(fmt.Sprintf("  stop_words:    %t\n", c.Slug.StopWords))
+	if c.Slug.DatePrefix != "" {
+		sb.WriteString(fmt.Sprintf("  date_prefix:   %s\n", c.Slug.DatePrefix))
+	}
+	sb.WriteString("\nExport:\n")
+	sb.WriteString(fmt.Sprintf("  pdf.page_size: %s\n", c.Export.PDF.PageSize))
+	sb.WriteString(fmt.Sprintf("  pandoc:        %s\n", c.Export.Pandoc))
+	for _, setting := range []struct{ key, value string }{
+		{"pdf.engine", c.Export.PDF.Engine}, {"pdf.css", c.Export.PDF.CSS}, {"pdf.template", c.Export.PDF.Template},
+	} {
+		if setting.value != "" {
+			sb.WriteString(fmt.Sprintf("  %-14s %s\n", setting.key+":", setting.value))
+		}
+	}
+	for _, format := range sortedKeys(c.Export.Formats) {
+		f := c.Export.Formats[format]
+		parts := f.Args
+		if f.Extension != "" {
+			parts = append([]string{"." + f.Extension}, parts...)
+		}
+		sb.WriteString(fmt.Sprintf("  %-14s %s\n", format+":", strings.Join(parts, " ")))
+	}
+	sb.WriteString("\nBackup:\n")
+	if c.Backup.Remote != "" {
+		sb.WriteString(fmt.Sprintf("  remote:        %s\n", c.Backup.Remote))
+	}
+	sb.WriteString(fmt.Sprintf("  encrypt:       %t\n", c.Backup.Encrypt))
+	sb.WriteString(fmt.Sprintf("  s3.region:     %s\n", c.Backup.S3.Region))
+	sb.WriteString(fmt.Sprintf("  snapshot_dir:  %s\n", c.Backup.SnapshotDir))
+	k := c.Backup.Keep
+	sb.WriteString(fmt.Sprintf("  keep:          last %d, daily %d, weekly %d, monthly %d, yearly %d\n",
+		k.Last, k.Daily, k.Weekly, k.Monthly, k.Yearly))
+	for _, setting := range []struct{ key, value string }{
+		{"s3.endpoint", c.Backup.S3.Endpoint}, {"s3.bucket", c.Backup.S3.Bucket}, {"s3.prefix", c.Backup.S3.Prefix},
+		{"webdav.url", c.Backup.WebDAV.URL}, {"webdav.username", c.Backup.WebDAV.Username},
+	} {
+		if setting.value != "" {
+			sb.WriteString(fmt.Sprintf("  %-14s %s\n", setting.key+":", setting.value))
+		}
+	}
+	if len(c.Schema) > 0 {
+		sb.WriteString("\nSchema:\n")
+		for _, noteType := range sortedKeys(c.Schema) {
+			var fields []string
+			for _, name := range sortedKeys(c.Schema[noteType]) {
+				field := c.Schema[noteType][name]
+				var attrs []string
+				if field.Type != "" {
+					attrs = append(attrs, field.Type)
+				}
+				if field.Required {
+					attrs = append(attrs, "required")
+				}
+				if len(attrs) > 0 {
+					name += " (" + strings.Join(attrs, ", ") + ")"
+				}
+				fields = append(fields, name)
+			}
+			sb.WriteString(fmt.Sprintf("  %-14s %s\n", noteType+":", strings.Join(fields, ", ")))
+		}
+	}
+	if c.Location != (LocationConfig{}) {
+		sb.WriteString("\nLocation:\n")
+		sb.WriteString(fmt.Sprintf("  name:          %s\n", c.Location.Name))
+		sb.WriteString(fmt.Sprintf("  latitude:      %g\n", c.Location.Latitude))
+		sb.WriteString(fmt.Sprintf("  longitude:     %g\n", c.Location.Longitude))
+	}
+	if c.Weather != (WeatherConfig{}) {
+		sb.WriteString("\nWeather:\n")
+		sb.WriteString(fmt.Sprintf("  url:           %s\n", c.Weather.URL))
+		sb.WriteString(fmt.Sprintf("  units:         %s\n", c.Weather.Units))
+	}
+	if c.Calendar.Source != "" {
+		sb.WriteString("\nCalendar:\n")
+		sb.WriteString(fmt.Sprintf("  source:        %s\n", c.Calendar.Source))
+		sb.WriteString(fmt.Sprintf("  username:      %s\n", c.Calendar.Username))
+	}
+	if len(c.Watch.Hooks) > 0 {
+		sb.WriteString("\nWatch hooks:\n")
+		for _, hook := range c.Watch.Hooks {
+			sb.WriteString(fmt.Sprintf("  %s\n", hook))
+		}
+	}
+	if len(c.ID) > 0 {
+		sb.WriteString("\nIDs:\n")
+		for _, noteType := range sortedKeys(c.ID) {
+			sb.WriteString(fmt.Sprintf("  %-14s %s\n", noteType+":", c.ID[noteType]))
+		}
+	}
+	if len(c.Habits) > 0 {
+		sb.WriteString(fmt.Sprintf("\nHabits: %s\n", strings.Join(c.Habits, ", ")))
+	}
+	if len(c.Ignore) > 0 {
+		sb.WriteString(fmt.Sprintf("\nIgnore: %s\n", strings.Join(c.Ignore, ", ")))
+	}
+	if len(c.Views) > 0 {
+		sb.WriteString("\nViews:\n")
+		for _, name := range c.ViewNames() {
+			sb.WriteString(fmt.Sprintf("  %-14s %s\n", name+":", c.Views[name]))
+		}
+	}
+	if len(c.Alias) > 0 {
+		sb.WriteString("\nAliases:\n")
+		for _, name := range c.AliasNames() {
+			sb.WriteString(fmt.Sprintf("  %-14s %s\n", name+":", c.Alias[name]))
+		}
+	}
 	return sb.String()
 }
 
+// AliasNames returns the configured alias names in sorted order.
+func (c *Config) AliasNames() []string {
+	return sortedKeys(c.Alias)
+}
+
+// ViewNames returns the configured view names in sorted order.
+func (c *Config) ViewNames() []string {
+	return sortedKeys(c.Views)
+}
+
+// String describes the filters of the view, e.g. `type:zettel text:"go channels"`.
+func (v ViewConfig) String() string {
+	var parts []string
+	for _, f := range []struct{ key, value string }{
+		{"query", v.Query}, {"type", v.Type}, {"tag", v.Tag}, {"text", v.Text},
+		{"since", v.Since}, {"before", v.Before}, {"sort", v.Sort},
+	} {
+		if strings.ContainsAny(f.value, " \t") {
+			parts = append(parts, f.key+":"+strconv.Quote(f.value))
+		} else if f.value != "" {
+			parts = append(parts, f.key+":"+f.value)
+		}
+	}
+	if v.Limit > 0 {
+		parts = append(parts, fmt.Sprintf("limit:%d", v.Limit))
+	}
+	if v.Note != "" {
+		parts = append(parts, "note:"+v.Note)
+	}
+	if len(parts) == 0 {
+		return "(all notes)"
+	}
+	return strings.Join(parts, " ")
+}
+
+// PackNames returns the configured template pack names in sorted order.
+func (c *Config) PackNames() []string {
+	return sortedKeys(c.Templates.Packs)
+}
+
+// IDGenerator returns the name of the ID generator configured for noteType,
+// or an empty string if notes of that type are not given generated IDs.
+func (c *Config) IDGenerator(noteType string) string {
+	return c.ID[noteType]
+}
+
+// Schemas returns the frontmatter schema of each note type with one.
+func (c *Config) Schemas() map[string]frontmatter.Schema {
+	schemas := make(map[string]frontmatter.Schema, len(c.Schema))
+	for noteType, fields := range c.Schema {
+		schema := make(frontmatter.Schema, len(fields))
+		for name, field := range fields {
+			schema[name] = frontmatter.Field{Type: frontmatter.FieldType(field.Type), Required: field.Required}
+		}
+		schemas[noteType] = schema
+	}
+	return schemas
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 // package config
 //
 // import (
@@ -3,9 +3,13 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -25,6 +29,7 @@ func TestNewConfig_Defaults(t *testing.T) {
 
 	// Verify default values.
 	assert.Equal(t, "nvim", cfg.General.Editor)
+	assert.Empty(t, cfg.Source())
 
 	// Expected data home: tmpHome/.local/share/exo
 	expectedDataHome := filepath.Join(tmpHome, ".local", "share", "exo")
@@ -37,11 +42,18 @@ func TestNewConfig_Defaults(t *testing.T) {
 	assert.Equal(t, filepath.Join(expectedDataHome, "projects"), cfg.Dir.ProjectsDir)
 	assert.Equal(t, filepath.Join(expectedDataHome, "0-inbox"), cfg.Dir.InboxDir)
 	assert.Equal(t, filepath.Join(expectedDataHome, "ideas"), cfg.Dir.IdeaDir)
+	assert.Equal(t, filepath.Join(expectedDataHome, "plugins"), cfg.Dir.PluginDir)
+	assert.Equal(t, filepath.Join(expectedDataHome, "literature"), cfg.Dir.LiteratureDir)
 
 	// Verify logging defaults.
 	assert.Equal(t, "info", cfg.Log.Level)
 	assert.Equal(t, "text", cfg.Log.Format)
 	assert.Equal(t, "stdout", cfg.Log.Output)
+	assert.Equal(t, 60, cfg.Search.SnippetLength)
+	assert.Equal(t, 0, cfg.Periodic.Daily.CarryOver)
+	assert.Equal(t, []string{"Tasks", "Log", "Notes"}, cfg.Daily.SectionNames())
+	assert.Empty(t, cfg.Cite.Bibliography)
+	assert.Equal(t, "apa", cfg.Cite.Style)
 }
 
 func TestNewConfig_ConfigFile(t *testing.T) {
@@ -72,6 +84,15 @@ log:
   level: debug
   format: json
   output: stderr
+watch:
+  hooks:
+    - exo index status
+    - echo "$EXO_NOTE"
+calendar:
+  source: "~/calendar.ics"
+cite:
+  bibliography: "~/refs.bib"
+  style: chicago
 `
 	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
 
@@ -80,6 +101,7 @@ log:
 	require.NotNil(t, cfg)
 
 	assert.Equal(t, "code", cfg.General.Editor)
+	assert.Equal(t, configPath, cfg.Source())
 	home, err := os.UserHomeDir()
 	require.NoError(t, err)
 	expectedDataHome := filepath.Join(home, "mydata")
@@ -88,6 +110,14 @@ log:
 	assert.Equal(t, "debug", cfg.Log.Level)
 	assert.Equal(t, "json", cfg.Log.Format)
 	assert.Equal(t, "stderr", cfg.Log.Output)
+	assert.Equal(t, []string{"exo index status", `echo "$EXO_NOTE"`}, cfg.Watch.Hooks)
+	assert.Equal(t, filepath.Join(home, "calendar.ics"), cfg.Calendar.Source)
+	assert.Equal(t, filepath.Join(home, "refs.bib"), cfg.Cite.Bibliography)
+	assert.Equal(t, "chicago", cfg.Cite.Style)
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Cite.Style = "ieee"
+	assert.ErrorContains(t, cfg.Validate(), "cite.style")
 }
 
 func TestNewConfig_EnvOverride(t *testing.T) {
@@ -106,6 +136,114 @@ func TestNewConfig_EnvOverride(t *testing.T) {
 	assert.Equal(t, "vim", cfg.General.Editor)
 }
 
+func TestNewConfigIn_Windows(t *testing.T) {
+	vars := map[string]string{
+		"APPDATA":      `C:\Users\ada\AppData\Roaming`,
+		"LOCALAPPDATA": `C:\Users\ada\AppData\Local`,
+		"USERPROFILE":  `C:\Users\ada`,
+	}
+	env := fs.Env{GOOS: "windows", Home: `C:\Users\ada`, Getenv: func(key string) string { return vars[key] }}
+
+	cfg, err := config.NewConfigIn("", env)
+	require.NoError(t, err)
+	assert.Equal(t, "notepad", cfg.General.Editor)
+	assert.Equal(t, `C:\Users\ada\AppData\Local\exo\data`, cfg.Dir.DataHome)
+	assert.Equal(t, `C:\Users\ada\AppData\Local\exo\data\0-inbox`, cfg.Dir.InboxDir)
+	assert.Equal(t, `C:\Users\ada\AppData\Roaming\exo\templates`, cfg.Templates.ProfileDir)
+	assert.Equal(t, `C:\Users\ada\AppData\Local\exo\state\snapshots`, cfg.Backup.SnapshotDir)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("general:\n  editor: code --wait\ndir:\n  data_home: '%USERPROFILE%\\Documents\\notes'\n  zettel_dir: '~\\zettel'\n"), 0644))
+	vars["EDITOR"] = ""
+	cfg, err = config.NewConfigIn(path, env)
+	require.NoError(t, err)
+	assert.Equal(t, "code --wait", cfg.General.Editor)
+	assert.Equal(t, `C:\Users\ada\Documents\notes`, cfg.Dir.DataHome)
+	assert.Equal(t, `C:\Users\ada\zettel`, cfg.Dir.ZettelDir)
+}
+
+func TestDirConfig_StatePath(t *testing.T) {
+	vars := map[string]string{"XDG_STATE_HOME": "/state"}
+	env := fs.Env{GOOS: "linux", Home: "/home/ada", Getenv: func(key string) string { return vars[key] }}
+	cfg, err := config.NewConfigIn("", env)
+	require.NoError(t, err)
+	assert.Regexp(t, `^/state/exo/exo-[0-9a-f]{8}$`, cfg.Dir.StateDir)
+	assert.Regexp(t, `^/home/ada/.cache/exo/exo-[0-9a-f]{8}$`, cfg.Dir.CacheDir)
+
+	other, err := config.NewConfigIn("", fs.Env{GOOS: "linux", Home: "/home/bob", Getenv: func(string) string { return "" }})
+	require.NoError(t, err)
+	assert.NotEqual(t, filepath.Base(cfg.Dir.StateDir), filepath.Base(other.Dir.StateDir), "vaults do not share state")
+
+	dataHome := t.TempDir()
+	dirs := config.DirConfig{DataHome: dataHome, StateDir: filepath.Join(t.TempDir(), "state")}
+	legacy := filepath.Join(dataHome, ".exo", "review.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(legacy), 0755))
+	require.NoError(t, os.WriteFile(legacy, []byte("{}"), 0644))
+
+	path := dirs.StatePath("review.json")
+	assert.Equal(t, filepath.Join(dirs.StateDir, "review.json"), path)
+	assert.FileExists(t, path, "files of earlier versions are moved")
+	assert.NoFileExists(t, legacy)
+	assert.Equal(t, filepath.Join(dirs.StateDir, "recent.json"), dirs.StatePath("recent.json"))
+}
+
+func TestConfig_Origin(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	vars := map[string]string{"EXO_DATA_HOME": "/notes", "EDITOR": "vi"}
+	env := fs.Env{GOOS: "linux", Home: home, Getenv: func(key string) string { return vars[key] }}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("general:\n  editor: code\nlog:\n  level: debug\nalias:\n  T: zet list\n"), 0644))
+
+	cfg, err := config.NewConfigIn(path, env)
+	require.NoError(t, err)
+	assert.Equal(t, config.Origin{Kind: config.FromEnv, Source: "EDITOR"}, cfg.Origin("general.editor"), "EDITOR wins over the file")
+	assert.Equal(t, config.Origin{Kind: config.FromFile, Source: path, Line: 4}, cfg.Origin("log.level"))
+	assert.Equal(t, "file "+path+":6", cfg.Origin("alias.t").String())
+	assert.Equal(t, config.Origin{Kind: config.FromEnv, Source: "EXO_DATA_HOME"}, cfg.Origin("dir.data_home"))
+	assert.Equal(t, "default", cfg.Origin("log.format").String())
+
+	cfg.Override("general.read_only", "--read-only", func(c *config.Config) { c.General.ReadOnly = true })
+	assert.True(t, cfg.General.ReadOnly)
+	assert.Equal(t, "flag --read-only", cfg.Origin("general.read_only").String())
+	require.NoError(t, cfg.Save())
+	saved, err := config.NewConfig(path)
+	require.NoError(t, err)
+	assert.False(t, saved.General.ReadOnly, "flags are not saved")
+}
+
+func TestDefaults(t *testing.T) {
+	home := t.TempDir()
+	configDir := filepath.Join(home, ".config", "exo")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("log:\n  level: debug\n"), 0644))
+	vars := map[string]string{"EDITOR": "vi"}
+	env := fs.Env{GOOS: "linux", Home: home, Getenv: func(key string) string { return vars[key] }}
+
+	cfg, err := config.NewConfigIn("", env)
+	require.NoError(t, err)
+	assert.Equal(t, "debug", cfg.Log.Level)
+
+	defaults, err := config.Defaults(env)
+	require.NoError(t, err)
+	assert.Equal(t, "info", defaults.Log.Level, "the configuration file is not read")
+	assert.Equal(t, "vi", defaults.General.Editor, "the environment still applies")
+	assert.Empty(t, defaults.Source())
+}
+
+func TestNewConfigIn_BrokenFile(t *testing.T) {
+	home := t.TempDir()
+	env := fs.Env{GOOS: "linux", Home: home, Getenv: func(string) string { return "" }}
+	_, err := config.NewConfigIn("", env)
+	require.NoError(t, err, "a missing file leaves the defaults")
+
+	configDir := filepath.Join(home, ".config", "exo")
+	require.NoError(t, os.MkdirAll(configDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(configDir, "config.yaml"), []byte("dir:\n  data_home: [oops\n"), 0644))
+	_, err = config.NewConfigIn("", env)
+	assert.ErrorContains(t, err, "failed to read config file", "a broken file is not ignored")
+}
+
 func TestValidate(t *testing.T) {
 	cfg := &config.Config{
 		General: config.GeneralConfig{
@@ -150,3 +288,322 @@ func TestSaveAndString(t *testing.T) {
 	assert.Contains(t, str, "editor")
 	assert.Contains(t, str, "data_home")
 }
+
+func TestNewConfig_Aliases(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	configPath := filepath.Join(tmpHome, "config.yaml")
+	configContent := `
+alias:
+  t: zet new --template thought
+  y: day prev
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+
+	assert.Equal(t, "zet new --template thought", cfg.Alias["t"])
+	assert.Equal(t, []string{"t", "y"}, cfg.AliasNames())
+	assert.Contains(t, cfg.String(), "Aliases:")
+}
+
+func TestNewConfig_DailySections(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	configPath := filepath.Join(tmpHome, "config.yaml")
+	configContent := `
+daily:
+  sections:
+    - name: Log
+      timestamp: true
+    - name: Gratitude
+      heading: "### Grateful for"
+      prefix: "1. "
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Log", "Gratitude"}, cfg.Daily.SectionNames())
+
+	s, ok := cfg.Daily.Section("gratitude")
+	require.True(t, ok)
+	assert.Equal(t, "### Grateful for", s.HeadingLine())
+	assert.Equal(t, "1. ", s.EntryPrefix())
+	s, ok = cfg.Daily.Section("Log")
+	require.True(t, ok)
+	assert.True(t, s.Timestamp)
+	assert.Equal(t, "## Log", s.HeadingLine())
+	assert.Equal(t, "- ", s.EntryPrefix())
+	_, ok = cfg.Daily.Section("Tasks")
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(configPath, []byte("daily:\n  sections:\n    - name: Log\n    - name: log\n"), 0644))
+	_, err = config.NewConfig(configPath)
+	assert.ErrorContains(t, err, "duplicate section")
+}
+
+func TestNewConfig_TemplateOverrides(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	configPath := filepath.Join(tmpHome, "config.yaml")
+	configContent := `
+periodic:
+  daily:
+    template_overrides:
+      - template: day-holiday
+        from: 12-24
+        to: 01-01
+      - template: day-trip
+        from: 2025-06-02
+        to: 2025-06-06
+      - template: day-weekend
+        weekdays: [Saturday, sunday]
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+	cfg, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+	overrides := cfg.Periodic.Daily.TemplateOverrides
+	require.Len(t, overrides, 3)
+	assert.Contains(t, cfg.String(), "daily.template_overrides: day-holiday, day-trip, day-weekend")
+
+	day := func(s string) time.Time {
+		d, err := time.ParseInLocation("2006-01-02", s, time.Local)
+		require.NoError(t, err)
+		return d
+	}
+	for date, want := range map[string][]bool{
+		"2025-12-24": {true, false, false},
+		"2026-01-01": {true, false, false},
+		"2025-11-30": {false, false, true},
+		"2025-06-06": {false, true, false},
+		"2026-06-03": {false, false, false},
+		"2025-06-07": {false, false, true},
+	} {
+		for i, o := range overrides {
+			assert.Equal(t, want[i], o.Matches(day(date)), "%s: %s", date, o.Template)
+		}
+	}
+
+	for content, msg := range map[string]string{
+		"[{template: x}]":                                   "from or weekdays is required",
+		"[{template: x, from: 2025-13-01}]":                 "invalid date",
+		"[{template: x, from: 12-24, to: 2026-01-01}]":      "both be YYYY-MM-DD or MM-DD",
+		"[{template: x, from: 2025-02-01, to: 2025-01-01}]": "to is before from",
+		"[{template: x, weekdays: [caturday]}]":             "invalid weekday",
+		"[{from: 12-24}]":                                   "template cannot be empty",
+	} {
+		require.NoError(t, os.WriteFile(configPath, []byte("periodic:\n  daily:\n    template_overrides: "+content+"\n"), 0644))
+		_, err = config.NewConfig(configPath)
+		assert.ErrorContains(t, err, msg, content)
+	}
+}
+
+func TestNewConfig_Views(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	configPath := filepath.Join(tmpHome, "config.yaml")
+	configContent := `
+views:
+  reading:
+    tag: toread
+    sort: title
+  projects:
+    query: "tag:project AND modified:>2024-01-01"
+  recent-go:
+    type: zettel
+    text: "go channels"
+    since: 30d
+    limit: 10
+    note: Views/Recent Go
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"projects", "reading", "recent-go"}, cfg.ViewNames())
+	assert.Equal(t, `query:"tag:project AND modified:>2024-01-01"`, cfg.Views["projects"].String())
+	assert.Equal(t, config.ViewConfig{Type: "zettel", Text: "go channels", Since: "30d", Limit: 10, Note: "Views/Recent Go"}, cfg.Views["recent-go"])
+	assert.Equal(t, "tag:toread sort:title", cfg.Views["reading"].String())
+	assert.Contains(t, cfg.String(), "Views:")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("views:\n  x:\n    sort: size\n"), 0644))
+	_, err = config.NewConfig(configPath)
+	assert.ErrorContains(t, err, "views.x.sort")
+	require.NoError(t, os.WriteFile(configPath, []byte("views:\n  x:\n    query: \"tag:a OR\"\n"), 0644))
+	_, err = config.NewConfig(configPath)
+	assert.ErrorContains(t, err, "views.x.query")
+}
+
+func TestNewConfig_Schema(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	configPath := filepath.Join(tmpHome, "config.yaml")
+	configContent := `
+schema:
+  book:
+    isbn: {type: string, required: true}
+    rating: {type: number}
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]frontmatter.Schema{"book": {
+		"isbn":   {Type: frontmatter.StringType, Required: true},
+		"rating": {Type: frontmatter.NumberType},
+	}}, cfg.Schemas())
+	assert.Contains(t, cfg.String(), "book:          isbn (string, required), rating (number)")
+
+	require.NoError(t, os.WriteFile(configPath, []byte("schema:\n  book:\n    pages: {type: integer}\n"), 0644))
+	_, err = config.NewConfig(configPath)
+	assert.ErrorContains(t, err, "schema.book.pages.type")
+}
+
+func TestRebaseDirs(t *testing.T) {
+	cfg := &config.Config{
+		Dir: config.DirConfig{
+			DataHome:    "/old",
+			TemplateDir: "/old/templates",
+			ZettelDir:   "/old/notes/zettel",
+			IdeaDir:     "/elsewhere/ideas",
+		},
+	}
+	cfg.RebaseDirs("/old", "/new")
+
+	assert.Equal(t, "/new", cfg.Dir.DataHome)
+	assert.Equal(t, "/new/templates", cfg.Dir.TemplateDir)
+	assert.Equal(t, "/new/notes/zettel", cfg.Dir.ZettelDir)
+	assert.Equal(t, "/elsewhere/ideas", cfg.Dir.IdeaDir)
+}
+
+func TestSave_RoundTrip(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	cfg, err := config.NewConfig("")
+	require.NoError(t, err)
+	cfg.RebaseDirs(cfg.Dir.DataHome, filepath.Join(tmpHome, "vault"))
+	require.NoError(t, cfg.Save())
+
+	reloaded, err := config.NewConfig(filepath.Join(tmpHome, ".config", "exo", "config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpHome, "vault"), reloaded.Dir.DataHome)
+	assert.Equal(t, filepath.Join(tmpHome, "vault", "zettel"), reloaded.Dir.ZettelDir)
+}
+
+func TestSave_LoadedFile(t *testing.T) {
+	tmpHome := t.TempDir()
+	origHome := os.Getenv("HOME")
+	defer os.Setenv("HOME", origHome)
+	os.Setenv("HOME", tmpHome)
+	os.Unsetenv("EXO_DATA_HOME")
+
+	configPath := filepath.Join(tmpHome, "custom.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte("general:\n  editor: vim\n"), 0644))
+	cfg, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+	cfg.RebaseDirs(cfg.Dir.DataHome, filepath.Join(tmpHome, "vault"))
+	require.NoError(t, cfg.Save())
+
+	assert.NoFileExists(t, filepath.Join(tmpHome, ".config", "exo", "config.yaml"))
+	reloaded, err := config.NewConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(tmpHome, "vault"), reloaded.Dir.DataHome)
+	assert.Equal(t, "vim", reloaded.General.Editor)
+}
+
+func TestSaveDirs(t *testing.T) {
+	home := t.TempDir()
+	vars := map[string]string{"EDITOR": "true"}
+	env := fs.Env{GOOS: "linux", Home: home, Getenv: func(key string) string { return vars[key] }}
+	old, vault := filepath.Join(home, "old"), filepath.Join(home, "vault")
+	path := filepath.Join(home, "config.yaml")
+	content := "# My settings\ngeneral:\n  editor: vim # not $EDITOR\ndir:\n  data_home: " + old +
+		"\n  zettel_dir: " + old + "/zettel # slip-box\n  idea_dir: /elsewhere/ideas\nlog:\n  level: debug\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	cfg, err := config.NewConfigIn(path, env)
+	require.NoError(t, err)
+	assert.Equal(t, "true", cfg.General.Editor)
+	cfg.RebaseDirs(old, vault)
+	require.NoError(t, cfg.SaveDirs())
+
+	saved, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# My settings\ngeneral:\n  editor: vim # not $EDITOR\ndir:\n  data_home: "+vault+
+		"\n  zettel_dir: "+vault+"/zettel # slip-box\n  idea_dir: /elsewhere/ideas\nlog:\n  level: debug\n", string(saved),
+		"only the directory settings of the file are written")
+
+	vars["EXO_DATA_HOME"] = "/from/env"
+	fresh := filepath.Join(home, "fresh.yaml")
+	require.NoError(t, os.WriteFile(fresh, []byte("log:\n    level: warn\n"), 0644))
+	cfg, err = config.NewConfigIn(fresh, env)
+	require.NoError(t, err)
+	require.NoError(t, cfg.SaveDirs())
+	saved, err = os.ReadFile(fresh)
+	require.NoError(t, err)
+	assert.Equal(t, "log:\n    level: warn\n", string(saved), "the data home from the environment is not written")
+
+	delete(vars, "EXO_DATA_HOME")
+	cfg, err = config.NewConfigIn(fresh, env)
+	require.NoError(t, err)
+	cfg.RebaseDirs(cfg.Dir.DataHome, vault)
+	require.NoError(t, cfg.SaveDirs())
+	saved, err = os.ReadFile(fresh)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(saved), "log:\n    level: warn\ndir:\n    data_home: "+vault+"\n"), "a default data home is written")
+	assert.NotContains(t, string(saved), "editor")
+	reloaded, err := config.NewConfigIn(fresh, env)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(vault, "zettel"), reloaded.Dir.ZettelDir)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := config.ParseMode("0640")
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), mode)
+
+	mode, err = config.ParseMode("2775")
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSetgid|0775, mode)
+
+	for _, bad := range []string{"", "rw-r--r--", "0999", "17777"} {
+		_, err := config.ParseMode(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestTemplatesConfig_Modes(t *testing.T) {
+	file, dir, err := config.TemplatesConfig{}.Modes()
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), file)
+	assert.Equal(t, os.FileMode(0755), dir)
+
+	_, _, err = config.TemplatesConfig{FileMode: "0660", DirMode: "x"}.Modes()
+	assert.ErrorContains(t, err, "templates.dir_mode")
+}
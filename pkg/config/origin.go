@@ -0,0 +1,235 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// OriginKind is where an effective configuration value came from.
+type OriginKind string
+
+// The kinds of origins, from the weakest to the strongest.
+const (
+	FromDefault OriginKind = "default"
+	FromFile    OriginKind = "file"
+	FromEnv     OriginKind = "env"
+	FromFlag    OriginKind = "flag"
+)
+
+// Origin is where a configuration value came from: the configuration file
+// and line it is set on, the environment variable or the command-line flag
+// that overrides it, or the built-in default.
+type Origin struct {
+	Kind OriginKind
+	// Source is the configuration file, the environment variable or the
+	// flag; it is empty for defaults.
+	Source string
+	// Line is the line of the configuration file the value is set on, if
+	// known.
+	Line int
+}
+
+// String returns o as shown by "exo config show --origin", e.g.
+// "file /home/ada/.config/exo/config.yaml:3" or "env EDITOR".
+func (o Origin) String() string {
+	switch {
+	case o.Kind == FromDefault || o.Source == "":
+		return string(o.Kind)
+	case o.Line > 0:
+		return fmt.Sprintf("%s %s:%d", o.Kind, o.Source, o.Line)
+	}
+	return fmt.Sprintf("%s %s", o.Kind, o.Source)
+}
+
+// Origin returns where the value of key, a dotted path of the configuration
+// file such as "general.editor", came from. The keys of maps such as alias
+// take the origin of the map when they have none of their own.
+func (c *Config) Origin(key string) Origin {
+	key = strings.ToLower(key)
+	for {
+		if o, ok := c.origins[key]; ok {
+			return o
+		}
+		i := strings.LastIndex(key, ".")
+		if i < 0 {
+			return Origin{Kind: FromDefault}
+		}
+		key = key[:i]
+	}
+}
+
+// Override sets the value of key with set, on behalf of the command-line
+// flag, e.g. "--read-only". Save keeps writing the value key had before.
+func (c *Config) Override(key, flag string, set func(*Config)) {
+	key = strings.ToLower(key)
+	if _, ok := c.overridden[key]; !ok {
+		var before yaml.Node
+		if err := before.Encode(c); err == nil {
+			if n := lookupNode(&before, key); n != nil {
+				if c.overridden == nil {
+					c.overridden = make(map[string]*yaml.Node)
+				}
+				c.overridden[key] = n
+			}
+		}
+	}
+	set(c)
+	if c.origins == nil {
+		c.origins = make(map[string]Origin)
+	}
+	c.origins[key] = Origin{Kind: FromFlag, Source: flag}
+}
+
+// marshal encodes c as YAML, with the values flags override replaced by the
+// ones they had before.
+func (c *Config) marshal() ([]byte, error) {
+	if len(c.overridden) == 0 {
+		return yaml.Marshal(c)
+	}
+	var doc yaml.Node
+	if err := doc.Encode(c); err != nil {
+		return nil, err
+	}
+	for key, before := range c.overridden {
+		if n := lookupNode(&doc, key); n != nil {
+			*n = *before
+		}
+	}
+	return yaml.Marshal(&doc)
+}
+
+// loadOrigins returns the origins of the values v read from its
+// configuration file and of those env overrides.
+func loadOrigins(v *viper.Viper, env fs.Env) map[string]Origin {
+	origins := make(map[string]Origin)
+	if file := v.ConfigFileUsed(); file != "" {
+		lines := fileLines(file)
+		for _, key := range v.AllKeys() {
+			if v.InConfig(key) {
+				origins[key] = Origin{Kind: FromFile, Source: file, Line: lines[key]}
+			}
+		}
+	}
+	envKey := func(key, name string) {
+		if _, set := origins[key]; !set && env.Getenv(name) != "" {
+			origins[key] = Origin{Kind: FromEnv, Source: name}
+		}
+	}
+	envKey("dir.data_home", envDataHome)
+	if !env.Windows() {
+		envKey("dir.state_dir", "XDG_STATE_HOME")
+		envKey("backup.snapshot_dir", "XDG_STATE_HOME")
+		envKey("dir.cache_dir", "XDG_CACHE_HOME")
+	}
+	// EDITOR wins over the configuration file.
+	if env.Getenv("EDITOR") != "" {
+		origins["general.editor"] = Origin{Kind: FromEnv, Source: "EDITOR"}
+	}
+	return origins
+}
+
+// fileLines returns the line each key of the YAML file at path is set on, by
+// dotted lowercase key; it is empty when the file cannot be parsed.
+func fileLines(path string) map[string]int {
+	lines := make(map[string]int)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return lines
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || len(doc.Content) == 0 {
+		return lines
+	}
+	var walk func(n *yaml.Node, prefix string)
+	walk = func(n *yaml.Node, prefix string) {
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := prefix + strings.ToLower(n.Content[i].Value)
+			lines[key] = n.Content[i].Line
+			walk(n.Content[i+1], key+".")
+		}
+	}
+	walk(doc.Content[0], "")
+	return lines
+}
+
+// lookupNode returns the value of the dotted key in the YAML document or
+// mapping n, or nil.
+func lookupNode(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		n = n.Content[0]
+	}
+	for _, part := range strings.Split(key, ".") {
+		if n.Kind != yaml.MappingNode {
+			return nil
+		}
+		var next *yaml.Node
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if strings.EqualFold(n.Content[i].Value, part) {
+				next = n.Content[i+1]
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+// setNode sets the dotted key of the YAML document n to a copy of value,
+// adding the mappings leading to it that n lacks. The comments of the value
+// replaced are kept.
+func setNode(n *yaml.Node, key string, value *yaml.Node) {
+	if n.Kind != yaml.DocumentNode {
+		*n = yaml.Node{Kind: yaml.DocumentNode}
+	}
+	if len(n.Content) == 0 {
+		n.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	n = n.Content[0]
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if n.Kind != yaml.MappingNode {
+			*n = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		var next *yaml.Node
+		for j := 0; j+1 < len(n.Content); j += 2 {
+			if strings.EqualFold(n.Content[j].Value, part) {
+				next = n.Content[j+1]
+				break
+			}
+		}
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: part}, next)
+		}
+		if i == len(parts)-1 {
+			replaced := *value
+			replaced.HeadComment, replaced.LineComment, replaced.FootComment = next.HeadComment, next.LineComment, next.FootComment
+			*next = replaced
+		}
+		n = next
+	}
+}
+
+// indentOf returns the indentation of the nested keys of the YAML file data,
+// or that of yaml.Marshal, which Save writes with, when it has none.
+func indentOf(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if indent := len(line) - len(trimmed); indent >= 2 && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			return indent
+		}
+	}
+	return 4
+}
@@ -0,0 +1,370 @@
+// Package daemon answers the requests of editor plugins over a Unix socket,
+// from an index of the vault kept warm between requests, so that resolving a
+// link or finding backlinks does not pay for the startup of the exo command.
+//
+// The protocol is JSON-RPC 2.0 with one message per line. The methods are:
+//
+//	status     {}                          the vault and the number of notes indexed
+//	resolve    {"name"}                    the notes a name or wikilink target names
+//	search     {"query", "limit"}          the notes containing every word of query
+//	backlinks  {"note"}                    the notes linking to a note, by name or path
+//	create     {"title", "dir", "content"} create a note, as the HTTP API does
+//
+// Notes are returned with their path relative to the vault and their absolute
+// file path.
+package daemon
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+	"github.com/a-kostevski/exo/pkg/vault"
+)
+
+// Error codes of JSON-RPC 2.0, and those of the daemon.
+const (
+	CodeParse          = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternal       = -32603
+	CodeNotFound       = -32001
+	CodeConflict       = -32002
+)
+
+// Options configures a Daemon.
+type Options struct {
+	// Notes are the notes served and created, as by the HTTP API.
+	vault.Notes
+}
+
+// Daemon serves the notes of an index to the clients of a socket.
+type Daemon struct {
+	opts Options
+	ix   *store.Index
+
+	mu    sync.RWMutex
+	names *scan.Names
+}
+
+// Request is a JSON-RPC request. Requests without an ID are notifications and
+// get no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response, carrying either a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is the error of a failed request.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NoteResponse is a note returned by the daemon.
+type NoteResponse struct {
+	scan.Note
+	// Path is relative to the data home, replacing the absolute scan path.
+	Path string `json:"path"`
+	// File is the absolute path of the note.
+	File string `json:"file"`
+}
+
+// Status describes the vault served.
+type Status struct {
+	DataHome string `json:"data_home"`
+	Notes    int    `json:"notes"`
+}
+
+// New returns a Daemon answering from ix, which it keeps up to date through
+// Update.
+func New(ix *store.Index, opts Options) (*Daemon, error) {
+	d := &Daemon{opts: opts, ix: ix}
+	if err := d.loadNames(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Update brings the index up to date with the notes at paths, which changed.
+func (d *Daemon) Update(paths ...string) error {
+	if _, err := d.ix.Update(paths...); err != nil {
+		return err
+	}
+	return d.loadNames()
+}
+
+// loadNames indexes the names of the indexed notes for resolve.
+func (d *Daemon) loadNames() error {
+	notes, err := d.ix.Notes(store.Query{})
+	if err != nil {
+		return err
+	}
+	names := scan.NewNames(d.opts.DataHome(), notes)
+	d.mu.Lock()
+	d.names = names
+	d.mu.Unlock()
+	return nil
+}
+
+// Listen listens on the Unix socket at path, readable by the current user
+// only. A socket left behind by a daemon that is no longer running is
+// replaced; one still answering is not.
+func Listen(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(path); err == nil {
+		if conn, err := net.Dial("unix", path); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return ln, nil
+}
+
+// Serve answers the clients connecting to ln until ctx is done, then closes
+// ln.
+func (d *Daemon) Serve(ctx context.Context, ln net.Listener) error {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	go func() {
+		<-ctx.Done()
+		ln.Close()
+	}()
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.serveConn(ctx, conn)
+		}()
+	}
+}
+
+// serveConn answers the requests sent over conn, one per line, until the
+// client disconnects or ctx is done.
+func (d *Daemon) serveConn(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	enc := json.NewEncoder(conn)
+	sc := bufio.NewScanner(conn)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		if resp := d.Handle([]byte(line)); resp != nil {
+			if err := enc.Encode(resp); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Handle answers the request encoded in data, returning nil for
+// notifications.
+func (d *Daemon) Handle(data []byte) *Response {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return &Response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &Error{CodeParse, "invalid JSON: " + err.Error()}}
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		id := req.ID
+		if id == nil {
+			id = json.RawMessage("null")
+		}
+		return &Response{JSONRPC: "2.0", ID: id, Error: &Error{CodeInvalidRequest, `expected a "2.0" request with a method`}}
+	}
+	result, err := d.call(req.Method, req.Params)
+	if req.ID == nil {
+		return nil
+	}
+	resp := &Response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{CodeInternal, err.Error()}
+		}
+		resp.Result, resp.Error = nil, rpcErr
+	}
+	return resp
+}
+
+// call runs method with params.
+func (d *Daemon) call(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "status":
+		n, err := d.ix.Count()
+		if err != nil {
+			return nil, err
+		}
+		return Status{DataHome: d.opts.DataHome(), Notes: n}, nil
+	case "resolve":
+		var p struct {
+			Name string `json:"name"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return d.notes(d.resolve(p.Name)), nil
+	case "search":
+		var p struct {
+			Query string `json:"query"`
+			Limit int    `json:"limit"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		notes, err := d.ix.Search(p.Query)
+		if err != nil {
+			return nil, err
+		}
+		if p.Limit > 0 && len(notes) > p.Limit {
+			notes = notes[:p.Limit]
+		}
+		return d.notes(notes), nil
+	case "backlinks":
+		var p struct {
+			Note string `json:"note"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		n, err := d.resolveOne(p.Note)
+		if err != nil {
+			return nil, err
+		}
+		notes, err := d.ix.Backlinks(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		return d.notes(notes), nil
+	case "create":
+		var p struct {
+			Title   string `json:"title"`
+			Dir     string `json:"dir"`
+			Content string `json:"content"`
+		}
+		if err := decodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return d.create(p.Title, p.Dir, p.Content)
+	}
+	return nil, &Error{CodeMethodNotFound, fmt.Sprintf("unknown method %q", method)}
+}
+
+// decodeParams decodes the params of a request into v.
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return &Error{CodeInvalidParams, "invalid params: " + err.Error()}
+	}
+	return nil
+}
+
+// resolve returns the notes named name, which may be a wikilink target with a
+// heading or label, as in "Go channels#Buffering|buffers".
+func (d *Daemon) resolve(name string) []scan.Note {
+	name, _, _ = strings.Cut(name, "|")
+	name, _, _ = strings.Cut(name, "#")
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.names.Resolve(name)
+}
+
+// resolveOne returns the note named name, failing when no note or more than
+// one is.
+func (d *Daemon) resolveOne(name string) (scan.Note, error) {
+	notes := d.resolve(name)
+	switch len(notes) {
+	case 0:
+		return scan.Note{}, &Error{CodeNotFound, fmt.Sprintf("note not found: %s", name)}
+	case 1:
+		return notes[0], nil
+	}
+	return scan.Note{}, &Error{CodeConflict, fmt.Sprintf("%q names %d notes", name, len(notes))}
+}
+
+// create creates the note titled title in dir, relative to the data home, or
+// in the directory of new notes, with content or a heading.
+func (d *Daemon) create(title, dir, content string) (NoteResponse, error) {
+	path, err := d.opts.Create(title, dir, content)
+	if err != nil {
+		switch exoerrors.CodeOf(err) {
+		case exoerrors.Validation:
+			return NoteResponse{}, &Error{CodeInvalidParams, err.Error()}
+		case exoerrors.Conflict:
+			return NoteResponse{}, &Error{CodeConflict, err.Error()}
+		}
+		return NoteResponse{}, err
+	}
+	if err := d.Update(path); err != nil {
+		return NoteResponse{}, err
+	}
+	n, err := scan.ReadNote(path)
+	if err != nil {
+		return NoteResponse{}, err
+	}
+	return d.response(n), nil
+}
+
+func (d *Daemon) response(n scan.Note) NoteResponse {
+	return NoteResponse{Note: n, Path: d.opts.Rel(n.Path), File: n.Path}
+}
+
+// notes returns the responses for notes, never nil so that no notes are
+// encoded as an empty list.
+func (d *Daemon) notes(notes []scan.Note) []NoteResponse {
+	out := []NoteResponse{}
+	for _, n := range notes {
+		out = append(out, d.response(n))
+	}
+	return out
+}
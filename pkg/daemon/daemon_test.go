@@ -0,0 +1,189 @@
+package daemon_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/daemon"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newDaemon(t *testing.T) (*daemon.Daemon, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"0-inbox/Hello.md":    "# Hello\n\nChannels are pipes. See [[Other]].\n",
+		"ideas/Other.md":      "---\ntitle: Other\naliases: [Elsewhere]\n---\nNothing here.\n",
+		"templates/zettel.md": "# {{.Title}}\n",
+	} {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	templates := filepath.Join(dir, "templates")
+	ix, err := store.Open(filepath.Join(dir, ".exo", "index.db"), dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { ix.Close() })
+	_, err = ix.Sync(templates)
+	require.NoError(t, err)
+
+	ignore, err := scan.NewIgnore(dir, []string{"drafts/"})
+	require.NoError(t, err)
+	d, err := daemon.New(ix, daemon.Options{Notes: vault.Notes{
+		Config:     config.Config{Dir: config.DirConfig{DataHome: dir}},
+		NewNoteDir: filepath.Join(dir, "0-inbox"),
+		Exclude:    []string{templates},
+		Ignore:     ignore,
+	}})
+	require.NoError(t, err)
+	return d, dir
+}
+
+// call sends a request for method with params to d and decodes its result
+// into result.
+func call(t *testing.T, d *daemon.Daemon, method, params string, result interface{}) *daemon.Error {
+	t.Helper()
+	resp := d.Handle([]byte(`{"jsonrpc": "2.0", "id": 1, "method": "` + method + `", "params": ` + params + `}`))
+	require.NotNil(t, resp)
+	assert.Equal(t, "1", string(resp.ID))
+	if resp.Error != nil {
+		return resp.Error
+	}
+	data, err := json.Marshal(resp.Result)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, result))
+	return nil
+}
+
+func paths(notes []daemon.NoteResponse) []string {
+	var out []string
+	for _, n := range notes {
+		out = append(out, n.Path)
+	}
+	return out
+}
+
+func TestResolve(t *testing.T) {
+	d, dir := newDaemon(t)
+	var notes []daemon.NoteResponse
+	require.Nil(t, call(t, d, "resolve", `{"name": "elsewhere#Heading|label"}`, &notes))
+	require.Equal(t, []string{"ideas/Other.md"}, paths(notes))
+	assert.Equal(t, filepath.Join(dir, "ideas", "Other.md"), notes[0].File)
+	assert.Equal(t, "Other", notes[0].Title)
+
+	require.Nil(t, call(t, d, "resolve", `{"name": "Missing"}`, &notes))
+	assert.Empty(t, notes)
+}
+
+func TestSearchAndBacklinks(t *testing.T) {
+	d, _ := newDaemon(t)
+	var notes []daemon.NoteResponse
+	require.Nil(t, call(t, d, "search", `{"query": "pipes"}`, &notes))
+	assert.Equal(t, []string{"0-inbox/Hello.md"}, paths(notes))
+
+	require.Nil(t, call(t, d, "backlinks", `{"note": "Other"}`, &notes))
+	assert.Equal(t, []string{"0-inbox/Hello.md"}, paths(notes))
+
+	err := call(t, d, "backlinks", `{"note": "Missing"}`, &notes)
+	require.NotNil(t, err)
+	assert.Equal(t, daemon.CodeNotFound, err.Code)
+
+	var status daemon.Status
+	require.Nil(t, call(t, d, "status", `{}`, &status))
+	assert.Equal(t, 2, status.Notes)
+}
+
+func TestCreate(t *testing.T) {
+	d, dir := newDaemon(t)
+	var n daemon.NoteResponse
+	require.Nil(t, call(t, d, "create", `{"title": "New idea"}`, &n))
+	assert.Equal(t, "0-inbox/New idea.md", n.Path)
+	data, err := os.ReadFile(filepath.Join(dir, "0-inbox", "New idea.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# New idea\n", string(data))
+
+	var notes []daemon.NoteResponse
+	require.Nil(t, call(t, d, "resolve", `{"name": "New idea"}`, &notes))
+	assert.Equal(t, []string{"0-inbox/New idea.md"}, paths(notes), "created notes are indexed")
+
+	rpcErr := call(t, d, "create", `{"title": "New idea"}`, &n)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, daemon.CodeConflict, rpcErr.Code)
+	rpcErr = call(t, d, "create", `{"title": "x", "dir": "templates"}`, &n)
+	require.NotNil(t, rpcErr)
+	assert.Equal(t, daemon.CodeInvalidParams, rpcErr.Code)
+	rpcErr = call(t, d, "create", `{"title": "x", "dir": "0-inbox/drafts"}`, &n)
+	require.NotNil(t, rpcErr, "ignored directories are out of reach")
+	assert.Equal(t, daemon.CodeInvalidParams, rpcErr.Code)
+	assert.NoDirExists(t, filepath.Join(dir, "0-inbox", "drafts"))
+}
+
+func TestHandle_Errors(t *testing.T) {
+	d, _ := newDaemon(t)
+	for data, code := range map[string]int{
+		`{`:                             daemon.CodeParse,
+		`{"id": 1, "method": "status"}`: daemon.CodeInvalidRequest,
+		`{"jsonrpc": "2.0", "id": 1, "method": "rename"}`:               daemon.CodeMethodNotFound,
+		`{"jsonrpc": "2.0", "id": 1, "method": "search", "params": []}`: daemon.CodeInvalidParams,
+	} {
+		resp := d.Handle([]byte(data))
+		require.NotNil(t, resp, data)
+		require.NotNil(t, resp.Error, data)
+		assert.Equal(t, code, resp.Error.Code, data)
+	}
+	assert.Nil(t, d.Handle([]byte(`{"jsonrpc": "2.0", "method": "status"}`)), "notifications get no response")
+}
+
+func TestServe(t *testing.T) {
+	d, _ := newDaemon(t)
+	socket := filepath.Join(t.TempDir(), "exo.sock")
+	ln, err := daemon.Listen(socket)
+	require.NoError(t, err)
+	info, err := os.Stat(socket)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = daemon.Listen(socket)
+	assert.Error(t, err, "a running daemon is not replaced")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Serve(ctx, ln) }()
+
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte(`{"jsonrpc": "2.0", "id": "a", "method": "resolve", "params": {"name": "Hello"}}` + "\n" +
+		`{"jsonrpc": "2.0", "id": "b", "method": "status"}` + "\n"))
+	require.NoError(t, err)
+	sc := bufio.NewScanner(conn)
+	var ids []string
+	for i := 0; i < 2 && sc.Scan(); i++ {
+		var resp daemon.Response
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &resp))
+		assert.Nil(t, resp.Error)
+		ids = append(ids, string(resp.ID))
+	}
+	assert.Equal(t, []string{`"a"`, `"b"`}, ids)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestListen_Stale(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "exo.sock")
+	require.NoError(t, os.WriteFile(socket, nil, 0600))
+	ln, err := daemon.Listen(socket)
+	require.NoError(t, err, "a socket left behind is replaced")
+	ln.Close()
+}
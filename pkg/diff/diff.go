@@ -0,0 +1,191 @@
+// Package diff compares texts, such as two notes or two versions of a note,
+// line by line as unified diffs or word by word.
+package diff
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// ANSI escape sequences coloring the changes.
+const (
+	red   = "\x1b[31m"
+	green = "\x1b[32m"
+	cyan  = "\x1b[36m"
+	bold  = "\x1b[1m"
+	reset = "\x1b[0m"
+)
+
+// Context is the number of unchanged lines shown around changes.
+const Context = 3
+
+// Unified returns the unified diff from a, named from, to b, named to, or an
+// empty string when they are the same. With color, removed lines are red and
+// added lines green.
+func Unified(a, b, from, to string, color bool) (string, error) {
+	out, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: from,
+		ToFile:   to,
+		Context:  Context,
+	})
+	if err != nil || !color || out == "" {
+		return out, err
+	}
+	lines := strings.Split(out, "\n")
+	for i, line := range lines {
+		switch {
+		case line == "":
+		case i < 2:
+			// The names of the files.
+			lines[i] = bold + line + reset
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = cyan + line + reset
+		case strings.HasPrefix(line, "-"):
+			lines[i] = red + line + reset
+		case strings.HasPrefix(line, "+"):
+			lines[i] = green + line + reset
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// splitLines splits text into lines, each ending with a line break.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// tokens splits text into words, runs of spaces and line breaks.
+var tokens = regexp.MustCompile(`\n|[^\S\n]+|\S+`)
+
+// line is a line of a word diff.
+type line struct {
+	text    string
+	changed bool
+	// number is the line of the new text it is at, from 1.
+	number int
+}
+
+// Words returns the word diff from a to b, or an empty string when they are
+// the same: the lines with changes, and Context lines around them, with the
+// words removed from a marked as [-removed-] and the words added in b as
+// {+added+}, or, with color, in red and green. Groups of lines are introduced
+// by "@@ line N @@", N being the line of b they start at.
+func Words(a, b string, color bool) string {
+	if a == b {
+		return ""
+	}
+	at, bt := tokens.FindAllString(a, -1), tokens.FindAllString(b, -1)
+	m := difflib.NewMatcherWithJunk(at, bt, false, nil)
+
+	var (
+		lines   []line
+		current line
+		number  = 1
+	)
+	current.number = number
+	// add appends text to the lines, marking it as removed if kind is 'd' or
+	// added if kind is 'i'.
+	add := func(text string, kind byte) {
+		if text == "\n" {
+			current.changed = current.changed || kind != 'e'
+			lines = append(lines, current)
+			if kind != 'd' {
+				number++
+			}
+			current = line{number: number}
+			return
+		}
+		switch kind {
+		case 'd':
+			text = mark(text, "[-", "-]", red, color)
+			current.changed = true
+		case 'i':
+			text = mark(text, "{+", "+}", green, color)
+			current.changed = true
+		}
+		current.text += text
+	}
+	// addAll adds tokens, grouping the words and spaces between line breaks
+	// so that they are marked once.
+	addAll := func(tokens []string, kind byte) {
+		run := ""
+		for _, t := range tokens {
+			if t == "\n" {
+				if run != "" {
+					add(run, kind)
+					run = ""
+				}
+				add(t, kind)
+				continue
+			}
+			run += t
+		}
+		if run != "" {
+			add(run, kind)
+		}
+	}
+	for _, op := range m.GetOpCodes() {
+		switch op.Tag {
+		case 'e':
+			addAll(bt[op.J1:op.J2], 'e')
+		case 'd':
+			addAll(at[op.I1:op.I2], 'd')
+		case 'i':
+			addAll(bt[op.J1:op.J2], 'i')
+		case 'r':
+			addAll(at[op.I1:op.I2], 'd')
+			addAll(bt[op.J1:op.J2], 'i')
+		}
+	}
+	if current.text != "" || current.changed {
+		lines = append(lines, current)
+	}
+	return hunks(lines, color)
+}
+
+// mark marks text as changed, between the markers start and end or, with color,
+// in the color code.
+func mark(text, start, end, code string, color bool) string {
+	if color {
+		return code + text + reset
+	}
+	return start + text + end
+}
+
+// hunks returns the changed lines, with Context lines around them.
+func hunks(lines []line, color bool) string {
+	show := make([]bool, len(lines))
+	for i, l := range lines {
+		if !l.changed {
+			continue
+		}
+		for j := max(0, i-Context); j <= min(len(lines)-1, i+Context); j++ {
+			show[j] = true
+		}
+	}
+	var sb strings.Builder
+	for i := range lines {
+		if !show[i] {
+			continue
+		}
+		if i == 0 || !show[i-1] {
+			header := fmt.Sprintf("@@ line %d @@", lines[i].number)
+			if color {
+				header = cyan + header + reset
+			}
+			sb.WriteString(header + "\n")
+		}
+		sb.WriteString(lines[i].text + "\n")
+	}
+	return sb.String()
+}
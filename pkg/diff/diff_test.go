@@ -0,0 +1,54 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnified(t *testing.T) {
+	out, err := diff.Unified("a\nb\n", "a\nc\n", "old.md", "new.md", false)
+	require.NoError(t, err)
+	assert.Equal(t, "--- old.md\n+++ new.md\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n", out)
+
+	out, err = diff.Unified("a\nb\n", "a\nc\n", "old.md", "new.md", true)
+	require.NoError(t, err)
+	assert.Contains(t, out, "\x1b[31m-b\x1b[0m\n\x1b[32m+c\x1b[0m\n")
+
+	out, err = diff.Unified("same\n", "same\n", "old.md", "new.md", true)
+	require.NoError(t, err)
+	assert.Empty(t, out)
+}
+
+func TestWords(t *testing.T) {
+	a := "# Day\n\none\ntwo\nthree\nfour\nfive\nsix\n- Went for a run\n"
+	b := "# Day\n\none\ntwo\nthree\nfour\nfive\nsix\n- Went for a long walk\n"
+	assert.Equal(t, "@@ line 6 @@\nfour\nfive\nsix\n- Went for a [-run-]{+long walk+}\n", diff.Words(a, b, false))
+	assert.Equal(t, "\x1b[36m@@ line 6 @@\x1b[0m\nfour\nfive\nsix\n- Went for a \x1b[31mrun\x1b[0m\x1b[32mlong walk\x1b[0m\n", diff.Words(a, b, true))
+
+	assert.Equal(t, "@@ line 1 @@\none\n{+two+}\nthree\n", diff.Words("one\nthree\n", "one\ntwo\nthree\n", false))
+	assert.Equal(t, "@@ line 1 @@\none\n[-two-]\nthree\n", diff.Words("one\ntwo\nthree\n", "one\nthree\n", false))
+	assert.Empty(t, diff.Words("same\n", "same\n", false))
+}
+
+func TestMerge(t *testing.T) {
+	base := "# Day\n\n- one\n- two\n- three\n"
+
+	merged, conflict := diff.Merge(base, "# Day\n\n- one\n- two\n- three\n- four\n", "# Day\n\n- zero\n- one\n- two\n- three\n", "exo", "disk")
+	assert.False(t, conflict)
+	assert.Equal(t, "# Day\n\n- zero\n- one\n- two\n- three\n- four\n", merged)
+
+	merged, conflict = diff.Merge(base, "# Day\n\n- one\n- 2\n- three\n", "# Day\n\n- one\n- 2\n- three\n", "exo", "disk")
+	assert.False(t, conflict, "the same change on both sides")
+	assert.Equal(t, "# Day\n\n- one\n- 2\n- three\n", merged)
+
+	merged, conflict = diff.Merge(base, "# Day\n\n- one\n- deux\n- three\n", "# Day\n\n- one\n- zwei\n- three\n", "exo", "disk")
+	assert.True(t, conflict)
+	assert.Equal(t, "# Day\n\n- one\n<<<<<<< exo\n- deux\n=======\n- zwei\n>>>>>>> disk\n- three\n", merged)
+
+	merged, conflict = diff.Merge(base, base, "# Day\n", "exo", "disk")
+	assert.False(t, conflict)
+	assert.Equal(t, "# Day\n", merged)
+}
@@ -0,0 +1,110 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// hunk is a change of a text from a base text: the lines [i1, i2) of the base
+// replaced by lines.
+type hunk struct {
+	i1, i2 int
+	lines  []string
+	ours   bool
+}
+
+// Merge merges the changes made to base in ours and in theirs, line by line.
+// Where both changed the same lines differently, the merged text holds both
+// versions between conflict markers, as git writes them:
+//
+//	<<<<<<< oursName
+//	our lines
+//	=======
+//	their lines
+//	>>>>>>> theirsName
+//
+// It reports whether there were such conflicts.
+func Merge(base, ours, theirs, oursName, theirsName string) (string, bool) {
+	b := splitLines(base)
+	changes := append(hunks3(b, splitLines(ours), true), hunks3(b, splitLines(theirs), false)...)
+	// Order the changes by where they start, ours first.
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].i1 < changes[j].i1 })
+
+	var sb strings.Builder
+	conflict := false
+	pos := 0
+	for len(changes) > 0 {
+		// Group the changes overlapping or touching the lines of the first
+		// one; the changes of one side are never next to each other.
+		lo, hi := changes[0].i1, changes[0].i2
+		n := 1
+		for n < len(changes) && changes[n].i1 <= hi {
+			if changes[n].i2 > hi {
+				hi = changes[n].i2
+			}
+			n++
+		}
+		group := changes[:n]
+		changes = changes[n:]
+
+		sb.WriteString(strings.Join(b[pos:lo], ""))
+		var mine, others []hunk
+		for _, h := range group {
+			if h.ours {
+				mine = append(mine, h)
+			} else {
+				others = append(others, h)
+			}
+		}
+		oursText, theirsText := apply(b, mine, lo, hi), apply(b, others, lo, hi)
+		switch {
+		case len(others) == 0:
+			sb.WriteString(oursText)
+		case len(mine) == 0, oursText == theirsText:
+			sb.WriteString(theirsText)
+		default:
+			conflict = true
+			sb.WriteString("<<<<<<< " + oursName + "\n" + withBreak(oursText))
+			sb.WriteString("=======\n" + withBreak(theirsText))
+			sb.WriteString(">>>>>>> " + theirsName + "\n")
+		}
+		pos = hi
+	}
+	sb.WriteString(strings.Join(b[pos:], ""))
+	return sb.String(), conflict
+}
+
+// hunks3 returns the changes turning base into other.
+func hunks3(base, other []string, ours bool) []hunk {
+	var changes []hunk
+	for _, op := range difflib.NewMatcherWithJunk(base, other, false, nil).GetOpCodes() {
+		if op.Tag != 'e' {
+			changes = append(changes, hunk{i1: op.I1, i2: op.I2, lines: other[op.J1:op.J2], ours: ours})
+		}
+	}
+	return changes
+}
+
+// apply returns the lines [lo, hi) of base with the changes, all within them,
+// applied.
+func apply(base []string, changes []hunk, lo, hi int) string {
+	var sb strings.Builder
+	pos := lo
+	for _, h := range changes {
+		sb.WriteString(strings.Join(base[pos:h.i1], ""))
+		sb.WriteString(strings.Join(h.lines, ""))
+		pos = h.i2
+	}
+	sb.WriteString(strings.Join(base[pos:hi], ""))
+	return sb.String()
+}
+
+// withBreak returns text ending with a line break, unless it is empty.
+func withBreak(text string) string {
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return text
+}
@@ -0,0 +1,117 @@
+// Package errors classifies the errors of exo into categories, so that the
+// command line can exit with a distinct status for each and report them in a
+// machine-readable form.
+package errors
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// Code is the category of an error.
+type Code string
+
+// The categories of errors.
+const (
+	// NotFound means a note, template or file does not exist.
+	NotFound Code = "not_found"
+	// Conflict means the operation clashes with the existing state, e.g. a
+	// note to be created already exists or a name is ambiguous.
+	Conflict Code = "conflict"
+	// Usage means the command line is invalid, e.g. an unknown command or
+	// flag, or the wrong number of arguments.
+	Usage Code = "usage"
+	// Validation means the input, such as an argument or a template, is invalid.
+	Validation Code = "validation"
+	// IO means reading or writing failed.
+	IO Code = "io"
+	// Config means the configuration is missing, unreadable or invalid.
+	Config Code = "config"
+	// Internal is the category of errors that have not been classified.
+	Internal Code = "internal"
+)
+
+// The exit statuses of exo. Scripts may rely on them; do not renumber.
+const (
+	ExitOK       = 0
+	ExitGeneric  = 1
+	ExitUsage    = 2
+	ExitNotFound = 3
+	ExitConflict = 4
+	ExitConfig   = 5
+)
+
+// exitCodes maps the categories to exit statuses.
+var exitCodes = map[Code]int{
+	NotFound:   ExitNotFound,
+	Conflict:   ExitConflict,
+	Usage:      ExitUsage,
+	Validation: ExitUsage,
+	IO:         ExitGeneric,
+	Config:     ExitConfig,
+	Internal:   ExitGeneric,
+}
+
+// Error is an error with a category.
+type Error struct {
+	Code Code
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of category code formatted like fmt.Errorf, so that
+// %w wraps an underlying error.
+func New(code Code, format string, args ...interface{}) error {
+	return &Error{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// Wrap returns err with category code, or nil when err is nil. Categories
+// already in err take precedence, as they are more specific.
+func Wrap(code Code, err error) error {
+	var e *Error
+	if err == nil || errors.As(err, &e) {
+		return err
+	}
+	return &Error{Code: code, Err: err}
+}
+
+// CodeOf returns the category of err: that of the first Error in its chain,
+// else one derived from the file system errors it wraps, else Internal.
+func CodeOf(err error) Code {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	var pathErr *os.PathError
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		return NotFound
+	case errors.Is(err, os.ErrExist):
+		return Conflict
+	case errors.Is(err, os.ErrPermission), errors.As(err, &pathErr):
+		return IO
+	}
+	return Internal
+}
+
+// Is reports whether err is of category code.
+func Is(err error, code Code) bool {
+	return err != nil && CodeOf(err) == code
+}
+
+// ExitCode returns the exit status for err: ExitOK for nil, else the status of
+// its category.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return exitCodes[CodeOf(err)]
+}
@@ -0,0 +1,64 @@
+package errors_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodeOf(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want exoerrors.Code
+	}{
+		{"categorized", exoerrors.New(exoerrors.Validation, "bad title"), exoerrors.Validation},
+		{"wrapped category", fmt.Errorf("creating note: %w", exoerrors.New(exoerrors.Conflict, "exists")), exoerrors.Conflict},
+		{"not exist", fmt.Errorf("open: %w", os.ErrNotExist), exoerrors.NotFound},
+		{"exist", os.ErrExist, exoerrors.Conflict},
+		{"path error", &os.PathError{Op: "write", Path: "/x", Err: errors.New("disk full")}, exoerrors.IO},
+		{"unclassified", errors.New("boom"), exoerrors.Internal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, exoerrors.CodeOf(tt.err))
+			assert.True(t, exoerrors.Is(tt.err, tt.want))
+		})
+	}
+}
+
+func TestWrap(t *testing.T) {
+	assert.NoError(t, exoerrors.Wrap(exoerrors.IO, nil))
+
+	err := exoerrors.Wrap(exoerrors.Config, os.ErrNotExist)
+	assert.Equal(t, exoerrors.Config, exoerrors.CodeOf(err))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	inner := exoerrors.New(exoerrors.Validation, "bad")
+	assert.Equal(t, exoerrors.Validation, exoerrors.CodeOf(exoerrors.Wrap(exoerrors.Config, inner)),
+		"the more specific category is kept")
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{nil, 0},
+		{errors.New("boom"), 1},
+		{exoerrors.New(exoerrors.IO, "failed"), 1},
+		{exoerrors.New(exoerrors.Usage, "unknown flag"), 2},
+		{exoerrors.New(exoerrors.Validation, "bad title"), 2},
+		{exoerrors.New(exoerrors.NotFound, "missing"), 3},
+		{fmt.Errorf("open: %w", os.ErrNotExist), 3},
+		{exoerrors.New(exoerrors.Conflict, "exists"), 4},
+		{exoerrors.New(exoerrors.Config, "invalid"), 5},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, exoerrors.ExitCode(tt.err), "%v", tt.err)
+	}
+}
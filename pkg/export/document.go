@@ -0,0 +1,204 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/render"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Document is a set of notes exported as a single file, such as a PDF.
+type Document struct {
+	Title string
+	Notes []DocumentNote
+	// Unresolved lists the wikilinks that are not links to notes of the
+	// document.
+	Unresolved []UnresolvedLink
+}
+
+// DocumentNote is a note of a Document.
+type DocumentNote struct {
+	Note scan.Note
+	// ID is the anchor of the note in the document.
+	ID string
+	// Body is the Markdown body of the note, without frontmatter, with embeds
+	// expanded, wikilinks to notes of the document turned into links to their
+	// anchors and the paths of images made absolute. Other wikilinks are
+	// replaced by their label.
+	Body string
+}
+
+// NewDocument reads notes, in order, into a document titled title. Embeds are
+// resolved among names, the notes of the vault under root.
+func NewDocument(title string, notes []scan.Note, root string, names *scan.Names) (*Document, error) {
+	doc := &Document{Title: title}
+	ids := make(map[string]bool)
+	keys := make([]string, len(notes))
+	for _, n := range notes {
+		// Notes are told apart from the headings in them, which are identified
+		// by their slug.
+		base := "note-" + slug(n.Title)
+		id := base
+		for i := 2; ids[id]; i++ {
+			id = base + "-" + strconv.Itoa(i)
+		}
+		ids[id] = true
+		doc.Notes = append(doc.Notes, DocumentNote{Note: n, ID: id})
+		keys[len(doc.Notes)-1] = id
+	}
+	index := newLinkIndex(root, notes, keys, names)
+
+	transcluder := &render.Transcluder{Resolve: func(target string) (string, error) {
+		if matches := names.Resolve(target); len(matches) == 1 {
+			return matches[0].Path, nil
+		}
+		return "", fmt.Errorf("cannot resolve %s", target)
+	}}
+	unresolved := make(unresolvedLinks)
+	for i, n := range doc.Notes {
+		content, err := os.ReadFile(n.Note.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		_, body := frontmatter.Split(string(content))
+		if body, err = transcluder.Expand(n.Note.Path, body); err != nil {
+			return nil, err
+		}
+		body = resolveAnchors(body, n.Note.Path, index, unresolved)
+		doc.Notes[i].Body = absoluteImages(body, filepath.Dir(n.Note.Path))
+	}
+	doc.Unresolved = unresolved.sorted()
+	return doc, nil
+}
+
+// resolveAnchors rewrites [[target#heading|label]] links in the body of the
+// note at notePath to notes in index as Markdown links to the heading or,
+// without one, to the note. Links to other notes are replaced by their label
+// and recorded.
+func resolveAnchors(body, notePath string, index *linkIndex, unresolved unresolvedLinks) string {
+	return md.RewriteLinks(body, func(l md.Link) string {
+		if !l.Wiki {
+			return l.Text
+		}
+		label := l.Label
+		if label == "" {
+			label = l.Target
+		}
+		id, reason := index.resolve(l.Target)
+		if reason != "" {
+			unresolved.add(index, notePath, l.Target, reason)
+			return label
+		}
+		if l.Anchor != "" {
+			id = slug(l.Anchor)
+		}
+		return "[" + label + "](#" + id + ")"
+	})
+}
+
+// absoluteImages rewrites the paths of images relative to dir, the directory
+// of the note body is from, as absolute paths, as the document is not written
+// next to the note.
+func absoluteImages(body, dir string) string {
+	return md.RewriteLinks(body, func(l md.Link) string {
+		if l.Wiki || !l.Embed || strings.Contains(l.Target, ":") || strings.HasPrefix(l.Target, "/") || strings.HasPrefix(l.Target, "#") {
+			return l.Text
+		}
+		l.Target = filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(l.Target)))
+		return l.String()
+	})
+}
+
+// DefaultPageTemplate lays a document out as one HTML page, each note
+// starting on a new page when printed.
+const DefaultPageTemplate = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+@page { size: {{.PageSize}}; margin: 2cm; }
+{{.CSS}}
+</style>
+</head>
+<body>
+{{range .Notes}}<article id="{{.ID}}">
+{{.HTML}}</article>
+{{end}}</body>
+</html>
+`
+
+// DefaultCSS is the style of documents without a stylesheet of their own.
+const DefaultCSS = `body { font-family: Georgia, serif; font-size: 11pt; line-height: 1.5; }
+h1, h2, h3, h4, h5, h6 { font-family: Helvetica, Arial, sans-serif; }
+pre, code { font-family: Menlo, Consolas, monospace; font-size: 9pt; }
+pre { background: #f5f5f5; padding: 0.5em; white-space: pre-wrap; }
+blockquote { border-left: 3px solid #ccc; margin-left: 0; padding-left: 1em; color: #555; }
+img { max-width: 100%; }
+article + article { break-before: page; }`
+
+// PageOptions configures the HTML page of a document.
+type PageOptions struct {
+	// Template is the html/template source of the page; it defaults to
+	// DefaultPageTemplate. It is given the Title, PageSize and CSS of the
+	// document and its Notes, each with an ID, a Title and its HTML.
+	Template string
+	// CSS is the stylesheet of the page; it defaults to DefaultCSS.
+	CSS string
+	// PageSize is the CSS page size, e.g. "A4" or "letter"; it defaults to A4.
+	PageSize string
+	// Renderer converts note bodies to HTML; it defaults to MarkdownRenderer.
+	Renderer Renderer
+}
+
+// HTML renders the document as a standalone HTML page.
+func (d *Document) HTML(opts PageOptions) (string, error) {
+	if opts.Template == "" {
+		opts.Template = DefaultPageTemplate
+	}
+	if opts.CSS == "" {
+		opts.CSS = DefaultCSS
+	}
+	if opts.PageSize == "" {
+		opts.PageSize = "A4"
+	}
+	if opts.Renderer == nil {
+		opts.Renderer = MarkdownRenderer{}
+	}
+	tmpl, err := template.New("page").Parse(opts.Template)
+	if err != nil {
+		return "", fmt.Errorf("invalid page template: %w", err)
+	}
+
+	type pageNote struct {
+		ID, Title string
+		HTML      template.HTML
+	}
+	data := struct {
+		Title    string
+		PageSize template.CSS
+		CSS      template.CSS
+		Notes    []pageNote
+	}{Title: d.Title, PageSize: template.CSS(opts.PageSize), CSS: template.CSS(opts.CSS)}
+	for _, n := range d.Notes {
+		rendered, err := opts.Renderer.Render(n.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to render %s: %w", n.Note.Path, err)
+		}
+		data.Notes = append(data.Notes, pageNote{ID: n.ID, Title: n.Note.Title, HTML: template.HTML(rendered)})
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render page template: %w", err)
+	}
+	return buf.String(), nil
+}
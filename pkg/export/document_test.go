@@ -0,0 +1,75 @@
+package export_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDocument(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "0-inbox", "Go channels.md"), "---\ntags: [go]\n---\n# Go channels\n\nSee [[Concurrency#Pipelines|pipelines]] and [[Missing note]].\n\n![[Snippet]]\n\n![diagram](../assets/chan.png)\n")
+	writeFile(t, filepath.Join(vault, "zettel", "Concurrency.md"), "# Concurrency\n\n## Pipelines\n\nBack to [[Go channels]].\n")
+	writeFile(t, filepath.Join(vault, "zettel", "Snippet.md"), "Embedded [[Concurrency]] text.\n")
+	all, err := scan.Scan(vault)
+	require.NoError(t, err)
+	names := scan.NewNames(vault, all)
+
+	var notes []scan.Note
+	for _, title := range []string{"Go channels", "Concurrency"} {
+		notes = append(notes, names.Resolve(title)[0])
+	}
+	doc, err := export.NewDocument("Go", notes, vault, names)
+	require.NoError(t, err)
+
+	require.Len(t, doc.Notes, 2)
+	assert.Equal(t, "note-go-channels", doc.Notes[0].ID)
+	assert.Contains(t, doc.Notes[0].Body, "See [pipelines](#pipelines) and Missing note.")
+	assert.Contains(t, doc.Notes[0].Body, "Embedded [Concurrency](#note-concurrency) text.")
+	assert.Contains(t, doc.Notes[0].Body, "![diagram]("+filepath.ToSlash(filepath.Join(vault, "assets", "chan.png"))+")")
+	assert.NotContains(t, doc.Notes[0].Body, "tags:")
+	assert.Equal(t, "\n# Concurrency\n\n## Pipelines\n\nBack to [Go channels](#note-go-channels).\n"[1:], doc.Notes[1].Body)
+	assert.Equal(t, []export.UnresolvedLink{{From: "0-inbox/Go channels.md", Target: "Missing note", Reason: export.ReasonMissing}}, doc.Unresolved)
+
+	page, err := doc.HTML(export.PageOptions{PageSize: "letter"})
+	require.NoError(t, err)
+	assert.Contains(t, page, "<title>Go</title>")
+	assert.Contains(t, page, "@page { size: letter; margin: 2cm; }")
+	assert.Contains(t, page, "article + article { break-before: page; }")
+	assert.Contains(t, page, `<article id="note-go-channels">`)
+	assert.Contains(t, page, `<a href="#note-go-channels">Go channels</a>`)
+
+	page, err = doc.HTML(export.PageOptions{Template: "{{range .Notes}}[{{.Title}}]{{end}} {{.CSS}}", CSS: "h1 {}"})
+	require.NoError(t, err)
+	assert.Equal(t, "[Go channels][Concurrency] h1 {}", page)
+
+	_, err = doc.HTML(export.PageOptions{Template: "{{.Missing"})
+	assert.ErrorContains(t, err, "invalid page template")
+}
+
+func TestNewDocument_ResolvesNames(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "20250208143005 Channels.md"), "---\naliases: [chans]\n---\n# Go channels\n\nSee [[Pipes]].\n")
+	writeFile(t, filepath.Join(vault, "zettel", "Pipes.md"), "---\nid: p-1\n---\n# Pipelines\n\nOn [[20250208143005]], [[chans]], [[Go channels]] and [[p-1]].\n\nNot [[Draft]], [[Twin]] nor [[Gone]].\n")
+	writeFile(t, filepath.Join(vault, "Draft.md"), "# Draft\n")
+	writeFile(t, filepath.Join(vault, "a", "Twin.md"), "# Twin\n")
+	writeFile(t, filepath.Join(vault, "b", "Twin.md"), "# Twin\n")
+	all, err := scan.Scan(vault)
+	require.NoError(t, err)
+	names := scan.NewNames(vault, all)
+
+	notes := []scan.Note{names.Resolve("Go channels")[0], names.Resolve("Pipes")[0], names.Resolve("a/Twin")[0], names.Resolve("b/Twin")[0]}
+	doc, err := export.NewDocument("Go", notes, vault, names)
+	require.NoError(t, err)
+	assert.Contains(t, doc.Notes[1].Body, "On [20250208143005](#note-go-channels), [chans](#note-go-channels), [Go channels](#note-go-channels) and [p-1](#note-pipelines).")
+	assert.Contains(t, doc.Notes[1].Body, "Not Draft, Twin nor Gone.")
+	assert.Equal(t, []export.UnresolvedLink{
+		{From: "zettel/Pipes.md", Target: "Draft", Reason: export.ReasonNotExported},
+		{From: "zettel/Pipes.md", Target: "Gone", Reason: export.ReasonMissing},
+		{From: "zettel/Pipes.md", Target: "Twin", Reason: export.ReasonAmbiguous},
+	}, doc.Unresolved)
+}
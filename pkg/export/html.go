@@ -0,0 +1,319 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/render"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// HTMLOptions configures an HTML export.
+type HTMLOptions struct {
+	// Source is the vault directory (data_home) to export.
+	Source string
+	// Out is the directory the site is written to.
+	Out string
+	// Exclude lists directories under Source that are not exported (e.g. templates).
+	Exclude []string
+	// Ignore matches the files and directories under Source that are not
+	// exported, as listed in .exoignore.
+	Ignore *scan.Ignore
+	// Renderer converts note bodies to HTML; it defaults to MarkdownRenderer.
+	Renderer Renderer
+}
+
+// HTMLResult summarizes an HTML export.
+type HTMLResult struct {
+	Notes       int
+	Tags        int
+	Attachments int
+	// Unresolved lists the wikilinks that did not match an exported note.
+	Unresolved []UnresolvedLink
+}
+
+// page is a note together with its location in the exported site.
+type page struct {
+	note scan.Note
+	url  string // slash-separated path relative to the site root
+}
+
+// HTML renders every note under opts.Source to a static site in opts.Out: one page
+// per note with embeds expanded and wikilinks resolved to relative URLs, an index
+// page, one page per tag,
+// and every non-note file copied alongside as an attachment.
+func HTML(opts HTMLOptions) (*HTMLResult, error) {
+	if opts.Renderer == nil {
+		opts.Renderer = MarkdownRenderer{}
+	}
+	source, err := filepath.Abs(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	out, err := filepath.Abs(opts.Out)
+	if err != nil {
+		return nil, err
+	}
+	if isWithin(source, out) {
+		// Do not export the site into itself on a later run.
+		opts.Exclude = append(opts.Exclude, out)
+	}
+
+	notes, err := scan.ScanContext(context.Background(), scan.WalkOptions{Ignore: opts.Ignore}, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan notes: %w", err)
+	}
+	var pages []page
+	for _, n := range notes {
+		if scan.Excluded(n.Path, opts.Exclude) {
+			continue
+		}
+		rel, err := filepath.Rel(source, n.Path)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, page{note: n, url: filepath.ToSlash(strings.TrimSuffix(rel, scan.NoteExtension)) + ".html"})
+	}
+	sort.Slice(pages, func(i, j int) bool {
+		return strings.ToLower(pages[i].note.Title) < strings.ToLower(pages[j].note.Title)
+	})
+
+	exported := make([]scan.Note, len(pages))
+	urls := make([]string, len(pages))
+	for i, p := range pages {
+		exported[i], urls[i] = p.note, p.url
+	}
+	index := newLinkIndex(source, exported, urls, scan.NewNames(source, notes))
+	names := scan.NewNames(source, exported)
+	transcluder := &render.Transcluder{Resolve: func(target string) (string, error) {
+		if matches := names.Resolve(target); len(matches) == 1 {
+			return matches[0].Path, nil
+		}
+		return "", fmt.Errorf("cannot resolve %s", target)
+	}}
+
+	result := &HTMLResult{}
+	unresolved := make(unresolvedLinks)
+	tags := make(map[string][]page)
+	for _, p := range pages {
+		content, err := os.ReadFile(p.note.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		_, body := frontmatter.Split(string(content))
+		if body, err = transcluder.Expand(p.note.Path, body); err != nil {
+			return nil, err
+		}
+		body = resolveWikilinks(body, p.note.Path, p.url, index, unresolved)
+		rendered, err := opts.Renderer.Render(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", p.note.Path, err)
+		}
+
+		var tagLinks []string
+		for _, tag := range p.note.Tags {
+			tags[tag] = append(tags[tag], p)
+			tagLinks = append(tagLinks, link(relURL(p.url, tagURL(tag)), "#"+tag))
+		}
+		var footer string
+		if len(tagLinks) > 0 {
+			footer = `<p class="tags">` + strings.Join(tagLinks, " ") + "</p>\n"
+		}
+		if err := writePage(out, p.url, p.note.Title, rendered+footer); err != nil {
+			return nil, err
+		}
+		result.Notes++
+	}
+
+	if err := writeIndex(out, pages, tags); err != nil {
+		return nil, err
+	}
+	for tag, tagged := range tags {
+		if err := writeTagPage(out, tag, tagged); err != nil {
+			return nil, err
+		}
+	}
+	result.Tags = len(tags)
+
+	result.Attachments, err = copyAttachments(source, out, opts.Exclude, opts.Ignore)
+	if err != nil {
+		return nil, err
+	}
+	result.Unresolved = unresolved.sorted()
+	return result, nil
+}
+
+// resolveWikilinks rewrites [[target#anchor|label]] links in the body of the
+// note at notePath as Markdown links relative to its page at from. Links to
+// notes not exported are replaced by their label and recorded.
+func resolveWikilinks(body, notePath, from string, index *linkIndex, unresolved unresolvedLinks) string {
+	return md.RewriteLinks(body, func(l md.Link) string {
+		if !l.Wiki {
+			return l.Text
+		}
+		label := l.Label
+		if label == "" {
+			label = l.Target
+		}
+		to, reason := index.resolve(l.Target)
+		if reason != "" {
+			unresolved.add(index, notePath, l.Target, reason)
+			return label
+		}
+		anchor := ""
+		if l.Anchor != "" {
+			anchor = "#" + slug(l.Anchor)
+		}
+		return "[" + label + "](" + relURL(from, to) + anchor + ")"
+	})
+}
+
+// relURL returns the escaped URL of to relative to the page at from.
+func relURL(from, to string) string {
+	rel, err := filepath.Rel(path.Dir(from), to)
+	if err != nil {
+		rel = to
+	}
+	segments := strings.Split(filepath.ToSlash(rel), "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
+
+// tagURL returns the site path of the page listing notes tagged tag.
+func tagURL(tag string) string {
+	return "tags/" + tag + ".html"
+}
+
+// slug converts heading text into an anchor name.
+func slug(s string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(s)), " ", "-")
+}
+
+func link(href, text string) string {
+	return `<a href="` + html.EscapeString(href) + `">` + html.EscapeString(text) + "</a>"
+}
+
+func writeIndex(out string, pages []page, tags map[string][]page) error {
+	var sb strings.Builder
+	sb.WriteString("<h1>Notes</h1>\n<ul>\n")
+	for _, p := range pages {
+		sb.WriteString("<li>" + link(relURL("index.html", p.url), p.note.Title) + "</li>\n")
+	}
+	sb.WriteString("</ul>\n")
+	if len(tags) > 0 {
+		names := make([]string, 0, len(tags))
+		for tag := range tags {
+			names = append(names, tag)
+		}
+		sort.Strings(names)
+		sb.WriteString("<h2>Tags</h2>\n<ul>\n")
+		for _, tag := range names {
+			sb.WriteString(fmt.Sprintf("<li>%s (%d)</li>\n", link(relURL("index.html", tagURL(tag)), "#"+tag), len(tags[tag])))
+		}
+		sb.WriteString("</ul>\n")
+	}
+	return writePage(out, "index.html", "Notes", sb.String())
+}
+
+func writeTagPage(out, tag string, pages []page) error {
+	from := tagURL(tag)
+	var sb strings.Builder
+	sb.WriteString("<h1>#" + html.EscapeString(tag) + "</h1>\n<ul>\n")
+	for _, p := range pages {
+		sb.WriteString("<li>" + link(relURL(from, p.url), p.note.Title) + "</li>\n")
+	}
+	sb.WriteString("</ul>\n")
+	return writePage(out, from, "#"+tag, sb.String())
+}
+
+// writePage wraps body in the page layout and writes it to rel under out.
+func writePage(out, rel, title, body string) error {
+	dest := filepath.Join(out, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	var sb strings.Builder
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>" + html.EscapeString(title) + "</title>\n</head>\n<body>\n")
+	if rel != "index.html" {
+		sb.WriteString("<nav>" + link(relURL(rel, "index.html"), "Index") + "</nav>\n")
+	}
+	sb.WriteString("<main>\n" + body + "</main>\n</body>\n</html>\n")
+	if err := os.WriteFile(dest, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return nil
+}
+
+// copyAttachments copies every non-hidden, non-note file under source to the same
+// relative location under out.
+func copyAttachments(source, out string, exclude []string, ignore *scan.Ignore) (int, error) {
+	count := 0
+	err := walkAttachments(source, exclude, ignore, func(p, rel string, info fs.FileInfo) error {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		dest := filepath.Join(out, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, data, 0644); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, fmt.Errorf("failed to copy attachments: %w", err)
+	}
+	return count, nil
+}
+
+// walkAttachments calls fn with the path, the path relative to source and the file
+// info of every non-hidden, non-note file under source outside the excluded directories
+// and not ignored.
+func walkAttachments(source string, exclude []string, ignore *scan.Ignore, fn func(p, rel string, info fs.FileInfo) error) error {
+	skip := scan.WalkOptions{Exclude: exclude, Ignore: ignore}
+	return filepath.WalkDir(source, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip.Skip(source, p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(p) == scan.NoteExtension {
+			return nil
+		}
+		rel, err := filepath.Rel(source, p)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(p, rel, info)
+	})
+}
+
+// isWithin reports whether p is dir or located under it.
+func isWithin(dir, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,84 @@
+package export_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestHTML(t *testing.T) {
+	vault := t.TempDir()
+	out := filepath.Join(t.TempDir(), "site")
+	writeFile(t, filepath.Join(vault, "0-inbox", "Go channels.md"), "---\ntags: [go]\n---\n# Go channels\n\nSee [[Concurrency#Pipelines|pipelines]] and [[Missing note]].\n\n![[Concurrency#Pipelines]]\n\n![diagram](../assets/chan.png)\n")
+	writeFile(t, filepath.Join(vault, "zettel", "Concurrency.md"), "---\naliases: [CSP]\n---\n# Concurrency\n\n## Pipelines\n\nBack to [[Go channels]], or [[csp|itself]]. #go #design\n")
+	writeFile(t, filepath.Join(vault, "assets", "chan.png"), "png")
+	writeFile(t, filepath.Join(vault, "templates", "zettel.md"), "# {{.Title}}\n")
+	writeFile(t, filepath.Join(vault, ".git", "config"), "")
+
+	result, err := export.HTML(export.HTMLOptions{
+		Source:  vault,
+		Out:     out,
+		Exclude: []string{filepath.Join(vault, "templates")},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, result.Notes)
+	assert.Equal(t, 2, result.Tags)
+	assert.Equal(t, 1, result.Attachments)
+	assert.Equal(t, []export.UnresolvedLink{{From: "0-inbox/Go channels.md", Target: "Missing note", Reason: export.ReasonMissing}}, result.Unresolved)
+
+	page := readFile(t, filepath.Join(out, "0-inbox", "Go channels.html"))
+	assert.Contains(t, page, `<a href="../zettel/Concurrency.html#pipelines">pipelines</a>`)
+	assert.Contains(t, page, "and Missing note.")
+	assert.Contains(t, page, `<p>Back to <a href="Go%20channels.html">Go channels</a>`, "embedded section")
+	assert.Contains(t, page, `<img src="../assets/chan.png" alt="diagram">`)
+	assert.Contains(t, page, `<a href="../tags/go.html">#go</a>`)
+	assert.Contains(t, page, `<a href="../index.html">Index</a>`)
+
+	back := readFile(t, filepath.Join(out, "zettel", "Concurrency.html"))
+	assert.Contains(t, back, `<a href="../0-inbox/Go%20channels.html">Go channels</a>`)
+	assert.Contains(t, back, `<a href="Concurrency.html">itself</a>`)
+
+	index := readFile(t, filepath.Join(out, "index.html"))
+	assert.Contains(t, index, `<a href="0-inbox/Go%20channels.html">Go channels</a>`)
+	assert.Contains(t, index, `<a href="tags/go.html">#go</a> (2)`)
+
+	tagPage := readFile(t, filepath.Join(out, "tags", "design.html"))
+	assert.Contains(t, tagPage, `<a href="../zettel/Concurrency.html">Concurrency</a>`)
+
+	assert.FileExists(t, filepath.Join(out, "assets", "chan.png"))
+	assert.NoFileExists(t, filepath.Join(out, "templates", "zettel.html"))
+	assert.NoDirExists(t, filepath.Join(out, ".git"))
+}
+
+func TestHTML_OutputInsideVault(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "a.md"), "# A\n")
+	out := filepath.Join(vault, "site")
+
+	_, err := export.HTML(export.HTMLOptions{Source: vault, Out: out})
+	require.NoError(t, err)
+	result, err := export.HTML(export.HTMLOptions{Source: vault, Out: out})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Notes)
+	assert.Equal(t, 0, result.Attachments)
+	assert.NoDirExists(t, filepath.Join(out, "site"))
+}
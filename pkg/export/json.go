@@ -0,0 +1,265 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// BundleVersion is the version of the bundle format written by WriteJSON.
+const BundleVersion = 1
+
+// Bundle is a self-contained snapshot of a vault: every note with its parsed
+// frontmatter, the link graph between notes, attachments and the configuration.
+type Bundle struct {
+	Version  int                    `json:"version"`
+	Exported time.Time              `json:"exported"`
+	DataHome string                 `json:"data_home"`
+	Config   map[string]interface{} `json:"config,omitempty"`
+	Notes    []BundleNote           `json:"notes"`
+	Links    []Link                 `json:"links"`
+	Files    []BundleFile           `json:"files,omitempty"`
+	// Unresolved lists the links whose To is empty, and why.
+	Unresolved []UnresolvedLink `json:"unresolved,omitempty"`
+}
+
+// BundleNote is a note in a bundle. Content holds the file verbatim; Frontmatter
+// and Title are derived from it for consumers of the bundle.
+type BundleNote struct {
+	Path        string                 `json:"path"` // slash-separated, relative to the data home
+	Title       string                 `json:"title"`
+	Tags        []string               `json:"tags,omitempty"`
+	Frontmatter map[string]interface{} `json:"frontmatter,omitempty"`
+	Content     string                 `json:"content"`
+	Modified    time.Time              `json:"modified"`
+}
+
+// BundleFile is a non-note file (e.g. an image) in a bundle.
+type BundleFile struct {
+	Path     string    `json:"path"`
+	Data     []byte    `json:"data"`
+	Modified time.Time `json:"modified"`
+}
+
+// Link is an edge of the link graph. To is the path of the linked note, or empty
+// if the wikilink Target does not match any note.
+type Link struct {
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"`
+	Target string `json:"target"`
+}
+
+// JSONOptions configures a JSON bundle export.
+type JSONOptions struct {
+	// Source is the vault directory (data_home) to export.
+	Source string
+	// Exclude lists directories under Source that are not exported.
+	Exclude []string
+	// Ignore matches the files and directories under Source that are not
+	// exported, as listed in .exoignore.
+	Ignore *scan.Ignore
+	// Config is included as the configuration snapshot when non-nil.
+	Config *config.Config
+}
+
+// NewBundle collects the vault at opts.Source into a Bundle.
+func NewBundle(opts JSONOptions) (*Bundle, error) {
+	source, err := filepath.Abs(opts.Source)
+	if err != nil {
+		return nil, err
+	}
+	bundle := &Bundle{Version: BundleVersion, Exported: time.Now().UTC(), DataHome: source}
+	if opts.Config != nil {
+		if bundle.Config, err = configSnapshot(opts.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	notes, err := scan.ScanContext(context.Background(), scan.WalkOptions{Ignore: opts.Ignore}, source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan notes: %w", err)
+	}
+	var (
+		kept     []BundleNote
+		exported []scan.Note
+		keys     []string
+	)
+	byPath := make(map[string]scan.Note)
+	for _, n := range notes {
+		if scan.Excluded(n.Path, opts.Exclude) {
+			continue
+		}
+		rel, err := filepath.Rel(source, n.Path)
+		if err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(n.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read note: %w", err)
+		}
+		meta, _, err := frontmatter.Parse(string(content))
+		if err != nil || len(meta) == 0 {
+			meta = nil
+		}
+		rel = filepath.ToSlash(rel)
+		kept = append(kept, BundleNote{
+			Path:        rel,
+			Title:       n.Title,
+			Tags:        n.Tags,
+			Frontmatter: meta,
+			Content:     string(content),
+			Modified:    n.Modified,
+		})
+		exported = append(exported, n)
+		keys = append(keys, rel)
+		byPath[rel] = n
+	}
+	index := newLinkIndex(source, exported, keys, scan.NewNames(source, notes))
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Path < kept[j].Path })
+	bundle.Notes = kept
+
+	bundle.Links = []Link{}
+	unresolved := make(unresolvedLinks)
+	for _, n := range kept {
+		for _, target := range byPath[n.Path].Links {
+			to, reason := index.resolve(target)
+			if reason != "" {
+				unresolved.add(index, byPath[n.Path].Path, target, reason)
+			}
+			bundle.Links = append(bundle.Links, Link{From: n.Path, To: to, Target: target})
+		}
+	}
+	bundle.Unresolved = unresolved.sorted()
+
+	err = walkAttachments(source, opts.Exclude, opts.Ignore, func(p, rel string, info fs.FileInfo) error {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		bundle.Files = append(bundle.Files, BundleFile{Path: filepath.ToSlash(rel), Data: data, Modified: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attachments: %w", err)
+	}
+	return bundle, nil
+}
+
+// WriteJSON encodes bundle to w.
+func WriteJSON(w io.Writer, bundle *Bundle) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(bundle)
+}
+
+// ReadJSON decodes a bundle from r, rejecting bundles of an unsupported version.
+func ReadJSON(r io.Reader) (*Bundle, error) {
+	var bundle Bundle
+	if err := json.NewDecoder(r).Decode(&bundle); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if bundle.Version < 1 || bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d (this exo supports up to %d)", bundle.Version, BundleVersion)
+	}
+	return &bundle, nil
+}
+
+// ErrNotEmpty is returned by Restore when the destination already contains files.
+var ErrNotEmpty = errors.New("destination is not empty")
+
+// Restore writes the notes and files of bundle into dest, which must not exist or
+// be empty. File contents and modification times are restored verbatim.
+func Restore(bundle *Bundle, dest string) (int, error) {
+	entries, err := os.ReadDir(dest)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, fmt.Errorf("failed to read destination: %w", err)
+	}
+	if len(entries) > 0 {
+		return 0, fmt.Errorf("%w: %s", ErrNotEmpty, dest)
+	}
+
+	count := 0
+	write := func(rel string, data []byte, modified time.Time) error {
+		target, err := bundlePath(dest, rel)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		if !modified.IsZero() {
+			_ = os.Chtimes(target, modified, modified)
+		}
+		count++
+		return nil
+	}
+	for _, n := range bundle.Notes {
+		if err := write(n.Path, []byte(n.Content), n.Modified); err != nil {
+			return count, err
+		}
+	}
+	for _, f := range bundle.Files {
+		if err := write(f.Path, f.Data, f.Modified); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// RestoreConfig returns the configuration snapshot of bundle with every directory
+// under the exported data home rebased onto dataHome. It returns nil if the bundle
+// has no configuration snapshot.
+func RestoreConfig(bundle *Bundle, dataHome string) (*config.Config, error) {
+	if bundle.Config == nil {
+		return nil, nil
+	}
+	data, err := yaml.Marshal(bundle.Config)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration snapshot: %w", err)
+	}
+	var cfg config.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration snapshot: %w", err)
+	}
+	cfg.RebaseDirs(cfg.Dir.DataHome, dataHome)
+	return &cfg, nil
+}
+
+// configSnapshot converts cfg to a generic map using its YAML field names.
+func configSnapshot(cfg *config.Config) (map[string]interface{}, error) {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	var snapshot map[string]interface{}
+	if err := yaml.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to encode configuration: %w", err)
+	}
+	return snapshot, nil
+}
+
+// bundlePath joins a bundle path onto dest, rejecting paths that escape it.
+func bundlePath(dest, rel string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid path in bundle: %s", rel)
+	}
+	return filepath.Join(dest, clean), nil
+}
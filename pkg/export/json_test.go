@@ -0,0 +1,98 @@
+package export_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSON_RoundTrip(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "0-inbox", "a.md"), "---\ntags: [go]\nid: \"0001\"\n---\n# Alpha\n\nSee [[Beta]] and [[Gone]].\n")
+	writeFile(t, filepath.Join(vault, "zettel", "b.md"), "# Beta\n\nBack to [[a]], by ID [[0001]], or [[zettel]].\n")
+	writeFile(t, filepath.Join(vault, "assets", "img.png"), "\x89PNG")
+	writeFile(t, filepath.Join(vault, "templates", "zettel.md"), "# {{.Title}}\n")
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(filepath.Join(vault, "zettel", "b.md"), modified, modified))
+
+	cfg := &config.Config{
+		General: config.GeneralConfig{Editor: "code"},
+		Dir:     config.DirConfig{DataHome: vault, TemplateDir: filepath.Join(vault, "templates"), ZettelDir: filepath.Join(vault, "zettel")},
+		Alias:   map[string]string{"t": "zet"},
+	}
+	bundle, err := export.NewBundle(export.JSONOptions{
+		Source:  vault,
+		Exclude: []string{cfg.Dir.TemplateDir},
+		Config:  cfg,
+	})
+	require.NoError(t, err)
+
+	require.Len(t, bundle.Notes, 2)
+	assert.Equal(t, "0-inbox/a.md", bundle.Notes[0].Path)
+	assert.Equal(t, "Alpha", bundle.Notes[0].Title)
+	assert.Equal(t, "0001", bundle.Notes[0].Frontmatter["id"])
+	assert.Equal(t, []export.Link{
+		{From: "0-inbox/a.md", To: "zettel/b.md", Target: "Beta"},
+		{From: "0-inbox/a.md", Target: "Gone"},
+		{From: "zettel/b.md", To: "0-inbox/a.md", Target: "a"},
+		{From: "zettel/b.md", To: "0-inbox/a.md", Target: "0001"},
+		{From: "zettel/b.md", Target: "zettel"},
+	}, bundle.Links)
+	assert.Equal(t, []export.UnresolvedLink{
+		{From: "0-inbox/a.md", Target: "Gone", Reason: export.ReasonMissing},
+		{From: "zettel/b.md", Target: "zettel", Reason: export.ReasonNotExported},
+	}, bundle.Unresolved)
+	require.Len(t, bundle.Files, 1)
+	assert.Equal(t, "assets/img.png", bundle.Files[0].Path)
+
+	var buf bytes.Buffer
+	require.NoError(t, export.WriteJSON(&buf, bundle))
+	decoded, err := export.ReadJSON(&buf)
+	require.NoError(t, err)
+
+	dest := filepath.Join(t.TempDir(), "restored")
+	count, err := export.Restore(decoded, dest)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.Equal(t, "# Beta\n\nBack to [[a]], by ID [[0001]], or [[zettel]].\n", readFile(t, filepath.Join(dest, "zettel", "b.md")))
+	assert.Equal(t, "\x89PNG", readFile(t, filepath.Join(dest, "assets", "img.png")))
+	info, err := os.Stat(filepath.Join(dest, "zettel", "b.md"))
+	require.NoError(t, err)
+	assert.True(t, info.ModTime().Equal(modified))
+
+	restored, err := export.RestoreConfig(decoded, dest)
+	require.NoError(t, err)
+	assert.Equal(t, "code", restored.General.Editor)
+	assert.Equal(t, dest, restored.Dir.DataHome)
+	assert.Equal(t, filepath.Join(dest, "zettel"), restored.Dir.ZettelDir)
+	assert.Equal(t, "zet", restored.Alias["t"])
+}
+
+func TestRestore_RejectsNonEmptyDestination(t *testing.T) {
+	dest := t.TempDir()
+	writeFile(t, filepath.Join(dest, "existing.md"), "x")
+
+	_, err := export.Restore(&export.Bundle{Version: export.BundleVersion}, dest)
+	assert.ErrorIs(t, err, export.ErrNotEmpty)
+}
+
+func TestRestore_RejectsEscapingPaths(t *testing.T) {
+	bundle := &export.Bundle{Version: export.BundleVersion, Notes: []export.BundleNote{{Path: "../evil.md"}}}
+	_, err := export.Restore(bundle, filepath.Join(t.TempDir(), "dest"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid path")
+}
+
+func TestReadJSON_Version(t *testing.T) {
+	_, err := export.ReadJSON(strings.NewReader(`{"version": 99}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported bundle version")
+}
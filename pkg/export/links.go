@@ -0,0 +1,101 @@
+package export
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Reasons a wikilink is left unresolved by an export.
+const (
+	// ReasonMissing is given for links to notes not in the vault.
+	ReasonMissing = "missing"
+	// ReasonAmbiguous is given for links naming more than one note.
+	ReasonAmbiguous = "ambiguous"
+	// ReasonNotExported is given for links to notes of the vault left out of
+	// the export.
+	ReasonNotExported = "not exported"
+)
+
+// UnresolvedLink is a wikilink an export could not turn into a link to an
+// exported note; it is replaced by its label.
+type UnresolvedLink struct {
+	// From is the slash-separated path of the linking note, relative to the
+	// vault.
+	From   string `json:"from"`
+	Target string `json:"target"`
+	Reason string `json:"reason"`
+}
+
+// linkIndex resolves wikilink targets to a key identifying an exported note,
+// such as its page URL, by any name of the note: its path, file name, title,
+// IDs or aliases (see scan.Names). Links thus keep working when the exported
+// files are named differently than the notes.
+type linkIndex struct {
+	root     string
+	exported *scan.Names
+	vault    *scan.Names
+	keys     map[string]string
+}
+
+// newLinkIndex indexes the exported notes under root, keys[i] identifying
+// notes[i]. vault lists every note, to tell links to notes left out of the
+// export from links to missing notes; it defaults to notes.
+func newLinkIndex(root string, notes []scan.Note, keys []string, vault *scan.Names) *linkIndex {
+	idx := &linkIndex{root: root, exported: scan.NewNames(root, notes), vault: vault, keys: make(map[string]string)}
+	if idx.vault == nil {
+		idx.vault = idx.exported
+	}
+	for i, n := range notes {
+		idx.keys[n.Path] = keys[i]
+	}
+	return idx
+}
+
+// resolve returns the key of the exported note target names or, failing
+// that, why the link is unresolved.
+func (idx *linkIndex) resolve(target string) (key, reason string) {
+	switch matches := idx.exported.Resolve(target); len(matches) {
+	case 1:
+		return idx.keys[matches[0].Path], ""
+	case 0:
+	default:
+		return "", ReasonAmbiguous
+	}
+	switch matches := idx.vault.Resolve(target); len(matches) {
+	case 0:
+		return "", ReasonMissing
+	case 1:
+		return "", ReasonNotExported
+	default:
+		return "", ReasonAmbiguous
+	}
+}
+
+// unresolvedLinks collects the links left unresolved by an export, once each.
+type unresolvedLinks map[UnresolvedLink]bool
+
+// add records the link to target from the note at notePath.
+func (u unresolvedLinks) add(idx *linkIndex, notePath, target, reason string) {
+	from := notePath
+	if rel, err := filepath.Rel(idx.root, notePath); err == nil {
+		from = rel
+	}
+	u[UnresolvedLink{From: filepath.ToSlash(from), Target: target, Reason: reason}] = true
+}
+
+// sorted returns the links ordered by linking note, then target.
+func (u unresolvedLinks) sorted() []UnresolvedLink {
+	var links []UnresolvedLink
+	for l := range u {
+		links = append(links, l)
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].From != links[j].From {
+			return links[i].From < links[j].From
+		}
+		return links[i].Target < links[j].Target
+	})
+	return links
+}
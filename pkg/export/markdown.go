@@ -0,0 +1,62 @@
+package export
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+)
+
+// Renderer converts Markdown to an HTML fragment.
+type Renderer interface {
+	Render(markdown string) (string, error)
+}
+
+// MarkdownRenderer is a Renderer for GitHub Flavored Markdown: CommonMark with
+// tables, task lists, strikethrough and autolinks. Headings get IDs after their
+// text, as wikilink anchors do. Raw HTML is left out and links with dangerous
+// URLs, such as javascript: ones, are emptied.
+type MarkdownRenderer struct{}
+
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+)
+
+// Render implements Renderer.
+func (MarkdownRenderer) Render(source string) (string, error) {
+	var buf bytes.Buffer
+	ctx := parser.NewContext(parser.WithIDs(&headingIDs{used: make(map[string]bool)}))
+	if err := markdown.Convert([]byte(source), &buf, parser.WithContext(ctx)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// headingIDs gives the headings of a document the slug of their text as ID,
+// numbering repeated ones.
+type headingIDs struct {
+	used map[string]bool
+}
+
+// Generate implements parser.IDs.
+func (ids *headingIDs) Generate(value []byte, kind ast.NodeKind) []byte {
+	base := slug(string(value))
+	if base == "" {
+		base = "heading"
+	}
+	id := base
+	for i := 1; ids.used[id]; i++ {
+		id = base + "-" + strconv.Itoa(i)
+	}
+	ids.used[id] = true
+	return []byte(id)
+}
+
+// Put implements parser.IDs.
+func (ids *headingIDs) Put(value []byte) {
+	ids.used[string(value)] = true
+}
@@ -0,0 +1,46 @@
+package export_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func render(t *testing.T, md string) string {
+	t.Helper()
+	out, err := export.MarkdownRenderer{}.Render(md)
+	require.NoError(t, err)
+	return out
+}
+
+func TestMarkdownRenderer_Blocks(t *testing.T) {
+	md := "# Title\n\nSome *emphasis* and **strong** text\nover two lines.\n\n> quoted\n\n- one\n- [x] done\n\n1. first\n2. second\n\n---\n\n```go\nfmt.Println(\"<hi>\")\n```\n"
+	expected := "<h1 id=\"title\">Title</h1>\n" +
+		"<p>Some <em>emphasis</em> and <strong>strong</strong> text\nover two lines.</p>\n" +
+		"<blockquote>\n<p>quoted</p>\n</blockquote>\n" +
+		"<ul>\n<li>one</li>\n<li><input checked=\"\" disabled=\"\" type=\"checkbox\"> done</li>\n</ul>\n" +
+		"<ol>\n<li>first</li>\n<li>second</li>\n</ol>\n" +
+		"<hr>\n" +
+		"<pre><code class=\"language-go\">fmt.Println(&quot;&lt;hi&gt;&quot;)\n</code></pre>\n"
+	assert.Equal(t, expected, render(t, md))
+}
+
+func TestMarkdownRenderer_Inline(t *testing.T) {
+	assert.Equal(t, "<p>Use <code>a*b*c</code> &amp; <a href=\"x.html\">link</a> ![not]</p>\n", render(t, "Use `a*b*c` & [link](x.html) ![not]"))
+	assert.Equal(t, "<p><img src=\"img/a.png\" alt=\"diagram\"> snake_case_name</p>\n", render(t, "![diagram](img/a.png) snake_case_name"))
+}
+
+func TestMarkdownRenderer_GFM(t *testing.T) {
+	assert.Equal(t, "<table>\n<thead>\n<tr>\n<th>A</th>\n<th>B</th>\n</tr>\n</thead>\n<tbody>\n<tr>\n<td>1</td>\n<td>2</td>\n</tr>\n</tbody>\n</table>\n",
+		render(t, "| A | B |\n|---|---|\n| 1 | 2 |\n"))
+	assert.Equal(t, "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul>\n</li>\n<li>c</li>\n</ul>\n", render(t, "- a\n  - b\n- c\n"))
+	assert.Equal(t, "<h1 id=\"go-channels\">Go Channels</h1>\n<h2 id=\"go-channels-1\">Go Channels</h2>\n", render(t, "# Go Channels\n\n## Go Channels\n"))
+}
+
+func TestMarkdownRenderer_Unsafe(t *testing.T) {
+	out := render(t, "[x](javascript:alert(1)) <script>alert(2)</script>")
+	assert.NotContains(t, out, "javascript:")
+	assert.NotContains(t, out, "<script>")
+}
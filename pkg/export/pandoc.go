@@ -0,0 +1,74 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// PandocExtensions maps pandoc output formats to the extension of their files
+// where it is not the name of the format.
+var PandocExtensions = map[string]string{
+	"latex":      "tex",
+	"beamer":     "tex",
+	"context":    "tex",
+	"markdown":   "md",
+	"gfm":        "md",
+	"commonmark": "md",
+	"plain":      "txt",
+	"html5":      "html",
+	"asciidoc":   "adoc",
+	"docbook":    "xml",
+}
+
+// PandocExtension returns the file extension of the pandoc output format to,
+// ignoring its extensions such as "+smart".
+func PandocExtension(to string) string {
+	name := strings.ToLower(to)
+	if i := strings.IndexAny(name, "+-"); i > 0 {
+		name = name[:i]
+	}
+	if ext, ok := PandocExtensions[name]; ok {
+		return ext
+	}
+	return name
+}
+
+// Markdown returns the document as one Markdown file for pandoc: a metadata
+// block, meta with the title and date of the document added, followed by each
+// note in a div carrying its ID, so that links to it resolve.
+func (d *Document) Markdown(meta map[string]interface{}, date time.Time) (string, error) {
+	block := map[string]interface{}{"title": d.Title, "date": date.Format("2006-01-02")}
+	for k, v := range meta {
+		block[k] = v
+	}
+	var sb strings.Builder
+	for i, n := range d.Notes {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("::: {#" + n.ID + "}\n")
+		sb.WriteString(strings.TrimRight(n.Body, "\n") + "\n")
+		sb.WriteString(":::\n")
+	}
+	return frontmatter.Render(block, sb.String())
+}
+
+// Pandoc converts markdown to the format to, written to out, with the pandoc
+// executable at path, passing args after its own.
+func Pandoc(ctx context.Context, path, markdown, to, out string, args []string) error {
+	cmdArgs := append([]string{"--from", "markdown", "--to", to, "--standalone", "--output", out}, args...)
+	cmd := exec.CommandContext(ctx, path, cmdArgs...)
+	cmd.Stdin = strings.NewReader(markdown)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("pandoc failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package export_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPandocExtension(t *testing.T) {
+	assert.Equal(t, "docx", export.PandocExtension("docx"))
+	assert.Equal(t, "tex", export.PandocExtension("latex"))
+	assert.Equal(t, "md", export.PandocExtension("markdown+smart"))
+	assert.Equal(t, "html", export.PandocExtension("HTML5"))
+}
+
+func TestDocument_Markdown(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "A.md"), "---\nauthor: Ann\n---\n# A\n\nSee [[B]].\n")
+	writeFile(t, filepath.Join(vault, "B.md"), "# B\n\nBack to [[A]].\n\n")
+	notes, err := scan.Scan(vault)
+	require.NoError(t, err)
+	doc, err := export.NewDocument("Notes", notes, vault, scan.NewNames(vault, notes))
+	require.NoError(t, err)
+
+	markdown, err := doc.Markdown(map[string]interface{}{"author": "Ann"}, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "---\nauthor: Ann\ndate: \"2025-03-01\"\ntitle: Notes\n---\n"+
+		"::: {#note-a}\n# A\n\nSee [B](#note-b).\n:::\n\n"+
+		"::: {#note-b}\n# B\n\nBack to [A](#note-a).\n:::\n", markdown)
+}
+
+func TestPandoc(t *testing.T) {
+	dir := t.TempDir()
+	pandoc := filepath.Join(dir, "pandoc")
+	// The fake pandoc writes its arguments and input to the output file.
+	script := "#!/bin/sh\nargs=\"$*\"\nwhile [ $# -gt 0 ]; do [ \"$1\" = --output ] && out=$2; shift; done\n{ echo \"$args\"; cat; } > \"$out\"\n"
+	require.NoError(t, os.WriteFile(pandoc, []byte(script), 0755))
+
+	out := filepath.Join(dir, "notes.docx")
+	require.NoError(t, export.Pandoc(context.Background(), pandoc, "# A\n", "docx", out, []string{"--toc"}))
+	assert.Equal(t, "--from markdown --to docx --standalone --output "+out+" --toc\n# A\n", readFile(t, out))
+
+	failing := filepath.Join(dir, "failing")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho unknown format >&2\nexit 2\n"), 0755))
+	assert.ErrorContains(t, export.Pandoc(context.Background(), failing, "# A\n", "nope", out, nil), "pandoc failed: exit status 2: unknown format")
+}
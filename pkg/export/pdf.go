@@ -0,0 +1,77 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// PDFEngines are the HTML-to-PDF programs looked for on PATH, in order:
+// wkhtmltopdf, then Chromium and Chrome, which print pages headless.
+var PDFEngines = []string{"wkhtmltopdf", "chromium", "chromium-browser", "google-chrome", "google-chrome-stable"}
+
+// ErrNoPDFEngine is returned by FindPDFEngine when no engine is installed.
+var ErrNoPDFEngine = errors.New("no PDF engine found (install wkhtmltopdf or Chromium, or set export.pdf.engine)")
+
+// FindPDFEngine returns the path of the HTML-to-PDF program named engine,
+// a name or a path, or of the first of PDFEngines on PATH when engine is empty.
+func FindPDFEngine(engine string) (string, error) {
+	if engine != "" {
+		path, err := exec.LookPath(engine)
+		if err != nil {
+			return "", fmt.Errorf("PDF engine %s not found: %w", engine, err)
+		}
+		return path, nil
+	}
+	for _, name := range PDFEngines {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", ErrNoPDFEngine
+}
+
+// PDF prints the HTML page to the PDF file out with engine, the path of
+// wkhtmltopdf or of a Chromium-based browser.
+func PDF(ctx context.Context, engine, page, out string) error {
+	f, err := os.CreateTemp("", "exo-export-*.html")
+	if err != nil {
+		return fmt.Errorf("failed to write page: %w", err)
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.WriteString(page); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write page: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write page: %w", err)
+	}
+	out, err = filepath.Abs(out)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.CommandContext(ctx, engine, pdfArgs(engine, f.Name(), out)...)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", filepath.Base(engine), err, strings.TrimSpace(string(output)))
+	}
+	if _, err := os.Stat(out); err != nil {
+		return fmt.Errorf("%s did not write %s", filepath.Base(engine), out)
+	}
+	return nil
+}
+
+// pdfArgs returns the arguments engine prints the page at in to out with.
+func pdfArgs(engine, in, out string) []string {
+	if strings.Contains(strings.ToLower(filepath.Base(engine)), "wkhtmltopdf") {
+		return []string{"--quiet", "--enable-local-file-access", in, out}
+	}
+	page := url.URL{Scheme: "file", Path: filepath.ToSlash(in)}
+	return []string{"--headless", "--disable-gpu", "--no-pdf-header-footer", "--print-to-pdf-no-header",
+		"--print-to-pdf=" + out, page.String()}
+}
@@ -0,0 +1,46 @@
+package export_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEngine writes an executable named name that copies the page it is given
+// to the output file, as wkhtmltopdf is called.
+func fakeEngine(t *testing.T, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\ncp \"$3\" \"$4\"\n"), 0755))
+	return path
+}
+
+func TestFindPDFEngine(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, err := export.FindPDFEngine("")
+	assert.ErrorIs(t, err, export.ErrNoPDFEngine)
+	_, err = export.FindPDFEngine("wkhtmltopdf")
+	assert.ErrorContains(t, err, "PDF engine wkhtmltopdf not found")
+
+	path := fakeEngine(t, "chromium")
+	t.Setenv("PATH", filepath.Dir(path))
+	found, err := export.FindPDFEngine("")
+	require.NoError(t, err)
+	assert.Equal(t, path, found)
+}
+
+func TestPDF(t *testing.T) {
+	engine := fakeEngine(t, "wkhtmltopdf")
+	out := filepath.Join(t.TempDir(), "out.pdf")
+	require.NoError(t, export.PDF(context.Background(), engine, "<p>page</p>", out))
+	assert.Equal(t, "<p>page</p>", readFile(t, out))
+
+	failing := filepath.Join(t.TempDir(), "wkhtmltopdf")
+	require.NoError(t, os.WriteFile(failing, []byte("#!/bin/sh\necho broken >&2\nexit 1\n"), 0755))
+	assert.ErrorContains(t, export.PDF(context.Background(), failing, "<p>page</p>", out), "wkhtmltopdf failed")
+}
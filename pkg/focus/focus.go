@@ -0,0 +1,144 @@
+package focus
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// EnvVar is the environment variable holding the focus token of a shell session.
+const EnvVar = "EXO_FOCUS"
+
+// Kind is the kind of context a session can focus on.
+type Kind string
+
+// Supported focus kinds.
+const (
+	KindTag     Kind = "tag"
+	KindProject Kind = "project"
+)
+
+// projectKey is the frontmatter key naming the project a note belongs to.
+const projectKey = "project"
+
+// Focus is the context commands are scoped to. The zero value means no focus.
+type Focus struct {
+	Kind Kind
+	Name string
+}
+
+// Parse parses a focus token of the form "tag:<name>" or "project:<name>".
+// An empty token yields the zero Focus.
+func Parse(token string) (Focus, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return Focus{}, nil
+	}
+	kind, name, ok := strings.Cut(token, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" || (Kind(kind) != KindTag && Kind(kind) != KindProject) {
+		return Focus{}, fmt.Errorf("invalid focus %q (expected tag:<name> or project:<name>)", token)
+	}
+	if Kind(kind) == KindTag {
+		name = strings.TrimPrefix(name, "#")
+	}
+	return Focus{Kind: Kind(kind), Name: name}, nil
+}
+
+// FromEnv returns the focus of the current shell session.
+func FromEnv() (Focus, error) {
+	return Parse(os.Getenv(EnvVar))
+}
+
+// IsZero reports whether f is the absence of a focus.
+func (f Focus) IsZero() bool {
+	return f.Name == ""
+}
+
+// Token returns the value of EnvVar selecting f.
+func (f Focus) Token() string {
+	if f.IsZero() {
+		return ""
+	}
+	return string(f.Kind) + ":" + f.Name
+}
+
+// String returns a human-readable description of f, e.g. "#go" or "project website".
+func (f Focus) String() string {
+	switch {
+	case f.IsZero():
+		return "none"
+	case f.Kind == KindTag:
+		return "#" + f.Name
+	default:
+		return "project " + f.Name
+	}
+}
+
+// Match reports whether n belongs to the focused context: for a tag, whether n
+// carries it; for a project, whether n lives in the project's directory under
+// projectsDir or names the project in its frontmatter. Every note matches the zero Focus.
+func (f Focus) Match(n scan.Note, projectsDir string) bool {
+	switch {
+	case f.IsZero():
+		return true
+	case f.Kind == KindTag:
+		return n.HasTag(f.Name)
+	}
+	if strings.EqualFold(frontmatter.String(n.Meta, projectKey), f.Name) {
+		return true
+	}
+	rel, err := filepath.Rel(f.ProjectDir(projectsDir), n.Path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Filter returns the notes matching f.
+func (f Focus) Filter(notes []scan.Note, projectsDir string) []scan.Note {
+	if f.IsZero() {
+		return notes
+	}
+	var out []scan.Note
+	for _, n := range notes {
+		if f.Match(n, projectsDir) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// ProjectDir returns the directory of the focused project under projectsDir,
+// or an empty string if f does not focus on a project.
+func (f Focus) ProjectDir(projectsDir string) string {
+	if f.Kind != KindProject || f.IsZero() {
+		return ""
+	}
+	return filepath.Join(projectsDir, f.Name)
+}
+
+// Annotate adds the focused context to the frontmatter of content, so that a
+// new note belongs to it: the tag is added to "tags", or "project" is set.
+func (f Focus) Annotate(content string) (string, error) {
+	if f.IsZero() {
+		return content, nil
+	}
+	meta, body, err := frontmatter.Parse(content)
+	if err != nil {
+		return "", err
+	}
+	if f.Kind == KindProject {
+		meta[projectKey] = f.Name
+		return frontmatter.Render(meta, body)
+	}
+	tags := frontmatter.Strings(meta, "tags")
+	for _, t := range tags {
+		if strings.EqualFold(t, f.Name) {
+			return content, nil
+		}
+	}
+	meta["tags"] = append(tags, f.Name)
+	return frontmatter.Render(meta, body)
+}
@@ -0,0 +1,71 @@
+package focus_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/focus"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	f, err := focus.Parse("tag:#go")
+	require.NoError(t, err)
+	assert.Equal(t, focus.Focus{Kind: focus.KindTag, Name: "go"}, f)
+	assert.Equal(t, "tag:go", f.Token())
+	assert.Equal(t, "#go", f.String())
+
+	f, err = focus.Parse("project:website")
+	require.NoError(t, err)
+	assert.Equal(t, "project website", f.String())
+
+	f, err = focus.Parse("")
+	require.NoError(t, err)
+	assert.True(t, f.IsZero())
+
+	for _, bad := range []string{"go", "tag:", "area:home"} {
+		_, err := focus.Parse(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv(focus.EnvVar, "tag:go")
+	f, err := focus.FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "go", f.Name)
+}
+
+func TestFilter(t *testing.T) {
+	notes := []scan.Note{
+		{Path: "/v/0-inbox/a.md", Tags: []string{"go"}},
+		{Path: "/v/projects/website/plan.md"},
+		{Path: "/v/0-inbox/b.md", Meta: map[string]interface{}{"project": "Website"}},
+		{Path: "/v/projects/website-old/x.md"},
+	}
+
+	tag := focus.Focus{Kind: focus.KindTag, Name: "GO"}
+	assert.Equal(t, notes[:1], tag.Filter(notes, "/v/projects"))
+
+	project := focus.Focus{Kind: focus.KindProject, Name: "website"}
+	assert.Equal(t, notes[1:3], project.Filter(notes, "/v/projects"))
+
+	assert.Equal(t, notes, focus.Focus{}.Filter(notes, "/v/projects"))
+}
+
+func TestAnnotate(t *testing.T) {
+	tag := focus.Focus{Kind: focus.KindTag, Name: "go"}
+	content, err := tag.Annotate("# Note\n")
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntags:\n  - go\n---\n# Note\n", content)
+
+	again, err := tag.Annotate(content)
+	require.NoError(t, err)
+	assert.Equal(t, content, again)
+
+	project := focus.Focus{Kind: focus.KindProject, Name: "website"}
+	content, err = project.Annotate("")
+	require.NoError(t, err)
+	assert.Equal(t, "---\nproject: website\n---\n", content)
+}
@@ -0,0 +1,167 @@
+package frontmatter
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// delimiter separates the YAML frontmatter block from the note body.
+const delimiter = "---"
+
+// Split separates a leading YAML frontmatter block from the body of a note.
+// If the content has no (terminated) frontmatter, raw is empty and body is the full content.
+func Split(content string) (raw, body string) {
+	lines := strings.SplitAfter(content, "\n")
+	if len(lines) == 0 || strings.TrimRight(lines[0], "\r\n") != delimiter {
+		return "", content
+	}
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimRight(lines[i], "\r\n") == delimiter {
+			return strings.Join(lines[1:i], ""), strings.Join(lines[i+1:], "")
+		}
+	}
+	return "", content
+}
+
+// Parse decodes the frontmatter of content into a map and returns it with the body.
+// Content without frontmatter yields an empty (non-nil) map.
+func Parse(content string) (map[string]interface{}, string, error) {
+	raw, body := Split(content)
+	meta := make(map[string]interface{})
+	if raw == "" {
+		return meta, body, nil
+	}
+	if err := yaml.Unmarshal([]byte(raw), &meta); err != nil {
+		return nil, content, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if meta == nil {
+		meta = make(map[string]interface{})
+	}
+	return meta, body, nil
+}
+
+// Render encodes meta as a YAML frontmatter block and prepends it to body.
+// An empty meta map yields the body unchanged.
+func Render(meta map[string]interface{}, body string) (string, error) {
+	if len(meta) == 0 {
+		return body, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(meta); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	return delimiter + "\n" + buf.String() + delimiter + "\n" + body, nil
+}
+
+// String returns the string value of key, or "" if it is missing or not a scalar.
+func String(meta map[string]interface{}, key string) string {
+	switch v := meta[key].(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	case []interface{}, map[string]interface{}:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Bool reports whether key is set to true, given as a boolean or as the string
+// "true" or "yes" in any case.
+func Bool(meta map[string]interface{}, key string) bool {
+	switch v := meta[key].(type) {
+	case bool:
+		return v
+	case string:
+		v = strings.ToLower(strings.TrimSpace(v))
+		return v == "true" || v == "yes"
+	}
+	return false
+}
+
+// Strings returns the value of key as a list of strings. A scalar value is
+// returned as a single-element list, and a comma-separated string is split.
+func Strings(meta map[string]interface{}, key string) []string {
+	var out []string
+	switch v := meta[key].(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+				out = append(out, s)
+			}
+		}
+	case []string:
+		for _, s := range v {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	case string:
+		for _, s := range strings.Split(v, ",") {
+			if s = strings.TrimSpace(s); s != "" {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// Set sets the scalar key to value in the frontmatter of content, keeping the
+// other fields and their formatting as written. The field is appended when
+// missing. Content without frontmatter is returned unchanged.
+func Set(content, key, value string) (string, error) {
+	if raw, _ := Split(content); raw == "" {
+		return content, nil
+	}
+	return Edit(content, func(m *yaml.Node) error {
+		for i := 0; i+1 < len(m.Content); i += 2 {
+			if m.Content[i].Value == key {
+				m.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+				return nil
+			}
+		}
+		m.Content = append(m.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value})
+		return nil
+	})
+}
+
+// Edit calls fn with the mapping node of the frontmatter of content and
+// returns content with the frontmatter fn left, keeping the fields fn did not
+// change as written. Content without frontmatter is given an empty mapping.
+func Edit(content string, fn func(m *yaml.Node) error) (string, error) {
+	raw, body := Split(content)
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return "", fmt.Errorf("failed to parse frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return "", fmt.Errorf("frontmatter is not a mapping")
+	}
+	if err := fn(doc.Content[0]); err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return "", fmt.Errorf("failed to encode frontmatter: %w", err)
+	}
+	return delimiter + "\n" + buf.String() + delimiter + "\n" + body, nil
+}
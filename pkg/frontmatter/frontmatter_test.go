@@ -0,0 +1,75 @@
+package frontmatter_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	raw, body := frontmatter.Split("---\ntitle: A\n---\n# A\n")
+	assert.Equal(t, "title: A\n", raw)
+	assert.Equal(t, "# A\n", body)
+
+	raw, body = frontmatter.Split("# No frontmatter\n---\n")
+	assert.Equal(t, "", raw)
+	assert.Equal(t, "# No frontmatter\n---\n", body)
+
+	// Unterminated frontmatter is treated as body.
+	raw, body = frontmatter.Split("---\ntitle: A\n")
+	assert.Equal(t, "", raw)
+	assert.Equal(t, "---\ntitle: A\n", body)
+}
+
+func TestParse(t *testing.T) {
+	meta, body, err := frontmatter.Parse("---\ntitle: Note\ntags: [a, b]\n---\nBody")
+	require.NoError(t, err)
+	assert.Equal(t, "Note", frontmatter.String(meta, "title"))
+	assert.Equal(t, []string{"a", "b"}, frontmatter.Strings(meta, "tags"))
+	assert.Equal(t, "Body", body)
+
+	_, _, err = frontmatter.Parse("---\ntitle: [unclosed\n---\n")
+	require.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	out, err := frontmatter.Render(map[string]interface{}{"title": "Note"}, "Body\n")
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Note\n---\nBody\n", out)
+
+	meta, body, err := frontmatter.Parse(out)
+	require.NoError(t, err)
+	assert.Equal(t, "Note", meta["title"])
+	assert.Equal(t, "Body\n", body)
+}
+
+func TestStrings_CommaSeparated(t *testing.T) {
+	meta := map[string]interface{}{"tags": "a, b ,c"}
+	assert.Equal(t, []string{"a", "b", "c"}, frontmatter.Strings(meta, "tags"))
+}
+
+func TestBool(t *testing.T) {
+	meta := map[string]interface{}{"pinned": true, "locked": "Yes", "draft": false, "archived": "no", "count": 1}
+	assert.True(t, frontmatter.Bool(meta, "pinned"))
+	assert.True(t, frontmatter.Bool(meta, "locked"))
+	assert.False(t, frontmatter.Bool(meta, "draft"))
+	assert.False(t, frontmatter.Bool(meta, "archived"))
+	assert.False(t, frontmatter.Bool(meta, "count"))
+	assert.False(t, frontmatter.Bool(meta, "missing"))
+}
+
+func TestSet(t *testing.T) {
+	out, err := frontmatter.Set("---\ncreated: 2025-02-08\ntags: [go]\ntype: zettel\n---\n# Go\n", "type", "project")
+	require.NoError(t, err)
+	assert.Equal(t, "---\ncreated: 2025-02-08\ntags: [go]\ntype: project\n---\n# Go\n", out)
+
+	out, err = frontmatter.Set("---\ntitle: Go\n---\n# Go\n", "type", "project")
+	require.NoError(t, err)
+	assert.Equal(t, "---\ntitle: Go\ntype: project\n---\n# Go\n", out)
+
+	out, err = frontmatter.Set("# Go\n", "type", "project")
+	require.NoError(t, err)
+	assert.Equal(t, "# Go\n", out)
+}
@@ -0,0 +1,146 @@
+package frontmatter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldType is the type of a frontmatter value in a Schema.
+type FieldType string
+
+// Field types. A field without a type takes any value.
+const (
+	// AnyType accepts any value.
+	AnyType FieldType = ""
+	// StringType accepts any scalar, as YAML reads some text, such as an ISBN,
+	// as a number.
+	StringType FieldType = "string"
+	// NumberType accepts integers and decimals.
+	NumberType FieldType = "number"
+	// BoolType accepts true and false.
+	BoolType FieldType = "bool"
+	// DateType accepts dates (YYYY-MM-DD) and times (RFC 3339).
+	DateType FieldType = "date"
+	// ListType accepts lists.
+	ListType FieldType = "list"
+)
+
+// FieldTypes are the names of the field types, in the order they are listed in help.
+var FieldTypes = []FieldType{StringType, NumberType, BoolType, DateType, ListType}
+
+// Valid reports whether t is a known field type.
+func (t FieldType) Valid() bool {
+	if t == AnyType {
+		return true
+	}
+	for _, known := range FieldTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Field describes a frontmatter field of a Schema.
+type Field struct {
+	Type FieldType
+	// Required fields must be present and not empty; other fields are
+	// optional and only checked when set.
+	Required bool
+}
+
+// Schema describes the frontmatter of a kind of note, by field name. Fields
+// not in the schema are not checked.
+type Schema map[string]Field
+
+// Check returns the problems found in meta, ordered by field name: required
+// fields missing or empty, and fields whose values are not of their type.
+func (s Schema) Check(meta map[string]interface{}) []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var problems []string
+	for _, name := range names {
+		field := s[name]
+		value := lookup(meta, name)
+		if isEmpty(value) {
+			if field.Required {
+				problems = append(problems, fmt.Sprintf("missing required field %q", name))
+			}
+			continue
+		}
+		if !field.Type.matches(value) {
+			problems = append(problems, fmt.Sprintf("field %q is not a %s", name, field.Type))
+		}
+	}
+	return problems
+}
+
+// lookup returns the value of key in meta, matching key case-insensitively
+// when there is no exact match.
+func lookup(meta map[string]interface{}, key string) interface{} {
+	if v, ok := meta[key]; ok {
+		return v
+	}
+	for k, v := range meta {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// isEmpty reports whether v is missing, blank text or an empty list.
+func isEmpty(v interface{}) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// matches reports whether v is a value of type t.
+func (t FieldType) matches(v interface{}) bool {
+	switch t {
+	case StringType:
+		switch v.(type) {
+		case []interface{}, map[string]interface{}:
+			return false
+		}
+		return true
+	case NumberType:
+		switch v.(type) {
+		case int, int64, uint64, float64:
+			return true
+		}
+		return false
+	case BoolType:
+		_, ok := v.(bool)
+		return ok
+	case DateType:
+		switch v := v.(type) {
+		case time.Time:
+			return true
+		case string:
+			for _, layout := range []string{"2006-01-02", time.RFC3339, "2006-01-02 15:04"} {
+				if _, err := time.Parse(layout, v); err == nil {
+					return true
+				}
+			}
+		}
+		return false
+	case ListType:
+		_, ok := v.([]interface{})
+		return ok
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package frontmatter_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Check(t *testing.T) {
+	schema := frontmatter.Schema{
+		"isbn":    {Type: frontmatter.StringType, Required: true},
+		"authors": {Type: frontmatter.ListType, Required: true},
+		"rating":  {Type: frontmatter.NumberType},
+		"read":    {Type: frontmatter.DateType},
+		"owned":   {Type: frontmatter.BoolType},
+		"notes":   {},
+	}
+
+	meta, _, err := frontmatter.Parse("---\nisbn: 9780441013593\nAuthors: [Frank Herbert]\nrating: 4.5\nread: 2024-05-01\nowned: true\nnotes: [a]\n---\n")
+	require.NoError(t, err)
+	assert.Empty(t, schema.Check(meta))
+
+	meta, _, err = frontmatter.Parse("---\nisbn: \"\"\nauthors: Frank Herbert\nrating: five\nread: May\nowned: yes\n---\n")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`field "authors" is not a list`,
+		`missing required field "isbn"`,
+		`field "owned" is not a bool`,
+		`field "rating" is not a number`,
+		`field "read" is not a date`,
+	}, schema.Check(meta))
+
+	assert.Empty(t, schema.Check(map[string]interface{}{"isbn": "x", "authors": []interface{}{"y"}, "read": "2024-05-01T10:00:00Z"}))
+}
+
+func TestFieldType_Valid(t *testing.T) {
+	assert.True(t, frontmatter.FieldType("").Valid())
+	assert.True(t, frontmatter.DateType.Valid())
+	assert.False(t, frontmatter.FieldType("integer").Valid())
+}
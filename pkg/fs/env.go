@@ -0,0 +1,313 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Env is the environment paths and commands are resolved in: the operating
+// system, the home directory and the environment variables. OSEnv describes
+// the running system; other values let the rules of one system, such as the
+// Windows drive letters and %APPDATA%, be tested on any other.
+type Env struct {
+	// GOOS is the operating system, as runtime.GOOS.
+	GOOS string
+	// Home is the home directory of the user.
+	Home string
+	// Getenv returns the value of an environment variable.
+	Getenv func(key string) string
+	// LookPath finds a program on PATH, as exec.LookPath.
+	LookPath func(file string) (string, error)
+}
+
+// OSEnv returns the environment of the running process. Home is empty when
+// the home directory cannot be determined.
+func OSEnv() Env {
+	home, _ := os.UserHomeDir()
+	return Env{GOOS: runtime.GOOS, Home: home, Getenv: os.Getenv, LookPath: exec.LookPath}
+}
+
+// Windows reports whether e is a Windows environment.
+func (e Env) Windows() bool {
+	return e.GOOS == "windows"
+}
+
+// ConfigDir returns the directory the configuration of app is kept in:
+// %APPDATA%\app on Windows, else $HOME/.config/app.
+func (e Env) ConfigDir(app string) string {
+	if e.Windows() {
+		return e.Join(e.windowsDir("APPDATA", "Roaming"), app)
+	}
+	return e.Join(e.Home, ".config", app)
+}
+
+// DataDir returns the directory the data of app is kept in:
+// %LOCALAPPDATA%\app\data on Windows, else $HOME/.local/share/app.
+func (e Env) DataDir(app string) string {
+	if e.Windows() {
+		return e.Join(e.windowsDir("LOCALAPPDATA", "Local"), app, "data")
+	}
+	return e.Join(e.Home, ".local", "share", app)
+}
+
+// StateDir returns the directory the state of app, such as snapshots, is kept
+// in: %LOCALAPPDATA%\app\state on Windows, else $XDG_STATE_HOME/app, which
+// defaults to $HOME/.local/state/app.
+func (e Env) StateDir(app string) string {
+	if e.Windows() {
+		return e.Join(e.windowsDir("LOCALAPPDATA", "Local"), app, "state")
+	}
+	if stateHome := e.getenv("XDG_STATE_HOME"); stateHome != "" {
+		return e.Join(e.Sanitize(stateHome), app)
+	}
+	return e.Join(e.Home, ".local", "state", app)
+}
+
+// CacheDir returns the directory the caches of app, which can be rebuilt, are
+// kept in: %LOCALAPPDATA%\app\cache on Windows, else $XDG_CACHE_HOME/app,
+// which defaults to $HOME/.cache/app.
+func (e Env) CacheDir(app string) string {
+	if e.Windows() {
+		return e.Join(e.windowsDir("LOCALAPPDATA", "Local"), app, "cache")
+	}
+	if cacheHome := e.getenv("XDG_CACHE_HOME"); cacheHome != "" {
+		return e.Join(e.Sanitize(cacheHome), app)
+	}
+	return e.Join(e.Home, ".cache", app)
+}
+
+// windowsDir returns the directory in the environment variable key, or the
+// AppData subdirectory fallback of the home directory when it is unset.
+func (e Env) windowsDir(key, fallback string) string {
+	if dir := e.getenv(key); dir != "" {
+		return e.Clean(dir)
+	}
+	return e.Join(e.Home, "AppData", fallback)
+}
+
+func (e Env) getenv(key string) string {
+	if e.Getenv == nil {
+		return ""
+	}
+	return e.Getenv(key)
+}
+
+// windowsVarPattern matches a %VARIABLE% reference.
+var windowsVarPattern = regexp.MustCompile(`%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// Expand expands a leading tilde to the home directory, and on Windows the
+// %VARIABLE% references of environment variables that are set.
+func (e Env) Expand(p string) string {
+	if e.Windows() {
+		p = windowsVarPattern.ReplaceAllStringFunc(p, func(m string) string {
+			if value := e.getenv(m[1 : len(m)-1]); value != "" {
+				return value
+			}
+			return m
+		})
+	}
+	if p == "~" {
+		return e.Home
+	}
+	if strings.HasPrefix(p, "~/") || (e.Windows() && strings.HasPrefix(p, `~\`)) {
+		return e.Join(e.Home, p[2:])
+	}
+	return p
+}
+
+// Sanitize expands p, cleans it and makes it absolute by joining it with the
+// home directory when it is relative.
+func (e Env) Sanitize(p string) string {
+	p = e.Clean(e.Expand(p))
+	if !e.IsAbs(p) {
+		p = e.Join(e.Home, p)
+	}
+	return p
+}
+
+// IsAbs reports whether p is absolute: on Windows, it starts with a drive
+// letter and a separator, as C:\Users, or is a UNC path, as \\server\share.
+func (e Env) IsAbs(p string) bool {
+	if !e.Windows() {
+		return strings.HasPrefix(p, "/")
+	}
+	p = strings.ReplaceAll(p, "/", `\`)
+	if strings.HasPrefix(p, `\\`) {
+		return true
+	}
+	return len(p) >= 3 && isDriveLetter(p[0]) && p[1] == ':' && p[2] == '\\'
+}
+
+// Clean returns the shortest path equivalent to p, as filepath.Clean does on
+// e.GOOS; on Windows, forward slashes are turned into backslashes.
+func (e Env) Clean(p string) string {
+	if !e.Windows() {
+		return path.Clean(p)
+	}
+	p = strings.ReplaceAll(p, `\`, "/")
+	volume := ""
+	switch {
+	case strings.HasPrefix(p, "//"):
+		// A UNC path: the volume is \\server\share.
+		parts := strings.SplitN(p[2:], "/", 3)
+		if len(parts) < 2 {
+			return strings.ReplaceAll(p, "/", `\`)
+		}
+		volume = "//" + parts[0] + "/" + parts[1]
+		p = "/"
+		if len(parts) == 3 {
+			p += parts[2]
+		}
+	case len(p) >= 2 && isDriveLetter(p[0]) && p[1] == ':':
+		volume, p = p[:2], p[2:]
+	}
+	if p == "" {
+		return strings.ReplaceAll(volume, "/", `\`) + "."
+	}
+	return strings.ReplaceAll(volume+path.Clean(p), "/", `\`)
+}
+
+// Join joins the elements of a path with the separator of e.GOOS, ignoring
+// empty ones, and cleans the result.
+func (e Env) Join(elem ...string) string {
+	var parts []string
+	for _, el := range elem {
+		if el != "" {
+			parts = append(parts, el)
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	sep := "/"
+	if e.Windows() {
+		sep = `\`
+	}
+	return e.Clean(strings.Join(parts, sep))
+}
+
+func isDriveLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// DefaultEditor returns the editor used when none is configured: notepad on
+// Windows, else nvim.
+func (e Env) DefaultEditor() string {
+	if e.Windows() {
+		return "notepad"
+	}
+	return "nvim"
+}
+
+// ShellCommand returns the command line running the shell command line s:
+// with cmd /C on Windows, else with sh -c.
+func (e Env) ShellCommand(s string) []string {
+	if e.Windows() {
+		return []string{"cmd", "/C", s}
+	}
+	return []string{"sh", "-c", s}
+}
+
+// EditorCommand returns the command line opening the file at p in editor: a
+// program name or path followed by arguments, quoted with double quotes when
+// they contain spaces, as `code --wait` or `"C:\Program Files\Vim\gvim.exe"`.
+// On Windows, batch files such as code.cmd, named with or without extension,
+// are run through cmd /C, as they cannot be started on their own.
+func (e Env) EditorCommand(editor, p string) ([]string, error) {
+	args, err := splitCommand(editor, !e.Windows())
+	if err != nil {
+		return nil, fmt.Errorf("invalid editor %q: %w", editor, err)
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("editor cannot be empty")
+	}
+	args = append(args, p)
+	if !e.Windows() {
+		return args, nil
+	}
+	program := args[0]
+	if e.LookPath != nil {
+		if resolved, err := e.LookPath(program); err == nil {
+			program = resolved
+		}
+	}
+	switch strings.ToLower(path.Ext(strings.ReplaceAll(program, `\`, "/"))) {
+	case ".cmd", ".bat":
+		return append([]string{"cmd", "/C", program}, args[1:]...), nil
+	}
+	return args, nil
+}
+
+// lineEditors are the editors known to open files at a line given as "+N".
+var lineEditors = map[string]bool{
+	"vi": true, "vim": true, "nvim": true, "gvim": true, "nano": true,
+	"emacs": true, "emacsclient": true, "micro": true, "kak": true,
+}
+
+// EditorAtLine returns editor with the argument making it open files at line,
+// "+line", when it is known to take one, as vim, nvim, nano and emacs are;
+// other editors are returned unchanged.
+func (e Env) EditorAtLine(editor string, line int) string {
+	args, err := splitCommand(editor, !e.Windows())
+	if err != nil || len(args) == 0 || line < 1 {
+		return editor
+	}
+	program := strings.ToLower(path.Base(strings.ReplaceAll(args[0], `\`, "/")))
+	program = strings.TrimSuffix(program, ".exe")
+	if !lineEditors[program] {
+		return editor
+	}
+	return fmt.Sprintf("%s +%d", editor, line)
+}
+
+// SplitWords splits a command line into words separated by spaces, as a shell
+// does: single and double quotes group words, and are removed. It fails on an
+// unterminated quote.
+func SplitWords(s string) ([]string, error) {
+	return splitCommand(s, true)
+}
+
+// splitCommand splits a command line into words separated by spaces. Double
+// quotes group words; so do single quotes when single is set, as Windows
+// paths may contain them. Backslashes are kept, as Windows paths use them.
+func splitCommand(s string, single bool) ([]string, error) {
+	var (
+		args    []string
+		word    strings.Builder
+		inWord  bool
+		closing rune
+	)
+	for _, r := range s {
+		switch {
+		case closing != 0:
+			if r == closing {
+				closing = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '"' || (single && r == '\''):
+			closing, inWord = r, true
+		case r == ' ' || r == '\t':
+			if inWord {
+				args = append(args, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if closing != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inWord {
+		args = append(args, word.String())
+	}
+	return args, nil
+}
@@ -0,0 +1,122 @@
+package fs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowsEnv is a Windows environment, whatever the system the tests run on.
+func windowsEnv(vars map[string]string, programs map[string]string) fs.Env {
+	return fs.Env{
+		GOOS:   "windows",
+		Home:   `C:\Users\ada`,
+		Getenv: func(key string) string { return vars[key] },
+		LookPath: func(file string) (string, error) {
+			if p, ok := programs[file]; ok {
+				return p, nil
+			}
+			return "", errors.New("not found")
+		},
+	}
+}
+
+func TestEnv_Dirs(t *testing.T) {
+	env := windowsEnv(map[string]string{
+		"APPDATA":      `C:\Users\ada\AppData\Roaming`,
+		"LOCALAPPDATA": `D:/Local/`,
+	}, nil)
+	assert.Equal(t, `C:\Users\ada\AppData\Roaming\exo`, env.ConfigDir("exo"))
+	assert.Equal(t, `D:\Local\exo\data`, env.DataDir("exo"))
+	assert.Equal(t, `D:\Local\exo\state`, env.StateDir("exo"))
+	assert.Equal(t, `D:\Local\exo\cache`, env.CacheDir("exo"))
+	assert.Equal(t, "notepad", env.DefaultEditor())
+
+	unset := windowsEnv(nil, nil)
+	assert.Equal(t, `C:\Users\ada\AppData\Roaming\exo`, unset.ConfigDir("exo"))
+	assert.Equal(t, `C:\Users\ada\AppData\Local\exo\data`, unset.DataDir("exo"))
+
+	unix := fs.Env{GOOS: "linux", Home: "/home/ada", Getenv: func(string) string { return "" }}
+	assert.Equal(t, "/home/ada/.config/exo", unix.ConfigDir("exo"))
+	assert.Equal(t, "/home/ada/.local/share/exo", unix.DataDir("exo"))
+	assert.Equal(t, "/home/ada/.local/state/exo", unix.StateDir("exo"))
+	assert.Equal(t, "/home/ada/.cache/exo", unix.CacheDir("exo"))
+	xdg := unix
+	xdg.Getenv = func(key string) string { return map[string]string{"XDG_CACHE_HOME": "/tmp/cache"}[key] }
+	assert.Equal(t, "/tmp/cache/exo", xdg.CacheDir("exo"))
+	assert.Equal(t, "nvim", unix.DefaultEditor())
+}
+
+func TestEnv_Sanitize(t *testing.T) {
+	env := windowsEnv(map[string]string{"USERPROFILE": `C:\Users\ada`}, nil)
+	for in, want := range map[string]string{
+		`~\notes`:                  `C:\Users\ada\notes`,
+		`~/notes/../vault`:         `C:\Users\ada\vault`,
+		"~":                        `C:\Users\ada`,
+		`%USERPROFILE%\Documents`:  `C:\Users\ada\Documents`,
+		`%UNSET%\x`:                `C:\Users\ada\%UNSET%\x`,
+		`notes\inbox`:              `C:\Users\ada\notes\inbox`,
+		`d:/vault/./zettel`:        `d:\vault\zettel`,
+		`\\nas\share\notes\..\exo`: `\\nas\share\exo`,
+	} {
+		assert.Equal(t, want, env.Sanitize(in), in)
+	}
+	assert.True(t, env.IsAbs(`C:\x`))
+	assert.True(t, env.IsAbs(`\\nas\share`))
+	assert.False(t, env.IsAbs(`\x`))
+	assert.False(t, env.IsAbs(`C:x`))
+
+	unix := fs.Env{GOOS: "darwin", Home: "/Users/ada", Getenv: func(string) string { return "x" }}
+	assert.Equal(t, "/Users/ada/notes", unix.Sanitize("~/notes"))
+	assert.Equal(t, "/Users/ada/%HOME%", unix.Sanitize("%HOME%"), "%VARIABLES% are only expanded on Windows")
+	assert.Equal(t, "/Users/ada/~\\notes", unix.Sanitize(`~\notes`))
+}
+
+func TestEnv_EditorCommand(t *testing.T) {
+	env := windowsEnv(nil, map[string]string{
+		"code":    `C:\Program Files\VS Code\bin\code.cmd`,
+		"notepad": `C:\Windows\system32\notepad.exe`,
+	})
+	tests := []struct {
+		editor string
+		want   []string
+	}{
+		{"notepad", []string{"notepad", `C:\n.md`}},
+		{"code --wait", []string{"cmd", "/C", `C:\Program Files\VS Code\bin\code.cmd`, "--wait", `C:\n.md`}},
+		{`"C:\Program Files\Vim\gvim.exe" -p`, []string{`C:\Program Files\Vim\gvim.exe`, "-p", `C:\n.md`}},
+		{`C:\tools\edit.BAT`, []string{"cmd", "/C", `C:\tools\edit.BAT`, `C:\n.md`}},
+	}
+	for _, tt := range tests {
+		args, err := env.EditorCommand(tt.editor, `C:\n.md`)
+		require.NoError(t, err, tt.editor)
+		assert.Equal(t, tt.want, args, tt.editor)
+	}
+
+	unix := fs.Env{GOOS: "linux", Home: "/home/ada"}
+	args, err := unix.EditorCommand(`emacsclient -c -a ''`, "/n.md")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"emacsclient", "-c", "-a", "", "/n.md"}, args)
+
+	_, err = unix.EditorCommand(" ", "/n.md")
+	assert.Error(t, err)
+	_, err = env.EditorCommand(`"C:\Program Files\vim`, "/n.md")
+	assert.Error(t, err)
+}
+
+func TestEnv_EditorAtLine(t *testing.T) {
+	unix := fs.Env{GOOS: "linux", Home: "/home/ada"}
+	assert.Equal(t, "nvim +12", unix.EditorAtLine("nvim", 12))
+	assert.Equal(t, "/usr/bin/emacsclient -c +3", unix.EditorAtLine("/usr/bin/emacsclient -c", 3))
+	assert.Equal(t, "code --wait", unix.EditorAtLine("code --wait", 12), "other editors open the file at the top")
+	assert.Equal(t, "nvim", unix.EditorAtLine("nvim", 0))
+	env := windowsEnv(nil, nil)
+	assert.Equal(t, `"C:\Program Files\Vim\gvim.exe" +7`, env.EditorAtLine(`"C:\Program Files\Vim\gvim.exe"`, 7))
+}
+
+func TestEnv_ShellCommand(t *testing.T) {
+	assert.Equal(t, []string{"cmd", "/C", "echo hi"}, windowsEnv(nil, nil).ShellCommand("echo hi"))
+	assert.Equal(t, []string{"sh", "-c", "echo hi"}, fs.Env{GOOS: "linux"}.ShellCommand("echo hi"))
+}
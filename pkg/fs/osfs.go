@@ -66,16 +66,17 @@ func (fsys *OSFileSystem) AppendToFile(path, content string) error {
 	return nil
 }
 
-// OpenInEditor opens the specified file in the given editor.
-// It pipes the standard input/output and error streams to the editor process.
+// OpenInEditor opens the specified file in the given editor, which may carry
+// arguments, as "code --wait" (see Env.EditorCommand). It pipes the standard input/output and error streams to the editor process.
 func (fsys *OSFileSystem) OpenInEditor(path, editor string) error {
 	if path == "" {
 		return fmt.Errorf("filepath cannot be empty")
 	}
-	if editor == "" {
-		return fmt.Errorf("editor cannot be empty")
+	args, err := OSEnv().EditorCommand(editor, path)
+	if err != nil {
+		return err
 	}
-	cmd := exec.Command(editor, path)
+	cmd := exec.Command(args[0], args[1:]...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
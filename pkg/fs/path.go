@@ -3,19 +3,12 @@ package fs
 import (
 	"os"
 	"path/filepath"
-	"strings"
 )
 
-// ExpandPath expands a leading tilde (~) in the provided path to the user's home directory.
+// ExpandPath expands a leading tilde (~) in the provided path to the user's home directory,
+// and on Windows %VARIABLE% references to environment variables.
 func ExpandPath(path string) string {
-	if strings.HasPrefix(path, "~/") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return path // fallback to the original path if home cannot be determined
-		}
-		return filepath.Join(home, path[2:])
-	}
-	return path
+	return OSEnv().Expand(path)
 }
 
 // ResolvePath returns an absolute path by joining base with path if path is not absolute.
@@ -62,13 +55,22 @@ func GetXDGCacheHome() string {
 	return filepath.Join(home, ".cache")
 }
 
+// GetXDGStateHome returns the XDG_STATE_HOME directory, or defaults to $HOME/.local/state.
+func GetXDGStateHome() string {
+	if xdg := os.Getenv("XDG_STATE_HOME"); xdg != "" {
+		return xdg
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".local", "state")
+}
+
 // SanitizePath cleans the provided path after expanding any tilde. If the result is not absolute,
 // it is joined with the provided home directory.
 func SanitizePath(path, home string) string {
-	expanded := ExpandPath(path)
-	cleaned := filepath.Clean(expanded)
-	if !filepath.IsAbs(cleaned) {
-		cleaned = filepath.Join(home, cleaned)
-	}
-	return cleaned
+	env := OSEnv()
+	env.Home = home
+	return env.Sanitize(path)
 }
@@ -0,0 +1,70 @@
+package fs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+)
+
+// ErrReadOnly is returned by the writing methods of a ReadOnlyFileSystem.
+var ErrReadOnly = exoerrors.New(exoerrors.IO, "read-only mode is enabled")
+
+// ReadOnlyFileSystem wraps a FileSystem, passing reads through and rejecting
+// every write with ErrReadOnly.
+type ReadOnlyFileSystem struct {
+	fs FileSystem
+}
+
+// NewReadOnlyFileSystem creates a ReadOnlyFileSystem reading from fs.
+func NewReadOnlyFileSystem(fs FileSystem) *ReadOnlyFileSystem {
+	return &ReadOnlyFileSystem{fs: fs}
+}
+
+// EnsureDirectoryExists fails unless the parent directory of path already exists.
+func (r *ReadOnlyFileSystem) EnsureDirectoryExists(path string) error {
+	if r.fs.FileExists(filepath.Dir(path)) {
+		return nil
+	}
+	return r.reject("create the directory of", path)
+}
+
+// WriteFile always fails with ErrReadOnly.
+func (r *ReadOnlyFileSystem) WriteFile(path string, content []byte) error {
+	return r.reject("write", path)
+}
+
+// ReadFile reads the file from the wrapped FileSystem.
+func (r *ReadOnlyFileSystem) ReadFile(path string) ([]byte, error) {
+	return r.fs.ReadFile(path)
+}
+
+// FileExists reports whether the file exists in the wrapped FileSystem.
+func (r *ReadOnlyFileSystem) FileExists(path string) bool {
+	return r.fs.FileExists(path)
+}
+
+// DeleteFile always fails with ErrReadOnly.
+func (r *ReadOnlyFileSystem) DeleteFile(path string) error {
+	return r.reject("delete", path)
+}
+
+// AppendToFile always fails with ErrReadOnly.
+func (r *ReadOnlyFileSystem) AppendToFile(path, content string) error {
+	return r.reject("append to", path)
+}
+
+// OpenInEditor always fails with ErrReadOnly, as editors write the files they open.
+func (r *ReadOnlyFileSystem) OpenInEditor(path, editor string) error {
+	return r.reject("edit", path)
+}
+
+// ReadDir lists the directory in the wrapped FileSystem.
+func (r *ReadOnlyFileSystem) ReadDir(path string) ([]os.DirEntry, error) {
+	return r.fs.ReadDir(path)
+}
+
+func (r *ReadOnlyFileSystem) reject(action, path string) error {
+	return fmt.Errorf("cannot %s %s: %w", action, path, ErrReadOnly)
+}
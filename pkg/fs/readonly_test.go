@@ -0,0 +1,39 @@
+package fs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadOnlyFileSystem(t *testing.T) {
+	tmpDir := t.TempDir()
+	filePath := filepath.Join(tmpDir, "note.md")
+	require.NoError(t, os.WriteFile(filePath, []byte("# Note"), 0644))
+	rofs := fs.NewReadOnlyFileSystem(fs.NewOSFileSystem())
+
+	content, err := rofs.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Note", string(content))
+	assert.True(t, rofs.FileExists(filePath))
+	entries, err := rofs.ReadDir(tmpDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.NoError(t, rofs.EnsureDirectoryExists(filePath), "existing directories need not be created")
+
+	assert.ErrorIs(t, rofs.EnsureDirectoryExists(filepath.Join(tmpDir, "new", "note.md")), fs.ErrReadOnly)
+	assert.ErrorIs(t, rofs.WriteFile(filePath, []byte("changed")), fs.ErrReadOnly)
+	assert.ErrorIs(t, rofs.AppendToFile(filePath, "more"), fs.ErrReadOnly)
+	assert.ErrorIs(t, rofs.DeleteFile(filePath), fs.ErrReadOnly)
+	assert.ErrorIs(t, rofs.OpenInEditor(filePath, "true"), fs.ErrReadOnly)
+
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Note", string(content))
+	_, err = os.Stat(filepath.Join(tmpDir, "new"))
+	assert.True(t, os.IsNotExist(err))
+}
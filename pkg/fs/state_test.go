@@ -0,0 +1,33 @@
+package fs_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStateFile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "state")
+	path := filepath.Join(dir, "state.json")
+
+	got := map[string]int{"kept": 1}
+	require.NoError(t, fs.ReadStateFile(path, "test state", &got), "a missing file is no error")
+	assert.Equal(t, map[string]int{"kept": 1}, got)
+
+	require.NoError(t, fs.WriteStateFile(path, "test state", map[string]int{"a": 1}))
+	require.NoError(t, fs.WriteStateFile(path, "test state", map[string]int{"b": 2}))
+	got = nil
+	require.NoError(t, fs.ReadStateFile(path, "test state", &got))
+	assert.Equal(t, map[string]int{"b": 2}, got)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1, "no temporary file is left behind")
+
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	assert.ErrorContains(t, fs.ReadStateFile(path, "test state", &got), "failed to parse test state "+path)
+}
@@ -0,0 +1,140 @@
+// Package grep finds the lines of notes matching a pattern, together with the
+// headings they are under.
+package grep
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Options are what Grep looks for.
+type Options struct {
+	// Pattern is the text to find, or a regular expression with Regexp.
+	Pattern    string
+	Regexp     bool
+	IgnoreCase bool
+}
+
+// Match is a line of a note matching the pattern.
+type Match struct {
+	Path  string `json:"path"`
+	Title string `json:"title"`
+	// Headings are the headings the line is under, outermost first.
+	Headings []string `json:"headings,omitempty"`
+	// Line is the line number, counted from 1 at the top of the file.
+	Line int `json:"line"`
+	// Column is the byte offset of the match in the line, counted from 1.
+	Column int    `json:"column"`
+	Text   string `json:"text"`
+}
+
+// String returns m as "path:line: Title > Heading: text", leaving out a
+// first heading repeating the title.
+func (m Match) String() string {
+	headings := m.Headings
+	if len(headings) > 0 && headings[0] == m.Title {
+		headings = headings[1:]
+	}
+	context := strings.Join(append([]string{m.Title}, headings...), " > ")
+	return fmt.Sprintf("%s:%d: %s: %s", m.Path, m.Line, context, m.Text)
+}
+
+// Grep finds the lines matching a pattern.
+type Grep struct {
+	re *regexp.Regexp
+}
+
+// New returns a Grep for opts.
+func New(opts Options) (*Grep, error) {
+	if opts.Pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+	expr := opts.Pattern
+	if !opts.Regexp {
+		expr = regexp.QuoteMeta(expr)
+	}
+	if opts.IgnoreCase {
+		expr = "(?i)" + expr
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", opts.Pattern, err)
+	}
+	return &Grep{re: re}, nil
+}
+
+// Note returns the lines of content, the note at path titled title, that
+// match. Headings in fenced code blocks and frontmatter are not headings.
+func (g *Grep) Note(path, title, content string) []Match {
+	var matches []Match
+	doc := md.Parse(content)
+	for i, line := range doc.Lines {
+		loc := g.re.FindStringIndex(line)
+		if loc == nil {
+			continue
+		}
+		section := doc.At(i)
+		if section.Line == i {
+			// A heading is not under itself.
+			section = section.Parent
+		}
+		matches = append(matches, g.match(path, title, section.Path(), i, loc, line))
+	}
+	return matches
+}
+
+func (g *Grep) match(path, title string, headings []string, i int, loc []int, line string) Match {
+	return Match{
+		Path:     path,
+		Title:    title,
+		Headings: headings,
+		Line:     i + 1,
+		Column:   loc[0] + 1,
+		Text:     strings.TrimRight(line, "\r"),
+	}
+}
+
+// Notes returns the matching lines of notes, in their order, reading the
+// notes concurrently.
+func (g *Grep) Notes(ctx context.Context, notes []scan.Note) ([]Match, error) {
+	results := make([][]Match, len(notes))
+	errs := make([]error, len(notes))
+	sem := make(chan struct{}, runtime.NumCPU())
+	var wg sync.WaitGroup
+	for i, n := range notes {
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem; wg.Done() }()
+			content, err := os.ReadFile(n.Path)
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to read %s: %w", n.Path, err)
+				return
+			}
+			results[i] = g.Note(n.Path, n.Title, string(content))
+		}()
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var matches []Match
+	for i := range notes {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		matches = append(matches, results[i]...)
+	}
+	return matches, nil
+}
@@ -0,0 +1,76 @@
+package grep_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/grep"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const channels = `---
+title: Go channels
+---
+# Go channels
+
+Channels connect goroutines.
+
+## Buffers
+
+### Sizing
+A buffered channel holds values.
+
+` + "```go\n# not a heading, a channel comment\n```" + `
+
+## Closing
+Close a channel once.
+`
+
+func TestGrep_Note(t *testing.T) {
+	g, err := grep.New(grep.Options{Pattern: "channel"})
+	require.NoError(t, err)
+	matches := g.Note("/v/go.md", "Go channels", channels)
+	require.Len(t, matches, 5)
+	assert.Equal(t, grep.Match{Path: "/v/go.md", Title: "Go channels", Line: 2, Column: 11, Text: "title: Go channels"}, matches[0])
+	assert.Equal(t, 4, matches[1].Line)
+	assert.Empty(t, matches[1].Headings, "a heading is not under itself")
+	assert.Equal(t, []string{"Go channels", "Buffers", "Sizing"}, matches[2].Headings)
+	assert.Equal(t, []string{"Go channels", "Buffers", "Sizing"}, matches[3].Headings, "fenced lines are not headings")
+	assert.Equal(t, grep.Match{Path: "/v/go.md", Title: "Go channels", Headings: []string{"Go channels", "Closing"}, Line: 18, Column: 9, Text: "Close a channel once."}, matches[4])
+	assert.Equal(t, "/v/go.md:18: Go channels > Closing: Close a channel once.", matches[4].String())
+
+	g, err = grep.New(grep.Options{Pattern: `^channels`, Regexp: true, IgnoreCase: true})
+	require.NoError(t, err)
+	assert.Len(t, g.Note("/v/go.md", "Go channels", channels), 1)
+
+	_, err = grep.New(grep.Options{Pattern: "(", Regexp: true})
+	assert.Error(t, err)
+	_, err = grep.New(grep.Options{})
+	assert.Error(t, err)
+}
+
+func TestGrep_Notes(t *testing.T) {
+	dir := t.TempDir()
+	var notes []scan.Note
+	for _, name := range []string{"a.md", "b.md", "c.md"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("# "+name+"\nfind me\n"), 0644))
+		notes = append(notes, scan.Note{Path: path, Title: name})
+	}
+	g, err := grep.New(grep.Options{Pattern: "find"})
+	require.NoError(t, err)
+	matches, err := g.Notes(context.Background(), notes)
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	for i, m := range matches {
+		assert.Equal(t, notes[i].Path, m.Path, "matches keep the order of the notes")
+		assert.Equal(t, []string{notes[i].Title}, m.Headings)
+	}
+
+	_, err = g.Notes(context.Background(), []scan.Note{{Path: filepath.Join(dir, "missing.md")}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,253 @@
+// Package habit tracks habits in daily notes. Each daily note holds a block
+// under the Habits heading listing the habits as Markdown tasks, checked on
+// the days they were done:
+//
+//	## Habits
+//
+//	- [x] Run
+//	- [ ] Read
+package habit
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/md"
+)
+
+// Heading introduces the habits block of daily notes.
+const Heading = "## Habits"
+
+// dateLayout is the layout of daily note file names.
+const dateLayout = "2006-01-02"
+
+var itemPattern = regexp.MustCompile(`^\s*[-*+] \[([ xX])\] (.*\S)\s*$`)
+
+// Item is a habit listed in a daily note.
+type Item struct {
+	Name string
+	Done bool
+}
+
+// Parse returns the habits listed in the habits block of content, in order.
+func Parse(content string) []Item {
+	body, err := md.Extract(content, Heading)
+	if err != nil {
+		return nil
+	}
+	var items []Item
+	for _, line := range strings.Split(body, "\n") {
+		if m := itemPattern.FindStringSubmatch(line); m != nil {
+			items = append(items, Item{Name: m[2], Done: m[1] != " "})
+		}
+	}
+	return items
+}
+
+// Done reports whether the habit called name is checked in items, comparing
+// names case-insensitively.
+func Done(items []Item, name string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item.Name, name) {
+			return item.Done
+		}
+	}
+	return false
+}
+
+// Check returns content with the habit called name checked, or unchecked
+// unless done. The habits block is rewritten to list habits in order,
+// followed by the other habits it listed, and added to the end of content
+// when missing.
+func Check(content string, habits []string, name string, done bool) string {
+	items := Parse(content)
+	var lines []string
+	add := func(habit string, checked bool) {
+		if strings.EqualFold(habit, name) {
+			checked = done
+		}
+		mark := " "
+		if checked {
+			mark = "x"
+		}
+		lines = append(lines, fmt.Sprintf("- [%s] %s", mark, habit))
+	}
+	listed := func(habit string) bool {
+		for _, h := range habits {
+			if strings.EqualFold(h, habit) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, habit := range habits {
+		add(habit, Done(items, habit))
+	}
+	for _, item := range items {
+		if !listed(item.Name) {
+			add(item.Name, item.Done)
+		}
+	}
+	if !listed(name) && !containsItem(items, name) {
+		add(name, done)
+	}
+	return md.Replace(content, Heading, strings.Join(lines, "\n"))
+}
+
+// containsItem reports whether items list the habit called name.
+func containsItem(items []Item, name string) bool {
+	for _, item := range items {
+		if strings.EqualFold(item.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Read returns the habits listed in the daily note of date in dailyDir. A
+// missing note lists no habits.
+func Read(dailyDir string, date time.Time) ([]Item, error) {
+	content, err := os.ReadFile(filepath.Join(dailyDir, date.Format(dateLayout)+".md"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read daily note: %w", err)
+	}
+	return Parse(string(content)), nil
+}
+
+// Streak returns the number of consecutive days, up to today, the habit
+// called name was done. A streak still counts if today is not checked yet.
+func Streak(dailyDir, name string, today time.Time) (int, error) {
+	streak := 0
+	for day := today; ; day = day.AddDate(0, 0, -1) {
+		items, err := Read(dailyDir, day)
+		if err != nil {
+			return 0, err
+		}
+		if !Done(items, name) {
+			if day.Equal(today) {
+				continue
+			}
+			return streak, nil
+		}
+		streak++
+	}
+}
+
+// Matrix is the completion of habits over the days of a month.
+type Matrix struct {
+	// Month is the first day of the month.
+	Month  time.Time
+	Habits []string
+	// Done holds, for each habit, whether it was done on each day of the month.
+	Done [][]bool
+	// Elapsed is the number of days of the month up to today, over which
+	// completion rates are computed.
+	Elapsed int
+}
+
+// Report builds the Matrix of habits for the month of month from the daily
+// notes in dailyDir. Habits checked in the notes but not in habits are added.
+func Report(dailyDir string, habits []string, month, today time.Time) (Matrix, error) {
+	first := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, month.Location())
+	days := first.AddDate(0, 1, -1).Day()
+	m := Matrix{Month: first, Habits: append([]string(nil), habits...), Elapsed: days}
+	if end := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, month.Location()); end.Before(first.AddDate(0, 1, 0)) {
+		m.Elapsed = 0
+		if !end.Before(first) {
+			m.Elapsed = end.Day()
+		}
+	}
+	for range m.Habits {
+		m.Done = append(m.Done, make([]bool, days))
+	}
+	for d := 0; d < days; d++ {
+		items, err := Read(dailyDir, first.AddDate(0, 0, d))
+		if err != nil {
+			return Matrix{}, err
+		}
+		for _, item := range items {
+			if !item.Done {
+				continue
+			}
+			h := m.index(item.Name)
+			if h == -1 {
+				m.Habits = append(m.Habits, item.Name)
+				m.Done = append(m.Done, make([]bool, days))
+				h = len(m.Habits) - 1
+			}
+			m.Done[h][d] = true
+		}
+	}
+	return m, nil
+}
+
+// index returns the index of the habit called name, or -1.
+func (m Matrix) index(name string) int {
+	for i, habit := range m.Habits {
+		if strings.EqualFold(habit, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Count returns the number of days habit h was done.
+func (m Matrix) Count(h int) int {
+	n := 0
+	for _, done := range m.Done[h] {
+		if done {
+			n++
+		}
+	}
+	return n
+}
+
+// Render writes the matrix to w as a table with a column per day of the
+// month, marking the days a habit was done with x and the other past days
+// with a dot, followed by the completion rate of each habit.
+func (m Matrix) Render(w io.Writer) error {
+	width := len("Habit")
+	for _, habit := range m.Habits {
+		width = max(width, len([]rune(habit)))
+	}
+	days := m.Month.AddDate(0, 1, -1).Day()
+	var tens, units strings.Builder
+	for d := 1; d <= days; d++ {
+		tens.WriteByte(" 123"[d/10])
+		units.WriteByte(byte('0' + d%10))
+	}
+	fmt.Fprintf(w, "%s\n\n", m.Month.Format("January 2006"))
+	fmt.Fprintf(w, "%-*s  %s\n", width, "", tens.String())
+	fmt.Fprintf(w, "%-*s  %s  %s\n", width, "Habit", units.String(), "Done")
+	for h, habit := range m.Habits {
+		var row strings.Builder
+		for d, done := range m.Done[h] {
+			switch {
+			case done:
+				row.WriteByte('x')
+			case d < m.Elapsed:
+				row.WriteByte('.')
+			default:
+				row.WriteByte(' ')
+			}
+		}
+		rate := 0
+		if m.Elapsed > 0 {
+			rate = 100 * m.Count(h) / m.Elapsed
+		}
+		if _, err := fmt.Fprintf(w, "%-*s  %s  %d/%d (%d%%)\n", width, habit, row.String(), m.Count(h), m.Elapsed, rate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
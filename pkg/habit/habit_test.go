@@ -0,0 +1,96 @@
+package habit_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/habit"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	content := "# 2025-02-08\n\n## Habits\n\n- [x] Run\n- [ ] Read\n* [X] Floss\n- not a habit\n\n## Notes\n\n- [x] not a habit either\n"
+	items := habit.Parse(content)
+	assert.Equal(t, []habit.Item{{Name: "Run", Done: true}, {Name: "Read"}, {Name: "Floss", Done: true}}, items)
+	assert.True(t, habit.Done(items, "run"))
+	assert.False(t, habit.Done(items, "Read"))
+	assert.False(t, habit.Done(items, "Swim"))
+	assert.Empty(t, habit.Parse("# No habits\n"))
+}
+
+func TestCheck(t *testing.T) {
+	habits := []string{"Run", "Read"}
+
+	content := habit.Check("# 2025-02-08\n\n## Notes\n\n- keep\n", habits, "read", true)
+	assert.Equal(t, "# 2025-02-08\n\n## Notes\n\n- keep\n\n## Habits\n\n- [ ] Run\n- [x] Read\n", content)
+
+	content = habit.Check(content, habits, "Run", true)
+	assert.Equal(t, "# 2025-02-08\n\n## Notes\n\n- keep\n\n## Habits\n\n- [x] Run\n- [x] Read\n", content)
+
+	content = habit.Check(content, habits, "Read", false)
+	assert.Equal(t, "# 2025-02-08\n\n## Notes\n\n- keep\n\n## Habits\n\n- [x] Run\n- [ ] Read\n", content)
+
+	// Habits no longer configured are kept.
+	content = habit.Check("## Habits\n\n- [x] Swim\n", habits, "Run", true)
+	assert.Equal(t, "## Habits\n\n- [x] Run\n- [ ] Read\n- [x] Swim\n", content)
+}
+
+func writeDay(t *testing.T, dir string, date time.Time, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, date.Format("2006-01-02")+".md"), []byte(content), 0644))
+}
+
+func TestStreak(t *testing.T) {
+	dir := t.TempDir()
+	today := time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC)
+	writeDay(t, dir, today, "## Habits\n\n- [ ] Run\n")
+	writeDay(t, dir, today.AddDate(0, 0, -1), "## Habits\n\n- [x] Run\n")
+	writeDay(t, dir, today.AddDate(0, 0, -2), "## Habits\n\n- [x] Run\n")
+	writeDay(t, dir, today.AddDate(0, 0, -4), "## Habits\n\n- [x] Run\n")
+
+	streak, err := habit.Streak(dir, "run", today)
+	require.NoError(t, err)
+	assert.Equal(t, 2, streak)
+
+	writeDay(t, dir, today, "## Habits\n\n- [x] Run\n")
+	streak, err = habit.Streak(dir, "Run", today)
+	require.NoError(t, err)
+	assert.Equal(t, 3, streak)
+
+	streak, err = habit.Streak(dir, "Read", today)
+	require.NoError(t, err)
+	assert.Zero(t, streak)
+}
+
+func TestReport(t *testing.T) {
+	dir := t.TempDir()
+	day := func(d int) time.Time { return time.Date(2025, 2, d, 0, 0, 0, 0, time.UTC) }
+	writeDay(t, dir, day(1), "## Habits\n\n- [x] Run\n- [ ] Read\n")
+	writeDay(t, dir, day(2), "## Habits\n\n- [x] Run\n- [x] Read\n- [x] Swim\n")
+	writeDay(t, dir, day(4), "## Habits\n\n- [ ] Run\n- [x] Read\n")
+
+	m, err := habit.Report(dir, []string{"Run", "Read"}, day(14), day(4))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Run", "Read", "Swim"}, m.Habits)
+	assert.Equal(t, 4, m.Elapsed)
+	assert.Equal(t, 2, m.Count(0))
+	assert.Equal(t, 2, m.Count(1))
+	assert.Equal(t, 1, m.Count(2))
+
+	var buf bytes.Buffer
+	require.NoError(t, m.Render(&buf))
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 7)
+	assert.Equal(t, "February 2025", string(lines[0]))
+	assert.Equal(t, "Habit  1234567890123456789012345678  Done", string(lines[3]))
+	assert.Equal(t, "Run    xx..                          2/4 (50%)", string(lines[4]))
+	assert.Equal(t, "Read   .x.x                          2/4 (50%)", string(lines[5]))
+
+	m, err = habit.Report(dir, []string{"Run"}, day(1), day(1).AddDate(0, 2, 0))
+	require.NoError(t, err)
+	assert.Equal(t, 28, m.Elapsed, "past months are complete")
+}
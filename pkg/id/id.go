@@ -0,0 +1,236 @@
+package id
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAttempts is the number of IDs Unique generates before giving up.
+const DefaultAttempts = 10
+
+// ErrExhausted is returned by Unique when every generated ID was already taken.
+var ErrExhausted = errors.New("could not generate a unique id")
+
+// Generator produces note identifiers. dir is the directory the note is created in,
+// which lets generators such as Sequential number notes per directory.
+// Calling Generate again after a collision must eventually return a different ID.
+type Generator interface {
+	Generate(dir string) (string, error)
+}
+
+// Factory creates a new Generator.
+type Factory func() Generator
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		"timestamp":  func() Generator { return &Timestamp{} },
+		"ulid":       func() Generator { return &ULID{} },
+		"nanoid":     func() Generator { return &NanoID{} },
+		"sequential": func() Generator { return &Sequential{} },
+	}
+)
+
+// Register makes a generator available under name, replacing any existing one.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// Names returns the names of the registered generators in sorted order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns a new generator registered under name.
+func New(name string) (Generator, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown id generator %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(), nil
+}
+
+// Unique generates IDs with gen until one is not reported as taken, trying at most
+// attempts times (DefaultAttempts if attempts is not positive).
+func Unique(gen Generator, dir string, taken func(string) bool, attempts int) (string, error) {
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+	for i := 0; i < attempts; i++ {
+		id, err := gen.Generate(dir)
+		if err != nil {
+			return "", err
+		}
+		if !taken(id) {
+			return id, nil
+		}
+	}
+	return "", fmt.Errorf("%w after %d attempts", ErrExhausted, attempts)
+}
+
+// Timestamp generates IDs from the current time, e.g. "20250208143005".
+// Successive IDs from the same generator are at least one second apart,
+// so regenerating after a collision yields the next free second.
+type Timestamp struct {
+	// Layout is the time layout of the ID; it defaults to "20060102150405".
+	Layout string
+	// Now returns the current time; it defaults to time.Now.
+	Now func() time.Time
+
+	last time.Time
+}
+
+// Generate implements Generator.
+func (t *Timestamp) Generate(string) (string, error) {
+	now := time.Now
+	if t.Now != nil {
+		now = t.Now
+	}
+	layout := t.Layout
+	if layout == "" {
+		layout = "20060102150405"
+	}
+	ts := now().Truncate(time.Second)
+	if !t.last.IsZero() && !ts.After(t.last) {
+		ts = t.last.Add(time.Second)
+	}
+	t.last = ts
+	return ts.Format(layout), nil
+}
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID generates Universally Unique Lexicographically Sortable Identifiers:
+// a 48-bit millisecond timestamp followed by 80 random bits, encoded as
+// 26 Crockford base32 characters.
+type ULID struct {
+	// Now returns the current time; it defaults to time.Now.
+	Now func() time.Time
+}
+
+// Generate implements Generator.
+func (u *ULID) Generate(string) (string, error) {
+	now := time.Now
+	if u.Now != nil {
+		now = u.Now
+	}
+	var b [16]byte
+	ms := uint64(now().UnixMilli())
+	binary.BigEndian.PutUint64(b[:8], ms<<16)
+	if _, err := rand.Read(b[6:]); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+
+	// Encode the 128 bits as 26 base32 characters (the first carries 3 bits).
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	out := make([]byte, 26)
+	for i := 25; i >= 0; i-- {
+		out[i] = crockford[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out), nil
+}
+
+// nanoAlphabet is the URL-safe alphabet used by NanoID.
+const nanoAlphabet = "_-0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// NanoID generates short random URL-safe IDs.
+type NanoID struct {
+	// Size is the number of characters; it defaults to 21.
+	Size int
+}
+
+// Generate implements Generator.
+func (n *NanoID) Generate(string) (string, error) {
+	size := n.Size
+	if size <= 0 {
+		size = 21
+	}
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to read random bytes: %w", err)
+	}
+	for i := range b {
+		// The alphabet has 64 characters, so masking keeps the distribution uniform.
+		b[i] = nanoAlphabet[b[i]&63]
+	}
+	return string(b), nil
+}
+
+// Sequential numbers notes per directory: the ID is one more than the largest
+// numeric prefix among the directory's file names, zero-padded, e.g. "0042".
+type Sequential struct {
+	// Width is the minimum number of digits; it defaults to 4.
+	Width int
+
+	last map[string]int
+}
+
+// Generate implements Generator.
+func (s *Sequential) Generate(dir string) (string, error) {
+	width := s.Width
+	if width <= 0 {
+		width = 4
+	}
+	max, err := maxNumericPrefix(dir)
+	if err != nil {
+		return "", err
+	}
+	if s.last == nil {
+		s.last = make(map[string]int)
+	}
+	if last, ok := s.last[dir]; ok && last > max {
+		max = last
+	}
+	s.last[dir] = max + 1
+	return fmt.Sprintf("%0*d", width, max+1), nil
+}
+
+// maxNumericPrefix returns the largest leading number among the names in dir.
+// A missing directory yields 0.
+func maxNumericPrefix(dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+	max := 0
+	for _, e := range entries {
+		name := e.Name()
+		end := 0
+		for end < len(name) && name[end] >= '0' && name[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			continue
+		}
+		if n, err := strconv.Atoi(name[:end]); err == nil && n > max {
+			max = n
+		}
+	}
+	return max, nil
+}
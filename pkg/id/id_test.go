@@ -0,0 +1,113 @@
+package id_test
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/id"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fixedClock() func() time.Time {
+	t := time.Date(2025, 2, 8, 14, 30, 5, 0, time.UTC)
+	return func() time.Time { return t }
+}
+
+func TestTimestamp_AdvancesOnRegenerate(t *testing.T) {
+	gen := &id.Timestamp{Now: fixedClock()}
+
+	first, err := gen.Generate("")
+	require.NoError(t, err)
+	second, err := gen.Generate("")
+	require.NoError(t, err)
+
+	assert.Equal(t, "20250208143005", first)
+	assert.Equal(t, "20250208143006", second)
+}
+
+func TestULID(t *testing.T) {
+	gen := &id.ULID{Now: fixedClock()}
+
+	a, err := gen.Generate("")
+	require.NoError(t, err)
+	b, err := gen.Generate("")
+	require.NoError(t, err)
+
+	assert.Regexp(t, regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`), a)
+	// The first 10 characters encode the timestamp.
+	assert.Equal(t, a[:10], b[:10])
+	assert.Equal(t, "01JKJZFEE8", a[:10])
+	assert.NotEqual(t, a, b)
+}
+
+func TestNanoID(t *testing.T) {
+	id1, err := (&id.NanoID{}).Generate("")
+	require.NoError(t, err)
+	assert.Regexp(t, regexp.MustCompile(`^[A-Za-z0-9_-]{21}$`), id1)
+
+	id2, err := (&id.NanoID{Size: 8}).Generate("")
+	require.NoError(t, err)
+	assert.Len(t, id2, 8)
+}
+
+func TestSequential(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0007 Seven.md"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "0012.md"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.md"), nil, 0644))
+
+	gen := &id.Sequential{}
+	first, err := gen.Generate(dir)
+	require.NoError(t, err)
+	second, err := gen.Generate(dir)
+	require.NoError(t, err)
+	other, err := gen.Generate(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "0013", first)
+	assert.Equal(t, "0014", second)
+	assert.Equal(t, "0001", other)
+}
+
+func TestNew(t *testing.T) {
+	for _, name := range []string{"timestamp", "ulid", "nanoid", "sequential"} {
+		gen, err := id.New(name)
+		require.NoError(t, err, name)
+		assert.NotNil(t, gen)
+	}
+
+	_, err := id.New("uuid")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "available")
+}
+
+type fixedGenerator string
+
+func (f fixedGenerator) Generate(string) (string, error) { return string(f), nil }
+
+func TestRegister(t *testing.T) {
+	id.Register("fixed", func() id.Generator { return fixedGenerator("x") })
+	assert.Contains(t, id.Names(), "fixed")
+
+	gen, err := id.New("fixed")
+	require.NoError(t, err)
+	got, err := gen.Generate("")
+	require.NoError(t, err)
+	assert.Equal(t, "x", got)
+}
+
+func TestUnique(t *testing.T) {
+	gen := &id.Timestamp{Now: fixedClock()}
+	taken := map[string]bool{"20250208143005": true, "20250208143006": true}
+
+	got, err := id.Unique(gen, "", func(s string) bool { return taken[s] }, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "20250208143007", got)
+
+	_, err = id.Unique(fixedGenerator("x"), "", func(string) bool { return true }, 3)
+	assert.ErrorIs(t, err, id.ErrExhausted)
+}
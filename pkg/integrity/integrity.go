@@ -0,0 +1,282 @@
+// Package integrity keeps a manifest of the content hashes of the files of the
+// vault, to detect files changed behind the back of their modification time,
+// truncated files and notes that are not clean UTF-8.
+package integrity
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	exofs "github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// File is the manifest file, in the state directory.
+const File = "manifest.json"
+
+// Entry is the content of a file as last seen.
+type Entry struct {
+	Path     string    `json:"path"`
+	Hash     string    `json:"sha256"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Manifest records the content of the files of a vault in a JSON file next to
+// the notes.
+type Manifest struct {
+	path    string
+	Entries map[string]Entry
+}
+
+// OpenManifest reads the manifest at path. A missing file yields an empty
+// manifest.
+func OpenManifest(path string) (*Manifest, error) {
+	m := &Manifest{path: path, Entries: make(map[string]Entry)}
+	var entries []Entry
+	if err := exofs.ReadStateFile(path, "manifest", &entries); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		m.Entries[e.Path] = e
+	}
+	return m, nil
+}
+
+// Save writes the manifest to its file, ordered by path, creating the
+// directory if needed.
+func (m *Manifest) Save() error {
+	entries := make([]Entry, 0, len(m.Entries))
+	for _, e := range m.Entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return exofs.WriteStateFile(m.path, "manifest", entries)
+}
+
+// Kind is a kind of problem found in a file.
+type Kind string
+
+// The problems found.
+const (
+	// Modified files differ from the manifest without their modification
+	// time having changed, as when the disk or a program corrupted them.
+	Modified Kind = "modified"
+	// Truncated files are empty, but were not.
+	Truncated Kind = "truncated"
+	// InvalidUTF8 notes are not valid UTF-8, as when written in another
+	// encoding such as Windows-1252.
+	InvalidUTF8 Kind = "invalid-utf8"
+	// BOM notes start with a byte order mark.
+	BOM Kind = "bom"
+	// NUL notes contain NUL bytes, as the blocks left zeroed by a crash.
+	NUL Kind = "nul"
+)
+
+// Fixable reports whether problems of kind k can be fixed by normalizing the
+// file: modified and truncated files are restored from versions or backups.
+func (k Kind) Fixable() bool {
+	return k == InvalidUTF8 || k == BOM || k == NUL
+}
+
+// Issue is a problem found in a file.
+type Issue struct {
+	// Path is slash-separated, relative to the vault.
+	Path   string `json:"path"`
+	Kind   Kind   `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+	// Fixed is set when the problem was fixed.
+	Fixed bool `json:"fixed,omitempty"`
+}
+
+// Options configures Check.
+type Options struct {
+	// Exclude lists directories under the vault that are not checked.
+	Exclude []string
+	// Fix normalizes the notes with fixable problems.
+	Fix bool
+	// Accept records modified and truncated files in the manifest as they
+	// are, so that they are not reported again.
+	Accept bool
+}
+
+// Result summarizes a check.
+type Result struct {
+	Files   int
+	Added   int
+	Changed int
+	Removed int
+	Issues  []Issue
+}
+
+// Check compares the files under root, except hidden ones, with m and updates
+// m: new files are added, files whose modification time changed are recorded
+// as changed and files gone are removed. Files that changed without their
+// modification time changing, or were emptied, are reported and left as
+// recorded, unless opts.Accept is set. Notes are also checked for encoding
+// problems, and fixed with opts.Fix.
+func Check(root string, m *Manifest, opts Options) (*Result, error) {
+	result := &Result{}
+	seen := make(map[string]bool)
+	skip := scan.WalkOptions{Exclude: opts.Exclude}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if skip.Skip(root, p, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		seen[rel] = true
+		result.Files++
+		return check(p, rel, m, opts, result)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check files: %w", err)
+	}
+	for rel := range m.Entries {
+		if !seen[rel] {
+			delete(m.Entries, rel)
+			result.Removed++
+		}
+	}
+	sort.SliceStable(result.Issues, func(i, j int) bool { return result.Issues[i].Path < result.Issues[j].Path })
+	return result, nil
+}
+
+// check checks the file at p, rel under the vault.
+func check(p, rel string, m *Manifest, opts Options, result *Result) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	entry := Entry{Path: rel, Hash: hash(data), Size: info.Size(), Modified: info.ModTime().UTC()}
+
+	if filepath.Ext(p) == scan.NoteExtension {
+		var issues []Issue
+		fixed := data
+		for _, issue := range encodingIssues(rel, data) {
+			if opts.Fix {
+				fixed = normalize(fixed, issue.Kind)
+				issue.Fixed = true
+			}
+			issues = append(issues, issue)
+		}
+		if opts.Fix && len(issues) > 0 {
+			if err := os.WriteFile(p, fixed, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to fix %s: %w", rel, err)
+			}
+			if info, err = os.Stat(p); err != nil {
+				return err
+			}
+			entry = Entry{Path: rel, Hash: hash(fixed), Size: info.Size(), Modified: info.ModTime().UTC()}
+		}
+		result.Issues = append(result.Issues, issues...)
+	}
+
+	old, ok := m.Entries[rel]
+	switch {
+	case !ok:
+		result.Added++
+	case old.Hash == entry.Hash:
+	case entry.Size == 0 && old.Size > 0:
+		result.Issues = append(result.Issues, Issue{Path: rel, Kind: Truncated, Detail: fmt.Sprintf("was %d bytes", old.Size)})
+		if !opts.Accept {
+			return nil
+		}
+	case old.Modified.Equal(entry.Modified):
+		result.Issues = append(result.Issues, Issue{Path: rel, Kind: Modified, Detail: "content changed, modification time did not"})
+		if !opts.Accept {
+			return nil
+		}
+	default:
+		result.Changed++
+	}
+	m.Entries[rel] = entry
+	return nil
+}
+
+// encodingIssues returns the encoding problems of the note data.
+func encodingIssues(rel string, data []byte) []Issue {
+	var issues []Issue
+	if bytes.HasPrefix(data, bom) {
+		issues = append(issues, Issue{Path: rel, Kind: BOM})
+	}
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		issues = append(issues, Issue{Path: rel, Kind: NUL, Detail: fmt.Sprintf("at byte %d", i)})
+	}
+	if !utf8.Valid(data) {
+		valid := data
+		for len(valid) > 0 {
+			r, size := utf8.DecodeRune(valid)
+			if r == utf8.RuneError && size == 1 {
+				break
+			}
+			valid = valid[size:]
+		}
+		issues = append(issues, Issue{Path: rel, Kind: InvalidUTF8, Detail: fmt.Sprintf("at byte %d", len(data)-len(valid))})
+	}
+	return issues
+}
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// normalize fixes the problem kind of data: the byte order mark is removed,
+// NUL bytes are removed and text that is not UTF-8 is taken for Windows-1252
+// and converted.
+func normalize(data []byte, kind Kind) []byte {
+	switch kind {
+	case BOM:
+		return bytes.TrimPrefix(data, bom)
+	case NUL:
+		return bytes.ReplaceAll(data, []byte{0}, nil)
+	case InvalidUTF8:
+		if utf8.Valid(data) {
+			return data
+		}
+		var buf bytes.Buffer
+		for _, b := range data {
+			if b >= 0x80 && b < 0xA0 && windows1252[b-0x80] != 0 {
+				buf.WriteRune(windows1252[b-0x80])
+			} else {
+				buf.WriteRune(rune(b))
+			}
+		}
+		return buf.Bytes()
+	}
+	return data
+}
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252 to their runes; the
+// other bytes are those of Latin-1.
+var windows1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,117 @@
+package integrity_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/integrity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+// rewrite replaces the content of the file at path, keeping its modification
+// time.
+func rewrite(t *testing.T, path, content string) {
+	t.Helper()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, os.Chtimes(path, info.ModTime(), info.ModTime()))
+}
+
+func TestCheck(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "Go.md"), "# Go\n")
+	writeFile(t, filepath.Join(vault, "zettel", "Rust.md"), "# Rust\n")
+	writeFile(t, filepath.Join(vault, "assets", "img.png"), "png")
+	writeFile(t, filepath.Join(vault, "templates", "day.md"), "# {{.Date}}\n")
+	writeFile(t, filepath.Join(vault, ".git", "HEAD"), "ref")
+	opts := integrity.Options{Exclude: []string{filepath.Join(vault, "templates")}}
+
+	manifestPath := filepath.Join(t.TempDir(), integrity.File)
+	m, err := integrity.OpenManifest(manifestPath)
+	require.NoError(t, err)
+	result, err := integrity.Check(vault, m, opts)
+	require.NoError(t, err)
+	assert.Equal(t, &integrity.Result{Files: 3, Added: 3}, result)
+	require.NoError(t, m.Save())
+
+	m, err = integrity.OpenManifest(manifestPath)
+	require.NoError(t, err)
+	require.Len(t, m.Entries, 3)
+	assert.Equal(t, int64(5), m.Entries["Go.md"].Size)
+
+	// An edit moves the modification time; corruption and truncation do not.
+	later := time.Now().Add(time.Hour)
+	writeFile(t, filepath.Join(vault, "Go.md"), "# Go\n\nEdited.\n")
+	require.NoError(t, os.Chtimes(filepath.Join(vault, "Go.md"), later, later))
+	rewrite(t, filepath.Join(vault, "zettel", "Rust.md"), "# Rvst\n")
+	rewrite(t, filepath.Join(vault, "assets", "img.png"), "")
+	result, err = integrity.Check(vault, m, opts)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Changed)
+	assert.Equal(t, []integrity.Issue{
+		{Path: "assets/img.png", Kind: integrity.Truncated, Detail: "was 3 bytes"},
+		{Path: "zettel/Rust.md", Kind: integrity.Modified, Detail: "content changed, modification time did not"},
+	}, result.Issues)
+
+	result, err = integrity.Check(vault, m, opts)
+	require.NoError(t, err)
+	assert.Len(t, result.Issues, 2, "problems are reported until accepted")
+
+	opts.Accept = true
+	_, err = integrity.Check(vault, m, opts)
+	require.NoError(t, err)
+	opts.Accept = false
+	require.NoError(t, os.Remove(filepath.Join(vault, "assets", "img.png")))
+	result, err = integrity.Check(vault, m, opts)
+	require.NoError(t, err)
+	assert.Equal(t, &integrity.Result{Files: 2, Removed: 1}, result)
+}
+
+func TestCheck_Encoding(t *testing.T) {
+	vault := t.TempDir()
+	writeFile(t, filepath.Join(vault, "Bom.md"), "\xEF\xBB\xBF# Bom\n")
+	writeFile(t, filepath.Join(vault, "Latin.md"), "# Caf\xe9 \x93quoted\x94\n")
+	writeFile(t, filepath.Join(vault, "Crash.md"), "# Crash\n\x00\x00\x00")
+	writeFile(t, filepath.Join(vault, "image.bin"), "\xEF\xBB\xBF\x00\xff")
+
+	m, err := integrity.OpenManifest(filepath.Join(t.TempDir(), integrity.File))
+	require.NoError(t, err)
+	result, err := integrity.Check(vault, m, integrity.Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []integrity.Issue{
+		{Path: "Bom.md", Kind: integrity.BOM},
+		{Path: "Crash.md", Kind: integrity.NUL, Detail: "at byte 8"},
+		{Path: "Latin.md", Kind: integrity.InvalidUTF8, Detail: "at byte 5"},
+	}, result.Issues, "only notes are checked for encoding problems")
+	for _, issue := range result.Issues {
+		assert.True(t, issue.Kind.Fixable())
+	}
+
+	result, err = integrity.Check(vault, m, integrity.Options{Fix: true})
+	require.NoError(t, err)
+	require.Len(t, result.Issues, 3)
+	assert.True(t, result.Issues[0].Fixed)
+	for name, want := range map[string]string{
+		"Bom.md":   "# Bom\n",
+		"Latin.md": "# Café “quoted”\n",
+		"Crash.md": "# Crash\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(vault, name))
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+
+	result, err = integrity.Check(vault, m, integrity.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, result.Issues, "fixed notes are recorded as fixed")
+}
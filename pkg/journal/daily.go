@@ -0,0 +1,187 @@
+package journal
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+)
+
+// dayOneRef matches the links of Day One entries to their media, such as
+// dayone-moment://<identifier> and dayone-moment:/video/<identifier>.
+var dayOneRef = regexp.MustCompile(`dayone-moment:/+(?:[a-z]+/)?([A-Za-z0-9-]+)`)
+
+// imageExtensions are the extensions of media linked as images.
+var imageExtensions = map[string]bool{".jpg": true, ".jpeg": true, ".png": true, ".gif": true, ".heic": true, ".webp": true}
+
+// Heading returns the heading of the section of e in its daily note: the time
+// of the entry, followed by its title if it has one.
+func (e Entry) Heading() string {
+	heading := "## " + e.Time.Format("15:04")
+	if e.Title != "" {
+		heading += " " + e.Title
+	}
+	return heading
+}
+
+// CopyMedia copies the media of entries to dir, the attachments directory of
+// their daily note, and returns the links to them, relative to the note, by
+// Ref. A file already in dir with the same contents is reused; other name
+// clashes are numbered. Media whose name is not a local file name are refused,
+// so that nothing is written outside dir.
+func CopyMedia(dir string, entries []Entry) (map[string]string, error) {
+	links := make(map[string]string)
+	for _, e := range entries {
+		for _, m := range e.Media {
+			if !localName(m.Name) {
+				return nil, fmt.Errorf("invalid media name %q", m.Name)
+			}
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return nil, fmt.Errorf("failed to create directory: %w", err)
+			}
+			ext := path.Ext(m.Name)
+			stem := strings.TrimSuffix(m.Name, ext)
+			name := m.Name
+			for i := 2; ; i++ {
+				existing, err := os.ReadFile(filepath.Join(dir, name))
+				if os.IsNotExist(err) {
+					if err := os.WriteFile(filepath.Join(dir, name), m.Data, 0644); err != nil {
+						return nil, fmt.Errorf("failed to write %s: %w", name, err)
+					}
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+				if bytes.Equal(existing, m.Data) {
+					break
+				}
+				name = fmt.Sprintf("%s %d%s", stem, i, ext)
+			}
+			links[m.Ref] = AttachmentsDir + "/" + url.PathEscape(name)
+		}
+	}
+	return links, nil
+}
+
+// Add appends entries, all of one day, to content, the content of their daily
+// note, each as a section under its Heading, and returns the new content and
+// how many entries were added. Entries whose heading the note already has are
+// skipped, so that importing a journal again does not repeat them. The
+// locations, weather and tags of the entries added are merged into the
+// frontmatter. links maps the Ref of media to their links, as returned by
+// CopyMedia; media the text does not refer to are linked at the end of their
+// entry.
+func Add(content string, entries []Entry, links map[string]string) (string, int, error) {
+	added := 0
+	var locations, weathers, tags []string
+	for _, e := range entries {
+		heading := e.Heading()
+		if md.HasHeading(content, heading) {
+			continue
+		}
+		content = md.Insert(content, heading, entryText(e, links), "")
+		added++
+		if e.Location != "" {
+			locations = append(locations, e.Location)
+		}
+		if e.Weather != "" {
+			weathers = append(weathers, e.Weather)
+		}
+		tags = append(tags, e.Tags...)
+	}
+	if len(locations)+len(weathers)+len(tags) == 0 {
+		return content, added, nil
+	}
+
+	meta, body, err := frontmatter.Parse(content)
+	if err != nil {
+		return "", 0, err
+	}
+	if meta == nil {
+		meta = make(map[string]interface{})
+	}
+	merge(meta, "location", locations, false)
+	merge(meta, "weather", weathers, false)
+	merge(meta, "tags", tags, true)
+	content, err = frontmatter.Render(meta, body)
+	if err != nil {
+		return "", 0, err
+	}
+	return content, added, nil
+}
+
+// entryText returns the text of e with the links to its media replaced by
+// links, and the media it does not refer to linked after it.
+func entryText(e Entry, links map[string]string) string {
+	text := dayOneRef.ReplaceAllStringFunc(e.Text, func(m string) string {
+		if link, ok := links[dayOneRef.FindStringSubmatch(m)[1]]; ok {
+			return link
+		}
+		return m
+	})
+	var unreferenced []string
+	for _, m := range e.Media {
+		link, ok := links[m.Ref]
+		if !ok {
+			continue
+		}
+		if target := "](" + m.Ref + ")"; strings.Contains(text, target) {
+			text = strings.ReplaceAll(text, target, "]("+link+")")
+			continue
+		}
+		if strings.Contains(text, link) {
+			continue
+		}
+		if imageExtensions[strings.ToLower(path.Ext(m.Name))] {
+			unreferenced = append(unreferenced, "![]("+link+")")
+		} else {
+			unreferenced = append(unreferenced, "["+m.Name+"]("+link+")")
+		}
+	}
+	if len(unreferenced) > 0 {
+		if text != "" {
+			text += "\n\n"
+		}
+		text += strings.Join(unreferenced, "\n")
+	}
+	return text
+}
+
+// merge adds values to the frontmatter field key, skipping values it has. A
+// field holding a single value is written as text unless list is set.
+func merge(meta map[string]interface{}, key string, values []string, list bool) {
+	var merged []string
+	if _, ok := meta[key].([]interface{}); ok || list {
+		merged = frontmatter.Strings(meta, key)
+	} else if s := frontmatter.String(meta, key); s != "" {
+		// Locations hold commas, so text is one value.
+		merged = append(merged, s)
+	}
+	changed := false
+	for _, v := range values {
+		if !contains(merged, v) {
+			merged = append(merged, v)
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if len(merged) == 1 && !list {
+		meta[key] = merged[0]
+		return
+	}
+	items := make([]interface{}, len(merged))
+	for i, v := range merged {
+		items[i] = v
+	}
+	meta[key] = items
+}
@@ -0,0 +1,353 @@
+// Package journal reads the entries of journal exports, from Day One or as
+// generic JSON, and adds them to daily notes.
+package journal
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AttachmentsDir is the directory, next to daily notes, the media of entries
+// are copied to.
+const AttachmentsDir = "attachments"
+
+// Entry is an entry of a journal.
+type Entry struct {
+	// Time is when the entry was written, in its time zone.
+	Time  time.Time
+	Title string
+	// Text is the Markdown text of the entry.
+	Text     string
+	Tags     []string
+	Location string
+	Weather  string
+	Media    []Media
+	// Skipped are the media left out for a name or path reaching outside
+	// the export, as a crafted export could give.
+	Skipped []string
+}
+
+// Media is a photo or other file of an entry.
+type Media struct {
+	// Ref is how the text of the entry refers to the file: its Day One
+	// identifier, or its path as given in generic JSON.
+	Ref  string
+	Name string
+	Data []byte
+}
+
+// Date returns the day of the entry, at midnight in the local time zone.
+func (e Entry) Date() time.Time {
+	y, m, d := e.Time.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// rawEntry holds the fields of an entry of Day One and of generic journal JSON.
+type rawEntry struct {
+	// Day One.
+	CreationDate   string        `json:"creationDate"`
+	TimeZone       string        `json:"timeZone"`
+	Photos         []dayOneMedia `json:"photos"`
+	Videos         []dayOneMedia `json:"videos"`
+	Audios         []dayOneMedia `json:"audios"`
+	PDFAttachments []dayOneMedia `json:"pdfAttachments"`
+
+	// Generic.
+	Date  string   `json:"date"`
+	Title string   `json:"title"`
+	Body  string   `json:"body"`
+	Media []string `json:"media"`
+
+	// Both.
+	Text     string          `json:"text"`
+	Tags     []string        `json:"tags"`
+	Location json.RawMessage `json:"location"`
+	Weather  json.RawMessage `json:"weather"`
+}
+
+// dayOneMedia is a file of a Day One entry, exported as <md5>.<type> in the
+// directory of its kind.
+type dayOneMedia struct {
+	Identifier string `json:"identifier"`
+	MD5        string `json:"md5"`
+	Type       string `json:"type"`
+}
+
+// openFunc returns the contents of the file at name, relative to the export.
+type openFunc func(name string) ([]byte, error)
+
+// Read reads the entries of the journal export at path: a Day One export
+// archive, holding a JSON file per journal and their media, or a JSON file,
+// from Day One or with generic entries, whose media paths are relative to it.
+// Entries are ordered by time.
+func Read(path string) ([]Entry, error) {
+	if strings.EqualFold(filepath.Ext(path), ".zip") {
+		return readZip(path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read journal: %w", err)
+	}
+	dir := filepath.Dir(path)
+	entries, err := Parse(data, func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func readZip(zipPath string) ([]Entry, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer r.Close()
+	files := make(map[string]*zip.File, len(r.File))
+	for _, f := range r.File {
+		files[path.Clean(f.Name)] = f
+	}
+	read := func(f *zip.File) ([]byte, error) {
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+
+	var entries []Entry
+	for _, f := range r.File {
+		if !strings.EqualFold(path.Ext(f.Name), ".json") || strings.HasPrefix(path.Base(f.Name), ".") {
+			continue
+		}
+		data, err := read(f)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", f.Name, err)
+		}
+		dir := path.Dir(path.Clean(f.Name))
+		parsed, err := Parse(data, func(name string) ([]byte, error) {
+			f, ok := files[path.Join(dir, name)]
+			if !ok {
+				return nil, os.ErrNotExist
+			}
+			return read(f)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Name, err)
+		}
+		entries = append(entries, parsed...)
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func sortEntries(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.Before(entries[j].Time) })
+}
+
+// Parse parses a journal in JSON: a Day One export, an object whose "entries"
+// have a "creationDate", or generic entries, given as a list or as the
+// "entries" of an object, each with a "date" and a "text" or "body". Media are
+// read with open, by path relative to the journal; missing Day One media are
+// left out, and so are media whose names are not local (see Entry.Skipped).
+func Parse(data []byte, open func(name string) ([]byte, error)) ([]Entry, error) {
+	var raw []rawEntry
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("invalid journal: %w", err)
+		}
+	} else {
+		var doc struct {
+			Entries []rawEntry `json:"entries"`
+		}
+		if err := json.Unmarshal(trimmed, &doc); err != nil {
+			return nil, fmt.Errorf("invalid journal: %w", err)
+		}
+		raw = doc.Entries
+	}
+
+	entries := make([]Entry, 0, len(raw))
+	for i, r := range raw {
+		e, err := r.entry(open)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// dateLayouts are the layouts of the dates of generic entries, read in the
+// local time zone unless they carry their own.
+var dateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+
+func (r rawEntry) entry(open openFunc) (Entry, error) {
+	e := Entry{Title: strings.TrimSpace(r.Title), Tags: r.Tags}
+	var err error
+	if r.CreationDate != "" {
+		if e.Time, err = time.Parse(time.RFC3339, r.CreationDate); err != nil {
+			return e, fmt.Errorf("invalid creationDate %q", r.CreationDate)
+		}
+		if loc, err := time.LoadLocation(r.TimeZone); r.TimeZone != "" && err == nil {
+			e.Time = e.Time.In(loc)
+		} else {
+			e.Time = e.Time.Local()
+		}
+		e.Text = unescapeDayOne(r.Text)
+		for dir, media := range map[string][]dayOneMedia{"photos": r.Photos, "videos": r.Videos, "audios": r.Audios, "pdfs": r.PDFAttachments} {
+			for _, m := range media {
+				name := m.MD5 + "." + m.Type
+				if !localName(name) {
+					e.Skipped = append(e.Skipped, name)
+					continue
+				}
+				data, err := open(path.Join(dir, name))
+				if errors.Is(err, os.ErrNotExist) {
+					continue
+				}
+				if err != nil {
+					return e, fmt.Errorf("failed to read %s: %w", name, err)
+				}
+				e.Media = append(e.Media, Media{Ref: m.Identifier, Name: name, Data: data})
+			}
+		}
+		sort.Slice(e.Media, func(i, j int) bool { return e.Media[i].Name < e.Media[j].Name })
+	} else {
+		if r.Date == "" {
+			return e, errors.New("missing date")
+		}
+		if e.Time, err = parseDate(r.Date); err != nil {
+			return e, err
+		}
+		e.Text = r.Text
+		if e.Text == "" {
+			e.Text = r.Body
+		}
+		for _, name := range r.Media {
+			if !filepath.IsLocal(filepath.FromSlash(name)) {
+				e.Skipped = append(e.Skipped, name)
+				continue
+			}
+			data, err := open(name)
+			if err != nil {
+				return e, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			e.Media = append(e.Media, Media{Ref: name, Name: path.Base(filepath.ToSlash(name)), Data: data})
+		}
+	}
+	e.Text = strings.TrimSpace(strings.ReplaceAll(e.Text, "\r\n", "\n"))
+	// A heading opening the text is the title of the entry.
+	if first, rest, _ := strings.Cut(e.Text, "\n"); e.Title == "" && strings.HasPrefix(first, "# ") {
+		e.Title = strings.TrimSpace(strings.TrimPrefix(first, "# "))
+		e.Text = strings.TrimSpace(rest)
+	}
+	if e.Location, err = location(r.Location); err != nil {
+		return e, err
+	}
+	if e.Weather, err = weather(r.Weather); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// localName reports whether name is a single element of a local path, the
+// name of a file that stays in the directory it is joined to.
+func localName(name string) bool {
+	return filepath.IsLocal(name) && name == filepath.Base(name) && !strings.ContainsAny(name, `/\`)
+}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+// dayOneEscape matches the punctuation Day One escapes with a backslash in the
+// Markdown of entries.
+var dayOneEscape = regexp.MustCompile(`\\([.\-!()+#*_\[\]{}])`)
+
+func unescapeDayOne(text string) string {
+	return dayOneEscape.ReplaceAllString(text, "$1")
+}
+
+// location returns a location, given as text or as an object naming the place,
+// as text, e.g. "Tiergarten, Berlin, Germany".
+func location(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.TrimSpace(s), nil
+	}
+	var loc struct {
+		Name               string `json:"name"`
+		PlaceName          string `json:"placeName"`
+		LocalityName       string `json:"localityName"`
+		AdministrativeArea string `json:"administrativeArea"`
+		Country            string `json:"country"`
+	}
+	if err := json.Unmarshal(raw, &loc); err != nil {
+		return "", fmt.Errorf("invalid location: %w", err)
+	}
+	var parts []string
+	for _, p := range []string{loc.Name, loc.PlaceName, loc.LocalityName, loc.AdministrativeArea, loc.Country} {
+		if p = strings.TrimSpace(p); p != "" && !contains(parts, p) {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// weather returns the weather, given as text or as the Day One object, as
+// text, e.g. "Partly Cloudy, 18°C".
+func weather(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return strings.TrimSpace(s), nil
+	}
+	var w struct {
+		ConditionsDescription string   `json:"conditionsDescription"`
+		TemperatureCelsius    *float64 `json:"temperatureCelsius"`
+	}
+	if err := json.Unmarshal(raw, &w); err != nil {
+		return "", fmt.Errorf("invalid weather: %w", err)
+	}
+	var parts []string
+	if w.ConditionsDescription != "" {
+		parts = append(parts, w.ConditionsDescription)
+	}
+	if w.TemperatureCelsius != nil {
+		parts = append(parts, fmt.Sprintf("%.0f°C", *w.TemperatureCelsius))
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
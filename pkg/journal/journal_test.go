@@ -0,0 +1,192 @@
+package journal_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/journal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dayOneJSON = `{
+  "metadata": {"version": "1.0"},
+  "entries": [
+    {
+      "uuid": "B",
+      "creationDate": "2024-03-01T18:30:00Z",
+      "timeZone": "Europe/Berlin",
+      "text": "Dinner at home\\. ![](dayone-moment://P1)",
+      "photos": [{"identifier": "P1", "md5": "abc", "type": "jpeg"}],
+      "tags": ["family"],
+      "weather": {"conditionsDescription": "Clear", "temperatureCelsius": 7.6}
+    },
+    {
+      "uuid": "A",
+      "creationDate": "2024-03-01T07:15:00Z",
+      "timeZone": "Europe/Berlin",
+      "text": "# Morning\n\nA walk in the park.",
+      "location": {"placeName": "Tiergarten", "localityName": "Berlin", "country": "Germany"}
+    }
+  ]
+}`
+
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "export.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+	return path
+}
+
+func TestRead_DayOne(t *testing.T) {
+	entries, err := journal.Read(writeZip(t, map[string]string{
+		"Journal.json":    dayOneJSON,
+		"photos/abc.jpeg": "jpeg",
+	}))
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	morning := entries[0]
+	assert.Equal(t, "08:15", morning.Time.Format("15:04"))
+	assert.Equal(t, "Morning", morning.Title)
+	assert.Equal(t, "A walk in the park.", morning.Text)
+	assert.Equal(t, "Tiergarten, Berlin, Germany", morning.Location)
+	assert.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.Local), morning.Date())
+
+	dinner := entries[1]
+	assert.Equal(t, "19:30", dinner.Time.Format("15:04"))
+	assert.Equal(t, "Dinner at home. ![](dayone-moment://P1)", dinner.Text)
+	assert.Equal(t, "Clear, 8°C", dinner.Weather)
+	assert.Equal(t, []string{"family"}, dinner.Tags)
+	require.Len(t, dinner.Media, 1)
+	assert.Equal(t, journal.Media{Ref: "P1", Name: "abc.jpeg", Data: []byte("jpeg")}, dinner.Media[0])
+}
+
+func TestRead_Generic(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cat.png"), []byte("png"), 0644))
+	path := filepath.Join(dir, "journal.json")
+	require.NoError(t, os.WriteFile(path, []byte(`[
+  {"date": "2024-03-02 21:00", "title": "Night", "body": "Quiet.", "location": "Home", "weather": "Rain", "media": ["cat.png"]},
+  {"date": "2024-03-02", "text": "Started the day."}
+]`), 0644))
+
+	entries, err := journal.Read(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Started the day.", entries[0].Text)
+	assert.Equal(t, journal.Entry{
+		Time:     time.Date(2024, 3, 2, 21, 0, 0, 0, time.Local),
+		Title:    "Night",
+		Text:     "Quiet.",
+		Location: "Home",
+		Weather:  "Rain",
+		Media:    []journal.Media{{Ref: "cat.png", Name: "cat.png", Data: []byte("png")}},
+	}, entries[1])
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := journal.Parse([]byte(`[{"text": "no date"}]`), nil)
+	assert.Error(t, err)
+	_, err = journal.Parse([]byte(`[{"date": "yesterday"}]`), nil)
+	assert.Error(t, err)
+	_, err = journal.Parse([]byte(`{`), nil)
+	assert.Error(t, err)
+}
+
+func TestAdd(t *testing.T) {
+	entries, err := journal.Parse([]byte(dayOneJSON), func(name string) ([]byte, error) { return []byte("jpeg"), nil })
+	require.NoError(t, err)
+	dir := filepath.Join(t.TempDir(), journal.AttachmentsDir)
+	links, err := journal.CopyMedia(dir, entries)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"P1": "attachments/abc.jpeg"}, links)
+
+	content, added, err := journal.Add("---\ntags: [daily]\n---\n# 2024-03-01\n", entries, links)
+	require.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Equal(t, "---\nlocation: Tiergarten, Berlin, Germany\ntags:\n  - daily\n  - family\nweather: Clear, 8°C\n---\n"+
+		"# 2024-03-01\n\n"+
+		"## 19:30\n\nDinner at home. ![](attachments/abc.jpeg)\n\n"+
+		"## 08:15 Morning\n\nA walk in the park.\n", content)
+
+	again, added, err := journal.Add(content, entries, links)
+	require.NoError(t, err)
+	assert.Equal(t, 0, added)
+	assert.Equal(t, content, again)
+}
+
+func TestAdd_UnreferencedMedia(t *testing.T) {
+	entry := journal.Entry{
+		Time:  time.Date(2024, 3, 2, 9, 0, 0, 0, time.Local),
+		Text:  "See attached.",
+		Media: []journal.Media{{Ref: "scan.pdf", Name: "scan.pdf"}, {Ref: "cat.png", Name: "cat.png"}},
+	}
+	content, _, err := journal.Add("# 2024-03-02\n", []journal.Entry{entry},
+		map[string]string{"scan.pdf": "attachments/scan.pdf", "cat.png": "attachments/cat.png"})
+	require.NoError(t, err)
+	assert.Equal(t, "# 2024-03-02\n\n## 09:00\n\nSee attached.\n\n[scan.pdf](attachments/scan.pdf)\n![](attachments/cat.png)\n", content)
+}
+
+func TestCopyMedia_Clash(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cat.png"), []byte("other"), 0644))
+	links, err := journal.CopyMedia(dir, []journal.Entry{{Media: []journal.Media{{Ref: "cat.png", Name: "cat.png", Data: []byte("png")}}}})
+	require.NoError(t, err)
+	assert.Equal(t, "attachments/cat%202.png", links["cat.png"])
+	data, err := os.ReadFile(filepath.Join(dir, "cat 2.png"))
+	require.NoError(t, err)
+	assert.Equal(t, "png", string(data))
+}
+
+func TestRead_MaliciousMedia(t *testing.T) {
+	archive := writeZip(t, map[string]string{
+		"Journal.json": `{"entries": [{
+  "creationDate": "2024-03-01T18:30:00Z",
+  "text": "Crafted",
+  "photos": [
+    {"identifier": "P1", "md5": "../../evil", "type": "txt"},
+    {"identifier": "P2", "md5": "abc", "type": "jpeg"}
+  ]
+}]}`,
+		"photos/../../evil.txt": "evil",
+		"photos/abc.jpeg":       "jpeg",
+	})
+	entries, err := journal.Read(archive)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []string{"../../evil.txt"}, entries[0].Skipped)
+	require.Len(t, entries[0].Media, 1)
+	assert.Equal(t, "abc.jpeg", entries[0].Media[0].Name)
+
+	root := t.TempDir()
+	dir := filepath.Join(root, "daily", journal.AttachmentsDir)
+	_, err = journal.CopyMedia(dir, entries)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(root, "evil.txt"))
+	assert.FileExists(t, filepath.Join(dir, "abc.jpeg"))
+
+	_, err = journal.CopyMedia(dir, []journal.Entry{{Media: []journal.Media{{Ref: "x", Name: "../evil.txt", Data: []byte("evil")}}}})
+	assert.Error(t, err)
+	assert.NoFileExists(t, filepath.Join(root, "daily", "evil.txt"))
+
+	generic := filepath.Join(t.TempDir(), "journal.json")
+	require.NoError(t, os.WriteFile(generic, []byte(`[{"date": "2024-03-02", "text": "Hi", "media": ["../secret.txt", "/etc/passwd"]}]`), 0644))
+	entries, err = journal.Read(generic)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"../secret.txt", "/etc/passwd"}, entries[0].Skipped)
+	assert.Empty(t, entries[0].Media)
+}
@@ -0,0 +1,112 @@
+// Package lint checks notes against rules, such as required frontmatter fields
+// or a consistent heading hierarchy, and reports the issues found.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Note is a note as checked by rules.
+type Note struct {
+	scan.Note
+	// Type is the type of the note, e.g. "zettel": its frontmatter type,
+	// else the type of the directory it is in or its file name tells.
+	Type string
+	// Content is the text of the note file, frontmatter included.
+	Content string
+}
+
+// Issue is a problem found in a note.
+type Issue struct {
+	Path string `json:"path"`
+	// Line is the line of the file the issue is on, counting from 1, or 0 when
+	// it concerns the whole note.
+	Line    int    `json:"line,omitempty"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+	// Type is the type of the note, as in Note.Type.
+	Type string `json:"type,omitempty"`
+}
+
+// String formats the issue as "path:line: message (rule)".
+func (i Issue) String() string {
+	location := i.Path
+	if i.Line > 0 {
+		location += fmt.Sprintf(":%d", i.Line)
+	}
+	return fmt.Sprintf("%s: %s (%s)", location, i.Message, i.Rule)
+}
+
+// Rule checks notes for one kind of problem. Rules are independent of each
+// other and of the order notes are checked in.
+type Rule interface {
+	// Name identifies the rule, e.g. "long-note".
+	Name() string
+	// Description says what the rule reports, in a line.
+	Description() string
+	// Check returns the issues found in n. Only their Line and Message need
+	// to be set; Run fills in the rest.
+	Check(n Note) ([]Issue, error)
+}
+
+// Run checks every note against every rule and returns the issues found,
+// ordered by path and line. A rule failing on a note is reported as an
+// issue of that rule.
+func Run(notes []Note, rules []Rule) []Issue {
+	var issues []Issue
+	for _, n := range notes {
+		for _, r := range rules {
+			found, err := r.Check(n)
+			if err != nil {
+				found = []Issue{{Message: "rule failed: " + err.Error()}}
+			}
+			for _, issue := range found {
+				issue.Path, issue.Rule, issue.Type = n.Path, r.Name(), n.Type
+				issues = append(issues, issue)
+			}
+		}
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Path != issues[j].Path {
+			return issues[i].Path < issues[j].Path
+		}
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
+
+// Select returns the rules named in enabled or, when enabled is empty, all
+// of them but the ones named in disabled. Names are matched
+// case-insensitively; an unknown name in enabled is an error.
+func Select(rules []Rule, enabled, disabled []string) ([]Rule, error) {
+	if len(enabled) == 0 {
+		skip := make(map[string]bool, len(disabled))
+		for _, name := range disabled {
+			skip[strings.ToLower(name)] = true
+		}
+		var out []Rule
+		for _, r := range rules {
+			if !skip[strings.ToLower(r.Name())] {
+				out = append(out, r)
+			}
+		}
+		return out, nil
+	}
+	byName := make(map[string]Rule, len(rules))
+	for _, r := range rules {
+		byName[strings.ToLower(r.Name())] = r
+	}
+	out := make([]Rule, 0, len(enabled))
+	for _, name := range enabled {
+		r, ok := byName[strings.ToLower(name)]
+		if !ok {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
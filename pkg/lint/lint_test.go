@@ -0,0 +1,129 @@
+package lint_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/lint"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func note(path, typ, content string) lint.Note {
+	n := scan.ParseNote(path, content, now)
+	return lint.Note{Note: n, Type: typ, Content: content}
+}
+
+func check(t *testing.T, r lint.Rule, n lint.Note) []lint.Issue {
+	t.Helper()
+	issues, err := r.Check(n)
+	require.NoError(t, err)
+	return issues
+}
+
+func TestRequiredFields(t *testing.T) {
+	r := lint.RequiredFields{Fields: map[string][]string{"*": {"created"}, "book": {"isbn", "created"}}}
+	book := note("/v/literature/book.md", "book", "---\ncreated: 2025-01-01\nisbn: \"\"\n---\n# Book\n")
+	assert.Equal(t, []lint.Issue{{Line: 1, Message: `missing frontmatter field "isbn"`}}, check(t, r, book))
+
+	bare := note("/v/zettel/x.md", "zettel", "# X\n")
+	assert.Equal(t, []lint.Issue{{Message: `missing frontmatter field "created"`}}, check(t, r, bare))
+	assert.Empty(t, check(t, lint.RequiredFields{}, bare))
+}
+
+func TestSchema(t *testing.T) {
+	r := lint.Schema{Schemas: map[string]frontmatter.Schema{"book": {"isbn": {Required: true}, "pages": {Type: frontmatter.NumberType}}}}
+	book := note("/v/literature/book.md", "Book", "---\npages: many\n---\n# Book\n")
+	assert.Equal(t, []lint.Issue{
+		{Line: 1, Message: `missing required field "isbn"`},
+		{Line: 1, Message: `field "pages" is not a number`},
+	}, check(t, r, book))
+	assert.Empty(t, check(t, r, note("/v/zettel/x.md", "zettel", "# X\n")))
+}
+
+func TestHeadingHierarchy(t *testing.T) {
+	n := note("/v/x.md", "", "---\ntags: [a]\n---\n# X\n\n### Too deep\n\n```\n# not a heading\n```\n## Fine\n#tag\n#### Deep again\n# Second title\n")
+	assert.Equal(t, []lint.Issue{
+		{Line: 6, Message: "heading level jumps from 1 to 3"},
+		{Line: 13, Message: "heading level jumps from 2 to 4"},
+		{Line: 14, Message: "more than one level-one heading"},
+	}, check(t, lint.HeadingHierarchy{}, n))
+
+	assert.Empty(t, check(t, lint.HeadingHierarchy{}, note("/v/y.md", "", "## Start low\n### Then\n## Back\n")))
+}
+
+func TestUntaggedZettel(t *testing.T) {
+	r := lint.UntaggedZettel{Types: []string{"zettel"}}
+	assert.Equal(t, []lint.Issue{{Message: "zettel note has no tags"}}, check(t, r, note("/v/zettel/x.md", "zettel", "# X\n")))
+	assert.Empty(t, check(t, r, note("/v/zettel/y.md", "zettel", "# Y\n\n#go\n")))
+	assert.Empty(t, check(t, r, note("/v/day/z.md", "daily", "# Z\n")))
+}
+
+func TestLongNote(t *testing.T) {
+	n := note("/v/x.md", "", "# X\n\none two three four five\n")
+	assert.Equal(t, []lint.Issue{{Message: "note has 7 words, more than 3"}}, check(t, lint.LongNote{MaxWords: 3}, n))
+	assert.Empty(t, check(t, lint.LongNote{MaxWords: 7}, n))
+	assert.Empty(t, check(t, lint.LongNote{}, n))
+}
+
+func TestStaleTodo(t *testing.T) {
+	content := "---\ncreated: 2025-01-01\n---\n# Plan\n\n- [ ] old task\n- [ ] fresh task 2025-03-10\n- [x] done task\nTODO: ask about 2024-12-01\n```\n- [ ] in code\n```\n"
+	n := note("/v/x.md", "", content)
+	r := lint.StaleTodo{Days: 30, Now: now}
+	assert.Equal(t, []lint.Issue{
+		{Line: 6, Message: "open TODO from 2025-01-01, 73 days old"},
+		{Line: 9, Message: "open TODO from 2024-12-01, 104 days old"},
+	}, check(t, r, n))
+	assert.Empty(t, check(t, lint.StaleTodo{Days: 0, Now: now}, n))
+}
+
+// failing is a rule that cannot check notes.
+type failing struct{}
+
+func (failing) Name() string                          { return "failing" }
+func (failing) Description() string                   { return "Always fails" }
+func (failing) Check(lint.Note) ([]lint.Issue, error) { return nil, errors.New("boom") }
+
+func TestRun(t *testing.T) {
+	notes := []lint.Note{
+		note("/v/b.md", "zettel", "# B\n\n### Deep\n"),
+		note("/v/a.md", "zettel", "# A\n"),
+	}
+	issues := lint.Run(notes, []lint.Rule{lint.HeadingHierarchy{}, lint.UntaggedZettel{Types: []string{"zettel"}}, failing{}})
+	assert.Equal(t, []lint.Issue{
+		{Path: "/v/a.md", Rule: "untagged-zettel", Message: "zettel note has no tags", Type: "zettel"},
+		{Path: "/v/a.md", Rule: "failing", Message: "rule failed: boom", Type: "zettel"},
+		{Path: "/v/b.md", Rule: "untagged-zettel", Message: "zettel note has no tags", Type: "zettel"},
+		{Path: "/v/b.md", Rule: "failing", Message: "rule failed: boom", Type: "zettel"},
+		{Path: "/v/b.md", Line: 3, Rule: "heading-hierarchy", Message: "heading level jumps from 1 to 3", Type: "zettel"},
+	}, issues)
+	assert.Equal(t, "/v/b.md:3: heading level jumps from 1 to 3 (heading-hierarchy)", issues[4].String())
+	assert.Equal(t, "/v/a.md: zettel note has no tags (untagged-zettel)", issues[0].String())
+}
+
+func TestSelect(t *testing.T) {
+	rules := []lint.Rule{lint.HeadingHierarchy{}, lint.LongNote{}, lint.StaleTodo{}}
+	names := func(rules []lint.Rule) []string {
+		var out []string
+		for _, r := range rules {
+			out = append(out, r.Name())
+		}
+		return out
+	}
+
+	selected, err := lint.Select(rules, nil, []string{"Long-Note"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"heading-hierarchy", "stale-todo"}, names(selected))
+
+	selected, err = lint.Select(rules, []string{"long-note"}, []string{"long-note"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"long-note"}, names(selected))
+
+	_, err = lint.Select(rules, []string{"spelling"}, nil)
+	assert.ErrorContains(t, err, `unknown rule "spelling"`)
+}
@@ -0,0 +1,49 @@
+package lint
+
+import (
+	"context"
+
+	"github.com/a-kostevski/exo/pkg/plugin"
+)
+
+// PluginRule is a rule provided by a plugin, which checks each note in a
+// call to the plugin executable.
+type PluginRule struct {
+	Plugin plugin.Plugin
+	Rule   plugin.LintRule
+}
+
+// PluginRules returns the lint rules of plugins.
+func PluginRules(plugins []plugin.Plugin) []Rule {
+	var rules []Rule
+	for _, p := range plugins {
+		for _, r := range p.LintRules {
+			rules = append(rules, PluginRule{Plugin: p, Rule: r})
+		}
+	}
+	return rules
+}
+
+// Name implements Rule.
+func (r PluginRule) Name() string { return r.Rule.Name }
+
+// Description implements Rule.
+func (r PluginRule) Description() string {
+	if r.Rule.Description != "" {
+		return r.Rule.Description
+	}
+	return "Provided by the " + r.Plugin.Name + " plugin"
+}
+
+// Check implements Rule.
+func (r PluginRule) Check(n Note) ([]Issue, error) {
+	found, err := r.Plugin.Lint(context.Background(), r.Rule.Name, n.Path)
+	if err != nil {
+		return nil, err
+	}
+	issues := make([]Issue, len(found))
+	for i, f := range found {
+		issues[i] = Issue{Line: f.Line, Message: f.Message}
+	}
+	return issues, nil
+}
@@ -0,0 +1,234 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+)
+
+// RequiredFields reports notes lacking frontmatter fields.
+type RequiredFields struct {
+	// Fields maps a note type to the fields notes of that type must have;
+	// the fields under "*" are required of every note.
+	Fields map[string][]string
+}
+
+// Name implements Rule.
+func (RequiredFields) Name() string { return "frontmatter" }
+
+// Description implements Rule.
+func (RequiredFields) Description() string { return "Notes lacking required frontmatter fields" }
+
+// Check implements Rule.
+func (r RequiredFields) Check(n Note) ([]Issue, error) {
+	fields := append(append([]string(nil), r.Fields["*"]...), r.Fields[strings.ToLower(n.Type)]...)
+	line := frontmatterLine(n.Content)
+	var issues []Issue
+	seen := make(map[string]bool)
+	for _, field := range fields {
+		if seen[field] || hasField(n.Meta, field) {
+			continue
+		}
+		seen[field] = true
+		issues = append(issues, Issue{Line: line, Message: fmt.Sprintf("missing frontmatter field %q", field)})
+	}
+	return issues, nil
+}
+
+// hasField reports whether meta has a non-empty value for key.
+func hasField(meta map[string]interface{}, key string) bool {
+	switch v := meta[key].(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// frontmatterLine returns the line issues with the frontmatter of content are
+// reported on: its first line, or 0 when there is no frontmatter.
+func frontmatterLine(content string) int {
+	if raw, _ := frontmatter.Split(content); raw != "" {
+		return 1
+	}
+	return 0
+}
+
+// Schema reports notes whose frontmatter does not match the schema of their
+// type: required fields missing and values of the wrong type.
+type Schema struct {
+	// Schemas maps a note type to its schema.
+	Schemas map[string]frontmatter.Schema
+}
+
+// Name implements Rule.
+func (Schema) Name() string { return "schema" }
+
+// Description implements Rule.
+func (Schema) Description() string { return "Frontmatter not matching the schema of the note type" }
+
+// Check implements Rule.
+func (r Schema) Check(n Note) ([]Issue, error) {
+	schema := r.Schemas[strings.ToLower(n.Type)]
+	if len(schema) == 0 {
+		return nil, nil
+	}
+	line := frontmatterLine(n.Content)
+	var issues []Issue
+	for _, problem := range schema.Check(n.Meta) {
+		issues = append(issues, Issue{Line: line, Message: problem})
+	}
+	return issues, nil
+}
+
+// HeadingHierarchy reports headings skipping a level, such as a level-four
+// heading under a level-two one, and notes with several level-one headings.
+type HeadingHierarchy struct{}
+
+// Name implements Rule.
+func (HeadingHierarchy) Name() string { return "heading-hierarchy" }
+
+// Description implements Rule.
+func (HeadingHierarchy) Description() string {
+	return "Headings skipping a level, or several level-one headings"
+}
+
+// Check implements Rule.
+func (HeadingHierarchy) Check(n Note) ([]Issue, error) {
+	var issues []Issue
+	prev, titles := 0, 0
+	for _, s := range md.Parse(n.Content).Sections() {
+		level, number := s.Level, s.Line+1
+		if level == 1 {
+			if titles++; titles == 2 {
+				issues = append(issues, Issue{Line: number, Message: "more than one level-one heading"})
+			}
+		}
+		if prev > 0 && level > prev+1 {
+			issues = append(issues, Issue{Line: number, Message: fmt.Sprintf("heading level jumps from %d to %d", prev, level)})
+		}
+		prev = level
+	}
+	return issues, nil
+}
+
+// UntaggedZettel reports notes of the given types without any tag.
+type UntaggedZettel struct {
+	// Types are the note types that must be tagged, e.g. "zettel".
+	Types []string
+}
+
+// Name implements Rule.
+func (UntaggedZettel) Name() string { return "untagged-zettel" }
+
+// Description implements Rule.
+func (UntaggedZettel) Description() string { return "Zettels without tags" }
+
+// Check implements Rule.
+func (r UntaggedZettel) Check(n Note) ([]Issue, error) {
+	if len(n.Tags) > 0 {
+		return nil, nil
+	}
+	for _, t := range r.Types {
+		if strings.EqualFold(t, n.Type) {
+			return []Issue{{Message: n.Type + " note has no tags"}}, nil
+		}
+	}
+	return nil, nil
+}
+
+// LongNote reports notes of more than MaxWords words, which probably hold
+// more than one idea.
+type LongNote struct {
+	// MaxWords is the number of words above which a note is too long; zero
+	// disables the rule.
+	MaxWords int
+}
+
+// Name implements Rule.
+func (LongNote) Name() string { return "long-note" }
+
+// Description implements Rule.
+func (LongNote) Description() string { return "Notes longer than the word limit" }
+
+// Check implements Rule.
+func (r LongNote) Check(n Note) ([]Issue, error) {
+	if r.MaxWords <= 0 || n.Words <= r.MaxWords {
+		return nil, nil
+	}
+	return []Issue{{Message: fmt.Sprintf("note has %d words, more than %d", n.Words, r.MaxWords)}}, nil
+}
+
+var (
+	openTask   = regexp.MustCompile(`^\s*[-*+] \[ \]\s`)
+	todoWord   = regexp.MustCompile(`\bTODO\b`)
+	dateOnLine = regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}\b`)
+)
+
+// StaleTodo reports open TODO items older than Days days: unchecked tasks and
+// lines with "TODO". An item dates from the first date (YYYY-MM-DD) written
+// on its line or, failing that, from the creation of the note.
+type StaleTodo struct {
+	// Days is the age above which items are reported; zero disables the rule.
+	Days int
+	// Now is the time ages are measured at.
+	Now time.Time
+}
+
+// Name implements Rule.
+func (StaleTodo) Name() string { return "stale-todo" }
+
+// Description implements Rule.
+func (StaleTodo) Description() string { return "Open TODO items older than the age limit" }
+
+// Check implements Rule.
+func (r StaleTodo) Check(n Note) ([]Issue, error) {
+	if r.Days <= 0 {
+		return nil, nil
+	}
+	limit := r.Now.AddDate(0, 0, -r.Days)
+	var issues []Issue
+	bodyLines(n.Content, func(line string, number int) {
+		if !openTask.MatchString(line) && !todoWord.MatchString(line) {
+			return
+		}
+		since := n.Created
+		if date := dateOnLine.FindString(line); date != "" {
+			if t, err := time.ParseInLocation("2006-01-02", date, r.Now.Location()); err == nil {
+				since = t
+			}
+		}
+		if since.IsZero() || !since.Before(limit) {
+			return
+		}
+		days := int(r.Now.Sub(since).Hours() / 24)
+		issues = append(issues, Issue{Line: number, Message: fmt.Sprintf("open TODO from %s, %d days old", since.Format("2006-01-02"), days)})
+	})
+	return issues, nil
+}
+
+// bodyLines calls fn with every line of the body of content outside fenced
+// code blocks, and its line number in the file.
+func bodyLines(content string, fn func(line string, number int)) {
+	_, body := frontmatter.Split(content)
+	number := strings.Count(content[:len(content)-len(body)], "\n")
+	fenced := false
+	for _, line := range strings.Split(body, "\n") {
+		number++
+		line = strings.TrimRight(line, "\r")
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			fenced = !fenced
+			continue
+		}
+		if !fenced {
+			fn(line, number)
+		}
+	}
+}
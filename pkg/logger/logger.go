@@ -33,14 +33,15 @@ func NewLogger() Logger {
 	return &simpleLogger{}
 }
 
-// Info logs an informational message to stdout.
+// Info logs an informational message to stderr, leaving stdout to the output
+// of commands, such as the paths scripts capture.
 func (l *simpleLogger) Info(msg string, fields ...Field) {
 	timestamp := time.Now().Format(time.RFC3339)
 	line := fmt.Sprintf("[INFO] %s - %s", timestamp, msg)
 	if len(fields) > 0 {
 		line += " " + formatFields(fields)
 	}
-	fmt.Fprintln(os.Stdout, line)
+	fmt.Fprintln(os.Stderr, line)
 }
 
 // Error logs an error message to stderr.
@@ -54,7 +54,7 @@ func captureOutput(output *os.File, f func()) string {
 
 func TestInfo(t *testing.T) {
 	log := logger.NewLogger()
-	output := captureOutput(os.Stdout, func() {
+	output := captureOutput(os.Stderr, func() {
 		log.Info("Test info", logger.Field{Key: "user", Value: "alice"})
 	})
 
@@ -66,7 +66,7 @@ func TestInfo(t *testing.T) {
 
 func TestInfof(t *testing.T) {
 	log := logger.NewLogger()
-	output := captureOutput(os.Stdout, func() {
+	output := captureOutput(os.Stderr, func() {
 		log.Infof("Infof: number %d", 42)
 	})
 
@@ -102,7 +102,7 @@ func TestErrorf(t *testing.T) {
 func TestTimestampFormat(t *testing.T) {
 	log := logger.NewLogger()
 
-	output := captureOutput(os.Stdout, func() {
+	output := captureOutput(os.Stderr, func() {
 		log.Info("Timestamp test")
 	})
 	// Extract the timestamp between "[INFO] " and " - "
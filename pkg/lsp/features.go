@@ -0,0 +1,318 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/a-kostevski/exo/pkg/lint"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+)
+
+// Kinds of completion items and severities of diagnostics.
+const (
+	kindKeyword     = 14
+	kindFile        = 17
+	severityWarning = 2
+)
+
+// tagPrefix matches a tag being typed at the end of a line.
+var tagPrefix = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]*)$`)
+
+// Position is a position in a document: a line and a character offset in
+// UTF-16 code units, both from 0.
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is the text between two positions, the end excluded.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document.
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// TextEdit replaces a range of a document with new text.
+type TextEdit struct {
+	Range   Range  `json:"range"`
+	NewText string `json:"newText"`
+}
+
+// CompletionItem is a completion proposed to the client.
+type CompletionItem struct {
+	Label    string    `json:"label"`
+	Kind     int       `json:"kind"`
+	Detail   string    `json:"detail,omitempty"`
+	TextEdit *TextEdit `json:"textEdit,omitempty"`
+}
+
+// Diagnostic is an issue found in a document.
+type Diagnostic struct {
+	Range    Range  `json:"range"`
+	Severity int    `json:"severity"`
+	Code     string `json:"code"`
+	Source   string `json:"source"`
+	Message  string `json:"message"`
+}
+
+// text returns the text of the document at path: the one open in the client or,
+// failing that, the file.
+func (s *Server) text(path string) (string, error) {
+	if text, ok := s.docs[path]; ok {
+		return text, nil
+	}
+	data, err := os.ReadFile(path)
+	return string(data), err
+}
+
+// line returns line n of the document at path, without its line break.
+func (s *Server) line(path string, n int) (string, error) {
+	text, err := s.text(path)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(text, "\n")
+	if n < 0 || n >= len(lines) {
+		return "", nil
+	}
+	return strings.TrimSuffix(lines[n], "\r"), nil
+}
+
+// completion proposes note names after "[[" and tags after "#".
+func (s *Server) completion(path string, pos Position) ([]CompletionItem, error) {
+	line, err := s.line(path, pos.Line)
+	if err != nil {
+		return nil, err
+	}
+	cursor := byteOffset(line, pos.Character)
+	before := line[:cursor]
+	items := []CompletionItem{}
+
+	if i := strings.LastIndex(before, "[["); i >= 0 && !strings.ContainsAny(before[i:], "]|#") {
+		notes, err := s.ix.Notes(store.Query{})
+		if err != nil {
+			return nil, err
+		}
+		edit := Range{Position{pos.Line, character(line, i+2)}, pos}
+		closing := "]]"
+		if strings.HasPrefix(line[cursor:], "]]") {
+			closing = ""
+		}
+		for _, n := range notes {
+			if n.Path == path {
+				continue
+			}
+			name := strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension)
+			detail := n.Title
+			if rel, err := filepath.Rel(s.opts.DataHome, n.Path); err == nil {
+				detail += " (" + filepath.ToSlash(rel) + ")"
+			}
+			items = append(items, CompletionItem{
+				Label:    name,
+				Kind:     kindFile,
+				Detail:   detail,
+				TextEdit: &TextEdit{Range: edit, NewText: name + closing},
+			})
+		}
+		return items, nil
+	}
+
+	if m := tagPrefix.FindStringSubmatchIndex(before); m != nil {
+		tags, err := s.ix.Tags()
+		if err != nil {
+			return nil, err
+		}
+		edit := Range{Position{pos.Line, character(line, m[2])}, pos}
+		for _, tag := range tags {
+			items = append(items, CompletionItem{
+				Label:    tag,
+				Kind:     kindKeyword,
+				TextEdit: &TextEdit{Range: edit, NewText: tag},
+			})
+		}
+	}
+	return items, nil
+}
+
+// definition returns the notes the wikilink at pos links to, at the linked
+// heading if any.
+func (s *Server) definition(path string, pos Position) ([]Location, error) {
+	text, err := s.text(path)
+	if err != nil {
+		return nil, err
+	}
+	line, _ := s.line(path, pos.Line)
+	cursor := byteOffset(line, pos.Character)
+	locations := []Location{}
+	for _, l := range wikilinks(text) {
+		if l.line != pos.Line || cursor < l.start || cursor > l.end {
+			continue
+		}
+		for _, n := range s.resolve(l.Target) {
+			target := Position{}
+			if l.Anchor != "" {
+				target.Line = s.headingLine(n.Path, l.Anchor)
+			}
+			locations = append(locations, Location{URI: pathURI(n.Path), Range: Range{target, target}})
+		}
+	}
+	return locations, nil
+}
+
+// headingLine returns the line of the heading titled heading in the note at
+// path, or 0 if there is none.
+func (s *Server) headingLine(path, heading string) int {
+	text, err := s.text(path)
+	if err != nil {
+		return 0
+	}
+	for i, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "#") && strings.EqualFold(strings.TrimSpace(strings.TrimLeft(line, "#")), strings.TrimSpace(heading)) {
+			return i
+		}
+	}
+	return 0
+}
+
+// references returns the links to the note at path, from the notes linking to
+// it in the index.
+func (s *Server) references(path string) ([]Location, error) {
+	notes, err := s.ix.Backlinks(path)
+	if err != nil {
+		return nil, err
+	}
+	locations := []Location{}
+	for _, n := range notes {
+		text, err := s.text(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		lines := strings.Split(text, "\n")
+		for _, l := range wikilinks(text) {
+			if !linksTo(s.resolve(l.Target), path) {
+				continue
+			}
+			found = true
+			line := lines[l.line]
+			locations = append(locations, Location{
+				URI:   pathURI(n.Path),
+				Range: Range{Position{l.line, character(line, l.start)}, Position{l.line, character(line, l.end)}},
+			})
+		}
+		if !found {
+			locations = append(locations, Location{URI: pathURI(n.Path)})
+		}
+	}
+	return locations, nil
+}
+
+// lineLink is a wikilink of a document, on line line from its byte offset
+// start to end.
+type lineLink struct {
+	md.Link
+	line, start, end int
+}
+
+// wikilinks returns the wikilinks of text, leaving out those in code as
+// md.Links does, with the line they are on.
+func wikilinks(text string) []lineLink {
+	var links []lineLink
+	line, lineStart := 0, 0
+	for _, l := range md.Links(text) {
+		if !l.Wiki || strings.Contains(l.Text, "\n") {
+			continue
+		}
+		for {
+			i := strings.IndexByte(text[lineStart:], '\n')
+			if i < 0 || lineStart+i > l.Start {
+				break
+			}
+			lineStart += i + 1
+			line++
+		}
+		start := l.Start - lineStart
+		links = append(links, lineLink{Link: l, line: line, start: start, end: start + len(l.Text)})
+	}
+	return links
+}
+
+// linksTo reports whether the note at path is one of notes.
+func linksTo(notes []scan.Note, path string) bool {
+	for _, n := range notes {
+		if n.Path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// publishDiagnostics sends the issues the lint rules find in the document at
+// path to the client.
+func (s *Server) publishDiagnostics(path string) error {
+	text, err := s.text(path)
+	if err != nil {
+		return err
+	}
+	modified := time.Now()
+	if info, err := os.Stat(path); err == nil {
+		modified = info.ModTime()
+	}
+	n := lint.Note{Note: scan.ParseNote(path, text, modified), Content: text}
+	if s.opts.NoteType != nil {
+		n.Type = s.opts.NoteType(n.Note)
+	}
+	lines := strings.Split(text, "\n")
+	diagnostics := []Diagnostic{}
+	for _, issue := range lint.Run([]lint.Note{n}, s.opts.Rules) {
+		line := max(issue.Line-1, 0)
+		end := 0
+		if line < len(lines) {
+			text := strings.TrimSuffix(lines[line], "\r")
+			end = character(text, len(text))
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    Range{Position{line, 0}, Position{line, end}},
+			Severity: severityWarning,
+			Code:     issue.Rule,
+			Source:   "exo",
+			Message:  issue.Message,
+		})
+	}
+	return s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+		"uri":         pathURI(path),
+		"diagnostics": diagnostics,
+	})
+}
+
+// byteOffset returns the byte offset in line of the UTF-16 offset char.
+func byteOffset(line string, char int) int {
+	n := 0
+	for i, r := range line {
+		if n >= char {
+			return i
+		}
+		n += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// character returns the UTF-16 offset in line of the byte offset i.
+func character(line string, i int) int {
+	n := 0
+	for _, r := range line[:i] {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
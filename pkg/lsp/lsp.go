@@ -0,0 +1,318 @@
+// Package lsp implements a minimal Language Server Protocol server over the
+// notes of a vault, for editors such as Neovim: completion of wikilinks and
+// tags, go-to-definition on links, references from backlinks and diagnostics
+// from lint rules. Notes are looked up in the note index.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/a-kostevski/exo/pkg/lint"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+)
+
+// Error codes of JSON-RPC 2.0 used by the server.
+const (
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternal       = -32603
+)
+
+// ErrNoShutdown is returned by Serve when the client exits without asking the
+// server to shut down first.
+var ErrNoShutdown = errors.New("exit without shutdown")
+
+// Options configures a Server.
+type Options struct {
+	// DataHome is the vault directory served.
+	DataHome string
+	// Rules are the lint rules diagnostics are reported for.
+	Rules []lint.Rule
+	// NoteType returns the type of a note checked by Rules; types are empty
+	// when unset.
+	NoteType func(scan.Note) string
+}
+
+// Server answers a language client over the notes of an index. Documents open
+// in the client are checked as they are edited, before they are saved.
+type Server struct {
+	opts Options
+	ix   *store.Index
+
+	mu    sync.RWMutex
+	names *scan.Names
+
+	// docs holds the text of the documents open in the client, by path.
+	docs     map[string]string
+	out      io.Writer
+	shutdown bool
+}
+
+// message is a request or notification received from the client.
+type message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// docParams holds the params of the textDocument methods the server
+// implements.
+type docParams struct {
+	TextDocument struct {
+		URI  string `json:"uri"`
+		Text string `json:"text"`
+	} `json:"textDocument"`
+	// ContentChanges are whole documents, as synchronization is full.
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Position Position `json:"position"`
+}
+
+// rpcError is the error of a failed request.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// New returns a Server answering from ix, which it keeps up to date through
+// Update and as documents are saved.
+func New(ix *store.Index, opts Options) (*Server, error) {
+	s := &Server{opts: opts, ix: ix, docs: make(map[string]string)}
+	if err := s.loadNames(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Update brings the index up to date with the notes at paths, which changed.
+func (s *Server) Update(paths ...string) error {
+	if _, err := s.ix.Update(paths...); err != nil {
+		return err
+	}
+	return s.loadNames()
+}
+
+// loadNames indexes the names of the indexed notes for resolving links.
+func (s *Server) loadNames() error {
+	notes, err := s.ix.Notes(store.Query{})
+	if err != nil {
+		return err
+	}
+	names := scan.NewNames(s.opts.DataHome, notes)
+	s.mu.Lock()
+	s.names = names
+	s.mu.Unlock()
+	return nil
+}
+
+// resolve returns the notes a wikilink target names.
+func (s *Server) resolve(target string) []scan.Note {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.names.Resolve(target)
+}
+
+// Serve answers the messages read from in, writing responses and diagnostics
+// to out, until the client exits.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	r := bufio.NewReader(in)
+	for {
+		data, err := readMessage(r)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			if err := s.reply(json.RawMessage("null"), nil, &rpcError{codeInvalidRequest, "invalid JSON: " + err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		result, err := s.handle(msg)
+		if msg.ID == nil {
+			// Notifications get no response.
+			continue
+		}
+		if err := s.reply(msg.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+// handle answers msg.
+func (s *Server) handle(msg message) (interface{}, error) {
+	if s.shutdown {
+		return nil, &rpcError{codeInvalidRequest, "the server is shut down"}
+	}
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// Documents are sent whole on every change.
+				"textDocumentSync": map[string]interface{}{"openClose": true, "change": 1, "save": true},
+				"completionProvider": map[string]interface{}{
+					"triggerCharacters": []string{"[", "#"},
+				},
+				"definitionProvider": true,
+				"referencesProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "exo"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didSave", "textDocument/didClose",
+		"textDocument/completion", "textDocument/definition", "textDocument/references":
+	default:
+		if msg.ID == nil {
+			// Unknown notifications, such as "initialized" and "$/" ones, are ignored.
+			return nil, nil
+		}
+		return nil, &rpcError{codeMethodNotFound, fmt.Sprintf("unknown method %q", msg.Method)}
+	}
+	var p docParams
+	if err := json.Unmarshal(msg.Params, &p); err != nil {
+		return nil, &rpcError{codeInvalidParams, "invalid params: " + err.Error()}
+	}
+	path, err := uriPath(p.TextDocument.URI)
+	if err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+	switch msg.Method {
+	case "textDocument/didOpen":
+		s.docs[path] = p.TextDocument.Text
+		return nil, s.publishDiagnostics(path)
+	case "textDocument/didChange":
+		if len(p.ContentChanges) == 0 {
+			return nil, nil
+		}
+		s.docs[path] = p.ContentChanges[len(p.ContentChanges)-1].Text
+		return nil, s.publishDiagnostics(path)
+	case "textDocument/didSave":
+		if err := s.Update(path); err != nil {
+			return nil, err
+		}
+		return nil, s.publishDiagnostics(path)
+	case "textDocument/didClose":
+		delete(s.docs, path)
+		return nil, s.notify("textDocument/publishDiagnostics", map[string]interface{}{
+			"uri":         pathURI(path),
+			"diagnostics": []Diagnostic{},
+		})
+	case "textDocument/completion":
+		return s.completion(path, p.Position)
+	case "textDocument/definition":
+		return s.definition(path, p.Position)
+	case "textDocument/references":
+		return s.references(path)
+	}
+	return nil, nil
+}
+
+// reply responds to the request id with result or err.
+func (s *Server) reply(id json.RawMessage, result interface{}, err error) error {
+	if err == nil {
+		return s.write(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Result  interface{}     `json:"result"`
+		}{"2.0", id, result})
+	}
+	var rpcErr *rpcError
+	if !errors.As(err, &rpcErr) {
+		rpcErr = &rpcError{codeInternal, err.Error()}
+	}
+	return s.write(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Error   *rpcError       `json:"error"`
+	}{"2.0", id, rpcErr})
+}
+
+// notify sends the notification method to the client.
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(struct {
+		JSONRPC string      `json:"jsonrpc"`
+		Method  string      `json:"method"`
+		Params  interface{} `json:"params"`
+	}{"2.0", method, params})
+}
+
+// write sends v to the client, after a Content-Length header.
+func (s *Server) write(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	return err
+}
+
+// readMessage reads a message, after its headers, from r.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(value)); err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, errors.New("missing Content-Length header")
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// uriPath returns the path of a file URI.
+func uriPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return "", fmt.Errorf("not a file URI: %s", uri)
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+// pathURI returns the file URI of path.
+func pathURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
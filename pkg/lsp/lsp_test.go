@@ -0,0 +1,202 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/lint"
+	"github.com/a-kostevski/exo/pkg/lsp"
+	"github.com/a-kostevski/exo/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// response is a message sent by the server.
+type response struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Params json.RawMessage `json:"params"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+func newServer(t *testing.T) (*lsp.Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"Go.md":          "# Go\n\nChannels, see [[Rust#Ownership]] #lang\n",
+		"Rust.md":        "# Rust\n\n## Ownership\n\nBorrowing. #lang #systems\n",
+		"ideas/Crabs.md": "# Crabs\n\nLike [[rust]] and [[Go|the gopher]].\n\n```\n[[Rust]] in code\n```\n",
+	} {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	ix, err := store.Open(filepath.Join(dir, ".exo", "index.db"), dir)
+	require.NoError(t, err)
+	t.Cleanup(func() { ix.Close() })
+	_, err = ix.Sync()
+	require.NoError(t, err)
+	s, err := lsp.New(ix, lsp.Options{DataHome: dir, Rules: []lint.Rule{lint.HeadingHierarchy{}}})
+	require.NoError(t, err)
+	return s, dir
+}
+
+func uri(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// session sends the messages to s, followed by shutdown and exit, and returns
+// the responses and notifications sent back.
+func session(t *testing.T, s *lsp.Server, messages ...string) []response {
+	t.Helper()
+	var in bytes.Buffer
+	messages = append(messages, `{"jsonrpc": "2.0", "id": "shutdown", "method": "shutdown"}`, `{"jsonrpc": "2.0", "method": "exit"}`)
+	for _, m := range messages {
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(m), m)
+	}
+	var out bytes.Buffer
+	require.NoError(t, s.Serve(&in, &out))
+
+	var responses []response
+	r := bufio.NewReader(&out)
+	for {
+		header, err := r.ReadString('\n')
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		require.NoError(t, err)
+		_, err = r.ReadString('\n')
+		require.NoError(t, err)
+		data := make([]byte, length)
+		_, err = io.ReadFull(r, data)
+		require.NoError(t, err)
+		var resp response
+		require.NoError(t, json.Unmarshal(data, &resp))
+		responses = append(responses, resp)
+	}
+	require.NotEmpty(t, responses)
+	assert.Equal(t, `"shutdown"`, string(responses[len(responses)-1].ID))
+	return responses[:len(responses)-1]
+}
+
+func request(id int, method, path string, line, character int) string {
+	return fmt.Sprintf(`{"jsonrpc": "2.0", "id": %d, "method": %q, "params": {"textDocument": {"uri": %q}, "position": {"line": %d, "character": %d}}}`,
+		id, method, uri(path), line, character)
+}
+
+func TestCompletion(t *testing.T) {
+	s, dir := newServer(t)
+	doc := filepath.Join(dir, "New.md")
+	open := fmt.Sprintf(`{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": {"textDocument": {"uri": %q, "text": "# Ñew\n\nSee [[Ru and #la"}}}`, uri(doc))
+	responses := session(t, s, open, request(1, "textDocument/completion", doc, 2, 10), request(2, "textDocument/completion", doc, 2, 19))
+	require.Len(t, responses, 3)
+	assert.Equal(t, "textDocument/publishDiagnostics", responses[0].Method)
+
+	var items []lsp.CompletionItem
+	require.NoError(t, json.Unmarshal(responses[1].Result, &items))
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	assert.ElementsMatch(t, []string{"Go", "Rust", "Crabs"}, labels)
+	for _, item := range items {
+		if item.Label == "Crabs" {
+			assert.Equal(t, "Crabs (ideas/Crabs.md)", item.Detail)
+			assert.Equal(t, "Crabs]]", item.TextEdit.NewText)
+			assert.Equal(t, lsp.Range{Start: lsp.Position{Line: 2, Character: 6}, End: lsp.Position{Line: 2, Character: 10}}, item.TextEdit.Range)
+		}
+	}
+
+	require.NoError(t, json.Unmarshal(responses[2].Result, &items))
+	labels = nil
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	assert.Equal(t, []string{"lang", "systems"}, labels)
+	assert.Equal(t, 14, items[0].TextEdit.Range.Start.Character)
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	s, dir := newServer(t)
+	responses := session(t, s,
+		request(1, "textDocument/definition", filepath.Join(dir, "Go.md"), 2, 18),
+		request(2, "textDocument/definition", filepath.Join(dir, "Go.md"), 2, 2),
+		request(3, "textDocument/references", filepath.Join(dir, "Rust.md"), 0, 0),
+		request(4, "textDocument/definition", filepath.Join(dir, "ideas", "Crabs.md"), 5, 3),
+	)
+	require.Len(t, responses, 4)
+
+	var locations []lsp.Location
+	require.NoError(t, json.Unmarshal(responses[0].Result, &locations))
+	require.Len(t, locations, 1)
+	assert.Equal(t, uri(filepath.Join(dir, "Rust.md")), locations[0].URI)
+	assert.Equal(t, 2, locations[0].Range.Start.Line, "the link goes to its heading")
+
+	require.NoError(t, json.Unmarshal(responses[1].Result, &locations))
+	assert.Empty(t, locations, "no link at the position")
+
+	require.NoError(t, json.Unmarshal(responses[2].Result, &locations))
+	require.Len(t, locations, 2)
+	assert.Equal(t, uri(filepath.Join(dir, "Go.md")), locations[0].URI)
+	assert.Equal(t, lsp.Range{Start: lsp.Position{Line: 2, Character: 14}, End: lsp.Position{Line: 2, Character: 32}}, locations[0].Range)
+	assert.Equal(t, uri(filepath.Join(dir, "ideas", "Crabs.md")), locations[1].URI)
+	assert.Equal(t, 5, locations[1].Range.Start.Character)
+	assert.Equal(t, 2, locations[1].Range.Start.Line, "links in code blocks are not references")
+
+	require.NoError(t, json.Unmarshal(responses[3].Result, &locations))
+	assert.Empty(t, locations, "links in code blocks have no definition")
+}
+
+func TestDiagnostics(t *testing.T) {
+	s, dir := newServer(t)
+	doc := filepath.Join(dir, "Go.md")
+	change := fmt.Sprintf(`{"jsonrpc": "2.0", "method": "textDocument/didChange", "params": {"textDocument": {"uri": %q}, "contentChanges": [{"text": "# Go\n\n### Deep\n"}]}}`, uri(doc))
+	responses := session(t, s, change)
+	require.Len(t, responses, 1)
+	assert.Equal(t, "textDocument/publishDiagnostics", responses[0].Method)
+
+	var params struct {
+		URI         string           `json:"uri"`
+		Diagnostics []lsp.Diagnostic `json:"diagnostics"`
+	}
+	require.NoError(t, json.Unmarshal(responses[0].Params, &params))
+	assert.Equal(t, uri(doc), params.URI)
+	require.Len(t, params.Diagnostics, 1)
+	d := params.Diagnostics[0]
+	assert.Equal(t, "heading-hierarchy", d.Code)
+	assert.Equal(t, lsp.Range{Start: lsp.Position{Line: 2}, End: lsp.Position{Line: 2, Character: 8}}, d.Range)
+}
+
+func TestServe_Errors(t *testing.T) {
+	s, _ := newServer(t)
+	responses := session(t, s,
+		`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {}}`,
+		`{"jsonrpc": "2.0", "method": "initialized", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "textDocument/hover", "params": {}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "textDocument/completion", "params": {"textDocument": {"uri": "untitled:1"}}}`,
+	)
+	require.Len(t, responses, 3)
+	assert.Contains(t, string(responses[0].Result), `"definitionProvider":true`)
+	require.NotNil(t, responses[1].Error)
+	assert.Equal(t, -32601, responses[1].Error.Code)
+	require.NotNil(t, responses[2].Error)
+	assert.Equal(t, -32602, responses[2].Error.Code)
+
+	in := "Content-Length: 38\r\n\r\n" + `{"jsonrpc": "2.0", "method": "exit"}  `
+	s, _ = newServer(t)
+	assert.ErrorIs(t, s.Serve(strings.NewReader(in), io.Discard), lsp.ErrNoShutdown)
+}
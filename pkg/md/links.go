@@ -0,0 +1,157 @@
+package md
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+var (
+	// wikilinkPattern matches [[target#anchor|label]] and its ![[embed]] form.
+	wikilinkPattern = regexp.MustCompile(`(!?)\[\[([^\]|#]+)(?:#([^\]|]*))?(?:\|([^\]]*))?\]\]`)
+	// markdownLinkPattern matches [label](target) and ![alt](target).
+	markdownLinkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)\s]+)\)`)
+)
+
+// Link is a wikilink, [[target#anchor|label]], or a Markdown link,
+// [label](target), of a document, outside code.
+type Link struct {
+	// Wiki tells wikilinks from Markdown links.
+	Wiki bool
+	// Embed marks embeds and images: ![[target]] and ![alt](target).
+	Embed bool
+	// Target is the note a wikilink names, without surrounding spaces, or the
+	// destination of a Markdown link as written, #anchor included.
+	Target string
+	// Anchor is the heading or block a wikilink points to, without its #.
+	Anchor string
+	// Label is the text shown for the link: the label of a wikilink, empty
+	// when it has none, or the text of a Markdown link.
+	Label string
+	// Text is the link as written, which starts at the byte offset Start of
+	// the document.
+	Text  string
+	Start int
+}
+
+// String returns l written from its fields, e.g. "[[target#anchor|label]]".
+func (l Link) String() string {
+	var sb strings.Builder
+	if l.Embed {
+		sb.WriteString("!")
+	}
+	if !l.Wiki {
+		sb.WriteString("[" + l.Label + "](" + l.Target + ")")
+		return sb.String()
+	}
+	sb.WriteString("[[" + l.Target)
+	if l.Anchor != "" {
+		sb.WriteString("#" + l.Anchor)
+	}
+	if l.Label != "" {
+		sb.WriteString("|" + l.Label)
+	}
+	sb.WriteString("]]")
+	return sb.String()
+}
+
+// Links returns the links of content in the order they appear. Links in code
+// blocks and code spans, as a Markdown parser finds them, are code rather than
+// links and are left out; those of the frontmatter are kept.
+func Links(content string) []Link {
+	code := codeRanges(content)
+	inCode := func(start, end int) bool {
+		// The ranges are sorted and do not overlap.
+		i := sort.Search(len(code), func(i int) bool { return code[i][1] > start })
+		return i < len(code) && code[i][0] < end
+	}
+	var links []Link
+	for _, loc := range wikilinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		if inCode(loc[0], loc[1]) {
+			continue
+		}
+		l := Link{Wiki: true, Embed: loc[3] > loc[2], Text: content[loc[0]:loc[1]], Start: loc[0]}
+		l.Target = strings.TrimSpace(content[loc[4]:loc[5]])
+		if loc[6] >= 0 {
+			l.Anchor = content[loc[6]:loc[7]]
+		}
+		if loc[8] >= 0 {
+			l.Label = content[loc[8]:loc[9]]
+		}
+		links = append(links, l)
+	}
+	for _, loc := range markdownLinkPattern.FindAllStringSubmatchIndex(content, -1) {
+		if inCode(loc[0], loc[1]) {
+			continue
+		}
+		links = append(links, Link{
+			Embed:  loc[3] > loc[2],
+			Label:  content[loc[4]:loc[5]],
+			Target: content[loc[6]:loc[7]],
+			Text:   content[loc[0]:loc[1]],
+			Start:  loc[0],
+		})
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Start < links[j].Start })
+	return links
+}
+
+// RewriteLinks returns content with each of its links, as Links finds them,
+// replaced by what rewrite returns for it: l.Text keeps it as written,
+// l.String() writes it anew from its fields.
+func RewriteLinks(content string, rewrite func(l Link) string) string {
+	var sb strings.Builder
+	last := 0
+	for _, l := range Links(content) {
+		if l.Start < last {
+			// Inside a link already rewritten, such as the label of a
+			// Markdown link.
+			continue
+		}
+		sb.WriteString(content[last:l.Start])
+		sb.WriteString(rewrite(l))
+		last = l.Start + len(l.Text)
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// codeRanges returns the byte ranges of content, sorted, holding the code
+// blocks and code spans of its body.
+func codeRanges(content string) [][2]int {
+	_, body := frontmatter.Split(content)
+	offset := len(content) - len(body)
+	source := []byte(body)
+	doc := goldmark.DefaultParser().Parse(text.NewReader(source))
+	var ranges [][2]int
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.FencedCodeBlock, *ast.CodeBlock:
+			lines := n.Lines()
+			if lines.Len() > 0 {
+				ranges = append(ranges, [2]int{offset + lines.At(0).Start, offset + lines.At(lines.Len()-1).Stop})
+			}
+			return ast.WalkSkipChildren, nil
+		case *ast.CodeSpan:
+			first, last := n.FirstChild(), n.LastChild()
+			if t, ok := first.(*ast.Text); ok {
+				if u, ok := last.(*ast.Text); ok {
+					ranges = append(ranges, [2]int{offset + t.Segment.Start, offset + u.Segment.Stop})
+				}
+			}
+			return ast.WalkSkipChildren, nil
+		}
+		return ast.WalkContinue, nil
+	})
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+	return ranges
+}
@@ -0,0 +1,59 @@
+package md_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinks(t *testing.T) {
+	content := "---\nsee: \"[[Meta]]\"\n---\n# Title\n\nSee [[ Go #Channels|chans]], ![[diagram]] and [the docs](ref/go.md#top).\n\n![alt](img/a.png)\n"
+	links := md.Links(content)
+	require.Len(t, links, 5)
+
+	assert.Equal(t, md.Link{Wiki: true, Target: "Meta", Text: "[[Meta]]", Start: strings.Index(content, "[[Meta]]")}, links[0])
+	assert.Equal(t, md.Link{Wiki: true, Target: "Go", Anchor: "Channels", Label: "chans", Text: "[[ Go #Channels|chans]]", Start: strings.Index(content, "[[ Go")}, links[1])
+	assert.True(t, links[2].Wiki)
+	assert.True(t, links[2].Embed)
+	assert.Equal(t, "diagram", links[2].Target)
+	assert.Equal(t, md.Link{Label: "the docs", Target: "ref/go.md#top", Text: "[the docs](ref/go.md#top)", Start: strings.Index(content, "[the docs]")}, links[3])
+	assert.True(t, links[4].Embed)
+	assert.False(t, links[4].Wiki)
+	assert.Equal(t, "img/a.png", links[4].Target)
+}
+
+func TestLinks_SkipsCode(t *testing.T) {
+	content := "[[Prose]] and `[[Span]]`\n\n```md\n[[Fenced]] [a](b.md)\n```\n\n    [[Indented]]\n\n~~~\n[[Tilde]]\n~~~\n\nAfter [[Last]]\n"
+	var targets []string
+	for _, l := range md.Links(content) {
+		targets = append(targets, l.Target)
+	}
+	assert.Equal(t, []string{"Prose", "Last"}, targets)
+}
+
+func TestLink_String(t *testing.T) {
+	for _, s := range []string{"[[Go]]", "[[Go#Channels]]", "[[Go#Channels|chans]]", "![[diagram]]", "[docs](go.md)", "![alt](a.png)"} {
+		links := md.Links(s)
+		require.Len(t, links, 1, s)
+		assert.Equal(t, s, links[0].String())
+	}
+}
+
+func TestRewriteLinks(t *testing.T) {
+	content := "[[Old]], [[Old#Top|label]], `[[Old]]` and [old](old.md)\n\n```\n[[Old]]\n```\n"
+	result := md.RewriteLinks(content, func(l md.Link) string {
+		if l.Wiki && l.Target == "Old" {
+			l.Target = "New"
+			return l.String()
+		}
+		if !l.Wiki {
+			l.Target = "new.md"
+			return l.String()
+		}
+		return l.Text
+	})
+	assert.Equal(t, "[[New]], [[New#Top|label]], `[[Old]]` and [old](new.md)\n\n```\n[[Old]]\n```\n", result)
+}
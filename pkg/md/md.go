@@ -0,0 +1,292 @@
+// Package md models Markdown documents as a tree of sections, each introduced
+// by an ATX heading such as "## Notes" and running until the next heading of
+// the same or a higher level, to insert text under a heading, replace the text
+// of a section or extract it.
+package md
+
+import (
+	"strings"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+)
+
+// Section is a heading and the lines below it, up to the next heading of the
+// same or a higher level. The document itself is the section of level 0.
+type Section struct {
+	// Level is the number of #'s of the heading, from 1 to 6.
+	Level int
+	// Title is the text of the heading, e.g. "Notes" for "## Notes".
+	Title string
+	// Line is the index of the line of the heading, -1 for the document.
+	Line int
+	// End is the index of the line after the section.
+	End int
+
+	Parent   *Section
+	Children []*Section
+}
+
+// Heading returns the heading line of s, e.g. "## Notes".
+func (s *Section) Heading() string {
+	if s.Level == 0 {
+		return ""
+	}
+	return strings.Repeat("#", s.Level) + " " + s.Title
+}
+
+// Path returns the titles of the headings s is under, outermost first, and
+// that of s.
+func (s *Section) Path() []string {
+	var path []string
+	for ; s != nil && s.Level > 0; s = s.Parent {
+		path = append([]string{s.Title}, path...)
+	}
+	return path
+}
+
+// Document is a parsed Markdown document.
+type Document struct {
+	// Lines are the lines of the document, without their line breaks.
+	Lines []string
+	// Root is the section of the whole document, holding the sections of its
+	// headings.
+	Root *Section
+	// code marks the lines of frontmatter and fenced code blocks, which hold
+	// no headings.
+	code []bool
+}
+
+// Parse parses content into its sections. Lines starting with #'s in the
+// frontmatter or in fenced code blocks are not headings.
+func Parse(content string) *Document {
+	lines := strings.Split(content, "\n")
+	d := &Document{Lines: lines, code: make([]bool, len(lines))}
+	d.Root = &Section{Line: -1, End: len(lines)}
+	open := []*Section{d.Root}
+	frontmatter := strings.TrimSpace(lines[0]) == "---"
+	fence := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case frontmatter:
+			d.code[i] = true
+			if i > 0 && (trimmed == "---" || trimmed == "...") {
+				frontmatter = false
+			}
+			continue
+		case fence != "":
+			d.code[i] = true
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			d.code[i] = true
+			fence = trimmed[:3]
+			continue
+		}
+		level, title := Heading(line)
+		if level == 0 {
+			continue
+		}
+		for open[len(open)-1].Level >= level {
+			open[len(open)-1].End = i
+			open = open[:len(open)-1]
+		}
+		parent := open[len(open)-1]
+		s := &Section{Level: level, Title: title, Line: i, End: len(lines), Parent: parent}
+		parent.Children = append(parent.Children, s)
+		open = append(open, s)
+	}
+	return d
+}
+
+// Heading returns the level and the text of the ATX heading on line, such as
+// 2 and "Notes" for "## Notes", or 0 when line is not a heading.
+func Heading(line string) (int, string) {
+	line = strings.TrimSpace(line)
+	level := 0
+	for level < len(line) && line[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 || (level < len(line) && line[level] != ' ' && line[level] != '\t') {
+		return 0, ""
+	}
+	return level, strings.TrimSpace(line[level:])
+}
+
+// Sections returns the sections of d in document order, without the root.
+func (d *Document) Sections() []*Section {
+	var all []*Section
+	var walk func(s *Section)
+	walk = func(s *Section) {
+		for _, c := range s.Children {
+			all = append(all, c)
+			walk(c)
+		}
+	}
+	walk(d.Root)
+	return all
+}
+
+// Find returns the first section introduced by heading, e.g. "## Notes", or
+// nil. A heading given without #'s, e.g. "Notes", matches a heading of any
+// level with that text.
+func (d *Document) Find(heading string) *Section {
+	level, title := Heading(heading)
+	if level == 0 {
+		title = strings.TrimSpace(heading)
+	}
+	for _, s := range d.Sections() {
+		if s.Title == title && (level == 0 || s.Level == level) {
+			return s
+		}
+	}
+	return nil
+}
+
+// At returns the innermost section holding the line of index i.
+func (d *Document) At(i int) *Section {
+	s := d.Root
+	for {
+		var next *Section
+		for _, c := range s.Children {
+			if c.Line <= i && i < c.End {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return s
+		}
+		s = next
+	}
+}
+
+// Code reports whether the line of index i is in the frontmatter or in a
+// fenced code block.
+func (d *Document) Code(i int) bool {
+	return i >= 0 && i < len(d.code) && d.code[i]
+}
+
+// Body returns the text of s below its heading, subsections included,
+// without surrounding blank lines.
+func (d *Document) Body(s *Section) string {
+	return strings.Trim(strings.Join(d.Lines[s.Line+1:s.End], "\n"), "\n")
+}
+
+// String returns the document.
+func (d *Document) String() string {
+	return strings.Join(d.Lines, "\n")
+}
+
+// Extract returns the text of the section introduced by heading, as Find
+// matches it, without the heading and surrounding blank lines.
+func Extract(content, heading string) (string, error) {
+	d := Parse(content)
+	s := d.Find(heading)
+	if s == nil {
+		return "", exoerrors.New(exoerrors.NotFound, "heading not found: %s", strings.TrimSpace(heading))
+	}
+	return d.Body(s), nil
+}
+
+// HasHeading reports whether content has a section introduced by heading.
+func HasHeading(content, heading string) bool {
+	return Parse(content).Find(heading) != nil
+}
+
+// AppendUnder inserts text at the end of the section introduced by heading
+// (e.g. "## Notes"), after its subsections. If the heading does not exist, it
+// is appended to the end of the content first.
+func AppendUnder(content, heading, text string) string {
+	heading = strings.TrimSpace(heading)
+	d := Parse(content)
+	s := d.Find(heading)
+	if s == nil {
+		return appendSection(content, heading, text)
+	}
+	lines := d.Lines
+	// Insert after the last non-blank line of the section.
+	insert := s.End
+	for insert > s.Line+1 && strings.TrimSpace(lines[insert-1]) == "" {
+		insert--
+	}
+
+	var out []string
+	out = append(out, lines[:insert]...)
+	if insert == s.Line+1 {
+		// Keep a blank line between the heading and its first entry.
+		out = append(out, "")
+	}
+	out = append(out, text)
+	if s.End < len(lines) && insert == s.End {
+		out = append(out, "")
+	}
+	out = append(out, lines[insert:]...)
+	return withNewline(strings.Join(out, "\n"))
+}
+
+// Replace replaces the text of the section introduced by heading with text,
+// keeping the heading. If the heading does not exist, the section is appended
+// to the end of the content.
+func Replace(content, heading, text string) string {
+	heading = strings.TrimSpace(heading)
+	d := Parse(content)
+	s := d.Find(heading)
+	if s == nil {
+		return appendSection(content, heading, text)
+	}
+	var out []string
+	out = append(out, d.Lines[:s.Line+1]...)
+	out = append(out, "", strings.Trim(text, "\n"))
+	if s.End < len(d.Lines) {
+		out = append(out, "")
+		out = append(out, d.Lines[s.End:]...)
+	}
+	return withNewline(strings.Join(out, "\n"))
+}
+
+// Insert adds a section introduced by heading, holding text, right before the
+// section introduced by before, or at the end of the content if content has
+// no such heading. text may be empty.
+func Insert(content, heading, text, before string) string {
+	section := strings.TrimSpace(heading) + "\n"
+	if text = strings.Trim(text, "\n"); text != "" {
+		section += "\n" + text + "\n"
+	}
+	d := Parse(content)
+	var at *Section
+	if strings.TrimSpace(before) != "" {
+		at = d.Find(before)
+	}
+	if at == nil {
+		trimmed := strings.TrimRight(content, "\n")
+		if trimmed != "" {
+			trimmed += "\n\n"
+		}
+		return trimmed + section
+	}
+	var out []string
+	out = append(out, d.Lines[:at.Line]...)
+	out = append(out, strings.Split(section, "\n")...)
+	out = append(out, d.Lines[at.Line:]...)
+	return strings.Join(out, "\n")
+}
+
+// appendSection appends a section introduced by heading, holding text, to
+// the end of content.
+func appendSection(content, heading, text string) string {
+	trimmed := strings.TrimRight(content, "\n")
+	if trimmed != "" {
+		trimmed += "\n\n"
+	}
+	return trimmed + heading + "\n\n" + text + "\n"
+}
+
+func withNewline(s string) string {
+	if !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s
+}
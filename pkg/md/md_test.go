@@ -0,0 +1,94 @@
+package md_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendUnder_ExistingSection(t *testing.T) {
+	content := "# 2025-02-08\n\n## Notes\n\n- first\n\n## Tomorrow\n\n1.\n"
+	result := md.AppendUnder(content, "## Notes", "- second")
+	assert.Equal(t, "# 2025-02-08\n\n## Notes\n\n- first\n- second\n\n## Tomorrow\n\n1.\n", result)
+}
+
+func TestAppendUnder_EmptySectionAtEnd(t *testing.T) {
+	content := "# 2025-02-08\n\n## Notes"
+	result := md.AppendUnder(content, "## Notes", "- entry")
+	assert.Equal(t, "# 2025-02-08\n\n## Notes\n\n- entry\n", result)
+}
+
+func TestAppendUnder_SubheadingsStayInSection(t *testing.T) {
+	content := "## Log\n\n### Morning\n\n- a\n\n## Other\n"
+	result := md.AppendUnder(content, "## Log", "- b")
+	assert.Equal(t, "## Log\n\n### Morning\n\n- a\n- b\n\n## Other\n", result)
+}
+
+func TestAppendUnder_MissingHeading(t *testing.T) {
+	content := "# Title\n\nBody\n"
+	result := md.AppendUnder(content, "## Log", "- entry")
+	assert.Equal(t, "# Title\n\nBody\n\n## Log\n\n- entry\n", result)
+}
+
+func TestInsert(t *testing.T) {
+	content := "# 2025-02-08\n\n## Log\n\n- a\n\n## Notes\n"
+	assert.Equal(t, "# 2025-02-08\n\n## Log\n\n- a\n\n## Tasks\n\n## Notes\n",
+		md.Insert(content, "## Tasks", "", "## Notes"))
+	assert.Equal(t, "# 2025-02-08\n\n## Log\n\n- a\n\n## Notes\n\n## Gratitude\n\n1.\n",
+		md.Insert(content, "## Gratitude", "1.\n", "## Missing"))
+	assert.Equal(t, "## Tasks\n", md.Insert("", "## Tasks", "", ""))
+	assert.True(t, md.HasHeading(content, "## Log"))
+	assert.False(t, md.HasHeading(content, "## Tasks"))
+}
+
+func TestReplace(t *testing.T) {
+	content := "# W06\n\n## Summary\n\n### Old\n\n- stale\n\n## Notes\n\n- keep\n"
+	result := md.Replace(content, "## Summary", "### New\n\n- fresh\n")
+	assert.Equal(t, "# W06\n\n## Summary\n\n### New\n\n- fresh\n\n## Notes\n\n- keep\n", result)
+
+	result = md.Replace("# W06\n\n## Summary\n", "## Summary", "- fresh")
+	assert.Equal(t, "# W06\n\n## Summary\n\n- fresh\n", result)
+
+	result = md.Replace("# W06\n", "## Summary", "- fresh")
+	assert.Equal(t, "# W06\n\n## Summary\n\n- fresh\n", result)
+}
+
+func TestExtract(t *testing.T) {
+	content := "# Title\n\n## Idea\n\nFirst line\nSecond line\n\n### Detail\n\nMore\n\n## Next\n\nOther\n"
+
+	body, err := md.Extract(content, "## Idea")
+	require.NoError(t, err)
+	assert.Equal(t, "First line\nSecond line\n\n### Detail\n\nMore", body)
+
+	body, err = md.Extract(content, "Detail")
+	require.NoError(t, err)
+	assert.Equal(t, "More", body)
+
+	_, err = md.Extract(content, "## Missing")
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	content := "---\n# not: a heading\n---\n# Go\n\n## Channels\n\n```\n# comment\n```\n\n### Buffers\n\ntext\n\n## Select\n"
+	d := md.Parse(content)
+	sections := d.Sections()
+	require.Len(t, sections, 4)
+	goSection := sections[0]
+	assert.Equal(t, "# Go", goSection.Heading())
+	assert.Equal(t, []string{"Channels", "Select"}, []string{goSection.Children[0].Title, goSection.Children[1].Title})
+	assert.Equal(t, 3, goSection.Line)
+	assert.Equal(t, len(d.Lines), goSection.End)
+
+	buffers := d.Find("Buffers")
+	require.NotNil(t, buffers)
+	assert.Equal(t, []string{"Go", "Channels", "Buffers"}, buffers.Path())
+	assert.Equal(t, 15, buffers.End, "a section ends at the next heading of the same or a higher level")
+	assert.Equal(t, buffers, d.At(13))
+	assert.Equal(t, d.Find("## Channels"), d.At(8), "fenced lines are not headings")
+	assert.True(t, d.Code(8))
+	assert.Equal(t, d.Root, d.At(1), "frontmatter lines are not headings")
+	assert.Nil(t, d.Find("### Channels"))
+	assert.Equal(t, content, d.String())
+}
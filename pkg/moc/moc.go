@@ -0,0 +1,100 @@
+// Package moc renders maps of content: index notes listing the notes on a
+// topic, grouped into sections.
+package moc
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Block is the name of the generated block of a map of content.
+const Block = "moc"
+
+// GroupBy names how the notes of a map of content are grouped.
+type GroupBy string
+
+const (
+	// ByDir groups notes by their directory.
+	ByDir GroupBy = "dir"
+	// ByTag groups notes under each of their tags.
+	ByTag GroupBy = "tag"
+	// ByNone lists the notes without grouping them.
+	ByNone GroupBy = "none"
+)
+
+// ParseGroupBy returns the grouping called name.
+func ParseGroupBy(name string) (GroupBy, error) {
+	switch g := GroupBy(strings.ToLower(name)); g {
+	case ByDir, ByTag, ByNone:
+		return g, nil
+	}
+	return "", fmt.Errorf("invalid grouping %q (expected dir, tag or none)", name)
+}
+
+// Link returns the wikilink to n: its file name, with its title as the link
+// text when they differ.
+func Link(n scan.Note) string {
+	name := strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension)
+	if n.Title == "" || n.Title == name {
+		return "[[" + name + "]]"
+	}
+	return "[[" + name + "|" + n.Title + "]]"
+}
+
+// Render lists the notes as Markdown links sorted by title. With ByDir the
+// notes are grouped under a level-two heading per directory relative to root;
+// with ByTag under a heading per tag, the tag topic aside. Notes in root, or
+// without other tags, are listed first.
+func Render(notes []scan.Note, group GroupBy, root, topic string) string {
+	groups := make(map[string][]scan.Note)
+	for _, n := range notes {
+		switch group {
+		case ByDir:
+			dir, err := filepath.Rel(root, filepath.Dir(n.Path))
+			if err != nil || dir == "." {
+				dir = ""
+			}
+			groups[filepath.ToSlash(dir)] = append(groups[filepath.ToSlash(dir)], n)
+		case ByTag:
+			tagged := false
+			for _, tag := range n.Tags {
+				if !strings.EqualFold(tag, strings.TrimPrefix(topic, "#")) {
+					groups["#"+tag] = append(groups["#"+tag], n)
+					tagged = true
+				}
+			}
+			if !tagged {
+				groups[""] = append(groups[""], n)
+			}
+		default:
+			groups[""] = append(groups[""], n)
+		}
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sections []string
+	for _, name := range names {
+		members := groups[name]
+		sort.SliceStable(members, func(i, j int) bool {
+			return strings.ToLower(members[i].Title) < strings.ToLower(members[j].Title)
+		})
+		var sb strings.Builder
+		if name != "" {
+			sb.WriteString("## " + name + "\n\n")
+		}
+		for _, n := range members {
+			sb.WriteString("- " + Link(n) + "\n")
+		}
+		sections = append(sections, strings.TrimSuffix(sb.String(), "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
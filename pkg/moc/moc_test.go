@@ -0,0 +1,61 @@
+package moc_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/moc"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var notes = []scan.Note{
+	{Path: "/vault/zettel/channels.md", Title: "Channels", Tags: []string{"go", "concurrency"}},
+	{Path: "/vault/zettel/202502081430 Interfaces.md", Title: "Interfaces", Tags: []string{"go"}},
+	{Path: "/vault/go.md", Title: "go", Tags: []string{"go"}},
+	{Path: "/vault/projects/exo/errors.md", Title: "Error handling", Tags: []string{"go", "exo"}},
+}
+
+func TestLink(t *testing.T) {
+	assert.Equal(t, "[[go]]", moc.Link(notes[2]))
+	assert.Equal(t, "[[202502081430 Interfaces|Interfaces]]", moc.Link(notes[1]))
+}
+
+func TestRender(t *testing.T) {
+	assert.Equal(t, `- [[go]]
+
+## projects/exo
+
+- [[errors|Error handling]]
+
+## zettel
+
+- [[channels|Channels]]
+- [[202502081430 Interfaces|Interfaces]]`, moc.Render(notes, moc.ByDir, "/vault", ""))
+
+	assert.Equal(t, `- [[go]]
+- [[202502081430 Interfaces|Interfaces]]
+
+## #concurrency
+
+- [[channels|Channels]]
+
+## #exo
+
+- [[errors|Error handling]]`, moc.Render(notes, moc.ByTag, "/vault", "#Go"))
+
+	assert.Equal(t, `- [[channels|Channels]]
+- [[errors|Error handling]]
+- [[go]]
+- [[202502081430 Interfaces|Interfaces]]`, moc.Render(notes, moc.ByNone, "/vault", ""))
+
+	assert.Empty(t, moc.Render(nil, moc.ByDir, "/vault", ""))
+}
+
+func TestParseGroupBy(t *testing.T) {
+	g, err := moc.ParseGroupBy("Tag")
+	require.NoError(t, err)
+	assert.Equal(t, moc.ByTag, g)
+	_, err = moc.ParseGroupBy("month")
+	assert.Error(t, err)
+}
@@ -0,0 +1,121 @@
+package note
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/diff"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+)
+
+// ConflictPolicy is what saving a note changed on disk since it was loaded
+// does, as set by general.on_conflict.
+type ConflictPolicy string
+
+const (
+	// ConflictFail refuses to save the note, with ErrConflict.
+	ConflictFail ConflictPolicy = "fail"
+	// ConflictMerge merges the changes on disk into the note, with conflict
+	// markers where both changed the same lines.
+	ConflictMerge ConflictPolicy = "merge"
+	// ConflictCopy saves the note to a .conflict file next to it, leaving
+	// the changes on disk as they are.
+	ConflictCopy ConflictPolicy = "copy"
+	// ConflictOverwrite saves the note over the changes on disk.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+)
+
+// Valid reports whether p is a known policy.
+func (p ConflictPolicy) Valid() bool {
+	switch p {
+	case ConflictFail, ConflictMerge, ConflictCopy, ConflictOverwrite:
+		return true
+	}
+	return false
+}
+
+// ErrConflict is returned when saving a note changed on disk since it was
+// loaded, by a sync client or an editor.
+var ErrConflict = exoerrors.New(exoerrors.Conflict, "note changed on disk since it was read")
+
+// diskState is a note file as it was last read or written.
+type diskState struct {
+	content string
+	modTime time.Time
+}
+
+// record keeps the state of the file of the note, after it was read or
+// written with content.
+func (n *BaseNote) record(content string) {
+	if info, err := os.Stat(n.path); err == nil {
+		n.disk = &diskState{content: content, modTime: info.ModTime()}
+	}
+}
+
+// resolveConflict returns the path the note is to be saved to. When the file
+// of the note changed since it was loaded, the content of the note is merged
+// with the changes or saved elsewhere, as the configured policy says, or
+// ErrConflict returned.
+func (n *BaseNote) resolveConflict() (string, error) {
+	if n.conflictPath != "" {
+		// The note already went to a copy; keep saving there.
+		return n.conflictPath, nil
+	}
+	if n.disk == nil {
+		return n.path, nil
+	}
+	// The modification time rules out most changes cheaply, the content the
+	// files touched without changes.
+	info, err := os.Stat(n.path)
+	if err != nil || info.ModTime().Equal(n.disk.modTime) {
+		return n.path, nil
+	}
+	current, err := os.ReadFile(n.path)
+	if err != nil || string(current) == n.disk.content {
+		return n.path, nil
+	}
+
+	switch policy := ConflictPolicy(n.Config.General.OnConflict); policy {
+	case ConflictOverwrite:
+		n.logf("Overwriting %s, which changed on disk since it was read", n.path)
+		return n.path, nil
+	case ConflictMerge:
+		merged, conflicts := diff.Merge(n.disk.content, n.content, string(current), "exo", "disk")
+		n.content = merged
+		if conflicts {
+			n.warnf("Merged the changes to %s on disk with conflict markers; resolve them in the note", n.path)
+		}
+		return n.path, nil
+	case ConflictCopy:
+		path := conflictCopyPath(n.path, n.FS.FileExists)
+		n.conflictPath = path
+		n.warnf("%s changed on disk since it was read; saved to %s instead", n.path, path)
+		return path, nil
+	default:
+		return "", fmt.Errorf("cannot save %s: %w (use --force to overwrite it, or --on-conflict merge or copy)", n.path, ErrConflict)
+	}
+}
+
+// conflictCopyPath returns the first path of path.conflict, path.conflict.2,
+// and so on, that does not exist.
+func conflictCopyPath(path string, exists func(string) bool) string {
+	copyPath := path + ".conflict"
+	for i := 2; exists(copyPath); i++ {
+		copyPath = path + ".conflict." + strconv.Itoa(i)
+	}
+	return copyPath
+}
+
+func (n *BaseNote) logf(format string, args ...interface{}) {
+	if n.Logger != nil {
+		n.Logger.Infof(format, args...)
+	}
+}
+
+func (n *BaseNote) warnf(format string, args ...interface{}) {
+	if n.Logger != nil {
+		n.Logger.Errorf(format, args...)
+	}
+}
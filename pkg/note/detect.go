@@ -0,0 +1,67 @@
+package note
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// The note types Detect tells apart.
+const (
+	TypeDaily     NoteType = "daily"
+	TypeWeekly    NoteType = "weekly"
+	TypeMonthly   NoteType = "monthly"
+	TypeQuarterly NoteType = "quarterly"
+	TypeZettel    NoteType = "zettel"
+	TypeProject   NoteType = "project"
+	TypeIdea      NoteType = "idea"
+)
+
+// periodicNames are the file name patterns of periodic notes, without
+// extension.
+var periodicNames = []struct {
+	pattern *regexp.Regexp
+	typ     NoteType
+}{
+	{regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`), TypeDaily},
+	{regexp.MustCompile(`^\d{4}-W\d{2}$`), TypeWeekly},
+	{regexp.MustCompile(`^\d{4}-\d{2}$`), TypeMonthly},
+	{regexp.MustCompile(`^\d{4}-Q[1-4]$`), TypeQuarterly},
+}
+
+// Detect returns the type of the note at path with the frontmatter meta: its
+// type field, else the type of the innermost of dirs, which maps note
+// directories to the type of the notes in them, containing path, else the
+// period its file name names, as 2025-02-08.md names a daily note. Periodic
+// notes in a directory of a broader type, such as "periodic", are also told
+// apart by their file name. It returns "" when nothing tells the type.
+func Detect(path string, meta map[string]interface{}, dirs map[string]string) NoteType {
+	if t := frontmatter.String(meta, "type"); t != "" {
+		return NoteType(strings.ToLower(t))
+	}
+	var dirType NoteType
+	best := -1
+	for dir, typ := range dirs {
+		if dir != "" && len(dir) > best && within(dir, path) {
+			dirType, best = NoteType(typ), len(dir)
+		}
+	}
+	switch dirType {
+	case "", "periodic":
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		for _, p := range periodicNames {
+			if p.pattern.MatchString(name) {
+				return p.typ
+			}
+		}
+	}
+	return dirType
+}
+
+// within reports whether path is in dir or one of its subdirectories.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,41 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	dirs := map[string]string{
+		"/v/day":      "daily",
+		"/v/periodic": "periodic",
+		"/v/zettel":   "zettel",
+		"/v/projects": "project",
+		"/v/ideas":    "idea",
+	}
+	tests := []struct {
+		name string
+		path string
+		meta map[string]interface{}
+		want note.NoteType
+	}{
+		{"frontmatter", "/v/zettel/Go.md", map[string]interface{}{"type": "Project"}, note.TypeProject},
+		{"directory", "/v/zettel/Go.md", nil, note.TypeZettel},
+		{"subdirectory", "/v/projects/exo/plan.md", nil, note.TypeProject},
+		{"daily directory", "/v/day/2025-02-08.md", nil, note.TypeDaily},
+		{"periodic directory", "/v/periodic/2025-W06.md", nil, note.TypeWeekly},
+		{"periodic name", "/v/2025-Q1.md", nil, note.TypeQuarterly},
+		{"month", "/v/2025-02.md", nil, note.TypeMonthly},
+		{"dated zettel", "/v/zettel/2025-02-08.md", nil, note.TypeZettel},
+		{"periodic other", "/v/periodic/review.md", nil, "periodic"},
+		{"unknown", "/v/misc/Go.md", nil, ""},
+		{"directory itself", "/v/ideas", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, note.Detect(tt.path, tt.meta, dirs))
+		})
+	}
+}
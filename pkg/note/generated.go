@@ -0,0 +1,49 @@
+package note
+
+import "strings"
+
+// generatedMarkers returns the HTML comments enclosing the block of a note
+// generated under name, e.g. "<!-- exo:begin moc -->".
+func generatedMarkers(name string) (begin, end string) {
+	return "<!-- exo:begin " + name + " -->", "<!-- exo:end " + name + " -->"
+}
+
+// GeneratedBlock returns the text between the markers of the block generated
+// under name, and whether content has the block.
+func GeneratedBlock(content, name string) (string, bool) {
+	begin, end := generatedMarkers(name)
+	i := strings.Index(content, begin)
+	if i == -1 {
+		return "", false
+	}
+	rest := content[i+len(begin):]
+	j := strings.Index(rest, end)
+	if j == -1 {
+		return "", false
+	}
+	return strings.Trim(rest[:j], "\n"), true
+}
+
+// ReplaceGenerated replaces the text of the block generated under name with
+// text, keeping everything outside its markers, so that the block can be
+// regenerated without losing manual edits. A missing block is appended to the
+// end of the content.
+func ReplaceGenerated(content, name, text string) string {
+	begin, end := generatedMarkers(name)
+	block := begin + "\n"
+	if text = strings.Trim(text, "\n"); text != "" {
+		block += text + "\n"
+	}
+	block += end
+
+	if i := strings.Index(content, begin); i != -1 {
+		if j := strings.Index(content[i:], end); j != -1 {
+			return content[:i] + block + content[i+j+len(end):]
+		}
+	}
+	trimmed := strings.TrimRight(content, "\n")
+	if trimmed != "" {
+		trimmed += "\n\n"
+	}
+	return trimmed + block + "\n"
+}
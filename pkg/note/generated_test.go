@@ -0,0 +1,29 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReplaceGenerated(t *testing.T) {
+	content := note.ReplaceGenerated("# Go\n\nMy intro.\n", "moc", "- [[a]]\n")
+	assert.Equal(t, "# Go\n\nMy intro.\n\n<!-- exo:begin moc -->\n- [[a]]\n<!-- exo:end moc -->\n", content)
+
+	block, ok := note.GeneratedBlock(content, "moc")
+	assert.True(t, ok)
+	assert.Equal(t, "- [[a]]", block)
+	_, ok = note.GeneratedBlock(content, "view")
+	assert.False(t, ok)
+
+	// Edits around the block are kept when it is regenerated.
+	content += "\nSee also [[b]].\n"
+	content = note.ReplaceGenerated(content, "moc", "- [[a]]\n- [[c]]")
+	assert.Equal(t, "# Go\n\nMy intro.\n\n<!-- exo:begin moc -->\n- [[a]]\n- [[c]]\n<!-- exo:end moc -->\n\nSee also [[b]].\n", content)
+
+	content = note.ReplaceGenerated(content, "moc", "")
+	assert.Contains(t, content, "<!-- exo:begin moc -->\n<!-- exo:end moc -->\n")
+
+	assert.Equal(t, "<!-- exo:begin moc -->\n- x\n<!-- exo:end moc -->\n", note.ReplaceGenerated("", "moc", "- x"))
+}
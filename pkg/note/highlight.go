@@ -0,0 +1,32 @@
+package note
+
+import (
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// SourcesKey is the frontmatter key listing the sources quoted in a note.
+const SourcesKey = "sources"
+
+// FormatHighlight formats text as a Markdown blockquote attributed to attribution.
+func FormatHighlight(text, attribution string) string {
+	return Quote(text) + "\n>\n> — " + attribution
+}
+
+// AddSource records source in the "sources" frontmatter list of content, creating
+// the frontmatter if needed. Sources already listed are not duplicated.
+func AddSource(content, source string) (string, error) {
+	meta, body, err := frontmatter.Parse(content)
+	if err != nil {
+		return "", err
+	}
+	sources := frontmatter.Strings(meta, SourcesKey)
+	for _, s := range sources {
+		if strings.EqualFold(s, source) {
+			return content, nil
+		}
+	}
+	meta[SourcesKey] = append(sources, source)
+	return frontmatter.Render(meta, body)
+}
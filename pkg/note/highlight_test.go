@@ -0,0 +1,26 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatHighlight(t *testing.T) {
+	assert.Equal(t, "> Less is more.\n>\n> — [[Mies]]", note.FormatHighlight("Less is more.", "[[Mies]]"))
+}
+
+func TestAddSource(t *testing.T) {
+	content, err := note.AddSource("# Highlights\n", "[[Book]]")
+	require.NoError(t, err)
+	assert.Equal(t, "---\nsources:\n  - '[[Book]]'\n---\n# Highlights\n", content)
+
+	content, err = note.AddSource(content, "https://example.com")
+	require.NoError(t, err)
+	again, err := note.AddSource(content, "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, content, again)
+	assert.Contains(t, content, "  - https://example.com\n")
+}
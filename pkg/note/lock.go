@@ -0,0 +1,71 @@
+package note
+
+import (
+	"fmt"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// ErrLocked is returned when changing a note whose frontmatter sets locked: true.
+var ErrLocked = exoerrors.New(exoerrors.Conflict, "note is locked")
+
+// IsLocked reports whether the frontmatter of content sets locked: true.
+func IsLocked(content string) bool {
+	meta, _, err := frontmatter.Parse(content)
+	return err == nil && frontmatter.Bool(meta, "locked")
+}
+
+// LockingFileSystem wraps a FileSystem, rejecting with ErrLocked every write
+// to and deletion of a locked note. Opening a locked note in the editor is
+// allowed, so that it can be unlocked by hand.
+type LockingFileSystem struct {
+	fs.FileSystem
+}
+
+// GuardLocked returns a LockingFileSystem protecting the locked notes of fsys.
+func GuardLocked(fsys fs.FileSystem) *LockingFileSystem {
+	return &LockingFileSystem{FileSystem: fsys}
+}
+
+// WriteFile writes the file unless it is a locked note.
+func (l *LockingFileSystem) WriteFile(path string, content []byte) error {
+	if err := l.check("write", path); err != nil {
+		return err
+	}
+	return l.FileSystem.WriteFile(path, content)
+}
+
+// DeleteFile deletes the file unless it is a locked note.
+func (l *LockingFileSystem) DeleteFile(path string) error {
+	if err := l.check("delete", path); err != nil {
+		return err
+	}
+	return l.FileSystem.DeleteFile(path)
+}
+
+// AppendToFile appends to the file unless it is a locked note, if the wrapped
+// FileSystem supports appending.
+func (l *LockingFileSystem) AppendToFile(path, content string) error {
+	appender, ok := l.FileSystem.(interface {
+		AppendToFile(path, content string) error
+	})
+	if !ok {
+		return fmt.Errorf("cannot append to %s: not supported", path)
+	}
+	if err := l.check("append to", path); err != nil {
+		return err
+	}
+	return appender.AppendToFile(path, content)
+}
+
+// check fails with ErrLocked if the file at path is a locked note. Files that
+// cannot be read, such as new notes, are not locked.
+func (l *LockingFileSystem) check(action, path string) error {
+	content, err := l.FileSystem.ReadFile(path)
+	if err != nil || !IsLocked(string(content)) {
+		return nil
+	}
+	return fmt.Errorf("cannot %s %s: %w (unlock it or use --force)", action, path, ErrLocked)
+}
@@ -0,0 +1,42 @@
+package note_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLocked(t *testing.T) {
+	assert.True(t, note.IsLocked("---\nlocked: true\n---\n# Ref\n"))
+	assert.False(t, note.IsLocked("---\nlocked: false\n---\n# Ref\n"))
+	assert.False(t, note.IsLocked("# locked: true\n"))
+}
+
+func TestLockingFileSystem(t *testing.T) {
+	dir := t.TempDir()
+	locked := filepath.Join(dir, "locked.md")
+	open := filepath.Join(dir, "open.md")
+	require.NoError(t, os.WriteFile(locked, []byte("---\nlocked: true\n---\n# Ref\n"), 0644))
+	require.NoError(t, os.WriteFile(open, []byte("# Open\n"), 0644))
+	lfs := note.GuardLocked(fs.NewOSFileSystem())
+
+	err := lfs.WriteFile(locked, []byte("changed"))
+	assert.ErrorIs(t, err, note.ErrLocked)
+	assert.Equal(t, exoerrors.Conflict, exoerrors.CodeOf(err))
+	assert.ErrorIs(t, lfs.AppendToFile(locked, "more"), note.ErrLocked)
+	assert.ErrorIs(t, lfs.DeleteFile(locked), note.ErrLocked)
+	content, err := os.ReadFile(locked)
+	require.NoError(t, err)
+	assert.Equal(t, "---\nlocked: true\n---\n# Ref\n", string(content))
+
+	require.NoError(t, lfs.WriteFile(open, []byte("changed")))
+	require.NoError(t, lfs.WriteFile(filepath.Join(dir, "new.md"), []byte("# New\n")))
+	require.NoError(t, lfs.DeleteFile(open))
+	assert.NoFileExists(t, open)
+}
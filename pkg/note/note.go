@@ -1,15 +1,20 @@
 package note
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
 	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/scan"
 	"github.com/a-kostevski/exo/pkg/templates"
 )
 
@@ -29,6 +34,7 @@ type Note interface {
 	Open() error
 
 	// Metadata accessors
+	ID() string
 	Title() string
 	Path() string
 	Created() time.Time
@@ -51,10 +57,20 @@ type BaseNote struct {
 	fileName     string
 	subDir       string
 	templateName string
+	noteType     string
 
 	created  time.Time
 	modified time.Time
 
+	// disk is the file of the note as last loaded or saved, which Save
+	// checks for changes made meanwhile; conflictPath is where the note is
+	// saved instead, once it was copied there for such changes.
+	disk         *diskState
+	conflictPath string
+
+	// ctx is the context templates are processed and the note saved in.
+	ctx context.Context
+
 	// Dependencies (injected via the constructor)
 	Config config.Config
 	TM     templates.TemplateManager
@@ -69,7 +85,7 @@ type NoteOption func(*BaseNote) error
 // Additional options (like setting the subdirectory, filename, template, etc.) can be provided.
 func NewBaseNote(title string, cfg config.Config, tm templates.TemplateManager, logger logger.Logger, fs fs.FileSystem, opts ...NoteOption) (Note, error) {
 	if title == "" {
-		return nil, errors.New("title cannot be empty")
+		return nil, exoerrors.New(exoerrors.Validation, "title cannot be empty")
 	}
 
 	n := &BaseNote{
@@ -85,13 +101,13 @@ func NewBaseNote(title string, cfg config.Config, tm templates.TemplateManager,
 	// Apply functional options to set additional attributes.
 	for _, opt := range opts {
 		if err := opt(n); err != nil {
-			return nil, fmt.Errorf("failed to apply option: %w", err)
+			return nil, exoerrors.Wrap(exoerrors.Validation, fmt.Errorf("failed to apply option: %w", err))
 		}
 	}
 
 	// updatePath must be called if both subDir and fileName are set.
 	if n.subDir == "" || n.fileName == "" {
-		return nil, errors.New("subdirectory and filename must be provided")
+		return nil, exoerrors.New(exoerrors.Validation, "subdirectory and filename must be provided")
 	}
 	if err := n.updatePath(); err != nil {
 		return nil, err
@@ -133,6 +149,27 @@ func WithTemplateName(templateName string) NoteOption {
 	}
 }
 
+// WithType sets the note type, e.g. "zettel", whose schema the frontmatter
+// of the note is checked against on Save. A "type" frontmatter field takes
+// precedence.
+func WithType(noteType string) NoteOption {
+	return func(n *BaseNote) error {
+		n.noteType = noteType
+		return nil
+	}
+}
+
+// WithID sets the note identifier.
+func WithID(id string) NoteOption {
+	return func(n *BaseNote) error {
+		if id == "" {
+			return errors.New("id cannot be empty")
+		}
+		n.id = id
+		return nil
+	}
+}
+
 // WithContent sets initial content.
 func WithContent(content string) NoteOption {
 	return func(n *BaseNote) error {
@@ -141,6 +178,27 @@ func WithContent(content string) NoteOption {
 	}
 }
 
+// WithContext sets the context in which the template of the note is applied
+// and the note saved, which give up once it is done.
+func WithContext(ctx context.Context) NoteOption {
+	return func(n *BaseNote) error {
+		if ctx == nil {
+			return errors.New("context cannot be nil")
+		}
+		n.ctx = ctx
+		return nil
+	}
+}
+
+// Context returns the context of the note, context.Background() if none was
+// set with WithContext.
+func (n *BaseNote) Context() context.Context {
+	if n.ctx == nil {
+		return context.Background()
+	}
+	return n.ctx
+}
+
 // updatePath calculates the full file path based on the configuration, subdirectory, and filename.
 func (n *BaseNote) updatePath() error {
 	n.path = filepath.Join(n.Config.Dir.DataHome, n.subDir, n.fileName)
@@ -163,12 +221,101 @@ func (n *BaseNote) Save() error {
 	if n.path == "" {
 		return errors.New("note path not set")
 	}
+	if err := n.Context().Err(); err != nil {
+		return fmt.Errorf("failed to save %s: %w", n.path, err)
+	}
+	path, err := n.resolveConflict()
+	if err != nil {
+		return err
+	}
+	if n.Config.General.Timestamps {
+		if err := n.stampTimes(time.Now()); err != nil {
+			return err
+		}
+	}
+	if err := n.checkSchema(); err != nil {
+		return err
+	}
 	// Ensure the parent directory exists.
-	if err := n.FS.EnsureDirectoryExists(n.path); err != nil {
+	if err := n.FS.EnsureDirectoryExists(path); err != nil {
 		return err
 	}
-	if err := os.WriteFile(n.path, []byte(n.content), 0644); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", n.path, err)
+	if err := n.FS.WriteFile(path, []byte(n.content)); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", path, err)
+	}
+	if path == n.path {
+		n.record(n.content)
+	}
+	return nil
+}
+
+// stampTimes sets the modified field of the frontmatter of the note to now.
+// When the frontmatter has no created (or date) field, the one of the file
+// being replaced is kept or, for a new note, the creation time of the note is
+// added. Notes without frontmatter are left as they are.
+func (n *BaseNote) stampTimes(now time.Time) error {
+	if raw, _ := frontmatter.Split(n.content); raw == "" {
+		return nil
+	}
+	meta, _, err := frontmatter.Parse(n.content)
+	if err != nil {
+		return exoerrors.Wrap(exoerrors.Validation, err)
+	}
+	content := n.content
+	if meta["created"] == nil && meta["date"] == nil {
+		created := ""
+		if !n.Exists() {
+			created = n.created.Format(time.RFC3339)
+		} else if old, err := n.FS.ReadFile(n.path); err == nil {
+			if oldMeta, _, err := frontmatter.Parse(string(old)); err == nil {
+				created = timeString(oldMeta["created"])
+			}
+		}
+		if created != "" {
+			if content, err = frontmatter.Set(content, "created", created); err != nil {
+				return err
+			}
+		}
+	}
+	if content, err = frontmatter.Set(content, "modified", now.Format(time.RFC3339)); err != nil {
+		return err
+	}
+	n.content, n.modified = content, now
+	return nil
+}
+
+// timeString formats a frontmatter time value as it was written: dates as
+// YYYY-MM-DD and other times in RFC 3339.
+func timeString(v interface{}) string {
+	switch t := v.(type) {
+	case time.Time:
+		if t.Equal(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())) {
+			return t.Format("2006-01-02")
+		}
+		return t.Format(time.RFC3339)
+	case string:
+		return t
+	}
+	return ""
+}
+
+// checkSchema checks the frontmatter of the note against the schema of its
+// type, if one is configured.
+func (n *BaseNote) checkSchema() error {
+	meta, _, err := frontmatter.Parse(n.content)
+	noteType := strings.ToLower(frontmatter.String(meta, "type"))
+	if noteType == "" {
+		noteType = strings.ToLower(n.noteType)
+	}
+	schema := n.Config.Schemas()[noteType]
+	if len(schema) == 0 {
+		return nil
+	}
+	if err != nil {
+		return exoerrors.Wrap(exoerrors.Validation, err)
+	}
+	if problems := schema.Check(meta); len(problems) > 0 {
+		return exoerrors.New(exoerrors.Validation, "%s note %q does not match its schema: %s", noteType, n.title, strings.Join(problems, "; "))
 	}
 	return nil
 }
@@ -182,6 +329,12 @@ func (n *BaseNote) Load() error {
 		return fmt.Errorf("failed to read file %s: %w", n.path, err)
 	}
 	n.content = string(content)
+	n.record(n.content)
+	// Take the times kept in the frontmatter over the ones of this instance.
+	if info, err := os.Stat(n.path); err == nil {
+		loaded := scan.ParseNote(n.path, n.content, info.ModTime())
+		n.created, n.modified = loaded.Created, loaded.Modified
+	}
 	return nil
 }
 
@@ -189,7 +342,7 @@ func (n *BaseNote) Delete() error {
 	if n.path == "" {
 		return errors.New("note path not set")
 	}
-	if err := os.Remove(n.path); err != nil {
+	if err := n.FS.DeleteFile(n.path); err != nil {
 		return fmt.Errorf("failed to delete file %s: %w", n.path, err)
 	}
 	return nil
@@ -204,11 +357,15 @@ func (n *BaseNote) Open() error {
 		return errors.New("note path not set")
 	}
 	if !n.Exists() {
-		return fmt.Errorf("note file does not exist: %s", n.path)
+		return exoerrors.New(exoerrors.NotFound, "note file does not exist: %s", n.path)
 	}
 	return n.FS.OpenInEditor(n.path, n.Config.General.Editor)
 }
 
+func (n *BaseNote) ID() string {
+	return n.id
+}
+
 func (n *BaseNote) Title() string {
 	return n.title
 }
@@ -227,10 +384,10 @@ func (n *BaseNote) Modified() time.Time {
 
 func (n *BaseNote) Validate() error {
 	if n.title == "" {
-		return errors.New("title is required")
+		return exoerrors.New(exoerrors.Validation, "title is required")
 	}
 	if n.path == "" {
-		return errors.New("path is required")
+		return exoerrors.New(exoerrors.Validation, "path is required")
 	}
 	return nil
 }
@@ -239,12 +396,18 @@ func (n *BaseNote) String() string {
 	return fmt.Sprintf("Note{ID: %s, Title: %s}", n.id, n.title)
 }
 
-// ApplyTemplate uses the template manager to process a template and sets the note content.
+// ApplyTemplate uses the template manager to process a template and sets the
+// note content, giving up once the context of the note is done.
 func (n *BaseNote) ApplyTemplate(data interface{}) error {
+	return n.ApplyTemplateWithContext(n.Context(), data)
+}
+
+// ApplyTemplateWithContext is ApplyTemplate giving up once ctx is done.
+func (n *BaseNote) ApplyTemplateWithContext(ctx context.Context, data interface{}) error {
 	if n.templateName == "" {
 		return errors.New("no template name set")
 	}
-	content, err := n.TM.ProcessTemplate(n.templateName, data)
+	content, err := n.TM.ProcessTemplateWithContext(ctx, n.templateName, data)
 	if err != nil {
 		return fmt.Errorf("failed to process template: %w", err)
 	}
@@ -1,9 +1,14 @@
 package note_test
 
 import (
+	"context"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
 	"github.com/a-kostevski/exo/pkg/note"
 	"github.com/a-kostevski/exo/pkg/testutil"
 	"github.com/stretchr/testify/assert"
@@ -37,3 +42,173 @@ func TestNewBaseNote_Failure_MissingOptions(t *testing.T) {
 	_, err := note.NewBaseNote("Test Note", cfg, dtm, dl, dfs)
 	require.Error(t, err)
 }
+
+func TestApplyTemplateWithContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	created, err := note.NewBaseNote("Test Note", cfg, dtm, dl, dfs,
+		note.WithSubDir("notes"),
+		note.WithFileName("test.md"),
+		note.WithTemplateName("default"),
+		note.WithContent("Initial Content"),
+	)
+	require.NoError(t, err)
+	n := created.(*note.BaseNote)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, n.ApplyTemplateWithContext(ctx, map[string]interface{}{"Title": "Test Note"}), context.Canceled)
+	assert.Equal(t, "Initial Content", n.Content(), "the content is kept when the template is not applied")
+
+	require.NoError(t, n.ApplyTemplate(map[string]interface{}{"Title": "Test Note"}))
+	assert.Equal(t, "Template: Test Note", n.Content())
+}
+
+func TestWithContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	ctx, cancel := context.WithCancel(context.Background())
+	created, err := note.NewBaseNote("Test Note", cfg, dtm, dl, dfs,
+		note.WithContext(ctx),
+		note.WithSubDir("notes"),
+		note.WithFileName("test.md"),
+		note.WithTemplateName("default"),
+	)
+	require.NoError(t, err)
+	n := created.(*note.BaseNote)
+	cancel()
+
+	assert.ErrorIs(t, n.ApplyTemplate(map[string]interface{}{"Title": "Test Note"}), context.Canceled)
+	assert.ErrorIs(t, n.Save(), context.Canceled)
+	assert.False(t, n.Exists(), "the note is not saved once its context is done")
+}
+
+func TestSave_Schema(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Schema = map[string]map[string]config.FieldConfig{
+		"book": {"isbn": {Type: "string", Required: true}, "rating": {Type: "number"}},
+	}
+	n, err := note.NewBaseNote("Dune", cfg, dtm, dl, dfs,
+		note.WithSubDir("literature"),
+		note.WithFileName("Dune.md"),
+		note.WithType("book"),
+		note.WithContent("---\nrating: five\n---\n# Dune\n"),
+	)
+	require.NoError(t, err)
+	err = n.Save()
+	assert.ErrorContains(t, err, `missing required field "isbn"; field "rating" is not a number`)
+	assert.False(t, n.Exists(), "a note not matching its schema is not written")
+
+	require.NoError(t, n.SetContent("---\nisbn: 9780441013593\nrating: 5\n---\n# Dune\n"))
+	require.NoError(t, n.Save())
+
+	// The type in the frontmatter takes precedence.
+	require.NoError(t, n.SetContent("---\ntype: article\n---\n# Dune\n"))
+	require.NoError(t, n.Save())
+}
+
+func TestSave_Timestamps(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.General.Timestamps = true
+	newNote := func(content string) note.Note {
+		n, err := note.NewBaseNote("Test Note", cfg, dtm, dl, dfs,
+			note.WithSubDir("notes"),
+			note.WithFileName("test.md"),
+			note.WithContent(content),
+		)
+		require.NoError(t, err)
+		return n
+	}
+
+	n := newNote("---\ntags: [go]\n---\n# Test Note\n")
+	require.NoError(t, n.Save())
+	meta, _, err := frontmatter.Parse(n.Content())
+	require.NoError(t, err)
+	assert.Equal(t, n.Created().Format(time.RFC3339), meta["created"])
+	assert.Equal(t, n.Modified().Format(time.RFC3339), meta["modified"])
+
+	// Replacing the content keeps the creation time of the file.
+	require.NoError(t, os.WriteFile(n.Path(), []byte("---\ncreated: 2024-01-02\n---\n# Test Note\n"), 0644))
+	n = newNote("---\ntags: [go]\n---\n# Test Note\n")
+	require.NoError(t, n.Save())
+	meta, _, err = frontmatter.Parse(n.Content())
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02", meta["created"])
+	assert.NotEmpty(t, meta["modified"])
+
+	loaded := newNote("")
+	require.NoError(t, loaded.Load())
+	assert.Equal(t, 2024, loaded.Created().Year())
+	assert.Equal(t, n.Modified().Unix(), loaded.Modified().Unix())
+
+	// Notes without frontmatter are written as they are.
+	n = newNote("# Test Note\n")
+	require.NoError(t, n.Save())
+	assert.Equal(t, "# Test Note\n", n.Content())
+}
+
+func TestSave_Conflict(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	base := "# Plan\n\nmorning\nnoon\nevening\n"
+	// load returns the note, loaded, and changes its file on disk as a sync
+	// client would.
+	load := func(policy string, onDisk string) note.Note {
+		cfg.General.OnConflict = policy
+		n, err := note.NewBaseNote("Plan", cfg, dtm, dl, dfs,
+			note.WithSubDir("notes"),
+			note.WithFileName("plan.md"),
+		)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(n.Path()), 0755))
+		require.NoError(t, os.WriteFile(n.Path(), []byte(base), 0644))
+		require.NoError(t, n.Load())
+		require.NoError(t, os.WriteFile(n.Path(), []byte(onDisk), 0644))
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(n.Path(), later, later))
+		return n
+	}
+	read := func(path string) string {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	n := load("fail", "# Plan\n\nmorning\nnoon\nnight\n")
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nnoon\nevening\n"))
+	assert.ErrorIs(t, n.Save(), note.ErrConflict)
+	assert.Equal(t, "# Plan\n\nmorning\nnoon\nnight\n", read(n.Path()), "the changes on disk are kept")
+
+	// Files touched without changes are not conflicts.
+	n = load("fail", base)
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nnoon\nevening\n"))
+	require.NoError(t, n.Save())
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nlunch\nevening\n"))
+	require.NoError(t, n.Save(), "saved changes are not conflicts")
+
+	n = load("merge", "# Plan\n\nmorning\nnoon\nnight\n")
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nnoon\nevening\n"))
+	require.NoError(t, n.Save())
+	assert.Equal(t, "# Plan\n\nbreakfast\nnoon\nnight\n", read(n.Path()))
+
+	n = load("merge", "# Plan\n\nbrunch\nnoon\nevening\n")
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nnoon\nevening\n"))
+	require.NoError(t, n.Save())
+	assert.Equal(t, "# Plan\n\n<<<<<<< exo\nbreakfast\n=======\nbrunch\n>>>>>>> disk\nnoon\nevening\n", read(n.Path()))
+
+	n = load("copy", "# Plan\n\nmorning\nnoon\nnight\n")
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nnoon\nevening\n"))
+	require.NoError(t, n.Save())
+	assert.Equal(t, "# Plan\n\nmorning\nnoon\nnight\n", read(n.Path()))
+	assert.Equal(t, "# Plan\n\nbreakfast\nnoon\nevening\n", read(n.Path()+".conflict"))
+	n = load("copy", "# Plan\n")
+	require.NoError(t, n.Save())
+	assert.Equal(t, base, read(n.Path()+".conflict.2"), "existing copies are kept")
+
+	n = load("overwrite", "# Plan\n\nmorning\nnoon\nnight\n")
+	require.NoError(t, n.SetContent("# Plan\n\nbreakfast\nnoon\nevening\n"))
+	require.NoError(t, n.Save())
+	assert.Equal(t, "# Plan\n\nbreakfast\nnoon\nevening\n", read(n.Path()))
+}
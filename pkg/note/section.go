@@ -0,0 +1,35 @@
+package note
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineRange returns lines first through last (1-based, inclusive) of content.
+// last is clamped to the number of lines.
+func LineRange(content string, first, last int) (string, error) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if first < 1 || last < first {
+		return "", fmt.Errorf("invalid line range %d-%d", first, last)
+	}
+	if first > len(lines) {
+		return "", fmt.Errorf("line %d is past the end of the note (%d lines)", first, len(lines))
+	}
+	if last > len(lines) {
+		last = len(lines)
+	}
+	return strings.Join(lines[first-1:last], "\n"), nil
+}
+
+// Quote formats text as a Markdown blockquote.
+func Quote(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ">"
+		} else {
+			lines[i] = "> " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
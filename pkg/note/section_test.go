@@ -0,0 +1,30 @@
+package note_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineRange(t *testing.T) {
+	content := "one\ntwo\nthree\nfour\n"
+
+	got, err := note.LineRange(content, 2, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "two\nthree", got)
+
+	got, err = note.LineRange(content, 3, 10)
+	require.NoError(t, err)
+	assert.Equal(t, "three\nfour", got)
+
+	_, err = note.LineRange(content, 5, 6)
+	assert.Error(t, err)
+	_, err = note.LineRange(content, 3, 2)
+	assert.Error(t, err)
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "> a\n>\n> b", note.Quote("a\n\nb\n"))
+}
@@ -0,0 +1,68 @@
+// Package notify shows notifications, on the desktop when a notification
+// program is available.
+package notify
+
+import (
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+)
+
+// Notifier shows notifications.
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// Command notifies by running a program, such as notify-send.
+type Command struct {
+	Name string
+	// Args returns the arguments of the program for a notification.
+	Args func(title, message string) []string
+}
+
+// Notify runs the program.
+func (c Command) Notify(title, message string) error {
+	if out, err := exec.Command(c.Name, c.Args(title, message)...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s failed: %w: %s", c.Name, err, out)
+	}
+	return nil
+}
+
+// Bell notifies by ringing the terminal bell and writing the notification to W.
+type Bell struct {
+	W io.Writer
+}
+
+// Notify writes the notification.
+func (b Bell) Notify(title, message string) error {
+	_, err := fmt.Fprintf(b.W, "\a%s: %s\n", title, message)
+	return err
+}
+
+// Nop discards notifications.
+type Nop struct{}
+
+// Notify does nothing.
+func (Nop) Notify(title, message string) error {
+	return nil
+}
+
+// Desktop returns a Notifier showing desktop notifications with notify-send,
+// or osascript on macOS, or fallback when neither is available.
+func Desktop(fallback Notifier) Notifier {
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("osascript"); err == nil {
+			return Command{Name: "osascript", Args: func(title, message string) []string {
+				return []string{"-e", fmt.Sprintf("display notification %q with title %q", message, title)}
+			}}
+		}
+		return fallback
+	}
+	if _, err := exec.LookPath("notify-send"); err == nil {
+		return Command{Name: "notify-send", Args: func(title, message string) []string {
+			return []string{title, message}
+		}}
+	}
+	return fallback
+}
@@ -0,0 +1,33 @@
+package notify_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	n := notify.Command{Name: "sh", Args: func(title, message string) []string {
+		return []string{"-c", `printf '%s|%s' "$1" "$2" > "$3"`, "sh", title, message, out}
+	}}
+	require.NoError(t, n.Notify("Done", "Write report"))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Equal(t, "Done|Write report", string(data))
+
+	failing := notify.Command{Name: "sh", Args: func(string, string) []string { return []string{"-c", "echo nope >&2; exit 1"} }}
+	assert.ErrorContains(t, failing.Notify("a", "b"), "nope")
+}
+
+func TestBell(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, notify.Bell{W: &buf}.Notify("Done", "Write report"))
+	assert.Equal(t, "\aDone: Write report\n", buf.String())
+	assert.NoError(t, notify.Nop{}.Notify("Done", "Write report"))
+}
@@ -0,0 +1,505 @@
+// Package notion imports the Markdown and CSV export of a Notion workspace as
+// notes: pages become notes, databases become notes listing their rows, and
+// links between pages become wikilinks.
+package notion
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// AttachmentsDir is the directory, next to the imported notes, images and
+// other files of pages are copied to.
+const AttachmentsDir = "attachments"
+
+// DatabaseMode is how the properties of database rows are imported.
+type DatabaseMode string
+
+const (
+	// Frontmatter moves the properties of each row to the frontmatter of its
+	// note; the database note lists the rows.
+	Frontmatter DatabaseMode = "frontmatter"
+	// Table keeps the row notes as exported; the database note holds a table
+	// of the rows and their properties.
+	Table DatabaseMode = "table"
+)
+
+// Options configures an import.
+type Options struct {
+	// Dest is the directory notes are written to, e.g. the inbox.
+	Dest string
+	// Databases is how database rows are imported; it defaults to Frontmatter.
+	Databases DatabaseMode
+}
+
+// Result summarizes an import.
+type Result struct {
+	// Notes are the paths of the notes written, databases included.
+	Notes       []string
+	Databases   int
+	Attachments int
+}
+
+var (
+	// idSuffix is the ID Notion appends to the names of exported files.
+	idSuffix    = regexp.MustCompile(`\s+[0-9a-f]{32}$`)
+	linkPattern = regexp.MustCompile(`(!?)\[([^\]]*)\]\(([^)]+)\)`)
+	// propertyLine is a "Name: value" line listing a property under the title
+	// of a database row.
+	propertyLine = regexp.MustCompile(`^([^:]+):\s?(.*)$`)
+)
+
+// file is a file of the export, by its path in the archive.
+type file struct {
+	path string
+	data []byte
+}
+
+// Import imports the Notion export archive at zipPath. Archives nested in it,
+// as Notion splits large exports, are imported too.
+func Import(zipPath string, opts Options) (*Result, error) {
+	r, err := zip.OpenReader(zipPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export: %w", err)
+	}
+	defer r.Close()
+	files, err := readZip(&r.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return importFiles(files, opts)
+}
+
+// readZip returns the files of the archive, those of nested archives included,
+// skipping directories and hidden files.
+func readZip(r *zip.Reader) ([]file, error) {
+	var files []file
+	for _, f := range r.File {
+		name := path.Clean(strings.ReplaceAll(f.Name, "\\", "/"))
+		if f.FileInfo().IsDir() || hidden(name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		data, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if strings.EqualFold(path.Ext(name), ".zip") {
+			nested, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+			if err != nil {
+				return nil, fmt.Errorf("failed to open %s: %w", name, err)
+			}
+			inner, err := readZip(nested)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, inner...)
+			continue
+		}
+		files = append(files, file{path: name, data: data})
+	}
+	return files, nil
+}
+
+// hidden reports whether a segment of name starts with a dot, or is the
+// __MACOSX metadata of archives made on macOS.
+func hidden(name string) bool {
+	for _, segment := range strings.Split(name, "/") {
+		if strings.HasPrefix(segment, ".") || segment == "__MACOSX" {
+			return true
+		}
+	}
+	return false
+}
+
+// CleanName returns name, a file or directory name of the export, without the
+// ID Notion appends to it and its extension, e.g. "Reading list" for
+// "Reading list 0a1b…9f_all.csv".
+func CleanName(name string) string {
+	stem := strings.TrimSuffix(name, path.Ext(name))
+	stem = strings.TrimSuffix(stem, "_all")
+	return strings.TrimSpace(idSuffix.ReplaceAllString(stem, ""))
+}
+
+// database is a database of the export: its rows, read from its CSV file, and
+// the pages of the rows, in the directory named as the file.
+type database struct {
+	file   file
+	header []string
+	rows   [][]string
+	// pages maps the lower-cased titles of rows to their pages.
+	pages map[string]*page
+}
+
+// page is a page of the export.
+type page struct {
+	file file
+	// name is the name of the note, without extension.
+	name string
+	// row is the database row the page is, if any.
+	db  *database
+	row []string
+}
+
+// importer holds the state of an import.
+type importer struct {
+	opts        Options
+	pages       map[string]*page
+	databases   map[string]*database
+	attachments map[string]string // archive path -> name under AttachmentsDir
+	taken       map[string]bool   // lower-cased names of notes in Dest
+}
+
+func importFiles(files []file, opts Options) (*Result, error) {
+	if opts.Databases == "" {
+		opts.Databases = Frontmatter
+	}
+	if opts.Databases != Frontmatter && opts.Databases != Table {
+		return nil, fmt.Errorf("unknown database mode %q (want frontmatter or table)", opts.Databases)
+	}
+	im := &importer{
+		opts:        opts,
+		pages:       make(map[string]*page),
+		databases:   make(map[string]*database),
+		attachments: make(map[string]string),
+		taken:       make(map[string]bool),
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].path < files[j].path })
+
+	// Prefer the CSV file of all the rows of a database over the one of its
+	// current view.
+	csvFiles := make(map[string]file)
+	for _, f := range files {
+		if strings.EqualFold(path.Ext(f.path), ".csv") {
+			key := strings.TrimSuffix(strings.TrimSuffix(f.path, path.Ext(f.path)), "_all")
+			if _, ok := csvFiles[key]; !ok || strings.HasSuffix(strings.TrimSuffix(f.path, path.Ext(f.path)), "_all") {
+				csvFiles[key] = f
+			}
+		}
+	}
+	for key, f := range csvFiles {
+		db, err := readDatabase(f)
+		if err != nil {
+			return nil, err
+		}
+		im.databases[key] = db
+	}
+
+	existing, err := os.ReadDir(opts.Dest)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range existing {
+		im.taken[strings.ToLower(strings.TrimSuffix(e.Name(), ".md"))] = true
+	}
+	takenAttachments := make(map[string]bool)
+	if existing, err := os.ReadDir(filepath.Join(opts.Dest, AttachmentsDir)); err == nil {
+		for _, e := range existing {
+			takenAttachments[strings.ToLower(e.Name())] = true
+		}
+	}
+
+	for _, f := range files {
+		switch strings.ToLower(path.Ext(f.path)) {
+		case ".csv":
+		case ".md":
+			p := &page{file: f, name: im.uniqueName(safeName(CleanName(path.Base(f.path))))}
+			im.pages[f.path] = p
+			// Rows are pages in the directory named as the CSV file of their
+			// database.
+			if db, ok := im.databases[path.Dir(f.path)]; ok {
+				db.pages[strings.ToLower(CleanName(path.Base(f.path)))] = p
+			}
+		default:
+			name := path.Base(f.path)
+			ext := path.Ext(name)
+			stem := strings.TrimSuffix(name, ext)
+			for i := 2; takenAttachments[strings.ToLower(name)]; i++ {
+				name = fmt.Sprintf("%s %d%s", stem, i, ext)
+			}
+			takenAttachments[strings.ToLower(name)] = true
+			im.attachments[f.path] = name
+		}
+	}
+	for _, db := range im.databases {
+		for _, row := range db.rows {
+			if p := db.pages[strings.ToLower(row[0])]; p != nil {
+				p.db, p.row = db, row
+			}
+		}
+	}
+	dbNames := make(map[string]string)
+	dbKeys := make([]string, 0, len(im.databases))
+	for key := range im.databases {
+		dbKeys = append(dbKeys, key)
+	}
+	sort.Strings(dbKeys)
+	for _, key := range dbKeys {
+		dbNames[key] = im.uniqueName(safeName(CleanName(path.Base(key))))
+	}
+
+	if err := os.MkdirAll(opts.Dest, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+	result := &Result{}
+	paths := make([]string, 0, len(im.pages))
+	for p := range im.pages {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		pg := im.pages[p]
+		content, err := im.convertPage(pg, dbNames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s: %w", p, err)
+		}
+		dest, err := im.write(pg.name, content)
+		if err != nil {
+			return nil, err
+		}
+		result.Notes = append(result.Notes, dest)
+	}
+	for _, key := range dbKeys {
+		dest, err := im.write(dbNames[key], im.databaseNote(dbNames[key], im.databases[key]))
+		if err != nil {
+			return nil, err
+		}
+		result.Notes = append(result.Notes, dest)
+		result.Databases++
+	}
+	for _, f := range files {
+		name, ok := im.attachments[f.path]
+		if !ok {
+			continue
+		}
+		dest := filepath.Join(opts.Dest, AttachmentsDir, name)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(dest, f.data, 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+		result.Attachments++
+	}
+	return result, nil
+}
+
+// readDatabase reads the rows of the database exported to the CSV file f.
+func readDatabase(f file) (*database, error) {
+	data := bytes.TrimPrefix(f.data, []byte("\xef\xbb\xbf"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", f.path, err)
+	}
+	db := &database{file: f, pages: make(map[string]*page)}
+	if len(records) > 0 {
+		db.header = records[0]
+		for _, row := range records[1:] {
+			if len(row) > 0 && strings.TrimSpace(row[0]) != "" {
+				db.rows = append(db.rows, row)
+			}
+		}
+	}
+	return db, nil
+}
+
+// uniqueName returns name, numbered if a note of that name exists or was
+// imported before.
+func (im *importer) uniqueName(name string) string {
+	if name == "" {
+		name = "Untitled"
+	}
+	unique := name
+	for i := 2; im.taken[strings.ToLower(unique)]; i++ {
+		unique = fmt.Sprintf("%s %d", name, i)
+	}
+	im.taken[strings.ToLower(unique)] = true
+	return unique
+}
+
+// safeName replaces the characters file names cannot hold.
+func safeName(name string) string {
+	return strings.TrimSpace(strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(name))
+}
+
+// write writes content to the note called name in Dest.
+func (im *importer) write(name, content string) (string, error) {
+	dest := filepath.Join(im.opts.Dest, name+".md")
+	if err := os.WriteFile(dest, []byte(content), 0644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", dest, err)
+	}
+	return dest, nil
+}
+
+// convertPage returns the note of p: its links converted and, for rows in
+// frontmatter mode, its properties moved to the frontmatter.
+func (im *importer) convertPage(p *page, dbNames map[string]string) (string, error) {
+	body := strings.ReplaceAll(string(p.file.data), "\r\n", "\n")
+	body = im.convertLinks(body, path.Dir(p.file.path), dbNames)
+	if p.db == nil || im.opts.Databases != Frontmatter {
+		return body, nil
+	}
+	meta := make(map[string]interface{})
+	for i, name := range p.db.header {
+		if i == 0 || i >= len(p.row) || strings.TrimSpace(p.row[i]) == "" {
+			continue
+		}
+		key := propertyKey(name)
+		if key == "tags" {
+			var tags []string
+			for _, tag := range strings.Split(p.row[i], ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					tags = append(tags, tag)
+				}
+			}
+			meta[key] = tags
+			continue
+		}
+		meta[key] = strings.TrimSpace(p.row[i])
+	}
+	return frontmatter.Render(meta, stripProperties(body, p.db.header))
+}
+
+// propertyKey returns the frontmatter key of a database property, e.g.
+// "due_date" for "Due Date".
+func propertyKey(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "_")
+}
+
+// stripProperties removes the lines listing the properties of a database row
+// Notion writes under its title.
+func stripProperties(body string, header []string) string {
+	names := make(map[string]bool, len(header))
+	for _, name := range header {
+		names[strings.TrimSpace(name)] = true
+	}
+	lines := strings.Split(body, "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start < len(lines) && strings.HasPrefix(lines[start], "# ") {
+		start++
+	}
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	end := start
+	for end < len(lines) {
+		m := propertyLine.FindStringSubmatch(lines[end])
+		if m == nil || !names[strings.TrimSpace(m[1])] {
+			break
+		}
+		end++
+	}
+	if end == start {
+		return body
+	}
+	rest := lines[end:]
+	for len(rest) > 0 && strings.TrimSpace(rest[0]) == "" {
+		rest = rest[1:]
+	}
+	kept := append(append([]string{}, lines[:start]...), rest...)
+	return strings.Join(kept, "\n")
+}
+
+// convertLinks turns the Markdown links of a page in dir to other pages and
+// databases into wikilinks, and the ones to attachments into links to their
+// copies.
+func (im *importer) convertLinks(body, dir string, dbNames map[string]string) string {
+	return linkPattern.ReplaceAllStringFunc(body, func(m string) string {
+		sub := linkPattern.FindStringSubmatch(m)
+		bang, label, target := sub[1], sub[2], sub[3]
+		if strings.Contains(target, "://") || strings.HasPrefix(target, "mailto:") || strings.HasPrefix(target, "#") {
+			return m
+		}
+		decoded, err := url.PathUnescape(target)
+		if err != nil {
+			return m
+		}
+		resolved := path.Join(dir, decoded)
+		name := ""
+		if p, ok := im.pages[resolved]; ok {
+			name = p.name
+		} else if n, ok := dbNames[strings.TrimSuffix(strings.TrimSuffix(resolved, path.Ext(resolved)), "_all")]; ok && strings.EqualFold(path.Ext(resolved), ".csv") {
+			name = n
+		}
+		if name != "" {
+			if label == "" || label == name {
+				return "[[" + name + "]]"
+			}
+			return "[[" + name + "|" + label + "]]"
+		}
+		if attachment, ok := im.attachments[resolved]; ok {
+			return bang + "[" + label + "](" + AttachmentsDir + "/" + url.PathEscape(attachment) + ")"
+		}
+		return m
+	})
+}
+
+// databaseNote returns the note of db, called name.
+func (im *importer) databaseNote(name string, db *database) string {
+	var sb strings.Builder
+	sb.WriteString("# " + name + "\n\n")
+	rowLink := func(row []string) string {
+		p := db.pages[strings.ToLower(row[0])]
+		switch {
+		case p == nil:
+			return row[0]
+		case p.name == row[0]:
+			return "[[" + p.name + "]]"
+		default:
+			return "[[" + p.name + "|" + row[0] + "]]"
+		}
+	}
+	if im.opts.Databases == Frontmatter {
+		for _, row := range db.rows {
+			sb.WriteString("- " + rowLink(row) + "\n")
+		}
+		return sb.String()
+	}
+	if len(db.header) == 0 {
+		return sb.String()
+	}
+	cell := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(strings.TrimSpace(s), "|", `\|`), "\n", " ")
+	}
+	headers := make([]string, len(db.header))
+	rules := make([]string, len(db.header))
+	for i, h := range db.header {
+		headers[i], rules[i] = cell(h), "---"
+	}
+	sb.WriteString("| " + strings.Join(headers, " | ") + " |\n")
+	sb.WriteString("| " + strings.Join(rules, " | ") + " |\n")
+	for _, row := range db.rows {
+		cells := make([]string, len(db.header))
+		for i := range db.header {
+			switch {
+			case i == 0:
+				cells[i] = cell(rowLink(row))
+			case i < len(row):
+				cells[i] = cell(row[i])
+			}
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,122 @@
+package notion_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/notion"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	pageID = " 0123456789abcdef0123456789abcdef"
+	dbID   = " fedcba9876543210fedcba9876543210"
+	rowID  = " 00000000000000000000000000000001"
+)
+
+// writeZip writes an archive of files, by path, and returns its path.
+func writeZip(t *testing.T, files map[string]string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	path := filepath.Join(t.TempDir(), "export.zip")
+	require.NoError(t, os.WriteFile(path, buf.Bytes(), 0644))
+	return path
+}
+
+func exportFiles() map[string]string {
+	return map[string]string{
+		"Home" + pageID + ".md": "# Home\n\nSee [Reading list](Home" + pageID + "/Reading%20list" + dbID + ".csv) " +
+			"and [the guide](Home" + pageID + "/Guide" + pageID[:len(pageID)-1] + "2.md).\n\n" +
+			"![diagram](Home" + pageID + "/diagram.png)\n\n[web](https://example.com/a.md)\n",
+		"Home" + pageID + "/Guide" + pageID[:len(pageID)-1] + "2.md":       "# Guide\n\nBack [Home](../Home" + pageID + ".md).\n",
+		"Home" + pageID + "/diagram.png":                                   "png",
+		"Home" + pageID + "/Reading list" + dbID + ".csv":                  "\xef\xbb\xbfName,Status\nDune,Done\n",
+		"Home" + pageID + "/Reading list" + dbID + "_all.csv":              "\xef\xbb\xbfName,Status,Tags\nDune,Done,\"sf, classic\"\nEmma,,\n",
+		"Home" + pageID + "/Reading list" + dbID + "/Dune" + rowID + ".md": "# Dune\n\nStatus: Done\nTags: sf, classic\n\nGreat book.\n",
+	}
+}
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestCleanName(t *testing.T) {
+	assert.Equal(t, "Reading list", notion.CleanName("Reading list"+dbID+"_all.csv"))
+	assert.Equal(t, "Home", notion.CleanName("Home"+pageID+".md"))
+	assert.Equal(t, "Home", notion.CleanName("Home"+pageID))
+	assert.Equal(t, "Plain", notion.CleanName("Plain.md"))
+}
+
+func TestImport_Frontmatter(t *testing.T) {
+	dest := t.TempDir()
+	result, err := notion.Import(writeZip(t, exportFiles()), notion.Options{Dest: dest})
+	require.NoError(t, err)
+	assert.Len(t, result.Notes, 4)
+	assert.Equal(t, 1, result.Databases)
+	assert.Equal(t, 1, result.Attachments)
+
+	assert.Equal(t, "# Home\n\nSee [[Reading list]] and [[Guide|the guide]].\n\n"+
+		"![diagram](attachments/diagram.png)\n\n[web](https://example.com/a.md)\n", read(t, filepath.Join(dest, "Home.md")))
+	assert.Equal(t, "# Guide\n\nBack [[Home]].\n", read(t, filepath.Join(dest, "Guide.md")))
+	assert.Equal(t, "---\nstatus: Done\ntags:\n  - sf\n  - classic\n---\n# Dune\n\nGreat book.\n",
+		read(t, filepath.Join(dest, "Dune.md")))
+	assert.Equal(t, "# Reading list\n\n- [[Dune]]\n- Emma\n", read(t, filepath.Join(dest, "Reading list.md")))
+	assert.Equal(t, "png", read(t, filepath.Join(dest, "attachments", "diagram.png")))
+}
+
+func TestImport_Table(t *testing.T) {
+	dest := t.TempDir()
+	_, err := notion.Import(writeZip(t, exportFiles()), notion.Options{Dest: dest, Databases: notion.Table})
+	require.NoError(t, err)
+
+	assert.Equal(t, "# Reading list\n\n| Name | Status | Tags |\n| --- | --- | --- |\n"+
+		"| [[Dune]] | Done | sf, classic |\n| Emma |  |  |\n", read(t, filepath.Join(dest, "Reading list.md")))
+	assert.Equal(t, "# Dune\n\nStatus: Done\nTags: sf, classic\n\nGreat book.\n", read(t, filepath.Join(dest, "Dune.md")))
+}
+
+func TestImport_KeepsExistingNotes(t *testing.T) {
+	dest := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dest, "Home.md"), []byte("mine"), 0644))
+
+	_, err := notion.Import(writeZip(t, exportFiles()), notion.Options{Dest: dest})
+	require.NoError(t, err)
+	assert.Equal(t, "mine", read(t, filepath.Join(dest, "Home.md")))
+	assert.Contains(t, read(t, filepath.Join(dest, "Home 2.md")), "# Home")
+	assert.Equal(t, "# Guide\n\nBack [[Home 2|Home]].\n", read(t, filepath.Join(dest, "Guide.md")))
+}
+
+func TestImport_NestedArchive(t *testing.T) {
+	var inner bytes.Buffer
+	w := zip.NewWriter(&inner)
+	f, err := w.Create("Page" + pageID + ".md")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("# Page\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	dest := t.TempDir()
+	result, err := notion.Import(writeZip(t, map[string]string{"Export-Part-1.zip": inner.String()}), notion.Options{Dest: dest})
+	require.NoError(t, err)
+	assert.Len(t, result.Notes, 1)
+	assert.Equal(t, "# Page\n", read(t, filepath.Join(dest, "Page.md")))
+}
+
+func TestImport_InvalidMode(t *testing.T) {
+	_, err := notion.Import(writeZip(t, exportFiles()), notion.Options{Dest: t.TempDir(), Databases: "list"})
+	assert.Error(t, err)
+}
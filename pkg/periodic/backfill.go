@@ -0,0 +1,84 @@
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// period describes how the notes of a period type are found and created.
+type period struct {
+	nav    PeriodNavigator
+	subDir string
+	title  func(time.Time) string
+	create func(context.Context, time.Time, config.Config, templates.TemplateManager, logger.Logger, fs.FileSystem) error
+}
+
+var periods = map[PeriodType]period{
+	Daily: {&DailyNavigator{}, "day", func(d time.Time) string { return d.Format("2006-01-02") },
+		func(ctx context.Context, d time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) error {
+			_, err := NewDailyNote(ctx, d, cfg, tm, log, fs)
+			return err
+		}},
+	Weekly: {&WeeklyNavigator{}, "week", WeekTitle,
+		func(ctx context.Context, d time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) error {
+			_, err := NewWeeklyNote(ctx, d, cfg, tm, log, fs)
+			return err
+		}},
+	Monthly: {&MonthlyNavigator{}, "month", MonthTitle,
+		func(ctx context.Context, d time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) error {
+			_, err := NewMonthlyNote(ctx, d, cfg, tm, log, fs)
+			return err
+		}},
+	Quarterly: {&QuarterlyNavigator{}, "quarter", QuarterTitle,
+		func(ctx context.Context, d time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) error {
+			_, err := NewQuarterlyNote(ctx, d, cfg, tm, log, fs)
+			return err
+		}},
+}
+
+// BackfillOptions are the notes Backfill creates.
+type BackfillOptions struct {
+	Period PeriodType
+	// From and To are dates in the first and last periods to create the
+	// notes of, included.
+	From, To time.Time
+	// DryRun only returns the paths of the missing notes.
+	DryRun bool
+}
+
+// Backfill creates the missing notes of the periods from opts.From to
+// opts.To from their templates, as "exo day" would, and returns their paths.
+// Creating a daily note also creates the weekly, monthly and quarterly notes
+// it links to; those are not returned.
+func Backfill(ctx context.Context, opts BackfillOptions, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fsys fs.FileSystem) ([]string, error) {
+	p, ok := periods[opts.Period]
+	if !ok {
+		return nil, exoerrors.New(exoerrors.Usage, "unknown period %q (expected daily, weekly, monthly or quarterly)", opts.Period)
+	}
+	from, to := p.nav.Start(opts.From), p.nav.Start(opts.To)
+	if from.After(to) {
+		return nil, exoerrors.New(exoerrors.Usage, "the range starts after it ends")
+	}
+	var created []string
+	for date := from; !date.After(to); date = p.nav.Next(date) {
+		path := filepath.Join(cfg.Dir.DataHome, p.subDir, p.title(date)+".md")
+		if fsys.FileExists(path) {
+			continue
+		}
+		if !opts.DryRun {
+			if err := p.create(ctx, date, cfg, tm, log, fsys); err != nil {
+				return created, fmt.Errorf("failed to create %s note %s: %w", opts.Period, p.title(date), err)
+			}
+		}
+		created = append(created, path)
+	}
+	return created, nil
+}
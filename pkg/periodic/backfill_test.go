@@ -0,0 +1,55 @@
+package periodic_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackfill(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	day := func(d int) time.Time { return time.Date(2025, 2, d, 0, 0, 0, 0, time.UTC) }
+	dayPath := func(name string) string { return filepath.Join(cfg.Dir.DataHome, "day", name) }
+
+	existing, err := periodic.NewDailyNote(context.Background(), day(9), cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	require.NoError(t, existing.SetContent("kept"))
+	require.NoError(t, existing.Save())
+
+	opts := periodic.BackfillOptions{Period: periodic.Daily, From: day(8), To: day(10), DryRun: true}
+	missing, err := periodic.Backfill(context.Background(), opts, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{dayPath("2025-02-08.md"), dayPath("2025-02-10.md")}, missing)
+	assert.NoFileExists(t, dayPath("2025-02-08.md"), "a dry run creates nothing")
+
+	opts.DryRun = false
+	created, err := periodic.Backfill(context.Background(), opts, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, missing, created)
+	assert.FileExists(t, dayPath("2025-02-10.md"))
+	content, err := os.ReadFile(dayPath("2025-02-09.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "kept", string(content), "existing notes are left untouched")
+
+	created, err = periodic.Backfill(context.Background(), periodic.BackfillOptions{Period: periodic.Weekly, From: day(1), To: day(10)}, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(cfg.Dir.DataHome, "week", "2025-W05.md"),
+		filepath.Join(cfg.Dir.DataHome, "week", "2025-W06.md"),
+		filepath.Join(cfg.Dir.DataHome, "week", "2025-W07.md"),
+	}, created)
+
+	_, err = periodic.Backfill(context.Background(), periodic.BackfillOptions{Period: periodic.Daily, From: day(10), To: day(8)}, cfg, dtm, dl, dfs)
+	assert.True(t, exoerrors.Is(err, exoerrors.Usage))
+	_, err = periodic.Backfill(context.Background(), periodic.BackfillOptions{Period: "yearly", From: day(8), To: day(8)}, cfg, dtm, dl, dfs)
+	assert.True(t, exoerrors.Is(err, exoerrors.Usage))
+}
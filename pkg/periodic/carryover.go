@@ -0,0 +1,99 @@
+package periodic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/md"
+)
+
+// CarriedOverHeading is the heading unchecked tasks from previous daily notes are
+// inserted under.
+const CarriedOverHeading = "## Carried over"
+
+var (
+	openTaskPattern  = regexp.MustCompile(`^(\s*[-*+] )\[ \] (.*\S)\s*$`)
+	dailyFilePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}\.md$`)
+)
+
+// carryOver holds the unchecked tasks collected from previous daily notes and
+// the contents of those notes with the tasks marked as moved ("- [>]").
+type carryOver struct {
+	tasks   []string
+	sources map[string]string
+}
+
+// collectCarryOver collects the unchecked tasks of the last n daily notes in dir
+// named before the daily note file name, oldest first. Tasks repeated across notes
+// are collected once.
+func collectCarryOver(fsys fs.FileSystem, dir, name string, n int) (carryOver, error) {
+	c := carryOver{sources: make(map[string]string)}
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return c, nil
+		}
+		return c, fmt.Errorf("failed to read daily notes: %w", err)
+	}
+	var previous []string
+	for _, e := range entries {
+		if !e.IsDir() && dailyFilePattern.MatchString(e.Name()) && e.Name() < name {
+			previous = append(previous, e.Name())
+		}
+	}
+	sort.Strings(previous)
+	if len(previous) > n {
+		previous = previous[len(previous)-n:]
+	}
+
+	seen := make(map[string]bool)
+	for _, prev := range previous {
+		path := filepath.Join(dir, prev)
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return c, fmt.Errorf("failed to read daily note %s: %w", prev, err)
+		}
+		doc := md.Parse(string(data))
+		lines := doc.Lines
+		moved := false
+		for i, line := range lines {
+			m := openTaskPattern.FindStringSubmatch(line)
+			if m == nil || doc.Code(i) {
+				continue
+			}
+			lines[i] = m[1] + "[>] " + m[2]
+			moved = true
+			if !seen[m[2]] {
+				seen[m[2]] = true
+				c.tasks = append(c.tasks, m[2])
+			}
+		}
+		if moved {
+			c.sources[path] = strings.Join(lines, "\n")
+		}
+	}
+	return c, nil
+}
+
+// apply inserts the collected tasks into content under CarriedOverHeading.
+func (c carryOver) apply(content string) string {
+	for _, task := range c.tasks {
+		content = md.AppendUnder(content, CarriedOverHeading, "- [ ] "+task)
+	}
+	return content
+}
+
+// markMoved writes the previous daily notes with their tasks marked as moved.
+func (c carryOver) markMoved(fsys fs.FileSystem) error {
+	for path, content := range c.sources {
+		if err := fsys.WriteFile(path, []byte(content)); err != nil {
+			return fmt.Errorf("failed to mark tasks as moved in %s: %w", filepath.Base(path), err)
+		}
+	}
+	return nil
+}
@@ -1,13 +1,20 @@
 package periodic
 
 import (
+	"context"
 	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
 	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/md"
 	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/provider"
 	"github.com/a-kostevski/exo/pkg/templates"
 )
 
@@ -32,6 +39,13 @@ func (dn *DailyNavigator) End(date time.Time) time.Time {
 	return date
 }
 
+// DayOf returns the local midnight starting the calendar day of t, as the
+// dates of daily note titles are.
+func DayOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
 // DailyNote represents a daily periodic note.
 type DailyNote struct {
 	*PeriodicNote // Embeds all periodic note functionality.
@@ -40,18 +54,20 @@ type DailyNote struct {
 // NewDailyNote creates (or loads) a daily note for the given date.
 // It sets daily-specific defaults (e.g. subdirectory "day", filename based on date, template "day")
 // and sets the navigator to a DailyNavigator.
-func NewDailyNote(date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) (*DailyNote, error) {
+func NewDailyNote(ctx context.Context, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) (*DailyNote, error) {
 	// For a daily note, use the date formatted as YYYY-MM-DD as the title.
 	title := date.Format("2006-01-02")
 	// Set defaults: place the note in a "day" subdirectory, use a file name "<date>.md",
-	// and choose the "day" template.
+	// and choose the "day" template or its variant for the date.
+	templateName := DailyTemplate(date, cfg, tm)
 	opts := []note.NoteOption{
 		note.WithSubDir("day"),
 		note.WithFileName(fmt.Sprintf("%s.md", title)),
-		note.WithTemplateName("day"),
+		note.WithTemplateName(templateName),
+		note.WithType("daily"),
 	}
 	// Create the underlying PeriodicNote.
-	p, err := NewPeriodicNote(title, date, cfg, tm, log, fs, opts...)
+	p, err := NewPeriodicNote(ctx, title, date, cfg, tm, log, fs, opts...)
 	if err != nil {
 		log.Error("Failed to create periodic note",
 			logger.Field{Key: "error", Value: err},
@@ -69,23 +85,52 @@ func NewDailyNote(date time.Time, cfg config.Config, tm templates.TemplateManage
 	if !daily.Exists() {
 		log.Info("Initializing new daily note",
 			logger.Field{Key: "path", Value: daily.Path()})
-		templateData := map[string]interface{}{
-			"Date":     title,
-			"Previous": daily.PreviousOrZero().Format("2006-01-02"),
-			"Next":     daily.NextOrZero().Format("2006-01-02"),
+		// The data of the providers enabled in periodic.daily.providers is
+		// given to the template under their keys; a failing provider is
+		// logged and left out rather than keeping the note from being created.
+		templateData, err := provider.Data(ctx, cfg, cfg.Periodic.Daily.Providers, date)
+		if err != nil {
+			log.Error("Failed to get template data",
+				logger.Field{Key: "error", Value: err},
+				logger.Field{Key: "path", Value: daily.Path()})
 		}
+		for key, value := range dailyTemplateData(date) {
+			templateData[key] = value
+		}
+		addStreak(ctx, templateData, templateName, date, cfg, tm, log, fs)
 		if err := daily.ApplyTemplate(templateData); err != nil {
 			log.Error("Failed to apply template",
 				logger.Field{Key: "error", Value: err},
 				logger.Field{Key: "path", Value: daily.Path()})
 			return nil, fmt.Errorf("failed to apply template: %w", err)
 		}
+		// Today's note takes over the unchecked tasks of previous daily notes,
+		// which are marked as moved once the note is saved.
+		var carried carryOver
+		if n := cfg.Periodic.Daily.CarryOver; n > 0 && title == time.Now().Format("2006-01-02") {
+			if carried, err = collectCarryOver(fs, filepath.Dir(daily.Path()), filepath.Base(daily.Path()), n); err != nil {
+				return nil, err
+			}
+			if err := daily.SetContent(carried.apply(daily.Content())); err != nil {
+				return nil, err
+			}
+		}
+		// The note links to the notes of its week, month and quarter, which
+		// are created when missing.
+		if titles := rollupTitles(ctx, date, cfg, tm, log, fs); len(titles) > 0 {
+			if err := daily.SetContent(insertRollupLinks(daily.Content(), titles)); err != nil {
+				return nil, err
+			}
+		}
 		if err := daily.Save(); err != nil {
 			log.Error("Failed to save daily note",
 				logger.Field{Key: "error", Value: err},
 				logger.Field{Key: "path", Value: daily.Path()})
 			return nil, fmt.Errorf("failed to save daily note: %w", err)
 		}
+		if err := carried.markMoved(fs); err != nil {
+			return nil, err
+		}
 	} else {
 		// Otherwise, load the existing note.
 		if err := daily.Load(); err != nil {
@@ -98,6 +143,34 @@ func NewDailyNote(date time.Time, cfg config.Config, tm templates.TemplateManage
 	return daily, nil
 }
 
+// DailyTemplate returns the name of the template the daily note of date is
+// created from: that of the first of periodic.daily.template_overrides matching
+// the date or, failing that, the template of its weekday, such as
+// "day-monday", when there is one, or else "day".
+func DailyTemplate(date time.Time, cfg config.Config, tm templates.TemplateManager) string {
+	for _, o := range cfg.Periodic.Daily.TemplateOverrides {
+		if o.Matches(date) {
+			return o.Template
+		}
+	}
+	name := "day-" + strings.ToLower(date.Weekday().String())
+	if _, _, err := tm.Resolve(name); err == nil {
+		return name
+	}
+	return "day"
+}
+
+// dailyTemplateData returns the data the day template of the note of date is
+// given, besides the data of providers.
+func dailyTemplateData(date time.Time) map[string]interface{} {
+	nav := &DailyNavigator{}
+	return map[string]interface{}{
+		"Date":     date.Format("2006-01-02"),
+		"Previous": nav.Previous(date).Format("2006-01-02"),
+		"Next":     nav.Next(date).Format("2006-01-02"),
+	}
+}
+
 // PreviousOrZero is a helper that returns the previous period (or zero time if error).
 func (d *DailyNote) PreviousOrZero() time.Time {
 	t, err := d.Previous()
@@ -115,3 +188,51 @@ func (d *DailyNote) NextOrZero() time.Time {
 	}
 	return t
 }
+
+// AppendEntry appends text as a bullet prefixed with the time of at (HH:MM) under
+// the given heading, then saves the note. The heading is created if missing.
+func (d *DailyNote) AppendEntry(heading, text string, at time.Time) error {
+	entry := fmt.Sprintf("- %s %s", at.Format("15:04"), text)
+	if err := d.SetContent(md.AppendUnder(d.Content(), heading, entry)); err != nil {
+		return err
+	}
+	if err := d.Save(); err != nil {
+		return fmt.Errorf("failed to save daily note: %w", err)
+	}
+	return nil
+}
+
+// AppendToSection appends text as an entry of the daily.sections section
+// called name, then saves the note. A missing section is created with its
+// template, before the first of the sections configured after it that the
+// note has, or at the end of the note.
+func (d *DailyNote) AppendToSection(name, text string, at time.Time) error {
+	sections := d.Config.Daily.Sections
+	i := slices.IndexFunc(sections, func(s config.DailySection) bool { return strings.EqualFold(s.Name, strings.TrimSpace(name)) })
+	if i == -1 {
+		return exoerrors.New(exoerrors.NotFound, "unknown section %q (daily.sections: %s)", name, strings.Join(d.Config.Daily.SectionNames(), ", "))
+	}
+	section := sections[i]
+	content := d.Content()
+	if heading := section.HeadingLine(); !md.HasHeading(content, heading) {
+		before := ""
+		for _, next := range sections[i+1:] {
+			if md.HasHeading(content, next.HeadingLine()) {
+				before = next.HeadingLine()
+				break
+			}
+		}
+		content = md.Insert(content, heading, section.Template, before)
+	}
+	entry := section.EntryPrefix()
+	if section.Timestamp {
+		entry += at.Format("15:04") + " "
+	}
+	if err := d.SetContent(md.AppendUnder(content, section.HeadingLine(), entry+text)); err != nil {
+		return err
+	}
+	if err := d.Save(); err != nil {
+		return fmt.Errorf("failed to save daily note: %w", err)
+	}
+	return nil
+}
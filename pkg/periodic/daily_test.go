@@ -1,11 +1,17 @@
 package periodic_test
 
 import (
+	"context"
+	"io/fs"
+	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/a-kostevski/exo/pkg/config"
 	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/provider"
+	"github.com/a-kostevski/exo/pkg/templates"
 	"github.com/a-kostevski/exo/pkg/testutil"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +23,7 @@ func TestNewDailyNote_Initialization(t *testing.T) {
 	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
 
 	date := time.Now().Truncate(24 * time.Hour)
-	daily, err := periodic.NewDailyNote(date, cfg, dtm, dl, dfs)
+	daily, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
 	require.NoError(t, err)
 	require.NotNil(t, daily)
 
@@ -30,6 +36,18 @@ func TestNewDailyNote_Initialization(t *testing.T) {
 	assert.Equal(t, expectedPath, daily.Path())
 }
 
+func TestNewDailyNote_Canceled(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	date := time.Date(2025, 2, 10, 0, 0, 0, 0, time.Local)
+	_, err := periodic.NewDailyNote(ctx, date, cfg, dtm, dl, dfs)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoFileExists(t, filepath.Join(cfg.Dir.DataHome, "day", "2025-02-10.md"))
+}
+
 func TestNewDailyNote_LoadExisting(t *testing.T) {
 	// Create a daily note, modify its content, save it, then create another instance for the same date,
 	// and verify that it loads the updated content.
@@ -38,7 +56,7 @@ func TestNewDailyNote_LoadExisting(t *testing.T) {
 
 	date := time.Now().Truncate(24 * time.Hour)
 	// First creation will initialize and save the note.
-	daily1, err := periodic.NewDailyNote(date, cfg, dtm, dl, dfs)
+	daily1, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
 	require.NoError(t, err)
 	require.NotNil(t, daily1)
 
@@ -50,7 +68,7 @@ func TestNewDailyNote_LoadExisting(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create another daily note for the same date. It should load the saved content.
-	daily2, err := periodic.NewDailyNote(date, cfg, dtm, dl, dfs)
+	daily2, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
 	require.NoError(t, err)
 	require.NotNil(t, daily2)
 
@@ -63,7 +81,7 @@ func TestDailyNote_NavigationHelpers(t *testing.T) {
 
 	// Create a daily note for a known date.
 	date := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
-	daily, err := periodic.NewDailyNote(date, cfg, dtm, dl, dfs)
+	daily, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
 	require.NoError(t, err)
 
 	// Test PreviousOrZero and NextOrZero.
@@ -84,7 +102,7 @@ func TestDailyNote_TemplateApplied(t *testing.T) {
 
 	// Create a daily note. Since the file does not exist, it should be initialized.
 	date := time.Now().Truncate(24 * time.Hour)
-	daily, err := periodic.NewDailyNote(date, cfg, dtm, dl, dfs)
+	daily, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
 	require.NoError(t, err)
 
 	// Our DummyTemplateManager (used via dtm) returns "Template: unknown" because the provided
@@ -92,3 +110,179 @@ func TestDailyNote_TemplateApplied(t *testing.T) {
 	expected := "Template: unknown"
 	assert.Equal(t, expected, daily.Content())
 }
+
+// recordingTemplateManager keeps the data templates are processed with.
+type recordingTemplateManager struct {
+	*testutil.DummyTemplateManager
+	data map[string]interface{}
+}
+
+func (r *recordingTemplateManager) ProcessTemplateWithContext(_ context.Context, name string, data interface{}) (string, error) {
+	r.data = data.(map[string]interface{})
+	return r.DummyTemplateManager.ProcessTemplate(name, data)
+}
+
+func TestNewDailyNote_Providers(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, _, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Periodic.Daily.Providers = []string{"moon", "location"}
+	tm := &recordingTemplateManager{DummyTemplateManager: &testutil.DummyTemplateManager{}}
+
+	date := time.Date(2024, time.January, 25, 0, 0, 0, 0, time.Local)
+	_, err := periodic.NewDailyNote(context.Background(), date, cfg, tm, dl, dfs)
+	require.NoError(t, err, "a failing provider does not keep the note from being created")
+
+	assert.Equal(t, "2024-01-25", tm.data["Date"])
+	assert.Equal(t, provider.MoonAt(date.Add(12*time.Hour)), tm.data["Moon"])
+	assert.NotContains(t, tm.data, "Location", "no location is configured")
+}
+
+// dayOnlyTemplates has only the day template installed, resolving the others
+// to the built-in defaults.
+type dayOnlyTemplates struct {
+	*testutil.DummyTemplateManager
+}
+
+func (dayOnlyTemplates) ProcessTemplateWithContext(_ context.Context, name string, data interface{}) (string, error) {
+	if name != "day" {
+		return templates.ProcessDefaultTemplate(name, data)
+	}
+	return "# Day\n\n[[previous]] - [[next]]\n", nil
+}
+
+func (dayOnlyTemplates) Resolve(name string) (templates.TemplateSource, string, error) {
+	if name == "day" {
+		return templates.TemplateSource{Origin: templates.OriginCustom, Path: "day.md"}, "", nil
+	}
+	content, err := templates.LoadDefaultTemplate(name)
+	if err != nil {
+		return templates.TemplateSource{}, "", fs.ErrNotExist
+	}
+	return templates.TemplateSource{Origin: templates.OriginBuiltIn, Path: name + ".md"}, content, nil
+}
+
+func TestNewDailyNote_RollupLinks(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, _, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Periodic.Daily.LinkWeek = true
+	cfg.Periodic.Daily.LinkMonth = true
+	cfg.Periodic.Daily.LinkQuarter = true
+	tm := dayOnlyTemplates{&testutil.DummyTemplateManager{}}
+
+	date := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	daily, err := periodic.NewDailyNote(context.Background(), date, cfg, tm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "# Day\n\nUp: [[2025-W06]] · [[2025-02]] · [[2025-Q1]]\n\n[[previous]] - [[next]]\n", daily.Content())
+
+	// The notes linked to are created from the built-in templates.
+	for _, rel := range []string{"week/2025-W06.md", "month/2025-02.md", "quarter/2025-Q1.md"} {
+		data, err := os.ReadFile(filepath.Join(tmpDir, rel))
+		require.NoError(t, err, rel)
+		assert.Contains(t, string(data), "# 2025-")
+	}
+
+	// Links already in the template are not repeated.
+	cfg.Periodic.Daily.LinkMonth = false
+	cfg.Periodic.Daily.LinkQuarter = false
+	next, err := periodic.NewDailyNote(context.Background(), date.AddDate(0, 0, 1), cfg, fixedTemplates{&testutil.DummyTemplateManager{}, "# Day\n\nWeek [[2025-W06]]\n"}, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "# Day\n\nWeek [[2025-W06]]\n", next.Content())
+}
+
+// fixedTemplates renders every template as content.
+type fixedTemplates struct {
+	*testutil.DummyTemplateManager
+	content string
+}
+
+func (f fixedTemplates) ProcessTemplateWithContext(context.Context, string, interface{}) (string, error) {
+	return f.content, nil
+}
+
+func TestDailyNote_AppendEntry(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+
+	date := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	daily, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+
+	at := time.Date(2025, 2, 8, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, daily.AppendEntry("## Log", "did a thing", at))
+
+	// Reloading the note for the same date should see the appended entry.
+	reloaded, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "Template: unknown\n\n## Log\n\n- 09:30 did a thing\n", reloaded.Content())
+}
+
+func TestDailyNote_AppendToSection(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Daily.Sections = []config.DailySection{
+		{Name: "Tasks", Prefix: "- [ ] "},
+		{Name: "Log", Timestamp: true},
+		{Name: "Gratitude", Heading: "## Grateful for", Prefix: "1. ", Template: "Three things:"},
+		{Name: "Notes"},
+	}
+
+	date := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	daily, err := periodic.NewDailyNote(context.Background(), date, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	require.NoError(t, daily.SetContent("# 2025-02-08\n\n## Notes\n\n- keep\n"))
+
+	at := time.Date(2025, 2, 8, 9, 30, 0, 0, time.UTC)
+	require.NoError(t, daily.AppendToSection("log", "did a thing", at))
+	require.NoError(t, daily.AppendToSection("Tasks", "call Bob", at))
+	require.NoError(t, daily.AppendToSection("Gratitude", "coffee", at))
+	require.NoError(t, daily.AppendToSection("Log", "another", at.Add(time.Hour)))
+	assert.Equal(t, "# 2025-02-08\n\n## Tasks\n\n- [ ] call Bob\n\n## Log\n\n- 09:30 did a thing\n- 10:30 another\n\n"+
+		"## Grateful for\n\nThree things:\n1. coffee\n\n## Notes\n\n- keep\n", daily.Content())
+
+	err = daily.AppendToSection("Dreams", "flying", at)
+	assert.ErrorContains(t, err, "Tasks, Log, Gratitude, Notes")
+}
+
+func TestNewDailyNote_CarryOver(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Periodic.Daily.CarryOver = 2
+
+	today := time.Now()
+	dayDir := filepath.Join(tmpDir, "day")
+	require.NoError(t, os.MkdirAll(dayDir, 0755))
+	write := func(daysAgo int, content string) string {
+		path := filepath.Join(dayDir, today.AddDate(0, 0, -daysAgo).Format("2006-01-02")+".md")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	tooOld := write(5, "- [ ] ancient\n")
+	older := write(3, "- [ ] call Bob\n- [x] done\n")
+	yesterday := write(1, "## Tasks\n\n- [ ] write report\n  - [ ] call Bob\n")
+
+	daily, err := periodic.NewDailyNote(context.Background(), today, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "Template: unknown\n\n## Carried over\n\n- [ ] call Bob\n- [ ] write report\n", daily.Content())
+
+	assertFile := func(path, want string) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, want, string(data))
+	}
+	assertFile(older, "- [>] call Bob\n- [x] done\n")
+	assertFile(yesterday, "## Tasks\n\n- [>] write report\n  - [>] call Bob\n")
+	assertFile(tooOld, "- [ ] ancient\n")
+}
+
+func TestNewDailyNote_CarryOverOnlyToday(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Periodic.Daily.CarryOver = 1
+
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "day"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "day", "2025-02-07.md"), []byte("- [ ] pending\n"), 0644))
+
+	daily, err := periodic.NewDailyNote(context.Background(), time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC), cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "Template: unknown", daily.Content())
+}
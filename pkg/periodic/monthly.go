@@ -0,0 +1,108 @@
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// MonthlyNavigator implements PeriodNavigator for calendar months.
+type MonthlyNavigator struct{}
+
+func (mn *MonthlyNavigator) Previous(date time.Time) time.Time {
+	return mn.Start(date).AddDate(0, -1, 0)
+}
+
+func (mn *MonthlyNavigator) Next(date time.Time) time.Time {
+	return mn.Start(date).AddDate(0, 1, 0)
+}
+
+func (mn *MonthlyNavigator) Start(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+}
+
+func (mn *MonthlyNavigator) End(date time.Time) time.Time {
+	return mn.Start(date).AddDate(0, 1, -1)
+}
+
+// MonthTitle returns the month title of date, e.g. "2025-02".
+func MonthTitle(date time.Time) string {
+	return date.Format("2006-01")
+}
+
+// MonthlyNote represents a monthly periodic note.
+type MonthlyNote struct {
+	*PeriodicNote
+}
+
+// NewMonthlyNote creates (or loads) the monthly note for the month containing date.
+// It uses the subdirectory "month", a filename based on the month (e.g. 2025-02.md)
+// and the "month" template.
+func NewMonthlyNote(ctx context.Context, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) (*MonthlyNote, error) {
+	nav := &MonthlyNavigator{}
+	start := nav.Start(date)
+	title := MonthTitle(start)
+	opts := []note.NoteOption{
+		note.WithSubDir("month"),
+		note.WithFileName(fmt.Sprintf("%s.md", title)),
+		note.WithTemplateName("month"),
+		note.WithType("monthly"),
+	}
+	p, err := NewPeriodicNote(ctx, title, start, cfg, tm, log, fs, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create periodic note: %w", err)
+	}
+	p.SetNavigator(nav)
+	p.periodType = Monthly
+
+	monthly := &MonthlyNote{PeriodicNote: p}
+	if monthly.Exists() {
+		if err := monthly.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load existing monthly note: %w", err)
+		}
+		return monthly, nil
+	}
+
+	log.Info("Initializing new monthly note",
+		logger.Field{Key: "path", Value: monthly.Path()})
+	if err := monthly.ApplyTemplate(monthlyTemplateData(start)); err != nil {
+		return nil, fmt.Errorf("failed to apply template: %w", err)
+	}
+	if err := monthly.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save monthly note: %w", err)
+	}
+	return monthly, nil
+}
+
+// monthlyTemplateData returns the data the month template of the month
+// starting at start is given.
+func monthlyTemplateData(start time.Time) map[string]interface{} {
+	nav := &MonthlyNavigator{}
+	return map[string]interface{}{
+		"Title":    MonthTitle(start),
+		"Start":    start.Format("2006-01-02"),
+		"End":      nav.End(start).Format("2006-01-02"),
+		"Previous": MonthTitle(nav.Previous(start)),
+		"Next":     MonthTitle(nav.Next(start)),
+		"Quarter":  QuarterTitle(start),
+		"Weeks":    monthWeeks(start),
+	}
+}
+
+// monthWeeks returns the titles of the ISO weeks with days in the month
+// starting at start.
+func monthWeeks(start time.Time) []string {
+	nav := &WeeklyNavigator{}
+	end := start.AddDate(0, 1, 0)
+	var weeks []string
+	for week := nav.Start(start); week.Before(end); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, WeekTitle(week))
+	}
+	return weeks
+}
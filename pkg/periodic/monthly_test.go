@@ -0,0 +1,36 @@
+package periodic_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonthlyNavigator(t *testing.T) {
+	nav := &periodic.MonthlyNavigator{}
+	date := time.Date(2025, 3, 31, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), nav.Start(date))
+	assert.Equal(t, time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), nav.End(date))
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), nav.Next(date))
+	assert.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), nav.Previous(date))
+	assert.Equal(t, "2025-03", periodic.MonthTitle(date))
+}
+
+func TestNewMonthlyNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+
+	monthly, err := periodic.NewMonthlyNote(context.Background(), time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC), cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.True(t, monthly.Exists())
+	assert.Equal(t, filepath.Join(cfg.Dir.DataHome, "month", "2025-02.md"), monthly.Path())
+	assert.Equal(t, "Template: 2025-02", monthly.Content())
+	require.NoError(t, monthly.Validate())
+}
@@ -1,6 +1,7 @@
 package periodic
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -18,7 +19,13 @@ type PeriodType string
 const (
 	// Daily represents a daily period.
 	Daily PeriodType = "daily"
-	// Other period types (e.g., Weekly, Monthly) could be added here.
+	// Weekly represents an ISO week starting on Monday.
+	Weekly PeriodType = "weekly"
+	// Monthly represents a calendar month.
+	Monthly PeriodType = "monthly"
+	// Quarterly represents a calendar quarter, starting in January, April,
+	// July or October.
+	Quarterly PeriodType = "quarterly"
 )
 
 // PeriodNavigator defines methods for navigating between periods.
@@ -39,14 +46,16 @@ type PeriodicNote struct {
 
 // NewPeriodicNote creates a new PeriodicNote from a BaseNote. It is the common
 // constructor for any periodic note type. In addition to the BaseNote dependencies,
-// you provide the current date and any additional note options.
-func NewPeriodicNote(title string, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem, opts ...note.NoteOption) (*PeriodicNote, error) {
+// you provide the current date and any additional note options. The template of
+// the note is applied and the note saved in ctx.
+func NewPeriodicNote(ctx context.Context, title string, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem, opts ...note.NoteOption) (*PeriodicNote, error) {
 	// For periodic notes, you might want to enforce a default subdirectory.
 	defaultOpts := []note.NoteOption{
 		// A default subdirectory may be "periodic"; individual types can override this.
 		note.WithSubDir("periodic"),
 		// The file name is typically derived from the title (which for daily might be the date).
 		note.WithFileName(fmt.Sprintf("%s.md", title)),
+		note.WithContext(ctx),
 	}
 	allOpts := append(defaultOpts, opts...)
 	base, err := note.NewBaseNote(title, cfg, tm, log, fs, allOpts...)
@@ -1,6 +1,7 @@
 package periodic_test
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -24,7 +25,7 @@ func TestPeriodicNote_Navigation(t *testing.T) {
 		note.WithFileName("2025-02-08.md"),
 		note.WithTemplateName("periodic"),
 	}
-	p, err := periodic.NewPeriodicNote(title, testDate, cfg, dtm, dl, dfs, opts...)
+	p, err := periodic.NewPeriodicNote(context.Background(), title, testDate, cfg, dtm, dl, dfs, opts...)
 	require.NoError(t, err)
 	// Set the navigator to a DailyNavigator.
 	dailyNav := &periodic.DailyNavigator{}
@@ -57,7 +58,7 @@ func TestPeriodicNote_Validate_NoNavigator(t *testing.T) {
 		note.WithFileName("2025-02-08.md"),
 		note.WithTemplateName("periodic"),
 	}
-	p, err := periodic.NewPeriodicNote(title, testDate, cfg, dtm, dl, dfs, opts...)
+	p, err := periodic.NewPeriodicNote(context.Background(), title, testDate, cfg, dtm, dl, dfs, opts...)
 	require.NoError(t, err)
 	// Do not set a navigator.
 	err = p.Validate()
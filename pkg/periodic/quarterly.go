@@ -0,0 +1,100 @@
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// QuarterlyNavigator implements PeriodNavigator for calendar quarters.
+type QuarterlyNavigator struct{}
+
+func (qn *QuarterlyNavigator) Previous(date time.Time) time.Time {
+	return qn.Start(date).AddDate(0, -3, 0)
+}
+
+func (qn *QuarterlyNavigator) Next(date time.Time) time.Time {
+	return qn.Start(date).AddDate(0, 3, 0)
+}
+
+func (qn *QuarterlyNavigator) Start(date time.Time) time.Time {
+	month := (date.Month()-1)/3*3 + 1
+	return time.Date(date.Year(), month, 1, 0, 0, 0, 0, date.Location())
+}
+
+func (qn *QuarterlyNavigator) End(date time.Time) time.Time {
+	return qn.Start(date).AddDate(0, 3, -1)
+}
+
+// QuarterTitle returns the quarter title of date, e.g. "2025-Q1".
+func QuarterTitle(date time.Time) string {
+	return fmt.Sprintf("%d-Q%d", date.Year(), (int(date.Month())-1)/3+1)
+}
+
+// QuarterlyNote represents a quarterly periodic note.
+type QuarterlyNote struct {
+	*PeriodicNote
+}
+
+// NewQuarterlyNote creates (or loads) the quarterly note for the quarter containing
+// date. It uses the subdirectory "quarter", a filename based on the quarter (e.g.
+// 2025-Q1.md) and the "quarter" template.
+func NewQuarterlyNote(ctx context.Context, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) (*QuarterlyNote, error) {
+	nav := &QuarterlyNavigator{}
+	start := nav.Start(date)
+	title := QuarterTitle(start)
+	opts := []note.NoteOption{
+		note.WithSubDir("quarter"),
+		note.WithFileName(fmt.Sprintf("%s.md", title)),
+		note.WithTemplateName("quarter"),
+		note.WithType("quarterly"),
+	}
+	p, err := NewPeriodicNote(ctx, title, start, cfg, tm, log, fs, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create periodic note: %w", err)
+	}
+	p.SetNavigator(nav)
+	p.periodType = Quarterly
+
+	quarterly := &QuarterlyNote{PeriodicNote: p}
+	if quarterly.Exists() {
+		if err := quarterly.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load existing quarterly note: %w", err)
+		}
+		return quarterly, nil
+	}
+
+	log.Info("Initializing new quarterly note",
+		logger.Field{Key: "path", Value: quarterly.Path()})
+	if err := quarterly.ApplyTemplate(quarterlyTemplateData(start)); err != nil {
+		return nil, fmt.Errorf("failed to apply template: %w", err)
+	}
+	if err := quarterly.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save quarterly note: %w", err)
+	}
+	return quarterly, nil
+}
+
+// quarterlyTemplateData returns the data the quarter template of the quarter
+// starting at start is given.
+func quarterlyTemplateData(start time.Time) map[string]interface{} {
+	nav := &QuarterlyNavigator{}
+	months := make([]string, 3)
+	for i := range months {
+		months[i] = MonthTitle(start.AddDate(0, i, 0))
+	}
+	return map[string]interface{}{
+		"Title":    QuarterTitle(start),
+		"Start":    start.Format("2006-01-02"),
+		"End":      nav.End(start).Format("2006-01-02"),
+		"Previous": QuarterTitle(nav.Previous(start)),
+		"Next":     QuarterTitle(nav.Next(start)),
+		"Months":   months,
+	}
+}
@@ -0,0 +1,37 @@
+package periodic_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuarterlyNavigator(t *testing.T) {
+	nav := &periodic.QuarterlyNavigator{}
+	date := time.Date(2025, 8, 17, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC), nav.Start(date))
+	assert.Equal(t, time.Date(2025, 9, 30, 0, 0, 0, 0, time.UTC), nav.End(date))
+	assert.Equal(t, time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), nav.Next(date))
+	assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), nav.Previous(date))
+	assert.Equal(t, "2025-Q3", periodic.QuarterTitle(date))
+	assert.Equal(t, "2025-Q4", periodic.QuarterTitle(time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestNewQuarterlyNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+
+	quarterly, err := periodic.NewQuarterlyNote(context.Background(), time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC), cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.True(t, quarterly.Exists())
+	assert.Equal(t, filepath.Join(cfg.Dir.DataHome, "quarter", "2025-Q1.md"), quarterly.Path())
+	assert.Equal(t, "Template: 2025-Q1", quarterly.Content())
+	require.NoError(t, quarterly.Validate())
+}
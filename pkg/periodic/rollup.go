@@ -0,0 +1,81 @@
+package periodic
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// rollupPrefix starts the line of a daily note linking to the notes of its
+// week, month and quarter.
+const rollupPrefix = "Up: "
+
+// DaysHeading is the heading of a weekly note the daily notes of the week are
+// listed under.
+const DaysHeading = "## Days"
+
+// rollupTitles returns the titles of the weekly, monthly and quarterly notes of
+// date enabled in cfg, creating the notes that are missing. A note that cannot
+// be created is logged and still linked to.
+func rollupTitles(ctx context.Context, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) []string {
+	var titles []string
+	create := func(title string, enabled bool, newNote func() error) {
+		if !enabled {
+			return
+		}
+		if err := newNote(); err != nil {
+			log.Error("Failed to create periodic note",
+				logger.Field{Key: "error", Value: err},
+				logger.Field{Key: "title", Value: title})
+		}
+		titles = append(titles, title)
+	}
+	daily := cfg.Periodic.Daily
+	create(WeekTitle(date), daily.LinkWeek, func() error {
+		_, err := NewWeeklyNote(ctx, date, cfg, tm, log, fs)
+		return err
+	})
+	create(MonthTitle(date), daily.LinkMonth, func() error {
+		_, err := NewMonthlyNote(ctx, date, cfg, tm, log, fs)
+		return err
+	})
+	create(QuarterTitle(date), daily.LinkQuarter, func() error {
+		_, err := NewQuarterlyNote(ctx, date, cfg, tm, log, fs)
+		return err
+	})
+	return titles
+}
+
+// insertRollupLinks inserts a line linking to the notes titled titles that
+// content does not link to yet below its first level-one heading, or at the
+// top when it has none.
+func insertRollupLinks(content string, titles []string) string {
+	var links []string
+	for _, title := range titles {
+		if !linksTo(content, title) {
+			links = append(links, "[["+title+"]]")
+		}
+	}
+	if len(links) == 0 {
+		return content
+	}
+	line := rollupPrefix + strings.Join(links, " · ")
+	lines := strings.Split(content, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "# ") {
+			rest := append([]string{"", line}, lines[i+1:]...)
+			return strings.Join(append(lines[:i+1], rest...), "\n")
+		}
+	}
+	return line + "\n\n" + content
+}
+
+// linksTo reports whether content holds a wikilink to the note titled title.
+func linksTo(content, title string) bool {
+	return strings.Contains(content, "[["+title+"]]") || strings.Contains(content, "[["+title+"|") || strings.Contains(content, "[["+title+"#")
+}
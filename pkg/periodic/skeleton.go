@@ -0,0 +1,97 @@
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+var (
+	weekTitle    = regexp.MustCompile(`^(\d{4})-W(\d{2})$`)
+	quarterTitle = regexp.MustCompile(`^(\d{4})-Q([1-4])$`)
+)
+
+// ParseTitle returns the period of the periodic note titled title, such as
+// "2025-02-08", "2025-W06", "2025-02" or "2025-Q1", and the date it starts at.
+func ParseTitle(title string) (PeriodType, time.Time, error) {
+	if t, err := time.ParseInLocation("2006-01-02", title, time.Local); err == nil {
+		return Daily, t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01", title, time.Local); err == nil {
+		return Monthly, t, nil
+	}
+	if m := weekTitle.FindStringSubmatch(title); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		week, _ := strconv.Atoi(m[2])
+		// January 4th is always in the first ISO week of its year.
+		start := (&WeeklyNavigator{}).Start(time.Date(year, 1, 4, 0, 0, 0, 0, time.Local)).AddDate(0, 0, 7*(week-1))
+		if _, w := start.ISOWeek(); w != week {
+			return "", time.Time{}, fmt.Errorf("invalid week %q", title)
+		}
+		return Weekly, start, nil
+	}
+	if m := quarterTitle.FindStringSubmatch(title); m != nil {
+		year, _ := strconv.Atoi(m[1])
+		quarter, _ := strconv.Atoi(m[2])
+		return Quarterly, time.Date(year, time.Month(3*quarter-2), 1, 0, 0, 0, 0, time.Local), nil
+	}
+	return "", time.Time{}, fmt.Errorf("%q is not the title of a periodic note", title)
+}
+
+// Skeleton returns the contents the periodic note titled title is created
+// with from its template and the links to other periodic notes enabled in cfg,
+// without the data of providers and the tasks carried over, which change from
+// note to note.
+func Skeleton(ctx context.Context, title string, cfg config.Config, tm templates.TemplateManager) (string, error) {
+	period, start, err := ParseTitle(title)
+	if err != nil {
+		return "", err
+	}
+	var (
+		name string
+		data map[string]interface{}
+	)
+	switch period {
+	case Daily:
+		name, data = DailyTemplate(start, cfg, tm), dailyTemplateData(start)
+	case Weekly:
+		name, data = "week", weeklyTemplateData(start)
+	case Monthly:
+		name, data = "month", monthlyTemplateData(start)
+	case Quarterly:
+		name, data = "quarter", quarterlyTemplateData(start)
+	}
+	if usesStreak(tm, name) {
+		// Numbers are ignored when comparing notes with their skeleton.
+		data["Streak"] = Streak{}
+	}
+	content, err := tm.ProcessTemplateWithContext(ctx, name, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to process template: %w", err)
+	}
+	switch {
+	case period == Daily:
+		var titles []string
+		for _, link := range []struct {
+			title   string
+			enabled bool
+		}{
+			{WeekTitle(start), cfg.Periodic.Daily.LinkWeek},
+			{MonthTitle(start), cfg.Periodic.Daily.LinkMonth},
+			{QuarterTitle(start), cfg.Periodic.Daily.LinkQuarter},
+		} {
+			if link.enabled {
+				titles = append(titles, link.title)
+			}
+		}
+		content = insertRollupLinks(content, titles)
+	case period == Weekly && cfg.Periodic.Weekly.LinkDays:
+		content = linkDays(content, start)
+	}
+	return content, nil
+}
@@ -0,0 +1,100 @@
+package periodic_test
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// namingTemplateManager renders templates as their name and data. Only the
+// templates of periods and the variants listed are installed.
+type namingTemplateManager struct {
+	testutil.DummyTemplateManager
+	variants []string
+}
+
+func (tm *namingTemplateManager) Resolve(name string) (templates.TemplateSource, string, error) {
+	if strings.Contains(name, "-") && !slices.Contains(tm.variants, name) {
+		return templates.TemplateSource{}, "", fs.ErrNotExist
+	}
+	return tm.DummyTemplateManager.Resolve(name)
+}
+
+func (tm *namingTemplateManager) ProcessTemplateWithContext(ctx context.Context, name string, data interface{}) (string, error) {
+	return fmt.Sprintf("%s %v", name, data), nil
+}
+
+func TestParseTitle(t *testing.T) {
+	for title, want := range map[string]struct {
+		period periodic.PeriodType
+		start  time.Time
+	}{
+		"2025-02-08": {periodic.Daily, time.Date(2025, 2, 8, 0, 0, 0, 0, time.Local)},
+		"2025-W06":   {periodic.Weekly, time.Date(2025, 2, 3, 0, 0, 0, 0, time.Local)},
+		"2026-W01":   {periodic.Weekly, time.Date(2025, 12, 29, 0, 0, 0, 0, time.Local)},
+		"2025-02":    {periodic.Monthly, time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local)},
+		"2025-Q3":    {periodic.Quarterly, time.Date(2025, 7, 1, 0, 0, 0, 0, time.Local)},
+	} {
+		period, start, err := periodic.ParseTitle(title)
+		require.NoError(t, err, title)
+		assert.Equal(t, want.period, period, title)
+		assert.True(t, want.start.Equal(start), "%s: %s", title, start)
+	}
+	for _, title := range []string{"Go channels", "2025-W54", "2025-Q5", "2025-13"} {
+		_, _, err := periodic.ParseTitle(title)
+		assert.Error(t, err, title)
+	}
+}
+
+func TestSkeleton(t *testing.T) {
+	tm := &namingTemplateManager{}
+	var cfg config.Config
+	content, err := periodic.Skeleton(context.Background(), "2025-02-08", cfg, tm)
+	require.NoError(t, err)
+	assert.Equal(t, "day map[Date:2025-02-08 Next:2025-02-09 Previous:2025-02-07]", content)
+
+	cfg.Periodic.Daily.LinkWeek, cfg.Periodic.Daily.LinkQuarter = true, true
+	content, err = periodic.Skeleton(context.Background(), "2025-02-08", cfg, tm)
+	require.NoError(t, err)
+	assert.Equal(t, "Up: [[2025-W06]] · [[2025-Q1]]\n\nday map[Date:2025-02-08 Next:2025-02-09 Previous:2025-02-07]", content)
+
+	content, err = periodic.Skeleton(context.Background(), "2025-Q1", cfg, tm)
+	require.NoError(t, err)
+	assert.Equal(t, "quarter map[End:2025-03-31 Months:[2025-01 2025-02 2025-03] Next:2025-Q2 Previous:2024-Q4 Start:2025-01-01 Title:2025-Q1]", content)
+
+	_, err = periodic.Skeleton(context.Background(), "Go channels", cfg, tm)
+	assert.Error(t, err)
+}
+
+func TestDailyTemplate(t *testing.T) {
+	saturday := time.Date(2025, 2, 8, 0, 0, 0, 0, time.Local)
+	var cfg config.Config
+	assert.Equal(t, "day", periodic.DailyTemplate(saturday, cfg, &namingTemplateManager{}))
+
+	tm := &namingTemplateManager{variants: []string{"day-saturday"}}
+	assert.Equal(t, "day-saturday", periodic.DailyTemplate(saturday, cfg, tm), "the weekday variant is used when installed")
+	assert.Equal(t, "day", periodic.DailyTemplate(saturday.AddDate(0, 0, 1), cfg, tm))
+
+	cfg.Periodic.Daily.TemplateOverrides = []config.TemplateOverride{
+		{Template: "day-holiday", From: "12-24", To: "01-01"},
+		{Template: "day-weekend", Weekdays: []string{"saturday", "sunday"}},
+	}
+	assert.Equal(t, "day-weekend", periodic.DailyTemplate(saturday, cfg, tm), "overrides come first")
+	assert.Equal(t, "day-holiday", periodic.DailyTemplate(time.Date(2025, 12, 27, 0, 0, 0, 0, time.Local), cfg, tm), "the first match wins")
+	assert.Equal(t, "day", periodic.DailyTemplate(saturday.AddDate(0, 0, 2), cfg, tm))
+
+	content, err := periodic.Skeleton(context.Background(), "2025-02-08", cfg, tm)
+	require.NoError(t, err)
+	assert.Equal(t, "day-weekend map[Date:2025-02-08 Next:2025-02-09 Previous:2025-02-07]", content)
+}
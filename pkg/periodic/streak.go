@@ -0,0 +1,130 @@
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// Streak is the consistency of journaling in daily notes: the runs of
+// consecutive days whose daily note has content of its own.
+type Streak struct {
+	// Current is the number of consecutive days written up to today. It still
+	// counts if today's note is not written yet.
+	Current int `json:"current"`
+	// Longest is the longest run of consecutive days written, which started
+	// on LongestStart.
+	Longest      int       `json:"longest"`
+	LongestStart time.Time `json:"longest_start"`
+	// Days is the number of days written.
+	Days int `json:"days"`
+	// Last is the last day written, if any.
+	Last time.Time `json:"last"`
+}
+
+// Streaks returns the streaks of the daily notes up to today. A daily note
+// counts as written when it has a line its template did not put there, as
+// "exo diff --template" shows; numbers are ignored, so that the dates and
+// streaks templates show are not taken for writing.
+func Streaks(ctx context.Context, today time.Time, cfg config.Config, tm templates.TemplateManager, fsys fs.FileSystem) (Streak, error) {
+	dir := filepath.Join(cfg.Dir.DataHome, "day")
+	entries, err := fsys.ReadDir(dir)
+	if err != nil && fsys.FileExists(dir) {
+		return Streak{}, fmt.Errorf("failed to read daily notes: %w", err)
+	}
+	today = DayOf(today)
+	var written []time.Time
+	for _, e := range entries {
+		title := strings.TrimSuffix(e.Name(), ".md")
+		period, date, err := ParseTitle(title)
+		if e.IsDir() || period != Daily || err != nil || !strings.HasSuffix(e.Name(), ".md") || date.After(today) {
+			continue
+		}
+		content, err := fsys.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return Streak{}, fmt.Errorf("failed to read daily note: %w", err)
+		}
+		skeleton, err := Skeleton(ctx, title, cfg, tm)
+		if err != nil {
+			return Streak{}, err
+		}
+		if hasOwnContent(string(content), skeleton) {
+			written = append(written, DayOf(date))
+		}
+	}
+	sort.Slice(written, func(i, j int) bool { return written[i].Before(written[j]) })
+
+	var s Streak
+	run := 0
+	for i, day := range written {
+		if i > 0 && written[i-1].AddDate(0, 0, 1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > s.Longest {
+			s.Longest, s.LongestStart = run, day.AddDate(0, 0, 1-run)
+		}
+	}
+	s.Days = len(written)
+	if len(written) > 0 {
+		s.Last = written[len(written)-1]
+		if yesterday := today.AddDate(0, 0, -1); !s.Last.Before(yesterday) {
+			s.Current = run
+		}
+	}
+	return s, nil
+}
+
+// digits matches the numbers ignored when telling what was written.
+var digits = regexp.MustCompile(`[0-9]+`)
+
+// hasOwnContent reports whether content has a non-blank line that skeleton,
+// the content of the note as created, does not have, numbers aside.
+func hasOwnContent(content, skeleton string) bool {
+	normalize := func(line string) string {
+		return digits.ReplaceAllString(strings.TrimSpace(line), "0")
+	}
+	template := make(map[string]bool)
+	for _, line := range strings.Split(skeleton, "\n") {
+		template[normalize(line)] = true
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if line := normalize(line); line != "" && !template[line] {
+			return true
+		}
+	}
+	return false
+}
+
+// usesStreak reports whether the template called name shows streaks, which
+// are then computed for the notes created from it.
+func usesStreak(tm templates.TemplateManager, name string) bool {
+	_, content, err := tm.Resolve(name)
+	return err == nil && strings.Contains(content, ".Streak")
+}
+
+// addStreak gives data the streak as of today, under "Streak", when the
+// template called name shows it. A streak that cannot be computed is logged
+// and left at zero rather than keeping the note from being created.
+func addStreak(ctx context.Context, data map[string]interface{}, name string, today time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fsys fs.FileSystem) {
+	if !usesStreak(tm, name) {
+		return
+	}
+	streak, err := Streaks(ctx, today, cfg, tm, fsys)
+	if err != nil {
+		log.Error("Failed to compute streak",
+			logger.Field{Key: "error", Value: err},
+			logger.Field{Key: "template", Value: name})
+	}
+	data["Streak"] = streak
+}
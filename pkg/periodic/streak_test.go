@@ -0,0 +1,57 @@
+package periodic_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreaks(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, _, dl, _, _ := testutil.NewDummyDeps(tmpDir)
+	require.NoError(t, os.MkdirAll(cfg.Dir.TemplateDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(cfg.Dir.TemplateDir, "day.md"),
+		[]byte("# {{ .Date }}\n\nStreak: {{ .Streak.Current }} days, longest {{ .Streak.Longest }}\n\n## Notes\n"), 0644))
+	osfs := fs.NewOSFileSystem()
+	tm, err := templates.NewTemplateManager(templates.TemplateConfig{TemplateDir: cfg.Dir.TemplateDir, Logger: dl, FS: osfs})
+	require.NoError(t, err)
+	day := func(d int) time.Time { return time.Date(2025, 2, d, 0, 0, 0, 0, time.Local) }
+	write := func(d int, text string) {
+		daily, err := periodic.NewDailyNote(context.Background(), day(d), cfg, tm, dl, osfs)
+		require.NoError(t, err)
+		if text != "" {
+			require.NoError(t, daily.SetContent(daily.Content()+text+"\n"))
+			require.NoError(t, daily.Save())
+		}
+	}
+	for _, d := range []int{1, 2, 3, 5, 6} {
+		write(d, "Wrote something.")
+	}
+	write(7, "") // Created but left empty.
+
+	streak, err := periodic.Streaks(context.Background(), day(8), cfg, tm, osfs)
+	require.NoError(t, err)
+	assert.Equal(t, periodic.Streak{Current: 0, Longest: 3, LongestStart: day(1), Days: 5, Last: day(6)}, streak)
+
+	streak, err = periodic.Streaks(context.Background(), day(7), cfg, tm, osfs)
+	require.NoError(t, err)
+	assert.Equal(t, 2, streak.Current, "today not written yet keeps the streak")
+
+	streak, err = periodic.Streaks(context.Background(), day(2), cfg, tm, osfs)
+	require.NoError(t, err)
+	assert.Equal(t, periodic.Streak{Current: 2, Longest: 2, LongestStart: day(1), Days: 2, Last: day(2)}, streak, "later notes are ignored")
+
+	write(8, "")
+	content, err := os.ReadFile(filepath.Join(cfg.Dir.DataHome, "day", "2025-02-08.md"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "Streak: 0 days, longest 3", "templates show the streak")
+}
@@ -0,0 +1,146 @@
+package periodic
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/habit"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// SummaryHeading is the heading of the weekly note a summary is written under.
+const SummaryHeading = "## Summary"
+
+var (
+	listItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+[.)])\s+(.*\S)\s*$`)
+	taskPattern     = regexp.MustCompile(`^\[([ xX>])\]\s*(.*)$`)
+)
+
+// SummaryOptions selects the daily notes and zettels summarized.
+type SummaryOptions struct {
+	// DailyDir is the directory of the daily notes.
+	DailyDir string
+	// From and To are the first and last day summarized.
+	From, To time.Time
+	// Zettels are the notes whose creation in the period is reported.
+	Zettels []scan.Note
+	// Template is the source of the daily note template. List items copied
+	// from it unchanged, such as prompts to fill in, are left out.
+	Template string
+}
+
+// Summary aggregates the daily notes of a period, as given to the summary
+// template.
+type Summary struct {
+	// Start and End are the first and last day summarized, e.g. "2025-02-03".
+	Start, End string
+	// Days are the titles of the daily notes found, oldest first.
+	Days []string
+	// Sections are the list items of the daily notes grouped by heading, in
+	// the order the headings first appear.
+	Sections []SummarySection
+	// Completed are the checked tasks of the daily notes.
+	Completed []SummaryEntry
+	// Zettels are the file names, without extension, of the zettels created
+	// in the period.
+	Zettels []string
+}
+
+// SummarySection holds the entries found under a heading of the daily notes.
+type SummarySection struct {
+	Heading string
+	Entries []SummaryEntry
+}
+
+// SummaryEntry is a list item of the daily note of Day.
+type SummaryEntry struct {
+	Day  string
+	Text string
+}
+
+// Summarize reads the daily notes of the days from opts.From to opts.To and
+// collects their list items, their checked tasks and the zettels created in
+// that time. Unchecked and moved tasks, empty list items and the habit
+// check-ins under habit.Heading are left out.
+func Summarize(fsys fs.FileSystem, opts SummaryOptions) (Summary, error) {
+	from := time.Date(opts.From.Year(), opts.From.Month(), opts.From.Day(), 0, 0, 0, 0, opts.From.Location())
+	to := time.Date(opts.To.Year(), opts.To.Month(), opts.To.Day(), 0, 0, 0, 0, opts.To.Location())
+	s := Summary{Start: from.Format("2006-01-02"), End: to.Format("2006-01-02")}
+
+	ignore := make(map[string]bool)
+	for _, line := range strings.Split(opts.Template, "\n") {
+		ignore[strings.TrimSpace(line)] = true
+	}
+	sections := make(map[string]int)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		title := day.Format("2006-01-02")
+		data, err := fsys.ReadFile(filepath.Join(opts.DailyDir, title+".md"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return s, fmt.Errorf("failed to read daily note %s: %w", title, err)
+		}
+		s.Days = append(s.Days, title)
+
+		doc := md.Parse(string(data))
+		for i, line := range doc.Lines {
+			if level, _ := md.Heading(line); level > 0 || doc.Code(i) {
+				continue
+			}
+			// Items are grouped by the heading they are under, below the
+			// title of the note.
+			section := doc.At(i)
+			if inHabits(section) {
+				continue
+			}
+			heading := ""
+			if section.Level >= 2 {
+				heading = section.Title
+			}
+			m := listItemPattern.FindStringSubmatch(line)
+			if m == nil || ignore[strings.TrimSpace(line)] {
+				continue
+			}
+			text := m[1]
+			if t := taskPattern.FindStringSubmatch(text); t != nil {
+				if (t[1] == "x" || t[1] == "X") && t[2] != "" {
+					s.Completed = append(s.Completed, SummaryEntry{Day: title, Text: t[2]})
+				}
+				continue
+			}
+			i, ok := sections[heading]
+			if !ok {
+				i = len(s.Sections)
+				sections[heading] = i
+				s.Sections = append(s.Sections, SummarySection{Heading: heading})
+			}
+			s.Sections[i].Entries = append(s.Sections[i].Entries, SummaryEntry{Day: title, Text: text})
+		}
+	}
+
+	end := to.AddDate(0, 0, 1)
+	for _, n := range opts.Zettels {
+		if !n.Created.Before(from) && n.Created.Before(end) {
+			s.Zettels = append(s.Zettels, strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension))
+		}
+	}
+	return s, nil
+}
+
+// inHabits reports whether section is the habits block of a daily note or
+// is under it.
+func inHabits(section *md.Section) bool {
+	for ; section != nil; section = section.Parent {
+		if section.Heading() == habit.Heading {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package periodic_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarize(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("2025-02-02.md", "## Notes\n\n- before the period\n")
+	write("2025-02-03.md", "# 2025-02-03\n\n## Wins\n\n1.\n2.\n\n## Notes\n\n- 09:30 shipped the release\n- Questions Raised:\n\n## Tasks\n\n- [x] review PR\n- [ ] write docs\n- [>] moved on\n1. [ ]\n")
+	write("2025-02-05.md", "## Notes\n\n- 14:00 [[Weekly sync]]\n  - nested detail\n\n## Wins\n\n1. Fixed the flaky test\n- [X] call Bob\n")
+	write("2025-02-10.md", "## Notes\n\n- after the period\n")
+
+	created := func(day int) time.Time { return time.Date(2025, time.February, day, 10, 0, 0, 0, time.Local) }
+	opts := periodic.SummaryOptions{
+		DailyDir: dir,
+		From:     time.Date(2025, time.February, 3, 0, 0, 0, 0, time.Local),
+		To:       time.Date(2025, time.February, 9, 0, 0, 0, 0, time.Local),
+		Zettels: []scan.Note{
+			{Path: "/v/zettel/20250201 Old.md", Created: created(1)},
+			{Path: "/v/zettel/20250204 Channels.md", Created: created(4)},
+			{Path: "/v/zettel/Last day.md", Created: created(9)},
+		},
+		Template: "## Notes\n\n- Questions Raised:\n",
+	}
+	s, err := periodic.Summarize(testutil.NewDummyFS(), opts)
+	require.NoError(t, err)
+
+	assert.Equal(t, "2025-02-03", s.Start)
+	assert.Equal(t, "2025-02-09", s.End)
+	assert.Equal(t, []string{"2025-02-03", "2025-02-05"}, s.Days)
+	assert.Equal(t, []periodic.SummarySection{
+		{Heading: "Notes", Entries: []periodic.SummaryEntry{
+			{Day: "2025-02-03", Text: "09:30 shipped the release"},
+			{Day: "2025-02-05", Text: "14:00 [[Weekly sync]]"},
+			{Day: "2025-02-05", Text: "nested detail"},
+		}},
+		{Heading: "Wins", Entries: []periodic.SummaryEntry{
+			{Day: "2025-02-05", Text: "Fixed the flaky test"},
+		}},
+	}, s.Sections)
+	assert.Equal(t, []periodic.SummaryEntry{
+		{Day: "2025-02-03", Text: "review PR"},
+		{Day: "2025-02-05", Text: "call Bob"},
+	}, s.Completed)
+	assert.Equal(t, []string{"20250204 Channels", "Last day"}, s.Zettels)
+}
+
+func TestSummarize_SkipsHabits(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2025-02-03.md"),
+		[]byte("# 2025-02-03\n\n## Habits\n\n- [x] water\n- [ ] read\n\n## Tasks\n\n- [x] review PR\n- [ ] write docs\n"), 0644))
+
+	day := time.Date(2025, time.February, 3, 0, 0, 0, 0, time.Local)
+	s, err := periodic.Summarize(testutil.NewDummyFS(), periodic.SummaryOptions{DailyDir: dir, From: day, To: day})
+	require.NoError(t, err)
+	assert.Equal(t, []periodic.SummaryEntry{{Day: "2025-02-03", Text: "review PR"}}, s.Completed)
+	assert.Empty(t, s.Sections)
+}
@@ -0,0 +1,132 @@
+package periodic
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// WeeklyNavigator implements PeriodNavigator for ISO weeks starting on Monday.
+type WeeklyNavigator struct{}
+
+func (wn *WeeklyNavigator) Previous(date time.Time) time.Time {
+	return wn.Start(date).AddDate(0, 0, -7)
+}
+
+func (wn *WeeklyNavigator) Next(date time.Time) time.Time {
+	return wn.Start(date).AddDate(0, 0, 7)
+}
+
+func (wn *WeeklyNavigator) Start(date time.Time) time.Time {
+	// time.Weekday starts on Sunday; shift so that Monday is day 0.
+	offset := (int(date.Weekday()) + 6) % 7
+	y, m, d := date.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, date.Location())
+}
+
+func (wn *WeeklyNavigator) End(date time.Time) time.Time {
+	return wn.Start(date).AddDate(0, 0, 6)
+}
+
+// WeekTitle returns the ISO week title of date, e.g. "2025-W06".
+func WeekTitle(date time.Time) string {
+	year, week := date.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+// WeeklyNote represents a weekly periodic note.
+type WeeklyNote struct {
+	*PeriodicNote
+}
+
+// NewWeeklyNote creates (or loads) the weekly note for the week containing date.
+// It uses the subdirectory "week", a filename based on the ISO week (e.g. 2025-W06.md)
+// and the "week" template.
+func NewWeeklyNote(ctx context.Context, date time.Time, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem) (*WeeklyNote, error) {
+	nav := &WeeklyNavigator{}
+	start := nav.Start(date)
+	title := WeekTitle(start)
+	opts := []note.NoteOption{
+		note.WithSubDir("week"),
+		note.WithFileName(fmt.Sprintf("%s.md", title)),
+		note.WithTemplateName("week"),
+		note.WithType("weekly"),
+	}
+	p, err := NewPeriodicNote(ctx, title, start, cfg, tm, log, fs, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create periodic note: %w", err)
+	}
+	p.SetNavigator(nav)
+	p.periodType = Weekly
+
+	weekly := &WeeklyNote{PeriodicNote: p}
+	if weekly.Exists() {
+		if err := weekly.Load(); err != nil {
+			return nil, fmt.Errorf("failed to load existing weekly note: %w", err)
+		}
+		return weekly, nil
+	}
+
+	log.Info("Initializing new weekly note",
+		logger.Field{Key: "path", Value: weekly.Path()})
+	data := weeklyTemplateData(start)
+	// The streak is that at the end of the week, or today for the current week.
+	asOf := nav.End(start)
+	if now := time.Now(); now.Before(asOf) {
+		asOf = now
+	}
+	addStreak(ctx, data, "week", asOf, cfg, tm, log, fs)
+	if err := weekly.ApplyTemplate(data); err != nil {
+		return nil, fmt.Errorf("failed to apply template: %w", err)
+	}
+	if cfg.Periodic.Weekly.LinkDays {
+		if err := weekly.SetContent(linkDays(weekly.Content(), start)); err != nil {
+			return nil, err
+		}
+	}
+	if err := weekly.Save(); err != nil {
+		return nil, fmt.Errorf("failed to save weekly note: %w", err)
+	}
+	return weekly, nil
+}
+
+// weeklyTemplateData returns the data the week template of the week starting
+// at start is given.
+func weeklyTemplateData(start time.Time) map[string]interface{} {
+	nav := &WeeklyNavigator{}
+	return map[string]interface{}{
+		"Title":    WeekTitle(start),
+		"Start":    start.Format("2006-01-02"),
+		"End":      nav.End(start).Format("2006-01-02"),
+		"Previous": WeekTitle(nav.Previous(start)),
+		"Next":     WeekTitle(nav.Next(start)),
+		"Days":     weekDays(start),
+	}
+}
+
+// linkDays adds to content the links to the daily notes of the week starting
+// at start that it does not link to yet, under DaysHeading.
+func linkDays(content string, start time.Time) string {
+	for _, day := range weekDays(start) {
+		if !linksTo(content, day) {
+			content = md.AppendUnder(content, DaysHeading, "- [["+day+"]]")
+		}
+	}
+	return content
+}
+
+// weekDays returns the daily note titles of the seven days starting at start.
+func weekDays(start time.Time) []string {
+	days := make([]string, 7)
+	for i := range days {
+		days[i] = start.AddDate(0, 0, i).Format("2006-01-02")
+	}
+	return days
+}
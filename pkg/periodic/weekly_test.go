@@ -0,0 +1,56 @@
+package periodic_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/periodic"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeeklyNavigator(t *testing.T) {
+	nav := &periodic.WeeklyNavigator{}
+	// 2025-02-08 is a Saturday in ISO week 6.
+	date := time.Date(2025, 2, 8, 15, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2025, 2, 3, 0, 0, 0, 0, time.UTC), nav.Start(date))
+	assert.Equal(t, time.Date(2025, 2, 9, 0, 0, 0, 0, time.UTC), nav.End(date))
+	assert.Equal(t, time.Date(2025, 2, 10, 0, 0, 0, 0, time.UTC), nav.Next(date))
+	assert.Equal(t, time.Date(2025, 1, 27, 0, 0, 0, 0, time.UTC), nav.Previous(date))
+	assert.Equal(t, "2025-W06", periodic.WeekTitle(date))
+}
+
+func TestNewWeeklyNote(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+
+	date := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	weekly, err := periodic.NewWeeklyNote(context.Background(), date, cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+
+	assert.True(t, weekly.Exists())
+	assert.Equal(t, filepath.Join(cfg.Dir.DataHome, "week", "2025-W06.md"), weekly.Path())
+	assert.Equal(t, "Template: 2025-W06", weekly.Content())
+	require.NoError(t, weekly.Validate())
+
+	// A second instance for another day of the same week loads the same note.
+	require.NoError(t, weekly.SetContent("edited"))
+	require.NoError(t, weekly.Save())
+	again, err := periodic.NewWeeklyNote(context.Background(), date.AddDate(0, 0, -3), cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", again.Content())
+}
+
+func TestNewWeeklyNote_LinkDays(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.Periodic.Weekly.LinkDays = true
+
+	weekly, err := periodic.NewWeeklyNote(context.Background(), time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC), cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+	assert.Equal(t, "Template: 2025-W06\n\n## Days\n\n- [[2025-02-03]]\n- [[2025-02-04]]\n- [[2025-02-05]]\n- [[2025-02-06]]\n- [[2025-02-07]]\n- [[2025-02-08]]\n- [[2025-02-09]]\n", weekly.Content())
+}
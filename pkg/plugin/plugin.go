@@ -0,0 +1,329 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// Plugins are executables in the plugin directory speaking a subprocess protocol.
+// Invoked with ManifestFlag, a plugin prints its Manifest as JSON. Invoked with
+// FuncFlag followed by a function name and its arguments, it prints the result of
+// one of its template functions. Invoked with LintFlag followed by a rule name and
+// the path of a note, it prints the issues of one of its lint rules found in the
+// note as a JSON array of LintIssue. Any other invocation runs one of its commands,
+// with the command name as the first argument and the vault locations in the
+// environment (see Env).
+const (
+	// ManifestFlag asks a plugin to print its manifest.
+	ManifestFlag = "--exo-manifest"
+	// FuncFlag asks a plugin to evaluate one of its template functions.
+	FuncFlag = "--exo-func"
+	// LintFlag asks a plugin to check a note against one of its lint rules.
+	LintFlag = "--exo-lint"
+	// DefaultTimeout bounds manifest and template function calls.
+	DefaultTimeout = 5 * time.Second
+)
+
+// Manifest describes what a plugin provides.
+type Manifest struct {
+	Name          string     `json:"name"`
+	Description   string     `json:"description,omitempty"`
+	Commands      []Command  `json:"commands,omitempty"`
+	NoteTypes     []NoteType `json:"note_types,omitempty"`
+	TemplateFuncs []string   `json:"template_funcs,omitempty"`
+	LintRules     []LintRule `json:"lint_rules,omitempty"`
+}
+
+// Command is a subcommand provided by a plugin.
+type Command struct {
+	Name  string `json:"name"`
+	Short string `json:"short,omitempty"`
+	// Usage is the argument synopsis, e.g. "<note> [options]".
+	Usage string `json:"usage,omitempty"`
+}
+
+// NoteType is a kind of note provided by a plugin.
+type NoteType struct {
+	Name string `json:"name"`
+	// Dir is the directory, relative to the data home, notes of this type are
+	// created in; it defaults to Name.
+	Dir string `json:"dir,omitempty"`
+	// Template is the name of the template new notes are created from; it
+	// defaults to Name.
+	Template string `json:"template,omitempty"`
+}
+
+// LintRule is a rule of "exo lint" provided by a plugin.
+type LintRule struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// LintIssue is a problem found in a note by a lint rule of a plugin.
+type LintIssue struct {
+	// Line is the line of the note the issue is on, or 0 for the whole note.
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Plugin is a discovered plugin executable together with its manifest.
+type Plugin struct {
+	Path string
+	Manifest
+}
+
+// Discover returns the plugins found in dir, sorted by name. Every executable
+// regular file is asked for its manifest; plugins that fail to answer are left
+// out and reported in the returned error, while the others are still returned.
+// A missing directory yields no plugins.
+func Discover(ctx context.Context, dir string) ([]Plugin, error) {
+	return discover(ctx, dir, nil)
+}
+
+// DiscoverCached is Discover, reading the manifests of plugins whose
+// executable did not change, by size and modification time, from the cache
+// file at cacheFile instead of running them. The cache is rewritten when
+// plugins changed; failing to read or write it only costs running them.
+func DiscoverCached(ctx context.Context, dir, cacheFile string) ([]Plugin, error) {
+	cache := readCache(cacheFile)
+	plugins, err := discover(ctx, dir, cache)
+	if cache.changed {
+		cache.write(cacheFile)
+	}
+	return plugins, err
+}
+
+func discover(ctx context.Context, dir string, cache *manifestCache) ([]Plugin, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read plugin directory: %w", err)
+	}
+	var (
+		plugins []Plugin
+		errs    []error
+		seen    = make(map[string]bool)
+	)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || info.Mode()&0111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		seen[path] = true
+		if p, ok := cache.lookup(path, info); ok {
+			plugins = append(plugins, p)
+			continue
+		}
+		p, err := Load(ctx, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		cache.store(p, info)
+		plugins = append(plugins, p)
+	}
+	cache.prune(seen)
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, errors.Join(errs...)
+}
+
+// manifestCache holds the manifests of plugins by executable path. A nil
+// cache holds nothing.
+type manifestCache struct {
+	Entries map[string]cacheEntry `json:"plugins"`
+	changed bool
+}
+
+// cacheEntry is the manifest of a plugin executable of a given size and
+// modification time.
+type cacheEntry struct {
+	Size     int64    `json:"size"`
+	ModTime  int64    `json:"mtime"`
+	Manifest Manifest `json:"manifest"`
+}
+
+// readCache reads the cache file at path; it is empty when the file is
+// missing or invalid.
+func readCache(path string) *manifestCache {
+	cache := &manifestCache{}
+	_ = fs.ReadStateFile(path, "plugin cache", cache)
+	if cache.Entries == nil {
+		cache.Entries = make(map[string]cacheEntry)
+	}
+	return cache
+}
+
+// lookup returns the cached plugin at path, if its executable is unchanged.
+func (c *manifestCache) lookup(path string, info os.FileInfo) (Plugin, bool) {
+	if c == nil {
+		return Plugin{}, false
+	}
+	e, ok := c.Entries[path]
+	if !ok || e.Size != info.Size() || e.ModTime != info.ModTime().UnixNano() {
+		return Plugin{}, false
+	}
+	return Plugin{Path: path, Manifest: e.Manifest}, true
+}
+
+// store caches the manifest of p, whose executable is described by info.
+func (c *manifestCache) store(p Plugin, info os.FileInfo) {
+	if c == nil {
+		return
+	}
+	c.Entries[p.Path] = cacheEntry{Size: info.Size(), ModTime: info.ModTime().UnixNano(), Manifest: p.Manifest}
+	c.changed = true
+}
+
+// prune drops the plugins no longer found.
+func (c *manifestCache) prune(seen map[string]bool) {
+	if c == nil {
+		return
+	}
+	for path := range c.Entries {
+		if !seen[path] {
+			delete(c.Entries, path)
+			c.changed = true
+		}
+	}
+}
+
+// write saves the cache to path, replacing the file atomically.
+func (c *manifestCache) write(path string) {
+	_ = fs.WriteStateFile(path, "plugin cache", c)
+}
+
+// Load reads the manifest of the plugin executable at path. The plugin name
+// defaults to the file name.
+func Load(ctx context.Context, path string) (Plugin, error) {
+	out, err := run(ctx, path, ManifestFlag)
+	if err != nil {
+		return Plugin{}, fmt.Errorf("plugin %s: %w", filepath.Base(path), err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(out, &m); err != nil {
+		return Plugin{}, fmt.Errorf("plugin %s: invalid manifest: %w", filepath.Base(path), err)
+	}
+	if m.Name == "" {
+		m.Name = filepath.Base(path)
+	}
+	for _, c := range m.Commands {
+		if c.Name == "" || strings.HasPrefix(c.Name, "-") {
+			return Plugin{}, fmt.Errorf("plugin %s: invalid command name %q", m.Name, c.Name)
+		}
+	}
+	for _, r := range m.LintRules {
+		if r.Name == "" {
+			return Plugin{}, fmt.Errorf("plugin %s: lint rule without a name", m.Name)
+		}
+	}
+	for i, t := range m.NoteTypes {
+		if t.Name == "" {
+			return Plugin{}, fmt.Errorf("plugin %s: note type without a name", m.Name)
+		}
+		if t.Dir == "" {
+			m.NoteTypes[i].Dir = t.Name
+		}
+		if t.Template == "" {
+			m.NoteTypes[i].Template = t.Name
+		}
+	}
+	return Plugin{Path: path, Manifest: m}, nil
+}
+
+// Command returns an exec.Cmd running the plugin command name with args.
+// The caller connects its standard streams and adds the environment.
+func (p Plugin) Command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, p.Path, append([]string{name}, args...)...)
+}
+
+// Call evaluates the template function fn of the plugin with args and returns
+// its output without the trailing newline.
+func (p Plugin) Call(ctx context.Context, fn string, args ...string) (string, error) {
+	out, err := run(ctx, p.Path, append([]string{FuncFlag, fn}, args...)...)
+	if err != nil {
+		return "", fmt.Errorf("plugin %s: %s: %w", p.Name, fn, err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
+
+// Lint checks the note at path against the lint rule of the plugin.
+func (p Plugin) Lint(ctx context.Context, rule, path string) ([]LintIssue, error) {
+	out, err := run(ctx, p.Path, LintFlag, rule, path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %s: %w", p.Name, rule, err)
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+	var issues []LintIssue
+	if err := json.Unmarshal(out, &issues); err != nil {
+		return nil, fmt.Errorf("plugin %s: %s: invalid issues: %w", p.Name, rule, err)
+	}
+	return issues, nil
+}
+
+// Env returns the environment plugins run with: the current environment plus
+// the vault locations.
+func Env(dataHome, templateDir, configFile string) []string {
+	return append(os.Environ(),
+		"EXO_DATA_HOME="+dataHome,
+		"EXO_TEMPLATE_DIR="+templateDir,
+		"EXO_CONFIG="+configFile,
+	)
+}
+
+// FuncMap returns the template functions of plugins. When several plugins
+// provide a function of the same name, the first one wins.
+func FuncMap(plugins []Plugin) template.FuncMap {
+	funcs := make(template.FuncMap)
+	for _, p := range plugins {
+		for _, name := range p.TemplateFuncs {
+			if _, ok := funcs[name]; ok {
+				continue
+			}
+			p, name := p, name
+			funcs[name] = func(args ...interface{}) (string, error) {
+				strs := make([]string, len(args))
+				for i, a := range args {
+					strs[i] = fmt.Sprint(a)
+				}
+				return p.Call(context.Background(), name, strs...)
+			}
+		}
+	}
+	return funcs
+}
+
+// run executes path with args under DefaultTimeout and returns its standard output.
+func run(ctx context.Context, path string, args ...string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	var stdout, stderr bytes.Buffer
+	c := exec.CommandContext(ctx, path, args...)
+	c.Stdout, c.Stderr = &stdout, &stderr
+	if err := c.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,136 @@
+package plugin_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/plugin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const helloPlugin = `#!/bin/sh
+case "$1" in
+--exo-manifest)
+	echo '{"name":"hello","commands":[{"name":"greet","short":"Say hello"}],"note_types":[{"name":"recipe"}],"template_funcs":["shout"],"lint_rules":[{"name":"no-fixme","description":"FIXME markers"}]}'
+	;;
+--exo-lint)
+	grep -n FIXME "$3" | sed 's/:.*//' | awk 'BEGIN{printf "["} NR>1{printf ","} {printf "{\"line\":%s,\"message\":\"FIXME left\"}", $1} END{print "]"}'
+	;;
+--exo-func)
+	shift 2
+	echo "$*!" | tr a-z A-Z
+	;;
+greet)
+	echo "hello $2 from $EXO_DATA_HOME"
+	;;
+esac
+`
+
+func writePlugin(t *testing.T, dir, name, script string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(script), 0755))
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "exo-hello", helloPlugin)
+	writePlugin(t, dir, "broken", "#!/bin/sh\necho not json\n")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("docs"), 0644))
+
+	plugins, err := plugin.Discover(context.Background(), dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin broken: invalid manifest")
+	require.Len(t, plugins, 1)
+
+	p := plugins[0]
+	assert.Equal(t, "hello", p.Name)
+	assert.Equal(t, []plugin.Command{{Name: "greet", Short: "Say hello"}}, p.Commands)
+	assert.Equal(t, []plugin.NoteType{{Name: "recipe", Dir: "recipe", Template: "recipe"}}, p.NoteTypes)
+	assert.Equal(t, []plugin.LintRule{{Name: "no-fixme", Description: "FIXME markers"}}, p.LintRules)
+}
+
+func TestDiscover_MissingDir(t *testing.T) {
+	plugins, err := plugin.Discover(context.Background(), filepath.Join(t.TempDir(), "none"))
+	require.NoError(t, err)
+	assert.Empty(t, plugins)
+}
+
+func TestDiscoverCached(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(t.TempDir(), "calls")
+	counting := func(name string) string {
+		return "#!/bin/sh\necho x >> '" + calls + "'\necho '{\"name\":\"" + name + "\"}'\n"
+	}
+	writePlugin(t, dir, "exo-a", counting("a"))
+	cacheFile := filepath.Join(t.TempDir(), "cache", "plugins.json")
+	countCalls := func() int {
+		data, _ := os.ReadFile(calls)
+		return strings.Count(string(data), "x")
+	}
+
+	for range 2 {
+		plugins, err := plugin.DiscoverCached(context.Background(), dir, cacheFile)
+		require.NoError(t, err)
+		require.Len(t, plugins, 1)
+		assert.Equal(t, "a", plugins[0].Name)
+	}
+	assert.Equal(t, 1, countCalls(), "an unchanged plugin is asked for its manifest once")
+
+	writePlugin(t, dir, "exo-a", counting("renamed"))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "exo-a"), later, later))
+	plugins, err := plugin.DiscoverCached(context.Background(), dir, cacheFile)
+	require.NoError(t, err)
+	require.Len(t, plugins, 1)
+	assert.Equal(t, "renamed", plugins[0].Name, "a changed plugin is asked again")
+	assert.Equal(t, 2, countCalls())
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "exo-a")))
+	plugins, err = plugin.DiscoverCached(context.Background(), dir, cacheFile)
+	require.NoError(t, err)
+	assert.Empty(t, plugins, "removed plugins are not served from the cache")
+}
+
+func TestPlugin_CommandAndFuncs(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "exo-hello", helloPlugin)
+	p, err := plugin.Load(context.Background(), filepath.Join(dir, "exo-hello"))
+	require.NoError(t, err)
+
+	c := p.Command(context.Background(), "greet", "world")
+	c.Env = plugin.Env("/vault", "/vault/templates", "")
+	out, err := c.Output()
+	require.NoError(t, err)
+	assert.Equal(t, "hello world from /vault\n", string(out))
+
+	tmpl, err := template.New("t").Funcs(plugin.FuncMap([]plugin.Plugin{p})).Parse(`{{shout "hi" 2}}`)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, nil))
+	assert.Equal(t, "HI 2!", strings.TrimSpace(buf.String()))
+}
+
+func TestPlugin_Lint(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "exo-hello", helloPlugin)
+	p, err := plugin.Load(context.Background(), filepath.Join(dir, "exo-hello"))
+	require.NoError(t, err)
+
+	note := filepath.Join(dir, "note.md")
+	require.NoError(t, os.WriteFile(note, []byte("# Note\n\nFIXME: finish\nok\nFIXME again\n"), 0644))
+	issues, err := p.Lint(context.Background(), "no-fixme", note)
+	require.NoError(t, err)
+	assert.Equal(t, []plugin.LintIssue{{Line: 3, Message: "FIXME left"}, {Line: 5, Message: "FIXME left"}}, issues)
+
+	require.NoError(t, os.WriteFile(note, []byte("# Clean\n"), 0644))
+	issues, err = p.Lint(context.Background(), "no-fixme", note)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
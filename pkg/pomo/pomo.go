@@ -0,0 +1,95 @@
+// Package pomo runs pomodoro timers and counts the pomodoros completed.
+package pomo
+
+import (
+	"context"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// File is the state file, in the state directory, completed pomodoros are
+// counted in.
+const File = "pomo.json"
+
+// DefaultDuration is the length of a pomodoro.
+const DefaultDuration = 25 * time.Minute
+
+// dateLayout is the layout of the days in Stats.
+const dateLayout = "2006-01-02"
+
+// Stats counts the pomodoros completed.
+type Stats struct {
+	path string
+	// Last is the day (YYYY-MM-DD) the last pomodoro was completed.
+	Last string `json:"last,omitempty"`
+	// Today is the number of pomodoros completed on Last.
+	Today int `json:"today"`
+	// Streak is the number of consecutive days, up to Last, with a
+	// pomodoro completed.
+	Streak int `json:"streak"`
+	Total  int `json:"total"`
+}
+
+// OpenStats reads the stats at path. A missing file yields empty stats.
+func OpenStats(path string) (*Stats, error) {
+	s := &Stats{path: path}
+	if err := fs.ReadStateFile(path, "pomodoro stats", s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the stats to their file, creating the directory if needed.
+func (s *Stats) Save() error {
+	return fs.WriteStateFile(s.path, "pomodoro stats", s)
+}
+
+// Complete counts a pomodoro completed at now.
+func (s *Stats) Complete(now time.Time) {
+	today, streak := s.At(now)
+	if today == 0 {
+		streak++
+	}
+	s.Last = now.Format(dateLayout)
+	s.Today = today + 1
+	s.Streak = streak
+	s.Total++
+}
+
+// At returns the number of pomodoros completed on the day of now and the
+// current streak: the consecutive days with a pomodoro completed, up to the
+// day of now or the day before.
+func (s *Stats) At(now time.Time) (today, streak int) {
+	switch s.Last {
+	case now.Format(dateLayout):
+		return s.Today, s.Streak
+	case now.AddDate(0, 0, -1).Format(dateLayout):
+		return 0, s.Streak
+	default:
+		return 0, 0
+	}
+}
+
+// Run counts down d, calling tick with the time remaining every interval and
+// once at the start. It returns nil once d has elapsed, or the error of ctx
+// if it is done first.
+func Run(ctx context.Context, d, interval time.Duration, tick func(remaining time.Duration)) error {
+	end := time.Now().Add(d)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	tick(d)
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			tick(0)
+			return nil
+		case <-ticker.C:
+			tick(time.Until(end).Round(interval))
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package pomo_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/pomo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".exo", "pomo.json")
+	s, err := pomo.OpenStats(path)
+	require.NoError(t, err)
+	day := func(d, h int) time.Time { return time.Date(2025, 2, d, h, 0, 0, 0, time.UTC) }
+
+	s.Complete(day(8, 9))
+	s.Complete(day(8, 10))
+	today, streak := s.At(day(8, 11))
+	assert.Equal(t, 2, today)
+	assert.Equal(t, 1, streak)
+
+	today, streak = s.At(day(9, 9))
+	assert.Equal(t, 0, today)
+	assert.Equal(t, 1, streak, "the streak holds until the day is over")
+	s.Complete(day(9, 9))
+	today, streak = s.At(day(9, 10))
+	assert.Equal(t, 1, today)
+	assert.Equal(t, 2, streak)
+
+	require.NoError(t, s.Save())
+	s, err = pomo.OpenStats(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, s.Total)
+
+	s.Complete(day(12, 9))
+	today, streak = s.At(day(12, 10))
+	assert.Equal(t, 1, today)
+	assert.Equal(t, 1, streak, "a missed day breaks the streak")
+	_, streak = s.At(day(14, 10))
+	assert.Zero(t, streak)
+}
+
+func TestRun(t *testing.T) {
+	var ticks []time.Duration
+	err := pomo.Run(context.Background(), 50*time.Millisecond, 10*time.Millisecond, func(remaining time.Duration) {
+		ticks = append(ticks, remaining)
+	})
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(ticks), 3)
+	assert.Equal(t, 50*time.Millisecond, ticks[0])
+	assert.Equal(t, time.Duration(0), ticks[len(ticks)-1])
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = pomo.Run(ctx, time.Minute, 5*time.Millisecond, func(time.Duration) {})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,155 @@
+// Package prompts keeps a list of journaling prompts and picks the prompts of
+// a day from it.
+package prompts
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultFile is the file prompts are added to when the prompts path is a
+// directory.
+const DefaultFile = "prompts.md"
+
+// Order is the way prompts are picked for successive days.
+type Order string
+
+const (
+	// Rotate goes through the prompts in turn, count per day.
+	Rotate Order = "rotate"
+	// Random picks the prompts of a day at random, the same ones for the
+	// same day.
+	Random Order = "random"
+)
+
+var itemPrefixes = []string{"- ", "* ", "+ "}
+
+// Load reads the prompts of the file at path, or of the Markdown and text files
+// of the directory at path, in file name order. Each non-blank line is a
+// prompt; list markers are removed and headings and comments are skipped. A
+// missing path holds no prompts.
+func Load(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompts: %w", err)
+		}
+		files = files[:0]
+		for _, e := range entries {
+			if ext := filepath.Ext(e.Name()); !e.IsDir() && (ext == ".md" || ext == ".txt") {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	var prompts []string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompts: %w", err)
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if p := parseLine(line); p != "" {
+				prompts = append(prompts, p)
+			}
+		}
+	}
+	return prompts, nil
+}
+
+// parseLine returns the prompt on a line of a prompts file, or "" if it holds
+// none.
+func parseLine(line string) string {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "<!--") {
+		return ""
+	}
+	for _, prefix := range itemPrefixes {
+		if rest, ok := strings.CutPrefix(line, prefix); ok {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return line
+}
+
+// Add appends prompt to the file at path, or to DefaultFile in the directory
+// at path, creating it if needed.
+func Add(path, prompt string) error {
+	prompt = strings.Join(strings.Fields(prompt), " ")
+	if prompt == "" {
+		return errors.New("empty prompt")
+	}
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		path = filepath.Join(path, DefaultFile)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create prompts directory: %w", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read prompts: %w", err)
+	}
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	if err := os.WriteFile(path, append(content, "- "+prompt+"\n"...), 0644); err != nil {
+		return fmt.Errorf("failed to write prompts: %w", err)
+	}
+	return nil
+}
+
+// Pick returns count of the prompts for date, at most all of them. The same
+// date always gets the same prompts from the same list.
+func Pick(prompts []string, date time.Time, count int, order Order) []string {
+	n := len(prompts)
+	if n == 0 || count <= 0 {
+		return nil
+	}
+	if count > n {
+		count = n
+	}
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400
+	picked := make([]string, count)
+	if order == Random {
+		perm := rand.New(rand.NewSource(day)).Perm(n)
+		for i := range picked {
+			picked[i] = prompts[perm[i]]
+		}
+		return picked
+	}
+	start := int(day * int64(count) % int64(n))
+	if start < 0 {
+		start += n
+	}
+	for i := range picked {
+		picked[i] = prompts[(start+i)%n]
+	}
+	return picked
+}
+
+// ParseOrder parses the name of an order; "" is Rotate.
+func ParseOrder(s string) (Order, error) {
+	switch Order(s) {
+	case "", Rotate:
+		return Rotate, nil
+	case Random:
+		return Random, nil
+	}
+	return "", fmt.Errorf("unknown prompt order %q (expected rotate or random)", s)
+}
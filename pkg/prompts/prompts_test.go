@@ -0,0 +1,75 @@
+package prompts_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/prompts"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAndAdd(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "prompts.md")
+
+	list, err := prompts.Load(file)
+	require.NoError(t, err)
+	assert.Empty(t, list, "a missing file holds no prompts")
+
+	require.NoError(t, os.WriteFile(file, []byte("# Prompts\n\n- What went well?\n* What  was hard?\n<!-- later -->\nWho helped me?"), 0644))
+	require.NoError(t, prompts.Add(file, "  What did I   learn? "))
+	assert.ErrorContains(t, prompts.Add(file, " "), "empty prompt")
+	list, err = prompts.Load(file)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"What went well?", "What  was hard?", "Who helped me?", "What did I learn?"}, list)
+
+	sub := filepath.Join(dir, "set")
+	require.NoError(t, os.MkdirAll(sub, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "b.txt"), []byte("Second\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "ignored.json"), []byte("Nope\n"), 0644))
+	require.NoError(t, prompts.Add(sub, "First"))
+	list, err = prompts.Load(sub)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Second", "First"}, list, "files are read in name order and prompts added to prompts.md")
+}
+
+func TestPick(t *testing.T) {
+	list := []string{"a", "b", "c", "d", "e"}
+	day := time.Date(2025, time.February, 8, 0, 0, 0, 0, time.UTC)
+
+	first := prompts.Pick(list, day, 2, prompts.Rotate)
+	second := prompts.Pick(list, day.AddDate(0, 0, 1), 2, prompts.Rotate)
+	require.Len(t, first, 2)
+	assert.Equal(t, list[(indexOf(list, first[0])+2)%5], second[0], "the next day gets the next prompts")
+	assert.Equal(t, first, prompts.Pick(list, day.Add(15*time.Hour), 2, prompts.Rotate), "a day keeps its prompts")
+
+	random := prompts.Pick(list, day, 3, prompts.Random)
+	assert.Len(t, random, 3)
+	assert.NotEqual(t, random[0], random[1])
+	assert.Equal(t, random, prompts.Pick(list, day, 3, prompts.Random))
+
+	assert.Len(t, prompts.Pick(list, day, 9, prompts.Rotate), 5)
+	assert.Len(t, prompts.Pick(list, time.Date(1960, time.March, 1, 0, 0, 0, 0, time.UTC), 1, prompts.Rotate), 1)
+	assert.Nil(t, prompts.Pick(nil, day, 1, prompts.Rotate))
+	assert.Nil(t, prompts.Pick(list, day, 0, prompts.Rotate))
+}
+
+func TestParseOrder(t *testing.T) {
+	order, err := prompts.ParseOrder("")
+	require.NoError(t, err)
+	assert.Equal(t, prompts.Rotate, order)
+	_, err = prompts.ParseOrder("shuffle")
+	assert.ErrorContains(t, err, "unknown prompt order")
+}
+
+func indexOf(list []string, s string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,132 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/calendar"
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// AgendaCacheTTL is how long the agenda fetched from a calendar URL is reused
+// before the calendar is asked again.
+const AgendaCacheTTL = time.Hour
+
+// Agenda is the data of the "agenda" provider: the events of the date in the
+// calendar of calendar.source, ordered by start.
+type Agenda []calendar.Event
+
+// String returns the events as a Markdown list, e.g.
+// "- 09:30–09:45 Stand-up (Room 1)", all-day events first.
+func (a Agenda) String() string {
+	var allDay, timed []string
+	for _, ev := range a {
+		item := ev.Summary
+		if ev.Location != "" {
+			item += " (" + ev.Location + ")"
+		}
+		if ev.AllDay {
+			allDay = append(allDay, "- All day: "+item)
+			continue
+		}
+		when := ev.Start.Local().Format("15:04")
+		if ev.End.After(ev.Start) {
+			when += "–" + ev.End.Local().Format("15:04")
+		}
+		timed = append(timed, "- "+when+" "+item)
+	}
+	return strings.Join(append(allDay, timed...), "\n")
+}
+
+type agendaProvider struct {
+	src calendar.Source
+	// cache is the directory the agendas of calendar URLs are kept in, or ""
+	// to fetch them every time.
+	cache string
+}
+
+func newAgenda(cfg config.Config) (Provider, error) {
+	if cfg.Calendar.Source == "" {
+		return nil, errors.New("no calendar configured (set calendar.source)")
+	}
+	p := agendaProvider{src: calendar.Source{
+		Location: cfg.Calendar.Source,
+		Username: cfg.Calendar.Username,
+		Password: os.Getenv(calendar.PasswordEnv),
+		Client:   &http.Client{Timeout: DefaultTimeout},
+	}}
+	if cfg.Dir.CacheDir != "" && calendar.IsURL(cfg.Calendar.Source) {
+		p.cache = filepath.Join(cfg.Dir.CacheDir, "agenda")
+	}
+	return p, nil
+}
+
+// Provide implements Provider. The agenda of a calendar URL is cached for
+// AgendaCacheTTL; when the calendar cannot be reached, as offline, the last
+// agenda fetched for the date is used however old it is.
+func (p agendaProvider) Provide(ctx context.Context, date time.Time) (interface{}, error) {
+	y, m, d := date.Date()
+	from := time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+	path := p.cachePath(from)
+	cached, fetched, cacheErr := p.cached(path)
+	if cacheErr == nil && time.Since(fetched) < AgendaCacheTTL {
+		return cached, nil
+	}
+	events, err := calendar.Load(ctx, p.src, from, from.AddDate(0, 0, 1))
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+	// The agenda is still good when it cannot be cached.
+	_ = p.store(path, events)
+	return Agenda(events), nil
+}
+
+// cachePath returns the file the agenda of day is cached in, or "" when
+// agendas are not cached. Calendars are told apart by a hash of their source.
+func (p agendaProvider) cachePath(day time.Time) string {
+	if p.cache == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(p.src.Location))
+	return filepath.Join(p.cache, fmt.Sprintf("%s-%x.json", day.Format("2006-01-02"), sum[:4]))
+}
+
+// cached returns the agenda cached at path and when it was fetched.
+func (p agendaProvider) cached(path string) (Agenda, time.Time, error) {
+	if path == "" {
+		return nil, time.Time{}, os.ErrNotExist
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	var agenda Agenda
+	if err := json.Unmarshal(data, &agenda); err != nil {
+		return nil, time.Time{}, err
+	}
+	return agenda, info.ModTime(), nil
+}
+
+// store caches events at path.
+func (p agendaProvider) store(path string, events []calendar.Event) error {
+	if path == "" {
+		return nil
+	}
+	return fs.WriteStateFile(path, "agenda cache", events)
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// Location is the data of the "location" provider: the configured place.
+type Location struct {
+	Name      string
+	Latitude  float64
+	Longitude float64
+}
+
+// String returns the name of the place, or its coordinates if it has none.
+func (l Location) String() string {
+	if l.Name != "" {
+		return l.Name
+	}
+	return fmt.Sprintf("%.4f, %.4f", l.Latitude, l.Longitude)
+}
+
+type locationProvider struct {
+	location Location
+}
+
+func newLocation(cfg config.Config) (Provider, error) {
+	if cfg.Location == (config.LocationConfig{}) {
+		return nil, errors.New("no location configured (set location.name, location.latitude and location.longitude)")
+	}
+	return locationProvider{Location(cfg.Location)}, nil
+}
+
+// Provide implements Provider.
+func (p locationProvider) Provide(context.Context, time.Time) (interface{}, error) {
+	return p.location, nil
+}
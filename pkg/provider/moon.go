@@ -0,0 +1,71 @@
+package provider
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// synodicMonth is the mean time, in days, from one new moon to the next.
+const synodicMonth = 29.530588853
+
+// knownNewMoon is a new moon the phase is computed from.
+var knownNewMoon = time.Date(2000, time.January, 6, 18, 14, 0, 0, time.UTC)
+
+// moonPhases names the eight phases of the moon, from the new moon on.
+var moonPhases = []struct{ name, emoji string }{
+	{"new moon", "🌑"},
+	{"waxing crescent", "🌒"},
+	{"first quarter", "🌓"},
+	{"waxing gibbous", "🌔"},
+	{"full moon", "🌕"},
+	{"waning gibbous", "🌖"},
+	{"last quarter", "🌗"},
+	{"waning crescent", "🌘"},
+}
+
+// Moon is the data of the "moon" provider: the phase of the moon at noon of
+// the date.
+type Moon struct {
+	// Phase is the name of the phase, e.g. "waxing crescent".
+	Phase string
+	Emoji string
+	// Age is the number of days since the last new moon.
+	Age float64
+	// Illumination is the illuminated percentage of the disc.
+	Illumination int
+}
+
+// String returns the emoji and name of the phase.
+func (m Moon) String() string {
+	return m.Emoji + " " + m.Phase
+}
+
+type moonProvider struct{}
+
+func newMoon(config.Config) (Provider, error) {
+	return moonProvider{}, nil
+}
+
+// Provide implements Provider.
+func (moonProvider) Provide(_ context.Context, date time.Time) (interface{}, error) {
+	return MoonAt(time.Date(date.Year(), date.Month(), date.Day(), 12, 0, 0, 0, date.Location())), nil
+}
+
+// MoonAt returns the phase of the moon at t, approximated from the mean length
+// of the lunar month, which is accurate to about a day.
+func MoonAt(t time.Time) Moon {
+	age := math.Mod(t.Sub(knownNewMoon).Hours()/24, synodicMonth)
+	if age < 0 {
+		age += synodicMonth
+	}
+	phase := moonPhases[int(math.Floor(age/synodicMonth*8+0.5))%8]
+	return Moon{
+		Phase:        phase.name,
+		Emoji:        phase.emoji,
+		Age:          math.Round(age*10) / 10,
+		Illumination: int(math.Round((1 - math.Cos(2*math.Pi*age/synodicMonth)) / 2 * 100)),
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/prompts"
+)
+
+type promptsProvider struct {
+	path  string
+	count int
+	order prompts.Order
+}
+
+func newPrompts(cfg config.Config) (Provider, error) {
+	order, err := prompts.ParseOrder(cfg.Prompts.Order)
+	if err != nil {
+		return nil, err
+	}
+	return promptsProvider{path: cfg.Prompts.Path, count: cfg.Prompts.Count, order: order}, nil
+}
+
+// Provide implements Provider. The data of the "prompts" provider is the list
+// of journaling prompts picked for the date.
+func (p promptsProvider) Provide(_ context.Context, date time.Time) (interface{}, error) {
+	if p.path == "" {
+		return nil, fmt.Errorf("no prompts file configured (set prompts.path)")
+	}
+	list, err := prompts.Load(p.path)
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, fmt.Errorf("no prompts in %s (add some with \"exo prompts add\")", p.path)
+	}
+	return prompts.Pick(list, date, p.count, p.order), nil
+}
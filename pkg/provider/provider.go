@@ -0,0 +1,116 @@
+// Package provider supplies data to note templates from sources outside the
+// note itself, such as the weather, the phase of the moon, the configured
+// location, the journaling prompts or the calendar agenda of the day.
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// DefaultTimeout bounds the time a provider is given to return its data.
+const DefaultTimeout = 10 * time.Second
+
+// Provider supplies template data for notes about a date.
+type Provider interface {
+	Provide(ctx context.Context, date time.Time) (interface{}, error)
+}
+
+// Factory creates a Provider from the configuration.
+type Factory func(cfg config.Config) (Provider, error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		"agenda":   newAgenda,
+		"location": newLocation,
+		"moon":     newMoon,
+		"prompts":  newPrompts,
+		"weather":  newWeather,
+	}
+)
+
+// Register makes a provider available under name, replacing any existing one.
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[name] = factory
+}
+
+// Names returns the names of the registered providers in sorted order.
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New returns the provider registered under name.
+func New(name string, cfg config.Config) (Provider, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown provider %q (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return factory(cfg)
+}
+
+// Data returns the data of the named providers for date, keyed by Key. The
+// providers that fail are left out, and their errors are returned together
+// with the data of the others.
+func Data(ctx context.Context, cfg config.Config, names []string, date time.Time) (map[string]interface{}, error) {
+	data := make(map[string]interface{}, len(names))
+	var errs []error
+	for _, name := range names {
+		v, err := provide(ctx, cfg, name, date)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("provider %s: %w", name, err))
+			continue
+		}
+		data[Key(name)] = v
+	}
+	return data, errors.Join(errs...)
+}
+
+// provide returns the data of the named provider for date.
+func provide(ctx context.Context, cfg config.Config, name string, date time.Time) (interface{}, error) {
+	p, err := New(name, cfg)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+	return p.Provide(ctx, date)
+}
+
+// Key returns the template data key of the named provider: the name in
+// CamelCase, e.g. "Weather" for "weather" and "WorkItems" for "work-items".
+func Key(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
@@ -0,0 +1,158 @@
+package provider_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticProvider string
+
+func (p staticProvider) Provide(context.Context, time.Time) (interface{}, error) {
+	if p == "" {
+		return nil, errors.New("nothing to provide")
+	}
+	return string(p), nil
+}
+
+func TestData(t *testing.T) {
+	provider.Register("test-quote", func(config.Config) (provider.Provider, error) { return staticProvider("Stay hungry."), nil })
+	provider.Register("test-broken", func(config.Config) (provider.Provider, error) { return staticProvider(""), nil })
+	assert.Contains(t, provider.Names(), "test-quote")
+
+	cfg := config.Config{Location: config.LocationConfig{Name: "Berlin", Latitude: 52.52, Longitude: 13.41}}
+	data, err := provider.Data(context.Background(), cfg, []string{"test-quote", "location", "test-broken", "nope"}, time.Now())
+	assert.ErrorContains(t, err, "provider test-broken: nothing to provide")
+	assert.ErrorContains(t, err, `unknown provider "nope"`)
+	assert.Equal(t, map[string]interface{}{
+		"TestQuote": "Stay hungry.",
+		"Location":  provider.Location{Name: "Berlin", Latitude: 52.52, Longitude: 13.41},
+	}, data)
+
+	_, err = provider.Data(context.Background(), config.Config{}, []string{"location"}, time.Now())
+	assert.ErrorContains(t, err, "no location configured")
+}
+
+func TestPrompts(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "prompts.md")
+	cfg := config.Config{Prompts: config.PromptsConfig{Path: file, Count: 2}}
+	_, err := provider.Data(context.Background(), cfg, []string{"prompts"}, time.Now())
+	assert.ErrorContains(t, err, "no prompts in")
+
+	require.NoError(t, os.WriteFile(file, []byte("- One?\n- Two?\n- Three?\n"), 0644))
+	data, err := provider.Data(context.Background(), cfg, []string{"prompts"}, time.Now())
+	require.NoError(t, err)
+	assert.Len(t, data["Prompts"], 2)
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "Weather", provider.Key("weather"))
+	assert.Equal(t, "WorkItems", provider.Key("work-items"))
+	assert.Equal(t, "Moon2", provider.Key("moon_2"))
+}
+
+func TestMoonAt(t *testing.T) {
+	tests := []struct {
+		date  time.Time
+		phase string
+	}{
+		{time.Date(2024, time.January, 11, 12, 0, 0, 0, time.UTC), "new moon"},
+		{time.Date(2024, time.January, 18, 12, 0, 0, 0, time.UTC), "first quarter"},
+		{time.Date(2024, time.January, 25, 18, 0, 0, 0, time.UTC), "full moon"},
+		{time.Date(2024, time.February, 2, 12, 0, 0, 0, time.UTC), "last quarter"},
+		{time.Date(1999, time.December, 22, 18, 0, 0, 0, time.UTC), "full moon"},
+	}
+	for _, tt := range tests {
+		m := provider.MoonAt(tt.date)
+		assert.Equal(t, tt.phase, m.Phase, tt.date.String())
+	}
+	full := provider.MoonAt(time.Date(2024, time.January, 25, 18, 0, 0, 0, time.UTC))
+	assert.GreaterOrEqual(t, full.Illumination, 98)
+	assert.Equal(t, "🌕 full moon", full.String())
+}
+
+func TestWeather(t *testing.T) {
+	var query map[string][]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		if r.URL.Query().Get("start_date") == "2025-02-09" {
+			w.Write([]byte(`{"daily":{"time":["2025-02-09"],"weather_code":[null],"temperature_2m_max":[null],"temperature_2m_min":[null]}}`))
+			return
+		}
+		w.Write([]byte(`{"daily":{"time":["2025-02-08"],"weather_code":[2],"temperature_2m_max":[41.5],"temperature_2m_min":[30.2],"precipitation_sum":[1.2]}}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.Config{
+		Location: config.LocationConfig{Latitude: 52.52, Longitude: 13.41},
+		Weather:  config.WeatherConfig{URL: srv.URL, Units: "fahrenheit"},
+	}
+	p, err := provider.New("weather", cfg)
+	require.NoError(t, err)
+	got, err := p.Provide(context.Background(), time.Date(2025, time.February, 8, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, provider.Weather{Summary: "Partly cloudy", Code: 2, High: 41.5, Low: 30.2, Unit: "°F", Precipitation: 1.2}, got)
+	assert.Equal(t, "Partly cloudy, 30–42 °F", got.(provider.Weather).String())
+	assert.Equal(t, []string{"52.52"}, query["latitude"])
+	assert.Equal(t, []string{"fahrenheit"}, query["temperature_unit"])
+
+	_, err = p.Provide(context.Background(), time.Date(2025, time.February, 9, 0, 0, 0, 0, time.UTC))
+	assert.ErrorContains(t, err, "no weather for 2025-02-09")
+
+	_, err = provider.New("weather", config.Config{})
+	assert.ErrorContains(t, err, "no location")
+}
+
+func TestAgenda(t *testing.T) {
+	ics := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VEVENT\r\nUID:standup\r\nSUMMARY:Stand-up\r\nLOCATION:Room 1\r\nDTSTART:20250208T093000\r\nDTEND:20250208T094500\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:offsite\r\nSUMMARY:Offsite\r\nDTSTART;VALUE=DATE:20250208\r\nDTEND;VALUE=DATE:20250209\r\nEND:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\nUID:later\r\nSUMMARY:Later\r\nDTSTART:20250210T100000\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(ics))
+	}))
+
+	cache := t.TempDir()
+	cfg := config.Config{Calendar: config.CalendarConfig{Source: srv.URL}, Dir: config.DirConfig{CacheDir: cache}}
+	p, err := provider.New("agenda", cfg)
+	require.NoError(t, err)
+	date := time.Date(2025, time.February, 8, 0, 0, 0, 0, time.Local)
+	got, err := p.Provide(context.Background(), date)
+	require.NoError(t, err)
+	agenda := got.(provider.Agenda)
+	require.Len(t, agenda, 2)
+	assert.Equal(t, "- All day: Offsite\n- 09:30–09:45 Stand-up (Room 1)", agenda.String())
+
+	_, err = p.Provide(context.Background(), date)
+	require.NoError(t, err)
+	assert.Equal(t, 1, requests, "the agenda is cached")
+
+	// Offline, an expired agenda is still used.
+	srv.Close()
+	files, err := filepath.Glob(filepath.Join(cache, "agenda", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	old := time.Now().Add(-2 * provider.AgendaCacheTTL)
+	require.NoError(t, os.Chtimes(files[0], old, old))
+	got, err = p.Provide(context.Background(), date)
+	require.NoError(t, err)
+	assert.Equal(t, agenda.String(), got.(provider.Agenda).String())
+	_, err = p.Provide(context.Background(), date.AddDate(0, 0, 1))
+	assert.ErrorContains(t, err, "failed to fetch calendar")
+
+	_, err = provider.New("agenda", config.Config{})
+	assert.ErrorContains(t, err, "no calendar configured")
+}
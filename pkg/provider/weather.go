@@ -0,0 +1,164 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// DefaultWeatherURL is the Open-Meteo forecast API.
+const DefaultWeatherURL = "https://api.open-meteo.com/v1/forecast"
+
+// weatherCodes describes the WMO weather codes returned by Open-Meteo.
+var weatherCodes = map[int]string{
+	0:  "Clear sky",
+	1:  "Mainly clear",
+	2:  "Partly cloudy",
+	3:  "Overcast",
+	45: "Fog",
+	48: "Rime fog",
+	51: "Light drizzle",
+	53: "Drizzle",
+	55: "Dense drizzle",
+	56: "Freezing drizzle",
+	57: "Freezing drizzle",
+	61: "Light rain",
+	63: "Rain",
+	65: "Heavy rain",
+	66: "Freezing rain",
+	67: "Freezing rain",
+	71: "Light snow",
+	73: "Snow",
+	75: "Heavy snow",
+	77: "Snow grains",
+	80: "Light showers",
+	81: "Showers",
+	82: "Violent showers",
+	85: "Snow showers",
+	86: "Heavy snow showers",
+	95: "Thunderstorm",
+	96: "Thunderstorm with hail",
+	99: "Thunderstorm with heavy hail",
+}
+
+// Weather is the data of the "weather" provider: the forecast, or the
+// recorded weather, of the date at the configured location.
+type Weather struct {
+	// Summary describes the weather, e.g. "Partly cloudy".
+	Summary string
+	// Code is the WMO weather code.
+	Code int
+	// High and Low are the highest and lowest temperatures, in Unit.
+	High, Low float64
+	// Unit is "°C" or "°F".
+	Unit string
+	// Precipitation is the total precipitation in millimeters.
+	Precipitation float64
+}
+
+// String returns the summary and the temperature range.
+func (w Weather) String() string {
+	return fmt.Sprintf("%s, %.0f–%.0f %s", w.Summary, w.Low, w.High, w.Unit)
+}
+
+type weatherProvider struct {
+	url       string
+	units     string
+	latitude  float64
+	longitude float64
+	client    *http.Client
+}
+
+func newWeather(cfg config.Config) (Provider, error) {
+	if cfg.Location.Latitude == 0 && cfg.Location.Longitude == 0 {
+		return nil, errors.New("no location to forecast (set location.latitude and location.longitude)")
+	}
+	p := weatherProvider{
+		url:       cfg.Weather.URL,
+		units:     cfg.Weather.Units,
+		latitude:  cfg.Location.Latitude,
+		longitude: cfg.Location.Longitude,
+		client:    &http.Client{Timeout: DefaultTimeout},
+	}
+	if p.url == "" {
+		p.url = DefaultWeatherURL
+	}
+	if p.units == "" {
+		p.units = "celsius"
+	}
+	return p, nil
+}
+
+// Provide implements Provider.
+func (p weatherProvider) Provide(ctx context.Context, date time.Time) (interface{}, error) {
+	day := date.Format("2006-01-02")
+	q := url.Values{}
+	q.Set("latitude", strconv.FormatFloat(p.latitude, 'f', -1, 64))
+	q.Set("longitude", strconv.FormatFloat(p.longitude, 'f', -1, 64))
+	q.Set("daily", "weather_code,temperature_2m_max,temperature_2m_min,precipitation_sum")
+	q.Set("temperature_unit", p.units)
+	q.Set("timezone", "auto")
+	q.Set("start_date", day)
+	q.Set("end_date", day)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url+"?"+q.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid weather URL: %w", err)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch weather: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch weather: %s", resp.Status)
+	}
+
+	var forecast struct {
+		Daily struct {
+			Time          []string   `json:"time"`
+			Code          []*int     `json:"weather_code"`
+			High          []*float64 `json:"temperature_2m_max"`
+			Low           []*float64 `json:"temperature_2m_min"`
+			Precipitation []*float64 `json:"precipitation_sum"`
+		} `json:"daily"`
+	}
+	if err := json.Unmarshal(body, &forecast); err != nil {
+		return nil, fmt.Errorf("failed to decode weather: %w", err)
+	}
+	d := forecast.Daily
+	for i, t := range d.Time {
+		if t != day || i >= len(d.Code) || i >= len(d.High) || i >= len(d.Low) || d.Code[i] == nil || d.High[i] == nil || d.Low[i] == nil {
+			continue
+		}
+		w := Weather{
+			Summary: weatherCodes[*d.Code[i]],
+			Code:    *d.Code[i],
+			High:    *d.High[i],
+			Low:     *d.Low[i],
+			Unit:    "°C",
+		}
+		if w.Summary == "" {
+			w.Summary = fmt.Sprintf("Weather code %d", w.Code)
+		}
+		if p.units == "fahrenheit" {
+			w.Unit = "°F"
+		}
+		if i < len(d.Precipitation) && d.Precipitation[i] != nil {
+			w.Precipitation = *d.Precipitation[i]
+		}
+		return w, nil
+	}
+	return nil, fmt.Errorf("no weather for %s", day)
+}
@@ -0,0 +1,382 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Parse parses the query input. Ages such as 30d are taken before now. An
+// empty input matches every note.
+func Parse(input string, now time.Time) (Query, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return Query{}, err
+	}
+	p := &parser{tokens: tokens, now: now}
+	if p.peek().kind == tokEOF {
+		return Query{}, nil
+	}
+	e, err := p.parseOr()
+	if err != nil {
+		return Query{}, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return Query{}, fmt.Errorf("unexpected %s at column %d", tok, tok.pos)
+	}
+	return Query{Expr: e}, nil
+}
+
+// tokenKind is the kind of a token.
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+	tokTerm
+)
+
+// token is a lexed token. Terms have a field unless they are text.
+type token struct {
+	kind   tokenKind
+	pos    int
+	field  string
+	op     string
+	value  string
+	quoted bool
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokLParen:
+		return `"("`
+	case tokRParen:
+		return `")"`
+	case tokAnd:
+		return "AND"
+	case tokOr:
+		return "OR"
+	case tokNot:
+		return "NOT"
+	}
+	if t.field != "" {
+		return strconv.Quote(t.field + ":" + t.op + t.value)
+	}
+	return strconv.Quote(t.value)
+}
+
+// lex splits input into tokens.
+func lex(input string) ([]token, error) {
+	var tokens []token
+	i := 0
+	for {
+		for i < len(input) && isSpace(input[i]) {
+			i++
+		}
+		if i == len(input) {
+			return append(tokens, token{kind: tokEOF, pos: i + 1}), nil
+		}
+		pos := i + 1
+		switch c := input[i]; {
+		case c == '(':
+			tokens = append(tokens, token{kind: tokLParen, pos: pos})
+			i++
+			continue
+		case c == ')':
+			tokens = append(tokens, token{kind: tokRParen, pos: pos})
+			i++
+			continue
+		case c == '-' && i+1 < len(input) && !isSpace(input[i+1]) && input[i+1] != ')':
+			tokens = append(tokens, token{kind: tokNot, pos: pos})
+			i++
+			continue
+		case c == '"':
+			value, next, err := lexQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokTerm, pos: pos, value: value, quoted: true})
+			i = next
+			continue
+		}
+
+		start := i
+		for i < len(input) && !isSpace(input[i]) && !isDelim(input[i]) && input[i] != ':' {
+			i++
+		}
+		word := input[start:i]
+		if i == len(input) || input[i] != ':' {
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, pos: pos})
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, pos: pos})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, pos: pos})
+			default:
+				tokens = append(tokens, token{kind: tokTerm, pos: pos, value: word})
+			}
+			continue
+		}
+
+		tok := token{kind: tokTerm, pos: pos, field: strings.ToLower(word)}
+		i++
+		for _, op := range []string{"<=", ">=", "<", ">", "="} {
+			if strings.HasPrefix(input[i:], op) {
+				tok.op = op
+				i += len(op)
+				break
+			}
+		}
+		if i < len(input) && input[i] == '"' {
+			value, next, err := lexQuoted(input, i)
+			if err != nil {
+				return nil, err
+			}
+			tok.value, tok.quoted, i = value, true, next
+		} else {
+			start := i
+			for i < len(input) && !isSpace(input[i]) && !isDelim(input[i]) {
+				i++
+			}
+			tok.value = input[start:i]
+		}
+		if tok.field == "" {
+			return nil, fmt.Errorf("missing field name before \":\" at column %d", pos)
+		}
+		if tok.value == "" && !tok.quoted {
+			return nil, fmt.Errorf("missing value for %q at column %d", tok.field, pos)
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+// lexQuoted reads the double-quoted string starting at input[i], in which \"
+// and \\ stand for a quote and a backslash, and returns its value and the
+// position after its closing quote.
+func lexQuoted(input string, i int) (string, int, error) {
+	var sb strings.Builder
+	for j := i + 1; j < len(input); j++ {
+		switch input[j] {
+		case '\\':
+			if j+1 < len(input) && (input[j+1] == '"' || input[j+1] == '\\') {
+				j++
+			}
+			sb.WriteByte(input[j])
+		case '"':
+			return sb.String(), j + 1, nil
+		default:
+			sb.WriteByte(input[j])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated quote at column %d", i+1)
+}
+
+func isSpace(c byte) bool { return unicode.IsSpace(rune(c)) }
+func isDelim(c byte) bool { return c == '(' || c == ')' || c == '"' }
+
+// parser builds expressions from tokens by recursive descent.
+type parser struct {
+	tokens []token
+	pos    int
+	now    time.Time
+}
+
+func (p *parser) peek() token { return p.tokens[p.pos] }
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses expressions separated by OR.
+func (p *parser) parseOr() (Expr, error) {
+	var or Or
+	for {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, e)
+		if p.peek().kind != tokOr {
+			break
+		}
+		p.next()
+	}
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+// parseAnd parses expressions separated by AND or written next to each other.
+func (p *parser) parseAnd() (Expr, error) {
+	var and And
+	for {
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, e)
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+			continue
+		case tokEOF, tokRParen, tokOr:
+		default:
+			continue
+		}
+		break
+	}
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+// parseUnary parses a term, a negation or a group in parentheses.
+func (p *parser) parseUnary() (Expr, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokNot:
+		e, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return Not{Expr: e}, nil
+	case tokLParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if end := p.next(); end.kind != tokRParen {
+			return nil, fmt.Errorf("missing \")\" for \"(\" at column %d", tok.pos)
+		}
+		return e, nil
+	case tokTerm:
+		return p.term(tok)
+	}
+	return nil, fmt.Errorf("unexpected %s at column %d", tok, tok.pos)
+}
+
+// term returns the term of tok.
+func (p *parser) term(tok token) (Expr, error) {
+	if tok.field == "" {
+		if strings.HasPrefix(tok.value, "#") && !tok.quoted && len(tok.value) > 1 {
+			return Term{Field: Tag, Value: tok.value[1:]}, nil
+		}
+		return Term{Field: Text, Value: tok.value}, nil
+	}
+	t := Term{Field: Field(tok.field), Op: tok.op, Value: tok.value}
+	switch t.Field {
+	case Tag:
+		t.Value = strings.TrimPrefix(t.Value, "#")
+	case Type, Title, Text:
+	case Created, Modified:
+		from, to, err := timeRange(t.Op, t.Value, p.now)
+		if err != nil {
+			return nil, fmt.Errorf("%s at column %d", err, tok.pos)
+		}
+		t.From, t.To = from, to
+		return t, nil
+	default:
+		names := make([]string, len(Fields))
+		for i, f := range Fields {
+			names[i] = string(f)
+		}
+		return nil, fmt.Errorf("unknown field %q at column %d (expected %s)", tok.field, tok.pos, strings.Join(names, ", "))
+	}
+	if t.Op != "" {
+		return nil, fmt.Errorf("%s cannot be compared with %q at column %d", t.Field, t.Op, tok.pos)
+	}
+	if strings.TrimSpace(t.Value) == "" {
+		return nil, fmt.Errorf("missing value for %q at column %d", t.Field, tok.pos)
+	}
+	return t, nil
+}
+
+// timeRange returns the times from (inclusive) to (exclusive) matched by
+// comparing with op a date (YYYY, YYYY-MM or YYYY-MM-DD, today or yesterday),
+// which spans a period, or an age before now, which is an instant.
+func timeRange(op, value string, now time.Time) (from, to time.Time, err error) {
+	start, end, ok := period(value, now)
+	if !ok {
+		t, err := parseAge(value, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		if op == "<" || op == "<=" {
+			return time.Time{}, t, nil
+		}
+		return t, time.Time{}, nil
+	}
+	switch op {
+	case "<":
+		return time.Time{}, start, nil
+	case "<=":
+		return time.Time{}, end, nil
+	case ">":
+		return end, time.Time{}, nil
+	case ">=":
+		return start, time.Time{}, nil
+	}
+	return start, end, nil
+}
+
+// period returns the start and end of the day, month or year written as value.
+func period(value string, now time.Time) (start, end time.Time, ok bool) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch strings.ToLower(value) {
+	case "today":
+		return today, today.AddDate(0, 0, 1), true
+	case "yesterday":
+		return today.AddDate(0, 0, -1), today, true
+	}
+	for _, layout := range []struct {
+		layout              string
+		years, months, days int
+	}{
+		{"2006-01-02", 0, 0, 1},
+		{"2006-01", 0, 1, 0},
+		{"2006", 1, 0, 0},
+	} {
+		if t, err := time.ParseInLocation(layout.layout, value, now.Location()); err == nil {
+			return t, t.AddDate(layout.years, layout.months, layout.days), true
+		}
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// parseAge parses an age in years, months, weeks or days (e.g. 2y, 6m, 3w,
+// 30d) or a duration (e.g. 12h) into the time that long before now.
+func parseAge(value string, now time.Time) (time.Time, error) {
+	if len(value) > 1 {
+		if n, err := strconv.Atoi(value[:len(value)-1]); err == nil && n >= 0 {
+			switch value[len(value)-1] {
+			case 'y':
+				return now.AddDate(-n, 0, 0), nil
+			case 'm':
+				return now.AddDate(0, -n, 0), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			}
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d >= 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date or age %q (expected e.g. 2024-01-31, 2024-01, today or 30d)", value)
+}
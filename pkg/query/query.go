@@ -0,0 +1,145 @@
+// Package query parses the query language used to select notes, e.g.
+//
+//	tag:project AND modified:>2024-01-01 AND "kubernetes"
+//
+// A query is made of terms combined with AND (also implied between adjacent
+// terms), OR and NOT (also written as a leading "-"), grouped with
+// parentheses. NOT binds tighter than AND, which binds tighter than OR. A term
+// is a field and a value, "field:value", or text to search for: a word, or a
+// phrase in double quotes. The fields are:
+//
+//	tag:go             notes with the tag (#go is short for it)
+//	type:zettel        notes of the type
+//	title:chan         notes whose title or an alias contains the text
+//	text:channels      notes containing the text, like a bare word
+//	created:2024-01    notes created on a day, in a month or a year
+//	modified:>=30d     notes modified within an age, e.g. 30d, 6m or 2y
+//
+// Dates are compared with <, <=, > and >=; an age alone means "since".
+package query
+
+import (
+	"strings"
+	"time"
+)
+
+// Field names what a term matches.
+type Field string
+
+// The fields of terms.
+const (
+	// Tag matches notes carrying the tag, case-insensitively.
+	Tag Field = "tag"
+	// Type matches the type of notes.
+	Type Field = "type"
+	// Title matches notes whose title or an alias contains the value,
+	// case-insensitively.
+	Title Field = "title"
+	// Text matches notes containing the value, a word or a phrase.
+	Text Field = "text"
+	// Created matches notes created within a time range.
+	Created Field = "created"
+	// Modified matches notes modified within a time range.
+	Modified Field = "modified"
+)
+
+// Fields lists the fields of terms.
+var Fields = []Field{Tag, Type, Title, Text, Created, Modified}
+
+// Expr is a node of a parsed query: an And, Or, Not or Term.
+type Expr interface {
+	String() string
+	expr()
+}
+
+// And matches notes matched by all of its expressions.
+type And []Expr
+
+// Or matches notes matched by any of its expressions.
+type Or []Expr
+
+// Not matches notes not matched by its expression.
+type Not struct {
+	Expr Expr
+}
+
+// Term matches notes by a field.
+type Term struct {
+	Field Field
+	// Op is the comparison written before the value of a time field: "",
+	// "<", "<=", ">" or ">=".
+	Op string
+	// Value is the value as written, without quotes.
+	Value string
+	// From and To bound the times matched by a time field, From <= t < To.
+	// A zero bound is open.
+	From, To time.Time
+}
+
+func (And) expr()  {}
+func (Or) expr()   {}
+func (Not) expr()  {}
+func (Term) expr() {}
+
+func (e And) String() string { return join(e, " AND ") }
+func (e Or) String() string  { return join(e, " OR ") }
+func (e Not) String() string { return "NOT " + group(e.Expr) }
+
+func (t Term) String() string {
+	if t.Field == Text {
+		return Quote(t.Value)
+	}
+	return string(t.Field) + ":" + t.Op + Quote(t.Value)
+}
+
+// join writes exprs separated by sep, grouping the ones that need it.
+func join(exprs []Expr, sep string) string {
+	parts := make([]string, len(exprs))
+	for i, e := range exprs {
+		parts[i] = group(e)
+	}
+	return strings.Join(parts, sep)
+}
+
+// group writes e, in parentheses unless it is a term or a negation.
+func group(e Expr) string {
+	switch e.(type) {
+	case Term, Not:
+		return e.String()
+	}
+	return "(" + e.String() + ")"
+}
+
+// Query is a parsed query.
+type Query struct {
+	// Expr is the root of the query; nil matches every note.
+	Expr Expr
+}
+
+// String returns the query in its canonical form.
+func (q Query) String() string {
+	if q.Expr == nil {
+		return ""
+	}
+	return q.Expr.String()
+}
+
+// IsEmpty reports whether the query matches every note.
+func (q Query) IsEmpty() bool {
+	return q.Expr == nil
+}
+
+// Quote returns value as written in a query: as is when it is a single word
+// that cannot be taken for anything else, otherwise in double quotes.
+func Quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, " \t\n\"():\\") && !strings.HasPrefix(value, "#") &&
+		!strings.HasPrefix(value, "-") && !isKeyword(value) {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// isKeyword reports whether word is an operator of the language.
+func isKeyword(word string) bool {
+	return word == "AND" || word == "OR" || word == "NOT"
+}
@@ -0,0 +1,107 @@
+package query_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", ""},
+		{`tag:project AND modified:>2024-01-01 AND "kubernetes"`, "tag:project AND modified:>2024-01-01 AND kubernetes"},
+		{"tag:a tag:b OR tag:c", "(tag:a AND tag:b) OR tag:c"},
+		{"tag:a (tag:b OR tag:c)", "tag:a AND (tag:b OR tag:c)"},
+		{"NOT tag:a -#b", "NOT tag:a AND NOT tag:b"},
+		{"-(a OR b)", "NOT (a OR b)"},
+		{`title:"go channels" TYPE:Zettel`, `title:"go channels" AND type:Zettel`},
+		{`"say \"hi\""`, `"say \"hi\""`},
+		{"well-known", "well-known"},
+		{`"AND"`, `"AND"`},
+	}
+	for _, tt := range tests {
+		q, err := query.Parse(tt.input, now)
+		require.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, q.String(), tt.input)
+	}
+
+	q, err := query.Parse(`-tag:a "b c"`, now)
+	require.NoError(t, err)
+	assert.Equal(t, query.And{
+		query.Not{Expr: query.Term{Field: query.Tag, Value: "a"}},
+		query.Term{Field: query.Text, Value: "b c"},
+	}, q.Expr)
+}
+
+func TestParse_Times(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	tests := []struct {
+		input    string
+		from, to time.Time
+	}{
+		{"created:2024-01-31", day(2024, 1, 31), day(2024, 2, 1)},
+		{"created:2024-02", day(2024, 2, 1), day(2024, 3, 1)},
+		{"created:2024", day(2024, 1, 1), day(2025, 1, 1)},
+		{"modified:>2024-01-01", day(2024, 1, 2), time.Time{}},
+		{"modified:>=2024-01", day(2024, 1, 1), time.Time{}},
+		{"modified:<2024", time.Time{}, day(2024, 1, 1)},
+		{"modified:<=2024-01-31", time.Time{}, day(2024, 2, 1)},
+		{"modified:today", day(2025, 3, 15), day(2025, 3, 16)},
+		{"modified:yesterday", day(2025, 3, 14), day(2025, 3, 15)},
+		{"created:30d", now.AddDate(0, 0, -30), time.Time{}},
+		{"created:>=6m", now.AddDate(0, -6, 0), time.Time{}},
+		{"created:<2y", time.Time{}, now.AddDate(-2, 0, 0)},
+		{"created:>12h", now.Add(-12 * time.Hour), time.Time{}},
+	}
+	for _, tt := range tests {
+		q, err := query.Parse(tt.input, now)
+		require.NoError(t, err, tt.input)
+		term, ok := q.Expr.(query.Term)
+		require.True(t, ok, tt.input)
+		assert.Equal(t, tt.from, term.From, tt.input)
+		assert.Equal(t, tt.to, term.To, tt.input)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"tag:a OR", "unexpected end of query at column 9"},
+		{"(tag:a", `missing ")"`},
+		{"tag:a)", `unexpected ")" at column 6`},
+		{`"open`, "unterminated quote at column 1"},
+		{"size:3", `unknown field "size"`},
+		{"tag:", `missing value for "tag"`},
+		{`title:""`, `missing value for "title"`},
+		{"tag:>a", "tag cannot be compared"},
+		{"created:last-week", `invalid date or age "last-week"`},
+		{":x", "missing field name"},
+		{"AND tag:a", "unexpected AND at column 1"},
+	}
+	for _, tt := range tests {
+		_, err := query.Parse(tt.input, now)
+		require.Error(t, err, tt.input)
+		assert.Contains(t, err.Error(), tt.want, tt.input)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "go", query.Quote("go"))
+	assert.Equal(t, `"go channels"`, query.Quote("go channels"))
+	assert.Equal(t, `"OR"`, query.Quote("OR"))
+	assert.Equal(t, `"a\"b"`, query.Quote(`a"b`))
+	assert.Equal(t, `""`, query.Quote(""))
+	assert.Equal(t, `"#go"`, query.Quote("#go"))
+	assert.Equal(t, `"-x"`, query.Quote("-x"))
+	assert.Equal(t, `"a:b"`, query.Quote("a:b"))
+}
@@ -0,0 +1,132 @@
+// Package recent records the notes opened and edited, so that recently and
+// frequently used notes can be listed and ranked first.
+package recent
+
+import (
+	"sort"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// File is the state file, in the state directory, uses are recorded in.
+const File = "recent.json"
+
+// MaxEntries is the number of notes a Store keeps; the least recently used
+// notes beyond it are forgotten.
+const MaxEntries = 500
+
+// Event is a use of a note.
+type Event string
+
+// The events recorded.
+const (
+	Open Event = "open"
+	Edit Event = "edit"
+)
+
+// Entry is the use of a note.
+type Entry struct {
+	Path     string    `json:"path"`
+	Opens    int       `json:"opens"`
+	Edits    int       `json:"edits"`
+	LastUsed time.Time `json:"last_used"`
+}
+
+// Uses returns the number of times the note was opened or edited.
+func (e Entry) Uses() int {
+	return e.Opens + e.Edits
+}
+
+// Frecency scores the use of the note at now: its uses weighted by how
+// recently it was last used, from 100 within four days down to 10 after three
+// months.
+func (e Entry) Frecency(now time.Time) float64 {
+	var weight float64
+	switch age := now.Sub(e.LastUsed); {
+	case age <= 4*24*time.Hour:
+		weight = 100
+	case age <= 14*24*time.Hour:
+		weight = 70
+	case age <= 31*24*time.Hour:
+		weight = 50
+	case age <= 90*24*time.Hour:
+		weight = 30
+	default:
+		weight = 10
+	}
+	return weight * float64(e.Uses())
+}
+
+// Store keeps the use of notes in a JSON file in the state directory.
+type Store struct {
+	path    string
+	Entries map[string]Entry
+}
+
+// OpenStore reads the store at path. A missing file yields an empty store.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, Entries: make(map[string]Entry)}
+	var entries []Entry
+	if err := fs.ReadStateFile(path, "recent notes", &entries); err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		s.Entries[e.Path] = e
+	}
+	return s, nil
+}
+
+// Save writes the store to its file, most recently used first, creating the
+// directory if needed.
+func (s *Store) Save() error {
+	entries := s.Recent()
+	if len(entries) > MaxEntries {
+		entries = entries[:MaxEntries]
+	}
+	return fs.WriteStateFile(s.path, "recent notes", entries)
+}
+
+// Record records event on the note at path at now.
+func (s *Store) Record(path string, event Event, now time.Time) {
+	e := s.Entries[path]
+	e.Path = path
+	switch event {
+	case Open:
+		e.Opens++
+	case Edit:
+		e.Edits++
+	}
+	e.LastUsed = now
+	s.Entries[path] = e
+}
+
+// Forget removes the note at path from the store.
+func (s *Store) Forget(path string) {
+	delete(s.Entries, path)
+}
+
+// Recent returns the entries, the most recently used first.
+func (s *Store) Recent() []Entry {
+	entries := s.list()
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].LastUsed.After(entries[j].LastUsed) })
+	return entries
+}
+
+// Frecent returns the entries with the highest frecency at now first, then
+// the most recently used.
+func (s *Store) Frecent(now time.Time) []Entry {
+	entries := s.Recent()
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Frecency(now) > entries[j].Frecency(now) })
+	return entries
+}
+
+// list returns the entries ordered by path.
+func (s *Store) list() []Entry {
+	entries := make([]Entry, 0, len(s.Entries))
+	for _, e := range s.Entries {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Path < entries[j].Path })
+	return entries
+}
@@ -0,0 +1,77 @@
+package recent_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/recent"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".exo", "recent.json")
+	s, err := recent.OpenStore(path)
+	require.NoError(t, err)
+	assert.Empty(t, s.Recent())
+
+	now := time.Date(2025, 2, 8, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		s.Record("/v/old.md", recent.Open, now.AddDate(0, 0, -100))
+	}
+	s.Record("/v/daily.md", recent.Edit, now.Add(-time.Hour))
+	s.Record("/v/daily.md", recent.Open, now.Add(-time.Hour))
+	s.Record("/v/new.md", recent.Open, now)
+	require.NoError(t, s.Save())
+
+	s, err = recent.OpenStore(path)
+	require.NoError(t, err)
+	paths := func(entries []recent.Entry) []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Path)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"/v/new.md", "/v/daily.md", "/v/old.md"}, paths(s.Recent()))
+	// daily: 2 uses × 100, new: 1 use × 100, old: 5 uses × 10.
+	assert.Equal(t, []string{"/v/daily.md", "/v/new.md", "/v/old.md"}, paths(s.Frecent(now)))
+	assert.Equal(t, 1, s.Entries["/v/daily.md"].Edits)
+
+	s.Forget("/v/old.md")
+	assert.Equal(t, []string{"/v/new.md", "/v/daily.md"}, paths(s.Recent()))
+}
+
+func TestOpenStore_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recent.json")
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0644))
+	_, err := recent.OpenStore(path)
+	assert.ErrorContains(t, err, "failed to parse recent notes")
+}
+
+func TestTrack(t *testing.T) {
+	root := t.TempDir()
+	templates := filepath.Join(root, "templates")
+	state := filepath.Join(root, recent.File)
+	fsys := recent.Track(fs.NewOSFileSystem(), state, root, []string{templates}, testutil.NewDummyLogger())
+
+	note := filepath.Join(root, "zettel", "go.md")
+	require.NoError(t, fsys.WriteFile(note, []byte("# Go\n")))
+	require.NoError(t, fsys.WriteFile(filepath.Join(templates, "day.md"), []byte("# Day\n")))
+	require.NoError(t, fsys.WriteFile(filepath.Join(root, "notes.txt"), []byte("text")))
+	require.NoError(t, fsys.WriteFile(filepath.Join(t.TempDir(), "elsewhere.md"), []byte("# Elsewhere\n")))
+
+	s, err := recent.OpenStore(state)
+	require.NoError(t, err)
+	require.Len(t, s.Entries, 1, "only notes under the root are tracked")
+	assert.Equal(t, 1, s.Entries[note].Edits)
+
+	require.NoError(t, fsys.DeleteFile(note))
+	s, err = recent.OpenStore(state)
+	require.NoError(t, err)
+	assert.Empty(t, s.Entries)
+}
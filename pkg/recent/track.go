@@ -0,0 +1,90 @@
+package recent
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+)
+
+// TrackingFileSystem wraps a FileSystem, recording in a Store the notes under
+// a root directory that are opened in the editor or written.
+type TrackingFileSystem struct {
+	fs.FileSystem
+	path    string
+	root    string
+	exclude []string
+	logger  logger.Logger
+	now     func() time.Time
+}
+
+// Track returns a TrackingFileSystem recording the uses of the Markdown files
+// under root, other than those under the exclude directories, in the store at
+// path. Failures to record are logged and do not fail the file operation.
+func Track(fsys fs.FileSystem, path, root string, exclude []string, log logger.Logger) *TrackingFileSystem {
+	return &TrackingFileSystem{FileSystem: fsys, path: path, root: root, exclude: exclude, logger: log, now: time.Now}
+}
+
+// WriteFile writes the file and records an edit of it.
+func (t *TrackingFileSystem) WriteFile(path string, content []byte) error {
+	if err := t.FileSystem.WriteFile(path, content); err != nil {
+		return err
+	}
+	t.record(path, func(s *Store) { s.Record(path, Edit, t.now()) })
+	return nil
+}
+
+// OpenInEditor opens the file and records an opening of it.
+func (t *TrackingFileSystem) OpenInEditor(path, editor string) error {
+	if err := t.FileSystem.OpenInEditor(path, editor); err != nil {
+		return err
+	}
+	t.record(path, func(s *Store) { s.Record(path, Open, t.now()) })
+	return nil
+}
+
+// DeleteFile deletes the file and forgets its uses.
+func (t *TrackingFileSystem) DeleteFile(path string) error {
+	if err := t.FileSystem.DeleteFile(path); err != nil {
+		return err
+	}
+	t.record(path, func(s *Store) { s.Forget(path) })
+	return nil
+}
+
+// record applies update to the store if path is a tracked note.
+func (t *TrackingFileSystem) record(path string, update func(*Store)) {
+	path, err := filepath.Abs(path)
+	if err != nil || !t.tracks(path) {
+		return
+	}
+	s, err := OpenStore(t.path)
+	if err == nil {
+		update(s)
+		err = s.Save()
+	}
+	if err != nil && t.logger != nil {
+		t.logger.Errorf("Failed to record the use of %s: %v", path, err)
+	}
+}
+
+// tracks reports whether the uses of the file at the absolute path are recorded.
+func (t *TrackingFileSystem) tracks(path string) bool {
+	if filepath.Ext(path) != ".md" || !within(t.root, path) {
+		return false
+	}
+	for _, dir := range t.exclude {
+		if dir != "" && within(dir, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// within reports whether path lies under dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
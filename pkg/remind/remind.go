@@ -0,0 +1,139 @@
+// Package remind finds the reminders written in notes as @remind annotations,
+// such as "Renew the passport @remind(2024-03-01 09:00)", and keeps track of
+// those already notified.
+package remind
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// DefaultTime is the time of day of reminders given without one, as in
+// @remind(2024-03-01).
+const DefaultTime = "09:00"
+
+var (
+	// annotationPattern matches @remind(YYYY-MM-DD) and @remind(YYYY-MM-DD HH:MM).
+	annotationPattern = regexp.MustCompile(`@remind\(\s*(\d{4}-\d{2}-\d{2})(?:[ T]+(\d{1,2}:\d{2}))?\s*\)`)
+	// codeSpanPattern matches the code spans of a line, whose annotations are
+	// examples rather than reminders.
+	codeSpanPattern = regexp.MustCompile("`[^`]*`")
+	// itemPattern matches the list marker and checkbox starting a line.
+	itemPattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?`)
+	// donePattern matches checked tasks, whose reminders are done with.
+	donePattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+\[[xX]\]`)
+)
+
+// Reminder is an @remind annotation of a note.
+type Reminder struct {
+	// ID identifies the reminder among those notified: the slash-separated
+	// path of its note relative to the vault, its time and its text.
+	ID    string    `json:"id"`
+	Path  string    `json:"path"`
+	Title string    `json:"title"`
+	At    time.Time `json:"at"`
+	// Line is the line number of the annotation, counted from 1.
+	Line int `json:"line"`
+	// Text is the line of the annotation without it and its list marker,
+	// e.g. "Renew the passport".
+	Text string `json:"text"`
+}
+
+// String returns r as "2024-03-01 09:00  Title: text".
+func (r Reminder) String() string {
+	s := r.At.Format("2006-01-02 15:04") + "  " + r.Title
+	if r.Text != "" {
+		s += ": " + r.Text
+	}
+	return s
+}
+
+// Parse returns the reminders of content, without their ID, path and title,
+// in the order they appear. Annotations in the frontmatter, code blocks and
+// code spans, in checked tasks and with invalid dates are left out. Times are
+// local.
+func Parse(content string) []Reminder {
+	var reminders []Reminder
+	doc := md.Parse(content)
+	for i, line := range doc.Lines {
+		if doc.Code(i) || !strings.Contains(line, "@remind(") {
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if donePattern.MatchString(trimmed) {
+			continue
+		}
+		prose := codeSpanPattern.ReplaceAllStringFunc(line, func(s string) string {
+			return strings.Repeat(" ", len(s))
+		})
+		matches := annotationPattern.FindAllStringSubmatchIndex(prose, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		text := line
+		for j := len(matches) - 1; j >= 0; j-- {
+			text = text[:matches[j][0]] + text[matches[j][1]:]
+		}
+		text = strings.Join(strings.Fields(itemPattern.ReplaceAllString(strings.TrimSpace(text), "")), " ")
+		for _, m := range matches {
+			clock := DefaultTime
+			if m[4] >= 0 {
+				clock = line[m[4]:m[5]]
+			}
+			at, err := time.ParseInLocation("2006-01-02 15:04", line[m[2]:m[3]]+" "+clock, time.Local)
+			if err != nil {
+				continue
+			}
+			reminders = append(reminders, Reminder{At: at, Line: i + 1, Text: text})
+		}
+	}
+	return reminders
+}
+
+// Collect returns the reminders of notes located under root, ordered by time.
+func Collect(ctx context.Context, root string, notes []scan.Note) ([]Reminder, error) {
+	var reminders []Reminder
+	for _, n := range notes {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(n.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", n.Path, err)
+		}
+		if !strings.Contains(string(content), "@remind(") {
+			continue
+		}
+		rel, err := filepath.Rel(root, n.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range Parse(string(content)) {
+			r.ID = filepath.ToSlash(rel) + "#" + r.At.Format("2006-01-02T15:04") + " " + r.Text
+			r.Path, r.Title = n.Path, n.Title
+			reminders = append(reminders, r)
+		}
+	}
+	sort.SliceStable(reminders, func(i, j int) bool { return reminders[i].At.Before(reminders[j].At) })
+	return reminders, nil
+}
+
+// Between returns the reminders, ordered by time, falling in [from, to).
+func Between(reminders []Reminder, from, to time.Time) []Reminder {
+	var between []Reminder
+	for _, r := range reminders {
+		if !r.At.Before(from) && r.At.Before(to) {
+			between = append(between, r)
+		}
+	}
+	return between
+}
@@ -0,0 +1,72 @@
+package remind_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/remind"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func at(day, clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, time.Local)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParse(t *testing.T) {
+	content := "---\nnote: \"@remind(2024-01-01)\"\n---\n# Errands\n\n" +
+		"- [ ] Renew the passport @remind(2024-03-01 14:30)\n" +
+		"- [x] Book the flights @remind(2024-02-01)\n" +
+		"Call Bob @remind(2024-03-02) and again @remind(2024-03-09T08:00)\n" +
+		"Write `@remind(2024-04-01)` for a reminder.\n" +
+		"Not a date @remind(2024-13-45)\n\n" +
+		"```\n@remind(2024-05-01)\n```\n"
+	reminders := remind.Parse(content)
+	require.Len(t, reminders, 3)
+	assert.Equal(t, remind.Reminder{At: at("2024-03-01", "14:30"), Line: 6, Text: "Renew the passport"}, reminders[0])
+	assert.Equal(t, remind.Reminder{At: at("2024-03-02", remind.DefaultTime), Line: 8, Text: "Call Bob and again"}, reminders[1])
+	assert.Equal(t, at("2024-03-09", "08:00"), reminders[2].At)
+}
+
+func TestCollectAndNotify(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) scan.Note {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return scan.Note{Path: path, Title: name[:len(name)-3]}
+	}
+	notes := []scan.Note{
+		write("later.md", "Dentist @remind(2024-03-01 10:00)\n"),
+		write("soon.md", "Standup @remind(2024-03-01 09:00)\n"),
+		write("none.md", "Nothing to remember.\n"),
+	}
+	reminders, err := remind.Collect(context.Background(), root, notes)
+	require.NoError(t, err)
+	require.Len(t, reminders, 2)
+	assert.Equal(t, "soon.md#2024-03-01T09:00 Standup", reminders[0].ID)
+	assert.Equal(t, "2024-03-01 09:00  soon: Standup", reminders[0].String())
+	assert.Len(t, remind.Between(reminders, at("2024-03-01", "09:30"), at("2024-03-02", "00:00")), 1)
+
+	state := filepath.Join(root, "state", remind.File)
+	store, err := remind.OpenStore(state)
+	require.NoError(t, err)
+	now := at("2024-03-01", "09:05")
+	due := store.Due(reminders, now, time.Hour)
+	require.Len(t, due, 1)
+	store.MarkNotified(due[0], now)
+	require.NoError(t, store.Save())
+
+	reopened, err := remind.OpenStore(state)
+	require.NoError(t, err)
+	assert.Empty(t, reopened.Due(reminders, now, time.Hour), "notified once")
+	assert.Len(t, reopened.Due(reminders, at("2024-03-01", "10:00"), time.Hour), 1)
+	assert.Empty(t, reopened.Due(reminders, at("2024-03-02", "10:00"), time.Hour), "too late")
+}
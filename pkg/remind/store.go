@@ -0,0 +1,54 @@
+package remind
+
+import (
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// File is the state file, in the state directory, the reminders already
+// notified are kept in.
+const File = "reminders.json"
+
+// Store keeps the times reminders were notified at, by ID.
+type Store struct {
+	path     string
+	Notified map[string]time.Time
+}
+
+// OpenStore reads the store at path. A missing file yields an empty store.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, Notified: make(map[string]time.Time)}
+	if err := fs.ReadStateFile(path, "reminder state", &s.Notified); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the store to its file, creating the directory if needed.
+func (s *Store) Save() error {
+	return fs.WriteStateFile(s.path, "reminder state", s.Notified)
+}
+
+// Due returns the reminders due at now that were not notified yet, leaving
+// out those due before now minus within, which are too late to notify.
+func (s *Store) Due(reminders []Reminder, now time.Time, within time.Duration) []Reminder {
+	var due []Reminder
+	for _, r := range Between(reminders, now.Add(-within), now.Add(time.Nanosecond)) {
+		if _, ok := s.Notified[r.ID]; !ok {
+			due = append(due, r)
+		}
+	}
+	return due
+}
+
+// MarkNotified records that r was notified at now, and forgets the reminders
+// notified more than a year ago, which are long past.
+func (s *Store) MarkNotified(r Reminder, now time.Time) {
+	s.Notified[r.ID] = now
+	for id, at := range s.Notified {
+		if at.Before(now.AddDate(-1, 0, 0)) {
+			delete(s.Notified, id)
+		}
+	}
+}
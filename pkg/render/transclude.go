@@ -0,0 +1,105 @@
+// Package render prepares note content for display and export.
+package render
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+)
+
+// DefaultMaxDepth is the number of nested embeds expanded when
+// Transcluder.MaxDepth is not set.
+const DefaultMaxDepth = 5
+
+// Transcluder expands embeds, ![[note]] and ![[note#heading]], into the body
+// of the embedded note or the section under the heading, recursively.
+//
+// Embeds that cannot be expanded are turned into plain wikilinks, so that
+// they still point at their target: embeds of missing notes or headings,
+// embeds of a note already being expanded, which would never end, and embeds
+// nested deeper than MaxDepth. Embeds in code blocks and code spans are left
+// alone.
+type Transcluder struct {
+	// Resolve returns the path of the note a wikilink target names.
+	Resolve func(target string) (string, error)
+	// ReadFile reads a note; it defaults to os.ReadFile.
+	ReadFile func(path string) ([]byte, error)
+	// MaxDepth bounds the nesting of embeds; it defaults to DefaultMaxDepth.
+	MaxDepth int
+}
+
+// Expand returns body, the body of the note at path, with its embeds expanded.
+// It fails only when an embedded note cannot be read.
+func (t *Transcluder) Expand(path, body string) (string, error) {
+	return t.expand(body, []string{embedKey(path, "")})
+}
+
+// expand expands the embeds of body, found while expanding the embeds in
+// stack, outermost first.
+func (t *Transcluder) expand(body string, stack []string) (string, error) {
+	var err error
+	expanded := md.RewriteLinks(body, func(l md.Link) string {
+		if err != nil || !l.Wiki || !l.Embed {
+			return l.Text
+		}
+		var text string
+		text, err = t.embed(l.Target, strings.TrimSpace(l.Anchor), stack)
+		if err != nil || text == "" {
+			// Keep the link, without the "!" making it an embed.
+			return l.Text[1:]
+		}
+		return text
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
+
+// embed returns the expanded text of the note named target, or of its section
+// under heading, or "" when it cannot be embedded.
+func (t *Transcluder) embed(target, heading string, stack []string) (string, error) {
+	maxDepth := t.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxDepth
+	}
+	if len(stack) > maxDepth || t.Resolve == nil {
+		return "", nil
+	}
+	path, err := t.Resolve(target)
+	if err != nil {
+		return "", nil
+	}
+	key := embedKey(path, heading)
+	for _, k := range stack {
+		// The note or section is already being expanded, or the whole note
+		// is embedded in one of its sections.
+		if k == key || (heading == "" && strings.HasPrefix(k, key)) {
+			return "", nil
+		}
+	}
+
+	readFile := t.ReadFile
+	if readFile == nil {
+		readFile = os.ReadFile
+	}
+	content, err := readFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	_, text := frontmatter.Split(string(content))
+	if heading != "" {
+		if text, err = md.Extract(text, heading); err != nil {
+			return "", nil
+		}
+	}
+	return t.expand(strings.Trim(text, "\n"), append(stack, key))
+}
+
+// embedKey identifies an embedded note or section for cycle detection.
+func embedKey(path, heading string) string {
+	return path + "#" + strings.ToLower(heading)
+}
@@ -0,0 +1,90 @@
+package render_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/render"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vault returns a Transcluder reading the notes in files, named by path.
+func vault(files map[string]string) *render.Transcluder {
+	return &render.Transcluder{
+		Resolve: func(target string) (string, error) {
+			if _, ok := files[strings.ToLower(target)]; !ok {
+				return "", fmt.Errorf("note not found: %s", target)
+			}
+			return strings.ToLower(target), nil
+		},
+		ReadFile: func(path string) ([]byte, error) {
+			if path == "unreadable" {
+				return nil, errors.New("permission denied")
+			}
+			return []byte(files[path]), nil
+		},
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tr := vault(map[string]string{
+		"quote":   "---\ntags: [q]\n---\nStay hungry.\n",
+		"recipes": "# Recipes\n\n## Pancakes\n\nFlour, ![[quote]]\n\n## Bread\n\nYeast.\n",
+		"self":    "Before ![[self]] after\n",
+		"ping":    "ping ![[pong]]",
+		"pong":    "pong ![[ping]]",
+		"toc":     "# Toc\n\n## Summary\n\n![[toc#Details]]\n\n## Details\n\nAll of it.\n",
+	})
+
+	tests := []struct {
+		name, body, want string
+	}{
+		{"whole note", "Quote: ![[Quote]]", "Quote: Stay hungry."},
+		{"section", "![[recipes#Pancakes]]\n", "Flour, Stay hungry.\n"},
+		{"heading with hashes", "![[recipes### Bread]]", "Yeast."},
+		{"missing note", "See ![[Nowhere|there]].", "See [[Nowhere|there]]."},
+		{"missing heading", "![[recipes#Waffles]]", "[[recipes#Waffles]]"},
+		{"code fence", "```\n![[quote]]\n```\n~~~\n![[quote]]\n~~~", "```\n![[quote]]\n```\n~~~\n![[quote]]\n~~~"},
+		{"code span", "Write `![[quote]]` to embed", "Write `![[quote]]` to embed"},
+		{"inline several", "![[quote]] ![[recipes#Bread]]", "Stay hungry. Yeast."},
+		{"cycle through notes", "![[ping]]", "ping pong [[ping]]"},
+		{"section of the same note", "![[toc#Summary]]", "All of it."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tr.Expand("note", tt.body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	got, err := tr.Expand("self", "Before ![[self]] after\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Before [[self]] after\n", got, "a note does not embed itself")
+}
+
+func TestExpand_MaxDepth(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 10; i++ {
+		files[fmt.Sprintf("n%d", i)] = fmt.Sprintf("%d ![[n%d]]", i, i+1)
+	}
+	tr := vault(files)
+	tr.MaxDepth = 3
+	got, err := tr.Expand("root", "![[n0]]")
+	require.NoError(t, err)
+	assert.Equal(t, "0 1 2 [[n3]]", got)
+
+	tr.MaxDepth = 0
+	got, err = tr.Expand("root", "![[n0]]")
+	require.NoError(t, err)
+	assert.Equal(t, "0 1 2 3 4 [[n5]]", got, "defaults to DefaultMaxDepth")
+}
+
+func TestExpand_ReadError(t *testing.T) {
+	tr := vault(map[string]string{"unreadable": ""})
+	_, err := tr.Expand("note", "![[unreadable]]")
+	assert.ErrorContains(t, err, "permission denied")
+}
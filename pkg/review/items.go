@@ -0,0 +1,103 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Tag marks notes to review as a whole.
+const Tag = "review"
+
+// Item is something to review: a note tagged with Tag, or a flashcard written in
+// a note.
+type Item struct {
+	// ID identifies the item in the store: the slash-separated path of its note
+	// relative to the vault, followed by "#" and the question for flashcards.
+	ID    string
+	Path  string
+	Title string
+	// Question and Answer are set for flashcards.
+	Question string
+	Answer   string
+}
+
+// IsCard reports whether the item is a flashcard rather than a whole note.
+func (it Item) IsCard() bool {
+	return it.Question != ""
+}
+
+// Card is a question and its answer, written in a note as
+//
+//	Q: What does SM-2 schedule?
+//	A: Reviews, at growing intervals.
+//
+// Questions and answers may span several lines; a card ends at a blank line or
+// the next question.
+type Card struct {
+	Question string
+	Answer   string
+}
+
+// ParseCards returns the complete cards in content.
+func ParseCards(content string) []Card {
+	var (
+		cards []Card
+		q, a  []string
+		in    *[]string
+	)
+	flush := func() {
+		if len(q) > 0 && len(a) > 0 {
+			cards = append(cards, Card{Question: strings.Join(q, "\n"), Answer: strings.Join(a, "\n")})
+		}
+		q, a, in = nil, nil, nil
+	}
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "Q:"):
+			flush()
+			q, in = []string{strings.TrimSpace(trimmed[2:])}, &q
+		case strings.HasPrefix(trimmed, "A:") && len(q) > 0 && len(a) == 0:
+			a, in = []string{strings.TrimSpace(trimmed[2:])}, &a
+		case trimmed == "":
+			flush()
+		case in != nil:
+			*in = append(*in, trimmed)
+		}
+	}
+	flush()
+	return cards
+}
+
+// Collect returns the review items of notes located under root: the notes
+// tagged with Tag and the cards written in any note.
+func Collect(root string, notes []scan.Note) ([]Item, error) {
+	var items []Item
+	for _, n := range notes {
+		rel, err := filepath.Rel(root, n.Path)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		if n.HasTag(Tag) {
+			items = append(items, Item{ID: rel, Path: n.Path, Title: n.Title})
+		}
+		content, err := os.ReadFile(n.Path)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range ParseCards(string(content)) {
+			items = append(items, Item{
+				ID:       rel + "#" + c.Question,
+				Path:     n.Path,
+				Title:    n.Title,
+				Question: c.Question,
+				Answer:   c.Answer,
+			})
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,108 @@
+package review
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	// InitialEase is the ease factor of items reviewed for the first time.
+	InitialEase = 2.5
+	// MinEase is the lowest ease factor an item can reach.
+	MinEase = 1.3
+)
+
+// Grade is the answer to how well an item was remembered.
+type Grade int
+
+const (
+	// Again means the item was forgotten; it is shown again the next day.
+	Again Grade = iota
+	// Hard means the item was recalled with difficulty.
+	Hard
+	// Easy means the item was recalled effortlessly.
+	Easy
+)
+
+// ParseGrade parses "again", "hard" or "easy", or their first letter.
+func ParseGrade(s string) (Grade, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "a", "again":
+		return Again, nil
+	case "h", "hard":
+		return Hard, nil
+	case "e", "easy":
+		return Easy, nil
+	}
+	return 0, fmt.Errorf("unknown grade %q (expected again, hard or easy)", s)
+}
+
+func (g Grade) String() string {
+	switch g {
+	case Again:
+		return "again"
+	case Hard:
+		return "hard"
+	case Easy:
+		return "easy"
+	}
+	return fmt.Sprintf("Grade(%d)", int(g))
+}
+
+// quality maps a grade to the 0-5 response quality of the SM-2 algorithm.
+func (g Grade) quality() float64 {
+	switch g {
+	case Easy:
+		return 5
+	case Hard:
+		return 3
+	}
+	return 1
+}
+
+// State is the review schedule of an item.
+type State struct {
+	Ease        float64   `json:"ease"`
+	Interval    int       `json:"interval"`
+	Repetitions int       `json:"repetitions"`
+	Due         time.Time `json:"due"`
+	Reviewed    time.Time `json:"reviewed"`
+}
+
+// Review returns the state after reviewing the item with grade g at now,
+// following SM-2: successful reviews are spaced 1 day, 6 days and then the
+// previous interval times the ease factor apart, the ease factor grows with easy
+// grades and shrinks with hard ones, and forgotten items start over.
+func (s State) Review(g Grade, now time.Time) State {
+	if s.Ease == 0 {
+		s.Ease = InitialEase
+	}
+	if g == Again {
+		s.Repetitions = 0
+		s.Interval = 1
+	} else {
+		s.Repetitions++
+		switch s.Repetitions {
+		case 1:
+			s.Interval = 1
+		case 2:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.Ease))
+		}
+	}
+	q := g.quality()
+	s.Ease = math.Max(MinEase, math.Round((s.Ease+0.1-(5-q)*(0.08+(5-q)*0.02))*100)/100)
+	s.Reviewed = now
+	year, month, day := now.Date()
+	s.Due = time.Date(year, month, day+s.Interval, 0, 0, 0, 0, now.Location())
+	return s
+}
+
+// IsDue reports whether an item in state s is due at now. Items never reviewed
+// are always due.
+func (s State) IsDue(now time.Time) bool {
+	return s.Due.IsZero() || !s.Due.After(now)
+}
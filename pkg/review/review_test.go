@@ -0,0 +1,116 @@
+package review_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/review"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestState_Review(t *testing.T) {
+	now := time.Date(2025, 3, 1, 15, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	var s review.State
+	s = s.Review(review.Easy, now)
+	assert.Equal(t, review.State{Ease: 2.6, Interval: 1, Repetitions: 1, Due: day(2), Reviewed: now}, s)
+
+	s = s.Review(review.Easy, now)
+	assert.Equal(t, 6, s.Interval)
+	assert.Equal(t, day(7), s.Due)
+
+	s = s.Review(review.Hard, now)
+	assert.Equal(t, 16, s.Interval) // 6 × 2.7, before the ease drops for the hard grade
+	assert.InDelta(t, 2.56, s.Ease, 1e-9)
+
+	s = s.Review(review.Again, now)
+	assert.Equal(t, 1, s.Interval)
+	assert.Equal(t, 0, s.Repetitions)
+	assert.InDelta(t, 2.02, s.Ease, 1e-9)
+
+	for i := 0; i < 5; i++ {
+		s = s.Review(review.Again, now)
+	}
+	assert.Equal(t, review.MinEase, s.Ease)
+
+	assert.True(t, review.State{}.IsDue(now))
+	assert.True(t, s.IsDue(day(2)))
+	assert.False(t, s.IsDue(day(1)))
+}
+
+func TestParseGrade(t *testing.T) {
+	for input, want := range map[string]review.Grade{"a": review.Again, "Hard": review.Hard, " e ": review.Easy} {
+		g, err := review.ParseGrade(input)
+		require.NoError(t, err)
+		assert.Equal(t, want, g)
+	}
+	_, err := review.ParseGrade("good")
+	assert.Error(t, err)
+}
+
+func TestParseCards(t *testing.T) {
+	content := `# Go
+
+Q: What does a nil map panic on?
+A: Assignment.
+
+Q: Name two
+   channel directions.
+A: send-only
+   receive-only
+Q: Unanswered?
+
+Some text A: not an answer.
+`
+	assert.Equal(t, []review.Card{
+		{Question: "What does a nil map panic on?", Answer: "Assignment."},
+		{Question: "Name two\nchannel directions.", Answer: "send-only\nreceive-only"},
+	}, review.ParseCards(content))
+}
+
+func TestCollectAndStore(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	write("zettel/Maps.md", "# Maps\n\nRemember this #review\n")
+	write("zettel/Cards.md", "# Cards\n\nQ: 2+2?\nA: 4\n")
+	write("zettel/Plain.md", "# Plain\n")
+	notes, err := scan.Scan(dir)
+	require.NoError(t, err)
+
+	items, err := review.Collect(dir, notes)
+	require.NoError(t, err)
+	var ids []string
+	for _, it := range items {
+		ids = append(ids, it.ID)
+	}
+	assert.ElementsMatch(t, []string{"zettel/Maps.md", "zettel/Cards.md#2+2?"}, ids)
+
+	path := filepath.Join(dir, ".exo", "review.json")
+	store, err := review.OpenStore(path)
+	require.NoError(t, err)
+	now := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	assert.Len(t, store.Due(items, now), 2)
+
+	store.Review("zettel/Maps.md", review.Easy, now)
+	require.NoError(t, store.Save())
+
+	reopened, err := review.OpenStore(path)
+	require.NoError(t, err)
+	due := reopened.Due(items, now)
+	require.Len(t, due, 1)
+	assert.Equal(t, "zettel/Cards.md#2+2?", due[0].ID)
+	assert.True(t, due[0].IsCard())
+
+	// Overdue items come before new ones.
+	due = reopened.Due(items, now.AddDate(0, 0, 2))
+	require.Len(t, due, 2)
+	assert.Equal(t, "zettel/Maps.md", due[0].ID)
+}
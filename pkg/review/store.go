@@ -0,0 +1,54 @@
+package review
+
+import (
+	"sort"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// Store keeps the review state of items in a JSON file in the state directory.
+type Store struct {
+	path   string
+	States map[string]State
+}
+
+// OpenStore reads the store at path. A missing file yields an empty store.
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, States: make(map[string]State)}
+	if err := fs.ReadStateFile(path, "review state", &s.States); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Save writes the store to its file, creating the directory if needed.
+func (s *Store) Save() error {
+	return fs.WriteStateFile(s.path, "review state", s.States)
+}
+
+// Due returns the items due at now, the longest overdue first and items never
+// reviewed last.
+func (s *Store) Due(items []Item, now time.Time) []Item {
+	var due []Item
+	for _, it := range items {
+		if s.States[it.ID].IsDue(now) {
+			due = append(due, it)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := s.States[due[i].ID].Due, s.States[due[j].ID].Due
+		if a.IsZero() || b.IsZero() {
+			return !a.IsZero() && b.IsZero()
+		}
+		return a.Before(b)
+	})
+	return due
+}
+
+// Review records the grade of the item with the given ID and returns its new state.
+func (s *Store) Review(id string, g Grade, now time.Time) State {
+	state := s.States[id].Review(g, now)
+	s.States[id] = state
+	return state
+}
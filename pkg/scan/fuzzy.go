@@ -0,0 +1,67 @@
+package scan
+
+import (
+	"sort"
+	"strings"
+)
+
+// Fuzzy returns the notes whose title or one of whose aliases contains the
+// letters of query in order, ignoring case. The notes are ranked by their best
+// name: names containing query as typed first, then names where the letters of
+// query lie closest together; notes ranking the same keep their order.
+func Fuzzy(notes []Note, query string) []Note {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+	type match struct {
+		note  Note
+		score int
+	}
+	var matches []match
+	for _, n := range notes {
+		best := -1
+		for _, name := range append([]string{n.Title}, n.Aliases...) {
+			if score, ok := fuzzyScore(strings.ToLower(name), query); ok && (best < 0 || score < best) {
+				best = score
+			}
+		}
+		if best >= 0 {
+			matches = append(matches, match{note: n, score: best})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+	found := make([]Note, len(matches))
+	for i, m := range matches {
+		found[i] = m.note
+	}
+	return found
+}
+
+// fuzzyScore reports whether name contains the letters of query in order and
+// scores the match: 0 when name contains query, else 1 plus the number of other
+// letters between the first and last matched ones. Lower scores match better.
+func fuzzyScore(name, query string) (int, bool) {
+	if strings.Contains(name, query) {
+		return 0, true
+	}
+	q := []rune(query)
+	first, matched, gaps := -1, 0, 0
+	for i, r := range []rune(name) {
+		if matched == len(q) {
+			break
+		}
+		if r == q[matched] {
+			if first < 0 {
+				first = i
+			}
+			matched++
+		} else if first >= 0 {
+			gaps++
+		}
+	}
+	if matched < len(q) {
+		return 0, false
+	}
+	return 1 + gaps, true
+}
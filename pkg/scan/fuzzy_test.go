@@ -0,0 +1,32 @@
+package scan_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFuzzy(t *testing.T) {
+	notes := []scan.Note{
+		{Path: "/v/a.md", Title: "Garbage collection in Go"},
+		{Path: "/v/b.md", Title: "Go channels", Aliases: []string{"CSP"}},
+		{Path: "/v/c.md", Title: "Green threads"},
+		{Path: "/v/d.md", Title: "Channel sizing"},
+	}
+	titles := func(query string) []string {
+		var out []string
+		for _, n := range scan.Fuzzy(notes, query) {
+			out = append(out, n.Title)
+		}
+		return out
+	}
+
+	assert.Equal(t, []string{"Go channels", "Channel sizing"}, titles("channel"))
+	assert.Equal(t, []string{"Go channels", "Garbage collection in Go"}, titles("gc"),
+		"closer letters rank first")
+	assert.Equal(t, []string{"Go channels"}, titles("csp"), "aliases match")
+	assert.Equal(t, []string{"Green threads"}, titles("GRTHR"))
+	assert.Empty(t, titles("rust"))
+	assert.Empty(t, titles("  "))
+}
@@ -0,0 +1,150 @@
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFile is the file, at the root of a vault, listing the paths scans
+// leave out.
+const IgnoreFile = ".exoignore"
+
+// Ignore matches the paths under a root that scans leave out, from globs in the
+// style of .gitignore:
+//
+//	archive/          the directory archive at any depth
+//	/attachments      attachments at the root only
+//	*.excalidraw.md   any file with that name
+//	sync/**/draft-*   drafts at any depth under sync
+//
+// A glob without a slash, other than a trailing one, matches the name of a file
+// or directory at any depth; otherwise it matches the path relative to the
+// root. A trailing slash only matches directories, and everything under a
+// matched directory is left out. Negated globs (!glob) are not supported.
+type Ignore struct {
+	root  string
+	globs []glob
+}
+
+// glob is a pattern of an Ignore.
+type glob struct {
+	// segments are the slash-separated parts of the pattern, "**" matching any
+	// number of them.
+	segments []string
+	// anchored globs match paths relative to the root, others match names.
+	anchored bool
+	dirOnly  bool
+}
+
+// NewIgnore returns an Ignore matching the paths under root with patterns.
+// Blank patterns and those starting with # are skipped.
+func NewIgnore(root string, patterns []string) (*Ignore, error) {
+	ig := &Ignore{root: root}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		if strings.HasPrefix(p, "!") {
+			return nil, fmt.Errorf("invalid ignore pattern %q: negation is not supported", p)
+		}
+		p = filepath.ToSlash(p)
+		g := glob{dirOnly: strings.HasSuffix(p, "/")}
+		p = strings.TrimSuffix(p, "/")
+		g.anchored = strings.Contains(p, "/")
+		if p = strings.TrimPrefix(p, "/"); p == "" {
+			continue
+		}
+		g.segments = strings.Split(p, "/")
+		for _, s := range g.segments {
+			if _, err := path.Match(s, ""); err != nil {
+				return nil, fmt.Errorf("invalid ignore pattern %q: %w", p, err)
+			}
+		}
+		ig.globs = append(ig.globs, g)
+	}
+	return ig, nil
+}
+
+// LoadIgnore returns an Ignore matching the paths under root with patterns and
+// those of the IgnoreFile of root, if any.
+func LoadIgnore(root string, patterns []string) (*Ignore, error) {
+	data, err := os.ReadFile(filepath.Join(root, IgnoreFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", IgnoreFile, err)
+	}
+	all := append([]string(nil), patterns...)
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		all = append(all, sc.Text())
+	}
+	ig, err := NewIgnore(root, all)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", IgnoreFile, err)
+	}
+	return ig, nil
+}
+
+// Match reports whether the file or directory at path is left out, itself or
+// as it is under a directory left out. Paths outside the root never are; a nil
+// Ignore matches nothing.
+func (ig *Ignore) Match(p string, dir bool) bool {
+	if ig == nil || len(ig.globs) == 0 {
+		return false
+	}
+	rel, err := filepath.Rel(ig.root, p)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i <= len(parts); i++ {
+		if ig.match(parts[:i], i < len(parts) || dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether the path of the given parts, relative to the root,
+// matches one of the globs itself.
+func (ig *Ignore) match(parts []string, dir bool) bool {
+	for _, g := range ig.globs {
+		if g.dirOnly && !dir {
+			continue
+		}
+		if g.anchored {
+			if matchSegments(g.segments, parts) {
+				return true
+			}
+		} else if ok, _ := path.Match(g.segments[0], parts[len(parts)-1]); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSegments reports whether the path parts match the pattern segments,
+// "**" matching any number of parts.
+func matchSegments(segments, parts []string) bool {
+	if len(segments) == 0 {
+		return len(parts) == 0
+	}
+	if segments[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(segments[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	ok, _ := path.Match(segments[0], parts[0])
+	return ok && matchSegments(segments[1:], parts[1:])
+}
@@ -0,0 +1,62 @@
+package scan_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnore_Match(t *testing.T) {
+	root := filepath.FromSlash("/vault")
+	ig, err := scan.NewIgnore(root, []string{"# archives", "archive/", "/attachments", "*.excalidraw.md", "sync/**/draft-*", ""})
+	require.NoError(t, err)
+	tests := []struct {
+		path string
+		dir  bool
+		want bool
+	}{
+		{"archive", true, true},
+		{"projects/archive", true, true},
+		{"projects/archive", false, false},
+		{"attachments", true, true},
+		{"attachments/a.png", false, true},
+		{"zettel/attachments", true, false},
+		{"zettel/Board.excalidraw.md", false, true},
+		{"sync/draft-1.md", false, true},
+		{"sync/a/b/draft-2.md", false, true},
+		{"sync/a/final.md", false, false},
+		{"zettel/Go.md", false, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ig.Match(filepath.Join(root, filepath.FromSlash(tt.path)), tt.dir), tt.path)
+	}
+	assert.False(t, ig.Match(filepath.FromSlash("/elsewhere/archive"), true))
+	assert.False(t, (*scan.Ignore)(nil).Match(filepath.Join(root, "archive"), true))
+
+	_, err = scan.NewIgnore(root, []string{"!keep.md"})
+	assert.ErrorContains(t, err, "negation")
+	_, err = scan.NewIgnore(root, []string{"[a-"})
+	assert.ErrorContains(t, err, "invalid ignore pattern")
+}
+
+func TestLoadIgnore(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"zettel/Go.md", "archive/Old.md", "zettel/Board.excalidraw.md"} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte("# Note\n"), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, scan.IgnoreFile), []byte("# old notes\narchive/\n"), 0644))
+
+	ig, err := scan.LoadIgnore(dir, []string{"*.excalidraw.md"})
+	require.NoError(t, err)
+	notes, err := scan.ScanContext(context.Background(), scan.WalkOptions{Ignore: ig}, dir)
+	require.NoError(t, err)
+	require.Len(t, notes, 1)
+	assert.Equal(t, filepath.Join(dir, "zettel", "Go.md"), notes[0].Path)
+}
@@ -0,0 +1,146 @@
+package scan
+
+import (
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// Names resolves note names and wikilink targets to notes. A note is named by
+// its path relative to the root, its file name, its title, its IDs and its
+// aliases, in that order of precedence: an alias never shadows the file name or
+// title of another note.
+type Names struct {
+	paths   map[string][]Note
+	files   map[string][]Note
+	titles  map[string][]Note
+	ids     map[string][]Note
+	aliases map[string][]Note
+}
+
+// Collision is an alias naming more than one note: the note declaring it and
+// the notes with the same file name, title, ID or alias.
+type Collision struct {
+	Alias string
+	Notes []Note
+}
+
+// NewNames indexes the names of notes found under root.
+func NewNames(root string, notes []Note) *Names {
+	ns := &Names{
+		paths:   make(map[string][]Note),
+		files:   make(map[string][]Note),
+		titles:  make(map[string][]Note),
+		ids:     make(map[string][]Note),
+		aliases: make(map[string][]Note),
+	}
+	for _, n := range notes {
+		if rel, err := filepath.Rel(root, n.Path); err == nil {
+			addName(ns.paths, NameKey(filepath.ToSlash(rel)), n)
+		}
+		addName(ns.files, NameKey(filepath.Base(n.Path)), n)
+		addName(ns.titles, NameKey(n.Title), n)
+		for _, id := range IDs(n) {
+			addName(ns.ids, NameKey(id), n)
+		}
+		for _, alias := range n.Aliases {
+			addName(ns.aliases, NameKey(alias), n)
+		}
+	}
+	return ns
+}
+
+// NameKey normalizes a note name or wikilink target so that names match
+// case-insensitively and with or without extension.
+func NameKey(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(filepath.ToSlash(name)), NoteExtension))
+}
+
+// IDs returns the identifiers of n: the "id" frontmatter field and, for file
+// names such as "20250208143005 Title.md", the leading ID. A leading word is
+// only taken for an ID when the file name has a title after it and the word
+// contains a digit, as generated IDs do.
+func IDs(n Note) []string {
+	var ids []string
+	if id := frontmatter.String(n.Meta, "id"); id != "" {
+		ids = append(ids, id)
+	}
+	fields := strings.Fields(strings.TrimSuffix(filepath.Base(n.Path), NoteExtension))
+	if len(fields) > 1 && strings.IndexFunc(fields[0], unicode.IsDigit) >= 0 {
+		ids = append(ids, fields[0])
+	}
+	return ids
+}
+
+// addName appends n to the notes named key, once.
+func addName(m map[string][]Note, key string, n Note) {
+	if key != "" && !containsNote(m[key], n) {
+		m[key] = append(m[key], n)
+	}
+}
+
+// Resolve returns the notes named target: by relative path when it contains a
+// slash, else by file name, title, ID or alias, stopping at the first kind of name
+// that matches. More than one note is returned when the name is ambiguous.
+func (ns *Names) Resolve(target string) []Note {
+	key := NameKey(target)
+	if strings.Contains(key, "/") {
+		if notes := ns.paths[key]; len(notes) > 0 {
+			return notes
+		}
+		key = path.Base(key)
+	}
+	for _, m := range []map[string][]Note{ns.files, ns.titles, ns.ids, ns.aliases} {
+		if notes := m[key]; len(notes) > 0 {
+			return notes
+		}
+	}
+	return nil
+}
+
+// Collisions returns the aliases naming more than one note, ordered by alias.
+// An alias repeating the file name, title or ID of its own note is no collision.
+func (ns *Names) Collisions() []Collision {
+	var collisions []Collision
+	for key, declaring := range ns.aliases {
+		var notes []Note
+		for _, m := range []map[string][]Note{ns.files, ns.titles, ns.ids, ns.aliases} {
+			for _, n := range m[key] {
+				if !containsNote(notes, n) {
+					notes = append(notes, n)
+				}
+			}
+		}
+		if len(notes) < 2 {
+			continue
+		}
+		sort.Slice(notes, func(i, j int) bool { return notes[i].Path < notes[j].Path })
+		collisions = append(collisions, Collision{Alias: aliasName(declaring[0], key), Notes: notes})
+	}
+	sort.Slice(collisions, func(i, j int) bool { return NameKey(collisions[i].Alias) < NameKey(collisions[j].Alias) })
+	return collisions
+}
+
+// containsNote reports whether notes holds the note at the path of n.
+func containsNote(notes []Note, n Note) bool {
+	for _, other := range notes {
+		if other.Path == n.Path {
+			return true
+		}
+	}
+	return false
+}
+
+// aliasName returns the alias of n with the given key as it was written.
+func aliasName(n Note, key string) string {
+	for _, alias := range n.Aliases {
+		if NameKey(alias) == key {
+			return alias
+		}
+	}
+	return key
+}
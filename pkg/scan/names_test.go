@@ -0,0 +1,74 @@
+package scan_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNames(t *testing.T) {
+	root := filepath.FromSlash("/v")
+	note := func(rel, title string, aliases ...string) scan.Note {
+		return scan.Note{Path: filepath.Join(root, filepath.FromSlash(rel)), Title: title, Aliases: aliases}
+	}
+	notes := []scan.Note{
+		note("zettel/rust.md", "Rust", "Rustlang", "Crab", "rust"),
+		note("zettel/go.md", "Go", "Golang", "Gopher"),
+		note("0-inbox/gopher.md", "Gophers", "Crab"),
+		note("projects/go.md", "Go project"),
+	}
+	names := scan.NewNames(root, notes)
+
+	titles := func(target string) []string {
+		var out []string
+		for _, n := range names.Resolve(target) {
+			out = append(out, n.Title)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"Rust"}, titles("RUSTLANG"))
+	assert.Equal(t, []string{"Rust"}, titles("rust.md"))
+	assert.Equal(t, []string{"Go", "Go project"}, titles("go"), "file names are ambiguous")
+	assert.Equal(t, []string{"Go project"}, titles("projects/go"))
+	assert.Equal(t, []string{"Go", "Go project"}, titles("elsewhere/go"))
+	assert.Equal(t, []string{"Gophers"}, titles("gopher"), "file names win over aliases")
+	assert.Equal(t, []string{"Go project"}, titles("Go Project"))
+	assert.Nil(t, names.Resolve("missing"))
+
+	collisions := names.Collisions()
+	require.Len(t, collisions, 2)
+	assert.Equal(t, "Crab", collisions[0].Alias)
+	assert.Equal(t, []string{"Gophers", "Rust"}, []string{collisions[0].Notes[0].Title, collisions[0].Notes[1].Title})
+	assert.Equal(t, "Gopher", collisions[1].Alias)
+	assert.Equal(t, []string{"Gophers", "Go"}, []string{collisions[1].Notes[0].Title, collisions[1].Notes[1].Title})
+}
+
+func TestNames_IDs(t *testing.T) {
+	root := filepath.FromSlash("/v")
+	notes := []scan.Note{
+		{Path: filepath.Join(root, "20250208143005 Channels.md"), Title: "Channels"},
+		{Path: filepath.Join(root, "Select.md"), Title: "Select", Meta: map[string]interface{}{"id": "01JKX9"}},
+		{Path: filepath.Join(root, "Go tips.md"), Title: "Go tips"},
+		{Path: filepath.Join(root, "2025 goals.md"), Title: "2025 goals"},
+		{Path: filepath.Join(root, "2025.md"), Title: "Year 2025"},
+	}
+	assert.Equal(t, []string{"20250208143005"}, scan.IDs(notes[0]))
+	assert.Equal(t, []string{"01JKX9"}, scan.IDs(notes[1]))
+	assert.Empty(t, scan.IDs(notes[2]), "a leading word is no ID")
+
+	names := scan.NewNames(root, notes)
+	resolve := func(target string) []string {
+		var out []string
+		for _, n := range names.Resolve(target) {
+			out = append(out, n.Title)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"Channels"}, resolve("20250208143005"))
+	assert.Equal(t, []string{"Select"}, resolve("01jkx9"))
+	assert.Nil(t, resolve("go"))
+	assert.Equal(t, []string{"Year 2025"}, resolve("2025"), "file names win over IDs")
+}
@@ -0,0 +1,299 @@
+package scan
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/md"
+)
+
+// NoteExtension is the file extension of notes picked up by the scanner.
+const NoteExtension = ".md"
+
+var (
+	tagPattern  = regexp.MustCompile(`(?:^|\s)#([\p{L}\p{N}_/-]*[\p{L}_/-][\p{L}\p{N}_/-]*)`)
+	taskPattern = regexp.MustCompile(`(?m)^\s*[-*+] \[([ xX])\] `)
+	timeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"}
+)
+
+// Note holds the metadata extracted from a note file.
+type Note struct {
+	Path      string                 `json:"path"`
+	Title     string                 `json:"title"`
+	Aliases   []string               `json:"aliases,omitempty"`
+	Tags      []string               `json:"tags,omitempty"`
+	Links     []string               `json:"links,omitempty"`
+	Words     int                    `json:"words"`
+	Tasks     int                    `json:"tasks,omitempty"`
+	TasksDone int                    `json:"tasks_done,omitempty"`
+	Excerpt   string                 `json:"excerpt,omitempty"`
+	Pinned    bool                   `json:"pinned,omitempty"`
+	Locked    bool                   `json:"locked,omitempty"`
+	Created   time.Time              `json:"created"`
+	Modified  time.Time              `json:"modified"`
+	Meta      map[string]interface{} `json:"meta,omitempty"`
+}
+
+// Scan walks the given directories and returns the metadata of every note found,
+// in walk order. Hidden files and directories are skipped, as are directories
+// that do not exist. Notes are read concurrently; see Walk.
+func Scan(dirs ...string) ([]Note, error) {
+	return ScanContext(context.Background(), WalkOptions{}, dirs...)
+}
+
+// ReadNote reads the note at path and extracts its metadata.
+func ReadNote(path string) (Note, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Note{}, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Note{}, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return ParseNote(path, string(content), info.ModTime()), nil
+}
+
+// ParseNote extracts metadata from note content. The title is taken from the
+// frontmatter, the first level-one heading, or the file name, in that order.
+// Created defaults to modified when the frontmatter has no created/date field,
+// and a modified field takes precedence over modified.
+func ParseNote(path, content string, modified time.Time) Note {
+	meta, body, err := frontmatter.Parse(content)
+	if err != nil {
+		// Treat malformed frontmatter as part of the body.
+		meta, body = map[string]interface{}{}, content
+	}
+
+	n := Note{
+		Path:     path,
+		Title:    frontmatter.String(meta, "title"),
+		Words:    len(strings.Fields(body)),
+		Excerpt:  excerpt(body),
+		Pinned:   frontmatter.Bool(meta, "pinned"),
+		Locked:   frontmatter.Bool(meta, "locked"),
+		Created:  modified,
+		Modified: modified,
+		Meta:     meta,
+	}
+	if n.Title == "" {
+		n.Title = firstHeading(body)
+	}
+	if n.Title == "" {
+		n.Title = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	for _, key := range []string{"created", "date"} {
+		if t, ok := parseTime(meta[key]); ok {
+			n.Created = t
+			break
+		}
+	}
+	if t, ok := parseTime(meta["modified"]); ok {
+		n.Modified = t
+	}
+	n.Aliases = uniq(frontmatter.Strings(meta, "aliases"))
+	n.Tags = uniq(append(frontmatter.Strings(meta, "tags"), inlineTags(body)...))
+	if strings.Contains(body, "[[") {
+		for _, l := range md.Links(body) {
+			if l.Wiki {
+				n.Links = append(n.Links, l.Target)
+			}
+		}
+	}
+	n.Links = uniq(n.Links)
+	for _, m := range taskPattern.FindAllStringSubmatch(body, -1) {
+		n.Tasks++
+		if m[1] != " " {
+			n.TasksDone++
+		}
+	}
+	return n
+}
+
+// HasTag reports whether the note carries tag (case-insensitive, leading # ignored).
+func (n Note) HasTag(tag string) bool {
+	tag = strings.TrimPrefix(tag, "#")
+	for _, t := range n.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// nameContains reports whether the title or an alias of n contains text,
+// case-insensitively.
+func (n Note) nameContains(text string) bool {
+	text = strings.ToLower(text)
+	for _, name := range append([]string{n.Title}, n.Aliases...) {
+		if strings.Contains(strings.ToLower(name), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstHeading returns the text of the first level-one heading in body.
+func firstHeading(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			return strings.TrimSpace(line[2:])
+		}
+	}
+	return ""
+}
+
+// excerpt returns the first line of body that is neither blank, a heading nor a
+// code fence, with surrounding whitespace removed.
+func excerpt(body string) string {
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "```") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
+
+// Snippet shortens text to at most length characters, collapsing whitespace and
+// marking a cut with an ellipsis. A length of zero or less disables snippets.
+func Snippet(text string, length int) string {
+	if length <= 0 {
+		return ""
+	}
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= length {
+		return string(runes)
+	}
+	cut := strings.TrimSpace(string(runes[:length-1]))
+	return cut + "…"
+}
+
+// inlineTags returns the #tags found in body, ignoring fenced code blocks.
+func inlineTags(body string) []string {
+	var tags []string
+	inFence := false
+	for _, line := range strings.Split(body, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		for _, m := range tagPattern.FindAllStringSubmatch(line, -1) {
+			tags = append(tags, m[1])
+		}
+	}
+	return tags
+}
+
+// parseTime converts a frontmatter value into a time.
+func parseTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case string:
+		for _, layout := range timeLayouts {
+			if parsed, err := time.ParseInLocation(layout, t, time.Local); err == nil {
+				return parsed, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// uniq removes duplicates from values, keeping the first occurrence.
+func uniq(values []string) []string {
+	if len(values) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(values))
+	out := values[:0]
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// Filter selects notes by tag, creation time and title substring, which may also
+// be found in an alias. Zero fields match everything.
+type Filter struct {
+	Tag           string
+	Since         time.Time
+	TitleContains string
+}
+
+// Match reports whether n satisfies the filter.
+func (f Filter) Match(n Note) bool {
+	if f.Tag != "" && !n.HasTag(f.Tag) {
+		return false
+	}
+	if !f.Since.IsZero() && n.Created.Before(f.Since) {
+		return false
+	}
+	if f.TitleContains != "" && !n.nameContains(f.TitleContains) {
+		return false
+	}
+	return true
+}
+
+// Apply returns the notes matching the filter.
+func (f Filter) Apply(notes []Note) []Note {
+	var out []Note
+	for _, n := range notes {
+		if f.Match(n) {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// SortField names a field notes can be sorted by.
+type SortField string
+
+const (
+	SortByCreated  SortField = "created"
+	SortByModified SortField = "modified"
+	SortByTitle    SortField = "title"
+)
+
+// Sort orders notes in place by field, newest first for time fields. Pinned
+// notes come first.
+func Sort(notes []Note, field SortField) error {
+	var less func(a, b Note) bool
+	switch field {
+	case SortByCreated:
+		less = func(a, b Note) bool { return a.Created.After(b.Created) }
+	case SortByModified:
+		less = func(a, b Note) bool { return a.Modified.After(b.Modified) }
+	case SortByTitle:
+		less = func(a, b Note) bool { return strings.ToLower(a.Title) < strings.ToLower(b.Title) }
+	default:
+		return fmt.Errorf("unknown sort field %q (expected created, modified or title)", field)
+	}
+	sort.SliceStable(notes, func(i, j int) bool {
+		if notes[i].Pinned != notes[j].Pinned {
+			return notes[i].Pinned
+		}
+		return less(notes[i], notes[j])
+	})
+	return nil
+}
+
+// PinnedFirst moves the pinned notes to the front of notes in place, keeping
+// the order of the notes otherwise.
+func PinnedFirst(notes []Note) {
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].Pinned && !notes[j].Pinned })
+}
@@ -0,0 +1,181 @@
+package scan_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNote(t *testing.T) {
+	modified := time.Date(2025, 2, 10, 12, 0, 0, 0, time.UTC)
+	content := "---\ntags: [go]\naliases: [Short, Shorter]\ncreated: 2025-02-08\n---\n# Heading Title\n\nSome #idea text, see [[Other Note|alias]] and [[Third#part]].\n```\n#notatag\n```\n"
+	n := scan.ParseNote("/v/zettel/note.md", content, modified)
+
+	assert.Equal(t, "Heading Title", n.Title)
+	assert.Equal(t, []string{"Short", "Shorter"}, n.Aliases)
+	assert.Equal(t, []string{"go", "idea"}, n.Tags)
+	assert.Equal(t, []string{"Other Note", "Third"}, n.Links)
+	assert.Equal(t, modified, n.Modified)
+	assert.Equal(t, 2025, n.Created.Year())
+	assert.Equal(t, time.February, n.Created.Month())
+	assert.Equal(t, 8, n.Created.Day())
+	assert.True(t, n.HasTag("#IDEA"))
+}
+
+func TestParseNote_LinksInCode(t *testing.T) {
+	content := "# Code\n\nSee [[Real]].\n\n```go\nm := x[[0]]\n[[Fenced]]\n```\n\n    [[Indented]]\n\nAnd `[[Span]]`, ![[Embedded]].\n"
+	n := scan.ParseNote("/v/code.md", content, time.Now())
+	assert.Equal(t, []string{"Real", "Embedded"}, n.Links)
+}
+
+func TestParseNote_ModifiedField(t *testing.T) {
+	n := scan.ParseNote("/v/note.md", "---\nmodified: \"2025-03-01T09:30:00Z\"\n---\n# Note\n", time.Now())
+	assert.Equal(t, time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC), n.Modified.UTC())
+}
+
+func TestParseNote_TitleFallback(t *testing.T) {
+	n := scan.ParseNote("/v/my-note.md", "no heading", time.Now())
+	assert.Equal(t, "my-note", n.Title)
+}
+
+func TestParseNote_Tasks(t *testing.T) {
+	n := scan.ParseNote("/v/day/2025-02-08.md", "# Day\n\n- [x] ship it\n- [ ] review\n  * [X] nested\n- [link](x)\n", time.Now())
+	assert.Equal(t, 3, n.Tasks)
+	assert.Equal(t, 2, n.TasksDone)
+}
+
+func TestScan(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "sub"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, ".git"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "a.md"), []byte("# A"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "sub", "b.md"), []byte("# B"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "sub", "c.txt"), []byte("C"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, ".git", "d.md"), []byte("D"), 0644))
+
+	notes, err := scan.Scan(tmpDir, filepath.Join(tmpDir, "missing"))
+	require.NoError(t, err)
+	require.Len(t, notes, 2)
+	assert.Equal(t, "A", notes[0].Title)
+	assert.Equal(t, "B", notes[1].Title)
+}
+
+func TestFilterAndSort(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 2, d, 0, 0, 0, 0, time.UTC) }
+	notes := []scan.Note{
+		{Title: "Alpha", Tags: []string{"go"}, Created: day(1), Modified: day(9)},
+		{Title: "Beta", Aliases: []string{"Crab"}, Tags: []string{"rust"}, Created: day(5), Modified: day(6)},
+		{Title: "Gamma go", Tags: []string{"go"}, Created: day(7), Modified: day(7)},
+	}
+
+	filtered := scan.Filter{Tag: "go", Since: day(2)}.Apply(notes)
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "Gamma go", filtered[0].Title)
+
+	assert.Len(t, scan.Filter{TitleContains: "A"}.Apply(notes), 3)
+	assert.Len(t, scan.Filter{TitleContains: "crab"}.Apply(notes), 1)
+
+	require.NoError(t, scan.Sort(notes, scan.SortByModified))
+	assert.Equal(t, "Alpha", notes[0].Title)
+	require.NoError(t, scan.Sort(notes, scan.SortByCreated))
+	assert.Equal(t, "Gamma go", notes[0].Title)
+	assert.Error(t, scan.Sort(notes, "size"))
+}
+
+func TestSort_PinnedFirst(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 2, d, 0, 0, 0, 0, time.UTC) }
+	notes := []scan.Note{
+		scan.ParseNote("/v/a.md", "# A\n", day(9)),
+		scan.ParseNote("/v/b.md", "---\npinned: true\nlocked: true\n---\n# B\n", day(1)),
+		scan.ParseNote("/v/c.md", "# C\n", day(5)),
+	}
+	assert.True(t, notes[1].Pinned)
+	assert.True(t, notes[1].Locked)
+	assert.False(t, notes[0].Pinned)
+
+	require.NoError(t, scan.Sort(notes, scan.SortByModified))
+	assert.Equal(t, []string{"B", "A", "C"}, []string{notes[0].Title, notes[1].Title, notes[2].Title})
+
+	notes = []scan.Note{{Title: "X"}, {Title: "Y", Pinned: true}, {Title: "Z"}}
+	scan.PinnedFirst(notes)
+	assert.Equal(t, []string{"Y", "X", "Z"}, []string{notes[0].Title, notes[1].Title, notes[2].Title})
+}
+
+func TestParseNote_Excerpt(t *testing.T) {
+	n := scan.ParseNote("/n/a.md", "---\ntitle: A\n---\n# A\n\n## Sub\n\n```go\n  First real line.  \nSecond line.\n", time.Now())
+	assert.Equal(t, "First real line.", n.Excerpt)
+
+	n = scan.ParseNote("/n/b.md", "# Only a heading\n", time.Now())
+	assert.Empty(t, n.Excerpt)
+}
+
+func TestSnippet(t *testing.T) {
+	assert.Equal(t, "short text", scan.Snippet("short \n text", 20))
+	assert.Equal(t, "héllo…", scan.Snippet("héllo wörld", 6))
+	assert.Equal(t, "", scan.Snippet("anything", 0))
+}
+
+func TestWalk(t *testing.T) {
+	dir := t.TempDir()
+	var want []string
+	for i := 0; i < 50; i++ {
+		name := filepath.Join(dir, "notes", fmt.Sprintf("%02d", i/10), fmt.Sprintf("n%02d.md", i))
+		require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+		require.NoError(t, os.WriteFile(name, []byte(fmt.Sprintf("# Note %d\n", i)), 0644))
+		want = append(want, name)
+	}
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "templates", "zet.md"), []byte("# {{.Title}}\n"), 0644))
+
+	// Results stream in any order, with their content.
+	var got []string
+	for r := range scan.Walk(context.Background(), scan.WalkOptions{Workers: 4, Exclude: []string{filepath.Join(dir, "templates")}}, dir) {
+		require.NoError(t, r.Err)
+		assert.Equal(t, "# "+r.Note.Title+"\n", r.Content)
+		got = append(got, r.Note.Path)
+	}
+	assert.ElementsMatch(t, want, got)
+
+	// ScanContext keeps walk order.
+	notes, err := scan.ScanContext(context.Background(), scan.WalkOptions{Workers: 8}, filepath.Join(dir, "notes"))
+	require.NoError(t, err)
+	require.Len(t, notes, 50)
+	for i, n := range notes {
+		assert.Equal(t, want[i], n.Path)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = scan.ScanContext(ctx, scan.WalkOptions{}, dir)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	require.NoError(t, os.Symlink(filepath.Join(dir, "missing.md"), filepath.Join(dir, "notes", "broken.md")))
+	_, err = scan.Scan(dir)
+	assert.ErrorContains(t, err, "broken.md")
+}
+
+func TestWalkOptions_Skip(t *testing.T) {
+	root := t.TempDir()
+	ig, err := scan.NewIgnore(root, []string{"drafts/"})
+	require.NoError(t, err)
+	opts := scan.WalkOptions{Exclude: []string{filepath.Join(root, "templates")}, Ignore: ig}
+
+	assert.False(t, opts.Skip(root, root, true), "the root is walked")
+	assert.False(t, opts.Skip(root, filepath.Join(root, "zettel", "a.md"), false))
+	assert.True(t, opts.Skip(root, filepath.Join(root, ".git"), true))
+	assert.True(t, opts.Skip(root, filepath.Join(root, "zettel", ".draft.md"), false))
+	assert.True(t, opts.Skip(root, filepath.Join(root, "templates"), true))
+	assert.True(t, opts.Skip(root, filepath.Join(root, "templates", "zet.md"), false))
+	assert.False(t, opts.Skip(root, filepath.Join(root, "templates-old", "zet.md"), false))
+	assert.True(t, opts.Skip(root, filepath.Join(root, "drafts"), true))
+
+	assert.True(t, scan.Excluded("/vault/templates/zet.md", []string{"", "/vault/templates"}))
+	assert.False(t, scan.Excluded("/vault/zettel/a.md", []string{"", "/vault/templates"}))
+}
@@ -0,0 +1,183 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WalkOptions configures Walk.
+type WalkOptions struct {
+	// Workers bounds the number of notes read concurrently; it defaults to the
+	// number of CPUs.
+	Workers int
+	// Exclude lists directories whose notes are skipped.
+	Exclude []string
+	// Ignore matches the files and directories skipped, as listed in
+	// .exoignore and the ignore setting.
+	Ignore *Ignore
+}
+
+// Result is a note read by Walk, together with its content, or the error that
+// ended the walk.
+type Result struct {
+	Note    Note
+	Content string
+	Err     error
+
+	// seq is the position of the note in walk order.
+	seq int
+}
+
+// job is a note file to be read by a worker.
+type job struct {
+	dir, path string
+	seq       int
+}
+
+// Walk finds the notes under dirs, skipping hidden files and directories, the
+// excluded directories, the paths ignored and directories that do not exist, and reads them with a
+// bounded pool of workers. Results are streamed in no particular order. The
+// channel is closed once every note has been sent, after an error, or early when
+// ctx is cancelled, which callers can tell by checking ctx.Err.
+func Walk(ctx context.Context, opts WalkOptions, dirs ...string) <-chan Result {
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	jobs := make(chan job, workers)
+	results := make(chan Result, workers)
+
+	send := func(r Result) bool {
+		select {
+		case results <- r:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				info, err := os.Stat(j.path)
+				var content []byte
+				if err == nil {
+					content, err = os.ReadFile(j.path)
+				}
+				if err != nil {
+					send(Result{Err: fmt.Errorf("failed to scan %s: failed to read %s: %w", j.dir, j.path, err)})
+					cancel()
+					return
+				}
+				r := Result{Note: ParseNote(j.path, string(content), info.ModTime()), Content: string(content), seq: j.seq}
+				if !send(r) {
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		seq := 0
+		for _, dir := range dirs {
+			err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+				if err != nil {
+					if path == dir && errors.Is(err, fs.ErrNotExist) {
+						return filepath.SkipDir
+					}
+					return err
+				}
+				if opts.Skip(dir, path, d.IsDir()) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() || filepath.Ext(path) != NoteExtension {
+					return nil
+				}
+				select {
+				case jobs <- job{dir: dir, path: path, seq: seq}:
+					seq++
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			})
+			if err != nil {
+				if ctx.Err() == nil {
+					send(Result{Err: fmt.Errorf("failed to scan %s: %w", dir, err)})
+					cancel()
+				}
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		cancel()
+		close(results)
+	}()
+	return results
+}
+
+// ScanContext reads the notes under dirs like Scan, concurrently with the given
+// options, stopping at the first error or when ctx is cancelled.
+func ScanContext(ctx context.Context, opts WalkOptions, dirs ...string) ([]Note, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var results []Result
+	for r := range Walk(ctx, opts, dirs...) {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		results = append(results, r)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].seq < results[j].seq })
+	notes := make([]Note, len(results))
+	for i, r := range results {
+		notes[i] = r.Note
+	}
+	return notes, nil
+}
+
+// Skip reports whether a walk of root skips path, a directory if isDir: a
+// hidden file or directory, one of the Exclude directories or a path below one,
+// or a path Ignore matches. Root itself is never skipped. Walks of the vault
+// other than Walk, such as those for attachments, use it to skip the same paths.
+func (o WalkOptions) Skip(root, path string, isDir bool) bool {
+	if path == root {
+		return false
+	}
+	return strings.HasPrefix(filepath.Base(path), ".") || Excluded(path, o.Exclude) || o.Ignore.Match(path, isDir)
+}
+
+// Excluded reports whether path is one of dirs or below one of them.
+func Excluded(path string, dirs []string) bool {
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
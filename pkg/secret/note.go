@@ -0,0 +1,163 @@
+package secret
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+)
+
+// ErrNotFound is returned for secrets a note does not have.
+var ErrNotFound = errors.New("no such secret")
+
+// Keys returns the keys of the secrets of the note content, in order.
+func Keys(content string) ([]string, error) {
+	m, err := secrets(content)
+	if err != nil || m == nil {
+		return nil, err
+	}
+	var keys []string
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		keys = append(keys, m.Content[i].Value)
+	}
+	return keys, nil
+}
+
+// Get returns the secret key of the note content, decrypted with k. A value
+// not encrypted yet is returned as written.
+func Get(content, key string, k *Keyring) (string, error) {
+	m, err := secrets(content)
+	if err != nil {
+		return "", err
+	}
+	value := lookup(m, key)
+	if value == nil {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if value.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("secret %s is not a single value", key)
+	}
+	if !IsSealed(value.Value) {
+		return value.Value, nil
+	}
+	return k.Open(value.Value)
+}
+
+// Set sets the secret key of the note content to value, encrypted with k,
+// adding frontmatter to content when it has none.
+func Set(content, key, value string, k *Keyring) (string, error) {
+	return frontmatter.Edit(content, func(m *yaml.Node) error {
+		secrets := lookup(m, Field)
+		if secrets == nil {
+			secrets = &yaml.Node{Kind: yaml.MappingNode}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: Field}, secrets)
+		} else if secrets.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s is not a mapping", Field)
+		}
+		if err := check(secrets, k); err != nil {
+			return err
+		}
+		envelope, err := k.Seal(value)
+		if err != nil {
+			return err
+		}
+		if node := lookup(secrets, key); node != nil {
+			*node = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: envelope}
+			return nil
+		}
+		secrets.Content = append(secrets.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+			&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: envelope})
+		return nil
+	})
+}
+
+// Seal encrypts the secrets of the note content written in plain text with
+// k, and returns content with them sealed and how many there were. Content is
+// returned unchanged when every secret is encrypted already.
+func Seal(content string, k *Keyring) (string, int, error) {
+	m, err := secrets(content)
+	if err != nil || m == nil {
+		return content, 0, err
+	}
+	plain := 0
+	for i := 1; i < len(m.Content); i += 2 {
+		if !IsSealed(m.Content[i].Value) {
+			plain++
+		}
+	}
+	if plain == 0 {
+		return content, 0, nil
+	}
+	sealed, err := frontmatter.Edit(content, func(m *yaml.Node) error {
+		secrets := lookup(m, Field)
+		if err := check(secrets, k); err != nil {
+			return err
+		}
+		for i := 0; i+1 < len(secrets.Content); i += 2 {
+			value := secrets.Content[i+1]
+			if value.Kind != yaml.ScalarNode {
+				return fmt.Errorf("secret %s is not a single value", secrets.Content[i].Value)
+			}
+			if IsSealed(value.Value) {
+				continue
+			}
+			envelope, err := k.Seal(value.Value)
+			if err != nil {
+				return err
+			}
+			*value = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: envelope, LineComment: value.LineComment}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	return sealed, plain, nil
+}
+
+// secrets returns the mapping node of the secrets of the note content, or nil
+// when it has none.
+func secrets(content string) (*yaml.Node, error) {
+	raw, _ := frontmatter.Split(content)
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		return nil, fmt.Errorf("invalid frontmatter: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	m := lookup(doc.Content[0], Field)
+	if m == nil {
+		return nil, nil
+	}
+	if m.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s is not a mapping", Field)
+	}
+	return m, nil
+}
+
+// lookup returns the value of key in the mapping node m, or nil.
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// check returns ErrDecrypt when k cannot decrypt the first encrypted secret
+// of the mapping node secrets, so that the secrets of a note are not sealed
+// with different passphrases.
+func check(secrets *yaml.Node, k *Keyring) error {
+	for i := 1; i < len(secrets.Content); i += 2 {
+		if IsSealed(secrets.Content[i].Value) {
+			_, err := k.Open(secrets.Content[i].Value)
+			return err
+		}
+	}
+	return nil
+}
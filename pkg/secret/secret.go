@@ -0,0 +1,152 @@
+// Package secret encrypts the values of the secret frontmatter field of notes,
+// such as API keys or personal data kept in otherwise plain notes:
+//
+//	secret:
+//	  api_key: exo-secret:v1:3q2+7w...
+//
+// Each value is replaced by an envelope holding it encrypted with AES-256-GCM,
+// with a key derived from a passphrase, and is decrypted on demand.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// Field is the frontmatter field holding the secrets of a note.
+	Field = "secret"
+	// EnvPassphrase is the environment variable holding the passphrase.
+	EnvPassphrase = "EXO_SECRET_PASSPHRASE"
+	// KeyIterations is the number of PBKDF2 iterations keys are derived from
+	// passphrases with.
+	KeyIterations = 600000
+
+	// prefix starts the envelopes of encrypted values.
+	prefix   = "exo-secret:v1:"
+	saltSize = 16
+)
+
+// ErrDecrypt is returned for values that cannot be decrypted.
+var ErrDecrypt = errors.New("cannot decrypt secret: wrong passphrase or corrupted data")
+
+// IsSealed reports whether value is the envelope of an encrypted value.
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, prefix)
+}
+
+// Keyring encrypts and decrypts values with the keys derived from a
+// passphrase. The values it seals share a salt, so that a key is derived once.
+type Keyring struct {
+	passphrase string
+	salt       []byte
+	keys       map[string]cipher.AEAD
+}
+
+// NewKeyring returns a keyring for passphrase.
+func NewKeyring(passphrase string) *Keyring {
+	return &Keyring{passphrase: passphrase, keys: make(map[string]cipher.AEAD)}
+}
+
+// aead returns the cipher of the key derived from salt.
+func (k *Keyring) aead(salt []byte) (cipher.AEAD, error) {
+	if aead, ok := k.keys[string(salt)]; ok {
+		return aead, nil
+	}
+	block, err := aes.NewCipher(DeriveKey(k.passphrase, salt, 32))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	k.keys[string(salt)] = aead
+	return aead, nil
+}
+
+// Seal encrypts plain into an envelope.
+func (k *Keyring) Seal(plain string) (string, error) {
+	if k.salt == nil {
+		k.salt = make([]byte, saltSize)
+		if _, err := rand.Read(k.salt); err != nil {
+			return "", fmt.Errorf("failed to generate salt: %w", err)
+		}
+	}
+	aead, err := k.aead(k.salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	data := append(append([]byte{}, k.salt...), nonce...)
+	data = aead.Seal(data, nonce, []byte(plain), nil)
+	return prefix + base64.RawStdEncoding.EncodeToString(data), nil
+}
+
+// Open decrypts an envelope made by Seal.
+func (k *Keyring) Open(envelope string) (string, error) {
+	if !IsSealed(envelope) {
+		return "", fmt.Errorf("not an encrypted secret")
+	}
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(envelope, prefix))
+	if err != nil || len(data) < saltSize {
+		return "", ErrDecrypt
+	}
+	aead, err := k.aead(data[:saltSize])
+	if err != nil {
+		return "", err
+	}
+	data = data[saltSize:]
+	n := aead.NonceSize()
+	if len(data) < n {
+		return "", ErrDecrypt
+	}
+	plain, err := aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plain), nil
+}
+
+// DeriveKey derives a key of keyLen bytes from passphrase and salt with
+// PBKDF2-HMAC-SHA256 and KeyIterations iterations.
+func DeriveKey(passphrase string, salt []byte, keyLen int) []byte {
+	return PBKDF2(passphrase, salt, KeyIterations, keyLen)
+}
+
+// PBKDF2 derives a key of keyLen bytes from passphrase and salt with
+// PBKDF2-HMAC-SHA256 (RFC 8018) and the given number of iterations.
+func PBKDF2(passphrase string, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, []byte(passphrase))
+	size := prf.Size()
+	var key []byte
+	for block := uint32(1); len(key) < keyLen; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(binary.BigEndian.AppendUint32(nil, block))
+		u := prf.Sum(nil)
+		t := make([]byte, size)
+		copy(t, u)
+		for i := 1; i < iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+		key = append(key, t...)
+	}
+	return key[:keyLen]
+}
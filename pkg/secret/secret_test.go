@@ -0,0 +1,113 @@
+package secret_test
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/secret"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyring(t *testing.T) {
+	k := secret.NewKeyring("hunter2")
+	envelope, err := k.Seal("sk-123")
+	require.NoError(t, err)
+	assert.True(t, secret.IsSealed(envelope))
+	assert.NotContains(t, envelope, "sk-123")
+
+	other, err := k.Seal("sk-123")
+	require.NoError(t, err)
+	assert.NotEqual(t, envelope, other, "values are sealed with their own nonce")
+
+	plain, err := secret.NewKeyring("hunter2").Open(envelope)
+	require.NoError(t, err)
+	assert.Equal(t, "sk-123", plain)
+
+	_, err = secret.NewKeyring("wrong").Open(envelope)
+	assert.ErrorIs(t, err, secret.ErrDecrypt)
+	_, err = k.Open(envelope[:len(envelope)-4])
+	assert.ErrorIs(t, err, secret.ErrDecrypt)
+	_, err = k.Open("sk-123")
+	assert.Error(t, err)
+}
+
+func TestSeal(t *testing.T) {
+	k := secret.NewKeyring("hunter2")
+	content := "---\ntitle: API\nsecret:\n  api_key: sk-123 # production\n  pin: 1234\n---\n# API\n"
+	sealed, count, err := secret.Seal(content, k)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.NotContains(t, sealed, "sk-123")
+	assert.NotContains(t, sealed, "1234\n")
+	assert.Contains(t, sealed, "# production")
+	assert.True(t, strings.HasSuffix(sealed, "---\n# API\n"))
+
+	keys, err := secret.Keys(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api_key", "pin"}, keys)
+	value, err := secret.Get(sealed, "pin", k)
+	require.NoError(t, err)
+	assert.Equal(t, "1234", value)
+	meta, _, err := frontmatter.Parse(sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "API", meta["title"])
+
+	again, count, err := secret.Seal(sealed, k)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.Equal(t, sealed, again, "sealed notes are left as written")
+
+	_, _, err = secret.Seal(strings.Replace(sealed, "pin:", "new: x\n  pin:", 1), secret.NewKeyring("wrong"))
+	assert.ErrorIs(t, err, secret.ErrDecrypt, "secrets are not sealed with another passphrase")
+
+	_, count, err = secret.Seal("# No frontmatter\n", k)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+}
+
+func TestSetAndGet(t *testing.T) {
+	k := secret.NewKeyring("hunter2")
+	content, err := secret.Set("# Bank\n", "iban", "DE89 3704", k)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(content, "---\nsecret:\n  iban: exo-secret:v1:"))
+	assert.True(t, strings.HasSuffix(content, "---\n# Bank\n"))
+
+	content, err = secret.Set(content, "iban", "DE00", k)
+	require.NoError(t, err)
+	value, err := secret.Get(content, "iban", k)
+	require.NoError(t, err)
+	assert.Equal(t, "DE00", value)
+
+	_, err = secret.Get(content, "pin", k)
+	assert.ErrorIs(t, err, secret.ErrNotFound)
+	_, err = secret.Get(content, "iban", secret.NewKeyring("wrong"))
+	assert.ErrorIs(t, err, secret.ErrDecrypt)
+	_, err = secret.Set(content, "pin", "1", secret.NewKeyring("wrong"))
+	assert.ErrorIs(t, err, secret.ErrDecrypt)
+
+	value, err = secret.Get("---\nsecret:\n  pin: 1234\n---\n", "pin", k)
+	require.NoError(t, err)
+	assert.Equal(t, "1234", value, "plain values are returned as written")
+}
+
+func TestPBKDF2(t *testing.T) {
+	// The PBKDF2-HMAC-SHA256 test vectors of RFC 7914, section 11.
+	tests := []struct {
+		passphrase, salt string
+		iterations       int
+		want             string
+	}{
+		{"passwd", "salt", 1, "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+			"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"},
+		{"Password", "NaCl", 80000, "4ddcd8f60b98be21830cee5ef22701f9641a4418d04c0414aeff08876b34ab56" +
+			"a1d425a1225833549adb841b51c9b3176a272bdebba1d078478f62b397f33c8d"},
+	}
+	for _, tt := range tests {
+		got := secret.PBKDF2(tt.passphrase, []byte(tt.salt), tt.iterations, 64)
+		assert.Equal(t, tt.want, hex.EncodeToString(got), "%s/%s", tt.passphrase, tt.salt)
+		assert.Equal(t, tt.want[:40], hex.EncodeToString(secret.PBKDF2(tt.passphrase, []byte(tt.salt), tt.iterations, 20)), "shorter keys are a prefix")
+	}
+}
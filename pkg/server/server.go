@@ -0,0 +1,346 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/export"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/vault"
+)
+
+// Options configures a Server.
+type Options struct {
+	// Notes are the notes served, created and updated.
+	vault.Notes
+	// Renderer converts note bodies to HTML; it defaults to export.MarkdownRenderer.
+	Renderer export.Renderer
+	// Token, when set, must be sent by clients as "Authorization: Bearer <token>".
+	Token string
+	// Hosts lists the host names, without port, requests may be addressed to;
+	// it defaults to LoopbackHosts. Checking the Host header keeps web pages
+	// from reaching the API through DNS rebinding.
+	Hosts []string
+}
+
+// LoopbackHosts are the host names of the local machine.
+var LoopbackHosts = []string{"localhost", "127.0.0.1", "::1"}
+
+// Server exposes the notes of a vault over a JSON HTTP API:
+//
+//	GET  /api/notes?tag=&q=   list notes, filtered by tag and title
+//	POST /api/notes           create a note from {"title", "dir", "content"}
+//	GET  /api/notes/{path}    read a note
+//	PUT  /api/notes/{path}    replace the content of a note with {"content"}
+//	GET  /api/search?q=       find notes whose content contains q
+//	GET  /api/render/{path}   render a note to HTML
+//
+// Note paths are slash-separated and relative to the data home. Requests to
+// another host than Options.Hosts and requests from web pages of another origin
+// are rejected, and POST and PUT bodies must be sent as application/json.
+type Server struct {
+	opts Options
+}
+
+// NoteResponse is a note returned by the API.
+type NoteResponse struct {
+	scan.Note
+	// Path is relative to the data home, replacing the absolute scan path.
+	Path    string `json:"path"`
+	Content string `json:"content,omitempty"`
+}
+
+// SearchResult is a line of a note matching a search.
+type SearchResult struct {
+	Path  string `json:"path"`
+	Title string `json:"title"`
+	Line  int    `json:"line"`
+	Text  string `json:"text"`
+}
+
+// New returns a Server for opts.
+func New(opts Options) *Server {
+	if opts.Renderer == nil {
+		opts.Renderer = export.MarkdownRenderer{}
+	}
+	if len(opts.Hosts) == 0 {
+		opts.Hosts = LoopbackHosts
+	}
+	return &Server{opts: opts}
+}
+
+// Handler returns the HTTP handler serving the API.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/notes", s.listNotes)
+	mux.HandleFunc("POST /api/notes", s.createNote)
+	mux.HandleFunc("GET /api/notes/{path...}", s.readNote)
+	mux.HandleFunc("PUT /api/notes/{path...}", s.updateNote)
+	mux.HandleFunc("GET /api/search", s.search)
+	mux.HandleFunc("GET /api/render/{path...}", s.render)
+	return s.guard(s.authorize(mux))
+}
+
+// guard rejects requests addressed to another host, cross-origin requests and
+// writes whose body is not JSON. A form or a fetch with a "simple" content
+// type can be sent to the API by any web page, without a preflight.
+func (s *Server) guard(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r.Host) {
+			writeError(w, http.StatusForbidden, fmt.Errorf("host %q not allowed", r.Host))
+			return
+		}
+		if crossOrigin(r) {
+			writeError(w, http.StatusForbidden, errors.New("cross-origin requests are not allowed"))
+			return
+		}
+		if r.Method == http.MethodPost || r.Method == http.MethodPut {
+			if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mediaType != "application/json" {
+				writeError(w, http.StatusUnsupportedMediaType, errors.New("content type must be application/json"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowedHost reports whether the Host header hostport names one of the
+// allowed hosts.
+func (s *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = strings.Trim(hostport, "[]")
+	}
+	for _, h := range s.opts.Hosts {
+		if strings.EqualFold(host, h) {
+			return true
+		}
+	}
+	return false
+}
+
+// crossOrigin reports whether r was sent by a web page of another origin than
+// the API, as told by the Sec-Fetch-Site header of browsers or, failing that,
+// their Origin header.
+func crossOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return true
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+	return err != nil || !strings.EqualFold(u.Host, r.Host)
+}
+
+// authorize rejects requests without the configured token.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.opts.Token == "" {
+		return next
+	}
+	want := []byte("Bearer " + s.opts.Token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			writeError(w, http.StatusUnauthorized, errors.New("missing or invalid token"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) listNotes(w http.ResponseWriter, r *http.Request) {
+	notes, err := s.notes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	filter := scan.Filter{Tag: r.URL.Query().Get("tag"), TitleContains: r.URL.Query().Get("q")}
+	out := []NoteResponse{}
+	for _, n := range filter.Apply(notes) {
+		out = append(out, s.response(n, ""))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+func (s *Server) createNote(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Title   string `json:"title"`
+		Dir     string `json:"dir"`
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	path, err := s.opts.Create(req.Title, req.Dir, req.Content)
+	if err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	s.writeNote(w, http.StatusCreated, path)
+}
+
+func (s *Server) readNote(w http.ResponseWriter, r *http.Request) {
+	path, ok := s.notePath(w, r)
+	if !ok {
+		return
+	}
+	s.writeNote(w, http.StatusOK, path)
+}
+
+func (s *Server) updateNote(w http.ResponseWriter, r *http.Request) {
+	path, ok := s.notePath(w, r)
+	if !ok {
+		return
+	}
+	var req struct {
+		Content *string `json:"content"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Content == nil {
+		writeError(w, http.StatusBadRequest, errors.New(`invalid request: expected {"content": "..."}`))
+		return
+	}
+	if err := s.opts.Update(path, *req.Content); err != nil {
+		writeError(w, status(err), err)
+		return
+	}
+	s.writeNote(w, http.StatusOK, path)
+}
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request) {
+	query := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("q")))
+	if query == "" {
+		writeError(w, http.StatusBadRequest, errors.New("query parameter q is required"))
+		return
+	}
+	results := []SearchResult{}
+	for res := range scan.Walk(r.Context(), scan.WalkOptions{Exclude: s.opts.Exclude, Ignore: s.opts.Ignore}, s.opts.DataHome()) {
+		if res.Err != nil {
+			writeError(w, http.StatusInternalServerError, res.Err)
+			return
+		}
+		for i, line := range strings.Split(res.Content, "\n") {
+			if strings.Contains(strings.ToLower(line), query) {
+				results = append(results, SearchResult{Path: s.opts.Rel(res.Note.Path), Title: res.Note.Title, Line: i + 1, Text: strings.TrimSpace(line)})
+			}
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Line < results[j].Line
+	})
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (s *Server) render(w http.ResponseWriter, r *http.Request) {
+	path, ok := s.notePath(w, r)
+	if !ok {
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	_, body := frontmatter.Split(string(content))
+	rendered, err := s.opts.Renderer.Render(body)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, rendered)
+}
+
+// notes scans the served notes.
+func (s *Server) notes(ctx context.Context) ([]scan.Note, error) {
+	notes, err := scan.ScanContext(ctx, scan.WalkOptions{Exclude: s.opts.Exclude, Ignore: s.opts.Ignore}, s.opts.DataHome())
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(notes, func(i, j int) bool { return notes[i].Path < notes[j].Path })
+	return notes, nil
+}
+
+// notePath resolves the {path} of r to an existing note, writing an error response
+// if it does not name one.
+func (s *Server) notePath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	path, err := s.opts.Resolve(r.PathValue("path"))
+	if err == nil && filepath.Ext(path) != scan.NoteExtension {
+		err = fmt.Errorf("not a note: %s", r.PathValue("path"))
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return "", false
+	}
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		writeError(w, http.StatusNotFound, fmt.Errorf("note not found: %s", r.PathValue("path")))
+		return "", false
+	}
+	return path, true
+}
+
+func (s *Server) response(n scan.Note, content string) NoteResponse {
+	return NoteResponse{Note: n, Path: s.opts.Rel(n.Path), Content: content}
+}
+
+// writeNote responds with the metadata and content of the note at path.
+func (s *Server) writeNote(w http.ResponseWriter, status int, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	n := scan.ParseNote(path, string(content), info.ModTime())
+	writeJSON(w, status, s.response(n, string(content)))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// status returns the HTTP status of a failure to create or update a note.
+func status(err error) int {
+	if errors.Is(err, fs.ErrReadOnly) {
+		return http.StatusForbidden
+	}
+	switch exoerrors.CodeOf(err) {
+	case exoerrors.Validation:
+		return http.StatusBadRequest
+	case exoerrors.Conflict:
+		return http.StatusConflict
+	case exoerrors.NotFound:
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
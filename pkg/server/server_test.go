@@ -0,0 +1,201 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/server"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVault(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"0-inbox/Hello.md":    "# Hello\n\nFirst line #go\nChannels are pipes.\n",
+		"ideas/Other.md":      "---\ntitle: Other\n---\nNothing here.\n",
+		"templates/zettel.md": "# {{.Title}}\n",
+		"0-inbox/image.png":   "png",
+		"0-inbox/nested/N.md": "# Nested\n",
+	} {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	return dir
+}
+
+func newServer(t *testing.T, dir, token string) *httptest.Server {
+	t.Helper()
+	s := server.New(server.Options{Notes: notes(dir, nil), Token: token})
+	srv := httptest.NewServer(s.Handler())
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func notes(dir string, fsys fs.FileSystem) vault.Notes {
+	return vault.Notes{
+		Config:     config.Config{Dir: config.DirConfig{DataHome: dir}},
+		FS:         fsys,
+		NewNoteDir: filepath.Join(dir, "0-inbox"),
+		Exclude:    []string{filepath.Join(dir, "templates")},
+	}
+}
+
+func do(t *testing.T, method, url, body string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(data)
+}
+
+func TestListNotes(t *testing.T) {
+	srv := newServer(t, newVault(t), "")
+
+	resp, body := do(t, http.MethodGet, srv.URL+"/api/notes", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var notes []server.NoteResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &notes))
+	var paths []string
+	for _, n := range notes {
+		paths = append(paths, n.Path)
+	}
+	assert.Equal(t, []string{"0-inbox/Hello.md", "0-inbox/nested/N.md", "ideas/Other.md"}, paths)
+
+	_, body = do(t, http.MethodGet, srv.URL+"/api/notes?tag=go", "")
+	require.NoError(t, json.Unmarshal([]byte(body), &notes))
+	require.Len(t, notes, 1)
+	assert.Equal(t, "Hello", notes[0].Title)
+}
+
+func TestCreateReadUpdate(t *testing.T) {
+	dir := newVault(t)
+	srv := newServer(t, dir, "")
+
+	resp, body := do(t, http.MethodPost, srv.URL+"/api/notes", `{"title":"New idea"}`)
+	require.Equal(t, http.StatusCreated, resp.StatusCode, body)
+	var n server.NoteResponse
+	require.NoError(t, json.Unmarshal([]byte(body), &n))
+	assert.Equal(t, "0-inbox/New idea.md", n.Path)
+	assert.Equal(t, "# New idea\n", n.Content)
+
+	resp, _ = do(t, http.MethodPost, srv.URL+"/api/notes", `{"title":"New idea"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, body = do(t, http.MethodPut, srv.URL+"/api/notes/0-inbox/New%20idea.md", `{"content":"# New idea\n\nMore #later\n"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode, body)
+	require.NoError(t, json.Unmarshal([]byte(body), &n))
+	assert.Equal(t, []string{"later"}, n.Tags)
+
+	resp, body = do(t, http.MethodGet, srv.URL+"/api/notes/0-inbox/New%20idea.md", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, body, `More #later`)
+
+	resp, _ = do(t, http.MethodPut, srv.URL+"/api/notes/0-inbox/New%20idea.md", `{"content":"---\nlocked: true\n---\n# New idea\n"}`)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	resp, _ = do(t, http.MethodPut, srv.URL+"/api/notes/0-inbox/New%20idea.md", `{"content":"# Changed\n"}`)
+	assert.Equal(t, http.StatusConflict, resp.StatusCode, "locked notes are not changed")
+
+	resp, _ = do(t, http.MethodGet, srv.URL+"/api/notes/0-inbox/Missing.md", "")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = do(t, http.MethodGet, srv.URL+"/api/notes/templates/zettel.md", "")
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	resp, _ = do(t, http.MethodPost, srv.URL+"/api/notes", `{"title":"x","dir":"../outside"}`)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestWritesThroughFS(t *testing.T) {
+	dir := newVault(t)
+	srv := httptest.NewServer(server.New(server.Options{Notes: notes(dir, fs.NewReadOnlyFileSystem(fs.NewOSFileSystem()))}).Handler())
+	t.Cleanup(srv.Close)
+
+	resp, body := do(t, http.MethodPost, srv.URL+"/api/notes", `{"title":"New idea"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, body)
+	assert.NoFileExists(t, filepath.Join(dir, "0-inbox", "New idea.md"))
+	resp, body = do(t, http.MethodPut, srv.URL+"/api/notes/0-inbox/Hello.md", `{"content":"# Changed\n"}`)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, body)
+}
+
+func TestSearchAndRender(t *testing.T) {
+	srv := newServer(t, newVault(t), "")
+
+	resp, body := do(t, http.MethodGet, srv.URL+"/api/search?q=channels", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	var results []server.SearchResult
+	require.NoError(t, json.Unmarshal([]byte(body), &results))
+	assert.Equal(t, []server.SearchResult{{Path: "0-inbox/Hello.md", Title: "Hello", Line: 4, Text: "Channels are pipes."}}, results)
+
+	resp, body = do(t, http.MethodGet, srv.URL+"/api/render/ideas/Other.md", "")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "<p>Nothing here.</p>\n", body)
+}
+
+func TestToken(t *testing.T) {
+	srv := newServer(t, newVault(t), "secret")
+
+	resp, _ := do(t, http.MethodGet, srv.URL+"/api/notes", "")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/notes", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestGuard(t *testing.T) {
+	dir := newVault(t)
+	srv := newServer(t, dir, "")
+
+	send := func(method, path string, header map[string]string, body string) int {
+		t.Helper()
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		if host, ok := header["Host"]; ok {
+			req.Host = host
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/notes", map[string]string{"Host": "evil.example:7474"}, ""), "DNS rebinding")
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/notes", map[string]string{"Host": "localhost:7474"}, ""))
+
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/notes", map[string]string{"Origin": "https://evil.example"}, ""))
+	assert.Equal(t, http.StatusForbidden, send(http.MethodGet, "/api/notes", map[string]string{"Sec-Fetch-Site": "cross-site"}, ""))
+	assert.Equal(t, http.StatusOK, send(http.MethodGet, "/api/notes", map[string]string{"Origin": srv.URL}, ""), "same origin")
+
+	assert.Equal(t, http.StatusUnsupportedMediaType,
+		send(http.MethodPost, "/api/notes", map[string]string{"Content-Type": "text/plain"}, `{"title":"CSRF"}`))
+	assert.Equal(t, http.StatusUnsupportedMediaType, send(http.MethodPost, "/api/notes", nil, `{"title":"CSRF"}`))
+	_, err := os.Stat(filepath.Join(dir, "0-inbox", "CSRF.md"))
+	assert.True(t, os.IsNotExist(err), "no note should be created")
+	assert.Equal(t, http.StatusCreated,
+		send(http.MethodPost, "/api/notes", map[string]string{"Content-Type": "application/json; charset=utf-8"}, `{"title":"JSON"}`))
+}
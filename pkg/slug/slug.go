@@ -0,0 +1,169 @@
+// Package slug turns note titles into file names: as typed, or in kebab,
+// snake or camel case, optionally shortened, without stop words and prefixed
+// with the date.
+package slug
+
+import (
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Style is how the words of a title are written in a file name.
+type Style string
+
+// The styles of file names.
+const (
+	// Title keeps the title as typed, only replacing the characters that
+	// cannot appear in a file name: "Go Channels: a primer".
+	Title Style = "title"
+	// Kebab lowercases the words and joins them with dashes: "go-channels-a-primer".
+	Kebab Style = "kebab"
+	// Snake lowercases the words and joins them with underscores: "go_channels_a_primer".
+	Snake Style = "snake"
+	// Camel joins the words, capitalized after the first: "goChannelsAPrimer".
+	Camel Style = "camel"
+)
+
+// Valid reports whether s is a known style; the empty style is Title.
+func (s Style) Valid() bool {
+	switch s {
+	case "", Title, Kebab, Snake, Camel:
+		return true
+	}
+	return false
+}
+
+// Separator returns the string the style puts between a date or ID prefix and
+// the rest of the file name.
+func (s Style) Separator() string {
+	switch s {
+	case Kebab, Camel:
+		return "-"
+	case Snake:
+		return "_"
+	}
+	return " "
+}
+
+// Options configures Make.
+type Options struct {
+	Style Style
+	// MaxLength is the number of characters above which the file name is
+	// cut, at a word boundary when possible; zero does not cut it.
+	MaxLength int
+	// StopWords drops common English words such as "a" and "the", unless the
+	// title has no other words.
+	StopWords bool
+	// DatePrefix is a Go time layout, e.g. "2006-01-02"; the date it formats
+	// is put before the title when not empty.
+	DatePrefix string
+}
+
+// Fallback is the file name of titles with no characters left.
+const Fallback = "untitled"
+
+// Make returns the file name, without extension, of a note titled title
+// created at now.
+func Make(title string, opts Options, now time.Time) string {
+	words := split(title, opts.Style)
+	if opts.StopWords {
+		words = dropStopWords(words)
+	}
+	name := join(words, opts.Style)
+	if name == "" {
+		name = Fallback
+	}
+	prefix := ""
+	if opts.DatePrefix != "" {
+		prefix = now.Format(opts.DatePrefix) + opts.Style.Separator()
+	}
+	if opts.MaxLength > 0 {
+		name = truncate(name, opts.MaxLength-utf8.RuneCountInString(prefix), opts.Style)
+	}
+	return prefix + name
+}
+
+// split returns the words of title: separated by spaces for the Title style,
+// else by any character that is neither a letter nor a digit.
+func split(title string, style Style) []string {
+	if style == "" || style == Title {
+		return strings.Fields(strings.NewReplacer("/", "-", "\\", "-", ":", "-").Replace(title))
+	}
+	return strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// join writes words in style.
+func join(words []string, style Style) string {
+	switch style {
+	case Kebab:
+		return strings.ToLower(strings.Join(words, "-"))
+	case Snake:
+		return strings.ToLower(strings.Join(words, "_"))
+	case Camel:
+		var sb strings.Builder
+		for i, w := range words {
+			w = strings.ToLower(w)
+			if i > 0 {
+				r, size := utf8.DecodeRuneInString(w)
+				w = string(unicode.ToUpper(r)) + w[size:]
+			}
+			sb.WriteString(w)
+		}
+		return sb.String()
+	}
+	return strings.Join(words, " ")
+}
+
+// truncate cuts name to at most max characters, at the last word boundary of
+// style when there is one.
+func truncate(name string, max int, style Style) string {
+	if max < 1 {
+		max = 1
+	}
+	runes := []rune(name)
+	if len(runes) <= max {
+		return name
+	}
+	cut := string(runes[:max])
+	if style != Camel {
+		sep := strings.TrimSpace(style.Separator())
+		if sep == "" {
+			sep = " "
+		}
+		// A cut right before a separator falls on a boundary already.
+		if !strings.HasPrefix(string(runes[max:]), sep) {
+			if i := strings.LastIndex(cut, sep); i > 0 {
+				cut = cut[:i]
+			}
+		}
+		cut = strings.TrimRight(cut, sep)
+	}
+	return cut
+}
+
+// dropStopWords returns words without the stop words, or words when all of
+// them are.
+func dropStopWords(words []string) []string {
+	var kept []string
+	for _, w := range words {
+		if !stopWords[strings.ToLower(w)] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		return words
+	}
+	return kept
+}
+
+// stopWords are the words dropped with Options.StopWords.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true,
+	"but": true, "by": true, "for": true, "from": true, "how": true, "in": true, "into": true,
+	"is": true, "it": true, "of": true, "on": true, "or": true, "that": true, "the": true,
+	"this": true, "to": true, "was": true, "what": true, "when": true, "with": true,
+}
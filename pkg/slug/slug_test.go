@@ -0,0 +1,51 @@
+package slug_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/slug"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMake(t *testing.T) {
+	now := time.Date(2025, 2, 8, 14, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		title string
+		opts  slug.Options
+		want  string
+	}{
+		{"title", "Go Channels: a primer", slug.Options{}, "Go Channels- a primer"},
+		{"kebab", "Go Channels: a primer", slug.Options{Style: slug.Kebab}, "go-channels-a-primer"},
+		{"snake", "Go Channels: a primer", slug.Options{Style: slug.Snake}, "go_channels_a_primer"},
+		{"camel", "Go Channels: a primer", slug.Options{Style: slug.Camel}, "goChannelsAPrimer"},
+		{"letters", "Café déjà vu", slug.Options{Style: slug.Kebab}, "café-déjà-vu"},
+		{"stop words", "The Art of the Deal", slug.Options{Style: slug.Kebab, StopWords: true}, "art-deal"},
+		{"only stop words", "To be or not to be", slug.Options{Style: slug.Snake, StopWords: true}, "not"},
+		{"all stop words", "The A", slug.Options{StopWords: true}, "The A"},
+		{"date prefix", "Go Channels", slug.Options{Style: slug.Kebab, DatePrefix: "2006-01-02"}, "2025-02-08-go-channels"},
+		{"date prefix title", "Go Channels", slug.Options{DatePrefix: "20060102"}, "20250208 Go Channels"},
+		{"max length", "Go channels and select statements", slug.Options{Style: slug.Kebab, MaxLength: 20}, "go-channels-and"},
+		{"max length boundary", "Go channels and select", slug.Options{Style: slug.Kebab, MaxLength: 15}, "go-channels-and"},
+		{"max length title", "Go channels and select", slug.Options{MaxLength: 13}, "Go channels"},
+		{"max length long word", "Supercalifragilistic", slug.Options{Style: slug.Snake, MaxLength: 5}, "super"},
+		{"max length camel", "Go channels", slug.Options{Style: slug.Camel, MaxLength: 6}, "goChan"},
+		{"max length with date", "Go channels", slug.Options{Style: slug.Kebab, MaxLength: 13, DatePrefix: "2006-01-02"}, "2025-02-08-go"},
+		{"empty", "?!", slug.Options{Style: slug.Kebab}, slug.Fallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, slug.Make(tt.title, tt.opts, now))
+		})
+	}
+}
+
+func TestStyle(t *testing.T) {
+	for _, style := range []slug.Style{"", slug.Title, slug.Kebab, slug.Snake, slug.Camel} {
+		assert.True(t, style.Valid(), style)
+	}
+	assert.False(t, slug.Style("pascal").Valid())
+	assert.Equal(t, " ", slug.Title.Separator())
+	assert.Equal(t, "_", slug.Snake.Separator())
+}
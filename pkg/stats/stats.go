@@ -0,0 +1,182 @@
+package stats
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Options configures statistics computation.
+type Options struct {
+	// Types maps a directory to the note type of the notes below it.
+	Types map[string]string
+	// DailyDir is the directory holding daily notes named YYYY-MM-DD.md.
+	DailyDir string
+	// Since limits the words-per-day and words-per-week series; zero means no limit.
+	Since time.Time
+	// Top is the number of entries reported for tags, largest and stalest notes.
+	Top int
+}
+
+// Count is a named counter.
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// NoteSummary identifies a note in a ranking.
+type NoteSummary struct {
+	Path     string    `json:"path"`
+	Title    string    `json:"title"`
+	Words    int       `json:"words"`
+	Modified time.Time `json:"modified"`
+}
+
+// Stats holds vault statistics.
+type Stats struct {
+	Notes        int           `json:"notes"`
+	Words        int           `json:"words"`
+	Links        int           `json:"links"`
+	ByType       []Count       `json:"by_type"`
+	WordsPerDay  []Count       `json:"words_per_day"`
+	WordsPerWeek []Count       `json:"words_per_week"`
+	Tags         []Count       `json:"tags"`
+	Largest      []NoteSummary `json:"largest"`
+	Stalest      []NoteSummary `json:"stalest"`
+}
+
+// Compute derives statistics from scanned notes.
+func Compute(notes []scan.Note, opts Options) Stats {
+	if opts.Top <= 0 {
+		opts.Top = 5
+	}
+	s := Stats{Notes: len(notes)}
+	byType := make(map[string]int)
+	tags := make(map[string]int)
+	perDay := make(map[string]int)
+	perWeek := make(map[string]int)
+
+	for _, n := range notes {
+		s.Words += n.Words
+		s.Links += len(n.Links)
+		byType[noteType(n.Path, opts.Types)]++
+		for _, tag := range n.Tags {
+			tags[tag]++
+		}
+		if date, ok := dailyDate(n.Path, opts.DailyDir); ok && !date.Before(opts.Since) {
+			perDay[date.Format("2006-01-02")] += n.Words
+			year, week := date.ISOWeek()
+			perWeek[fmt.Sprintf("%d-W%02d", year, week)] += n.Words
+		}
+	}
+
+	s.ByType = sortedByCount(byType, 0)
+	s.Tags = sortedByCount(tags, opts.Top)
+	s.WordsPerDay = sortedByName(perDay)
+	s.WordsPerWeek = sortedByName(perWeek)
+	s.Largest = rank(notes, opts.Top, func(a, b scan.Note) bool { return a.Words > b.Words })
+	s.Stalest = rank(notes, opts.Top, func(a, b scan.Note) bool { return a.Modified.Before(b.Modified) })
+	return s
+}
+
+// noteType returns the type of the directory containing path, preferring the most
+// specific (longest) matching directory, or "other".
+func noteType(path string, types map[string]string) string {
+	best, bestLen := "other", -1
+	for dir, typ := range types {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		if len(dir) > bestLen {
+			best, bestLen = typ, len(dir)
+		}
+	}
+	return best
+}
+
+// dailyDate parses the date of a daily note located directly in dailyDir.
+func dailyDate(path, dailyDir string) (time.Time, bool) {
+	if dailyDir == "" || filepath.Dir(path) != filepath.Clean(dailyDir) {
+		return time.Time{}, false
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	date, err := time.ParseInLocation("2006-01-02", name, time.Local)
+	return date, err == nil
+}
+
+// sortedByCount returns counts ordered by descending count then name, limited to top (0 = all).
+func sortedByCount(m map[string]int, top int) []Count {
+	counts := toCounts(m)
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	if top > 0 && len(counts) > top {
+		counts = counts[:top]
+	}
+	return counts
+}
+
+// sortedByName returns counts ordered by name.
+func sortedByName(m map[string]int) []Count {
+	counts := toCounts(m)
+	sort.Slice(counts, func(i, j int) bool { return counts[i].Name < counts[j].Name })
+	return counts
+}
+
+func toCounts(m map[string]int) []Count {
+	counts := make([]Count, 0, len(m))
+	for name, count := range m {
+		counts = append(counts, Count{Name: name, Count: count})
+	}
+	return counts
+}
+
+// rank returns the first top notes according to less.
+func rank(notes []scan.Note, top int, less func(a, b scan.Note) bool) []NoteSummary {
+	sorted := append([]scan.Note(nil), notes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return less(sorted[i], sorted[j]) })
+	if len(sorted) > top {
+		sorted = sorted[:top]
+	}
+	out := make([]NoteSummary, len(sorted))
+	for i, n := range sorted {
+		out[i] = NoteSummary{Path: n.Path, Title: n.Title, Words: n.Words, Modified: n.Modified}
+	}
+	return out
+}
+
+// Markdown renders the statistics as a Markdown block suitable for appending to a note.
+func (s Stats) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- Notes: %d", s.Notes))
+	if len(s.ByType) > 0 {
+		var types []string
+		for _, c := range s.ByType {
+			types = append(types, fmt.Sprintf("%s %d", c.Name, c.Count))
+		}
+		sb.WriteString(" (" + strings.Join(types, ", ") + ")")
+	}
+	sb.WriteString(fmt.Sprintf("\n- Words: %d\n- Links: %d\n", s.Words, s.Links))
+	if len(s.WordsPerWeek) > 0 {
+		sb.WriteString("- Words per week:\n")
+		for _, c := range s.WordsPerWeek {
+			sb.WriteString(fmt.Sprintf("  - %s: %d\n", c.Name, c.Count))
+		}
+	}
+	if len(s.Tags) > 0 {
+		var tags []string
+		for _, c := range s.Tags {
+			tags = append(tags, fmt.Sprintf("#%s (%d)", c.Name, c.Count))
+		}
+		sb.WriteString("- Top tags: " + strings.Join(tags, ", ") + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,41 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/stats"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	notes := []scan.Note{
+		{Path: "/v/day/2025-02-03.md", Title: "2025-02-03", Words: 10, Modified: recent},
+		{Path: "/v/day/2025-02-08.md", Title: "2025-02-08", Words: 20, Modified: recent},
+		{Path: "/v/day/2025-02-10.md", Title: "2025-02-10", Words: 5, Modified: recent},
+		{Path: "/v/zettel/a.md", Title: "A", Words: 100, Tags: []string{"go", "idea"}, Links: []string{"B"}, Modified: old},
+		{Path: "/v/zettel/b.md", Title: "B", Words: 50, Tags: []string{"go"}, Modified: recent},
+		{Path: "/v/misc.md", Title: "Misc", Words: 1, Modified: recent},
+	}
+	s := stats.Compute(notes, stats.Options{
+		Types:    map[string]string{"/v/day": "daily", "/v/zettel": "zettel"},
+		DailyDir: "/v/day",
+		Top:      2,
+	})
+
+	assert.Equal(t, 6, s.Notes)
+	assert.Equal(t, 186, s.Words)
+	assert.Equal(t, 1, s.Links)
+	assert.Equal(t, []stats.Count{{"daily", 3}, {"zettel", 2}, {"other", 1}}, s.ByType)
+	assert.Equal(t, []stats.Count{{"2025-02-03", 10}, {"2025-02-08", 20}, {"2025-02-10", 5}}, s.WordsPerDay)
+	assert.Equal(t, []stats.Count{{"2025-W06", 30}, {"2025-W07", 5}}, s.WordsPerWeek)
+	assert.Equal(t, []stats.Count{{"go", 2}, {"idea", 1}}, s.Tags)
+	assert.Equal(t, "A", s.Largest[0].Title)
+	assert.Len(t, s.Largest, 2)
+	assert.Equal(t, "A", s.Stalest[0].Title)
+	assert.Contains(t, s.Markdown(), "- Top tags: #go (2), #idea (1)")
+	assert.Contains(t, s.Markdown(), "- Notes: 6 (daily 3, zettel 2, other 1)")
+}
@@ -0,0 +1,181 @@
+package stats
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Metric is a per-note quantity tracked over time.
+type Metric string
+
+// Supported trend metrics.
+const (
+	MetricWords          Metric = "words"
+	MetricTasks          Metric = "tasks"
+	MetricTasksCompleted Metric = "tasks_completed"
+)
+
+// Metrics lists the supported metrics.
+var Metrics = []Metric{MetricWords, MetricTasks, MetricTasksCompleted}
+
+// Period is the bucket size of a trend.
+type Period string
+
+// Supported trend periods.
+const (
+	PeriodDaily   Period = "daily"
+	PeriodWeekly  Period = "weekly"
+	PeriodMonthly Period = "monthly"
+)
+
+// Periods lists the supported periods.
+var Periods = []Period{PeriodDaily, PeriodWeekly, PeriodMonthly}
+
+// TrendOptions configures a trend report.
+type TrendOptions struct {
+	Metric Metric
+	Period Period
+	// DailyDir is the directory holding daily notes named YYYY-MM-DD.md.
+	DailyDir string
+	// Since drops daily notes before this date; zero means no limit.
+	Since time.Time
+}
+
+// Point is the value of a metric over one period.
+type Point struct {
+	Label string    `json:"label"`
+	Start time.Time `json:"start"`
+	Value int       `json:"value"`
+}
+
+// Trend sums the metric of the daily notes among notes per period, from the first
+// to the last period with a daily note. Periods without notes have a zero value.
+func Trend(notes []scan.Note, opts TrendOptions) ([]Point, error) {
+	value, err := metricFunc(opts.Metric)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePeriod(opts.Period); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[time.Time]int)
+	var first, last time.Time
+	for _, n := range notes {
+		date, ok := dailyDate(n.Path, opts.DailyDir)
+		if !ok || date.Before(opts.Since) {
+			continue
+		}
+		start := periodStart(date, opts.Period)
+		totals[start] += value(n)
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+	if first.IsZero() {
+		return []Point{}, nil
+	}
+
+	var points []Point
+	for start := first; !start.After(last); start = nextPeriod(start, opts.Period) {
+		points = append(points, Point{Label: periodLabel(start, opts.Period), Start: start, Value: totals[start]})
+	}
+	return points, nil
+}
+
+func metricFunc(metric Metric) (func(scan.Note) int, error) {
+	switch metric {
+	case MetricWords:
+		return func(n scan.Note) int { return n.Words }, nil
+	case MetricTasks:
+		return func(n scan.Note) int { return n.Tasks }, nil
+	case MetricTasksCompleted:
+		return func(n scan.Note) int { return n.TasksDone }, nil
+	}
+	return nil, fmt.Errorf("unknown metric %q (expected %s)", metric, joinNames(Metrics))
+}
+
+func validatePeriod(period Period) error {
+	for _, p := range Periods {
+		if p == period {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown period %q (expected %s)", period, joinNames(Periods))
+}
+
+func joinNames[T ~string](values []T) string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = string(v)
+	}
+	return strings.Join(names, ", ")
+}
+
+// periodStart returns the first day of the period containing date. Weeks start on Monday.
+func periodStart(date time.Time, period Period) time.Time {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	switch period {
+	case PeriodWeekly:
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	case PeriodMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, day.Location())
+	}
+	return day
+}
+
+func nextPeriod(start time.Time, period Period) time.Time {
+	switch period {
+	case PeriodWeekly:
+		return start.AddDate(0, 0, 7)
+	case PeriodMonthly:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+func periodLabel(start time.Time, period Period) string {
+	switch period {
+	case PeriodWeekly:
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case PeriodMonthly:
+		return start.Format("2006-01")
+	}
+	return start.Format("2006-01-02")
+}
+
+// Chart renders points as a horizontal bar chart whose longest bar is width cells.
+func Chart(points []Point, width int) string {
+	if width <= 0 {
+		width = 40
+	}
+	max, labelWidth := 0, 0
+	for _, p := range points {
+		if p.Value > max {
+			max = p.Value
+		}
+		if len(p.Label) > labelWidth {
+			labelWidth = len(p.Label)
+		}
+	}
+	var sb strings.Builder
+	for _, p := range points {
+		bar := 0
+		if max > 0 {
+			bar = p.Value * width / max
+		}
+		if bar == 0 && p.Value > 0 {
+			bar = 1
+		}
+		fmt.Fprintf(&sb, "%-*s %s %d\n", labelWidth, p.Label, strings.Repeat("█", bar), p.Value)
+	}
+	return sb.String()
+}
@@ -0,0 +1,60 @@
+package stats_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/stats"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trendNotes() []scan.Note {
+	return []scan.Note{
+		{Path: "/v/day/2025-02-03.md", Words: 100, Tasks: 4, TasksDone: 3},
+		{Path: "/v/day/2025-02-05.md", Words: 50, Tasks: 2, TasksDone: 1},
+		{Path: "/v/day/2025-02-19.md", Words: 30, Tasks: 1, TasksDone: 1},
+		{Path: "/v/0-inbox/2025-02-04.md", Words: 999, TasksDone: 9},
+	}
+}
+
+func TestTrend_Weekly(t *testing.T) {
+	points, err := stats.Trend(trendNotes(), stats.TrendOptions{
+		Metric:   stats.MetricTasksCompleted,
+		Period:   stats.PeriodWeekly,
+		DailyDir: "/v/day",
+	})
+	require.NoError(t, err)
+
+	require.Len(t, points, 3)
+	assert.Equal(t, "2025-W06", points[0].Label)
+	assert.Equal(t, 4, points[0].Value)
+	assert.Equal(t, "2025-W07", points[1].Label)
+	assert.Equal(t, 0, points[1].Value)
+	assert.Equal(t, "2025-W08", points[2].Label)
+	assert.Equal(t, 1, points[2].Value)
+}
+
+func TestTrend_MonthlyWordsSince(t *testing.T) {
+	points, err := stats.Trend(trendNotes(), stats.TrendOptions{
+		Metric:   stats.MetricWords,
+		Period:   stats.PeriodMonthly,
+		DailyDir: "/v/day",
+		Since:    time.Date(2025, 2, 4, 0, 0, 0, 0, time.Local),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []stats.Point{{Label: "2025-02", Start: time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), Value: 80}}, points)
+}
+
+func TestTrend_InvalidOptions(t *testing.T) {
+	_, err := stats.Trend(nil, stats.TrendOptions{Metric: "mood", Period: stats.PeriodDaily})
+	assert.ErrorContains(t, err, "unknown metric")
+	_, err = stats.Trend(nil, stats.TrendOptions{Metric: stats.MetricWords, Period: "yearly"})
+	assert.ErrorContains(t, err, "unknown period")
+}
+
+func TestChart(t *testing.T) {
+	chart := stats.Chart([]stats.Point{{Label: "2025-W06", Value: 4}, {Label: "2025-W07", Value: 0}, {Label: "2025-W08", Value: 1}}, 8)
+	assert.Equal(t, "2025-W06 ████████ 4\n2025-W07  0\n2025-W08 ██ 1\n", chart)
+}
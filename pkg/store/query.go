@@ -0,0 +1,243 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Query selects notes from the index. Zero fields match everything.
+type Query struct {
+	// Tag matches notes carrying the tag, case-insensitively; a leading # is ignored.
+	Tag string
+	// Type matches the frontmatter type, or the top-level directory of notes
+	// without one.
+	Type string
+	// Since matches notes created at or after the time.
+	Since time.Time
+	// Before matches notes created before the time.
+	Before time.Time
+	// TitleContains matches notes whose title or an alias contains the text,
+	// case-insensitively.
+	TitleContains string
+	// Text matches notes containing every word of the text in their title,
+	// aliases or content.
+	Text string
+}
+
+// Notes returns the notes matching q, ordered by path.
+func (ix *Index) Notes(q Query) ([]scan.Note, error) {
+	var where []string
+	var args []interface{}
+	if q.Tag != "" {
+		where = append(where, "path IN (SELECT path FROM tags WHERE tag = ?)")
+		args = append(args, strings.ToLower(strings.TrimPrefix(q.Tag, "#")))
+	}
+	if q.Type != "" {
+		where = append(where, "type = ?")
+		args = append(args, strings.ToLower(q.Type))
+	}
+	if !q.Since.IsZero() {
+		where = append(where, "created >= ?")
+		args = append(args, q.Since.UnixNano())
+	}
+	if !q.Before.IsZero() {
+		where = append(where, "created < ?")
+		args = append(args, q.Before.UnixNano())
+	}
+	if q.TitleContains != "" {
+		where = append(where, "(instr(lower(title), ?) > 0 OR path IN (SELECT path FROM aliases WHERE instr(alias, ?) > 0))")
+		args = append(args, strings.ToLower(q.TitleContains), strings.ToLower(q.TitleContains))
+	}
+	if match := matchTerms(q.Text); match != "" {
+		where = append(where, "path IN (SELECT path FROM content WHERE content MATCH ?)")
+		args = append(args, match)
+	}
+	query := "SELECT data FROM notes"
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	return ix.query(query+" ORDER BY path", args...)
+}
+
+// Select returns the notes matching the parsed query q, ordered by path.
+func (ix *Index) Select(q query.Query) ([]scan.Note, error) {
+	if q.IsEmpty() {
+		return ix.query("SELECT data FROM notes ORDER BY path")
+	}
+	where, args := compile(q.Expr)
+	return ix.query("SELECT data FROM notes WHERE "+where+" ORDER BY path", args...)
+}
+
+// compile returns the SQL condition selecting the notes matched by e.
+func compile(e query.Expr) (string, []interface{}) {
+	switch e := e.(type) {
+	case query.And:
+		return compileAll(e, " AND ")
+	case query.Or:
+		return compileAll(e, " OR ")
+	case query.Not:
+		where, args := compile(e.Expr)
+		return "NOT " + where, args
+	case query.Term:
+		return compileTerm(e)
+	}
+	return "1", nil
+}
+
+// compileAll joins the conditions of exprs with sep, in parentheses.
+func compileAll(exprs []query.Expr, sep string) (string, []interface{}) {
+	var parts []string
+	var args []interface{}
+	for _, e := range exprs {
+		where, a := compile(e)
+		parts = append(parts, where)
+		args = append(args, a...)
+	}
+	return "(" + strings.Join(parts, sep) + ")", args
+}
+
+// compileTerm returns the SQL condition selecting the notes matched by t.
+func compileTerm(t query.Term) (string, []interface{}) {
+	switch t.Field {
+	case query.Tag:
+		return "path IN (SELECT path FROM tags WHERE tag = ?)", []interface{}{strings.ToLower(t.Value)}
+	case query.Type:
+		return "type = ?", []interface{}{strings.ToLower(t.Value)}
+	case query.Title:
+		text := strings.ToLower(t.Value)
+		return "(instr(lower(title), ?) > 0 OR path IN (SELECT path FROM aliases WHERE instr(alias, ?) > 0))", []interface{}{text, text}
+	case query.Text:
+		// A phrase in double quotes matches its words in sequence.
+		return "path IN (SELECT path FROM content WHERE content MATCH ?)", []interface{}{`"` + strings.ReplaceAll(t.Value, `"`, `""`) + `"`}
+	case query.Created, query.Modified:
+		column := string(t.Field)
+		var where []string
+		var args []interface{}
+		if !t.From.IsZero() {
+			where = append(where, column+" >= ?")
+			args = append(args, t.From.UnixNano())
+		}
+		if !t.To.IsZero() {
+			where = append(where, column+" < ?")
+			args = append(args, t.To.UnixNano())
+		}
+		if len(where) == 0 {
+			return "1", nil
+		}
+		return "(" + strings.Join(where, " AND ") + ")", args
+	}
+	return "0", nil
+}
+
+// Search returns the notes containing every word of text in their title, aliases
+// or content, best matches first.
+func (ix *Index) Search(text string) ([]scan.Note, error) {
+	match := matchTerms(text)
+	if match == "" {
+		return nil, nil
+	}
+	return ix.query(`SELECT notes.data FROM content JOIN notes ON notes.path = content.path
+		WHERE content MATCH ? ORDER BY bm25(content)`, match)
+}
+
+// matchTerms returns the full-text query matching every word of text, quoted
+// so that no word is taken for an operator.
+func matchTerms(text string) string {
+	var terms []string
+	for _, word := range strings.Fields(text) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// Backlinks returns the notes linking to the note at path, by title, alias,
+// ID, file name or path relative to the root.
+func (ix *Index) Backlinks(path string) ([]scan.Note, error) {
+	var data string
+	if err := ix.db.QueryRow("SELECT data FROM notes WHERE path = ?", path).Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("note %s is not indexed", path)
+		}
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	var n scan.Note
+	if err := json.Unmarshal([]byte(data), &n); err != nil {
+		return nil, fmt.Errorf("corrupt index entry: %w", err)
+	}
+	args := []interface{}{path, linkKey(n.Title), linkKey(filepath.Base(path))}
+	if rel, err := filepath.Rel(ix.root, path); err == nil {
+		args = append(args, linkKey(filepath.ToSlash(rel)))
+	}
+	for _, alias := range n.Aliases {
+		args = append(args, linkKey(alias))
+	}
+	for _, id := range scan.IDs(n) {
+		args = append(args, linkKey(id))
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(args)-1), ", ")
+	return ix.query(`SELECT data FROM notes WHERE path != ? AND path IN
+		(SELECT path FROM links WHERE target IN (`+placeholders+`)) ORDER BY path`, args...)
+}
+
+// Tags returns the tags of the indexed notes, lowercased, ordered by the number
+// of notes carrying them, then by name.
+func (ix *Index) Tags() ([]string, error) {
+	rows, err := ix.db.Query("SELECT tag FROM tags GROUP BY tag ORDER BY count(*) DESC, tag")
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	defer rows.Close()
+	var tags []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, fmt.Errorf("failed to query index: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	return tags, nil
+}
+
+// Count returns the number of notes indexed.
+func (ix *Index) Count() (int, error) {
+	var n int
+	if err := ix.db.QueryRow("SELECT count(*) FROM notes").Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to query index: %w", err)
+	}
+	return n, nil
+}
+
+// query returns the notes whose data is selected by query.
+func (ix *Index) query(query string, args ...interface{}) ([]scan.Note, error) {
+	rows, err := ix.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	defer rows.Close()
+	var notes []scan.Note
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to query index: %w", err)
+		}
+		var n scan.Note
+		if err := json.Unmarshal([]byte(data), &n); err != nil {
+			return nil, fmt.Errorf("corrupt index entry: %w", err)
+		}
+		notes = append(notes, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query index: %w", err)
+	}
+	return notes, nil
+}
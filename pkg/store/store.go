@@ -0,0 +1,372 @@
+// Package store keeps an index of note metadata in a SQLite database, so that
+// notes can be listed, searched and followed through links without reading
+// every file of the vault.
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite" // registers the "sqlite" driver
+
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// schemaVersion is bumped whenever the schema or the indexed data changes; an
+// index of another version is rebuilt.
+const schemaVersion = 3
+
+const schema = `
+CREATE TABLE notes (
+	path     TEXT PRIMARY KEY,
+	title    TEXT NOT NULL,
+	type     TEXT NOT NULL,
+	created  INTEGER NOT NULL,
+	modified INTEGER NOT NULL,
+	size     INTEGER NOT NULL,
+	mtime    INTEGER NOT NULL,
+	data     TEXT NOT NULL
+);
+CREATE INDEX notes_type ON notes(type);
+CREATE INDEX notes_created ON notes(created);
+CREATE TABLE tags (
+	path TEXT NOT NULL REFERENCES notes(path) ON DELETE CASCADE,
+	tag  TEXT NOT NULL
+);
+CREATE INDEX tags_tag ON tags(tag);
+CREATE INDEX tags_path ON tags(path);
+CREATE TABLE links (
+	path   TEXT NOT NULL REFERENCES notes(path) ON DELETE CASCADE,
+	target TEXT NOT NULL
+);
+CREATE INDEX links_target ON links(target);
+CREATE INDEX links_path ON links(path);
+CREATE TABLE aliases (
+	path  TEXT NOT NULL REFERENCES notes(path) ON DELETE CASCADE,
+	alias TEXT NOT NULL
+);
+CREATE INDEX aliases_alias ON aliases(alias);
+CREATE INDEX aliases_path ON aliases(path);
+CREATE VIRTUAL TABLE content USING fts5(path UNINDEXED, title, aliases, body);
+`
+
+// Index is the metadata index of the notes under a root directory.
+type Index struct {
+	db   *sql.DB
+	root string
+	// ignore matches the paths left out of the index.
+	ignore *scan.Ignore
+}
+
+// SyncStats counts the changes applied to the index by a sync.
+type SyncStats struct {
+	Added, Updated, Removed, Unchanged int
+}
+
+// Open opens the index at path, creating it if needed, for the notes under
+// root. An index written by another version of exo is emptied, to be filled by
+// the next Sync. Since the index is derived from the notes, a .gitignore next
+// to it keeps it out of synced vaults.
+func Open(path, root string) (*Index, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+	if err := ignore(path); err != nil {
+		return nil, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index: %w", err)
+	}
+	ix := &Index{db: db, root: root}
+	if err := ix.migrate(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open index %s: %w", path, err)
+	}
+	return ix, nil
+}
+
+// ignore adds the database at path, and its journal files, to the .gitignore
+// of its directory.
+func ignore(path string) error {
+	gitignore := filepath.Join(filepath.Dir(path), ".gitignore")
+	pattern := "/" + filepath.Base(path) + "*"
+	content, err := os.ReadFile(gitignore)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", gitignore, err)
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) == pattern {
+			return nil
+		}
+	}
+	if len(content) > 0 && !strings.HasSuffix(string(content), "\n") {
+		content = append(content, '\n')
+	}
+	if err := os.WriteFile(gitignore, append(content, pattern+"\n"...), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", gitignore, err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// migrate creates the schema, recreating it when it is of another version.
+func (ix *Index) migrate() error {
+	var version int
+	if err := ix.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version == schemaVersion {
+		return nil
+	}
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for _, table := range []string{"content", "aliases", "links", "tags", "notes"} {
+		if _, err := tx.Exec("DROP TABLE IF EXISTS " + table); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(schema); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", schemaVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SetIgnore leaves the paths ig matches out of the index from the next Sync,
+// which removes those already indexed.
+func (ix *Index) SetIgnore(ig *scan.Ignore) {
+	ix.ignore = ig
+}
+
+// Sync brings the index up to date with the notes under the root, skipping
+// hidden files, the excluded directories and the ignored paths. Only notes whose size or
+// modification time changed since they were indexed are read.
+func (ix *Index) Sync(exclude ...string) (SyncStats, error) {
+	var stats SyncStats
+	type stamp struct{ size, mtime int64 }
+	indexed := make(map[string]stamp)
+	rows, err := ix.db.Query("SELECT path, size, mtime FROM notes")
+	if err != nil {
+		return stats, fmt.Errorf("failed to read index: %w", err)
+	}
+	for rows.Next() {
+		var path string
+		var s stamp
+		if err := rows.Scan(&path, &s.size, &s.mtime); err != nil {
+			rows.Close()
+			return stats, fmt.Errorf("failed to read index: %w", err)
+		}
+		indexed[path] = s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return stats, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	skip := scan.WalkOptions{Exclude: exclude, Ignore: ix.ignore}
+	err = filepath.WalkDir(ix.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == ix.root && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if skip.Skip(ix.root, path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || filepath.Ext(path) != scan.NoteExtension {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		old, ok := indexed[path]
+		delete(indexed, path)
+		if ok && old.size == info.Size() && old.mtime == info.ModTime().UnixNano() {
+			stats.Unchanged++
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := ix.put(tx, scan.ParseNote(path, string(content), info.ModTime()), string(content), info); err != nil {
+			return fmt.Errorf("failed to index %s: %w", path, err)
+		}
+		if ok {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to scan %s: %w", ix.root, err)
+	}
+
+	for path := range indexed {
+		if err := remove(tx, path); err != nil {
+			return stats, fmt.Errorf("failed to remove %s from the index: %w", path, err)
+		}
+		stats.Removed++
+	}
+	if err := tx.Commit(); err != nil {
+		return stats, fmt.Errorf("failed to update index: %w", err)
+	}
+	return stats, nil
+}
+
+// Update re-indexes the notes at paths, such as notes reported changed by a
+// watcher. Paths that no longer exist are removed from the index together with
+// the notes below them, so that removed directories can be passed too.
+func (ix *Index) Update(paths ...string) (SyncStats, error) {
+	var stats SyncStats
+	tx, err := ix.db.Begin()
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			res, err := tx.Exec("DELETE FROM notes WHERE path = ? OR substr(path, 1, ?) = ?",
+				path, len(path)+1, path+string(filepath.Separator))
+			if err != nil {
+				return stats, fmt.Errorf("failed to remove %s from the index: %w", path, err)
+			}
+			if _, err := tx.Exec("DELETE FROM content WHERE path NOT IN (SELECT path FROM notes)"); err != nil {
+				return stats, fmt.Errorf("failed to remove %s from the index: %w", path, err)
+			}
+			n, _ := res.RowsAffected()
+			stats.Removed += int(n)
+			continue
+		}
+		if err != nil {
+			return stats, err
+		}
+		if info.IsDir() || filepath.Ext(path) != scan.NoteExtension {
+			continue
+		}
+		var indexed int
+		if err := tx.QueryRow("SELECT count(*) FROM notes WHERE path = ?", path).Scan(&indexed); err != nil {
+			return stats, fmt.Errorf("failed to read index: %w", err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return stats, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		if err := ix.put(tx, scan.ParseNote(path, string(content), info.ModTime()), string(content), info); err != nil {
+			return stats, fmt.Errorf("failed to index %s: %w", path, err)
+		}
+		if indexed > 0 {
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return stats, fmt.Errorf("failed to update index: %w", err)
+	}
+	return stats, nil
+}
+
+// Rebuild empties the index and indexes every note again.
+func (ix *Index) Rebuild(exclude ...string) (SyncStats, error) {
+	for _, table := range []string{"content", "aliases", "links", "tags", "notes"} {
+		if _, err := ix.db.Exec("DELETE FROM " + table); err != nil {
+			return SyncStats{}, fmt.Errorf("failed to empty index: %w", err)
+		}
+	}
+	return ix.Sync(exclude...)
+}
+
+// put stores the metadata and content of note n, replacing what was indexed.
+func (ix *Index) put(tx *sql.Tx, n scan.Note, content string, info fs.FileInfo) error {
+	if err := remove(tx, n.Path); err != nil {
+		return err
+	}
+	data, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("INSERT INTO notes (path, title, type, created, modified, size, mtime, data) VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		n.Path, n.Title, ix.noteType(n), n.Created.UnixNano(), n.Modified.UnixNano(), info.Size(), info.ModTime().UnixNano(), string(data)); err != nil {
+		return err
+	}
+	for _, tag := range n.Tags {
+		if _, err := tx.Exec("INSERT INTO tags (path, tag) VALUES (?, ?)", n.Path, strings.ToLower(tag)); err != nil {
+			return err
+		}
+	}
+	for _, link := range n.Links {
+		if _, err := tx.Exec("INSERT INTO links (path, target) VALUES (?, ?)", n.Path, linkKey(link)); err != nil {
+			return err
+		}
+	}
+	for _, alias := range n.Aliases {
+		if _, err := tx.Exec("INSERT INTO aliases (path, alias) VALUES (?, ?)", n.Path, linkKey(alias)); err != nil {
+			return err
+		}
+	}
+	_, err = tx.Exec("INSERT INTO content (path, title, aliases, body) VALUES (?, ?, ?, ?)",
+		n.Path, n.Title, strings.Join(n.Aliases, "\n"), content)
+	return err
+}
+
+// remove deletes the note at path from the index.
+func remove(tx *sql.Tx, path string) error {
+	if _, err := tx.Exec("DELETE FROM content WHERE path = ?", path); err != nil {
+		return err
+	}
+	_, err := tx.Exec("DELETE FROM notes WHERE path = ?", path)
+	return err
+}
+
+// noteType returns the type of n: its frontmatter "type" or, failing that, the
+// top-level directory it is in.
+func (ix *Index) noteType(n scan.Note) string {
+	if t := frontmatter.String(n.Meta, "type"); t != "" {
+		return strings.ToLower(t)
+	}
+	rel, err := filepath.Rel(ix.root, n.Path)
+	if err != nil {
+		return ""
+	}
+	if dir, _, ok := strings.Cut(filepath.ToSlash(rel), "/"); ok {
+		return strings.ToLower(dir)
+	}
+	return ""
+}
+
+// linkKey normalizes the target of a wikilink, or the name of a note, so that
+// links can be matched case-insensitively and with or without extension.
+func linkKey(name string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(name), scan.NoteExtension))
+}
@@ -0,0 +1,214 @@
+package store_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/query"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func paths(notes []scan.Note) []string {
+	var out []string
+	for _, n := range notes {
+		out = append(out, filepath.Base(n.Path))
+	}
+	return out
+}
+
+func openIndex(t *testing.T, root string) *store.Index {
+	t.Helper()
+	ix, err := store.Open(filepath.Join(root, ".exo", "index.db"), root)
+	require.NoError(t, err)
+	t.Cleanup(func() { ix.Close() })
+	return ix
+}
+
+func TestIndex_Sync(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "zettel/Go.md", "---\ncreated: 2025-01-02\n---\n# Go\n\nChannels and [[Rust]] #lang\n")
+	rust := write(t, root, "zettel/Rust.md", "# Rust\n\nOwnership #lang #systems\n")
+	write(t, root, "templates/zet.md", "# {{.Title}}\n")
+	write(t, root, ".trash/Old.md", "# Old\n")
+
+	ix := openIndex(t, root)
+	stats, err := ix.Sync(filepath.Join(root, "templates"))
+	require.NoError(t, err)
+	assert.Equal(t, store.SyncStats{Added: 2}, stats)
+
+	notes, err := ix.Notes(store.Query{})
+	require.NoError(t, err)
+	require.Equal(t, []string{"Go.md", "Rust.md"}, paths(notes))
+	want, err := scan.ReadNote(rust)
+	require.NoError(t, err)
+	assert.Equal(t, want.Title, notes[1].Title)
+	assert.Equal(t, want.Tags, notes[1].Tags)
+	assert.True(t, want.Modified.Equal(notes[1].Modified))
+
+	// Unchanged notes are not read again; changed and deleted ones are picked up.
+	stats, err = ix.Sync(filepath.Join(root, "templates"))
+	require.NoError(t, err)
+	assert.Equal(t, store.SyncStats{Unchanged: 2}, stats)
+
+	write(t, root, "zettel/Rust.md", "# Rust\n\nBorrowing #lang\n")
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(rust, later, later))
+	require.NoError(t, os.Remove(filepath.Join(root, "zettel", "Go.md")))
+	write(t, root, "0-inbox/Idea.md", "# Idea\n")
+
+	stats, err = ix.Sync(filepath.Join(root, "templates"))
+	require.NoError(t, err)
+	assert.Equal(t, store.SyncStats{Added: 1, Updated: 1, Removed: 1}, stats)
+	notes, err = ix.Notes(store.Query{Tag: "#SYSTEMS"})
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+	n, err := ix.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	stats, err = ix.Rebuild(filepath.Join(root, "templates"))
+	require.NoError(t, err)
+	assert.Equal(t, store.SyncStats{Added: 2}, stats)
+
+	// Notes ignored from then on are removed.
+	ig, err := scan.NewIgnore(root, []string{"0-inbox/"})
+	require.NoError(t, err)
+	ix.SetIgnore(ig)
+	stats, err = ix.Sync(filepath.Join(root, "templates"))
+	require.NoError(t, err)
+	assert.Equal(t, store.SyncStats{Removed: 1, Unchanged: 1}, stats)
+}
+
+func TestIndex_Queries(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "zettel/Go.md", "---\ncreated: 2025-01-02\n---\n# Go\n\nChannels and [[Rust]] #lang\n")
+	rust := write(t, root, "zettel/Rust.md", "---\naliases: [Rustlang, Crab language]\n---\n# Rust\n\nOwnership #lang #systems\n")
+	write(t, root, "0-inbox/Borrowing.md", "---\ntype: Question\ncreated: 2025-03-01\n---\n# How does borrowing work?\n\nSee [[rust.md]] and [[zettel/Rust|the note]].\n")
+	write(t, root, "0-inbox/Ownership.md", "# Ownership in Go\n\nGarbage collected.\n")
+	write(t, root, "0-inbox/Memory.md", "# Memory safety\n\nSee [[rustlang]].\n")
+
+	ix := openIndex(t, root)
+	_, err := ix.Sync()
+	require.NoError(t, err)
+
+	tests := []struct {
+		query store.Query
+		want  []string
+	}{
+		{store.Query{Tag: "lang"}, []string{"Go.md", "Rust.md"}},
+		{store.Query{Type: "zettel"}, []string{"Go.md", "Rust.md"}},
+		{store.Query{Type: "question"}, []string{"Borrowing.md"}},
+		{store.Query{Since: time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), Tag: "lang"}, []string{"Rust.md"}},
+		{store.Query{Before: time.Date(2025, 2, 1, 0, 0, 0, 0, time.Local), Tag: "lang"}, []string{"Go.md"}},
+		{store.Query{TitleContains: "GO"}, []string{"Ownership.md", "Go.md"}},
+		{store.Query{TitleContains: "crab"}, []string{"Rust.md"}},
+		{store.Query{Text: "collected garbage"}, []string{"Ownership.md"}},
+		{store.Query{Text: "ownership", Tag: "lang"}, []string{"Rust.md"}},
+	}
+	for _, tt := range tests {
+		notes, err := ix.Notes(tt.query)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, paths(notes), "%+v", tt.query)
+	}
+
+	notes, err := ix.Search("ownership")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"Rust.md", "Ownership.md"}, paths(notes))
+	notes, err = ix.Search(`garbage "collected`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Ownership.md"}, paths(notes))
+
+	notes, err = ix.Backlinks(rust)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Borrowing.md", "Memory.md", "Go.md"}, paths(notes))
+	_, err = ix.Backlinks(filepath.Join(root, "Missing.md"))
+	assert.Error(t, err)
+
+	tags, err := ix.Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"lang", "systems"}, tags)
+}
+
+func TestIndex_Select(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "zettel/Go.md", "---\ncreated: 2025-01-02\n---\n# Go\n\nChannels and goroutines #lang\n")
+	write(t, root, "zettel/Rust.md", "---\naliases: [Crab language]\ncreated: 2025-02-10\n---\n# Rust\n\nOwnership #lang #systems\n")
+	write(t, root, "projects/k8s.md", "---\ncreated: 2024-06-01\ntags: [project]\n---\n# Cluster\n\nRunning kubernetes operators.\n")
+	write(t, root, "projects/site.md", "---\ncreated: 2025-03-01\ntags: [project]\n---\n# Site\n\nStatic site.\n")
+
+	ix := openIndex(t, root)
+	_, err := ix.Sync()
+	require.NoError(t, err)
+
+	now := time.Date(2025, 3, 15, 12, 0, 0, 0, time.Local)
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"k8s.md", "site.md", "Go.md", "Rust.md"}},
+		{`tag:project AND created:>2024-12-31 OR "kubernetes"`, []string{"k8s.md", "site.md"}},
+		{"tag:project -kubernetes", []string{"site.md"}},
+		{"#lang created:2025-01", []string{"Go.md"}},
+		{"type:zettel NOT (ownership OR title:crab)", []string{"Go.md"}},
+		{"created:<=2025-02-10 created:>=2025-02", []string{"Rust.md"}},
+		{"created:30d", []string{"site.md"}},
+		{"created:<6m", []string{"k8s.md"}},
+		{`"kubernetes operators"`, []string{"k8s.md"}},
+		{`"operators kubernetes"`, nil},
+	}
+	for _, tt := range tests {
+		q, err := query.Parse(tt.query, now)
+		require.NoError(t, err, tt.query)
+		notes, err := ix.Select(q)
+		require.NoError(t, err, tt.query)
+		assert.Equal(t, tt.want, paths(notes), tt.query)
+	}
+}
+
+func TestOpen_IgnoresIndex(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, ".exo/.gitignore", "/review.json")
+	openIndex(t, root)
+	openIndex(t, root)
+
+	content, err := os.ReadFile(filepath.Join(root, ".exo", ".gitignore"))
+	require.NoError(t, err)
+	assert.Equal(t, "/review.json\n/index.db*\n", string(content))
+}
+
+func TestIndex_Update(t *testing.T) {
+	root := t.TempDir()
+	goNote := write(t, root, "zettel/Go.md", "# Go\n")
+	write(t, root, "zettel/deep/Rust.md", "# Rust\n\n#lang\n")
+	ix := openIndex(t, root)
+	_, err := ix.Sync()
+	require.NoError(t, err)
+
+	write(t, root, "zettel/Go.md", "# Go\n\n#lang\n")
+	idea := write(t, root, "Idea.md", "# Idea\n")
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "zettel", "deep")))
+
+	stats, err := ix.Update(goNote, idea, filepath.Join(root, "zettel", "deep"), filepath.Join(root, "notes.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, store.SyncStats{Added: 1, Updated: 1, Removed: 1}, stats)
+
+	notes, err := ix.Notes(store.Query{Tag: "lang"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go.md"}, paths(notes))
+	notes, err = ix.Search("rust")
+	require.NoError(t, err)
+	assert.Empty(t, notes)
+}
@@ -0,0 +1,151 @@
+// Package suggest ranks the completions of a link or tag being typed, for
+// shell completion and editor plugins: names matching what was typed best come
+// first, lifted by how frequently and recently their notes were used.
+package suggest
+
+import (
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Scores of the ways a name can match what was typed.
+const (
+	exact      = 100
+	prefix     = 80
+	wordPrefix = 60
+	substring  = 40
+	letters    = 20
+)
+
+// Candidate is a completion.
+type Candidate struct {
+	// Name is the text to complete to: the file name of a note, without
+	// extension, or a tag, without #.
+	Name string `json:"name"`
+	// Match is the name of the note matched, e.g. its title or an alias, when
+	// not Name.
+	Match string `json:"match,omitempty"`
+	Title string `json:"title,omitempty"`
+	// Path is relative to the root the notes are under.
+	Path string `json:"path,omitempty"`
+	// Notes is the number of notes carrying a tag.
+	Notes int     `json:"notes,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// Frecency scores the use of the note at path; see recent.Entry.Frecency.
+type Frecency func(path string) float64
+
+// Links returns the notes under root whose file name, title, ID or alias
+// matches typed, best first. Every note matches when typed is empty.
+func Links(notes []scan.Note, root, typed string, frecency Frecency) []Candidate {
+	typed = strings.ToLower(strings.TrimSpace(typed))
+	var candidates []Candidate
+	for _, n := range notes {
+		name := strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension)
+		best, match := -1, ""
+		for _, other := range append(append([]string{name, n.Title}, scan.IDs(n)...), n.Aliases...) {
+			if q := quality(other, typed); q > best {
+				best, match = q, other
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		c := Candidate{Name: name, Title: n.Title, Path: n.Path, Score: float64(best) + bonus(frecency, n.Path)}
+		if match != name {
+			c.Match = match
+		}
+		if rel, err := filepath.Rel(root, n.Path); err == nil {
+			c.Path = filepath.ToSlash(rel)
+		}
+		candidates = append(candidates, c)
+	}
+	rank(candidates)
+	return candidates
+}
+
+// Tags returns the tags of notes matching typed, a leading # ignored, best
+// first: tags carried by more notes, and by notes used more, rank higher.
+func Tags(notes []scan.Note, typed string, frecency Frecency) []Candidate {
+	typed = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(typed), "#"))
+	counts := make(map[string]int)
+	used := make(map[string]float64)
+	for _, n := range notes {
+		for _, tag := range n.Tags {
+			tag = strings.ToLower(tag)
+			counts[tag]++
+			if frecency != nil {
+				used[tag] += frecency(n.Path)
+			}
+		}
+	}
+	var candidates []Candidate
+	for tag, count := range counts {
+		q := quality(tag, typed)
+		if q < 0 {
+			continue
+		}
+		score := float64(q) + 10*math.Log10(1+float64(count)) + 10*math.Log10(1+used[tag])
+		candidates = append(candidates, Candidate{Name: tag, Notes: count, Score: score})
+	}
+	rank(candidates)
+	return candidates
+}
+
+// quality scores how name matches typed, lowercased, or returns -1 when it
+// does not. Anything matches nothing typed, with a score of 0.
+func quality(name, typed string) int {
+	name = strings.ToLower(name)
+	switch {
+	case typed == "":
+		return 0
+	case name == typed:
+		return exact
+	case strings.HasPrefix(name, typed):
+		return prefix
+	}
+	for i := strings.Index(name, typed); i >= 0; {
+		if strings.ContainsRune(" -_/.", rune(name[i-1])) {
+			return wordPrefix
+		}
+		next := strings.Index(name[i+1:], typed)
+		if next < 0 {
+			return substring
+		}
+		i += next + 1
+	}
+	rest := []rune(typed)
+	for _, r := range name {
+		if len(rest) > 0 && r == rest[0] {
+			rest = rest[1:]
+		}
+	}
+	if len(rest) == 0 {
+		return letters
+	}
+	return -1
+}
+
+// bonus returns how much the use of the note at path lifts its score: 10 for
+// each tenfold increase of its frecency.
+func bonus(frecency Frecency, path string) float64 {
+	if frecency == nil {
+		return 0
+	}
+	return 10 * math.Log10(1+frecency(path))
+}
+
+// rank orders candidates by score, then by name.
+func rank(candidates []Candidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+}
@@ -0,0 +1,71 @@
+package suggest_test
+
+import (
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/suggest"
+	"github.com/stretchr/testify/assert"
+)
+
+var notes = []scan.Note{
+	{Path: "/v/zettel/Go channels.md", Title: "Go channels", Tags: []string{"go", "concurrency"}},
+	{Path: "/v/zettel/Goroutines.md", Title: "Goroutines", Tags: []string{"go"}},
+	{Path: "/v/zettel/Rust.md", Title: "Rust", Aliases: []string{"Rustlang"}, Tags: []string{"lang"}},
+	{Path: "/v/0-inbox/20250208143005 Borrowing.md", Title: "How does borrowing work?", Tags: []string{"rust", "Lang"}},
+	{Path: "/v/ideas/Algorithms.md", Title: "Algorithms", Tags: []string{"cs/algorithms"}},
+}
+
+func names(candidates []suggest.Candidate) []string {
+	var out []string
+	for _, c := range candidates {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+func TestLinks(t *testing.T) {
+	candidates := suggest.Links(notes, "/v", "go", nil)
+	assert.Equal(t, []string{"Go channels", "Goroutines", "Algorithms", "20250208143005 Borrowing"}, names(candidates),
+		"prefixes rank first, then substrings, then letters in order")
+	assert.Equal(t, "zettel/Go channels.md", candidates[0].Path)
+
+	candidates = suggest.Links(notes, "/v", "RUSTLANG", nil)
+	assert.Equal(t, []string{"Rust"}, names(candidates))
+	assert.Equal(t, "Rustlang", candidates[0].Match, "the alias matched")
+
+	assert.Equal(t, []string{"20250208143005 Borrowing"}, names(suggest.Links(notes, "/v", "borrow", nil)))
+	assert.Equal(t, []string{"Go channels"}, names(suggest.Links(notes, "/v", "chan", nil)), "words of names match")
+	assert.Len(t, suggest.Links(notes, "/v", "", nil), len(notes))
+	assert.Empty(t, suggest.Links(notes, "/v", "python", nil))
+}
+
+func TestLinks_Frecency(t *testing.T) {
+	frecency := func(path string) float64 {
+		if path == "/v/zettel/Goroutines.md" {
+			return 500
+		}
+		return 0
+	}
+	candidates := suggest.Links(notes, "/v", "go", frecency)
+	assert.Equal(t, []string{"Goroutines", "Go channels", "Algorithms", "20250208143005 Borrowing"}, names(candidates), "used notes rank first")
+	assert.Equal(t, []string{"Goroutines", "20250208143005 Borrowing", "Algorithms", "Go channels", "Rust"},
+		names(suggest.Links(notes, "/v", "", frecency)))
+}
+
+func TestTags(t *testing.T) {
+	candidates := suggest.Tags(notes, "#", nil)
+	assert.Equal(t, []string{"go", "lang", "concurrency", "cs/algorithms", "rust"}, names(candidates), "tags on more notes first")
+	assert.Equal(t, 2, candidates[1].Notes, "tags match case-insensitively")
+
+	assert.Equal(t, []string{"lang"}, names(suggest.Tags(notes, "la", nil)))
+	assert.Equal(t, []string{"cs/algorithms"}, names(suggest.Tags(notes, "algo", nil)), "nested tags match by segment")
+
+	frecency := func(path string) float64 {
+		if path == "/v/ideas/Algorithms.md" {
+			return 1000
+		}
+		return 0
+	}
+	assert.Equal(t, "cs/algorithms", suggest.Tags(notes, "", frecency)[0].Name)
+}
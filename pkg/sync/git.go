@@ -0,0 +1,200 @@
+package sync
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// remoteName is the name of the remote managed by exo.
+const remoteName = "origin"
+
+// GitBackend implements Backend using the git command-line tool.
+type GitBackend struct {
+	Dir string // Vault root (data_home).
+}
+
+// NewGitBackend creates a Git backend for the vault at dir.
+func NewGitBackend(dir string) *GitBackend {
+	return &GitBackend{Dir: dir}
+}
+
+// IsRepo reports whether the vault is already a Git repository.
+func (g *GitBackend) IsRepo() bool {
+	_, err := os.Stat(filepath.Join(g.Dir, ".git"))
+	return err == nil
+}
+
+// Init creates the repository if needed, configures the remote when given,
+// and commits any existing notes.
+func (g *GitBackend) Init(remote string) error {
+	if err := os.MkdirAll(g.Dir, 0755); err != nil {
+		return fmt.Errorf("failed to create vault directory: %w", err)
+	}
+	if !g.IsRepo() {
+		if _, err := g.git("init"); err != nil {
+			return err
+		}
+	}
+	if remote != "" {
+		if _, err := g.git("remote", "get-url", remoteName); err == nil {
+			if _, err := g.git("remote", "set-url", remoteName, remote); err != nil {
+				return err
+			}
+		} else if _, err := g.git("remote", "add", remoteName, remote); err != nil {
+			return err
+		}
+	}
+	_, err := g.Commit("exo sync init")
+	return err
+}
+
+// Status parses "git status --porcelain=v1 --branch".
+func (g *GitBackend) Status() (*Status, error) {
+	if !g.IsRepo() {
+		return nil, errors.New("vault is not a git repository; run \"exo sync init\"")
+	}
+	out, err := g.git("status", "--porcelain=v1", "--branch", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+	status := &Status{}
+	if remote, err := g.git("remote", "get-url", remoteName); err == nil {
+		status.Remote = strings.TrimSpace(remote)
+	}
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "## ") {
+			parseBranchLine(strings.TrimPrefix(line, "## "), status)
+			continue
+		}
+		if len(line) < 4 {
+			continue
+		}
+		path := line[3:]
+		if i := strings.Index(path, " -> "); i >= 0 {
+			path = path[i+4:]
+		}
+		status.Changes = append(status.Changes, Change{Path: unquote(path), Status: changeStatus(line[:2])})
+	}
+	return status, nil
+}
+
+// parseBranchLine parses the "## branch...upstream [ahead N, behind M]" header.
+func parseBranchLine(line string, status *Status) {
+	line = strings.TrimPrefix(line, "No commits yet on ")
+	branch := line
+	if i := strings.Index(branch, "..."); i >= 0 {
+		branch = branch[:i]
+	} else if i := strings.Index(branch, " "); i >= 0 {
+		branch = branch[:i]
+	}
+	status.Branch = branch
+	if i := strings.Index(line, "["); i >= 0 {
+		for _, part := range strings.Split(strings.Trim(line[i:], "[]"), ", ") {
+			fields := strings.Fields(part)
+			if len(fields) != 2 {
+				continue
+			}
+			n, _ := strconv.Atoi(fields[1])
+			switch fields[0] {
+			case "ahead":
+				status.Ahead = n
+			case "behind":
+				status.Behind = n
+			}
+		}
+	}
+}
+
+// changeStatus maps a porcelain XY code to a Change status.
+func changeStatus(xy string) string {
+	switch {
+	case xy == "??":
+		return "untracked"
+	case xy == "DD" || xy == "AA" || strings.Contains(xy, "U"):
+		return "conflict"
+	case strings.Contains(xy, "R"):
+		return "renamed"
+	case strings.Contains(xy, "A"):
+		return "added"
+	case strings.Contains(xy, "D"):
+		return "deleted"
+	default:
+		return "modified"
+	}
+}
+
+// unquote removes the quoting git applies to paths with special characters.
+func unquote(path string) string {
+	if s, err := strconv.Unquote(path); err == nil {
+		return s
+	}
+	return path
+}
+
+// Commit stages all changes and commits them with message.
+func (g *GitBackend) Commit(message string) (bool, error) {
+	if _, err := g.git("add", "--all"); err != nil {
+		return false, err
+	}
+	if _, err := g.git("diff", "--cached", "--quiet"); err == nil {
+		return false, nil
+	}
+	if _, err := g.git("commit", "--quiet", "-m", message); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Push pushes the current branch to the remote, setting its upstream.
+func (g *GitBackend) Push() error {
+	if _, err := g.git("remote", "get-url", remoteName); err != nil {
+		return errors.New("no remote configured; set sync.remote and run \"exo sync init\"")
+	}
+	_, err := g.git("push", "--quiet", "--set-upstream", remoteName, "HEAD")
+	return err
+}
+
+// Pull fetches and merges the remote branch. Conflicted files are reported as a *ConflictError.
+func (g *GitBackend) Pull() error {
+	if _, err := g.git("remote", "get-url", remoteName); err != nil {
+		return errors.New("no remote configured; set sync.remote and run \"exo sync init\"")
+	}
+	branch, err := g.git("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return err
+	}
+	_, pullErr := g.git("pull", "--quiet", "--no-rebase", "--no-edit", remoteName, strings.TrimSpace(branch))
+	if pullErr == nil {
+		return nil
+	}
+	out, err := g.git("diff", "--name-only", "--diff-filter=U")
+	if err == nil && strings.TrimSpace(out) != "" {
+		return &ConflictError{Paths: strings.Split(strings.TrimSpace(out), "\n")}
+	}
+	return pullErr
+}
+
+// git runs a git command in the vault and returns its standard output.
+func (g *GitBackend) git(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", g.Dir}, args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return stdout.String(), fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
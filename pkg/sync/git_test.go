@@ -0,0 +1,94 @@
+package sync_test
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/sync"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupGit skips the test when git is unavailable and configures a commit identity.
+func setupGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "exo")
+	t.Setenv("GIT_AUTHOR_EMAIL", "exo@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "exo")
+	t.Setenv("GIT_COMMITTER_EMAIL", "exo@example.com")
+	t.Setenv("GIT_CONFIG_GLOBAL", filepath.Join(t.TempDir(), "gitconfig"))
+}
+
+func TestGitBackend_InitStatusCommit(t *testing.T) {
+	setupGit(t)
+	vault := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "a.md"), []byte("# A"), 0644))
+
+	g := sync.NewGitBackend(vault)
+	require.NoError(t, g.Init("https://example.com/vault.git"))
+
+	status, err := g.Status()
+	require.NoError(t, err)
+	assert.True(t, status.Clean())
+	assert.Equal(t, "https://example.com/vault.git", status.Remote)
+
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "a.md"), []byte("# A2"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(vault, "b.md"), []byte("# B"), 0644))
+	status, err = g.Status()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []sync.Change{{Path: "a.md", Status: "modified"}, {Path: "b.md", Status: "untracked"}}, status.Changes)
+
+	committed, err := g.Commit(sync.CommitMessage("zet", status.Changes))
+	require.NoError(t, err)
+	assert.True(t, committed)
+
+	committed, err = g.Commit("nothing")
+	require.NoError(t, err)
+	assert.False(t, committed)
+}
+
+func TestGitBackend_PushPullConflict(t *testing.T) {
+	setupGit(t)
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	require.NoError(t, exec.Command("git", "init", "--bare", "--quiet", remote).Run())
+
+	first := sync.NewGitBackend(filepath.Join(t.TempDir(), "first"))
+	require.NoError(t, os.MkdirAll(first.Dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(first.Dir, "note.md"), []byte("base\n"), 0644))
+	require.NoError(t, first.Init(remote))
+	require.NoError(t, first.Push())
+
+	secondDir := filepath.Join(t.TempDir(), "second")
+	require.NoError(t, exec.Command("git", "clone", "--quiet", remote, secondDir).Run())
+	second := sync.NewGitBackend(secondDir)
+
+	// Both sides edit the same line.
+	require.NoError(t, os.WriteFile(filepath.Join(first.Dir, "note.md"), []byte("first\n"), 0644))
+	_, err := first.Commit("first edit")
+	require.NoError(t, err)
+	require.NoError(t, first.Push())
+
+	require.NoError(t, os.WriteFile(filepath.Join(secondDir, "note.md"), []byte("second\n"), 0644))
+	_, err = second.Commit("second edit")
+	require.NoError(t, err)
+
+	err = second.Pull()
+	var conflict *sync.ConflictError
+	require.True(t, errors.As(err, &conflict), "expected conflict, got %v", err)
+	assert.Equal(t, []string{"note.md"}, conflict.Paths)
+}
+
+func TestCommitMessage(t *testing.T) {
+	changes := []sync.Change{
+		{Path: "day/2025-02-08.md", Status: "modified"},
+		{Path: "0-inbox/a.md", Status: "untracked"},
+	}
+	assert.Equal(t, "exo log: modified day/2025-02-08.md; added 0-inbox/a.md", sync.CommitMessage("log", changes))
+	assert.Equal(t, "exo sync", sync.CommitMessage("sync", nil))
+}
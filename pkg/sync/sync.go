@@ -0,0 +1,80 @@
+package sync
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Backend is a version-control backend that keeps a vault in sync with a remote.
+type Backend interface {
+	// Init prepares the vault for syncing and records the remote, if any.
+	Init(remote string) error
+	// Status reports the local changes and how the vault relates to its remote.
+	Status() (*Status, error)
+	// Commit records all local changes with the given message. It reports
+	// whether anything was committed.
+	Commit(message string) (bool, error)
+	// Push sends local commits to the remote.
+	Push() error
+	// Pull fetches and merges remote changes. Merge conflicts are reported as a *ConflictError.
+	Pull() error
+}
+
+// Change is a changed file in the vault.
+type Change struct {
+	Path   string // Path relative to the vault root.
+	Status string // One of "added", "modified", "deleted", "renamed", "untracked" or "conflict".
+}
+
+// Status describes the sync state of a vault.
+type Status struct {
+	Branch  string
+	Remote  string
+	Ahead   int
+	Behind  int
+	Changes []Change
+}
+
+// Clean reports whether there are no local changes.
+func (s *Status) Clean() bool {
+	return len(s.Changes) == 0
+}
+
+// ConflictError is returned by Pull when the merge left conflicted files.
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("merge conflict in %d note(s): %s", len(e.Paths), strings.Join(e.Paths, ", "))
+}
+
+// CommitMessage builds an informative commit message for changes made by the
+// given exo command, e.g. "exo log: modified day/2025-02-08.md".
+func CommitMessage(command string, changes []Change) string {
+	if len(changes) == 0 {
+		return "exo " + command
+	}
+	byStatus := make(map[string][]string)
+	var order []string
+	for _, c := range changes {
+		status := c.Status
+		if status == "untracked" {
+			status = "added"
+		}
+		if _, ok := byStatus[status]; !ok {
+			order = append(order, status)
+		}
+		byStatus[status] = append(byStatus[status], c.Path)
+	}
+	var parts []string
+	for _, status := range order {
+		paths := byStatus[status]
+		if len(paths) > 3 {
+			parts = append(parts, fmt.Sprintf("%s %s and %d more", status, strings.Join(paths[:3], ", "), len(paths)-3))
+		} else {
+			parts = append(parts, fmt.Sprintf("%s %s", status, strings.Join(paths, ", ")))
+		}
+	}
+	return fmt.Sprintf("exo %s: %s", command, strings.Join(parts, "; "))
+}
@@ -1,6 +1,7 @@
 package templates
 
 import (
+	"bytes"
 	"embed"
 	"fmt"
 	"os"
@@ -93,12 +94,26 @@ func (r *DefaultInputReader) ReadResponse() (string, error) {
 
 // InstallDefaultTemplates installs built-in templates from the default template store
 // into the target directory (usually the custom TemplateDir).
+//
+// A missing target directory is created with cfg.DirPermissions, including any
+// setgid bit so that templates in a group-shared directory inherit its group.
+// New templates are written with cfg.FilePermissions regardless of the umask;
+// script-style templates (starting with "#!") are also made executable wherever
+// they are readable. Overwritten templates are backed up and rewritten in place,
+// keeping the owner, group and mode of the existing file.
 func InstallDefaultTemplates(cfg TemplateConfig, opts InstallOptions, defaultStore DefaultTemplateStore) error {
 	if strings.TrimSpace(opts.TargetDir) == "" {
 		return fmt.Errorf("target directory cannot be empty")
 	}
+	filePerms, dirPerms := cfg.FilePermissions, cfg.DirPermissions
+	if filePerms == 0 {
+		filePerms = 0644
+	}
+	if dirPerms == 0 {
+		dirPerms = defaultDirPerms
+	}
 	// Ensure target directory exists.
-	if err := os.MkdirAll(opts.TargetDir, defaultDirPerms); err != nil {
+	if err := ensureDir(opts.TargetDir, dirPerms); err != nil {
 		return fmt.Errorf("failed to create target directory: %w", err)
 	}
 	if defaultStore == nil {
@@ -131,25 +146,104 @@ func InstallDefaultTemplates(cfg TemplateConfig, opts InstallOptions, defaultSto
 				}
 			}
 			// Create backup.
-			if err := CreateBackup(destPath); err != nil {
+			if _, err := copyBackup(destPath); err != nil {
 				return fmt.Errorf("failed to create backup for %s: %w", destPath, err)
 			}
+			// Rewrite in place.
+			if err := writeExisting(destPath, content); err != nil {
+				return fmt.Errorf("failed to write template %s: %w", file, err)
+			}
+			continue
 		}
 		// Write the file.
-		if err := os.WriteFile(destPath, content, cfg.FilePermissions); err != nil {
+		if err := writeNew(destPath, content, TemplateMode(filePerms, content)); err != nil {
 			return fmt.Errorf("failed to write template %s: %w", file, err)
 		}
 	}
 	return nil
 }
 
+// TemplateMode returns perm with the execute bits mirroring the read bits when
+// content is a script, i.e. starts with a shebang line.
+func TemplateMode(perm os.FileMode, content []byte) os.FileMode {
+	if bytes.HasPrefix(content, []byte("#!")) {
+		perm |= (perm & 0444) >> 2
+	}
+	return perm
+}
+
+// ensureDir creates dir with perm if it does not exist. An existing directory is
+// left untouched so that its ownership and mode are preserved.
+func ensureDir(dir string, perm os.FileMode) error {
+	if _, err := os.Stat(dir); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(dir, perm.Perm()); err != nil {
+		return err
+	}
+	// MkdirAll applies the umask and ignores the setgid and sticky bits.
+	return os.Chmod(dir, perm)
+}
+
+// writeNew creates path with content and sets its mode to perm, regardless of the umask.
+func writeNew(path string, content []byte, perm os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm.Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// writeExisting replaces the content of the existing file at path without
+// recreating it, so its owner, group and mode are kept.
+func writeExisting(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_TRUNC, 0)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// backupPath returns the path a backup of path is written to: path with
+// BackupExtension appended, or with a timestamp as well if that backup exists.
+func backupPath(path string) string {
+	backup := path + BackupExtension
+	if _, err := os.Stat(backup); err == nil {
+		timestamp := time.Now().Format("20060102150405")
+		backup = fmt.Sprintf("%s.%s%s", path, timestamp, BackupExtension)
+	}
+	return backup
+}
+
 // CreateBackup renames the existing file by appending backupExtension.
 // If a backup already exists, it appends a timestamp.
 func CreateBackup(path string) error {
-	backupPath := path + BackupExtension
-	if _, err := os.Stat(backupPath); err == nil {
-		timestamp := time.Now().Format("20060102150405")
-		backupPath = fmt.Sprintf("%s.%s%s", path, timestamp, BackupExtension)
+	return os.Rename(path, backupPath(path))
+}
+
+// copyBackup copies the existing file at path to its backup path, keeping its
+// mode, and returns the backup path.
+func copyBackup(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
 	}
-	return os.Rename(path, backupPath)
+	backup := backupPath(path)
+	return backup, writeNew(backup, content, info.Mode().Perm())
 }
@@ -144,3 +144,64 @@ func TestcreateBackup_UniqueNames(t *testing.T) {
 	}
 	assert.GreaterOrEqual(t, len(backups), 2)
 }
+
+// mapStore is a DefaultTemplateStore backed by a map of file names to contents.
+type mapStore map[string]string
+
+func (m mapStore) ReadTemplate(name string) ([]byte, error) { return []byte(m[name]), nil }
+
+func (m mapStore) ListTemplates() ([]string, error) {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func TestInstallDefaultTemplates_Permissions(t *testing.T) {
+	targetDir := filepath.Join(t.TempDir(), "shared")
+	store := mapStore{
+		"note.md": "# {{.Title}}\n",
+		"hook.sh": "#!/bin/sh\necho hi\n",
+	}
+	cfg := templates.TemplateConfig{
+		FilePermissions: 0640,
+		DirPermissions:  os.ModeSetgid | 0770,
+	}
+	opts := templates.InstallOptions{TargetDir: targetDir, Force: true}
+	require.NoError(t, templates.InstallDefaultTemplates(cfg, opts, store))
+
+	info, err := os.Stat(targetDir)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSetgid|0770, info.Mode()&(os.ModePerm|os.ModeSetgid))
+
+	info, err = os.Stat(filepath.Join(targetDir, "note.md"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Join(targetDir, "hook.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0750), info.Mode().Perm())
+}
+
+func TestInstallDefaultTemplates_OverwriteKeepsMode(t *testing.T) {
+	targetDir := t.TempDir()
+	destPath := filepath.Join(targetDir, "hook.sh")
+	require.NoError(t, os.WriteFile(destPath, []byte("old"), 0700))
+	require.NoError(t, os.Chmod(destPath, 0700))
+
+	cfg := templates.TemplateConfig{FilePermissions: 0644}
+	opts := templates.InstallOptions{TargetDir: targetDir, Force: true}
+	require.NoError(t, templates.InstallDefaultTemplates(cfg, opts, mapStore{"hook.sh": "#!/bin/sh\n"}))
+
+	info, err := os.Stat(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0700), info.Mode().Perm())
+	content, err := os.ReadFile(destPath)
+	require.NoError(t, err)
+	assert.Equal(t, "#!/bin/sh\n", string(content))
+
+	backup, err := os.ReadFile(destPath + templates.BackupExtension)
+	require.NoError(t, err)
+	assert.Equal(t, "old", string(backup))
+}
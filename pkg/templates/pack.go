@@ -0,0 +1,267 @@
+package templates
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+)
+
+// PackTemplateDir is the directory of a template pack holding its templates.
+// Packs without it keep their templates at the top level.
+const PackTemplateDir = "templates"
+
+// dirTemplateStore is a DefaultTemplateStore reading the templates of a directory.
+type dirTemplateStore struct {
+	dir string
+	ext string
+}
+
+// NewDirTemplateStore creates a DefaultTemplateStore holding the files of dir
+// with extension ext.
+func NewDirTemplateStore(dir, ext string) DefaultTemplateStore {
+	return &dirTemplateStore{dir: dir, ext: ext}
+}
+
+func (d *dirTemplateStore) ReadTemplate(name string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(d.dir, name))
+}
+
+func (d *dirTemplateStore) ListTemplates() ([]string, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && filepath.Ext(entry.Name()) == d.ext {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+// InstallPack fetches the template pack at source, validates its templates and
+// scaffolds and installs them like InstallDefaultTemplates, backing up or
+// prompting for the templates and scaffolds it would overwrite.
+func InstallPack(ctx context.Context, cfg TemplateConfig, opts InstallOptions, source string) error {
+	dir, cleanup, err := FetchPack(ctx, source)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+	if _, err := os.Stat(filepath.Join(dir, PackTemplateDir)); err == nil {
+		dir = filepath.Join(dir, PackTemplateDir)
+	}
+	ext := cfg.TemplateExtension
+	if ext == "" {
+		ext = ".md"
+	}
+	store := NewDirTemplateStore(dir, ext)
+	scaffolds, err := validateScaffolds(filepath.Join(dir, ScaffoldDir), ext, cfg.Funcs)
+	if err != nil {
+		return exoerrors.New(exoerrors.Validation, "invalid template pack %s: %w", source, err)
+	}
+	// Packs of scaffolds alone need no templates.
+	if names, _ := store.ListTemplates(); len(names) > 0 || !scaffolds {
+		if err := ValidatePack(store, cfg.Funcs); err != nil {
+			return exoerrors.New(exoerrors.Validation, "invalid template pack %s: %w", source, err)
+		}
+	}
+	if err := InstallDefaultTemplates(cfg, opts, store); err != nil {
+		return err
+	}
+	return installScaffolds(cfg, opts, filepath.Join(dir, ScaffoldDir))
+}
+
+// ValidatePack checks that store holds at least one template and that all of
+// them parse, with funcs available to them.
+func ValidatePack(store DefaultTemplateStore, funcs template.FuncMap) error {
+	names, err := store.ListTemplates()
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no templates found")
+	}
+	for _, name := range names {
+		content, err := store.ReadTemplate(name)
+		if err != nil {
+			return fmt.Errorf("failed to read template %s: %w", name, err)
+		}
+		if _, err := template.New(name).Funcs(funcs).Parse(string(content)); err != nil {
+			return fmt.Errorf("template %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// FetchPack makes the template pack at source available in a local directory
+// and returns the directory with a function removing what was fetched. source
+// is a local directory, a git repository (a "git@" address, a URL ending in
+// ".git" or any URL prefixed with "git+") or the URL of a .tar.gz archive.
+// Other http(s) URLs are cloned as git repositories.
+func FetchPack(ctx context.Context, source string) (string, func(), error) {
+	noop := func() {}
+	switch {
+	case isArchiveURL(source):
+		tmp, err := os.MkdirTemp("", "exo-pack-")
+		if err != nil {
+			return "", noop, err
+		}
+		cleanup := func() { os.RemoveAll(tmp) }
+		if err := downloadArchive(ctx, source, tmp); err != nil {
+			cleanup()
+			return "", noop, exoerrors.New(exoerrors.IO, "failed to fetch template pack %s: %w", source, err)
+		}
+		return archiveRoot(tmp), cleanup, nil
+	case isGitSource(source):
+		url := strings.TrimPrefix(source, "git+")
+		if strings.HasPrefix(url, "-") {
+			// git would take it for an option.
+			return "", noop, exoerrors.New(exoerrors.Validation, "invalid template pack source %q", source)
+		}
+		tmp, err := os.MkdirTemp("", "exo-pack-")
+		if err != nil {
+			return "", noop, err
+		}
+		cleanup := func() { os.RemoveAll(tmp) }
+		if err := cloneRepo(ctx, url, tmp); err != nil {
+			cleanup()
+			return "", noop, exoerrors.New(exoerrors.IO, "failed to fetch template pack %s: %w", source, err)
+		}
+		return tmp, cleanup, nil
+	}
+	dir := source
+	if rest, ok := strings.CutPrefix(dir, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", noop, err
+		}
+		dir = filepath.Join(home, rest)
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to fetch template pack %s: %w", source, err)
+	}
+	if !info.IsDir() {
+		return "", noop, exoerrors.New(exoerrors.Validation, "template pack %s is not a directory", source)
+	}
+	return dir, noop, nil
+}
+
+// isArchiveURL reports whether source is the URL of a .tar.gz archive.
+func isArchiveURL(source string) bool {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		return false
+	}
+	path := strings.SplitN(source, "?", 2)[0]
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// isGitSource reports whether source names a git repository.
+func isGitSource(source string) bool {
+	for _, prefix := range []string{"git+", "git@", "git://", "ssh://", "http://", "https://"} {
+		if strings.HasPrefix(source, prefix) {
+			return true
+		}
+	}
+	return strings.HasSuffix(source, ".git")
+}
+
+// cloneRepo clones the latest commit of the git repository url into dir.
+func cloneRepo(ctx context.Context, url, dir string) error {
+	cmd := exec.CommandContext(ctx, "git", "clone", "--quiet", "--depth", "1", "--", url, dir)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("git clone: %s", msg)
+	}
+	return nil
+}
+
+// downloadArchive downloads the .tar.gz archive at url and extracts it into dir.
+func downloadArchive(ctx context.Context, url, dir string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return extractTarGz(resp.Body, dir)
+}
+
+// extractTarGz extracts the regular files and directories of the gzipped tar
+// stream r into dir, rejecting entries that would land outside of it.
+func extractTarGz(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+	root := filepath.Clean(dir)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(root, filepath.FromSlash(hdr.Name))
+		if path == root {
+			continue
+		}
+		if !strings.HasPrefix(path, root+string(os.PathSeparator)) {
+			return fmt.Errorf("archive entry %s is outside the pack", hdr.Name)
+		}
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, 0755); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				return err
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(path, content, 0644); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// archiveRoot returns dir, or the only directory in it when an archive was
+// packed with a single top-level directory, as repository snapshots are.
+func archiveRoot(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) != 1 || !entries[0].IsDir() {
+		return dir
+	}
+	return filepath.Join(dir, entries[0].Name())
+}
@@ -0,0 +1,155 @@
+package templates_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func packConfig(dir string) templates.TemplateConfig {
+	return templates.TemplateConfig{
+		TemplateDir:       dir,
+		TemplateExtension: ".md",
+		FilePermissions:   0644,
+		Logger:            testutil.NewDummyLogger(),
+		FS:                fs.NewOSFileSystem(),
+	}
+}
+
+func TestInstallPack_Directory(t *testing.T) {
+	pack := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(pack, "templates"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pack, "README.md"), []byte("# GTD pack"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pack, "templates", "day.md"), []byte("# {{ .Title }}\n\n## Next actions\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(pack, "templates", "notes.txt"), []byte("not a template"), 0644))
+
+	target := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(target, "day.md"), []byte("old day"), 0644))
+	opts := templates.InstallOptions{TargetDir: target, Reader: &testutil.DummyInputReader{Response: "y"}}
+	require.NoError(t, templates.InstallPack(context.Background(), packConfig(target), opts, pack))
+
+	content, err := os.ReadFile(filepath.Join(target, "day.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# {{ .Title }}\n\n## Next actions\n", string(content))
+	backup, err := os.ReadFile(filepath.Join(target, "day.md"+templates.BackupExtension))
+	require.NoError(t, err)
+	assert.Equal(t, "old day", string(backup))
+	assert.NoFileExists(t, filepath.Join(target, "README.md"), "only the templates directory is installed")
+	assert.NoFileExists(t, filepath.Join(target, "notes.txt"))
+}
+
+func TestInstallPack_Invalid(t *testing.T) {
+	target := t.TempDir()
+	opts := templates.InstallOptions{TargetDir: target, Force: true}
+
+	empty := t.TempDir()
+	err := templates.InstallPack(context.Background(), packConfig(target), opts, empty)
+	assert.ErrorContains(t, err, "no templates found")
+
+	broken := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(broken, "good.md"), []byte("# {{ .Title }}"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(broken, "bad.md"), []byte("# {{ .Title "), 0644))
+	err = templates.InstallPack(context.Background(), packConfig(target), opts, broken)
+	assert.ErrorContains(t, err, "template bad.md")
+	assert.NoFileExists(t, filepath.Join(target, "good.md"), "nothing is installed from an invalid pack")
+
+	err = templates.InstallPack(context.Background(), packConfig(target), opts, filepath.Join(empty, "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestInstallPack_Archive(t *testing.T) {
+	archive := tarGz(t, map[string]string{
+		"exo-gtd-main/templates/week.md": "# Week {{ .Title }}\n",
+		"exo-gtd-main/LICENSE":           "MIT",
+	})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gtd.tar.gz" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	target := t.TempDir()
+	opts := templates.InstallOptions{TargetDir: target, Force: true}
+	require.NoError(t, templates.InstallPack(context.Background(), packConfig(target), opts, srv.URL+"/gtd.tar.gz"))
+	content, err := os.ReadFile(filepath.Join(target, "week.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Week {{ .Title }}\n", string(content))
+
+	err = templates.InstallPack(context.Background(), packConfig(target), opts, srv.URL+"/missing.tgz")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestFetchPack_ArchiveOutsidePack(t *testing.T) {
+	archive := tarGz(t, map[string]string{"../escape.md": "nope"})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+
+	_, cleanup, err := templates.FetchPack(context.Background(), srv.URL+"/evil.tar.gz")
+	defer cleanup()
+	assert.ErrorContains(t, err, "outside the pack")
+}
+
+func TestFetchPack_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(repo, "zettel.md"), []byte("# {{ .Title }}\n"), 0644))
+	for _, args := range [][]string{
+		{"init", "--quiet"},
+		{"add", "zettel.md"},
+		{"-c", "user.name=exo", "-c", "user.email=exo@example.com", "commit", "--quiet", "-m", "Add zettel template"},
+	} {
+		out, err := exec.Command("git", append([]string{"-C", repo}, args...)...).CombinedOutput()
+		require.NoError(t, err, string(out))
+	}
+
+	dir, cleanup, err := templates.FetchPack(context.Background(), "git+file://"+repo)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "zettel.md"))
+	cleanup()
+	assert.NoDirExists(t, dir)
+}
+
+func TestFetchPack_GitOption(t *testing.T) {
+	for _, source := range []string{"--upload-pack=touch pwned x.git", "git+-u=touch pwned"} {
+		_, cleanup, err := templates.FetchPack(context.Background(), source)
+		cleanup()
+		assert.True(t, exoerrors.Is(err, exoerrors.Validation), "%s: got %v", source, err)
+	}
+}
+
+// tarGz returns a gzipped tar archive holding files, keyed by path.
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
@@ -0,0 +1,42 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// DiffDefault returns the unified diff from the built-in default template name
+// to content, the template resolved from src, or an empty string when they are
+// the same.
+func DiffDefault(name string, src TemplateSource, content string) (string, error) {
+	def, err := LoadDefaultTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(def),
+		B:        difflib.SplitLines(content),
+		FromFile: DefaultTemplateBaseDir + "/" + name + ".md",
+		ToFile:   src.Path,
+		Context:  3,
+	})
+}
+
+// ResetTemplate restores the template file at path to the built-in default
+// template name, keeping its owner, group and mode. The replaced template is
+// backed up first, and the backup path is returned.
+func ResetTemplate(name, path string) (string, error) {
+	def, err := LoadDefaultTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	backup, err := copyBackup(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create backup for %s: %w", path, err)
+	}
+	if err := writeExisting(path, []byte(def)); err != nil {
+		return "", fmt.Errorf("failed to write template %s: %w", name, err)
+	}
+	return backup, nil
+}
@@ -0,0 +1,54 @@
+package templates_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffDefault(t *testing.T) {
+	def, err := templates.LoadDefaultTemplate("idea")
+	require.NoError(t, err)
+	src := templates.TemplateSource{Origin: templates.OriginCustom, Path: "/vault/templates/idea.md"}
+
+	diff, err := templates.DiffDefault("idea", src, def)
+	require.NoError(t, err)
+	assert.Empty(t, diff)
+
+	diff, err = templates.DiffDefault("idea", src, def+"## Next steps\n")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(diff, "--- default/idea.md\n+++ /vault/templates/idea.md\n@@ "), diff)
+	assert.Contains(t, diff, "\n+## Next steps\n")
+
+	_, err = templates.DiffDefault("missing", src, "")
+	assert.Error(t, err)
+}
+
+func TestResetTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "idea.md")
+	require.NoError(t, os.WriteFile(path, []byte("my idea template"), 0600))
+
+	backup, err := templates.ResetTemplate("idea", path)
+	require.NoError(t, err)
+	assert.Equal(t, path+templates.BackupExtension, backup)
+
+	def, err := templates.LoadDefaultTemplate("idea")
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, def, string(content))
+	saved, err := os.ReadFile(backup)
+	require.NoError(t, err)
+	assert.Equal(t, "my idea template", string(saved))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), "the mode of the template is kept")
+
+	_, err = templates.ResetTemplate("idea", filepath.Join(t.TempDir(), "missing.md"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,338 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+)
+
+// ScaffoldDir is the directory of a template directory, or of a template pack,
+// holding the scaffolds: templates of a set of files and directories, such as
+// a project with README.md, tasks.md and assets/, each kept in a directory
+// named after the scaffold.
+const ScaffoldDir = "scaffolds"
+
+// ScaffoldFile is a file or directory of a scaffold.
+type ScaffoldFile struct {
+	// Path is the slash-separated path of the file within the scaffold.
+	Path string
+	// Dir marks directories, which are created even when empty.
+	Dir bool
+	// Template marks the files with the template extension, executed as
+	// templates; other files, such as images, are copied as they are.
+	Template bool
+	Content  []byte
+}
+
+// ScaffoldVars returns the variables declared by the templates of files, each
+// once, as the first template declaring it does.
+func ScaffoldVars(files []ScaffoldFile) ([]Var, error) {
+	var vars []Var
+	seen := make(map[string]bool)
+	for _, f := range files {
+		if !f.Template {
+			continue
+		}
+		declared, err := ParseVars(string(f.Content))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Path, err)
+		}
+		for _, v := range declared {
+			if !seen[v.Name] {
+				seen[v.Name] = true
+				vars = append(vars, v)
+			}
+		}
+	}
+	return vars, nil
+}
+
+// ResolveScaffold returns the first of the custom scaffold and the profile
+// scaffold named name, with its files in lexical order.
+func (tm *defaultTemplateManager) ResolveScaffold(name string) (TemplateSource, []ScaffoldFile, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return TemplateSource{}, nil, exoerrors.New(exoerrors.Usage, "invalid scaffold name %q", name)
+	}
+	for _, dir := range []struct {
+		origin Origin
+		path   string
+	}{
+		{OriginCustom, tm.config.TemplateDir},
+		{OriginProfile, tm.config.ProfileDir},
+	} {
+		if dir.path == "" {
+			continue
+		}
+		root := filepath.Join(dir.path, ScaffoldDir, name)
+		if _, err := tm.config.FS.ReadDir(root); err != nil {
+			if errors.Is(err, iofs.ErrNotExist) {
+				continue
+			}
+			return TemplateSource{}, nil, err
+		}
+		var files []ScaffoldFile
+		if err := tm.readScaffold(root, "", &files); err != nil {
+			return TemplateSource{}, nil, fmt.Errorf("failed to read scaffold %s: %w", name, err)
+		}
+		return TemplateSource{Origin: dir.origin, Path: root}, files, nil
+	}
+	return TemplateSource{}, nil, exoerrors.New(exoerrors.NotFound, "no scaffold %s: %w", name, iofs.ErrNotExist)
+}
+
+// readScaffold appends the files under the directory rel of the scaffold at
+// root to files.
+func (tm *defaultTemplateManager) readScaffold(root, rel string, files *[]ScaffoldFile) error {
+	entries, err := tm.config.FS.ReadDir(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		p := path.Join(rel, entry.Name())
+		switch {
+		case entry.IsDir():
+			*files = append(*files, ScaffoldFile{Path: p, Dir: true})
+			if err := tm.readScaffold(root, p, files); err != nil {
+				return err
+			}
+		case entry.Type().IsRegular():
+			content, err := tm.config.FS.ReadFile(filepath.Join(root, filepath.FromSlash(p)))
+			if err != nil {
+				return err
+			}
+			*files = append(*files, ScaffoldFile{
+				Path:     p,
+				Template: path.Ext(p) == tm.config.TemplateExtension,
+				Content:  content,
+			})
+		}
+	}
+	return nil
+}
+
+// ProcessScaffold resolves a scaffold and executes its templates, and the
+// template actions in the paths of its files, such as "{{.Title}}.md", with
+// data. It stops once ctx is done, like ProcessTemplateWithContext.
+func (tm *defaultTemplateManager) ProcessScaffold(ctx context.Context, name string, data interface{}) ([]ScaffoldFile, error) {
+	_, files, err := tm.ResolveScaffold(name)
+	if err != nil {
+		return nil, err
+	}
+	processed := make([]ScaffoldFile, 0, len(files))
+	for _, f := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("failed to process scaffold %s: %w", name, err)
+		}
+		p, err := tm.execute(ctx, name+"/"+f.Path, f.Path, data)
+		if err != nil {
+			return nil, err
+		}
+		p = path.Clean(strings.TrimSpace(p))
+		if p == "." || path.IsAbs(p) || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, exoerrors.New(exoerrors.Validation, "scaffold %s: path %q becomes %q, outside the scaffold", name, f.Path, p)
+		}
+		f.Path = p
+		if f.Template {
+			content, err := tm.execute(ctx, name+"/"+f.Path, string(f.Content), data)
+			if err != nil {
+				return nil, err
+			}
+			f.Content = []byte(content)
+		}
+		processed = append(processed, f)
+	}
+	return processed, nil
+}
+
+// execute executes the template text named name with data.
+func (tm *defaultTemplateManager) execute(ctx context.Context, name, text string, data interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(tm.config.Funcs).Parse(text)
+	if err != nil {
+		return "", exoerrors.New(exoerrors.Validation, "failed to parse template %s: %w", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(contextWriter{ctx: ctx, w: &buf}, data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("failed to execute template %s: %w", name, ctxErr)
+		}
+		return "", exoerrors.New(exoerrors.Validation, "failed to execute template %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// ListScaffolds returns the names of the scaffolds of the custom and profile
+// directories, sorted.
+func (tm *defaultTemplateManager) ListScaffolds() ([]string, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, dir := range []string{tm.config.TemplateDir, tm.config.ProfileDir} {
+		if dir == "" {
+			continue
+		}
+		entries, err := tm.config.FS.ReadDir(filepath.Join(dir, ScaffoldDir))
+		if err != nil {
+			if errors.Is(err, iofs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read scaffold directory: %w", err)
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			// Backups of replaced scaffolds are not scaffolds.
+			if entry.IsDir() && !strings.Contains(name, BackupExtension) && !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// WriteScaffold writes the files of a processed scaffold under dir through
+// fsys. Nothing is written when one of them exists, which yields a conflict.
+func WriteScaffold(fsys fs.FileSystem, dir string, files []ScaffoldFile) error {
+	for _, f := range files {
+		if target := filepath.Join(dir, filepath.FromSlash(f.Path)); !f.Dir && fsys.FileExists(target) {
+			return exoerrors.New(exoerrors.Conflict, "%s already exists", target)
+		}
+	}
+	for _, f := range files {
+		target := filepath.Join(dir, filepath.FromSlash(f.Path))
+		if f.Dir {
+			// EnsureDirectoryExists creates the parent of a path: that of a
+			// file in the directory.
+			if err := fsys.EnsureDirectoryExists(filepath.Join(target, "_")); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			continue
+		}
+		if err := fsys.EnsureDirectoryExists(target); err != nil {
+			return fmt.Errorf("failed to create directory of %s: %w", target, err)
+		}
+		if err := fsys.WriteFile(target, f.Content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+	}
+	return nil
+}
+
+// installScaffolds copies the scaffolds of the directory src, a ScaffoldDir of
+// a template pack, to the ScaffoldDir of the template directory, backing up or
+// prompting for the scaffolds it would replace as InstallDefaultTemplates
+// does for templates.
+func installScaffolds(cfg TemplateConfig, opts InstallOptions, src string) error {
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to list scaffolds: %w", err)
+	}
+	filePerms, dirPerms := cfg.FilePermissions, cfg.DirPermissions
+	if filePerms == 0 {
+		filePerms = 0644
+	}
+	if dirPerms == 0 {
+		dirPerms = defaultDirPerms
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		name := entry.Name()
+		dest := filepath.Join(opts.TargetDir, ScaffoldDir, name)
+		if _, err := os.Stat(dest); err == nil {
+			if !opts.Force {
+				if opts.Reader == nil {
+					return fmt.Errorf("scaffold %s exists; set Force to true to overwrite", name)
+				}
+				fmt.Printf("Scaffold %s exists. Overwrite? [y/n]: ", name)
+				resp, err := opts.Reader.ReadResponse()
+				if err != nil {
+					return fmt.Errorf("failed to read user response: %w", err)
+				}
+				if strings.ToLower(strings.TrimSpace(resp)) != "y" {
+					continue
+				}
+			}
+			if err := CreateBackup(dest); err != nil {
+				return fmt.Errorf("failed to create backup for %s: %w", dest, err)
+			}
+		}
+		if err := copyTree(filepath.Join(src, name), dest, filePerms, dirPerms); err != nil {
+			return fmt.Errorf("failed to install scaffold %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// copyTree copies the directory src to dest, files as they are.
+func copyTree(src, dest string, filePerms, dirPerms os.FileMode) error {
+	return filepath.WalkDir(src, func(p string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return ensureDir(target, dirPerms)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		return writeNew(target, content, TemplateMode(filePerms, content))
+	})
+}
+
+// validateScaffolds checks that the templates of the scaffolds of the
+// directory src parse, with funcs available to them, and reports whether
+// there are any.
+func validateScaffolds(src, ext string, funcs template.FuncMap) (bool, error) {
+	found := false
+	err := filepath.WalkDir(src, func(p string, d iofs.DirEntry, err error) error {
+		if err != nil {
+			if p == src && errors.Is(err, iofs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if p == src {
+			return nil
+		}
+		found = true
+		rel, _ := filepath.Rel(src, p)
+		if _, err := template.New(rel).Funcs(funcs).Parse(filepath.ToSlash(rel)); err != nil {
+			return fmt.Errorf("scaffold path %s: %w", rel, err)
+		}
+		if d.IsDir() || filepath.Ext(p) != ext {
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if _, err := template.New(rel).Funcs(funcs).Parse(string(content)); err != nil {
+			return fmt.Errorf("scaffold %s: %w", filepath.ToSlash(rel), err)
+		}
+		return nil
+	})
+	return found, err
+}
@@ -0,0 +1,97 @@
+package templates_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessScaffold(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, templates.ScaffoldDir, "project")
+	logo := []byte{0x89, 'P', 'N', 'G', 0, '{', '{'}
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "assets"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "drafts"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte("{{/*\nvars:\n  - name: owner\n*/ -}}\n# {{ .Title }} by {{ .owner }}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "{{ .Title }} tasks.md"), []byte("- [ ] Start {{ .Title }}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "assets", "logo.png"), logo, 0644))
+
+	tm, err := templates.NewTemplateManager(packConfig(dir))
+	require.NoError(t, err)
+	names, err := tm.ListScaffolds()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"project"}, names)
+
+	src, raw, err := tm.ResolveScaffold("project")
+	require.NoError(t, err)
+	assert.Equal(t, templates.TemplateSource{Origin: templates.OriginCustom, Path: root}, src)
+	vars, err := templates.ScaffoldVars(raw)
+	require.NoError(t, err)
+	require.Len(t, vars, 1)
+	assert.Equal(t, "owner", vars[0].Name)
+
+	files, err := tm.ProcessScaffold(context.Background(), "project", map[string]interface{}{"Title": "Shed", "owner": "Ann"})
+	require.NoError(t, err)
+	out := filepath.Join(t.TempDir(), "Shed")
+	require.NoError(t, templates.WriteScaffold(fs.NewOSFileSystem(), out, files))
+	read := func(name string) string {
+		content, err := os.ReadFile(filepath.Join(out, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+	assert.Equal(t, "# Shed by Ann\n", read("README.md"))
+	assert.Equal(t, "- [ ] Start Shed\n", read("Shed tasks.md"))
+	assert.Equal(t, string(logo), read(filepath.Join("assets", "logo.png")), "other files are copied as they are")
+	assert.DirExists(t, filepath.Join(out, "drafts"))
+
+	err = templates.WriteScaffold(fs.NewOSFileSystem(), out, files)
+	assert.True(t, exoerrors.Is(err, exoerrors.Conflict), "existing files are not overwritten")
+
+	_, _, err = tm.ResolveScaffold("missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestProcessScaffold_PathOutside(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, templates.ScaffoldDir, "bad")
+	require.NoError(t, os.MkdirAll(root, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "{{ .Up }}"), []byte("x"), 0644))
+	tm, err := templates.NewTemplateManager(packConfig(dir))
+	require.NoError(t, err)
+	_, err = tm.ProcessScaffold(context.Background(), "bad", map[string]interface{}{"Up": ".."})
+	assert.ErrorContains(t, err, "outside the scaffold")
+}
+
+func TestInstallPack_Scaffolds(t *testing.T) {
+	pack := t.TempDir()
+	scaffold := filepath.Join(pack, templates.ScaffoldDir, "project")
+	require.NoError(t, os.MkdirAll(filepath.Join(scaffold, "assets"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(scaffold, "README.md"), []byte("# {{ .Title }}\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(scaffold, "assets", "logo.png"), []byte{0x89, 'P', 'N', 'G'}, 0644))
+
+	target := t.TempDir()
+	opts := templates.InstallOptions{TargetDir: target, Force: true}
+	require.NoError(t, templates.InstallPack(context.Background(), packConfig(target), opts, pack), "packs of scaffolds alone need no templates")
+	assert.FileExists(t, filepath.Join(target, templates.ScaffoldDir, "project", "README.md"))
+	assert.FileExists(t, filepath.Join(target, templates.ScaffoldDir, "project", "assets", "logo.png"))
+
+	// Reinstalling backs the scaffold up.
+	require.NoError(t, templates.InstallPack(context.Background(), packConfig(target), opts, pack))
+	assert.DirExists(t, filepath.Join(target, templates.ScaffoldDir, "project"+templates.BackupExtension))
+	tm, err := templates.NewTemplateManager(packConfig(target))
+	require.NoError(t, err)
+	names, err := tm.ListScaffolds()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"project"}, names)
+
+	require.NoError(t, os.WriteFile(filepath.Join(scaffold, "broken.md"), []byte("{{ .Title "), 0644))
+	err = templates.InstallPack(context.Background(), packConfig(t.TempDir()), opts, pack)
+	assert.ErrorContains(t, err, "broken.md")
+}
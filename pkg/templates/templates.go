@@ -2,32 +2,78 @@ package templates
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 	"strings"
 	"text/template"
 
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
 	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/a-kostevski/exo/pkg/logger"
 )
 
 // TemplateManager defines the interface for processing templates.
 type TemplateManager interface {
-	// ProcessTemplate loads a template from the custom directory, parses it,
-	// executes it with the given data, and returns the resulting string.
+	// ProcessTemplate resolves a template, parses it, executes it with the
+	// given data, and returns the resulting string.
 	ProcessTemplate(name string, data interface{}) (string, error)
+	// ProcessTemplateWithContext is ProcessTemplate stopping once ctx is done,
+	// before or after reading the template and at the next output of its
+	// execution, with an error wrapping ctx.Err().
+	ProcessTemplateWithContext(ctx context.Context, name string, data interface{}) (string, error)
 	// ListTemplates returns the names (without extension) of templates available in the custom directory.
 	ListTemplates() ([]string, error)
+	// Resolve looks a template up in the custom directory, then the profile
+	// directory, then the built-in defaults, and returns where it was found
+	// with its content. A template found nowhere yields an error wrapping
+	// fs.ErrNotExist.
+	Resolve(name string) (TemplateSource, string, error)
+	// ProcessScaffold is ProcessTemplateWithContext for a scaffold, a
+	// directory of templates and other files instantiated as a set: it
+	// resolves the scaffold and returns its files, templates executed with
+	// the given data.
+	ProcessScaffold(ctx context.Context, name string, data interface{}) ([]ScaffoldFile, error)
+	// ResolveScaffold looks a scaffold up in the ScaffoldDir of the custom
+	// directory, then of the profile directory, and returns where it was
+	// found with its files as they are. A scaffold found nowhere yields an
+	// error wrapping fs.ErrNotExist.
+	ResolveScaffold(name string) (TemplateSource, []ScaffoldFile, error)
+	// ListScaffolds returns the names of the scaffolds available.
+	ListScaffolds() ([]string, error)
+}
+
+// Origin names the place in the resolution chain a template comes from.
+type Origin string
+
+// The places templates are resolved from, in order of precedence.
+const (
+	OriginCustom  Origin = "custom"
+	OriginProfile Origin = "profile"
+	OriginBuiltIn Origin = "built-in"
+)
+
+// TemplateSource describes where a template was resolved from.
+type TemplateSource struct {
+	Origin Origin
+	// Path is the template file, or its path within the built-in templates.
+	Path string
 }
 
 // TemplateConfig holds configuration for template processing.
 type TemplateConfig struct {
-	TemplateDir       string        // Custom directory from which to load templates.
-	TemplateExtension string        // e.g. ".md"
-	FilePermissions   os.FileMode   // For writing files.
-	Logger            logger.Logger // Logger to use.
-	FS                fs.FileSystem // Abstract file system for file operations.
+	TemplateDir       string           // Custom directory from which to load templates.
+	ProfileDir        string           // Directory searched for templates missing from TemplateDir, if set.
+	TemplateExtension string           // e.g. ".md"
+	FilePermissions   os.FileMode      // For writing files.
+	DirPermissions    os.FileMode      // For creating directories.
+	Funcs             template.FuncMap // Extra functions available to templates, e.g. from plugins.
+	Logger            logger.Logger    // Logger to use.
+	FS                fs.FileSystem    // Abstract file system for file operations.
 }
 
 // defaultTemplateManager implements TemplateManager.
@@ -46,6 +92,9 @@ func NewTemplateManager(cfg TemplateConfig) (TemplateManager, error) {
 	if cfg.FilePermissions == 0 {
 		cfg.FilePermissions = 0644
 	}
+	if cfg.DirPermissions == 0 {
+		cfg.DirPermissions = defaultDirPerms
+	}
 	if cfg.Logger == nil {
 		return nil, fmt.Errorf("logger is required")
 	}
@@ -55,30 +104,89 @@ func NewTemplateManager(cfg TemplateConfig) (TemplateManager, error) {
 	return &defaultTemplateManager{config: cfg}, nil
 }
 
-// ProcessTemplate loads and executes a template from the custom directory.
+// ProcessTemplate resolves and executes a template.
 func (tm *defaultTemplateManager) ProcessTemplate(name string, data interface{}) (string, error) {
-	path := filepath.Join(tm.config.TemplateDir, name+tm.config.TemplateExtension)
-	content, err := tm.config.FS.ReadFile(path)
+	return tm.ProcessTemplateWithContext(context.Background(), name, data)
+}
+
+// ProcessTemplateWithContext resolves and executes a template, checking ctx
+// around reading it and on every write of its output.
+func (tm *defaultTemplateManager) ProcessTemplateWithContext(ctx context.Context, name string, data interface{}) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	_, content, err := tm.Resolve(name)
 	if err != nil {
 		return "", fmt.Errorf("failed to read template %s: %w", name, err)
 	}
-	tmpl, err := template.New(name).Parse(string(content))
+	if err := ctx.Err(); err != nil {
+		return "", fmt.Errorf("failed to read template %s: %w", name, err)
+	}
+	tmpl, err := template.New(name).Funcs(tm.config.Funcs).Parse(content)
 	if err != nil {
 		tm.config.Logger.Error("failed to parse template",
 			logger.Field{Key: "name", Value: name},
 			logger.Field{Key: "error", Value: err})
-		return "", fmt.Errorf("failed to parse template: %w", err)
+		return "", exoerrors.New(exoerrors.Validation, "failed to parse template: %w", err)
 	}
 	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
+	if err := tmpl.Execute(contextWriter{ctx: ctx, w: &buf}, data); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("failed to execute template: %w", ctxErr)
+		}
 		tm.config.Logger.Error("failed to execute template",
 			logger.Field{Key: "name", Value: name},
 			logger.Field{Key: "error", Value: err})
-		return "", fmt.Errorf("failed to execute template: %w", err)
+		return "", exoerrors.New(exoerrors.Validation, "failed to execute template: %w", err)
 	}
 	return buf.String(), nil
 }
 
+// contextWriter writes to w until ctx is done, failing every write after that
+// so that the execution of a template stops at its next output.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (c contextWriter) Write(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.w.Write(p)
+}
+
+// Resolve returns the first of the custom template, the profile template and
+// the built-in default named name, with its content.
+func (tm *defaultTemplateManager) Resolve(name string) (TemplateSource, string, error) {
+	file := name + tm.config.TemplateExtension
+	for _, dir := range []struct {
+		origin Origin
+		path   string
+	}{
+		{OriginCustom, tm.config.TemplateDir},
+		{OriginProfile, tm.config.ProfileDir},
+	} {
+		if dir.path == "" {
+			continue
+		}
+		path := filepath.Join(dir.path, file)
+		content, err := tm.config.FS.ReadFile(path)
+		if err == nil {
+			return TemplateSource{Origin: dir.origin, Path: path}, string(content), nil
+		}
+		if !errors.Is(err, iofs.ErrNotExist) {
+			return TemplateSource{}, "", err
+		}
+	}
+	path := DefaultTemplateBaseDir + "/" + file
+	content, err := DefaultTemplatesFS.ReadFile(path)
+	if err != nil {
+		return TemplateSource{}, "", exoerrors.New(exoerrors.NotFound, "no template %s: %w", name, iofs.ErrNotExist)
+	}
+	return TemplateSource{Origin: OriginBuiltIn, Path: path}, string(content), nil
+}
+
 // ListTemplates lists the names (without extension) of templates in the custom directory.
 func (tm *defaultTemplateManager) ListTemplates() ([]string, error) {
 	entries, err := tm.config.FS.ReadDir(tm.config.TemplateDir)
@@ -97,3 +205,31 @@ func (tm *defaultTemplateManager) ListTemplates() ([]string, error) {
 	}
 	return names, nil
 }
+
+// ProcessDefaultTemplate executes the built-in default template name (without
+// extension) with data, regardless of any custom or profile template.
+func ProcessDefaultTemplate(name string, data interface{}) (string, error) {
+	content, err := LoadDefaultTemplate(name)
+	if err != nil {
+		return "", err
+	}
+	tmpl, err := template.New(name).Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute template: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// LoadDefaultTemplate returns the content of the built-in default template name
+// (without extension).
+func LoadDefaultTemplate(name string) (string, error) {
+	content, err := DefaultTemplatesFS.ReadFile(DefaultTemplateBaseDir + "/" + name + ".md")
+	if err != nil {
+		return "", exoerrors.New(exoerrors.NotFound, "no default template %s: %w", name, err)
+	}
+	return string(content), nil
+}
@@ -1,9 +1,12 @@
 package templates_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"text/template"
 
 	"github.com/a-kostevski/exo/pkg/fs"
 	"github.com/a-kostevski/exo/pkg/templates"
@@ -37,6 +40,38 @@ func TestProcessTemplate(t *testing.T) {
 	assert.Equal(t, "Hello, Alice!", result)
 }
 
+func TestProcessTemplateWithContext(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "long.md"), []byte("start{{ stop }}{{ range .Items }}item {{ . }}\n{{ end }}"), 0644))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stops := 0
+	tm, err := templates.NewTemplateManager(templates.TemplateConfig{
+		TemplateDir: tmpDir,
+		Funcs: template.FuncMap{"stop": func() string {
+			stops++
+			cancel()
+			return ""
+		}},
+		Logger: testutil.NewDummyLogger(),
+		FS:     fs.NewOSFileSystem(),
+	})
+	require.NoError(t, err)
+
+	items := make([]int, 100000)
+	_, err = tm.ProcessTemplateWithContext(ctx, "long", map[string]interface{}{"Items": items})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, stops, "execution stops once the context is done")
+
+	_, err = tm.ProcessTemplateWithContext(ctx, "long", nil)
+	assert.ErrorIs(t, err, context.Canceled, "a done context keeps the template from being read")
+	assert.Equal(t, 1, stops)
+
+	out, err := tm.ProcessTemplateWithContext(context.Background(), "day", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, out)
+}
+
 func TestListTemplates(t *testing.T) {
 	tmpDir := t.TempDir()
 	// Create two template files.
@@ -68,3 +103,87 @@ func TestListTemplates(t *testing.T) {
 	assert.Contains(t, names, "second")
 	assert.Equal(t, 2, len(names))
 }
+
+func TestResolve(t *testing.T) {
+	customDir, profileDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(customDir, "day.md"), []byte("custom day"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(profileDir, "day.md"), []byte("profile day"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(profileDir, "week.md"), []byte("profile {{ .Title }}"), 0644))
+	tm, err := templates.NewTemplateManager(templates.TemplateConfig{
+		TemplateDir: customDir,
+		ProfileDir:  profileDir,
+		Logger:      testutil.NewDummyLogger(),
+		FS:          fs.NewOSFileSystem(),
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		origin  templates.Origin
+		path    string
+		content string
+	}{
+		{"day", templates.OriginCustom, filepath.Join(customDir, "day.md"), "custom day"},
+		{"week", templates.OriginProfile, filepath.Join(profileDir, "week.md"), "profile {{ .Title }}"},
+		{"zettel", templates.OriginBuiltIn, "default/zettel.md", ""},
+	}
+	for _, tt := range tests {
+		src, content, err := tm.Resolve(tt.name)
+		require.NoError(t, err, tt.name)
+		assert.Equal(t, templates.TemplateSource{Origin: tt.origin, Path: tt.path}, src, tt.name)
+		if tt.content != "" {
+			assert.Equal(t, tt.content, content, tt.name)
+		} else {
+			assert.NotEmpty(t, content, tt.name)
+		}
+	}
+
+	_, _, err = tm.Resolve("missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	out, err := tm.ProcessTemplate("week", map[string]interface{}{"Title": "2025-W06"})
+	require.NoError(t, err)
+	assert.Equal(t, "profile 2025-W06", out)
+	_, err = tm.ProcessTemplate("missing", nil)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestProcessDefaultTemplate(t *testing.T) {
+	out, err := templates.ProcessDefaultTemplate("clip", map[string]interface{}{
+		"Title":    "A page",
+		"URL":      "https://example.com",
+		"Captured": "2025-02-08",
+		"Archive":  "",
+		"Content":  "Body text",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "source: https://example.com\n")
+	assert.Contains(t, out, "# A page\n")
+	assert.NotContains(t, out, "Archived page")
+	assert.Contains(t, out, "\n\nBody text\n")
+
+	out, err = templates.ProcessDefaultTemplate("literature", map[string]interface{}{
+		"Key":       "knuth1984",
+		"Title":     `"Literate" Programming`,
+		"Authors":   []string{"Donald E. Knuth"},
+		"Year":      "1984",
+		"Date":      "2025-02-08",
+		"Reference": "Knuth, D. E. (1984). Literate Programming.",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, out, "citekey: knuth1984\n")
+	assert.Contains(t, out, "title: \"\\\"Literate\\\" Programming\"\n")
+	assert.Contains(t, out, "authors:\n  - \"Donald E. Knuth\"\n")
+	assert.Contains(t, out, "\nKnuth, D. E. (1984). Literate Programming.\n")
+
+	out, err = templates.ProcessDefaultTemplate("day", map[string]interface{}{
+		"Date":     "2025-02-08",
+		"Previous": "2025-02-07",
+		"Next":     "2025-02-09",
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out, "# 2025-02-08\n\n[[2025-02-07]] - [[2025-02-09]]\n"), out)
+
+	_, err = templates.ProcessDefaultTemplate("missing", nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,115 @@
+package templates
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// varsHeader matches the metadata header of a template: a template comment, so
+// that it produces no output, opening the template.
+var varsHeader = regexp.MustCompile(`^\{\{(?:- )?/\*([\s\S]*?)\*/(?: -)?\}\}`)
+
+// Var is a data key declared by a template.
+type Var struct {
+	Name string `yaml:"name"`
+	// Prompt is the question asked for the value interactively; it defaults to Name.
+	Prompt   string `yaml:"prompt,omitempty"`
+	Default  string `yaml:"default,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+}
+
+// ParseVars returns the variables declared in the metadata header of template
+// content, a leading comment holding YAML with a "vars" list:
+//
+//	{{/*
+//	vars:
+//	  - name: attendees
+//	    prompt: Who is attending?
+//	    required: true
+//	  - name: project
+//	    default: exo
+//	*/ -}}
+//
+// The "-" trims the newline after the header. Templates without a header declare
+// no variables.
+func ParseVars(content string) ([]Var, error) {
+	m := varsHeader.FindStringSubmatch(strings.TrimLeft(content, " \t\r\n"))
+	if m == nil {
+		return nil, nil
+	}
+	var meta struct {
+		Vars []Var `yaml:"vars"`
+	}
+	if err := yaml.Unmarshal([]byte(m[1]), &meta); err != nil {
+		// A plain comment, not a metadata header.
+		return nil, nil
+	}
+	for _, v := range meta.Vars {
+		if v.Name == "" {
+			return nil, fmt.Errorf("template variable without a name")
+		}
+	}
+	return meta.Vars, nil
+}
+
+// VarSources supplies the values of template variables. Values are taken from
+// Given, then from the environment variable EXO_VAR_<NAME> (upper-cased, with
+// other characters than letters and digits replaced by underscores) through
+// Env, then by asking through Ask, and finally from the default. Env and Ask
+// may be nil.
+type VarSources struct {
+	Given map[string]string
+	Env   func(key string) (string, bool)
+	// Ask asks for the value of v, returning "" to keep the default.
+	Ask func(v Var) (string, error)
+}
+
+// ResolveVars returns the values of vars from sources, together with every
+// given value whether declared or not. It fails when a required variable has no
+// value.
+func ResolveVars(vars []Var, sources VarSources) (map[string]string, error) {
+	values := make(map[string]string, len(vars)+len(sources.Given))
+	for k, v := range sources.Given {
+		values[k] = v
+	}
+	for _, v := range vars {
+		if _, ok := values[v.Name]; ok {
+			continue
+		}
+		value, ok := "", false
+		if sources.Env != nil {
+			value, ok = sources.Env(VarEnvName(v.Name))
+		}
+		if !ok && sources.Ask != nil {
+			answer, err := sources.Ask(v)
+			if err != nil {
+				return nil, err
+			}
+			value, ok = answer, answer != ""
+		}
+		if !ok {
+			value = v.Default
+		}
+		if value == "" && v.Required {
+			return nil, fmt.Errorf("template variable %q is required (set it with --var %s=... or %s)", v.Name, v.Name, VarEnvName(v.Name))
+		}
+		values[v.Name] = value
+	}
+	return values, nil
+}
+
+// VarEnvName returns the environment variable supplying the template variable name.
+func VarEnvName(name string) string {
+	return "EXO_VAR_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+}
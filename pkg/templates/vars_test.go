@@ -0,0 +1,78 @@
+package templates_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVars(t *testing.T) {
+	vars, err := templates.ParseVars("{{/*\nvars:\n  - name: attendees\n    prompt: Who?\n    required: true\n  - name: project\n    default: exo\n*/ -}}\n# {{.Title}}\n")
+	require.NoError(t, err)
+	assert.Equal(t, []templates.Var{
+		{Name: "attendees", Prompt: "Who?", Required: true},
+		{Name: "project", Default: "exo"},
+	}, vars)
+
+	for _, content := range []string{"# {{.Title}}\n", "{{/* just a note */}}\n# Title\n", "# Title {{/*\nvars:\n  - name: x\n*/}}"} {
+		vars, err := templates.ParseVars(content)
+		require.NoError(t, err)
+		assert.Empty(t, vars, content)
+	}
+
+	_, err = templates.ParseVars("{{/*\nvars:\n  - prompt: nameless\n*/}}")
+	assert.Error(t, err)
+}
+
+func TestResolveVars(t *testing.T) {
+	vars := []templates.Var{
+		{Name: "attendees", Required: true},
+		{Name: "project", Default: "exo"},
+		{Name: "room"},
+	}
+	env := map[string]string{"EXO_VAR_ATTENDEES": "Ann, Bob"}
+	lookup := func(key string) (string, bool) { v, ok := env[key]; return v, ok }
+
+	values, err := templates.ResolveVars(vars, templates.VarSources{
+		Given: map[string]string{"room": "B2", "extra": "yes"},
+		Env:   lookup,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"attendees": "Ann, Bob", "project": "exo", "room": "B2", "extra": "yes"}, values)
+
+	var asked []string
+	values, err = templates.ResolveVars(vars, templates.VarSources{
+		Ask: func(v templates.Var) (string, error) {
+			asked = append(asked, v.Name)
+			if v.Name == "attendees" {
+				return "Cleo", nil
+			}
+			return "", nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"attendees", "project", "room"}, asked)
+	assert.Equal(t, map[string]string{"attendees": "Cleo", "project": "exo", "room": ""}, values)
+
+	_, err = templates.ResolveVars(vars, templates.VarSources{})
+	assert.ErrorContains(t, err, `template variable "attendees" is required`)
+
+	_, err = templates.ResolveVars(vars, templates.VarSources{Ask: func(templates.Var) (string, error) { return "", errors.New("eof") }})
+	assert.Error(t, err)
+
+	assert.Equal(t, "EXO_VAR_DUE_DATE", templates.VarEnvName("due-date"))
+}
+
+func TestProcessDefaultTemplate_Meeting(t *testing.T) {
+	out, err := templates.ProcessDefaultTemplate("meeting", map[string]interface{}{
+		"Title":     "Weekly sync",
+		"Date":      "2025-02-08",
+		"attendees": "Ann, Bob",
+		"project":   "",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "---\ndate: 2025-02-08\nattendees: Ann, Bob\ntags: [meeting]\n---\n# Weekly sync\n\n## Agenda\n\n## Notes\n\n## Action items\n", out)
+}
@@ -1,6 +1,9 @@
 package testutil
 
 import (
+	"context"
+	"fmt"
+	iofs "io/fs"
 	"os"
 	"path/filepath"
 
@@ -32,7 +35,12 @@ func (dtm *DummyTemplateManager) ProcessTemplate(name string, data interface{})
 	return "Template: unknown", nil
 }
 
-func (dtm *DummyTemplateManager) ProcessTemplateWithContext(ctx interface{}, name string, data interface{}) (string, error) {
+// ProcessTemplateWithContext returns what ProcessTemplate does, or the error of
+// ctx once it is done.
+func (dtm *DummyTemplateManager) ProcessTemplateWithContext(ctx context.Context, name string, data interface{}) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
 	return dtm.ProcessTemplate(name, data)
 }
 
@@ -44,6 +52,26 @@ func (dtm *DummyTemplateManager) ListTemplates() ([]string, error) {
 	return []string{}, nil
 }
 
+// Resolve reports every template as an empty custom template.
+func (dtm *DummyTemplateManager) Resolve(name string) (templates.TemplateSource, string, error) {
+	return templates.TemplateSource{Origin: templates.OriginCustom, Path: name + ".md"}, "", nil
+}
+
+// ProcessScaffold reports every scaffold as missing.
+func (dtm *DummyTemplateManager) ProcessScaffold(ctx context.Context, name string, data interface{}) ([]templates.ScaffoldFile, error) {
+	_, _, err := dtm.ResolveScaffold(name)
+	return nil, err
+}
+
+// ResolveScaffold reports every scaffold as missing.
+func (dtm *DummyTemplateManager) ResolveScaffold(name string) (templates.TemplateSource, []templates.ScaffoldFile, error) {
+	return templates.TemplateSource{}, nil, fmt.Errorf("no scaffold %s: %w", name, iofs.ErrNotExist)
+}
+
+func (dtm *DummyTemplateManager) ListScaffolds() ([]string, error) {
+	return []string{}, nil
+}
+
 // InstallDefaultTemplates implements the required method from TemplateManager interface
 func (dtm *DummyTemplateManager) InstallDefaultTemplates(opts templates.InstallOptions) error {
 	return nil // For testing purposes, just return success
@@ -133,6 +161,9 @@ func NewDummyDeps(dataHome string) (config.Config, templates.TemplateManager, lo
 			ProjectsDir: filepath.Join(dataHome, "projects"),
 			InboxDir:    filepath.Join(dataHome, "0-inbox"),
 			IdeaDir:     filepath.Join(dataHome, "ideas"),
+			PluginDir:   filepath.Join(dataHome, "plugins"),
+			StateDir:    filepath.Join(dataHome, ".exo", "state"),
+			CacheDir:    filepath.Join(dataHome, ".exo", "cache"),
 		},
 	}
 	_ = os.MkdirAll(cfg.Dir.DataHome, 0755)
@@ -0,0 +1,470 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Pane is one of the three columns of the browser.
+type Pane int
+
+const (
+	PaneDirs Pane = iota
+	PaneNotes
+	PanePreview
+)
+
+// prompt is the kind of input the status line is reading.
+type prompt int
+
+const (
+	promptNone prompt = iota
+	promptSearch
+	promptTag
+	promptTitle
+	promptConfirm
+)
+
+// helpLine lists the keys of the browser.
+const helpLine = "tab pane  / search  t tag  esc clear  e open  n new  d trash  r reload  q quit"
+
+// Model is the bubbletea model of the browser: the notes under a root
+// directory, the directory and filters selected, and the focused pane. Side
+// effects are performed through Actions.
+type Model struct {
+	root    string
+	actions Actions
+	notes   []scan.Note
+	dirs    []string
+	shown   []scan.Note
+
+	focus  Pane
+	dir    int
+	cursor int
+	scroll int
+
+	query string
+	tag   string
+
+	prompt prompt
+	input  string
+	status string
+
+	width, height int
+}
+
+// NewModel returns a browser over notes, which live under root, performing
+// side effects with actions.
+func NewModel(root string, notes []scan.Note, actions Actions) *Model {
+	m := &Model{root: root, actions: actions, focus: PaneNotes}
+	m.SetNotes(notes)
+	return m
+}
+
+// SetNotes replaces the notes browsed, keeping the selected directory and note
+// when they still exist.
+func (m *Model) SetNotes(notes []scan.Note) {
+	dir, selected := m.Dir(), m.Selected()
+	m.notes = append([]scan.Note(nil), notes...)
+	_ = scan.Sort(m.notes, scan.SortByModified)
+
+	seen := map[string]bool{"": true}
+	m.dirs = []string{""}
+	for _, n := range m.notes {
+		for d := m.relDir(n.Path); d != "." && !seen[d]; d = filepath.ToSlash(filepath.Dir(d)) {
+			seen[d] = true
+			m.dirs = append(m.dirs, d)
+		}
+	}
+	sort.Strings(m.dirs)
+
+	m.dir = 0
+	for i, d := range m.dirs {
+		if d == dir {
+			m.dir = i
+		}
+	}
+	m.filter()
+	m.Select(selected)
+}
+
+// Select moves the cursor to the note at path, if it is shown.
+func (m *Model) Select(path string) {
+	for i, n := range m.shown {
+		if n.Path == path {
+			m.cursor, m.scroll = i, 0
+		}
+	}
+}
+
+// Selected returns the path of the note under the cursor, or "" when no note is
+// shown.
+func (m *Model) Selected() string {
+	if m.cursor < len(m.shown) {
+		return m.shown[m.cursor].Path
+	}
+	return ""
+}
+
+// Dir returns the selected directory relative to the root, or "" for all notes.
+func (m *Model) Dir() string {
+	if m.dir < len(m.dirs) {
+		return m.dirs[m.dir]
+	}
+	return ""
+}
+
+// Init implements tea.Model.
+func (m *Model) Init() tea.Cmd {
+	return nil
+}
+
+// Update implements tea.Model, handling key presses, terminal resizes and the
+// results of actions.
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		if msg.Type == tea.KeyRunes && len(msg.Runes) > 1 {
+			// Keys typed in quick succession arrive together.
+			var cmds []tea.Cmd
+			for _, r := range msg.Runes {
+				_, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}, Alt: msg.Alt})
+				cmds = append(cmds, cmd)
+			}
+			return m, tea.Sequence(cmds...)
+		}
+		m.status = ""
+		if m.prompt != promptNone {
+			return m, m.updatePrompt(msg)
+		}
+		return m, m.updateKey(msg.String())
+	case loadedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		m.SetNotes(msg.notes)
+		m.Select(msg.selected)
+	case editedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+		}
+		return m, m.load(msg.path)
+	case createdMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		return m, m.open(msg.path)
+	case trashedMsg:
+		if msg.err != nil {
+			m.status = msg.err.Error()
+			break
+		}
+		m.status = fmt.Sprintf("Moved %s to %s", filepath.Base(msg.path), msg.dest)
+		return m, m.load("")
+	}
+	return m, nil
+}
+
+// updateKey handles a key press outside of prompts.
+func (m *Model) updateKey(k string) tea.Cmd {
+	switch k {
+	case "q", "ctrl+c":
+		return tea.Quit
+	case "tab", "right", "l":
+		if m.focus < PanePreview {
+			m.focus++
+		}
+	case "shift+tab", "left", "h":
+		if m.focus > PaneDirs {
+			m.focus--
+		}
+	case "down", "j":
+		m.move(1)
+	case "up", "k":
+		m.move(-1)
+	case "pgdown":
+		m.move(10)
+	case "pgup":
+		m.move(-10)
+	case "enter", "e":
+		if m.focus == PaneDirs {
+			m.focus = PaneNotes
+		} else if path := m.Selected(); path != "" {
+			return m.open(path)
+		}
+	case "/":
+		m.prompt, m.input = promptSearch, m.query
+	case "t":
+		m.prompt, m.input = promptTag, m.tag
+	case "n":
+		m.prompt, m.input = promptTitle, ""
+	case "d":
+		if m.Selected() != "" {
+			m.prompt, m.input = promptConfirm, ""
+		}
+	case "esc":
+		m.query, m.tag = "", ""
+		m.filter()
+	case "r":
+		return m.load(m.Selected())
+	}
+	return nil
+}
+
+// updatePrompt handles a key press while the status line reads input. Searches
+// are applied as they are typed.
+func (m *Model) updatePrompt(k tea.KeyMsg) tea.Cmd {
+	if m.prompt == promptConfirm {
+		m.prompt = promptNone
+		if k.String() == "y" || k.String() == "Y" {
+			return m.trash(m.Selected())
+		}
+		return nil
+	}
+	switch k.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		if m.prompt == promptSearch {
+			m.query = ""
+			m.filter()
+		}
+		m.prompt = promptNone
+		return nil
+	case tea.KeyEnter:
+		p, input := m.prompt, strings.TrimSpace(m.input)
+		m.prompt = promptNone
+		switch p {
+		case promptTag:
+			m.tag = strings.TrimPrefix(input, "#")
+			m.filter()
+		case promptTitle:
+			if input != "" {
+				return m.create(m.Dir(), input)
+			}
+		case promptSearch:
+			m.focus = PaneNotes
+		}
+		return nil
+	case tea.KeyBackspace:
+		if m.input != "" {
+			_, size := utf8.DecodeLastRuneInString(m.input)
+			m.input = m.input[:len(m.input)-size]
+		}
+	case tea.KeyCtrlU:
+		m.input = ""
+	case tea.KeySpace:
+		m.input += " "
+	case tea.KeyRunes:
+		if k.Alt {
+			return nil
+		}
+		m.input += string(k.Runes)
+	default:
+		return nil
+	}
+	if m.prompt == promptSearch {
+		m.query = m.input
+		m.filter()
+	}
+	return nil
+}
+
+// move moves the selection of the focused pane by delta.
+func (m *Model) move(delta int) {
+	switch m.focus {
+	case PaneDirs:
+		m.dir = clamp(m.dir+delta, len(m.dirs))
+		m.filter()
+	case PaneNotes:
+		m.cursor, m.scroll = clamp(m.cursor+delta, len(m.shown)), 0
+	case PanePreview:
+		m.scroll = max(m.scroll+delta, 0)
+	}
+}
+
+// filter recomputes the notes shown from the directory, tag and search query,
+// resetting the cursor.
+func (m *Model) filter() {
+	dir := m.Dir()
+	query := strings.ToLower(m.query)
+	m.shown = m.shown[:0]
+	for _, n := range m.notes {
+		if d := m.relDir(n.Path); dir != "" && d != dir && !strings.HasPrefix(d, dir+"/") {
+			continue
+		}
+		if m.tag != "" && !n.HasTag(m.tag) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(n.Title), query) && !contentContains(n.Path, query) {
+			continue
+		}
+		m.shown = append(m.shown, n)
+	}
+	m.cursor, m.scroll = 0, 0
+}
+
+// contentContains reports whether the note at path contains the lower-case query.
+func contentContains(path, query string) bool {
+	content, err := os.ReadFile(path)
+	return err == nil && strings.Contains(strings.ToLower(string(content)), query)
+}
+
+// relDir returns the slash-separated directory of path relative to the root.
+func (m *Model) relDir(path string) string {
+	rel, err := filepath.Rel(m.root, filepath.Dir(path))
+	if err != nil {
+		return "."
+	}
+	return filepath.ToSlash(rel)
+}
+
+// View implements tea.Model, rendering the three panes, a status line and the
+// key help to fill the terminal.
+func (m *Model) View() string {
+	width, height := max(m.width, 40), max(m.height, 5)
+	dirWidth := width / 5
+	noteWidth := width * 2 / 5
+	previewWidth := width - dirWidth - noteWidth - 2
+	rows := height - 3
+
+	dirs := make([]string, len(m.dirs))
+	for i, d := range m.dirs {
+		if d == "" {
+			d = "All notes"
+		}
+		dirs[i] = d
+	}
+	notes := make([]string, len(m.shown))
+	for i, n := range m.shown {
+		notes[i] = n.Title
+	}
+
+	noteHeader := fmt.Sprintf("Notes (%d)", len(m.shown))
+	if m.tag != "" {
+		noteHeader += " #" + m.tag
+	}
+	if m.query != "" {
+		noteHeader += fmt.Sprintf(" %q", m.query)
+	}
+	columns := [][]string{
+		m.list(PaneDirs, "Directories", dirs, m.dir, dirWidth, rows),
+		m.list(PaneNotes, noteHeader, notes, m.cursor, noteWidth, rows),
+		m.preview(previewWidth, rows),
+	}
+
+	var b strings.Builder
+	for row := 0; row <= rows; row++ {
+		for i, col := range columns {
+			if i > 0 {
+				b.WriteString("│")
+			}
+			b.WriteString(col[row])
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(fit(m.statusLine(), width) + "\n")
+	b.WriteString(fit(helpLine, width))
+	return b.String()
+}
+
+// list renders a pane listing items with a header, scrolled to keep the
+// selected item visible.
+func (m *Model) list(p Pane, header string, items []string, selected, width, rows int) []string {
+	lines := []string{m.header(p, header, width)}
+	offset := 0
+	if selected >= rows {
+		offset = selected - rows + 1
+	}
+	for i := offset; i < offset+rows; i++ {
+		switch {
+		case i >= len(items):
+			lines = append(lines, fit("", width))
+		case i == selected && m.focus == p:
+			lines = append(lines, "\x1b[7m"+fit("> "+items[i], width)+"\x1b[0m")
+		case i == selected:
+			lines = append(lines, fit("> "+items[i], width))
+		default:
+			lines = append(lines, fit("  "+items[i], width))
+		}
+	}
+	return lines
+}
+
+// preview renders the content of the selected note.
+func (m *Model) preview(width, rows int) []string {
+	lines := []string{m.header(PanePreview, "Preview", width)}
+	var content []string
+	if path := m.Selected(); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			content = []string{err.Error()}
+		} else {
+			content = strings.Split(strings.ReplaceAll(string(data), "\t", "    "), "\n")
+		}
+	}
+	m.scroll = min(m.scroll, max(len(content)-1, 0))
+	for i := m.scroll; i < m.scroll+rows; i++ {
+		line := ""
+		if i < len(content) {
+			line = " " + content[i]
+		}
+		lines = append(lines, fit(line, width))
+	}
+	return lines
+}
+
+// header renders the title of a pane, highlighted when the pane is focused.
+func (m *Model) header(p Pane, title string, width int) string {
+	if m.focus == p {
+		return "\x1b[1;4m" + fit(title, width) + "\x1b[0m"
+	}
+	return fit(title, width)
+}
+
+// statusLine returns the prompt being typed or the last status message.
+func (m *Model) statusLine() string {
+	switch m.prompt {
+	case promptSearch:
+		return "Search: " + m.input + "_"
+	case promptTag:
+		return "Tag: #" + m.input + "_"
+	case promptTitle:
+		dir := m.Dir()
+		if dir == "" {
+			dir = "inbox"
+		}
+		return "New note in " + dir + ": " + m.input + "_"
+	case promptConfirm:
+		return fmt.Sprintf("Move %s to the trash? [y/N]", filepath.Base(m.Selected()))
+	}
+	return m.status
+}
+
+// fit pads or truncates s to exactly width runes.
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n <= width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	runes := []rune(s)
+	if width < 1 {
+		return ""
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+// clamp limits i to the indices of a list of length n.
+func clamp(i, n int) int {
+	return max(min(i, n-1), 0)
+}
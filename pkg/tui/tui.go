@@ -0,0 +1,102 @@
+package tui
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/term"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Actions performs the side effects requested from the browser.
+type Actions interface {
+	// Load returns the notes to browse.
+	Load() ([]scan.Note, error)
+	// Open edits the note at path; the browser releases the terminal meanwhile.
+	Open(path string) error
+	// Create creates a note titled title in dir, relative to the root, and
+	// returns its path.
+	Create(dir, title string) (string, error)
+	// Trash moves the note at path out of the way and returns where it went.
+	Trash(path string) (string, error)
+}
+
+// Run runs the browser m in the terminal until the user quits.
+func Run(m *Model, opts ...tea.ProgramOption) error {
+	_, err := tea.NewProgram(m, append([]tea.ProgramOption{tea.WithAltScreen()}, opts...)...).Run()
+	if errors.Is(err, tea.ErrProgramKilled) {
+		return nil
+	}
+	return err
+}
+
+// IsTerminal reports whether f is an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	return term.IsTerminal(f.Fd())
+}
+
+// loadedMsg carries the notes reloaded by Actions.Load and the note to select.
+type loadedMsg struct {
+	notes    []scan.Note
+	selected string
+	err      error
+}
+
+// editedMsg reports that the editor of the note at path exited.
+type editedMsg struct {
+	path string
+	err  error
+}
+
+// createdMsg reports the creation of the note at path.
+type createdMsg struct {
+	path string
+	err  error
+}
+
+// trashedMsg reports that the note at path was moved to dest.
+type trashedMsg struct {
+	path, dest string
+	err        error
+}
+
+// load reloads the notes, selecting the note at selected afterwards.
+func (m *Model) load(selected string) tea.Cmd {
+	return func() tea.Msg {
+		notes, err := m.actions.Load()
+		return loadedMsg{notes: notes, selected: selected, err: err}
+	}
+}
+
+// open edits the note at path with the terminal released.
+func (m *Model) open(path string) tea.Cmd {
+	return tea.Exec(execFunc(func() error { return m.actions.Open(path) }), func(err error) tea.Msg {
+		return editedMsg{path: path, err: err}
+	})
+}
+
+func (m *Model) create(dir, title string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := m.actions.Create(dir, title)
+		return createdMsg{path: path, err: err}
+	}
+}
+
+func (m *Model) trash(path string) tea.Cmd {
+	return func() tea.Msg {
+		dest, err := m.actions.Trash(path)
+		return trashedMsg{path: path, dest: dest, err: err}
+	}
+}
+
+// execFunc is a tea.ExecCommand running a function that uses the standard
+// streams itself, such as an editor launched by the file system.
+type execFunc func() error
+
+func (f execFunc) Run() error        { return f() }
+func (execFunc) SetStdin(io.Reader)  {}
+func (execFunc) SetStdout(io.Writer) {}
+func (execFunc) SetStderr(io.Writer) {}
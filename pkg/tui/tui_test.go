@@ -0,0 +1,184 @@
+package tui_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newVault writes notes to a temporary directory, the first one modified last,
+// and returns the directory and the scanned notes.
+func newVault(t *testing.T) (string, []scan.Note) {
+	t.Helper()
+	dir := t.TempDir()
+	files := []struct{ path, content string }{
+		{"0-inbox/Go channels.md", "# Go channels\n\nPipes between goroutines #go\n"},
+		{"0-inbox/Groceries.md", "# Groceries\n\nMilk and eggs\n"},
+		{"zettel/deep/Rust.md", "# Rust\n\nOwnership #lang\n"},
+	}
+	now := time.Now()
+	for i, f := range files {
+		path := filepath.Join(dir, filepath.FromSlash(f.path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(f.content), 0644))
+		mod := now.Add(-time.Duration(i) * time.Hour)
+		require.NoError(t, os.Chtimes(path, mod, mod))
+	}
+	notes, err := scan.Scan(dir)
+	require.NoError(t, err)
+	return dir, notes
+}
+
+type fakeActions struct {
+	dir    string
+	opened chan string
+}
+
+func (a *fakeActions) Load() ([]scan.Note, error) { return scan.Scan(a.dir) }
+func (a *fakeActions) Open(path string) error {
+	if a.opened != nil {
+		a.opened <- path
+	}
+	return nil
+}
+func (a *fakeActions) Create(dir, title string) (string, error) {
+	path := filepath.Join(a.dir, "0-inbox", title+".md")
+	return path, os.WriteFile(path, []byte("# "+title+"\n"), 0644)
+}
+func (a *fakeActions) Trash(path string) (string, error) {
+	return ".trash/" + filepath.Base(path), os.Remove(path)
+}
+
+func newModel(t *testing.T) (*tui.Model, string) {
+	dir, notes := newVault(t)
+	m := tui.NewModel(dir, notes, &fakeActions{dir: dir})
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 10})
+	return m, dir
+}
+
+// press sends key presses to m, named like tea.KeyMsg.String, and returns the
+// command of the last one.
+func press(m *tui.Model, keys ...string) tea.Cmd {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+		switch k {
+		case "enter":
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case "backspace":
+			msg = tea.KeyMsg{Type: tea.KeyBackspace}
+		}
+		_, cmd = m.Update(msg)
+	}
+	return cmd
+}
+
+// drive runs cmd and feeds the messages it produces back to m until no more
+// commands follow. Editors are not run.
+func drive(m *tui.Model, cmd tea.Cmd) {
+	for cmd != nil {
+		_, cmd = m.Update(cmd())
+	}
+}
+
+func TestModel_Navigation(t *testing.T) {
+	m, dir := newModel(t)
+
+	assert.Equal(t, filepath.Join(dir, "0-inbox", "Go channels.md"), m.Selected())
+	view := m.View()
+	assert.Contains(t, view, "All notes")
+	assert.Contains(t, view, "zettel/deep")
+	assert.Contains(t, view, "Notes (3)")
+	assert.Contains(t, view, "Pipes between goroutines")
+	assert.Len(t, strings.Split(view, "\n"), 10)
+
+	press(m, "j")
+	assert.Equal(t, filepath.Join(dir, "0-inbox", "Groceries.md"), m.Selected())
+
+	// Select the "zettel" directory: All notes, 0-inbox, zettel.
+	press(m, "h", "j", "j")
+	assert.Equal(t, "zettel", m.Dir())
+	assert.Equal(t, filepath.Join(dir, "zettel", "deep", "Rust.md"), m.Selected())
+
+	assert.Nil(t, press(m, "enter"))
+	assert.NotNil(t, press(m, "enter"), "opens the note")
+	assert.Equal(t, tea.QuitMsg{}, press(m, "q")())
+}
+
+func TestModel_Filters(t *testing.T) {
+	m, dir := newModel(t)
+
+	// Searches match content as it is typed.
+	press(m, "/", "e", "g", "g")
+	assert.Equal(t, filepath.Join(dir, "0-inbox", "Groceries.md"), m.Selected())
+	assert.Contains(t, m.View(), "Search: egg_")
+	press(m, "enter")
+	assert.Contains(t, m.View(), `Notes (1) "egg"`)
+
+	// Keys typed quickly arrive together.
+	press(m, "esc", "t", "#lang", "enter")
+	assert.Equal(t, filepath.Join(dir, "zettel", "deep", "Rust.md"), m.Selected())
+	assert.Contains(t, m.View(), "Notes (1) #lang")
+
+	press(m, "esc")
+	assert.Contains(t, m.View(), "Notes (3)")
+}
+
+func TestModel_NewAndTrash(t *testing.T) {
+	m, dir := newModel(t)
+
+	drive(m, press(m, "n", "I", "d", "e", "a", "backspace", "a", "enter"))
+	assert.FileExists(t, filepath.Join(dir, "0-inbox", "Idea.md"))
+	assert.Nil(t, press(m, "n", "esc"))
+
+	assert.Nil(t, press(m, "d", "n"))
+	drive(m, press(m, "d", "y"))
+	assert.NoFileExists(t, filepath.Join(dir, "0-inbox", "Go channels.md"))
+	assert.Contains(t, m.View(), "Moved Go channels.md to .trash/Go channels.md")
+	assert.Contains(t, m.View(), "Notes (3)")
+}
+
+func TestRun(t *testing.T) {
+	dir, notes := newVault(t)
+	actions := &fakeActions{dir: dir, opened: make(chan string, 1)}
+	in, keys, err := os.Pipe()
+	require.NoError(t, err)
+	defer in.Close()
+	defer keys.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- tui.Run(tui.NewModel(dir, notes, actions),
+			tea.WithInput(in), tea.WithOutput(io.Discard), tea.WithoutSignalHandler())
+	}()
+
+	_, err = keys.WriteString("nIdea\r")
+	require.NoError(t, err)
+	select {
+	case path := <-actions.opened:
+		assert.Equal(t, filepath.Join(dir, "0-inbox", "Idea.md"), path)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new note was not opened")
+	}
+
+	_, err = keys.WriteString("q")
+	require.NoError(t, err)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the browser did not quit")
+	}
+}
@@ -0,0 +1,252 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	exofs "github.com/a-kostevski/exo/pkg/fs"
+)
+
+// MigrateOptions configures a vault migration.
+type MigrateOptions struct {
+	From   string // Current data home.
+	To     string // New data home; must not exist or be empty.
+	Keep   bool   // Keep the source vault after a successful migration.
+	DryRun bool   // Only validate the migration, do not copy anything.
+	// ReadOnly refuses any migration but a dry run, as under --read-only.
+	ReadOnly bool
+	// Switch, if set, is called once the copy is verified and before the source
+	// is removed, e.g. to point the configuration at the new data home. The source
+	// is kept if it fails.
+	Switch func() error
+}
+
+// MigrateResult describes a completed migration.
+type MigrateResult struct {
+	Files   int  // Number of files copied.
+	GitRepo bool // Whether the vault contained a Git repository.
+}
+
+// Migrate copies the vault at opts.From to opts.To, preserving file modes and any
+// Git history, verifies that every file arrived intact (and runs "git fsck" when the
+// vault is a Git repository), calls opts.Switch, and finally removes the source
+// unless opts.Keep is set. The destination is removed again if verification fails.
+// Only a dry run is allowed when opts.ReadOnly is set.
+func Migrate(opts MigrateOptions) (*MigrateResult, error) {
+	from, to, err := validateMigration(opts.From, opts.To)
+	if err != nil {
+		return nil, err
+	}
+	result := &MigrateResult{}
+	if _, err := os.Stat(filepath.Join(from, ".git")); err == nil {
+		result.GitRepo = true
+	}
+	if opts.DryRun {
+		sums, err := Checksums(from)
+		if err != nil {
+			return nil, err
+		}
+		result.Files = len(sums)
+		return result, nil
+	}
+	if opts.ReadOnly {
+		return nil, fmt.Errorf("cannot migrate %s: %w", from, exofs.ErrReadOnly)
+	}
+
+	if err := CopyTree(from, to); err != nil {
+		_ = os.RemoveAll(to)
+		return nil, fmt.Errorf("failed to copy vault: %w", err)
+	}
+	n, err := Verify(from, to)
+	if err == nil && result.GitRepo {
+		err = gitFsck(to)
+	}
+	if err != nil {
+		_ = os.RemoveAll(to)
+		return nil, fmt.Errorf("verification failed, migration aborted: %w", err)
+	}
+	result.Files = n
+
+	if opts.Switch != nil {
+		if err := opts.Switch(); err != nil {
+			return result, fmt.Errorf("vault copied to %s, %s kept: %w", to, from, err)
+		}
+	}
+	if !opts.Keep {
+		if err := os.RemoveAll(from); err != nil {
+			return result, fmt.Errorf("vault migrated but failed to remove %s: %w", from, err)
+		}
+	}
+	return result, nil
+}
+
+// validateMigration checks the source and destination and returns their cleaned absolute paths.
+func validateMigration(from, to string) (string, string, error) {
+	if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+		return "", "", errors.New("source and destination must be set")
+	}
+	from, err := filepath.Abs(from)
+	if err != nil {
+		return "", "", err
+	}
+	to, err = filepath.Abs(to)
+	if err != nil {
+		return "", "", err
+	}
+	info, err := os.Stat(from)
+	if err != nil {
+		return "", "", fmt.Errorf("vault not accessible: %w", err)
+	}
+	if !info.IsDir() {
+		return "", "", fmt.Errorf("vault %s is not a directory", from)
+	}
+	if from == to {
+		return "", "", errors.New("source and destination are the same")
+	}
+	if rel, err := filepath.Rel(from, to); err == nil && !strings.HasPrefix(rel, "..") {
+		return "", "", fmt.Errorf("destination %s is inside the vault", to)
+	}
+	if entries, err := os.ReadDir(to); err == nil && len(entries) > 0 {
+		return "", "", fmt.Errorf("destination %s is not empty", to)
+	}
+	return from, to, nil
+}
+
+// CopyTree recursively copies src into dst, preserving file and directory modes.
+// Symbolic links are recreated rather than followed.
+func CopyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, info.Mode().Perm())
+		case info.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		default:
+			return copyFile(path, target, info.Mode().Perm())
+		}
+	})
+}
+
+// copyFile copies a single regular file.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Checksums returns the SHA-256 checksum of every regular file under root,
+// keyed by slash-separated path relative to root.
+func Checksums(root string) (map[string]string, error) {
+	sums := make(map[string]string)
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		sum, err := fileChecksum(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		sums[filepath.ToSlash(rel)] = sum
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to checksum %s: %w", root, err)
+	}
+	return sums, nil
+}
+
+// fileChecksum returns the hex-encoded SHA-256 checksum of the file at path.
+func fileChecksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks that every regular file under src exists under dst with identical
+// content, and returns the number of files compared.
+func Verify(src, dst string) (int, error) {
+	want, err := Checksums(src)
+	if err != nil {
+		return 0, err
+	}
+	got, err := Checksums(dst)
+	if err != nil {
+		return 0, err
+	}
+	var problems []string
+	for rel, sum := range want {
+		switch other, ok := got[rel]; {
+		case !ok:
+			problems = append(problems, "missing "+rel)
+		case other != sum:
+			problems = append(problems, "content differs "+rel)
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return 0, fmt.Errorf("%d file(s) did not verify: %s", len(problems), strings.Join(problems, ", "))
+	}
+	return len(want), nil
+}
+
+// gitFsck runs "git fsck" in dir if git is available.
+func gitFsck(dir string) error {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil
+	}
+	cmd := exec.Command("git", "-C", dir, "fsck", "--no-progress")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git fsck failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
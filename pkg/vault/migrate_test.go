@@ -0,0 +1,137 @@
+package vault_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeVault(t *testing.T, root string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "day"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "day", "2025-02-08.md"), []byte("# Day"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "script.sh"), []byte("#!/bin/sh"), 0755))
+}
+
+func TestMigrate(t *testing.T) {
+	tmpDir := t.TempDir()
+	from := filepath.Join(tmpDir, "old")
+	to := filepath.Join(tmpDir, "new")
+	writeVault(t, from)
+
+	result, err := vault.Migrate(vault.MigrateOptions{From: from, To: to})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	assert.False(t, result.GitRepo)
+
+	content, err := os.ReadFile(filepath.Join(to, "day", "2025-02-08.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Day", string(content))
+
+	info, err := os.Stat(filepath.Join(to, "script.sh"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+
+	_, err = os.Stat(from)
+	assert.True(t, os.IsNotExist(err), "source vault should be removed")
+}
+
+func TestMigrate_KeepAndDryRun(t *testing.T) {
+	tmpDir := t.TempDir()
+	from := filepath.Join(tmpDir, "old")
+	to := filepath.Join(tmpDir, "new")
+	writeVault(t, from)
+
+	result, err := vault.Migrate(vault.MigrateOptions{From: from, To: to, DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Files)
+	_, err = os.Stat(to)
+	assert.True(t, os.IsNotExist(err), "dry run must not create the destination")
+
+	_, err = vault.Migrate(vault.MigrateOptions{From: from, To: to, Keep: true})
+	require.NoError(t, err)
+	_, err = os.Stat(from)
+	assert.NoError(t, err, "source vault should be kept")
+}
+
+func TestMigrate_Switch(t *testing.T) {
+	tmpDir := t.TempDir()
+	from := filepath.Join(tmpDir, "old")
+	to := filepath.Join(tmpDir, "new")
+	writeVault(t, from)
+
+	_, err := vault.Migrate(vault.MigrateOptions{From: from, To: to, Switch: func() error {
+		_, err := os.Stat(filepath.Join(to, "day", "2025-02-08.md"))
+		require.NoError(t, err, "the copy should be done before switching")
+		return errors.New("read-only config")
+	}})
+	assert.ErrorContains(t, err, "read-only config")
+	_, err = os.Stat(filepath.Join(from, "day", "2025-02-08.md"))
+	assert.NoError(t, err, "source vault should be kept when switching fails")
+
+	switched := false
+	to2 := filepath.Join(tmpDir, "new2")
+	_, err = vault.Migrate(vault.MigrateOptions{From: from, To: to2, Switch: func() error {
+		switched = true
+		return nil
+	}})
+	require.NoError(t, err)
+	assert.True(t, switched)
+	_, err = os.Stat(from)
+	assert.True(t, os.IsNotExist(err), "source vault should be removed")
+}
+
+func TestMigrate_ReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	from := filepath.Join(tmpDir, "old")
+	to := filepath.Join(tmpDir, "new")
+	writeVault(t, from)
+
+	result, err := vault.Migrate(vault.MigrateOptions{From: from, To: to, DryRun: true, ReadOnly: true})
+	require.NoError(t, err, "a dry run is allowed in read-only mode")
+	assert.Equal(t, 2, result.Files)
+
+	switched := false
+	_, err = vault.Migrate(vault.MigrateOptions{From: from, To: to, ReadOnly: true, Switch: func() error {
+		switched = true
+		return nil
+	}})
+	assert.ErrorIs(t, err, fs.ErrReadOnly)
+	assert.False(t, switched)
+	_, err = os.Stat(to)
+	assert.True(t, os.IsNotExist(err), "read-only mode must not create the destination")
+	_, err = os.Stat(filepath.Join(from, "day", "2025-02-08.md"))
+	assert.NoError(t, err, "read-only mode must keep the source vault")
+}
+
+func TestMigrate_Invalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	from := filepath.Join(tmpDir, "old")
+	writeVault(t, from)
+
+	_, err := vault.Migrate(vault.MigrateOptions{From: from, To: filepath.Join(from, "nested")})
+	assert.ErrorContains(t, err, "inside the vault")
+
+	nonEmpty := filepath.Join(tmpDir, "full")
+	writeVault(t, nonEmpty)
+	_, err = vault.Migrate(vault.MigrateOptions{From: from, To: nonEmpty})
+	assert.ErrorContains(t, err, "not empty")
+}
+
+func TestVerify_DetectsDifferences(t *testing.T) {
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a")
+	b := filepath.Join(tmpDir, "b")
+	writeVault(t, a)
+	require.NoError(t, vault.CopyTree(a, b))
+	require.NoError(t, os.WriteFile(filepath.Join(b, "script.sh"), []byte("changed"), 0755))
+
+	_, err := vault.Verify(a, b)
+	assert.ErrorContains(t, err, "content differs script.sh")
+}
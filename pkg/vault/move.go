@@ -0,0 +1,209 @@
+package vault
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// MoveOptions configures moving a note.
+type MoveOptions struct {
+	Root  string      // Data home; path-qualified wikilinks are relative to it.
+	From  string      // The note to move.
+	ToDir string      // The directory the note is moved into.
+	Type  string      // Frontmatter type set on the note, if not empty.
+	Notes []scan.Note // Notes of the vault whose links to the note are updated.
+	// FS is the file system links are updated through; it defaults to the OS
+	// file system, guarding locked notes.
+	FS fs.FileSystem
+}
+
+// MoveResult describes a moved note.
+type MoveResult struct {
+	Path    string   // New path of the note.
+	Updated []string // Notes whose links to the note were updated.
+	Skipped []string // Locked notes whose links to the note were left as they are.
+}
+
+// MoveNote moves the note opts.From into opts.ToDir, keeping its file name. The
+// relative Markdown links of the note are rewritten for its new directory, its
+// frontmatter type is set to opts.Type when it has frontmatter, and the
+// Markdown links and path-qualified wikilinks of opts.Notes pointing to it are
+// updated through opts.FS, except in locked notes. A note already at the new
+// path is not replaced.
+func MoveNote(opts MoveOptions) (*MoveResult, error) {
+	from, err := filepath.Abs(opts.From)
+	if err != nil {
+		return nil, err
+	}
+	toDir, err := filepath.Abs(opts.ToDir)
+	if err != nil {
+		return nil, err
+	}
+	to := filepath.Join(toDir, filepath.Base(from))
+	if to == from {
+		return nil, exoerrors.New(exoerrors.Validation, "%s is already in %s", filepath.Base(from), toDir)
+	}
+	if _, err := os.Stat(to); err == nil {
+		return nil, exoerrors.New(exoerrors.Conflict, "cannot move %s: %s already exists", from, to)
+	}
+	info, err := os.Stat(from)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read note: %w", err)
+	}
+	moved := RelinkMoved(string(content), filepath.Dir(from), toDir)
+	if opts.Type != "" {
+		if moved, err = frontmatter.Set(moved, "type", opts.Type); err != nil {
+			return nil, fmt.Errorf("failed to set the type of %s: %w", from, err)
+		}
+	}
+
+	if err := os.MkdirAll(toDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", toDir, err)
+	}
+	f, err := os.OpenFile(to, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return nil, fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if _, err := f.WriteString(moved); err != nil {
+		f.Close()
+		os.Remove(to)
+		return nil, fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(to)
+		return nil, fmt.Errorf("failed to write %s: %w", to, err)
+	}
+	if err := os.Remove(from); err != nil {
+		os.Remove(to)
+		return nil, fmt.Errorf("failed to move %s: %w", from, err)
+	}
+
+	result := &MoveResult{Path: to}
+	err = result.relink(opts.FS, opts.Notes, from, func(n scan.Note, content string) string {
+		return RelinkTo(content, filepath.Dir(n.Path), opts.Root, from, to)
+	})
+	if err != nil {
+		return result, fmt.Errorf("note moved but %w", err)
+	}
+	return result, nil
+}
+
+// relink rewrites the notes other than the one at from with rewrite through
+// fsys, recording those changed as updated and the locked ones as skipped.
+func (r *MoveResult) relink(fsys fs.FileSystem, notes []scan.Note, from string, rewrite func(n scan.Note, content string) string) error {
+	fsys = fileSystem(fsys)
+	for _, n := range notes {
+		if n.Path == from {
+			continue
+		}
+		content, err := fsys.ReadFile(n.Path)
+		if err != nil {
+			return fmt.Errorf("failed to update links in %s: %w", n.Path, err)
+		}
+		relinked := rewrite(n, string(content))
+		if relinked == string(content) {
+			continue
+		}
+		if err := fsys.WriteFile(n.Path, []byte(relinked)); err != nil {
+			if errors.Is(err, note.ErrLocked) {
+				r.Skipped = append(r.Skipped, n.Path)
+				continue
+			}
+			return fmt.Errorf("failed to update links in %s: %w", n.Path, err)
+		}
+		r.Updated = append(r.Updated, n.Path)
+	}
+	return nil
+}
+
+// RelinkMoved rewrites the relative Markdown links of a note moved from the
+// directory oldDir to newDir, so that they point to the same files. Links in
+// code are left as they are.
+func RelinkMoved(content, oldDir, newDir string) string {
+	return md.RewriteLinks(content, func(l md.Link) string {
+		path, anchor, ok := linkPath(l)
+		if !ok {
+			return l.Text
+		}
+		rel, err := filepath.Rel(newDir, filepath.Join(oldDir, path))
+		if err != nil {
+			return l.Text
+		}
+		l.Target = linkTarget(l.Target, rel) + anchor
+		return l.String()
+	})
+}
+
+// RelinkTo rewrites the links of a note in dir pointing to the note at from so
+// that they point to to: relative Markdown links, and wikilinks qualified with
+// a path relative to root. Links in code are left as they are.
+func RelinkTo(content, dir, root, from, to string) string {
+	oldRel, oldErr := filepath.Rel(root, from)
+	newRel, newErr := filepath.Rel(root, to)
+	oldKey := scan.NameKey(filepath.ToSlash(oldRel))
+	return md.RewriteLinks(content, func(l md.Link) string {
+		if l.Wiki {
+			if oldErr != nil || newErr != nil || !strings.Contains(l.Target, "/") || scan.NameKey(l.Target) != oldKey {
+				return l.Text
+			}
+			link := filepath.ToSlash(newRel)
+			if !strings.HasSuffix(strings.ToLower(l.Target), scan.NoteExtension) {
+				link = strings.TrimSuffix(link, scan.NoteExtension)
+			}
+			l.Target = link
+			return l.String()
+		}
+		path, anchor, ok := linkPath(l)
+		if !ok || filepath.Join(dir, path) != from {
+			return l.Text
+		}
+		rel, err := filepath.Rel(dir, to)
+		if err != nil {
+			return l.Text
+		}
+		l.Target = linkTarget(l.Target, rel) + anchor
+		return l.String()
+	})
+}
+
+// linkPath returns the file a Markdown link refers to, unescaped, and the
+// #anchor of its target. Only relative links to files are reported.
+func linkPath(l md.Link) (path, anchor string, ok bool) {
+	target := l.Target
+	if l.Wiki || target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "/") || strings.Contains(target, ":") {
+		return "", "", false
+	}
+	path, anchor, hasAnchor := strings.Cut(target, "#")
+	if hasAnchor {
+		anchor = "#" + anchor
+	}
+	path, err := url.PathUnescape(path)
+	if err != nil {
+		return "", "", false
+	}
+	return filepath.FromSlash(path), anchor, true
+}
+
+// linkTarget formats the relative path rel as a link target written like orig.
+func linkTarget(orig, rel string) string {
+	rel = filepath.ToSlash(rel)
+	if strings.Contains(orig, "%20") {
+		rel = strings.ReplaceAll(rel, " ", "%20")
+	}
+	return rel
+}
@@ -0,0 +1,97 @@
+package vault_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoveNote(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	read := func(rel string) string {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		require.NoError(t, err)
+		return string(content)
+	}
+	note := write("0-inbox/go.md", "---\ncreated: 2025-02-08\n---\n# Go\n\nSee [Rust](rust.md#ownership) and [docs](https://go.dev).\n")
+	write("0-inbox/rust.md", "# Rust\n\nCompare [Go](go.md), [[go]] and [[0-inbox/go|Go]].\n")
+	write("projects/lang.md", "# Languages\n\n![Go](../0-inbox/go.md) [[0-inbox/go.md#Intro]]\n")
+	unrelated := "# Other\n\n[Rust](../0-inbox/rust.md)\n"
+	write("zettel/other.md", unrelated)
+	notes, err := scan.Scan(root)
+	require.NoError(t, err)
+
+	result, err := vault.MoveNote(vault.MoveOptions{
+		Root:  root,
+		From:  note,
+		ToDir: filepath.Join(root, "zettel"),
+		Type:  "zettel",
+		Notes: notes,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "zettel", "go.md"), result.Path)
+	assert.ElementsMatch(t, []string{filepath.Join(root, "0-inbox", "rust.md"), filepath.Join(root, "projects", "lang.md")}, result.Updated)
+	assert.NoFileExists(t, note)
+
+	assert.Equal(t, "---\ncreated: 2025-02-08\ntype: zettel\n---\n# Go\n\nSee [Rust](../0-inbox/rust.md#ownership) and [docs](https://go.dev).\n", read("zettel/go.md"))
+	assert.Equal(t, "# Rust\n\nCompare [Go](../zettel/go.md), [[go]] and [[zettel/go|Go]].\n", read("0-inbox/rust.md"))
+	assert.Equal(t, "# Languages\n\n![Go](../zettel/go.md) [[zettel/go.md#Intro]]\n", read("projects/lang.md"))
+	assert.Equal(t, unrelated, read("zettel/other.md"))
+}
+
+func TestMoveNote_Conflict(t *testing.T) {
+	root := t.TempDir()
+	from := filepath.Join(root, "0-inbox", "go.md")
+	require.NoError(t, os.MkdirAll(filepath.Dir(from), 0755))
+	require.NoError(t, os.WriteFile(from, []byte("# Go\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "zettel"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "zettel", "go.md"), []byte("# Other Go\n"), 0644))
+
+	_, err := vault.MoveNote(vault.MoveOptions{Root: root, From: from, ToDir: filepath.Join(root, "zettel")})
+	assert.True(t, exoerrors.Is(err, exoerrors.Conflict))
+	assert.FileExists(t, from)
+
+	_, err = vault.MoveNote(vault.MoveOptions{Root: root, From: from, ToDir: filepath.Dir(from)})
+	assert.True(t, exoerrors.Is(err, exoerrors.Validation))
+}
+
+func TestMoveNote_FS(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	from := write("0-inbox/go.md", "# Go\n")
+	locked := "---\nlocked: true\n---\n# Rust\n\n[Go](go.md)\n"
+	write("0-inbox/rust.md", locked)
+	write("0-inbox/lang.md", "# Languages\n\n[Go](go.md)\n")
+	notes, err := scan.Scan(root)
+	require.NoError(t, err)
+
+	result, err := vault.MoveNote(vault.MoveOptions{Root: root, From: from, ToDir: filepath.Join(root, "zettel"), Notes: notes})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "0-inbox", "lang.md")}, result.Updated)
+	assert.Equal(t, []string{filepath.Join(root, "0-inbox", "rust.md")}, result.Skipped, "locked notes are left as they are")
+	content, err := os.ReadFile(filepath.Join(root, "0-inbox", "rust.md"))
+	require.NoError(t, err)
+	assert.Equal(t, locked, string(content))
+
+	_, err = vault.MoveNote(vault.MoveOptions{Root: root, From: result.Path, ToDir: filepath.Join(root, "0-inbox"), Notes: notes,
+		FS: fs.NewReadOnlyFileSystem(fs.NewOSFileSystem())})
+	assert.ErrorIs(t, err, fs.ErrReadOnly, "links are updated through the file system")
+}
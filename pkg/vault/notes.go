@@ -0,0 +1,157 @@
+package vault
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/a-kostevski/exo/pkg/config"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Notes creates and changes the notes of a vault for its clients, such as the
+// HTTP API and the daemon. Clients name notes and directories by paths
+// relative to the data home, slash-separated; those escaping it or leading
+// into an excluded or ignored directory are rejected.
+//
+// Notes are saved as note.BaseNote saves them, through FS: locked notes and
+// read-only mode are respected, previous versions kept, changes made on disk
+// meanwhile detected, timestamps set and schemas checked.
+type Notes struct {
+	// Config is the configuration of the vault, whose data_home is served.
+	Config config.Config
+	// FS is the file system notes are written through; it defaults to the OS
+	// file system, guarding locked notes.
+	FS fs.FileSystem
+	// Logger reports the changes on disk a save merged or worked around; it
+	// may be nil.
+	Logger logger.Logger
+	// NewNoteDir is the directory, under the data home, notes created without
+	// a directory are placed in; it defaults to the data home.
+	NewNoteDir string
+	// Exclude lists directories under the data home that clients cannot
+	// reach (e.g. templates).
+	Exclude []string
+	// Ignore matches the files and directories under the data home that
+	// clients cannot reach, as listed in .exoignore.
+	Ignore *scan.Ignore
+}
+
+// DataHome returns the directory of the vault.
+func (n Notes) DataHome() string {
+	return n.Config.Dir.DataHome
+}
+
+// Resolve joins the slash-separated path rel onto the data home, failing with
+// a validation error for paths that escape it or are excluded.
+func (n Notes) Resolve(rel string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(rel))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", exoerrors.New(exoerrors.Validation, "invalid path: %s", rel)
+	}
+	path := filepath.Join(n.DataHome(), clean)
+	if n.Excluded(path) {
+		return "", exoerrors.New(exoerrors.Validation, "invalid path: %s", rel)
+	}
+	return path, nil
+}
+
+// Excluded reports whether path is in one of the excluded directories or
+// ignored.
+func (n Notes) Excluded(path string) bool {
+	if n.Ignore.Match(path, false) {
+		return true
+	}
+	for _, dir := range n.Exclude {
+		rel, err := filepath.Rel(dir, path)
+		if dir != "" && err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rel returns path relative to the data home, slash-separated.
+func (n Notes) Rel(path string) string {
+	rel, err := filepath.Rel(n.DataHome(), path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Create creates the note titled title in dir, relative to the data home, or
+// in NewNoteDir, with content or else a heading, and returns its path. A note
+// already at that path is not replaced: Create fails with a conflict error.
+func (n Notes) Create(title, dir, content string) (string, error) {
+	title = strings.TrimSpace(title)
+	if title == "" || strings.ContainsAny(title, `/\`) {
+		return "", exoerrors.New(exoerrors.Validation, "a title without slashes is required")
+	}
+	parent := n.NewNoteDir
+	if parent == "" {
+		parent = n.DataHome()
+	}
+	if dir != "" {
+		var err error
+		if parent, err = n.Resolve(dir); err != nil {
+			return "", err
+		}
+	}
+	path := filepath.Join(parent, title+scan.NoteExtension)
+	if n.Excluded(path) {
+		return "", exoerrors.New(exoerrors.Validation, "invalid path: %s", n.Rel(path))
+	}
+	if content == "" {
+		content = "# " + title + "\n"
+	}
+	created, err := n.note(path, title, note.WithContent(content))
+	if err != nil {
+		return "", err
+	}
+	if created.Exists() {
+		return "", exoerrors.New(exoerrors.Conflict, "note %s already exists", n.Rel(path))
+	}
+	if err := created.Save(); err != nil {
+		return "", err
+	}
+	return created.Path(), nil
+}
+
+// Update replaces the content of the note at path, which exists.
+func (n Notes) Update(path, content string) error {
+	updated, err := n.note(path, strings.TrimSuffix(filepath.Base(path), scan.NoteExtension))
+	if err != nil {
+		return err
+	}
+	if err := updated.Load(); err != nil {
+		return err
+	}
+	if err := updated.SetContent(content); err != nil {
+		return err
+	}
+	return updated.Save()
+}
+
+// note returns the note at path, under the data home.
+func (n Notes) note(path, title string, opts ...note.NoteOption) (note.Note, error) {
+	subDir, err := filepath.Rel(n.DataHome(), filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("note %s is outside of data_home: %w", path, err)
+	}
+	opts = append([]note.NoteOption{note.WithSubDir(subDir), note.WithFileName(filepath.Base(path))}, opts...)
+	return note.NewBaseNote(title, n.Config, nil, n.Logger, fileSystem(n.FS), opts...)
+}
+
+// fileSystem returns fsys, or the OS file system guarding locked notes when
+// it is nil.
+func fileSystem(fsys fs.FileSystem) fs.FileSystem {
+	if fsys == nil {
+		return note.GuardLocked(fs.NewOSFileSystem())
+	}
+	return fsys
+}
@@ -0,0 +1,118 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/md"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/slug"
+)
+
+// RenameOptions configures renaming a note.
+type RenameOptions struct {
+	Root  string      // Data home; path-qualified wikilinks are relative to it.
+	From  string      // The note to rename.
+	Name  string      // The new file name, in the same directory.
+	Notes []scan.Note // Notes of the vault whose links to the note are updated.
+	// FS is the file system links are updated through; it defaults to the OS
+	// file system, guarding locked notes.
+	FS fs.FileSystem
+}
+
+// RenameNote renames the note opts.From to opts.Name and updates the links of
+// opts.Notes pointing to it: Markdown links and path-qualified wikilinks, as
+// MoveNote does, and wikilinks naming the note by its old file name, unless
+// its title, an alias or an ID still names it. Wikilinks without a label are
+// given the old name as label, so that they read the same. Links are updated
+// through opts.FS, except in locked notes. A note already at the new path is
+// not replaced.
+func RenameNote(opts RenameOptions) (*MoveResult, error) {
+	from, err := filepath.Abs(opts.From)
+	if err != nil {
+		return nil, err
+	}
+	to := filepath.Join(filepath.Dir(from), opts.Name)
+	if to == from {
+		return nil, exoerrors.New(exoerrors.Validation, "%s is already named %s", from, opts.Name)
+	}
+	if info, err := os.Stat(to); err == nil {
+		// On case-insensitive file systems, a change of case finds the note
+		// itself.
+		if fromInfo, err := os.Stat(from); err != nil || !os.SameFile(info, fromInfo) {
+			return nil, exoerrors.New(exoerrors.Conflict, "cannot rename %s: %s already exists", from, to)
+		}
+	}
+	names := scan.NewNames(opts.Root, opts.Notes)
+	if err := os.Rename(from, to); err != nil {
+		return nil, fmt.Errorf("failed to rename %s: %w", from, err)
+	}
+
+	result := &MoveResult{Path: to}
+	renamed, err := scan.ReadNote(to)
+	if err != nil {
+		return result, fmt.Errorf("note renamed but failed to read it: %w", err)
+	}
+	// The names of the note that still name it after the rename.
+	kept := map[string]bool{scan.NameKey(renamed.Title): true}
+	for _, name := range append(scan.IDs(renamed), renamed.Aliases...) {
+		kept[scan.NameKey(name)] = true
+	}
+	oldKey := scan.NameKey(filepath.Base(from))
+	newName := strings.TrimSuffix(opts.Name, scan.NoteExtension)
+
+	err = result.relink(opts.FS, opts.Notes, from, func(n scan.Note, content string) string {
+		relinked := RelinkTo(content, filepath.Dir(n.Path), opts.Root, from, to)
+		return md.RewriteLinks(relinked, func(l md.Link) string {
+			key := scan.NameKey(l.Target)
+			if !l.Wiki || strings.Contains(l.Target, "/") || key != oldKey || kept[key] {
+				return l.Text
+			}
+			if resolved := names.Resolve(l.Target); len(resolved) != 1 || resolved[0].Path != from {
+				return l.Text
+			}
+			if l.Label == "" {
+				l.Label = l.Target
+			}
+			link := newName
+			if strings.HasSuffix(strings.ToLower(l.Target), scan.NoteExtension) {
+				link += scan.NoteExtension
+			}
+			l.Target = link
+			return l.String()
+		})
+	})
+	if err != nil {
+		return result, fmt.Errorf("note renamed but %w", err)
+	}
+	return result, nil
+}
+
+// SlugName returns the file name n would be created with under opts: its
+// title slugged as of its creation date, after the ID its file name starts
+// with, if any. A date prefix or ID the title itself starts with, as titles
+// taken from the file name do, is not repeated.
+func SlugName(n scan.Note, opts slug.Options) string {
+	title := strings.TrimSpace(n.Title)
+	prefix := ""
+	base := strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension)
+	if fields := strings.Fields(base); len(fields) > 1 {
+		for _, id := range scan.IDs(n) {
+			if fields[0] == id {
+				prefix = id + " "
+				title = strings.TrimSpace(strings.TrimPrefix(title, id))
+				break
+			}
+		}
+	}
+	if opts.DatePrefix != "" {
+		if date := n.Created.Format(opts.DatePrefix); strings.HasPrefix(title, date) {
+			title = strings.TrimLeft(strings.TrimPrefix(title, date), " -_")
+		}
+	}
+	return prefix + slug.Make(title, opts, n.Created) + scan.NoteExtension
+}
@@ -0,0 +1,83 @@
+package vault_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/slug"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenameNote(t *testing.T) {
+	root := t.TempDir()
+	write := func(rel, content string) string {
+		path := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	read := func(rel string) string {
+		content, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		require.NoError(t, err)
+		return string(content)
+	}
+	channels := write("zettel/Go Channels.md", "# Go Channels\n")
+	select_ := write("zettel/Select Statement.md", "Body without a title.\n")
+	write("0-inbox/rust.md", "# Rust\n\n[[Go Channels]], [Go](../zettel/Go%20Channels.md) and [[zettel/Go Channels#Buffers|buffers]].\n")
+	write("0-inbox/sel.md", "# Sel\n\n[[select statement#Default]], [[Select Statement|select]] and ![[Select Statement.md]].\n\n```\n[[Select Statement]]\n```\n")
+	notes, err := scan.Scan(root)
+	require.NoError(t, err)
+
+	result, err := vault.RenameNote(vault.RenameOptions{Root: root, From: channels, Name: "go-channels.md", Notes: notes})
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(root, "zettel", "go-channels.md"), result.Path)
+	assert.Equal(t, []string{filepath.Join(root, "0-inbox", "rust.md")}, result.Updated)
+	assert.Equal(t, "# Rust\n\n[[Go Channels]], [Go](../zettel/go-channels.md) and [[zettel/go-channels#Buffers|buffers]].\n",
+		read("0-inbox/rust.md"), "links by title keep working")
+	for i := range notes {
+		if notes[i].Path == channels {
+			notes[i].Path = result.Path
+		}
+	}
+
+	result, err = vault.RenameNote(vault.RenameOptions{Root: root, From: select_, Name: "select-statement.md", Notes: notes})
+	require.NoError(t, err)
+	assert.Len(t, result.Updated, 1)
+	assert.Equal(t, "# Sel\n\n[[select-statement#Default|select statement]], [[select-statement|select]] and ![[select-statement.md|Select Statement.md]].\n\n```\n[[Select Statement]]\n```\n",
+		read("0-inbox/sel.md"), "links by file name follow the note, those in code stay")
+
+	write("zettel/taken.md", "# Taken\n")
+	_, err = vault.RenameNote(vault.RenameOptions{Root: root, From: result.Path, Name: "taken.md"})
+	assert.True(t, exoerrors.Is(err, exoerrors.Conflict))
+	_, err = vault.RenameNote(vault.RenameOptions{Root: root, From: result.Path, Name: "select-statement.md"})
+	assert.True(t, exoerrors.Is(err, exoerrors.Validation))
+}
+
+func TestSlugName(t *testing.T) {
+	created := time.Date(2025, 2, 8, 9, 0, 0, 0, time.UTC)
+	kebab := slug.Options{Style: slug.Kebab}
+	dated := slug.Options{Style: slug.Kebab, DatePrefix: "2006-01-02"}
+	tests := []struct {
+		name string
+		note scan.Note
+		opts slug.Options
+		want string
+	}{
+		{"title", scan.Note{Path: "/v/Go Channels.md", Title: "Go Channels"}, kebab, "go-channels.md"},
+		{"id", scan.Note{Path: "/v/202502 Go Channels.md", Title: "Go Channels"}, kebab, "202502 go-channels.md"},
+		{"id from file name", scan.Note{Path: "/v/202502 Go Channels.md", Title: "202502 Go Channels"}, kebab, "202502 go-channels.md"},
+		{"date", scan.Note{Path: "/v/Go.md", Title: "Go", Created: created}, dated, "2025-02-08-go.md"},
+		{"dated already", scan.Note{Path: "/v/2025-02-08-go.md", Title: "2025-02-08-go", Created: created}, dated, "2025-02-08-go.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, vault.SlugName(tt.note, tt.opts))
+		})
+	}
+}
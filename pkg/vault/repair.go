@@ -0,0 +1,109 @@
+package vault
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/a-kostevski/exo/pkg/templates"
+)
+
+// RepairOptions configures repairing the structure of a vault.
+type RepairOptions struct {
+	Dirs        []string    // Directories that must exist.
+	DirMode     os.FileMode // Mode of the directories created.
+	TemplateDir string      // Directory of the templates.
+	FileMode    os.FileMode // Mode of the templates.
+	// Templates are the default templates installed in TemplateDir when
+	// missing.
+	Templates templates.DefaultTemplateStore
+}
+
+// Change is a change made by Repair.
+type Change struct {
+	Path   string
+	Action string // What was done, e.g. "created directory".
+}
+
+func (c Change) String() string {
+	return c.Action + ": " + c.Path
+}
+
+// Repair creates the missing directories of opts.Dirs and opts.TemplateDir,
+// installs the default templates missing from opts.TemplateDir, leaving the
+// others as they are, and fixes the permissions of the directories the owner
+// cannot use and of the templates not in opts.FileMode. It returns the
+// changes made; repairing a vault in order changes nothing.
+func Repair(opts RepairOptions) ([]Change, error) {
+	if opts.DirMode == 0 {
+		opts.DirMode = 0755
+	}
+	if opts.FileMode == 0 {
+		opts.FileMode = 0644
+	}
+	var changes []Change
+	for _, dir := range append(opts.Dirs, opts.TemplateDir) {
+		if dir == "" {
+			continue
+		}
+		info, err := os.Stat(dir)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.MkdirAll(dir, opts.DirMode.Perm()); err != nil {
+				return changes, fmt.Errorf("failed to create directory %s: %w", dir, err)
+			}
+			// MkdirAll applies the umask and ignores the setgid and sticky bits.
+			if err := os.Chmod(dir, opts.DirMode); err != nil {
+				return changes, err
+			}
+			changes = append(changes, Change{Path: dir, Action: "created directory"})
+		case err != nil:
+			return changes, err
+		case !info.IsDir():
+			return changes, fmt.Errorf("%s is not a directory", dir)
+		case info.Mode().Perm()&0700 != 0700:
+			mode := info.Mode() | 0700
+			if err := os.Chmod(dir, mode); err != nil {
+				return changes, fmt.Errorf("failed to change the mode of %s: %w", dir, err)
+			}
+			changes = append(changes, Change{Path: dir, Action: fmt.Sprintf("changed mode %04o to %04o", info.Mode().Perm(), mode.Perm())})
+		}
+	}
+
+	if opts.Templates == nil || opts.TemplateDir == "" {
+		return changes, nil
+	}
+	names, err := opts.Templates.ListTemplates()
+	if err != nil {
+		return changes, fmt.Errorf("failed to list default templates: %w", err)
+	}
+	for _, name := range names {
+		content, err := opts.Templates.ReadTemplate(name)
+		if err != nil {
+			return changes, fmt.Errorf("failed to read default template %s: %w", name, err)
+		}
+		path := filepath.Join(opts.TemplateDir, name)
+		want := templates.TemplateMode(opts.FileMode, content)
+		info, err := os.Stat(path)
+		switch {
+		case os.IsNotExist(err):
+			if err := os.WriteFile(path, content, want.Perm()); err != nil {
+				return changes, fmt.Errorf("failed to write template %s: %w", name, err)
+			}
+			// WriteFile applies the umask.
+			if err := os.Chmod(path, want); err != nil {
+				return changes, err
+			}
+			changes = append(changes, Change{Path: path, Action: "installed template"})
+		case err != nil:
+			return changes, err
+		case info.Mode().Perm() != want.Perm():
+			// A customized template keeps its content, only its mode is fixed.
+			if err := os.Chmod(path, want); err != nil {
+				return changes, fmt.Errorf("failed to change the mode of %s: %w", path, err)
+			}
+			changes = append(changes, Change{Path: path, Action: fmt.Sprintf("changed mode %04o to %04o", info.Mode().Perm(), want.Perm())})
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,54 @@
+package vault_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/templates"
+	"github.com/a-kostevski/exo/pkg/vault"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepair(t *testing.T) {
+	root := t.TempDir()
+	zettel := filepath.Join(root, "zettel")
+	templateDir := filepath.Join(root, "templates")
+	opts := vault.RepairOptions{
+		Dirs:        []string{root, zettel},
+		DirMode:     0755,
+		TemplateDir: templateDir,
+		FileMode:    0644,
+		Templates:   templates.NewEmbedTemplateStore(templates.DefaultTemplatesFS, templates.DefaultTemplateBaseDir),
+	}
+	defaults, err := opts.Templates.ListTemplates()
+	require.NoError(t, err)
+
+	changes, err := vault.Repair(opts)
+	require.NoError(t, err)
+	assert.Len(t, changes, 2+len(defaults))
+	assert.Equal(t, vault.Change{Path: zettel, Action: "created directory"}, changes[0])
+	assert.DirExists(t, templateDir)
+
+	changes, err = vault.Repair(opts)
+	require.NoError(t, err)
+	assert.Empty(t, changes, "a repaired vault is left as it is")
+
+	day := filepath.Join(templateDir, "day.md")
+	require.NoError(t, os.WriteFile(day, []byte("custom day"), 0600))
+	require.NoError(t, os.Chmod(day, 0600))
+	require.NoError(t, os.Remove(filepath.Join(templateDir, "week.md")))
+	require.NoError(t, os.Chmod(zettel, 0500))
+
+	changes, err = vault.Repair(opts)
+	require.NoError(t, err)
+	assert.Equal(t, []vault.Change{
+		{Path: zettel, Action: "changed mode 0500 to 0700"},
+		{Path: day, Action: "changed mode 0600 to 0644"},
+		{Path: filepath.Join(templateDir, "week.md"), Action: "installed template"},
+	}, changes)
+	content, err := os.ReadFile(day)
+	require.NoError(t, err)
+	assert.Equal(t, "custom day", string(content), "customized templates are kept")
+}
@@ -0,0 +1,98 @@
+package versions
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/logger"
+)
+
+// KeepingFileSystem wraps a FileSystem, keeping in a Store the previous
+// version of the notes it overwrites or that are changed in the editor.
+type KeepingFileSystem struct {
+	fs.FileSystem
+	store   *Store
+	exclude []string
+	logger  logger.Logger
+	now     func() time.Time
+}
+
+// Keep returns a KeepingFileSystem keeping the versions of the Markdown files
+// under the root of store, other than those under the exclude directories.
+// Failures to keep a version are logged and do not fail the file operation.
+func Keep(fsys fs.FileSystem, store *Store, exclude []string, log logger.Logger) *KeepingFileSystem {
+	return &KeepingFileSystem{FileSystem: fsys, store: store, exclude: exclude, logger: log, now: time.Now}
+}
+
+// WriteFile writes the file and keeps the contents it replaced.
+func (k *KeepingFileSystem) WriteFile(path string, content []byte) error {
+	old, kept := k.read(path)
+	if err := k.FileSystem.WriteFile(path, content); err != nil {
+		return err
+	}
+	if kept && !bytes.Equal(old, content) {
+		k.save(path, old)
+	}
+	return nil
+}
+
+// OpenInEditor opens the file and, if it was changed in the editor, keeps the
+// contents it had before.
+func (k *KeepingFileSystem) OpenInEditor(path, editor string) error {
+	old, kept := k.read(path)
+	if err := k.FileSystem.OpenInEditor(path, editor); err != nil {
+		return err
+	}
+	if kept {
+		if content, err := k.FileSystem.ReadFile(path); err == nil && !bytes.Equal(old, content) {
+			k.save(path, old)
+		}
+	}
+	return nil
+}
+
+// read returns the contents of the file at path, and whether it is an
+// existing note whose versions are kept.
+func (k *KeepingFileSystem) read(path string) ([]byte, bool) {
+	if !k.keeps(path) {
+		return nil, false
+	}
+	content, err := k.FileSystem.ReadFile(path)
+	return content, err == nil
+}
+
+// save keeps content as the previous version of the note at path.
+func (k *KeepingFileSystem) save(path string, content []byte) {
+	if err := k.store.Save(path, content, k.now()); err != nil && k.logger != nil {
+		k.logger.Errorf("Failed to keep the previous version of %s: %v", path, err)
+	}
+}
+
+// keeps reports whether the versions of the file at path are kept.
+func (k *KeepingFileSystem) keeps(path string) bool {
+	path, err := filepath.Abs(path)
+	if err != nil || filepath.Ext(path) != ".md" || !within(k.store.root, path) {
+		return false
+	}
+	rel, _ := filepath.Rel(k.store.root, path)
+	if strings.HasPrefix(filepath.ToSlash(rel), ".") {
+		// Files in the hidden directories of the data home, such as the
+		// versions themselves, are not notes.
+		return false
+	}
+	for _, dir := range k.exclude {
+		if dir != "" && within(dir, path) {
+			return false
+		}
+	}
+	return true
+}
+
+// within reports whether path lies under dir.
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
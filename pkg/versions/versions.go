@@ -0,0 +1,192 @@
+// Package versions keeps the previous versions of notes, so that a note
+// overwritten by mistake, by exo or in the editor, can be compared with and
+// recovered from them without git.
+package versions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dir is the directory, relative to data_home, versions are kept in. The
+// versions of a note are kept in a directory named after its path, e.g.
+// .versions/zettel/go.md/2024-03-15T101500.000.md.
+const Dir = ".versions"
+
+// layout is the layout of the names of versions: the time they were replaced.
+const layout = "2006-01-02T150405.000"
+
+// ErrNoVersion is returned for versions a note does not have.
+var ErrNoVersion = errors.New("no such version")
+
+// Version is a previous version of a note.
+type Version struct {
+	// Time is when the version was replaced.
+	Time time.Time
+	// Path is the file holding the version.
+	Path string
+	Size int64
+}
+
+// Store keeps the previous versions of the notes under a root directory.
+type Store struct {
+	root string
+	keep int
+}
+
+// NewStore returns a Store keeping up to keep versions of each note under
+// root, the data home.
+func NewStore(root string, keep int) *Store {
+	return &Store{root: root, keep: keep}
+}
+
+// dir returns the directory holding the versions of the note at path.
+func (s *Store) dir(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(s.root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is not in %s", path, s.root)
+	}
+	return filepath.Join(s.root, Dir, rel), nil
+}
+
+// Save keeps content as a version of the note at path replaced at now, unless
+// it is the same as the latest version. The oldest versions beyond the number
+// the store keeps are removed.
+func (s *Store) Save(path string, content []byte, now time.Time) error {
+	dir, err := s.dir(path)
+	if err != nil {
+		return err
+	}
+	list, err := s.List(path)
+	if err != nil {
+		return err
+	}
+	if len(list) > 0 {
+		if latest, err := os.ReadFile(list[0].Path); err == nil && bytes.Equal(latest, content) {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create version directory: %w", err)
+	}
+	// Versions replaced in the same millisecond are told apart by a later
+	// time.
+	name := ""
+	for t := now; ; t = t.Add(time.Millisecond) {
+		name = filepath.Join(dir, t.Format(layout)+filepath.Ext(path))
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			break
+		}
+	}
+	if err := os.WriteFile(name, content, 0644); err != nil {
+		return fmt.Errorf("failed to save version: %w", err)
+	}
+	return s.prune(path)
+}
+
+// prune removes the oldest versions of the note at path beyond the number the
+// store keeps.
+func (s *Store) prune(path string) error {
+	if s.keep <= 0 {
+		return nil
+	}
+	list, err := s.List(path)
+	if err != nil {
+		return err
+	}
+	for i := s.keep; i < len(list); i++ {
+		if err := os.Remove(list[i].Path); err != nil {
+			return fmt.Errorf("failed to remove old version: %w", err)
+		}
+	}
+	return nil
+}
+
+// List returns the versions of the note at path, the latest first.
+func (s *Store) List(path string) ([]Version, error) {
+	dir, err := s.dir(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list versions: %w", err)
+	}
+	var list []Version
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), filepath.Ext(e.Name()))
+		t, err := time.ParseInLocation(layout, name, time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, Version{Time: t, Path: filepath.Join(dir, e.Name()), Size: info.Size()})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Time.After(list[j].Time) })
+	return list, nil
+}
+
+// Get returns the version of the note at path referred to by ref: "@~N" is
+// the Nth latest version, "@~1" the one the note replaced.
+func (s *Store) Get(path, ref string) (*Version, error) {
+	n, err := ParseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("%s is the note itself, not a previous version", ref)
+	}
+	list, err := s.List(path)
+	if err != nil {
+		return nil, err
+	}
+	if n > len(list) {
+		return nil, fmt.Errorf("%s: %w (the note has %d)", ref, ErrNoVersion, len(list))
+	}
+	return &list[n-1], nil
+}
+
+// ParseRef returns N for a reference to a version "@~N". "@" refers to the
+// note itself, N = 0, and "@~" to the latest version, N = 1.
+func ParseRef(ref string) (int, error) {
+	if ref == "@" {
+		return 0, nil
+	}
+	rest, ok := strings.CutPrefix(ref, "@~")
+	if !ok {
+		return 0, fmt.Errorf("invalid version %q (expected @~N)", ref)
+	}
+	if rest == "" {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid version %q (expected @~N)", ref)
+	}
+	return n, nil
+}
+
+// IsRef reports whether arg is a reference to a version, such as "@~1".
+func IsRef(arg string) bool {
+	return arg == "@" || strings.HasPrefix(arg, "@~")
+}
@@ -0,0 +1,134 @@
+package versions_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/testutil"
+	"github.com/a-kostevski/exo/pkg/versions"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func read(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestStore(t *testing.T) {
+	root := t.TempDir()
+	note := filepath.Join(root, "zettel", "go.md")
+	s := versions.NewStore(root, 2)
+
+	list, err := s.List(note)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	now := time.Date(2024, 3, 15, 10, 15, 0, 0, time.Local)
+	require.NoError(t, s.Save(note, []byte("v1"), now))
+	require.NoError(t, s.Save(note, []byte("v1"), now.Add(time.Minute)), "the same contents are kept once")
+	require.NoError(t, s.Save(note, []byte("v2"), now.Add(time.Minute)))
+	require.NoError(t, s.Save(note, []byte("v3"), now.Add(time.Minute)))
+
+	list, err = s.List(note)
+	require.NoError(t, err)
+	require.Len(t, list, 2, "only the latest versions are kept")
+	assert.Equal(t, "v3", read(t, list[0].Path))
+	assert.Equal(t, "v2", read(t, list[1].Path))
+	assert.True(t, list[0].Time.After(list[1].Time), "versions saved at the same time are ordered")
+	assert.Equal(t, filepath.Join(root, versions.Dir, "zettel", "go.md"), filepath.Dir(list[0].Path))
+
+	v, err := s.Get(note, "@~2")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", read(t, v.Path))
+	v, err = s.Get(note, "@~")
+	require.NoError(t, err)
+	assert.Equal(t, "v3", read(t, v.Path))
+	_, err = s.Get(note, "@~3")
+	assert.ErrorIs(t, err, versions.ErrNoVersion)
+	_, err = s.Get(note, "@")
+	assert.Error(t, err)
+
+	assert.Error(t, s.Save(filepath.Join(t.TempDir(), "elsewhere.md"), []byte("x"), now))
+}
+
+func TestParseRef(t *testing.T) {
+	for ref, want := range map[string]int{"@": 0, "@~": 1, "@~1": 1, "@~12": 12} {
+		n, err := versions.ParseRef(ref)
+		require.NoError(t, err, ref)
+		assert.Equal(t, want, n, ref)
+	}
+	for _, ref := range []string{"", "HEAD~1", "@~x", "@~-1"} {
+		_, err := versions.ParseRef(ref)
+		assert.Error(t, err, ref)
+	}
+	assert.True(t, versions.IsRef("@~2"))
+	assert.False(t, versions.IsRef("note"))
+}
+
+func TestKeep(t *testing.T) {
+	root := t.TempDir()
+	templates := filepath.Join(root, "templates")
+	s := versions.NewStore(root, 10)
+	fsys := versions.Keep(fs.NewOSFileSystem(), s, []string{templates}, testutil.NewDummyLogger())
+
+	note := filepath.Join(root, "zettel", "go.md")
+	require.NoError(t, fsys.WriteFile(note, []byte("# Go\n")))
+	require.NoError(t, fsys.WriteFile(note, []byte("# Go\n")))
+	list, err := s.List(note)
+	require.NoError(t, err)
+	assert.Empty(t, list, "nothing is replaced by a new note or the same contents")
+
+	require.NoError(t, fsys.WriteFile(note, []byte("# Go, edited\n")))
+	list, err = s.List(note)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "# Go\n", read(t, list[0].Path))
+
+	day := filepath.Join(templates, "day.md")
+	require.NoError(t, fsys.WriteFile(day, []byte("# Day\n")))
+	require.NoError(t, fsys.WriteFile(day, []byte("# Day, edited\n")))
+	list, err = s.List(day)
+	require.NoError(t, err)
+	assert.Empty(t, list, "excluded directories have no versions")
+}
+
+func TestKeep_Editor(t *testing.T) {
+	root := t.TempDir()
+	s := versions.NewStore(root, 10)
+	editor := &editingFS{FileSystem: fs.NewOSFileSystem()}
+	fsys := versions.Keep(editor, s, nil, testutil.NewDummyLogger())
+
+	note := filepath.Join(root, "go.md")
+	require.NoError(t, os.WriteFile(note, []byte("# Go\n"), 0644))
+	require.NoError(t, fsys.OpenInEditor(note, "vi"))
+	list, err := s.List(note)
+	require.NoError(t, err)
+	assert.Empty(t, list, "a note left unchanged has no new version")
+
+	editor.content = "# Oops\n"
+	require.NoError(t, fsys.OpenInEditor(note, "vi"))
+	list, err = s.List(note)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "# Go\n", read(t, list[0].Path))
+}
+
+// editingFS replaces the contents of the files opened in the editor with
+// content, if set.
+type editingFS struct {
+	fs.FileSystem
+	content string
+}
+
+func (e *editingFS) OpenInEditor(path, editor string) error {
+	if e.content == "" {
+		return nil
+	}
+	return os.WriteFile(path, []byte(e.content), 0644)
+}
@@ -0,0 +1,227 @@
+// Package watch reports changes to the notes of a vault as they happen, for
+// keeping indexes up to date and running hooks when notes are edited outside
+// of exo.
+package watch
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/a-kostevski/exo/pkg/scan"
+)
+
+// Op is the kind of change to a note.
+type Op int
+
+const (
+	Created Op = iota
+	Modified
+	Removed
+)
+
+func (o Op) String() string {
+	switch o {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	}
+	return fmt.Sprintf("Op(%d)", int(o))
+}
+
+// Event is a change to a note, or to a directory of notes when Dir is set. Only
+// removals are reported for directories; the notes of a created directory are
+// reported one by one.
+type Event struct {
+	Path string
+	Op   Op
+	Dir  bool
+}
+
+// Watcher watches the notes under a root directory, skipping hidden files and
+// excluded directories.
+type Watcher struct {
+	root    string
+	exclude []string
+	delay   time.Duration
+	fsw     *fsnotify.Watcher
+	dirs    map[string]bool
+	// pending holds the first change seen to each path since the last batch.
+	pending map[string]Op
+}
+
+// New returns a Watcher of the notes under root that batches changes until none
+// happened for delay.
+func New(root string, delay time.Duration, exclude ...string) (*Watcher, error) {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start watching: %w", err)
+	}
+	w := &Watcher{
+		root:    root,
+		exclude: exclude,
+		delay:   delay,
+		fsw:     fsw,
+		dirs:    make(map[string]bool),
+		pending: make(map[string]Op),
+	}
+	if err := w.add(root, false); err != nil {
+		fsw.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.fsw.Close()
+}
+
+// Run calls fn with the changes to notes, in batches sorted by path, until ctx
+// is done. Rapid changes to the same note, such as an editor saving through a
+// temporary file, are reported as one event.
+func (w *Watcher) Run(ctx context.Context, fn func([]Event)) error {
+	timer := time.NewTimer(w.delay)
+	timer.Stop()
+	var removedDirs []Event
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return nil
+			}
+			return fmt.Errorf("failed to watch %s: %w", w.root, err)
+		case ev, ok := <-w.fsw.Events:
+			if !ok {
+				return nil
+			}
+			if dir := w.handle(ev); dir != nil {
+				removedDirs = append(removedDirs, *dir)
+			}
+			timer.Reset(w.delay)
+		case <-timer.C:
+			batch := append(removedDirs, w.flush()...)
+			removedDirs = nil
+			if len(batch) > 0 {
+				fn(batch)
+			}
+		}
+	}
+}
+
+// handle records a file system event, returning the removal of a watched
+// directory.
+func (w *Watcher) handle(ev fsnotify.Event) *Event {
+	path := filepath.Clean(ev.Name)
+	if w.skip(path) {
+		return nil
+	}
+	switch {
+	case ev.Has(fsnotify.Remove) || ev.Has(fsnotify.Rename):
+		if w.dirs[path] {
+			for dir := range w.dirs {
+				if dir == path || strings.HasPrefix(dir, path+string(filepath.Separator)) {
+					delete(w.dirs, dir)
+				}
+			}
+			return &Event{Path: path, Op: Removed, Dir: true}
+		}
+		w.record(path, Removed)
+	case ev.Has(fsnotify.Create):
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			// Notes may be written before the directory is watched.
+			_ = w.add(path, true)
+			return nil
+		}
+		w.record(path, Created)
+	case ev.Has(fsnotify.Write):
+		w.record(path, Modified)
+	}
+	return nil
+}
+
+// record notes a change to path if it is a note.
+func (w *Watcher) record(path string, op Op) {
+	if filepath.Ext(path) != scan.NoteExtension {
+		return
+	}
+	if _, ok := w.pending[path]; !ok {
+		w.pending[path] = op
+	}
+}
+
+// flush returns the pending changes, deciding from whether each note still
+// exists and whether it existed before.
+func (w *Watcher) flush() []Event {
+	var events []Event
+	for path, first := range w.pending {
+		_, err := os.Stat(path)
+		exists := err == nil
+		switch {
+		case exists && first == Created:
+			events = append(events, Event{Path: path, Op: Created})
+		case exists:
+			events = append(events, Event{Path: path, Op: Modified})
+		case first != Created:
+			events = append(events, Event{Path: path, Op: Removed})
+		}
+	}
+	clear(w.pending)
+	sort.Slice(events, func(i, j int) bool { return events[i].Path < events[j].Path })
+	return events
+}
+
+// add watches dir and the directories below it. With created set, the notes
+// found are recorded as created.
+func (w *Watcher) add(dir string, created bool) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			return nil
+		}
+		if path != w.root && w.skip(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			if created {
+				w.record(path, Created)
+			}
+			return nil
+		}
+		if err := w.fsw.Add(path); err != nil {
+			return fmt.Errorf("failed to watch %s: %w", path, err)
+		}
+		w.dirs[path] = true
+		return nil
+	})
+}
+
+// skip reports whether path is hidden or excluded.
+func (w *Watcher) skip(path string) bool {
+	if strings.HasPrefix(filepath.Base(path), ".") {
+		return true
+	}
+	for _, dir := range w.exclude {
+		if rel, err := filepath.Rel(dir, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
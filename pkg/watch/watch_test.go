@@ -0,0 +1,71 @@
+package watch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// next returns the next batch of events, failing after a timeout.
+func next(t *testing.T, batches <-chan []watch.Event) []watch.Event {
+	t.Helper()
+	select {
+	case batch := <-batches:
+		return batch
+	case <-time.After(5 * time.Second):
+		t.Fatal("no changes reported")
+		return nil
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	root := t.TempDir()
+	zettel := filepath.Join(root, "zettel")
+	require.NoError(t, os.MkdirAll(zettel, 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "templates"), 0755))
+	existing := filepath.Join(zettel, "Go.md")
+	require.NoError(t, os.WriteFile(existing, []byte("# Go\n"), 0644))
+
+	w, err := watch.New(root, 50*time.Millisecond, filepath.Join(root, "templates"))
+	require.NoError(t, err)
+	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	batches := make(chan []watch.Event, 10)
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx, func(batch []watch.Event) { batches <- batch }) }()
+
+	// An editor saving by replacing the file, and ignored files.
+	created := filepath.Join(zettel, "Rust.md")
+	require.NoError(t, os.WriteFile(created, []byte("# Rust\n"), 0644))
+	require.NoError(t, os.WriteFile(created, []byte("# Rust\n\nOwnership\n"), 0644))
+	require.NoError(t, os.Rename(existing, existing+"~"))
+	require.NoError(t, os.WriteFile(existing, []byte("# Go\n\nChannels\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(zettel, ".Go.md.swp"), nil, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "templates", "zet.md"), nil, 0644))
+	assert.Equal(t, []watch.Event{
+		{Path: existing, Op: watch.Modified},
+		{Path: created, Op: watch.Created},
+	}, next(t, batches))
+
+	// Notes in new directories, and removed directories.
+	deep := filepath.Join(root, "projects", "deep")
+	require.NoError(t, os.MkdirAll(deep, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(deep, "Plan.md"), []byte("# Plan\n"), 0644))
+	assert.Equal(t, []watch.Event{{Path: filepath.Join(deep, "Plan.md"), Op: watch.Created}}, next(t, batches))
+
+	require.NoError(t, os.Remove(created))
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "projects")))
+	batch := next(t, batches)
+	assert.Contains(t, batch, watch.Event{Path: created, Op: watch.Removed})
+	assert.Contains(t, batch, watch.Event{Path: filepath.Join(root, "projects"), Op: watch.Removed, Dir: true})
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,111 @@
+package web
+
+import (
+	"context"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Page is a fetched web page together with its readable content.
+type Page struct {
+	URL   string
+	Title string
+	// Text is the readable content of the page as plain Markdown paragraphs.
+	Text string
+	// HTML is the raw page as fetched.
+	HTML []byte
+}
+
+var (
+	// boilerplatePattern matches elements that never hold the readable content.
+	boilerplatePattern = regexp.MustCompile(`(?is)<!--.*?-->|<(script|style|noscript|template|svg|nav|header|footer|aside|form)\b[^>]*>.*?</(?:script|style|noscript|template|svg|nav|header|footer|aside|form)\s*>`)
+	articlePattern     = regexp.MustCompile(`(?is)<article\b[^>]*>(.*)</article\s*>`)
+	mainPattern        = regexp.MustCompile(`(?is)<main\b[^>]*>(.*)</main\s*>`)
+	bodyPattern        = regexp.MustCompile(`(?is)<body\b[^>]*>(.*)</body\s*>`)
+	headingPattern     = regexp.MustCompile(`(?is)<h([1-6])\b[^>]*>(.*?)</h[1-6]\s*>`)
+	listItemPattern    = regexp.MustCompile(`(?i)<li\b[^>]*>`)
+	lineBreakPattern   = regexp.MustCompile(`(?i)<br\s*/?>`)
+	blockPattern       = regexp.MustCompile(`(?i)</?(?:p|div|section|ul|ol|blockquote|pre|table|tr|figure|figcaption|dl|dt|dd)\b[^>]*>`)
+	tagPattern         = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// FetchPage retrieves the page at rawURL and extracts its title and readable text.
+func (c *Client) FetchPage(ctx context.Context, rawURL string) (*Page, error) {
+	body, err := c.get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	return &Page{URL: rawURL, Title: pageTitle(body), Text: ExtractText(body), HTML: body}, nil
+}
+
+// pageTitle returns the contents of the <title> element of body, or of its first
+// <h1> if it has no title.
+func pageTitle(body []byte) string {
+	match := titlePattern.FindSubmatch(body)
+	if match == nil {
+		match = headingPattern.FindSubmatch(body)
+		if match == nil || string(match[1]) != "1" {
+			return ""
+		}
+		match = match[1:]
+	}
+	return inlineText(string(match[1]))
+}
+
+// ExtractText returns the readable content of an HTML page as Markdown paragraphs.
+// Scripts, styles and page chrome such as navigation, headers and footers are
+// dropped, and the <article> or <main> element is preferred over the whole body.
+// Headings are demoted one level so they nest under the title of a note.
+func ExtractText(page []byte) string {
+	s := boilerplatePattern.ReplaceAllString(string(page), "")
+	for _, p := range []*regexp.Regexp{articlePattern, mainPattern, bodyPattern} {
+		if m := p.FindStringSubmatch(s); m != nil {
+			s = m[1]
+			break
+		}
+	}
+	// Line breaks in the source are plain whitespace; only markup breaks lines.
+	s = strings.Join(strings.Fields(s), " ")
+	s = headingPattern.ReplaceAllStringFunc(s, func(h string) string {
+		m := headingPattern.FindStringSubmatch(h)
+		level := int(m[1][0]-'0') + 1
+		if level > 6 {
+			level = 6
+		}
+		return "\n\n" + strings.Repeat("#", level) + " " + inlineText(m[2]) + "\n\n"
+	})
+	s = listItemPattern.ReplaceAllString(s, "\n- ")
+	s = lineBreakPattern.ReplaceAllString(s, "\n")
+	s = blockPattern.ReplaceAllString(s, "\n\n")
+	s = html.UnescapeString(tagPattern.ReplaceAllString(s, ""))
+
+	var paragraphs []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			paragraphs = append(paragraphs, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" || line == "-" {
+			flush()
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			flush()
+			paragraphs = append(paragraphs, line)
+			continue
+		}
+		current = append(current, line)
+	}
+	flush()
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// inlineText strips tags from an HTML fragment and collapses its whitespace.
+func inlineText(fragment string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(tagPattern.ReplaceAllString(fragment, ""))), " ")
+}
@@ -0,0 +1,52 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/a-kostevski/exo/pkg/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePage = `<!DOCTYPE html>
+<html><head><title>On  Notes &amp; Links</title><style>p { color: red }</style></head>
+<body>
+<nav><a href="/">Home</a></nav>
+<article>
+  <h1>On Notes</h1>
+  <p>Notes are <em>better</em>
+     when linked.</p>
+  <script>track()</script>
+  <ul><li>One</li><li>Two</li></ul>
+  <p>Line one<br>line two</p>
+</article>
+<footer>Copyright</footer>
+</body></html>`
+
+func TestExtractText(t *testing.T) {
+	want := "## On Notes\n\nNotes are better when linked.\n\n- One\n- Two\n\nLine one\nline two"
+	assert.Equal(t, want, web.ExtractText([]byte(samplePage)))
+}
+
+func TestExtractText_NoArticle(t *testing.T) {
+	page := `<html><body><header>Site</header><div>Just <b>text</b></div></body></html>`
+	assert.Equal(t, "Just text", web.ExtractText([]byte(page)))
+}
+
+func TestFetchPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, samplePage)
+	}))
+	defer srv.Close()
+
+	page, err := web.NewClient().FetchPage(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL, page.URL)
+	assert.Equal(t, "On Notes & Links", page.Title)
+	assert.Contains(t, page.Text, "Notes are better when linked.")
+	assert.Equal(t, samplePage, string(page.HTML))
+}
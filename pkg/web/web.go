@@ -0,0 +1,143 @@
+package web
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout bounds each HTTP request made by the client.
+	DefaultTimeout = 10 * time.Second
+	// DefaultArchiveEndpoint is the Wayback Machine availability API.
+	DefaultArchiveEndpoint = "https://archive.org/wayback/available"
+	// maxBodySize limits how much of a page is read when looking for its title.
+	maxBodySize = 1 << 20
+)
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// Client fetches page metadata over HTTP.
+type Client struct {
+	HTTP            *http.Client
+	ArchiveEndpoint string
+}
+
+// NewClient creates a Client with default timeout and archive endpoint.
+func NewClient() *Client {
+	return &Client{
+		HTTP:            &http.Client{Timeout: DefaultTimeout},
+		ArchiveEndpoint: DefaultArchiveEndpoint,
+	}
+}
+
+// FetchTitle retrieves the page at rawURL and returns the contents of its <title> element.
+func (c *Client) FetchTitle(ctx context.Context, rawURL string) (string, error) {
+	body, err := c.get(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	match := titlePattern.FindSubmatch(body)
+	if match == nil {
+		return "", fmt.Errorf("no title found at %s", rawURL)
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+	if title == "" {
+		return "", fmt.Errorf("empty title at %s", rawURL)
+	}
+	return title, nil
+}
+
+// ArchiveSnapshot returns the URL of the closest Wayback Machine snapshot of rawURL.
+func (c *Client) ArchiveSnapshot(ctx context.Context, rawURL string) (string, error) {
+	endpoint := c.ArchiveEndpoint + "?url=" + url.QueryEscape(rawURL)
+	body, err := c.get(ctx, endpoint)
+	if err != nil {
+		return "", err
+	}
+	var resp struct {
+		ArchivedSnapshots struct {
+			Closest struct {
+				Available bool   `json:"available"`
+				URL       string `json:"url"`
+			} `json:"closest"`
+		} `json:"archived_snapshots"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to decode archive response: %w", err)
+	}
+	closest := resp.ArchivedSnapshots.Closest
+	if !closest.Available || closest.URL == "" {
+		return "", fmt.Errorf("no archived snapshot for %s", rawURL)
+	}
+	return closest.URL, nil
+}
+
+// get performs a GET request and returns up to maxBodySize bytes of the body.
+func (c *Client) get(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request for %s: %w", rawURL, err)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", rawURL, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", rawURL, err)
+	}
+	return body, nil
+}
+
+// ValidateURL checks that rawURL is an absolute http(s) URL.
+func ValidateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid URL %q: must be an absolute http(s) URL", rawURL)
+	}
+	return nil
+}
+
+// FormatLink formats a Markdown bullet linking to rawURL with its access date.
+// If title is empty, the bare URL is used; if archive is non-empty, a link to the
+// archived snapshot is appended.
+func FormatLink(title, rawURL, archive string, accessed time.Time) string {
+	return "- " + Citation(title, rawURL, archive, accessed)
+}
+
+// Citation formats a Markdown link to rawURL followed by its access date, as used
+// by FormatLink and for attributing quotes.
+func Citation(title, rawURL, archive string, accessed time.Time) string {
+	var sb strings.Builder
+	if title == "" {
+		sb.WriteString(fmt.Sprintf("<%s>", rawURL))
+	} else {
+		sb.WriteString(fmt.Sprintf("[%s](%s)", escapeLinkText(title), rawURL))
+	}
+	sb.WriteString(fmt.Sprintf(" (accessed %s", accessed.Format("2006-01-02")))
+	if archive != "" {
+		sb.WriteString(fmt.Sprintf(", [archived](%s)", archive))
+	}
+	sb.WriteString(")")
+	return sb.String()
+}
+
+// escapeLinkText escapes characters that would terminate Markdown link text.
+func escapeLinkText(text string) string {
+	return strings.NewReplacer("[", `\[`, "]", `\]`).Replace(text)
+}
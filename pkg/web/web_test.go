@@ -0,0 +1,61 @@
+package web_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/a-kostevski/exo/pkg/web"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchTitle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><head><TITLE>\n  Go &amp; Notes\n</TITLE></head></html>")
+	}))
+	defer srv.Close()
+
+	title, err := web.NewClient().FetchTitle(context.Background(), srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "Go & Notes", title)
+}
+
+func TestFetchTitle_HTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := web.NewClient().FetchTitle(context.Background(), srv.URL)
+	require.Error(t, err)
+}
+
+func TestArchiveSnapshot(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "https://example.com", r.URL.Query().Get("url"))
+		fmt.Fprint(w, `{"archived_snapshots":{"closest":{"available":true,"url":"http://web.archive.org/web/1/https://example.com"}}}`)
+	}))
+	defer srv.Close()
+
+	client := web.NewClient()
+	client.ArchiveEndpoint = srv.URL
+	snapshot, err := client.ArchiveSnapshot(context.Background(), "https://example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "http://web.archive.org/web/1/https://example.com", snapshot)
+}
+
+func TestFormatLink(t *testing.T) {
+	accessed := time.Date(2025, 2, 8, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, `- [A \[b\]](https://x.io) (accessed 2025-02-08)`,
+		web.FormatLink("A [b]", "https://x.io", "", accessed))
+	assert.Equal(t, "- <https://x.io> (accessed 2025-02-08, [archived](https://a.org/x))",
+		web.FormatLink("", "https://x.io", "https://a.org/x", accessed))
+}
+
+func TestValidateURL(t *testing.T) {
+	assert.NoError(t, web.ValidateURL("https://example.com/a"))
+	assert.Error(t, web.ValidateURL("example.com"))
+	assert.Error(t, web.ValidateURL("ftp://example.com"))
+}
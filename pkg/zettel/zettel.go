@@ -2,14 +2,27 @@ package zettel
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/a-kostevski/exo/pkg/config"
+	"github.com/a-kostevski/exo/pkg/frontmatter"
 	"github.com/a-kostevski/exo/pkg/fs"
+	"github.com/a-kostevski/exo/pkg/id"
 	"github.com/a-kostevski/exo/pkg/logger"
 	"github.com/a-kostevski/exo/pkg/note"
+	"github.com/a-kostevski/exo/pkg/scan"
+	"github.com/a-kostevski/exo/pkg/slug"
 	"github.com/a-kostevski/exo/pkg/templates"
 )
 
+// NoteType is the note type name used to look up the configured ID generator.
+const NoteType = "zettel"
+
+// subDir is the directory, relative to the data home, new zettels are created in.
+const subDir = "0-inbox"
+
 // ZettelNote represents a specialized note (commonly known as a Zettel)
 // that extends the basic functionality provided by BaseNote. In addition to
 // the common note fields, a Zettel note includes a custom Tag field.
@@ -20,16 +33,31 @@ type ZettelNote struct {
 // NewZettelNote creates a new Zettel note with the specified title and tag.
 // Dependencies are passed in (config, template manager, logger, fs) so that the
 // note does not depend on global state. Default options (such as saving the note
-// in the "zettel" subdirectory, using a filename based on the title, and applying
-// the "zettel" template) are set; additional note options may be provided to
-// override these defaults.
+// in the "zettel" subdirectory, using a filename made from the title as the slug
+// settings say, and applying the "zettel" template) are set; additional note
+// options may be provided to override these defaults.
+//
+// When an ID generator is configured for zettels (id.zettel), the note is given a
+// generated ID that is unique across the vault and its filename is prefixed with it.
 func NewZettelNote(title string, cfg config.Config, tm templates.TemplateManager, log logger.Logger, fs fs.FileSystem, opts ...note.NoteOption) (note.Note, error) {
 	// Set defaults specific to Zettel notes.
+	name := slug.Make(title, cfg.Slug.Options(), time.Now())
 	defaultOpts := []note.NoteOption{
-		note.WithSubDir("0-inbox"),
-		// For a default filename, we use the title with a ".md" extension.
-		note.WithFileName(fmt.Sprintf("%s.md", title)),
+		note.WithSubDir(subDir),
+		// The default filename is the title, slugged as configured.
+		note.WithFileName(name + scan.NoteExtension),
 		note.WithTemplateName("zet"),
+		note.WithType(NoteType),
+	}
+	if scheme := cfg.IDGenerator(NoteType); scheme != "" {
+		noteID, err := generateID(cfg, scheme)
+		if err != nil {
+			return nil, err
+		}
+		defaultOpts = append(defaultOpts,
+			note.WithID(noteID),
+			note.WithFileName(fmt.Sprintf("%s %s%s", noteID, name, scan.NoteExtension)),
+		)
 	}
 	// Merge the defaults with any options passed in.
 	allOpts := append(defaultOpts, opts...)
@@ -79,3 +107,90 @@ func (z *ZettelNote) Save() error {
 	z.Logger.Infof("Saving Zettel note %s", z.Title())
 	return z.BaseNote.Save()
 }
+
+// generateID returns a new ID from the named generator that no existing note uses.
+func generateID(cfg config.Config, scheme string) (string, error) {
+	gen, err := id.New(scheme)
+	if err != nil {
+		return "", err
+	}
+	taken, err := TakenIDs(cfg.Dir.DataHome)
+	if err != nil {
+		return "", err
+	}
+	noteID, err := id.Unique(gen, filepath.Join(cfg.Dir.DataHome, subDir), func(s string) bool { return taken[s] }, 0)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate note id: %w", err)
+	}
+	return noteID, nil
+}
+
+// TakenIDs returns the set of IDs already used by notes under dataHome: the "id"
+// frontmatter field, each note's filename and the filename's leading ID prefix.
+func TakenIDs(dataHome string) (map[string]bool, error) {
+	notes, err := scan.Scan(dataHome)
+	if err != nil {
+		return nil, fmt.Errorf("failed to index notes: %w", err)
+	}
+	taken := make(map[string]bool)
+	for _, n := range notes {
+		if v := frontmatter.String(n.Meta, "id"); v != "" {
+			taken[v] = true
+		}
+		stem := strings.TrimSuffix(filepath.Base(n.Path), scan.NoteExtension)
+		taken[stem] = true
+		if fields := strings.Fields(stem); len(fields) > 0 {
+			taken[fields[0]] = true
+		}
+	}
+	return taken, nil
+}
+
+// ForkContent returns the initial content of a zettel forked from another note:
+// the excerpt quoted under the new title, and a link back to the source note.
+func ForkContent(title, source, excerpt string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n\n", title)
+	if strings.TrimSpace(excerpt) != "" {
+		sb.WriteString(note.Quote(excerpt))
+		sb.WriteString("\n\n")
+	}
+	fmt.Fprintf(&sb, "## Links\n\n- Forked from [[%s]]\n", source)
+	return sb.String()
+}
+
+// maxCaptureTitle is the maximum length, in characters, of a title taken from
+// captured text.
+const maxCaptureTitle = 80
+
+// CaptureContent returns the initial content of a zettel captured from text,
+// such as the output of another program: the text under the title.
+func CaptureContent(title, text string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", title)
+	if text = strings.Trim(text, "\n"); strings.TrimSpace(text) != "" {
+		sb.WriteString("\n" + text + "\n")
+	}
+	return sb.String()
+}
+
+// CaptureTitle splits captured text into a title, its first non-empty line
+// without heading markers and shortened to 80 characters, and the rest of it.
+// Text without a non-empty line has no title.
+func CaptureTitle(text string) (title, rest string) {
+	for {
+		line, after, found := strings.Cut(text, "\n")
+		if title = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#")); title != "" {
+			rest = after
+			break
+		}
+		if !found {
+			return "", ""
+		}
+		text = after
+	}
+	if runes := []rune(title); len(runes) > maxCaptureTitle {
+		title = strings.TrimSpace(string(runes[:maxCaptureTitle]))
+	}
+	return title, rest
+}
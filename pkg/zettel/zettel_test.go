@@ -3,6 +3,7 @@ package zettel_test
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -114,3 +115,63 @@ func TestZettelNote_Timestamps(t *testing.T) {
 	assert.WithinDuration(t, start, zNote.Created(), time.Second)
 	assert.WithinDuration(t, start, zNote.Modified(), time.Second)
 }
+
+// TestNewZettelNote_GeneratedID verifies that a configured ID generator prefixes the
+// filename and skips IDs already used in the vault.
+func TestNewZettelNote_GeneratedID(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.ID = map[string]string{zettel.NoteType: "sequential"}
+
+	inbox := filepath.Join(cfg.Dir.DataHome, "0-inbox")
+	require.NoError(t, os.MkdirAll(inbox, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(inbox, "0001 First.md"), []byte("# First\n"), 0644))
+	// An ID recorded in frontmatter elsewhere in the vault is also taken.
+	other := filepath.Join(cfg.Dir.DataHome, "projects")
+	require.NoError(t, os.MkdirAll(other, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(other, "moved.md"), []byte("---\nid: \"0002\"\n---\n"), 0644))
+
+	zNote, err := zettel.NewZettelNote("Second", cfg, dtm, dl, dfs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "0003", zNote.ID())
+	assert.Equal(t, filepath.Join(inbox, "0003 Second.md"), zNote.Path())
+}
+
+func TestNewZettelNote_UnknownIDGenerator(t *testing.T) {
+	tmpDir := t.TempDir()
+	cfg, dtm, dl, dfs, _ := testutil.NewDummyDeps(tmpDir)
+	cfg.ID = map[string]string{zettel.NoteType: "uuid"}
+
+	_, err := zettel.NewZettelNote("Note", cfg, dtm, dl, dfs)
+	require.Error(t, err)
+}
+
+func TestForkContent(t *testing.T) {
+	content := zettel.ForkContent("Narrow idea", "Broad note", "line one\nline two")
+	assert.Equal(t, "# Narrow idea\n\n> line one\n> line two\n\n## Links\n\n- Forked from [[Broad note]]\n", content)
+
+	content = zettel.ForkContent("Empty", "Source", "")
+	assert.Equal(t, "# Empty\n\n## Links\n\n- Forked from [[Source]]\n", content)
+}
+
+func TestCaptureContent(t *testing.T) {
+	assert.Equal(t, "# Build log\n\nok  \tpkg\t0.1s\n", zettel.CaptureContent("Build log", "\nok  \tpkg\t0.1s\n\n"))
+	assert.Equal(t, "# Empty\n", zettel.CaptureContent("Empty", "\n \n"))
+}
+
+func TestCaptureTitle(t *testing.T) {
+	title, rest := zettel.CaptureTitle("\n## Meeting notes \nAnn joined\n")
+	assert.Equal(t, "Meeting notes", title)
+	assert.Equal(t, "Ann joined\n", rest)
+
+	title, rest = zettel.CaptureTitle("single line")
+	assert.Equal(t, "single line", title)
+	assert.Equal(t, "", rest)
+
+	title, _ = zettel.CaptureTitle(strings.Repeat("word ", 30))
+	assert.Equal(t, 79, len(title), "long titles are shortened to 80 characters and trimmed")
+
+	title, _ = zettel.CaptureTitle("\n  \n")
+	assert.Equal(t, "", title)
+}
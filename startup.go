@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/a-kostevski/exo/cmd"
+	"github.com/a-kostevski/exo/pkg/config"
+)
+
+// startupOptions holds the global flags that must be known before the
+// dependencies are built, i.e. before cobra parses the command line.
+type startupOptions struct {
+	configPath string
+	debug      bool
+	readOnly   bool
+	force      bool
+	onConflict string
+}
+
+// parseStartupOptions extracts --config/-c, --debug-startup, --read-only,
+// --force and --on-conflict from the global flags at the start of args,
+// before the command. Everything else is left for cobra to parse.
+func parseStartupOptions(args []string) startupOptions {
+	flags := cmd.ParseGlobalFlags(args)
+	var opts startupOptions
+	opts.configPath, _ = flags.GetString("config")
+	opts.debug, _ = flags.GetBool("debug-startup")
+	opts.readOnly, _ = flags.GetBool("read-only")
+	opts.force, _ = flags.GetBool("force")
+	opts.onConflict, _ = flags.GetString("on-conflict")
+	return opts
+}
+
+// dumpStartup prints the resolved configuration for --debug-startup.
+func dumpStartup(w io.Writer, cfg *config.Config) {
+	source := cfg.Source()
+	if source == "" {
+		source = "(none, using defaults)"
+	}
+	fmt.Fprintf(w, "Configuration file: %s\n\n%s\n", source, cfg)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/a-kostevski/exo/cmd"
+	exoerrors "github.com/a-kostevski/exo/pkg/errors"
+)
+
+func TestParseStartupOptions(t *testing.T) {
+	tests := []struct {
+		args []string
+		want startupOptions
+	}{
+		{[]string{"day"}, startupOptions{}},
+		{[]string{"-c", "/a.yaml", "day"}, startupOptions{configPath: "/a.yaml"}},
+		{[]string{"--config=/b.yaml", "--debug-startup"}, startupOptions{configPath: "/b.yaml", debug: true}},
+		{[]string{"-c/c.yaml"}, startupOptions{configPath: "/c.yaml"}},
+		{[]string{"--read-only", "cat", "note"}, startupOptions{readOnly: true}},
+		{[]string{"--force", "bulk", "delete", "--yes"}, startupOptions{force: true}},
+		{[]string{"--on-conflict", "merge", "day"}, startupOptions{onConflict: "merge"}},
+		{[]string{"-d", "--output", "json", "--on-conflict=copy", "day"}, startupOptions{onConflict: "copy"}},
+		{[]string{"-dc", "/d.yaml", "--read-only"}, startupOptions{configPath: "/d.yaml", readOnly: true}},
+		{[]string{"log", "--", "--config", "x"}, startupOptions{}},
+		{[]string{"--", "--config", "x"}, startupOptions{}},
+		// Flags after the command are not global ones read at startup.
+		{[]string{"init", "--force"}, startupOptions{}},
+		{[]string{"bulk", "delete", "--force", "--yes"}, startupOptions{}},
+		{[]string{"day", "--on-conflict=copy"}, startupOptions{}},
+		{[]string{"log", "-cfoo"}, startupOptions{}},
+		{[]string{"log", "--read-only", "-c", "/e.yaml"}, startupOptions{}},
+		{[]string{"--unknown", "--force", "day"}, startupOptions{}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseStartupOptions(tt.args), "args: %v", tt.args)
+	}
+}
+
+func TestReportStartupError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing.yaml")
+	container := cmd.NewContainer(cmd.ContainerOptions{ConfigPath: path})
+	cfg, err := container.Config()
+	require.Error(t, err)
+	assert.NotNil(t, cfg, "the defaults stand in for the configuration")
+
+	var buf bytes.Buffer
+	code := cmd.ReportError(cmd.NewRootCmd(container.Dependencies()), &buf, err)
+	out := buf.String()
+	assert.Equal(t, exoerrors.ExitCode(exoerrors.New(exoerrors.Config, "")), code)
+	assert.Contains(t, out, "failed to load configuration")
+	assert.Contains(t, out, "while using: "+path)
+	assert.Contains(t, out, "does not exist")
+}